@@ -6,6 +6,7 @@ import (
 
 	"google.golang.org/grpc"
 
+	"github.com/herald-lol/herald/backend/internal/config"
 	// gRPC server implementations
 	"github.com/herald-lol/herald/backend/internal/grpc/server"
 )
@@ -16,8 +17,14 @@ import (
 func main() {
 	log.Println("🎮 Herald.lol gRPC Server starting...")
 
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	addr := net.JoinHostPort(cfg.Server.GRPCHost, cfg.Server.GRPCPort)
+
 	// Create TCP listener
-	lis, err := net.Listen("tcp", ":50051")
+	lis, err := net.Listen("tcp", addr)
 	if err != nil {
 		log.Fatalf("Failed to listen: %v", err)
 	}
@@ -29,7 +36,7 @@ func main() {
 	analyticsServer := server.NewAnalyticsGRPCServer()
 	_ = analyticsServer // Will register when services are stable
 
-	log.Println("🚀 Herald.lol gRPC Server listening on :50051")
+	log.Printf("🚀 Herald.lol gRPC Server listening on %s", addr)
 	log.Println("⚡ Gaming Analytics Services Ready (<5s response time)")
 
 	if err := s.Serve(lis); err != nil {
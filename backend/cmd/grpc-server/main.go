@@ -3,11 +3,11 @@ package main
 import (
 	"context"
 	"database/sql"
-	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
@@ -23,16 +23,12 @@ import (
 )
 
 func main() {
-	// Parse command line flags
-	var (
-		grpcHost = flag.String("host", "0.0.0.0", "gRPC server host")
-		grpcPort = flag.Int("port", 50051, "gRPC server port")
-		envFile  = flag.String("env", ".env", "Environment file path")
-	)
-	flag.Parse()
-
-	// Load configuration
-	cfg := config.Load(*envFile)
+	// Load and validate configuration (GRPC_HOST, GRPC_PORT, DB_*, REDIS_*)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	grpcPort, _ := strconv.Atoi(cfg.Server.GRPCPort) // validated by Load
 
 	// Initialize database
 	db, err := initDatabase(cfg)
@@ -46,7 +42,7 @@ func main() {
 	defer redisClient.Close()
 
 	// Initialize Riot API client
-	riotClient := riot.NewClient(cfg.RiotAPIKey, redisClient)
+	riotClient := riot.NewClient(cfg.Riot.APIKey, redisClient)
 
 	// Initialize core engine
 	coreEngine := analytics.NewCoreEngine(db, redisClient)
@@ -56,20 +52,25 @@ func main() {
 
 	// Initialize services
 	analyticsService := services.NewAnalyticsService(db, redisClient)
-	matchService := services.NewMatchProcessingService(db, redisClient)
 	riotService := services.NewRiotService(riotClient, db, redisClient)
+
+	// Finished processing jobs are swept per JOB_CLEANUP_INTERVAL / JOB_RETENTION
+	matchConfig := services.DefaultMatchProcessingConfig()
+	matchConfig.JobCleanupInterval = cfg.Cleanup.JobInterval
+	matchConfig.JobRetention = cfg.Cleanup.JobRetention
+	matchService := services.NewMatchProcessingService(riotService, analytics.NewAnalyticsEngine(nil), matchConfig)
 	realtimeService := services.NewRealtimeService()
 
 	// Create gRPC server configuration
 	grpcConfig := &server.GRPCServerConfig{
-		Host:                  *grpcHost,
-		Port:                  *grpcPort,
+		Host:                  cfg.Server.GRPCHost,
+		Port:                  grpcPort,
 		MaxConnectionIdle:     15 * time.Minute,
 		MaxConnectionAge:      30 * time.Minute,
 		MaxConnectionAgeGrace: 5 * time.Second,
 		KeepAliveTime:         5 * time.Minute,
 		KeepAliveTimeout:      20 * time.Second,
-		EnableReflection:      cfg.Environment == "development",
+		EnableReflection:      cfg.IsDevelopment(),
 		EnableHealthCheck:     true,
 	}
 
@@ -95,7 +96,7 @@ func main() {
 	}
 
 	log.Println("🎮 Herald.lol gRPC Server Started Successfully!")
-	log.Printf("📡 Listening on %s:%d", *grpcHost, *grpcPort)
+	log.Printf("📡 Listening on %s:%d", cfg.Server.GRPCHost, grpcPort)
 	log.Println("⚡ Performance targets: <5s analytics, 99.9% uptime")
 	log.Println("🎯 Services: Analytics, Match Processing, Riot API Integration")
 
@@ -110,22 +111,8 @@ func main() {
 }
 
 func initDatabase(cfg *config.Config) (*sql.DB, error) {
-	// Build connection string
-	var dsn string
-	if cfg.Environment == "production" {
-		dsn = fmt.Sprintf(
-			"host=%s port=%d user=%s password=%s dbname=%s sslmode=require",
-			cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName,
-		)
-	} else {
-		dsn = fmt.Sprintf(
-			"host=%s port=%d user=%s password=%s dbname=%s sslmode=disable",
-			cfg.DBHost, cfg.DBPort, cfg.DBUser, cfg.DBPassword, cfg.DBName,
-		)
-	}
-
 	// Open database connection
-	db, err := sql.Open("postgres", dsn)
+	db, err := sql.Open("postgres", cfg.GetDatabaseDSN())
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
@@ -150,9 +137,9 @@ func initDatabase(cfg *config.Config) (*sql.DB, error) {
 
 func initRedis(cfg *config.Config) *redis.Client {
 	redisClient := redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf("%s:%d", cfg.RedisHost, cfg.RedisPort),
-		Password:     cfg.RedisPassword,
-		DB:           0,
+		Addr:         cfg.GetRedisAddr(),
+		Password:     cfg.Redis.Password,
+		DB:           cfg.Redis.DB,
 		MaxRetries:   3,
 		PoolSize:     50,
 		MinIdleConns: 10,
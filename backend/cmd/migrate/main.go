@@ -48,6 +48,10 @@ func main() {
 		&models.TFTUnit{},
 		&models.TFTTrait{},
 		&models.TFTAugment{},
+		// Derived stats cleared and recomputed by a stats rebuild
+		&models.PlayerStats{},
+		&models.ChampionStats{},
+		&models.ChampionAggregate{},
 	)
 
 	if err != nil {
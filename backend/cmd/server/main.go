@@ -1,19 +1,32 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
 
+	"github.com/herald-lol/herald/backend/internal/analytics"
+	"github.com/herald-lol/herald/backend/internal/auth"
 	"github.com/herald-lol/herald/backend/internal/config"
+	"github.com/herald-lol/herald/backend/internal/export"
 	"github.com/herald-lol/herald/backend/internal/handlers"
+	"github.com/herald-lol/herald/backend/internal/logger"
+	"github.com/herald-lol/herald/backend/internal/match"
+	"github.com/herald-lol/herald/backend/internal/middleware"
 	"github.com/herald-lol/herald/backend/internal/models"
+	"github.com/herald-lol/herald/backend/internal/riot"
 	"github.com/herald-lol/herald/backend/internal/services"
+	"github.com/herald-lol/herald/backend/internal/summoner"
+	"github.com/herald-lol/herald/backend/internal/websocket"
 )
 
 func main() {
@@ -23,6 +36,14 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Configure log verbosity
+	if level, err := logger.ParseLevel(cfg.Logging.Level); err == nil {
+		logger.SetLevel(level)
+	} else {
+		log.Printf("Invalid log level %q, defaulting to info", cfg.Logging.Level)
+	}
+	logger.SetMaxLineLength(cfg.Logging.MaxLineLength)
+
 	// Connect to database
 	db, err := connectDatabase(cfg)
 	if err != nil {
@@ -36,7 +57,27 @@ func main() {
 
 	// Initialize services
 	authService := services.NewAuthService(db, cfg)
+	riotService := services.NewRiotService(cfg, db)
+
+	// Sync jobs still marked running were cut off when the server stopped
+	if reaped, err := riotService.ReapInterruptedSyncJobs(context.Background()); err != nil {
+		logger.Warnf("Failed to close interrupted sync jobs: %v", err)
+	} else if reaped > 0 {
+		logger.Infof("Marked %d interrupted sync job(s) as failed", reaped)
+	}
+	shareService := services.NewShareService(db, cfg)
+	goalService := services.NewGoalService(db)
 	analyticsService := services.NewAnalyticsService(db)
+	analyticsService.SetBatchSize(cfg.Analytics.BatchSize)
+	analyticsService.SetDuoMinSharedGames(cfg.Analytics.DuoMinSharedGames)
+	analyticsService.SetTimeOfDayBucketHours(cfg.Analytics.TimeOfDayBucketHours)
+	analyticsService.SetChampionPoolThreshold(cfg.Analytics.ChampionPoolThreshold)
+	analyticsService.SetBanMinGames(cfg.Analytics.BanMinGames)
+	analyticsService.SetObjectiveMinGames(cfg.Analytics.ObjectiveMinGames)
+	analyticsService.SetCSBenchmarks(cfg.Analytics.CSBenchmarks)
+	for alias, champion := range cfg.Analytics.ChampionAliases {
+		models.RegisterChampionAlias(alias, champion)
+	}
 	mapService := services.NewMapService() // Map zone service
 	damageAnalyticsService := services.NewDamageAnalyticsService(analyticsService)
 	visionAnalyticsService := services.NewVisionAnalyticsService(analyticsService, mapService)
@@ -44,15 +85,106 @@ func main() {
 	wardAnalyticsService := services.NewWardAnalyticsService(analyticsService, mapService)
 	championAnalyticsService := services.NewChampionAnalyticsService(analyticsService)
 	metaAnalyticsService := services.NewMetaAnalyticsService(analyticsService)
-	predictiveAnalyticsService := services.NewPredictiveAnalyticsService(analyticsService)
+	metaAnalyticsService.SetMinSample(cfg.Analytics.MetaMinGames, cfg.Analytics.MetaMinPickRate)
+	metaAnalyticsService.SetLearningCurve(cfg.Analytics.ComfortMinGames, cfg.Analytics.ComfortWinRate)
+	predictiveAnalyticsService := services.NewPredictiveAnalyticsService(analyticsService, metaAnalyticsService)
+	predictiveAnalyticsService.SetRecencyHalfLife(cfg.Analytics.RecommendationHalfLifeDays)
 	improvementRecommendationsService := services.NewImprovementRecommendationsService(db, analyticsService, predictiveAnalyticsService)
 	matchPredictionService := services.NewMatchPredictionService(analyticsService, predictiveAnalyticsService)
 	teamCompositionService := services.NewTeamCompositionService(analyticsService, predictiveAnalyticsService)
 	counterPickService := services.NewCounterPickService(db, analyticsService, metaAnalyticsService)
 	skillProgressionService := services.NewSkillProgressionService(db, analyticsService, predictiveAnalyticsService)
 
+	// Exports analyze summoners through the Riot client, cached in Redis
+	redisClient := redis.NewClient(&redis.Options{
+		Addr:     cfg.GetRedisAddr(),
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	defer redisClient.Close()
+	analyticsEngine := analytics.NewAnalyticsEngine(nil)
+	riotClient := riot.NewRiotClient(redisClient, riot.DefaultRiotClientConfig(cfg.Riot.APIKey))
+	summonerService := summoner.NewSummonerService(riotClient, analyticsEngine, redisClient, nil)
+	exportService := export.NewExportService(export.NewExportConfig(cfg), analyticsEngine, match.NewMatchAnalyzer(nil, analyticsEngine), summonerService)
+
+	// Fetch Riot's queue list and DataDragon's current patch, champion list and
+	// item list so uncommon queue IDs, new champions and items get readable
+	// names, then keep them fresh across patches
+	go func() {
+		refreshStaticData(riotService)
+
+		interval := cfg.Riot.StaticDataRefreshInterval
+		if interval <= 0 {
+			return
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			refreshStaticData(riotService)
+		}
+	}()
+
+	// Warm the user's analytics cache whenever a sync stores new matches
+	if cfg.Sync.PrecomputeAnalytics {
+		riotService.OnSyncComplete(analyticsService.PrecomputeAnalytics)
+	}
+
+	// Record a daily analytics snapshot after each sync for snapshot diffs
+	riotService.OnSyncComplete(analyticsService.SnapshotAfterSync)
+
+	// Unified subsystem status for the frontend banner
+	statusService := services.NewStatusService(db, riotService, analyticsService)
+	statusService.SetCheckTimeout(cfg.Server.StatusCheckTimeout)
+	statusService.SetCacheTTL(cfg.Server.StatusCacheTTL)
+	statusService.SetMessage(cfg.Server.StatusMessage)
+
+	// Background match syncing for linked accounts
+	if cfg.Sync.AutoSyncEnabled {
+		autoSyncService := services.NewAutoSyncService(db, riotService, cfg.Sync.AutoSyncInterval, cfg.Sync.AutoSyncWorkers)
+		statusService.SetAutoSync(autoSyncService)
+		autoSyncService.Start()
+		defer autoSyncService.Stop()
+	}
+
+	// Opt-in purge of old matches (MATCH_RETENTION_MONTHS), archiving their aggregates first
+	if cfg.Cleanup.MatchRetentionMonths > 0 {
+		retentionService := services.NewRetentionService(db, analyticsService, cfg.Cleanup.MatchRetentionMonths, cfg.Cleanup.MatchRetentionInterval)
+		retentionService.Start()
+		defer retentionService.Stop()
+	}
+
+	// Purge expired gaming sessions (SESSION_CLEANUP_INTERVAL / SESSION_GRACE_PERIOD)
+	// when the gaming auth tables are present
+	if db.Migrator().HasTable(&auth.GamingUserSession{}) {
+		sessionCtx, stopSessionCleanup := context.WithCancel(context.Background())
+		defer stopSessionCleanup()
+		go auth.NewDatabaseUserStore(db).StartGamingSessionCleanup(sessionCtx, cfg.Cleanup.SessionInterval, cfg.Cleanup.SessionGracePeriod)
+	}
+
+	// Operator-triggered analytics cache warmup for all active users, and
+	// optionally for the most active ones shortly after boot
+	cacheWarmupService := services.NewCacheWarmupService(db, analyticsService, cfg.Analytics.WarmupWorkers, cfg.Analytics.WarmupActiveDays)
+	defer cacheWarmupService.Stop()
+	if cfg.Analytics.StartupWarmup {
+		cacheWarmupService.StartAfterBoot(cfg.Analytics.StartupWarmupDelay, cfg.Analytics.StartupWarmupUsers)
+	}
+
+	// Live updates over WebSocket, at most WEBSOCKET_MAX_CONNECTIONS_PER_USER
+	// connections per user
+	hub := websocket.NewHub(cfg.Server.WebSocketMaxConnsPerUser).
+		WithTokenValidator(func(token, userID string) bool {
+			user, err := authService.ValidateToken(token)
+			return err == nil && user.ID == userID
+		})
+	hubCtx, stopHub := context.WithCancel(context.Background())
+	defer stopHub()
+	go hub.Run(hubCtx)
+
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
+	riotHandler := handlers.NewRiotHandler(riotService)
+	shareHandler := handlers.NewShareHandler(shareService)
+	goalHandler := handlers.NewGoalHandler(goalService)
 	analyticsHandler := handlers.NewAnalyticsHandler(analyticsService)
 	damageHandler := handlers.NewDamageHandler(damageAnalyticsService)
 	visionHandler := handlers.NewVisionHandler(visionAnalyticsService)
@@ -66,6 +198,13 @@ func main() {
 	teamCompositionHandler := handlers.NewTeamCompositionHandler(teamCompositionService)
 	counterPickHandler := handlers.NewCounterPickHandler(counterPickService)
 	skillProgressionHandler := handlers.NewSkillProgressionHandler(skillProgressionService)
+	statusHandler := handlers.NewStatusHandler(statusService)
+	adminHandler := handlers.NewAdminHandler(cacheWarmupService)
+	exportHandler := handlers.NewExportHandler(exportService).
+		WithRemakePreference(analyticsService).
+		WithTeammateMatches(riotService).
+		WithLinkedAccounts(riotService).
+		WithRankHistory(riotService)
 
 	// Setup Gin router
 	if !cfg.IsDevelopment() {
@@ -77,18 +216,53 @@ func main() {
 	// Add CORS middleware
 	r.Use(corsMiddleware())
 
+	// Reject oversized request bodies (MAX_REQUEST_BODY_BYTES, 0 disables)
+	r.Use(middleware.BodyLimit(cfg.Server.MaxBodyBytes))
+
+	// Add security headers (SECURITY_HEADERS_ENABLED=false disables them)
+	csp := cfg.Security.ContentSecurityPolicy
+	if csp == "" {
+		csp = middleware.DefaultContentSecurityPolicy
+	}
+	r.Use(middleware.SecurityHeaders(middleware.SecurityHeadersConfig{
+		Enabled:               cfg.Security.HeadersEnabled,
+		HSTSMaxAge:            cfg.Security.HSTSMaxAge,
+		ContentSecurityPolicy: csp,
+	}))
+
 	// Health check endpoint
 	r.GET("/health", func(c *gin.Context) {
+		riotStatus := riotService.APIKeyStatus()
+		status := "ok"
+		if !riotStatus.Configured || !riotStatus.Valid {
+			status = "degraded"
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"status":    "ok",
+			"status":    status,
 			"timestamp": time.Now().UTC(),
 			"version":   "1.0.0",
+			"riot_api":  riotStatus,
 		})
 	})
 
+	// WebSocket connections (?user_id=...&token=...)
+	r.GET("/ws", hub.HandleWebSocket)
+
 	// API routes
 	api := r.Group("/api/v1")
 	{
+		// Subsystem status (ok, degraded, down, disabled) for the frontend banner
+		api.GET("/status", statusHandler.GetStatus)
+
+		// Operator endpoints (ADMIN_TOKEN, disabled when unset)
+		admin := api.Group("/admin")
+		admin.Use(middleware.AdminToken(cfg.Server.AdminToken))
+		{
+			admin.POST("/cache-warmup", adminHandler.StartCacheWarmup)
+			admin.GET("/cache-warmup", adminHandler.GetCacheWarmupStatus)
+		}
+
 		// Auth routes
 		auth := api.Group("/auth")
 		{
@@ -103,7 +277,10 @@ func main() {
 			{
 				protected.GET("/profile", authHandler.GetProfile)
 				protected.POST("/change-password", authHandler.ChangePassword)
+				protected.GET("/preferences", authHandler.GetPreferences)
+				protected.PUT("/preferences", authHandler.UpdatePreferences)
 				protected.POST("/logout", authHandler.Logout)
+				protected.POST("/validate-batch", riotHandler.ValidateAccountsBatch)
 			}
 		}
 
@@ -119,6 +296,40 @@ func main() {
 		riot.Use(authHandler.AuthMiddleware())
 		{
 			// TODO: Add Riot API endpoints
+			riot.GET("/test", riotHandler.TestAPI)
+			riot.GET("/matches", riotHandler.GetMatchHistory)
+			riot.GET("/matches/:match_id", riotHandler.GetMatchDetails)
+			riot.GET("/matches/export", riotHandler.ExportMatches)
+			riot.GET("/matches/incomplete", riotHandler.GetIncompleteMatches)
+			riot.GET("/sync/status", riotHandler.GetSyncStatus)
+			riot.POST("/sync/all", riotHandler.SyncAllAccounts)
+			riot.GET("/sync/all/:id", riotHandler.GetBulkSync)
+		}
+
+		// Match sync jobs: list them and retry failed ones (protected)
+		syncJobs := api.Group("/sync/jobs")
+		syncJobs.Use(authHandler.AuthMiddleware())
+		{
+			syncJobs.GET("", riotHandler.ListSyncJobs)
+			syncJobs.POST("/:id/retry", riotHandler.RetrySyncJob)
+		}
+
+		// Share links: the token view is public, managing links requires auth
+		share := api.Group("/share")
+		{
+			share.GET("/:token", shareHandler.GetSharedProfile)
+			share.GET("", authHandler.AuthMiddleware(), shareHandler.ListShareLinks)
+			share.POST("", authHandler.AuthMiddleware(), shareHandler.CreateShareLink)
+			share.DELETE("/:id", authHandler.AuthMiddleware(), shareHandler.RevokeShareLink)
+		}
+
+		// Champion goals tracked against stored matches (protected)
+		goals := api.Group("/goals")
+		goals.Use(authHandler.AuthMiddleware())
+		{
+			goals.GET("", goalHandler.ListGoals)
+			goals.POST("", goalHandler.CreateGoal)
+			goals.DELETE("/:id", goalHandler.DeleteGoal)
 		}
 
 		// Analytics routes (protected)
@@ -139,16 +350,23 @@ func main() {
 			teamCompositionHandler.RegisterRoutes(analytics)
 			counterPickHandler.RegisterRoutes(analytics)
 			skillProgressionHandler.RegisterRoutes(analytics)
+			exportHandler.RegisterRoutes(analytics)
 		}
 
 		// Match routes (protected)
 		matches := api.Group("/matches")
 		matches.Use(authHandler.AuthMiddleware())
 		{
-			// TODO: Add match endpoints
+			matches.GET("/:match_id/timeline", riotHandler.GetMatchTimeline)
 		}
 	}
 
+	// Serve the built frontend for every route the API doesn't own
+	r.NoRoute(middleware.StaticCache(cfg.Server.StaticDir), middleware.SPA(cfg.Server.StaticDir))
+	if info, err := os.Stat(cfg.Server.StaticDir); err != nil || !info.IsDir() {
+		log.Printf("⚠️  Frontend build not found in %s, serving the API only", cfg.Server.StaticDir)
+	}
+
 	// Start server
 	srv := &http.Server{
 		Addr:         ":" + cfg.Server.Port,
@@ -156,13 +374,21 @@ func main() {
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
 		IdleTimeout:  cfg.Server.IdleTimeout,
+		TLSConfig: &tls.Config{
+			MinVersion: cfg.GetMinTLSVersion(),
+		},
 	}
 
 	log.Printf("🚀 Herald.lol API server starting on :%s", cfg.Server.Port)
 	log.Printf("📊 Environment: %s", cfg.Server.Environment)
 	log.Printf("🗄️  Database: %s", cfg.Database.Driver)
 
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if cfg.TLSEnabled() {
+		err = srv.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+	} else {
+		err = srv.ListenAndServe()
+	}
+	if err != nil && err != http.ErrServerClosed {
 		log.Fatalf("Server failed to start: %v", err)
 	}
 }
@@ -205,6 +431,12 @@ func runMigrations(db *gorm.DB) error {
 		&models.Subscription{},
 		&models.Match{},
 		&models.MatchParticipant{},
+		&models.MatchBan{},
+		&models.MatchTeamObjectives{},
+		&models.IncompleteMatch{},
+		&models.SyncJob{},
+		&models.MatchTimelineFrame{},
+		&models.ShareLink{},
 		&models.TFTMatch{},
 		&models.TFTParticipant{},
 		&models.TFTUnit{},
@@ -213,6 +445,10 @@ func runMigrations(db *gorm.DB) error {
 		// Analytics models
 		&models.MatchData{},
 		&models.PlayerStats{},
+		&models.ChampionStats{},
+		&models.SeasonArchive{},
+		&models.AnalyticsSnapshot{},
+		&models.ChampionAggregate{},
 		&models.KDAAnalysis{},
 		&models.CSAnalysis{},
 		// Damage models
@@ -354,3 +590,20 @@ func corsMiddleware() gin.HandlerFunc {
 		c.Next()
 	})
 }
+
+// refreshStaticData downloads Riot's queue list and DataDragon's current patch,
+// champion names and item names, falling back to the copies cached on disk
+// and keeping the previous data when neither is available
+func refreshStaticData(riotService *services.RiotService) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := riotService.RefreshQueues(ctx); err != nil {
+		logger.Warnf("Failed to refresh queue names, using built-in list: %v", err)
+	}
+	if err := riotService.RefreshChampionNames(ctx); err != nil {
+		logger.Warnf("Failed to refresh patch and champion names: %v", err)
+	}
+	if err := riotService.RefreshItemNames(ctx); err != nil {
+		logger.Warnf("Failed to refresh item names: %v", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"time"
 
 	"gorm.io/gorm"
@@ -484,3 +485,46 @@ func (s *DatabaseUserStore) InvalidateGamingSession(ctx context.Context, session
 
 	return nil
 }
+
+// CleanupExpiredGamingSessions removes sessions whose refresh window ended
+// more than gracePeriod ago, as well as invalidated sessions older than it
+func (s *DatabaseUserStore) CleanupExpiredGamingSessions(ctx context.Context, gracePeriod time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-gracePeriod)
+
+	result := s.db.WithContext(ctx).
+		Where("refresh_expires_at < ? OR (is_active = ? AND updated_at < ?)", cutoff, false, cutoff).
+		Delete(&GamingUserSession{})
+
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to cleanup gaming sessions: %w", result.Error)
+	}
+
+	return result.RowsAffected, nil
+}
+
+// StartGamingSessionCleanup periodically purges expired gaming sessions until
+// ctx is done. A non-positive interval disables the cleanup.
+func (s *DatabaseUserStore) StartGamingSessionCleanup(ctx context.Context, interval, gracePeriod time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := s.CleanupExpiredGamingSessions(ctx, gracePeriod)
+			if err != nil {
+				log.Printf("Gaming session cleanup failed: %v", err)
+				continue
+			}
+			if removed > 0 {
+				log.Printf("🧹 Removed %d expired gaming sessions", removed)
+			}
+		}
+	}
+}
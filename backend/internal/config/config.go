@@ -1,9 +1,12 @@
 package config
 
 import (
+	"crypto/tls"
+	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
@@ -11,13 +14,17 @@ import (
 
 // Config holds all configuration for the application
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
-	Riot     RiotConfig     `mapstructure:"riot"`
-	Logging  LoggingConfig  `mapstructure:"logging"`
-	Metrics  MetricsConfig  `mapstructure:"metrics"`
+	Server    ServerConfig    `mapstructure:"server"`
+	Database  DatabaseConfig  `mapstructure:"database"`
+	Redis     RedisConfig     `mapstructure:"redis"`
+	JWT       JWTConfig       `mapstructure:"jwt"`
+	Riot      RiotConfig      `mapstructure:"riot"`
+	Logging   LoggingConfig   `mapstructure:"logging"`
+	Metrics   MetricsConfig   `mapstructure:"metrics"`
+	Cleanup   CleanupConfig   `mapstructure:"cleanup"`
+	Security  SecurityConfig  `mapstructure:"security"`
+	Sync      SyncConfig      `mapstructure:"sync"`
+	Analytics AnalyticsConfig `mapstructure:"analytics"`
 }
 
 type ServerConfig struct {
@@ -28,6 +35,41 @@ type ServerConfig struct {
 	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
 	Environment  string        `mapstructure:"environment"`
 	Debug        bool          `mapstructure:"debug"`
+	TLSCertFile  string        `mapstructure:"tls_cert_file"`
+	TLSKeyFile   string        `mapstructure:"tls_key_file"`
+
+	// Address the gRPC servers listen on (GRPC_HOST, GRPC_PORT)
+	GRPCHost string `mapstructure:"grpc_host"`
+	GRPCPort string `mapstructure:"grpc_port"`
+
+	// Most WebSocket connections one user may hold open
+	// (WEBSOCKET_MAX_CONNECTIONS_PER_USER). The oldest is closed when a new
+	// connection goes over the limit.
+	WebSocketMaxConnsPerUser int `mapstructure:"websocket_max_conns_per_user"`
+
+	// Largest request body accepted, in bytes (MAX_REQUEST_BODY_BYTES).
+	// Larger requests get 413; 0 disables the limit.
+	MaxBodyBytes int64 `mapstructure:"max_body_bytes"`
+
+	// Longest each live probe of GET /api/status may take
+	// (STATUS_CHECK_TIMEOUT), and optional operator text shown in the
+	// frontend's status banner, e.g. a maintenance notice (STATUS_MESSAGE)
+	StatusCheckTimeout time.Duration `mapstructure:"status_check_timeout"`
+	StatusMessage      string        `mapstructure:"status_message"`
+	// How long GET /api/status reuses its last check (STATUS_CACHE_TTL), so
+	// the unauthenticated endpoint can't be used to hammer the database and
+	// cache; 0 checks on every request
+	StatusCacheTTL time.Duration `mapstructure:"status_cache_ttl"`
+
+	// Directory holding the frontend build (STATIC_DIR). Its files are served
+	// for paths outside /api, with index.html as the fallback for client-side
+	// routes.
+	StaticDir string `mapstructure:"static_dir"`
+
+	// Shared secret for operator endpoints under /api/v1/admin (ADMIN_TOKEN),
+	// sent as "Authorization: Bearer <token>". The endpoints are disabled when
+	// it is empty.
+	AdminToken string `mapstructure:"admin_token"`
 }
 
 type DatabaseConfig struct {
@@ -38,6 +80,14 @@ type DatabaseConfig struct {
 	Name     string `mapstructure:"name"`
 	SSLMode  string `mapstructure:"ssl_mode"`
 	Driver   string `mapstructure:"driver"` // sqlite or postgres
+
+	// SQLite concurrency: WAL lets readers proceed during a write, and the
+	// busy timeout makes writers wait for the lock instead of failing
+	SQLiteWAL         bool          `mapstructure:"sqlite_wal"`
+	SQLiteBusyTimeout time.Duration `mapstructure:"sqlite_busy_timeout"`
+
+	// Number of fetched matches written per transaction during sync
+	SyncWriteBatchSize int `mapstructure:"sync_write_batch_size"`
 }
 
 type RedisConfig struct {
@@ -58,11 +108,62 @@ type RiotConfig struct {
 	RateLimitPerMinute int           `mapstructure:"rate_limit_per_minute"`
 	BaseURL            string        `mapstructure:"base_url"`
 	Timeout            time.Duration `mapstructure:"timeout"`
+
+	// Account used to verify the API key works (RIOT_TEST_GAME_NAME,
+	// RIOT_TEST_TAG_LINE, RIOT_TEST_REGION). Defaults to "Hide on bush"#KR1 in kr.
+	TestGameName string `mapstructure:"test_game_name"`
+	TestTagLine  string `mapstructure:"test_tag_line"`
+	TestRegion   string `mapstructure:"test_region"`
+
+	// Platform to regional routing table, also the list of supported regions.
+	// Defaults to DefaultRiotRouting; riot.routing in the config file and
+	// RIOT_ROUTING ("na1=americas,euw1=europe") add or replace entries.
+	Routing map[string]string `mapstructure:"routing"`
+
+	// Cap on Riot requests in flight at once across every caller: syncs, bulk
+	// syncs and exports share it (RIOT_MAX_CONCURRENT_REQUESTS, default 10,
+	// 0 for no cap). A request holds its slot from sending until its body is
+	// closed; rate limit backoff waits happen outside the slot, so retrying
+	// requests never block others.
+	MaxConcurrentRequests int `mapstructure:"max_concurrent_requests"`
+
+	// Platform used for match lookups when the request names no region and
+	// the user has no linked account (RIOT_DEFAULT_REGION, default na1). It
+	// must be in Routing.
+	DefaultRegion string `mapstructure:"default_region"`
+
+	// How often the queue list, current patch and champion names are
+	// re-downloaded (RIOT_STATIC_DATA_REFRESH_INTERVAL, default 6h)
+	StaticDataRefreshInterval time.Duration `mapstructure:"static_data_refresh_interval"`
+
+	// Directory keeping the last downloaded queue list, patch, champion and
+	// item files (RIOT_STATIC_DATA_CACHE_DIR, default ./data/static, empty
+	// disables). When DataDragon is unreachable the cached files are loaded
+	// instead, so names keep resolving across restarts.
+	StaticDataCacheDir string `mapstructure:"static_data_cache_dir"`
+
+	// How long a player's match list is reused before Riot is asked again
+	// (RIOT_MATCH_LIST_CACHE_TTL, default 1m, 0 disables). A newer summoner
+	// revision date drops the cached list early.
+	MatchListCacheTTL time.Duration `mapstructure:"match_list_cache_ttl"`
+
+	// Match archive exports stream matches from the database to the client
+	// through a buffer of ExportBufferSize matches (RIOT_EXPORT_BUFFER_SIZE,
+	// default 50), which is also how many are loaded per query. Matches loaded
+	// but not yet written count against ExportMaxInFlightRows across every
+	// running export (RIOT_EXPORT_MAX_IN_FLIGHT_ROWS, default 500, 0 for no
+	// cap); exports wait for room before loading more.
+	ExportBufferSize      int `mapstructure:"export_buffer_size"`
+	ExportMaxInFlightRows int `mapstructure:"export_max_in_flight_rows"`
 }
 
+// LoggingConfig controls server logging. MaxLineLength (LOG_MAX_LINE_LENGTH,
+// default 4096, 0 for no limit) is the longest message in bytes written in
+// full; longer ones, e.g. an error echoing a huge response body, are cut.
 type LoggingConfig struct {
-	Level  string `mapstructure:"level"`
-	Format string `mapstructure:"format"`
+	Level         string `mapstructure:"level"`
+	Format        string `mapstructure:"format"`
+	MaxLineLength int    `mapstructure:"max_line_length"`
 }
 
 type MetricsConfig struct {
@@ -70,7 +171,158 @@ type MetricsConfig struct {
 	Port    string `mapstructure:"port"`
 }
 
-// Load loads configuration from environment variables and config files
+// CleanupConfig controls how aggressively expired sessions and finished
+// processing jobs are purged from memory and storage.
+//
+// Defaults:
+//
+//	SESSION_CLEANUP_INTERVAL  10m  how often expired sessions are swept
+//	SESSION_GRACE_PERIOD      1h   how long an expired session is kept before removal
+//	JOB_CLEANUP_INTERVAL      10m  how often finished jobs are swept
+//	JOB_RETENTION             1h   how long completed/failed jobs stay queryable
+//	MATCH_RETENTION_MONTHS    0    purge matches older than this many months (0 keeps everything)
+//	MATCH_RETENTION_INTERVAL  24h  how often the match retention purge runs
+//
+// Small instances can lower these values; large deployments that need
+// longer job history can raise JOB_RETENTION. Long-lived self-hosted
+// instances can opt into MATCH_RETENTION_MONTHS; aggregates of purged matches
+// are kept as season archives.
+type CleanupConfig struct {
+	SessionInterval        time.Duration `mapstructure:"session_interval"`
+	SessionGracePeriod     time.Duration `mapstructure:"session_grace_period"`
+	JobInterval            time.Duration `mapstructure:"job_interval"`
+	JobRetention           time.Duration `mapstructure:"job_retention"`
+	MatchRetentionMonths   int           `mapstructure:"match_retention_months"`
+	MatchRetentionInterval time.Duration `mapstructure:"match_retention_interval"`
+}
+
+// SecurityConfig controls response hardening headers and TLS.
+// Set SECURITY_HEADERS_ENABLED=false to turn the headers off in development.
+type SecurityConfig struct {
+	HeadersEnabled        bool          `mapstructure:"headers_enabled"`
+	HSTSMaxAge            time.Duration `mapstructure:"hsts_max_age"`
+	ContentSecurityPolicy string        `mapstructure:"content_security_policy"` // empty uses the built-in SPA policy
+	MinTLSVersion         string        `mapstructure:"min_tls_version"`         // "1.2" or "1.3"
+}
+
+// DefaultMaxGameCount is the MAX_GAME_COUNT used when it is not set
+const DefaultMaxGameCount = 1000
+
+// SyncConfig controls background match syncing (AUTO_SYNC_ENABLED,
+// AUTO_SYNC_INTERVAL, AUTO_SYNC_WORKERS). Workers share the Riot rate
+// limiters, so raising the pool size does not raise API usage past the limits.
+// PrecomputeAnalytics (SYNC_PRECOMPUTE_ANALYTICS) warms the user's common
+// analytics in the background whenever a sync stores new matches.
+// MaxGameCount (MAX_GAME_COUNT) is the most matches a single sync or export
+// may request, protecting the Riot rate budget.
+// RateLimitRetries (SYNC_RATE_LIMIT_RETRIES) is how many times a Riot request
+// answered with 429 is retried, waiting Retry-After or RateLimitBackoff
+// (SYNC_RATE_LIMIT_BACKOFF) doubled per attempt.
+// FetchTimelines (SYNC_FETCH_TIMELINES) also downloads each new match's
+// timeline to record ward placements for vision heatmaps and per-minute
+// gold, CS and XP for the match timeline charts, one extra Riot request per
+// match. It is off by default so syncs keep to the match budget.
+// LockTimeout (SYNC_LOCK_TIMEOUT) is how long a user's sync lock is held
+// before it is treated as abandoned and a new sync may start; 0 never expires
+// it.
+type SyncConfig struct {
+	AutoSyncEnabled     bool          `mapstructure:"auto_sync_enabled"`
+	AutoSyncInterval    time.Duration `mapstructure:"auto_sync_interval"`
+	AutoSyncWorkers     int           `mapstructure:"auto_sync_workers"`
+	PrecomputeAnalytics bool          `mapstructure:"precompute_analytics"`
+	MaxGameCount        int           `mapstructure:"max_game_count"`
+	RateLimitRetries    int           `mapstructure:"rate_limit_retries"`
+	RateLimitBackoff    time.Duration `mapstructure:"rate_limit_backoff"`
+	FetchTimelines      bool          `mapstructure:"fetch_timelines"`
+	LockTimeout         time.Duration `mapstructure:"lock_timeout"`
+}
+
+// AnalyticsConfig tunes analytics refreshes. BatchSize (ANALYTICS_BATCH_SIZE)
+// is the number of matches loaded per page while rebuilding aggregates.
+// MetaMinGames (META_MIN_GAMES) and MetaMinPickRate (META_MIN_PICK_RATE, in
+// percent) keep champions with too small a sample out of the meta tier lists.
+// DuoMinSharedGames (DUO_MIN_SHARED_GAMES) is how many games a teammate must
+// share with a player before they are treated as a duo partner.
+// ComfortWinRate (LEARNING_COMFORT_WIN_RATE, in percent) and ComfortMinGames
+// (LEARNING_COMFORT_MIN_GAMES) mark when a player has learned a champion, the
+// basis of the games-to-comfort estimates on pool-gap recommendations.
+// TimeOfDayBucketHours (TIME_OF_DAY_BUCKET_HOURS) is the default width of the
+// hour-of-day buckets in time-of-day analysis and must divide 24.
+// GradeS, GradeA, GradeB and GradeC (MATCH_GRADE_S, MATCH_GRADE_A,
+// MATCH_GRADE_B, MATCH_GRADE_C) are the lowest 0-100 performance scores graded
+// S to C on match lists and match details; lower scores are graded D. A score
+// of 50 means the player matched their role's benchmarks.
+// WarmupWorkers (ANALYTICS_WARMUP_WORKERS) bounds how many users the admin
+// cache warmup processes at once, and WarmupActiveDays
+// (ANALYTICS_WARMUP_ACTIVE_DAYS) is how recently a user must have logged in to
+// be warmed. StartupWarmup (ANALYTICS_STARTUP_WARMUP, default off) runs the
+// same warmup once StartupWarmupDelay (ANALYTICS_STARTUP_WARMUP_DELAY) after
+// boot for the StartupWarmupUsers (ANALYTICS_STARTUP_WARMUP_USERS) most
+// recently active users, so the first requests after a restart find a warm
+// cache.
+// ChampionPoolThreshold (CHAMPION_POOL_THRESHOLD, in percent) is the share of
+// games the effective champion pool must cover: the pool size is the fewest
+// most-played champions accounting for it.
+// BanMinGames (BAN_MIN_GAMES) is how many of a player's games a champion must
+// have been banned by their team in before its ban win rate is reported.
+// ObjectiveMinGames (OBJECTIVE_MIN_GAMES) is how many games a player needs
+// both with and without a first objective before its win rate impact counts.
+// RecommendationHalfLifeDays (RECOMMENDATION_HALF_LIFE_DAYS) is how many days
+// old a game is when it counts half as much towards champion recommendations.
+// CSBenchmarks is the CS per minute expected in each role, compared with the
+// player's on the CS benchmark report. Defaults to DefaultCSBenchmarks;
+// analytics.cs_benchmarks in the config file and CS_BENCHMARKS
+// ("mid=7.5,support=1.2") replace entries.
+// ChampionAliases adds champion nicknames accepted wherever a champion is
+// named, on top of the built-in ones; analytics.champion_aliases in the
+// config file and CHAMPION_ALIASES ("nami=Nami,sej=Sejuani") add or replace
+// entries.
+type AnalyticsConfig struct {
+	BatchSize         int     `mapstructure:"batch_size"`
+	MetaMinGames      int     `mapstructure:"meta_min_games"`
+	MetaMinPickRate   float64 `mapstructure:"meta_min_pick_rate"`
+	DuoMinSharedGames int     `mapstructure:"duo_min_shared_games"`
+	ComfortWinRate    float64 `mapstructure:"comfort_win_rate"`
+	ComfortMinGames   int     `mapstructure:"comfort_min_games"`
+
+	TimeOfDayBucketHours int `mapstructure:"time_of_day_bucket_hours"`
+
+	GradeS float64 `mapstructure:"grade_s"`
+	GradeA float64 `mapstructure:"grade_a"`
+	GradeB float64 `mapstructure:"grade_b"`
+	GradeC float64 `mapstructure:"grade_c"`
+
+	WarmupWorkers    int `mapstructure:"warmup_workers"`
+	WarmupActiveDays int `mapstructure:"warmup_active_days"`
+
+	StartupWarmup      bool          `mapstructure:"startup_warmup"`
+	StartupWarmupUsers int           `mapstructure:"startup_warmup_users"`
+	StartupWarmupDelay time.Duration `mapstructure:"startup_warmup_delay"`
+
+	ChampionPoolThreshold float64 `mapstructure:"champion_pool_threshold"`
+
+	BanMinGames       int `mapstructure:"ban_min_games"`
+	ObjectiveMinGames int `mapstructure:"objective_min_games"`
+
+	RecommendationHalfLifeDays float64 `mapstructure:"recommendation_half_life_days"`
+
+	CSBenchmarks map[string]float64 `mapstructure:"cs_benchmarks"`
+
+	ChampionAliases map[string]string `mapstructure:"champion_aliases"`
+}
+
+// DefaultCSBenchmarks is the CS per minute expected in each role
+var DefaultCSBenchmarks = map[string]float64{
+	"top":     7.0,
+	"jungle":  5.5,
+	"mid":     7.5,
+	"adc":     8.0,
+	"support": 1.2,
+}
+
+// Load loads configuration from environment variables and config files and
+// validates it. Every binary loads its settings through Load once at startup
+// and passes them on; nothing else reads the environment.
 func Load() (*Config, error) {
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
@@ -96,6 +348,50 @@ func Load() (*Config, error) {
 	// Override with environment variables for critical settings
 	overrideWithEnv(&config)
 
+	if routing := os.Getenv("RIOT_ROUTING"); routing != "" {
+		entries, err := parseRouting(routing)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RIOT_ROUTING: %w", err)
+		}
+		if config.Riot.Routing == nil {
+			config.Riot.Routing = make(map[string]string, len(entries))
+		}
+		for platform, route := range entries {
+			config.Riot.Routing[platform] = route
+		}
+	}
+
+	if benchmarks := os.Getenv("CS_BENCHMARKS"); benchmarks != "" {
+		entries, err := parseCSBenchmarks(benchmarks)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CS_BENCHMARKS: %w", err)
+		}
+		if config.Analytics.CSBenchmarks == nil {
+			config.Analytics.CSBenchmarks = make(map[string]float64, len(entries))
+		}
+		for role, benchmark := range entries {
+			config.Analytics.CSBenchmarks[role] = benchmark
+		}
+	}
+
+	if aliases := os.Getenv("CHAMPION_ALIASES"); aliases != "" {
+		entries, err := parseChampionAliases(aliases)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CHAMPION_ALIASES: %w", err)
+		}
+		if config.Analytics.ChampionAliases == nil {
+			config.Analytics.ChampionAliases = make(map[string]string, len(entries))
+		}
+		for alias, champion := range entries {
+			config.Analytics.ChampionAliases[alias] = champion
+		}
+	}
+
+	// Fail fast on misconfiguration rather than at first use
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
 	return &config, nil
 }
 
@@ -108,6 +404,13 @@ func setDefaults() {
 	viper.SetDefault("server.idle_timeout", "30s")
 	viper.SetDefault("server.environment", "development")
 	viper.SetDefault("server.debug", false)
+	viper.SetDefault("server.websocket_max_conns_per_user", 5)
+	viper.SetDefault("server.max_body_bytes", 1<<20)
+	viper.SetDefault("server.status_check_timeout", "2s")
+	viper.SetDefault("server.status_cache_ttl", "5s")
+	viper.SetDefault("server.static_dir", "./frontend/dist")
+	viper.SetDefault("server.grpc_host", "0.0.0.0")
+	viper.SetDefault("server.grpc_port", "50051")
 
 	// Database defaults
 	viper.SetDefault("database.host", "localhost")
@@ -117,6 +420,9 @@ func setDefaults() {
 	viper.SetDefault("database.name", "herald_dev")
 	viper.SetDefault("database.ssl_mode", "disable")
 	viper.SetDefault("database.driver", "sqlite")
+	viper.SetDefault("database.sqlite_wal", true)
+	viper.SetDefault("database.sqlite_busy_timeout", "5s")
+	viper.SetDefault("database.sync_write_batch_size", 10)
 
 	// Redis defaults
 	viper.SetDefault("redis.host", "localhost")
@@ -133,14 +439,74 @@ func setDefaults() {
 	viper.SetDefault("riot.rate_limit_per_second", 20)
 	viper.SetDefault("riot.rate_limit_per_minute", 100)
 	viper.SetDefault("riot.timeout", "30s")
+	viper.SetDefault("riot.test_game_name", "Hide on bush")
+	viper.SetDefault("riot.test_tag_line", "KR1")
+	viper.SetDefault("riot.test_region", "kr")
+	viper.SetDefault("riot.routing", DefaultRiotRouting)
+	viper.SetDefault("riot.max_concurrent_requests", 10)
+	viper.SetDefault("riot.default_region", "na1")
+	viper.SetDefault("riot.static_data_refresh_interval", "6h")
+	viper.SetDefault("riot.static_data_cache_dir", "./data/static")
+	viper.SetDefault("riot.match_list_cache_ttl", "1m")
+	viper.SetDefault("riot.export_buffer_size", 50)
+	viper.SetDefault("riot.export_max_in_flight_rows", 500)
 
 	// Logging defaults
 	viper.SetDefault("logging.level", "info")
 	viper.SetDefault("logging.format", "json")
+	viper.SetDefault("logging.max_line_length", 4096)
 
 	// Metrics defaults
 	viper.SetDefault("metrics.enabled", true)
 	viper.SetDefault("metrics.port", "9091")
+
+	// Cleanup defaults
+	viper.SetDefault("cleanup.session_interval", "10m")
+	viper.SetDefault("cleanup.session_grace_period", "1h")
+	viper.SetDefault("cleanup.job_interval", "10m")
+	viper.SetDefault("cleanup.job_retention", "1h")
+	viper.SetDefault("cleanup.match_retention_months", 0)
+	viper.SetDefault("cleanup.match_retention_interval", "24h")
+
+	// Security defaults
+	viper.SetDefault("security.headers_enabled", true)
+	viper.SetDefault("security.hsts_max_age", "8760h")
+	viper.SetDefault("security.content_security_policy", "")
+	viper.SetDefault("security.min_tls_version", "1.2")
+
+	// Sync defaults
+	viper.SetDefault("sync.auto_sync_enabled", false)
+	viper.SetDefault("sync.auto_sync_interval", "30m")
+	viper.SetDefault("sync.auto_sync_workers", 4)
+	viper.SetDefault("sync.precompute_analytics", true)
+	viper.SetDefault("sync.max_game_count", DefaultMaxGameCount)
+	viper.SetDefault("sync.rate_limit_retries", 3)
+	viper.SetDefault("sync.rate_limit_backoff", "10s")
+	viper.SetDefault("sync.fetch_timelines", false)
+	viper.SetDefault("sync.lock_timeout", "30m")
+
+	// Analytics defaults
+	viper.SetDefault("analytics.batch_size", 500)
+	viper.SetDefault("analytics.meta_min_games", 100)
+	viper.SetDefault("analytics.meta_min_pick_rate", 0.5)
+	viper.SetDefault("analytics.duo_min_shared_games", 3)
+	viper.SetDefault("analytics.comfort_win_rate", 50.0)
+	viper.SetDefault("analytics.comfort_min_games", 5)
+	viper.SetDefault("analytics.time_of_day_bucket_hours", 1)
+	viper.SetDefault("analytics.grade_s", 80.0)
+	viper.SetDefault("analytics.grade_a", 65.0)
+	viper.SetDefault("analytics.grade_b", 50.0)
+	viper.SetDefault("analytics.grade_c", 35.0)
+	viper.SetDefault("analytics.warmup_workers", 4)
+	viper.SetDefault("analytics.warmup_active_days", 30)
+	viper.SetDefault("analytics.startup_warmup", false)
+	viper.SetDefault("analytics.startup_warmup_users", 100)
+	viper.SetDefault("analytics.startup_warmup_delay", "30s")
+	viper.SetDefault("analytics.champion_pool_threshold", 80.0)
+	viper.SetDefault("analytics.ban_min_games", 3)
+	viper.SetDefault("analytics.objective_min_games", 5)
+	viper.SetDefault("analytics.recommendation_half_life_days", 30.0)
+	viper.SetDefault("analytics.cs_benchmarks", DefaultCSBenchmarks)
 }
 
 func overrideWithEnv(config *Config) {
@@ -148,6 +514,14 @@ func overrideWithEnv(config *Config) {
 		config.Server.Port = port
 	}
 
+	if grpcHost := os.Getenv("GRPC_HOST"); grpcHost != "" {
+		config.Server.GRPCHost = grpcHost
+	}
+
+	if grpcPort := os.Getenv("GRPC_PORT"); grpcPort != "" {
+		config.Server.GRPCPort = grpcPort
+	}
+
 	if env := os.Getenv("ENV"); env != "" {
 		config.Server.Environment = env
 	}
@@ -158,6 +532,42 @@ func overrideWithEnv(config *Config) {
 		}
 	}
 
+	if maxConns := os.Getenv("WEBSOCKET_MAX_CONNECTIONS_PER_USER"); maxConns != "" {
+		if val, err := strconv.Atoi(maxConns); err == nil {
+			config.Server.WebSocketMaxConnsPerUser = val
+		}
+	}
+
+	if maxBody := os.Getenv("MAX_REQUEST_BODY_BYTES"); maxBody != "" {
+		if val, err := strconv.ParseInt(maxBody, 10, 64); err == nil && val >= 0 {
+			config.Server.MaxBodyBytes = val
+		}
+	}
+
+	if statusTimeout := os.Getenv("STATUS_CHECK_TIMEOUT"); statusTimeout != "" {
+		if val, err := time.ParseDuration(statusTimeout); err == nil && val > 0 {
+			config.Server.StatusCheckTimeout = val
+		}
+	}
+
+	if statusCacheTTL := os.Getenv("STATUS_CACHE_TTL"); statusCacheTTL != "" {
+		if val, err := time.ParseDuration(statusCacheTTL); err == nil && val >= 0 {
+			config.Server.StatusCacheTTL = val
+		}
+	}
+
+	if statusMessage := os.Getenv("STATUS_MESSAGE"); statusMessage != "" {
+		config.Server.StatusMessage = statusMessage
+	}
+
+	if staticDir := os.Getenv("STATIC_DIR"); staticDir != "" {
+		config.Server.StaticDir = staticDir
+	}
+
+	if adminToken := os.Getenv("ADMIN_TOKEN"); adminToken != "" {
+		config.Server.AdminToken = adminToken
+	}
+
 	if dbHost := os.Getenv("DB_HOST"); dbHost != "" {
 		config.Database.Host = dbHost
 	}
@@ -178,6 +588,24 @@ func overrideWithEnv(config *Config) {
 		config.Database.Name = dbName
 	}
 
+	if walStr := os.Getenv("DB_SQLITE_WAL"); walStr != "" {
+		if val, err := strconv.ParseBool(walStr); err == nil {
+			config.Database.SQLiteWAL = val
+		}
+	}
+
+	if busyTimeout := os.Getenv("DB_SQLITE_BUSY_TIMEOUT"); busyTimeout != "" {
+		if val, err := time.ParseDuration(busyTimeout); err == nil {
+			config.Database.SQLiteBusyTimeout = val
+		}
+	}
+
+	if batchSize := os.Getenv("DB_SYNC_WRITE_BATCH_SIZE"); batchSize != "" {
+		if val, err := strconv.Atoi(batchSize); err == nil && val > 0 {
+			config.Database.SyncWriteBatchSize = val
+		}
+	}
+
 	if redisHost := os.Getenv("REDIS_HOST"); redisHost != "" {
 		config.Redis.Host = redisHost
 	}
@@ -194,9 +622,286 @@ func overrideWithEnv(config *Config) {
 		config.Riot.APIKey = riotAPIKey
 	}
 
+	if testGameName := os.Getenv("RIOT_TEST_GAME_NAME"); testGameName != "" {
+		config.Riot.TestGameName = testGameName
+	}
+
+	if testTagLine := os.Getenv("RIOT_TEST_TAG_LINE"); testTagLine != "" {
+		config.Riot.TestTagLine = testTagLine
+	}
+
+	if testRegion := os.Getenv("RIOT_TEST_REGION"); testRegion != "" {
+		config.Riot.TestRegion = testRegion
+	}
+
+	if maxConcurrent := os.Getenv("RIOT_MAX_CONCURRENT_REQUESTS"); maxConcurrent != "" {
+		if val, err := strconv.Atoi(maxConcurrent); err == nil && val >= 0 {
+			config.Riot.MaxConcurrentRequests = val
+		}
+	}
+
+	if defaultRegion := os.Getenv("RIOT_DEFAULT_REGION"); defaultRegion != "" {
+		config.Riot.DefaultRegion = strings.ToLower(defaultRegion)
+	}
+
+	if refresh := os.Getenv("RIOT_STATIC_DATA_REFRESH_INTERVAL"); refresh != "" {
+		if val, err := time.ParseDuration(refresh); err == nil && val > 0 {
+			config.Riot.StaticDataRefreshInterval = val
+		}
+	}
+
+	if cacheDir, ok := os.LookupEnv("RIOT_STATIC_DATA_CACHE_DIR"); ok {
+		config.Riot.StaticDataCacheDir = cacheDir
+	}
+
+	if ttl := os.Getenv("RIOT_MATCH_LIST_CACHE_TTL"); ttl != "" {
+		if val, err := time.ParseDuration(ttl); err == nil && val >= 0 {
+			config.Riot.MatchListCacheTTL = val
+		}
+	}
+
+	if bufferSize := os.Getenv("RIOT_EXPORT_BUFFER_SIZE"); bufferSize != "" {
+		if val, err := strconv.Atoi(bufferSize); err == nil && val > 0 {
+			config.Riot.ExportBufferSize = val
+		}
+	}
+
+	if maxRows := os.Getenv("RIOT_EXPORT_MAX_IN_FLIGHT_ROWS"); maxRows != "" {
+		if val, err := strconv.Atoi(maxRows); err == nil && val >= 0 {
+			config.Riot.ExportMaxInFlightRows = val
+		}
+	}
+
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		config.Logging.Level = logLevel
 	}
+
+	if maxLength := os.Getenv("LOG_MAX_LINE_LENGTH"); maxLength != "" {
+		if val, err := strconv.Atoi(maxLength); err == nil && val >= 0 {
+			config.Logging.MaxLineLength = val
+		}
+	}
+
+	if interval := os.Getenv("SESSION_CLEANUP_INTERVAL"); interval != "" {
+		if val, err := time.ParseDuration(interval); err == nil && val > 0 {
+			config.Cleanup.SessionInterval = val
+		}
+	}
+
+	if grace := os.Getenv("SESSION_GRACE_PERIOD"); grace != "" {
+		if val, err := time.ParseDuration(grace); err == nil && val >= 0 {
+			config.Cleanup.SessionGracePeriod = val
+		}
+	}
+
+	if interval := os.Getenv("JOB_CLEANUP_INTERVAL"); interval != "" {
+		if val, err := time.ParseDuration(interval); err == nil && val > 0 {
+			config.Cleanup.JobInterval = val
+		}
+	}
+
+	if retention := os.Getenv("JOB_RETENTION"); retention != "" {
+		if val, err := time.ParseDuration(retention); err == nil && val >= 0 {
+			config.Cleanup.JobRetention = val
+		}
+	}
+
+	if months := os.Getenv("MATCH_RETENTION_MONTHS"); months != "" {
+		if val, err := strconv.Atoi(months); err == nil && val >= 0 {
+			config.Cleanup.MatchRetentionMonths = val
+		}
+	}
+
+	if interval := os.Getenv("MATCH_RETENTION_INTERVAL"); interval != "" {
+		if val, err := time.ParseDuration(interval); err == nil && val > 0 {
+			config.Cleanup.MatchRetentionInterval = val
+		}
+	}
+
+	if enabled := os.Getenv("SECURITY_HEADERS_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			config.Security.HeadersEnabled = val
+		}
+	}
+
+	if csp := os.Getenv("CONTENT_SECURITY_POLICY"); csp != "" {
+		config.Security.ContentSecurityPolicy = csp
+	}
+
+	if minTLS := os.Getenv("MIN_TLS_VERSION"); minTLS != "" {
+		config.Security.MinTLSVersion = minTLS
+	}
+
+	if certFile := os.Getenv("TLS_CERT_FILE"); certFile != "" {
+		config.Server.TLSCertFile = certFile
+	}
+
+	if keyFile := os.Getenv("TLS_KEY_FILE"); keyFile != "" {
+		config.Server.TLSKeyFile = keyFile
+	}
+
+	if enabled := os.Getenv("AUTO_SYNC_ENABLED"); enabled != "" {
+		if val, err := strconv.ParseBool(enabled); err == nil {
+			config.Sync.AutoSyncEnabled = val
+		}
+	}
+
+	if interval := os.Getenv("AUTO_SYNC_INTERVAL"); interval != "" {
+		if val, err := time.ParseDuration(interval); err == nil && val > 0 {
+			config.Sync.AutoSyncInterval = val
+		}
+	}
+
+	if workers := os.Getenv("AUTO_SYNC_WORKERS"); workers != "" {
+		if val, err := strconv.Atoi(workers); err == nil && val > 0 {
+			config.Sync.AutoSyncWorkers = val
+		}
+	}
+
+	if precompute := os.Getenv("SYNC_PRECOMPUTE_ANALYTICS"); precompute != "" {
+		if val, err := strconv.ParseBool(precompute); err == nil {
+			config.Sync.PrecomputeAnalytics = val
+		}
+	}
+
+	if timelines := os.Getenv("SYNC_FETCH_TIMELINES"); timelines != "" {
+		if val, err := strconv.ParseBool(timelines); err == nil {
+			config.Sync.FetchTimelines = val
+		}
+	}
+
+	if maxGames := os.Getenv("MAX_GAME_COUNT"); maxGames != "" {
+		if val, err := strconv.Atoi(maxGames); err == nil && val > 0 {
+			config.Sync.MaxGameCount = val
+		}
+	}
+
+	if retries := os.Getenv("SYNC_RATE_LIMIT_RETRIES"); retries != "" {
+		if val, err := strconv.Atoi(retries); err == nil && val >= 0 {
+			config.Sync.RateLimitRetries = val
+		}
+	}
+
+	if backoff := os.Getenv("SYNC_RATE_LIMIT_BACKOFF"); backoff != "" {
+		if val, err := time.ParseDuration(backoff); err == nil && val > 0 {
+			config.Sync.RateLimitBackoff = val
+		}
+	}
+
+	if lockTimeout := os.Getenv("SYNC_LOCK_TIMEOUT"); lockTimeout != "" {
+		if val, err := time.ParseDuration(lockTimeout); err == nil && val >= 0 {
+			config.Sync.LockTimeout = val
+		}
+	}
+
+	if batchSize := os.Getenv("ANALYTICS_BATCH_SIZE"); batchSize != "" {
+		if val, err := strconv.Atoi(batchSize); err == nil && val > 0 {
+			config.Analytics.BatchSize = val
+		}
+	}
+
+	if minGames := os.Getenv("META_MIN_GAMES"); minGames != "" {
+		if val, err := strconv.Atoi(minGames); err == nil && val >= 0 {
+			config.Analytics.MetaMinGames = val
+		}
+	}
+
+	if minPickRate := os.Getenv("META_MIN_PICK_RATE"); minPickRate != "" {
+		if val, err := strconv.ParseFloat(minPickRate, 64); err == nil && val >= 0 {
+			config.Analytics.MetaMinPickRate = val
+		}
+	}
+
+	if minShared := os.Getenv("DUO_MIN_SHARED_GAMES"); minShared != "" {
+		if val, err := strconv.Atoi(minShared); err == nil && val > 0 {
+			config.Analytics.DuoMinSharedGames = val
+		}
+	}
+
+	if winRate := os.Getenv("LEARNING_COMFORT_WIN_RATE"); winRate != "" {
+		if val, err := strconv.ParseFloat(winRate, 64); err == nil && val > 0 && val <= 100 {
+			config.Analytics.ComfortWinRate = val
+		}
+	}
+
+	if minGames := os.Getenv("LEARNING_COMFORT_MIN_GAMES"); minGames != "" {
+		if val, err := strconv.Atoi(minGames); err == nil && val > 0 {
+			config.Analytics.ComfortMinGames = val
+		}
+	}
+
+	if bucketHours := os.Getenv("TIME_OF_DAY_BUCKET_HOURS"); bucketHours != "" {
+		if val, err := strconv.Atoi(bucketHours); err == nil && val > 0 && val <= 12 && 24%val == 0 {
+			config.Analytics.TimeOfDayBucketHours = val
+		}
+	}
+
+	for env, threshold := range map[string]*float64{
+		"MATCH_GRADE_S": &config.Analytics.GradeS,
+		"MATCH_GRADE_A": &config.Analytics.GradeA,
+		"MATCH_GRADE_B": &config.Analytics.GradeB,
+		"MATCH_GRADE_C": &config.Analytics.GradeC,
+	} {
+		if score := os.Getenv(env); score != "" {
+			if val, err := strconv.ParseFloat(score, 64); err == nil && val >= 0 && val <= 100 {
+				*threshold = val
+			}
+		}
+	}
+
+	if workers := os.Getenv("ANALYTICS_WARMUP_WORKERS"); workers != "" {
+		if val, err := strconv.Atoi(workers); err == nil && val > 0 {
+			config.Analytics.WarmupWorkers = val
+		}
+	}
+
+	if activeDays := os.Getenv("ANALYTICS_WARMUP_ACTIVE_DAYS"); activeDays != "" {
+		if val, err := strconv.Atoi(activeDays); err == nil && val > 0 {
+			config.Analytics.WarmupActiveDays = val
+		}
+	}
+
+	if startupWarmup := os.Getenv("ANALYTICS_STARTUP_WARMUP"); startupWarmup != "" {
+		if val, err := strconv.ParseBool(startupWarmup); err == nil {
+			config.Analytics.StartupWarmup = val
+		}
+	}
+
+	if users := os.Getenv("ANALYTICS_STARTUP_WARMUP_USERS"); users != "" {
+		if val, err := strconv.Atoi(users); err == nil && val > 0 {
+			config.Analytics.StartupWarmupUsers = val
+		}
+	}
+
+	if delay := os.Getenv("ANALYTICS_STARTUP_WARMUP_DELAY"); delay != "" {
+		if val, err := time.ParseDuration(delay); err == nil && val >= 0 {
+			config.Analytics.StartupWarmupDelay = val
+		}
+	}
+
+	if poolThreshold := os.Getenv("CHAMPION_POOL_THRESHOLD"); poolThreshold != "" {
+		if val, err := strconv.ParseFloat(poolThreshold, 64); err == nil && val > 0 && val <= 100 {
+			config.Analytics.ChampionPoolThreshold = val
+		}
+	}
+
+	if minGames := os.Getenv("BAN_MIN_GAMES"); minGames != "" {
+		if val, err := strconv.Atoi(minGames); err == nil && val > 0 {
+			config.Analytics.BanMinGames = val
+		}
+	}
+
+	if minGames := os.Getenv("OBJECTIVE_MIN_GAMES"); minGames != "" {
+		if val, err := strconv.Atoi(minGames); err == nil && val > 0 {
+			config.Analytics.ObjectiveMinGames = val
+		}
+	}
+
+	if halfLife := os.Getenv("RECOMMENDATION_HALF_LIFE_DAYS"); halfLife != "" {
+		if val, err := strconv.ParseFloat(halfLife, 64); err == nil && val > 0 {
+			config.Analytics.RecommendationHalfLifeDays = val
+		}
+	}
 }
 
 // IsDevelopment returns true if the environment is development
@@ -209,11 +914,42 @@ func (c *Config) IsProduction() bool {
 	return c.Server.Environment == "production"
 }
 
+// TLSEnabled returns true when a certificate and key are configured
+func (c *Config) TLSEnabled() bool {
+	return c.Server.TLSCertFile != "" && c.Server.TLSKeyFile != ""
+}
+
+// GetMinTLSVersion returns the configured minimum TLS version, never lower than TLS 1.2
+func (c *Config) GetMinTLSVersion() uint16 {
+	if c.Security.MinTLSVersion == "1.3" {
+		return tls.VersionTLS13
+	}
+	return tls.VersionTLS12
+}
+
+// IsSQLite returns true when the SQLite driver is in use (the default)
+func (c *Config) IsSQLite() bool {
+	return c.Database.Driver != "postgres"
+}
+
+// sqliteDSN returns the SQLite path with the WAL, busy timeout and immediate
+// transaction locking options for go-sqlite3
+func (c *Config) sqliteDSN() string {
+	params := []string{"_txlock=immediate"}
+	if c.Database.SQLiteWAL {
+		params = append(params, "_journal_mode=WAL")
+	}
+	if c.Database.SQLiteBusyTimeout > 0 {
+		params = append(params, "_busy_timeout="+strconv.FormatInt(c.Database.SQLiteBusyTimeout.Milliseconds(), 10))
+	}
+	return "./herald.db?" + strings.Join(params, "&")
+}
+
 // GetDatabaseDSN returns the database DSN string
 func (c *Config) GetDatabaseDSN() string {
 	switch c.Database.Driver {
 	case "sqlite":
-		return "./herald.db"
+		return c.sqliteDSN()
 	case "postgres":
 		return "host=" + c.Database.Host +
 			" port=" + c.Database.Port +
@@ -222,7 +958,7 @@ func (c *Config) GetDatabaseDSN() string {
 			" dbname=" + c.Database.Name +
 			" sslmode=" + c.Database.SSLMode
 	default:
-		return "./herald.db"
+		return c.sqliteDSN()
 	}
 }
 
@@ -230,3 +966,42 @@ func (c *Config) GetDatabaseDSN() string {
 func (c *Config) GetRedisAddr() string {
 	return c.Redis.Host + ":" + c.Redis.Port
 }
+
+// parseChampionAliases reads "alias=champion" pairs separated by commas
+func parseChampionAliases(value string) (map[string]string, error) {
+	aliases := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		alias, champion, ok := strings.Cut(pair, "=")
+		alias, champion = strings.TrimSpace(alias), strings.TrimSpace(champion)
+		if !ok || alias == "" || champion == "" {
+			return nil, fmt.Errorf("invalid champion alias entry %q, expected alias=champion", pair)
+		}
+		aliases[strings.ToLower(alias)] = champion
+	}
+	return aliases, nil
+}
+
+// parseCSBenchmarks reads "role=cs_per_min" pairs separated by commas
+func parseCSBenchmarks(value string) (map[string]float64, error) {
+	benchmarks := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		role, benchmark, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid benchmark entry %q, expected role=cs_per_min", pair)
+		}
+		val, err := strconv.ParseFloat(strings.TrimSpace(benchmark), 64)
+		if err != nil || val <= 0 {
+			return nil, fmt.Errorf("invalid benchmark entry %q, CS per minute must be a positive number", pair)
+		}
+		benchmarks[strings.ToLower(strings.TrimSpace(role))] = val
+	}
+	return benchmarks, nil
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultRiotRouting maps each supported platform to its regional
+// (continental) routing value. Override it with riot.routing in the config
+// file or RIOT_ROUTING="na1=americas,me1=europe" when Riot adds or moves a
+// platform.
+var DefaultRiotRouting = map[string]string{
+	"na1":  "americas",
+	"br1":  "americas",
+	"la1":  "americas",
+	"la2":  "americas",
+	"oc1":  "americas",
+	"euw1": "europe",
+	"eun1": "europe",
+	"tr1":  "europe",
+	"ru":   "europe",
+	"kr":   "asia",
+	"jp1":  "asia",
+	"ph2":  "asia",
+	"sg2":  "asia",
+	"th2":  "asia",
+	"tw2":  "asia",
+	"vn2":  "asia",
+}
+
+// RegionalRoutes are the routing values Riot accepts for regional endpoints
+var RegionalRoutes = []string{"americas", "europe", "asia", "sea", "esports"}
+
+var platformPattern = regexp.MustCompile(`^[a-z]+[0-9]*$`)
+
+// ValidateRouting checks that every platform has a well-formed name and
+// routes to a known regional value
+func (r RiotConfig) ValidateRouting() error {
+	if len(r.Routing) == 0 {
+		return fmt.Errorf("no platforms configured")
+	}
+	for platform, route := range r.Routing {
+		if !platformPattern.MatchString(platform) {
+			return fmt.Errorf("invalid platform %q", platform)
+		}
+		if !IsRegionalRoute(route) {
+			return fmt.Errorf("platform %s routes to unknown region %q, use one of %s", platform, route, strings.Join(RegionalRoutes, ", "))
+		}
+	}
+	return nil
+}
+
+// SupportedRegions returns the configured platforms in sorted order
+func (r RiotConfig) SupportedRegions() []string {
+	regions := make([]string, 0, len(r.Routing))
+	for platform := range r.Routing {
+		regions = append(regions, platform)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// IsSupportedRegion reports whether platform is in the routing table
+func (r RiotConfig) IsSupportedRegion(platform string) bool {
+	_, ok := r.Routing[platform]
+	return ok
+}
+
+// RegionalRoute returns the regional routing value for a platform
+func (r RiotConfig) RegionalRoute(platform string) (string, bool) {
+	route, ok := r.Routing[platform]
+	return route, ok
+}
+
+// IsRegionalRoute reports whether route is one of RegionalRoutes
+func IsRegionalRoute(route string) bool {
+	for _, known := range RegionalRoutes {
+		if route == known {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRouting reads "platform=route" pairs separated by commas
+func parseRouting(value string) (map[string]string, error) {
+	routing := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		platform, route, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid routing entry %q, expected platform=region", pair)
+		}
+		routing[strings.ToLower(strings.TrimSpace(platform))] = strings.ToLower(strings.TrimSpace(route))
+	}
+	return routing, nil
+}
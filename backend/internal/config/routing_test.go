@@ -0,0 +1,74 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRouting(t *testing.T) {
+	assert.NoError(t, RiotConfig{Routing: DefaultRiotRouting}.ValidateRouting())
+
+	tests := []struct {
+		name    string
+		routing map[string]string
+		problem string
+	}{
+		{"empty", nil, "no platforms configured"},
+		{"bad platform", map[string]string{"na-1": "americas"}, `invalid platform "na-1"`},
+		{"unknown route", map[string]string{"me1": "middleeast"}, `platform me1 routes to unknown region "middleeast"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RiotConfig{Routing: tt.routing}.ValidateRouting()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.problem)
+		})
+	}
+}
+
+func TestParseRouting(t *testing.T) {
+	routing, err := parseRouting(" ME1 = Europe ,,na1=americas")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"me1": "europe", "na1": "americas"}, routing)
+
+	_, err = parseRouting("me1")
+	assert.ErrorContains(t, err, `invalid routing entry "me1"`)
+}
+
+func TestRoutingLookups(t *testing.T) {
+	riot := RiotConfig{Routing: map[string]string{"kr": "asia", "euw1": "europe", "me1": "europe"}}
+
+	assert.Equal(t, []string{"euw1", "kr", "me1"}, riot.SupportedRegions())
+	assert.True(t, riot.IsSupportedRegion("me1"))
+	assert.False(t, riot.IsSupportedRegion("na1"))
+
+	route, ok := riot.RegionalRoute("kr")
+	assert.True(t, ok)
+	assert.Equal(t, "asia", route)
+	_, ok = riot.RegionalRoute("na1")
+	assert.False(t, ok)
+
+	assert.True(t, IsRegionalRoute("sea"))
+	assert.False(t, IsRegionalRoute("na1"))
+}
+
+func TestLoadRiotRouting(t *testing.T) {
+	t.Setenv("RIOT_ROUTING", "me1=europe,na1=europe")
+
+	cfg, err := Load()
+	require.NoError(t, err)
+	assert.Equal(t, "europe", cfg.Riot.Routing["me1"], "new platforms are added")
+	assert.Equal(t, "europe", cfg.Riot.Routing["na1"], "existing platforms are replaced")
+	assert.Equal(t, "asia", cfg.Riot.Routing["kr"], "other defaults are kept")
+	assert.Equal(t, "americas", DefaultRiotRouting["na1"], "the defaults are not modified")
+
+	t.Setenv("RIOT_ROUTING", "me1=middleeast")
+	_, err = Load()
+	assert.ErrorContains(t, err, `platform me1 routes to unknown region "middleeast"`)
+
+	t.Setenv("RIOT_ROUTING", "me1")
+	_, err = Load()
+	assert.ErrorContains(t, err, "invalid RIOT_ROUTING")
+}
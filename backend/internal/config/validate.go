@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/herald-lol/herald/backend/internal/logger"
+)
+
+// Environments are the accepted values of ENV
+var Environments = []string{"development", "test", "staging", "production"}
+
+// defaultJWTSecret is the placeholder secret production must not run with
+const defaultJWTSecret = "change_me_in_production"
+
+// ValidationError lists every problem found in a configuration, so a
+// misconfigured deploy reports them all at once instead of one per restart
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// Validate checks required keys, port ranges and enum values. Problems name
+// the environment variable that sets the value, or the config file key for
+// settings without one.
+func (c *Config) Validate() error {
+	var problems []string
+	addf := func(format string, args ...any) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if !contains(Environments, c.Server.Environment) {
+		addf("ENV %q is not one of %s", c.Server.Environment, strings.Join(Environments, ", "))
+	}
+	if err := validatePort(c.Server.Port); err != nil {
+		addf("PORT %v", err)
+	}
+	if err := validatePort(c.Server.GRPCPort); err != nil {
+		addf("GRPC_PORT %v", err)
+	}
+	if c.Metrics.Enabled {
+		if err := validatePort(c.Metrics.Port); err != nil {
+			addf("metrics.port %v", err)
+		}
+	}
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		addf("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if v := c.Security.MinTLSVersion; v != "1.2" && v != "1.3" {
+		addf("MIN_TLS_VERSION %q must be 1.2 or 1.3", v)
+	}
+
+	switch c.Database.Driver {
+	case "sqlite":
+	case "postgres":
+		if c.Database.Host == "" {
+			addf("DB_HOST is required with the postgres driver")
+		}
+		if c.Database.Name == "" {
+			addf("DB_NAME is required with the postgres driver")
+		}
+		if c.Database.User == "" {
+			addf("DB_USER is required with the postgres driver")
+		}
+		if err := validatePort(c.Database.Port); err != nil {
+			addf("DB_PORT %v", err)
+		}
+		if !contains([]string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}, c.Database.SSLMode) {
+			addf("database.ssl_mode %q is not a valid postgres sslmode", c.Database.SSLMode)
+		}
+	default:
+		addf("database.driver %q must be sqlite or postgres", c.Database.Driver)
+	}
+
+	if c.Redis.Host != "" {
+		if err := validatePort(c.Redis.Port); err != nil {
+			addf("REDIS_PORT %v", err)
+		}
+	}
+
+	if c.JWT.Secret == "" {
+		addf("JWT_SECRET is required")
+	} else if c.IsProduction() && c.JWT.Secret == defaultJWTSecret {
+		addf("JWT_SECRET must be changed from the default in production")
+	}
+	if c.JWT.Expiration <= 0 {
+		addf("jwt.expiration must be positive")
+	}
+
+	if _, err := logger.ParseLevel(c.Logging.Level); err != nil {
+		addf("LOG_LEVEL %q must be debug, info, warn or error", c.Logging.Level)
+	}
+
+	if err := c.Riot.ValidateRouting(); err != nil {
+		addf("RIOT_ROUTING: %v", err)
+	} else if !c.Riot.IsSupportedRegion(c.Riot.DefaultRegion) {
+		addf("RIOT_DEFAULT_REGION %q is not in the routing table", c.Riot.DefaultRegion)
+	}
+
+	if c.Sync.AutoSyncWorkers < 1 {
+		addf("AUTO_SYNC_WORKERS must be at least 1")
+	}
+	if c.Sync.MaxGameCount < 1 {
+		addf("MAX_GAME_COUNT must be at least 1")
+	}
+
+	a := c.Analytics
+	if !(a.GradeS >= a.GradeA && a.GradeA >= a.GradeB && a.GradeB >= a.GradeC) {
+		addf("MATCH_GRADE_S, MATCH_GRADE_A, MATCH_GRADE_B and MATCH_GRADE_C must be in descending order")
+	}
+	if h := a.TimeOfDayBucketHours; h < 1 || h > 12 || 24%h != 0 {
+		addf("TIME_OF_DAY_BUCKET_HOURS %d must divide 24", h)
+	}
+	if p := a.ChampionPoolThreshold; p <= 0 || p > 100 {
+		addf("CHAMPION_POOL_THRESHOLD %g must be above 0 and at most 100", p)
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// validatePort checks that port is a TCP port number
+func validatePort(port string) error {
+	n, err := strconv.Atoi(port)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", port)
+	}
+	if n < 1 || n > 65535 {
+		return fmt.Errorf("%d is outside 1-65535", n)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
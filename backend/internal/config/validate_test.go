@@ -0,0 +1,61 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() *Config {
+	return &Config{
+		Server:    ServerConfig{Port: "8080", GRPCPort: "50051", Environment: "development"},
+		Database:  DatabaseConfig{Driver: "sqlite"},
+		Redis:     RedisConfig{Host: "localhost", Port: "6379"},
+		JWT:       JWTConfig{Secret: defaultJWTSecret, Expiration: 24 * time.Hour},
+		Riot:      RiotConfig{Routing: DefaultRiotRouting, DefaultRegion: "na1"},
+		Logging:   LoggingConfig{Level: "info"},
+		Metrics:   MetricsConfig{Enabled: true, Port: "9091"},
+		Security:  SecurityConfig{MinTLSVersion: "1.2"},
+		Sync:      SyncConfig{AutoSyncWorkers: 4, MaxGameCount: 1000},
+		Analytics: AnalyticsConfig{GradeS: 80, GradeA: 65, GradeB: 50, GradeC: 35, TimeOfDayBucketHours: 1, ChampionPoolThreshold: 80},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, validConfig().Validate())
+
+	cfg := validConfig()
+	cfg.Server.Environment = "production"
+	cfg.Server.Port = "80800"
+	cfg.Database.Driver = "mysql"
+	cfg.Logging.Level = "verbose"
+	cfg.Server.TLSCertFile = "cert.pem"
+
+	err := cfg.Validate()
+	require.Error(t, err)
+
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Len(t, validationErr.Problems, 5)
+	assert.Contains(t, err.Error(), "PORT 80800 is outside 1-65535")
+	assert.Contains(t, err.Error(), `database.driver "mysql" must be sqlite or postgres`)
+	assert.Contains(t, err.Error(), "JWT_SECRET must be changed from the default in production")
+	assert.Contains(t, err.Error(), "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	assert.True(t, strings.HasPrefix(err.Error(), "invalid configuration:"))
+}
+
+func TestValidatePostgres(t *testing.T) {
+	cfg := validConfig()
+	cfg.Database = DatabaseConfig{Driver: "postgres", Port: "5432", SSLMode: "disable"}
+
+	err := cfg.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "DB_HOST is required")
+	assert.Contains(t, err.Error(), "DB_NAME is required")
+
+	cfg.Database.Host, cfg.Database.Name, cfg.Database.User = "db", "herald", "herald"
+	assert.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,351 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Herald.lol Gaming Analytics - Bundle Export
+// Single zip archive with matches plus computed analytics for archival
+
+// manifestFileName is the bundle entry holding the archive's manifest
+const manifestFileName = "manifest.json"
+
+// bundleWriter adds files to a bundle archive and records each one for the
+// manifest
+type bundleWriter struct {
+	archive *zip.Writer
+	files   []ManifestFile
+}
+
+func newBundleWriter(buffer *bytes.Buffer) *bundleWriter {
+	return &bundleWriter{archive: zip.NewWriter(buffer)}
+}
+
+func (w *bundleWriter) write(name string, content []byte) error {
+	if err := writeBundleFile(w.archive, name, content); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(content)
+	w.files = append(w.files, ManifestFile{
+		Name:   name,
+		Size:   len(content),
+		SHA256: hex.EncodeToString(sum[:]),
+	})
+	return nil
+}
+
+// close writes manifest.json, listing every file written so far, and
+// finalizes the archive. The manifest does not list itself.
+func (w *bundleWriter) close(manifest *ExportManifest) error {
+	manifest.Files = w.files
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := writeBundleFile(w.archive, manifestFileName, content); err != nil {
+		return err
+	}
+	if err := w.archive.Close(); err != nil {
+		return fmt.Errorf("failed to finalize bundle: %w", err)
+	}
+	return nil
+}
+
+// exportPlayerBundle writes the player's (filtered) matches together with the
+// period summary, champion and role stats and trends as separate files, plus
+// a manifest of those files
+func (s *ExportService) exportPlayerBundle(data *PlayerExportData, request *PlayerExportRequest) ([]byte, string, *ExportManifest, error) {
+	var buffer bytes.Buffer
+	archive := newBundleWriter(&buffer)
+
+	matchesCSV, _, err := s.csvProcessor.ExportPlayerData(data, request)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("failed to export matches: %w", err)
+	}
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"matches.json", data.Matches},
+		{"summary.json", map[string]interface{}{
+			"player_info": data.PlayerInfo,
+			"summary":     data.Summary,
+			"time_range":  data.TimeRange,
+			"total_games": data.TotalGames,
+			"filter":      request.Filter,
+			"exported_at": data.ExportedAt,
+		}},
+		{"champion_stats.json", data.ChampionStats},
+		{"role_stats.json", data.RoleStats},
+		{"trends.json", data.TrendAnalysis},
+		{"achievements.json", data.Achievements},
+	}
+
+	if err := archive.write("matches.csv", matchesCSV); err != nil {
+		return nil, "", nil, err
+	}
+
+	for _, file := range files {
+		content, err := json.MarshalIndent(file.data, "", "  ")
+		if err != nil {
+			return nil, "", nil, fmt.Errorf("failed to marshal %s: %w", file.name, err)
+		}
+		if err := archive.write(file.name, content); err != nil {
+			return nil, "", nil, err
+		}
+	}
+
+	championCSV, err := championStatsCSV(data.ChampionStats)
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if err := archive.write("champion_stats.csv", championCSV); err != nil {
+		return nil, "", nil, err
+	}
+
+	manifest := &ExportManifest{
+		PlayerPUUID:  request.PlayerPUUID,
+		SummonerName: data.PlayerInfo.SummonerName,
+		TimeRange:    request.TimeRange,
+		Filter:       request.Filter,
+		ExportedAt:   data.ExportedAt,
+		RowCount:     len(data.Matches),
+	}
+	if err := archive.close(manifest); err != nil {
+		return nil, "", nil, err
+	}
+
+	fileName := fmt.Sprintf("%s_bundle_%s.zip",
+		data.PlayerInfo.SummonerName,
+		time.Now().Format("2006-01-02"))
+
+	return buffer.Bytes(), fileName, manifest, nil
+}
+
+// ExportGroupAnalytics bundles each member's period summary and champion
+// stats into one zip, with a folder per member and a group overview
+func (s *ExportService) ExportGroupAnalytics(ctx context.Context, request *GroupExportRequest) (*ExportResult, error) {
+	if err := validateGroupExportRequest(request); err != nil {
+		return nil, fmt.Errorf("invalid group export request: %w", err)
+	}
+	if err := s.validateExportPassword(request.Password); err != nil {
+		return nil, fmt.Errorf("invalid group export request: %w", err)
+	}
+
+	var buffer bytes.Buffer
+	archive := newBundleWriter(&buffer)
+
+	overview := make([]map[string]interface{}, 0, len(request.Members))
+	dataPoints := 0
+	for i, member := range request.Members {
+		memberRequest := &PlayerExportRequest{
+			PlayerPUUID:  member.PlayerPUUID,
+			SummonerName: member.SummonerName,
+			Region:       member.Region,
+			Format:       "bundle",
+			TimeRange:    request.TimeRange,
+			Filter:       request.Filter,
+		}
+		if err := s.validatePlayerExportRequest(memberRequest); err != nil {
+			return nil, fmt.Errorf("invalid member %s: %w", member.PlayerPUUID, err)
+		}
+
+		data, err := s.collectPlayerData(ctx, memberRequest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect data for %s: %w", member.PlayerPUUID, err)
+		}
+		dataPoints += len(data.Matches)
+
+		folder := groupMemberFolder(i, member)
+		summary, err := json.MarshalIndent(map[string]interface{}{
+			"player_info": data.PlayerInfo,
+			"summary":     data.Summary,
+			"time_range":  data.TimeRange,
+			"total_games": data.TotalGames,
+		}, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal summary for %s: %w", member.PlayerPUUID, err)
+		}
+		if err := archive.write(folder+"/summary.json", summary); err != nil {
+			return nil, err
+		}
+
+		championCSV, err := championStatsCSV(data.ChampionStats)
+		if err != nil {
+			return nil, err
+		}
+		if err := archive.write(folder+"/champion_stats.csv", championCSV); err != nil {
+			return nil, err
+		}
+
+		overview = append(overview, map[string]interface{}{
+			"player_puuid":  member.PlayerPUUID,
+			"summoner_name": member.SummonerName,
+			"folder":        folder,
+			"total_games":   data.TotalGames,
+			"summary":       data.Summary,
+		})
+	}
+
+	exportedAt := time.Now()
+	overviewJSON, err := json.MarshalIndent(map[string]interface{}{
+		"group_name":  request.GroupName,
+		"time_range":  request.TimeRange,
+		"members":     overview,
+		"exported_at": exportedAt,
+	}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal group overview: %w", err)
+	}
+	if err := archive.write("group.json", overviewJSON); err != nil {
+		return nil, err
+	}
+
+	manifest := &ExportManifest{
+		GroupName:  request.GroupName,
+		TimeRange:  request.TimeRange,
+		Filter:     request.Filter,
+		ExportedAt: exportedAt,
+		RowCount:   dataPoints,
+	}
+	if err := archive.close(manifest); err != nil {
+		return nil, err
+	}
+
+	exportID := s.generateExportID()
+	fileName := fmt.Sprintf("%s_group_%s.zip", request.GroupName, time.Now().Format("2006-01-02"))
+	content := buffer.Bytes()
+	if request.Password != "" {
+		content, fileName, err = encryptArchive(fileName, content, request.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encrypt export: %w", err)
+		}
+	}
+	downloadURL := s.storeExport(exportID, fileName, content)
+
+	result := &ExportResult{
+		ExportID:    exportID,
+		Format:      "bundle",
+		FileSize:    len(content),
+		Status:      "completed",
+		DownloadURL: downloadURL,
+		CreatedAt:   time.Now(),
+		ExpiresAt:   time.Now().Add(s.config.ExportTTL),
+		Metadata: &ExportMetadata{
+			GroupName:  request.GroupName,
+			TimeRange:  request.TimeRange,
+			DataPoints: dataPoints,
+			Encrypted:  request.Password != "",
+		},
+		Manifest: manifest,
+	}
+
+	// Group exports are not served from the cache, the entry only lets
+	// GetExportStatus report the job and its manifest
+	s.exportCacheMu.Lock()
+	s.exportCache[s.generateCacheKey("group", exportID, "bundle", request.TimeRange)] = &CachedExport{
+		ExportID:    exportID,
+		UserID:      request.UserID,
+		Format:      "bundle",
+		FileSize:    result.FileSize,
+		DownloadURL: downloadURL,
+		CreatedAt:   result.CreatedAt,
+		ExpiresAt:   result.ExpiresAt,
+		Manifest:    manifest,
+	}
+	s.exportCacheMu.Unlock()
+
+	return result, nil
+}
+
+func validateGroupExportRequest(request *GroupExportRequest) error {
+	if request.GroupName == "" {
+		return fmt.Errorf("group name is required")
+	}
+	if len(request.Members) == 0 {
+		return fmt.Errorf("at least one member is required")
+	}
+	if len(request.Members) > MaxGroupExportMembers {
+		return fmt.Errorf("at most %d members can be exported at once", MaxGroupExportMembers)
+	}
+	if request.TimeRange == "" {
+		return fmt.Errorf("time range is required")
+	}
+	return nil
+}
+
+// groupMemberFolder names a member's folder, numbered to keep duplicate
+// summoner names apart
+func groupMemberFolder(index int, member GroupMember) string {
+	name := member.SummonerName
+	if name == "" {
+		name = member.PlayerPUUID
+	}
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, name)
+	return fmt.Sprintf("%02d_%s", index+1, name)
+}
+
+func writeBundleFile(archive *zip.Writer, name string, content []byte) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s to bundle: %w", name, err)
+	}
+	if _, err := file.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+func championStatsCSV(stats map[string]*ChampionStats) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	writer.Write([]string{"Champion", "Games", "Win Rate", "KDA", "CS", "Damage", "Play Rate", "Performance"})
+
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		champion := stats[name]
+		if champion == nil {
+			continue
+		}
+		writer.Write([]string{
+			csvText(champion.ChampionName),
+			strconv.Itoa(champion.GamesPlayed),
+			fmt.Sprintf("%.1f", champion.WinRate),
+			fmt.Sprintf("%.2f", champion.AverageKDA),
+			fmt.Sprintf("%.1f", champion.AverageCS),
+			strconv.Itoa(champion.AverageDamage),
+			fmt.Sprintf("%.1f", champion.PlayRate),
+			champion.Performance,
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write champion stats CSV: %w", err)
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -1,6 +1,10 @@
 package export
 
-import "time"
+import (
+	"time"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+)
 
 // Herald.lol Gaming Analytics - Export Service Configuration
 // Configuration settings for multi-format data export
@@ -14,8 +18,15 @@ type ExportConfig struct {
 	MaxFileSize       int64         `json:"max_file_size"` // In bytes
 	MaxConcurrentJobs int           `json:"max_concurrent_jobs"`
 	CleanupInterval   time.Duration `json:"cleanup_interval"`
+	MaxDownloadAge    time.Duration `json:"max_download_age"` // stored files older than this are served as 410 Gone
+	MaxGameCount      int           `json:"max_game_count"`   // most matches a player export may request (MAX_GAME_COUNT)
+
+	// MergeSharedMatches collapses a match played together by several linked
+	// accounts into one row in multi-account exports
+	MergeSharedMatches bool `json:"merge_shared_matches"`
 
-	// Storage settings
+	// Storage settings. StoragePath, when set, keeps a local copy of each
+	// export for download checks; empty keeps none.
 	StoragePath  string        `json:"storage_path"`
 	CDNBaseURL   string        `json:"cdn_base_url"`
 	SignedURLTTL time.Duration `json:"signed_url_ttl"`
@@ -101,6 +112,12 @@ type PDFConfig struct {
 	// Chart settings
 	ChartDPI    int  `json:"chart_dpi"`
 	EmbedCharts bool `json:"embed_charts"`
+
+	// Player summary report; counts at 0 use the report defaults
+	ReportsEnabled     bool `json:"reports_enabled"`
+	ReportTopChampions int  `json:"report_top_champions"`
+	ReportRecentGames  int  `json:"report_recent_games"`
+	ReportTrendWindow  int  `json:"report_trend_window"`
 }
 
 // ChartsConfig contains chart export configuration
@@ -195,6 +212,7 @@ type ExportSecuritySettings struct {
 	EnableEncryption    bool          `json:"enable_encryption"`
 	EncryptionAlgorithm string        `json:"encryption_algorithm"`
 	KeyRotationInterval time.Duration `json:"key_rotation_interval"`
+	MinPasswordLength   int           `json:"min_password_length"` // for password-protected exports
 
 	// Content security
 	DisallowSensitiveData bool     `json:"disallow_sensitive_data"`
@@ -220,8 +238,12 @@ func GetDefaultExportConfig() *ExportConfig {
 		MaxFileSize:       100 * 1024 * 1024, // 100MB
 		MaxConcurrentJobs: 10,
 		CleanupInterval:   1 * time.Hour,
+		MaxDownloadAge:    24 * time.Hour,
+		MaxGameCount:      config.DefaultMaxGameCount,
 
-		StoragePath:  "/var/herald/exports",
+		MergeSharedMatches: true,
+
+		StoragePath:  "", // local copies are opt-in
 		CDNBaseURL:   "https://cdn.herald.lol/exports",
 		SignedURLTTL: 4 * time.Hour,
 
@@ -286,6 +308,11 @@ func GetDefaultExportConfig() *ExportConfig {
 			DefaultFontSize:    11,
 			MaxPages:           200,
 
+			ReportsEnabled:     true,
+			ReportTopChampions: DefaultReportTopChampions,
+			ReportRecentGames:  DefaultReportRecentGames,
+			ReportTrendWindow:  DefaultReportTrendWindow,
+
 			DefaultMargins: &PDFMargins{
 				Top:    25,
 				Bottom: 25,
@@ -357,6 +384,7 @@ func GetDefaultExportConfig() *ExportConfig {
 			EnableEncryption:    false,
 			EncryptionAlgorithm: "AES-256-GCM",
 			KeyRotationInterval: 30 * 24 * time.Hour, // 30 days
+			MinPasswordLength:   DefaultMinExportPasswordLength,
 
 			DisallowSensitiveData: true,
 			SensitiveFields:       []string{"email", "ip_address", "device_id"},
@@ -372,6 +400,16 @@ func GetDefaultExportConfig() *ExportConfig {
 	}
 }
 
+// NewExportConfig returns the default configuration with the match cap
+// shared with syncs (MAX_GAME_COUNT) taken from cfg
+func NewExportConfig(cfg *config.Config) *ExportConfig {
+	exportConfig := GetDefaultExportConfig()
+	if cfg != nil && cfg.Sync.MaxGameCount > 0 {
+		exportConfig.MaxGameCount = cfg.Sync.MaxGameCount
+	}
+	return exportConfig
+}
+
 // GetExportConfigByProfile returns configuration optimized for different user profiles
 func GetExportConfigByProfile(profile string) *ExportConfig {
 	baseConfig := GetDefaultExportConfig()
@@ -384,6 +422,7 @@ func GetExportConfigByProfile(profile string) *ExportConfig {
 		baseConfig.XLSX.MaxRows = 1000
 		baseConfig.XLSX.EnableCharts = false
 		baseConfig.PDF.MaxPages = 10
+		baseConfig.PDF.ReportsEnabled = false // Premium feature
 		baseConfig.Charts.EnableInteractivity = false
 		baseConfig.SecuritySettings.RateLimitPerUser = 5 // Per hour
 
@@ -486,6 +525,19 @@ func GetFormatCapabilities() map[string]*FormatCapabilities {
 			CompressionRatio:   0.7, // 70% of original size when compressed
 			AverageGenTime:     6,   // seconds
 		},
+		"bundle": {
+			Name:               "Archive Bundle",
+			SupportsCharts:     false,
+			SupportsFormatting: false,
+			SupportsMultiSheet: true,
+			SupportsImages:     false,
+			MaxFileSize:        100 * 1024 * 1024, // 100MB
+			MaxRows:            100000,
+			MaxColumns:         1000,
+			StreamingSupport:   false,
+			CompressionRatio:   1.0, // already zipped
+			AverageGenTime:     4,   // seconds
+		},
 	}
 }
 
@@ -534,7 +586,7 @@ func GetSubscriptionExportLimits() map[string]*SubscriptionLimits {
 			MaxExportsPerDay:   100,
 			MaxExportsPerMonth: 2000,
 			MaxFileSize:        100 * 1024 * 1024, // 100MB
-			AllowedFormats:     []string{"csv", "json", "xlsx", "pdf"},
+			AllowedFormats:     []string{"csv", "json", "xlsx", "pdf", "bundle"},
 			MaxDataRows:        50000,
 			ChartsEnabled:      true,
 			Priority:           "high",
@@ -545,7 +597,7 @@ func GetSubscriptionExportLimits() map[string]*SubscriptionLimits {
 			MaxExportsPerDay:   1000,
 			MaxExportsPerMonth: 20000,
 			MaxFileSize:        500 * 1024 * 1024, // 500MB
-			AllowedFormats:     []string{"csv", "json", "xlsx", "pdf", "charts"},
+			AllowedFormats:     []string{"csv", "json", "xlsx", "pdf", "charts", "bundle"},
 			MaxDataRows:        1000000,
 			ChartsEnabled:      true,
 			Priority:           "highest",
@@ -7,10 +7,16 @@ import (
 	"crypto/rand"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+	"github.com/herald-lol/herald/backend/internal/logger"
+	"github.com/herald-lol/herald/backend/internal/models"
 )
 
 // Herald.lol Gaming Analytics - Export Service Helper Methods
@@ -31,18 +37,62 @@ func (s *ExportService) validatePlayerExportRequest(request *PlayerExportRequest
 		return fmt.Errorf("unsupported format: %s", request.Format)
 	}
 
+	if err := validateExportOptions(request.Format, request.ExportOptions); err != nil {
+		return err
+	}
+
 	if request.TimeRange == "" {
 		return fmt.Errorf("time range is required")
 	}
 
+	gameCount, err := resolveGameCount(request.GameCount, s.maxGameCount())
+	if err != nil {
+		return err
+	}
+	request.GameCount = gameCount
+
+	if err := validateExportFilter(request.Filter); err != nil {
+		return fmt.Errorf("invalid filter: %w", err)
+	}
+
+	if err := validateLinkedAccounts(request); err != nil {
+		return fmt.Errorf("invalid linked accounts: %w", err)
+	}
+
+	if err := s.validateExportPassword(request.Password); err != nil {
+		return err
+	}
+
 	// Validate subscription limits
-	if err := s.validateSubscriptionLimits(request.PlayerPUUID, request.Format); err != nil {
+	if err := s.validateSubscriptionLimits(request.SubscriptionTier, request.Format); err != nil {
 		return fmt.Errorf("subscription limit exceeded: %w", err)
 	}
 
 	return nil
 }
 
+// maxGameCount returns the configured cap on matches per player export
+func (s *ExportService) maxGameCount() int {
+	if s.config != nil && s.config.MaxGameCount > 0 {
+		return s.config.MaxGameCount
+	}
+	return config.DefaultMaxGameCount
+}
+
+// resolveGameCount applies the default to an unset game_count and checks it
+// against maxGameCount
+func resolveGameCount(gameCount, maxGameCount int) (int, error) {
+	if gameCount == 0 {
+		return min(DefaultExportGameCount, maxGameCount), nil
+	}
+
+	if gameCount < 1 || gameCount > maxGameCount {
+		return 0, fmt.Errorf("game_count must be between 1 and %d", maxGameCount)
+	}
+
+	return gameCount, nil
+}
+
 func (s *ExportService) validateMatchExportRequest(request *MatchExportRequest) error {
 	if request.MatchID == "" {
 		return fmt.Errorf("match ID is required")
@@ -56,6 +106,10 @@ func (s *ExportService) validateMatchExportRequest(request *MatchExportRequest)
 		return fmt.Errorf("unsupported format: %s", request.Format)
 	}
 
+	if err := validateExportOptions(request.Format, request.ExportOptions); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -72,6 +126,10 @@ func (s *ExportService) validateTeamExportRequest(request *TeamExportRequest) er
 		return fmt.Errorf("unsupported format: %s", request.Format)
 	}
 
+	if err := validateExportOptions(request.Format, request.ExportOptions); err != nil {
+		return err
+	}
+
 	if request.TimeRange == "" {
 		return fmt.Errorf("time range is required")
 	}
@@ -87,11 +145,16 @@ func (s *ExportService) validateChampionExportRequest(request *ChampionExportReq
 	if request.ChampionName == "" {
 		return fmt.Errorf("champion name is required")
 	}
+	request.ChampionName = models.ResolveChampionAlias(request.ChampionName)
 
 	if !s.isValidFormat(request.Format) {
 		return fmt.Errorf("unsupported format: %s", request.Format)
 	}
 
+	if err := validateExportOptions(request.Format, request.ExportOptions); err != nil {
+		return err
+	}
+
 	if request.TimeRange == "" {
 		return fmt.Errorf("time range is required")
 	}
@@ -112,18 +175,66 @@ func (s *ExportService) validateCustomReportRequest(request *CustomReportRequest
 		return fmt.Errorf("unsupported format: %s", request.Format)
 	}
 
+	if err := validateExportOptions(request.Format, request.ExportOptions); err != nil {
+		return err
+	}
+
 	validReportTypes := []string{"performance_trends", "champion_comparison", "rank_progression", "meta_analysis"}
 	if !s.isValidReportType(request.ReportType, validReportTypes) {
 		return fmt.Errorf("unsupported report type: %s", request.ReportType)
 	}
 
+	normalizeReportFilters(request.Filters)
+
 	return nil
 }
 
+// normalizeReportFilters resolves role and champion aliases in filter values
+// so "supp" or "mf" match the stored Riot spelling
+func normalizeReportFilters(filters []ReportFilter) {
+	for i := range filters {
+		var normalize func(string) string
+		switch strings.ToLower(filters[i].Field) {
+		case "role", "position", "team_position":
+			normalize = models.NormalizeRole
+		case "champion", "champion_name":
+			normalize = models.ResolveChampionAlias
+		default:
+			continue
+		}
+
+		switch value := filters[i].Value.(type) {
+		case string:
+			filters[i].Value = normalize(value)
+		case []interface{}:
+			for j, item := range value {
+				if str, ok := item.(string); ok {
+					value[j] = normalize(str)
+				}
+			}
+		case []string:
+			for j, item := range value {
+				value[j] = normalize(item)
+			}
+		}
+	}
+}
+
 // Utility helper methods
 
+// validateExportOptions rejects options that do not apply to format
+func validateExportOptions(format string, options *ExportOptions) error {
+	if options == nil {
+		return nil
+	}
+	if options.PrettyJSON && format != "json" {
+		return fmt.Errorf("pretty_json only applies to the json format, not %s", format)
+	}
+	return nil
+}
+
 func (s *ExportService) isValidFormat(format string) bool {
-	validFormats := []string{"csv", "json", "xlsx", "pdf", "charts"}
+	validFormats := []string{"csv", "json", "xlsx", "pdf", "charts", "bundle"}
 	for _, validFormat := range validFormats {
 		if format == validFormat {
 			return true
@@ -141,12 +252,31 @@ func (s *ExportService) isValidReportType(reportType string, validTypes []string
 	return false
 }
 
-func (s *ExportService) validateSubscriptionLimits(playerPUUID, format string) error {
-	// This would check against user subscription and usage limits
-	// For now, return nil (no limits enforced)
+func (s *ExportService) validateSubscriptionLimits(tier, format string) error {
+	// PDF reports need the deployment to offer them and the user's tier to
+	// include them; per-user usage limits are not enforced yet
+	if format == "pdf" {
+		if s.config.PDF == nil || !s.config.PDF.ReportsEnabled || !tierIncludesPDFReports(tier) {
+			return ErrPDFReportsUnavailable
+		}
+	}
 	return nil
 }
 
+// tierIncludesPDFReports reports whether the export profile of a
+// subscription tier includes PDF reports. Users without a tier, or with one
+// that has no export profile, get the free profile.
+func tierIncludesPDFReports(tier string) bool {
+	tier = strings.ToLower(tier)
+	switch tier {
+	case "premium", "pro", "enterprise":
+	default:
+		tier = "free"
+	}
+	profile := GetExportConfigByProfile(tier)
+	return profile.PDF != nil && profile.PDF.ReportsEnabled
+}
+
 // Cache helper methods
 
 func (s *ExportService) generateCacheKey(dataType, identifier, format, timeRange string) string {
@@ -217,11 +347,60 @@ func (s *ExportService) encryptData(data []byte) ([]byte, error) {
 
 // Storage helper methods
 
-func (s *ExportService) storeExport(exportID, fileName string, data []byte) (string, error) {
-	// In a real implementation, this would store to S3, GCS, or similar
-	// For now, return a mock download URL
-	downloadURL := fmt.Sprintf("%s/%s/%s", s.config.CDNBaseURL, exportID, fileName)
-	return downloadURL, nil
+// storeExport returns the export's download URL. With StoragePath set it
+// also keeps a local copy so downloads can be checked against the retention
+// policy; failing to write that copy is logged and does not fail the export.
+func (s *ExportService) storeExport(exportID, fileName string, data []byte) string {
+	if s.config.StoragePath != "" {
+		dir := filepath.Join(s.config.StoragePath, exportID)
+		err := os.MkdirAll(dir, 0o750)
+		if err == nil {
+			err = os.WriteFile(filepath.Join(dir, filepath.Base(fileName)), data, 0o640)
+		}
+		if err != nil {
+			logger.Warnf("Failed to keep a local copy of export %s in %s: %v", exportID, s.config.StoragePath, err)
+		}
+	}
+
+	return fmt.Sprintf("%s/%s/%s", s.config.CDNBaseURL, exportID, fileName)
+}
+
+// findStoredExportFile returns the locally stored file for an export
+func (s *ExportService) findStoredExportFile(exportID string) (string, os.FileInfo, error) {
+	if s.config.StoragePath == "" {
+		return "", nil, os.ErrNotExist
+	}
+
+	entries, err := os.ReadDir(filepath.Join(s.config.StoragePath, filepath.Base(exportID)))
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return "", nil, err
+		}
+		return filepath.Join(s.config.StoragePath, filepath.Base(exportID), entry.Name()), info, nil
+	}
+
+	return "", nil, os.ErrNotExist
+}
+
+// findCachedExport returns the cached job with the given export ID, or nil
+func (s *ExportService) findCachedExport(exportID string) *CachedExport {
+	s.exportCacheMu.RLock()
+	defer s.exportCacheMu.RUnlock()
+
+	for _, cached := range s.exportCache {
+		if cached.ExportID == exportID {
+			return cached
+		}
+	}
+	return nil
 }
 
 func (s *ExportService) getStoredExportInfo(exportID string) (*StoredExportInfo, error) {
@@ -244,9 +423,30 @@ func (s *ExportService) getUserExports(userID string, limit int) ([]*UserExport,
 	return []*UserExport{}, nil
 }
 
-func (s *ExportService) deleteStoredExport(exportID string) error {
-	// In a real implementation, this would delete from storage
-	return nil
+// deleteStoredExport removes the locally stored files of an export and
+// returns the number of bytes freed
+func (s *ExportService) deleteStoredExport(exportID string) (int64, error) {
+	if s.config.StoragePath == "" {
+		return 0, nil
+	}
+
+	dir := filepath.Join(s.config.StoragePath, filepath.Base(exportID))
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var freed int64
+	for _, entry := range entries {
+		if info, err := entry.Info(); err == nil && !entry.IsDir() {
+			freed += info.Size()
+		}
+	}
+
+	return freed, os.RemoveAll(dir)
 }
 
 // Team metrics calculation
@@ -382,7 +582,9 @@ func (s *ExportService) generateMetaAnalysisData(request *CustomReportRequest) [
 
 // Gaming-specific helper methods for Herald.lol
 
-func (s *ExportService) calculateGamingMetrics(matches []*MatchExportData) *GamingMetrics {
+// calculateGamingMetrics summarizes a set of exported matches; WinRate is a
+// fraction and the KDA is aggregated over all games
+func calculateGamingMetrics(matches []*MatchExportData) *GamingMetrics {
 	if len(matches) == 0 {
 		return &GamingMetrics{}
 	}
@@ -402,7 +604,7 @@ func (s *ExportService) calculateGamingMetrics(matches []*MatchExportData) *Gami
 			totalDeaths += match.Performance.Deaths
 			totalAssists += match.Performance.Assists
 			totalCS += int(match.Performance.CSPerMinute * float64(match.Duration) / 60)
-			totalDamage += match.Performance.TotalDamage
+			totalDamage += match.Performance.Damage
 			totalVision += match.Performance.VisionScore
 		}
 		totalDuration += match.Duration
@@ -413,15 +615,19 @@ func (s *ExportService) calculateGamingMetrics(matches []*MatchExportData) *Gami
 
 	gameCount := len(matches)
 	avgGameDuration := totalDuration / gameCount
+	csPerMin := 0.0
+	if totalDuration > 0 {
+		csPerMin = float64(totalCS) / float64(totalDuration) * 60
+	}
 
 	return &GamingMetrics{
 		GamesPlayed:       gameCount,
 		WinRate:           float64(wins) / float64(gameCount),
-		AverageKDA:        s.calculateKDA(totalKills, totalDeaths, totalAssists),
+		AverageKDA:        aggregateKDA(totalKills, totalDeaths, totalAssists),
 		AverageKills:      float64(totalKills) / float64(gameCount),
 		AverageDeaths:     float64(totalDeaths) / float64(gameCount),
 		AverageAssists:    float64(totalAssists) / float64(gameCount),
-		AverageCSPerMin:   float64(totalCS) / float64(totalDuration) * 60,
+		AverageCSPerMin:   csPerMin,
 		AverageDamage:     totalDamage / gameCount,
 		AverageVision:     totalVision / gameCount,
 		AverageGameLength: avgGameDuration,
@@ -429,10 +635,7 @@ func (s *ExportService) calculateGamingMetrics(matches []*MatchExportData) *Gami
 }
 
 func (s *ExportService) calculateKDA(kills, deaths, assists int) float64 {
-	if deaths == 0 {
-		return float64(kills + assists)
-	}
-	return float64(kills+assists) / float64(deaths)
+	return aggregateKDA(kills, deaths, assists)
 }
 
 func (s *ExportService) identifyPlaystyle(metrics *GamingMetrics) string {
@@ -0,0 +1,152 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/herald-lol/herald/backend/internal/match"
+)
+
+// Herald.lol Gaming Analytics - Multi-Account Export
+// Combines a user's linked Riot accounts into one player export
+
+// MaxLinkedExportAccounts caps the extra accounts merged into one export
+const MaxLinkedExportAccounts = 10
+
+// LinkedAccount is another Riot account linked to the requesting user whose
+// matches are merged into a player export. Only the handler sets them, from
+// the user's stored links.
+type LinkedAccount struct {
+	PlayerPUUID  string `json:"player_puuid"`
+	SummonerName string `json:"summoner_name"`
+	Region       string `json:"region"`
+}
+
+// mergeSharedMatches reports whether matches played together by several of
+// the request's accounts are collapsed into one row
+func (s *ExportService) mergeSharedMatches(request *PlayerExportRequest) bool {
+	if request.MergeSharedMatches != nil {
+		return *request.MergeSharedMatches
+	}
+	return s.config == nil || s.config.MergeSharedMatches
+}
+
+func validateLinkedAccounts(request *PlayerExportRequest) error {
+	if len(request.LinkedAccounts) > MaxLinkedExportAccounts {
+		return fmt.Errorf("at most %d linked accounts can be exported at once", MaxLinkedExportAccounts)
+	}
+
+	seen := map[string]bool{request.PlayerPUUID: true}
+	for _, account := range request.LinkedAccounts {
+		if account.PlayerPUUID == "" {
+			return fmt.Errorf("linked account PUUID is required")
+		}
+		if seen[account.PlayerPUUID] {
+			return fmt.Errorf("account %s is listed more than once", account.PlayerPUUID)
+		}
+		seen[account.PlayerPUUID] = true
+	}
+	return nil
+}
+
+// accountMatchIDs returns the matches to export for one account: its own
+// stored matches when the handler resolved them, otherwise the request's
+// match IDs for the requesting account
+func (r *PlayerExportRequest) accountMatchIDs(puuid string) []string {
+	if matchIDs, ok := r.AccountMatchIDs[puuid]; ok {
+		return matchIDs
+	}
+	if puuid == r.PlayerPUUID {
+		return r.MatchIDs
+	}
+	return nil
+}
+
+// collectAccountMatches analyzes one account's matches from its own point
+// of view
+func (s *ExportService) collectAccountMatches(ctx context.Context, request *PlayerExportRequest, puuid string) []*MatchExportData {
+	matches := []*MatchExportData{}
+	for _, matchID := range request.accountMatchIDs(puuid) {
+		// Get match data and analysis
+		matchAnalysis, err := s.matchAnalyzer.AnalyzeMatch(ctx, &match.MatchAnalysisRequest{
+			PlayerPUUID:   puuid,
+			AnalysisDepth: "standard",
+		})
+		if err != nil {
+			continue // Skip failed matches
+		}
+
+		matches = append(matches, &MatchExportData{
+			MatchID:     matchID,
+			Account:     puuid,
+			Champion:    request.ExportOptions.championName(matchAnalysis.MatchInfo.Champion),
+			Role:        matchAnalysis.MatchInfo.Role,
+			QueueID:     matchAnalysis.MatchInfo.QueueID,
+			Queue:       matchAnalysis.MatchInfo.QueueType,
+			PlayedAt:    matchAnalysis.MatchInfo.PlayedAt,
+			Result:      matchAnalysis.MatchInfo.Result,
+			Duration:    matchAnalysis.MatchInfo.GameDuration,
+			Performance: matchAnalysis.Performance,
+			KeyMoments:  matchAnalysis.KeyMoments,
+		})
+	}
+	return matches
+}
+
+// mergeAccountMatches combines each account's matches, the requesting account
+// first. A match that appears under several accounts is flagged on every row
+// with the other accounts in SharedWith. When dedupe is set only the first
+// account's row is kept, so the requesting user's perspective wins, and the
+// number of dropped rows is returned.
+func mergeAccountMatches(accounts [][]*MatchExportData, dedupe bool) ([]*MatchExportData, int) {
+	owners := make(map[string][]string)
+	for _, matches := range accounts {
+		for _, m := range matches {
+			owners[m.MatchID] = append(owners[m.MatchID], m.Account)
+		}
+	}
+
+	merged := make([]*MatchExportData, 0)
+	kept := make(map[string]bool)
+	duplicates := 0
+	for _, matches := range accounts {
+		for _, m := range matches {
+			if dedupe && kept[m.MatchID] {
+				duplicates++
+				continue
+			}
+			kept[m.MatchID] = true
+
+			for _, owner := range owners[m.MatchID] {
+				if owner != m.Account {
+					m.SharedWith = append(m.SharedWith, owner)
+				}
+			}
+			m.Shared = len(m.SharedWith) > 0
+			merged = append(merged, m)
+		}
+	}
+
+	return merged, duplicates
+}
+
+// countSharedMatches counts the rows flagged as played by several accounts
+func countSharedMatches(matches []*MatchExportData) int {
+	shared := 0
+	for _, m := range matches {
+		if m.Shared {
+			shared++
+		}
+	}
+	return shared
+}
+
+// linkedAccountKey identifies the set of linked accounts in a cache key
+func linkedAccountKey(accounts []LinkedAccount) string {
+	puuids := make([]string, len(accounts))
+	for i, account := range accounts {
+		puuids[i] = account.PlayerPUUID
+	}
+	return strings.Join(puuids, ",")
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/herald-lol/herald/backend/internal/analytics"
 	"github.com/herald-lol/herald/backend/internal/match"
+	"github.com/herald-lol/herald/backend/internal/models"
 )
 
 // Herald.lol Gaming Analytics - Export Data Models
@@ -12,12 +13,16 @@ import (
 
 // Export Request Models
 
+// DefaultExportGameCount is the number of matches a player export includes
+// when game_count is not set
+const DefaultExportGameCount = 100
+
 // PlayerExportRequest contains parameters for exporting player analytics data
 type PlayerExportRequest struct {
 	PlayerPUUID        string   `json:"player_puuid" validate:"required"`
 	SummonerName       string   `json:"summoner_name"`
 	Region             string   `json:"region" validate:"required"`
-	Format             string   `json:"format" validate:"required,oneof=csv json xlsx pdf charts"`
+	Format             string   `json:"format" validate:"required,oneof=csv json xlsx pdf charts bundle"`
 	TimeRange          string   `json:"time_range" validate:"required"`
 	GameModes          []string `json:"game_modes"`
 	MatchIDs           []string `json:"match_ids"`
@@ -28,8 +33,117 @@ type PlayerExportRequest struct {
 	CompressionLevel   string   `json:"compression_level,omitempty"`
 	EncryptionEnabled  bool     `json:"encryption_enabled,omitempty"`
 
+	// Optional match filter, e.g. inherited from an export template
+	Filter *ExportFilter `json:"filter,omitempty"`
+
+	// Leave out remakes, very short games and AFK games
+	ExcludeNonCompetitive bool `json:"exclude_non_competitive,omitempty"`
+	// Leave out remakes only; nil uses the user's excludeRemakes preference
+	ExcludeRemakes *bool `json:"exclude_remakes,omitempty"`
+
+	// GameCount limits the export to the most recent N matches, between 1 and
+	// ExportConfig.MaxGameCount (MAX_GAME_COUNT); zero uses
+	// DefaultExportGameCount.
+	GameCount int `json:"game_count,omitempty"`
+
+	// IncludeLinkedAccounts merges the user's other linked Riot accounts into
+	// the export. The handler resolves them from the user's stored links into
+	// LinkedAccounts, with each account's own matches in AccountMatchIDs.
+	IncludeLinkedAccounts bool                `json:"include_linked_accounts,omitempty"`
+	LinkedAccounts        []LinkedAccount     `json:"-"`
+	AccountMatchIDs       map[string][]string `json:"-"`
+	// MergeSharedMatches keeps one row, from the requesting account's point
+	// of view, for a match several linked accounts played together; nil uses
+	// the service's merge_shared_matches setting
+	MergeSharedMatches *bool `json:"merge_shared_matches,omitempty"`
+
+	// WithTeammate keeps only games where this Riot ID (GameName#TAG) was on
+	// the player's team. The handler resolves it to TeammateMatchIDs from the
+	// stored participants.
+	WithTeammate     string   `json:"with_teammate,omitempty"`
+	TeammateMatchIDs []string `json:"-"`
+
+	// RankHistory is the user's stored LP history, oldest first, loaded by
+	// the handler for ExportOptions.RankedColumns
+	RankHistory []RankedPoint `json:"-"`
+
+	// Password, when set, delivers the export as an AES-256 encrypted zip
+	// (see encryption.go). It is never stored or cached.
+	Password string `json:"password,omitempty"`
+
 	// Export options
 	ExportOptions *ExportOptions `json:"export_options,omitempty"`
+
+	// UserID is the authenticated user the export belongs to, set by the handler
+	UserID string `json:"-"`
+	// SubscriptionTier is the user's plan ("free", "premium", ...), set by the
+	// handler; PDF reports need a tier whose export profile includes them
+	SubscriptionTier string `json:"-"`
+}
+
+// isRankedOnly reports whether ExportOptions.RankedOnly is set
+func (r *PlayerExportRequest) isRankedOnly() bool {
+	return r.ExportOptions != nil && r.ExportOptions.RankedOnly
+}
+
+// includeRankedColumns reports whether ExportOptions.RankedColumns is set
+func (r *PlayerExportRequest) includeRankedColumns() bool {
+	return r.ExportOptions != nil && r.ExportOptions.RankedColumns
+}
+
+// includeStreakColumn reports whether ExportOptions.StreakColumn is set
+func (r *PlayerExportRequest) includeStreakColumn() bool {
+	return r.ExportOptions != nil && r.ExportOptions.StreakColumn
+}
+
+// includeBOM reports whether CSV output should start with a UTF-8 BOM
+func (r *PlayerExportRequest) includeBOM() bool {
+	return r.ExportOptions != nil && r.ExportOptions.CSVOptions != nil && r.ExportOptions.CSVOptions.IncludeBOM
+}
+
+// excludeRemakes reports whether remakes should be left out; the handler
+// fills in the user's preference when the request leaves it unset
+func (r *PlayerExportRequest) excludeRemakes() bool {
+	return r.ExcludeRemakes != nil && *r.ExcludeRemakes
+}
+
+// ExportFilter narrows the matches included in an export
+type ExportFilter struct {
+	Champions []string `json:"champions,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	GameModes []string `json:"game_modes,omitempty"`
+	TimeRange string   `json:"time_range,omitempty"`
+
+	// Ordering of exported matches, most recent first by default
+	SortBy    string `json:"sort_by,omitempty"`    // date, kda, duration, champion
+	SortOrder string `json:"sort_order,omitempty"` // asc or desc; champion defaults to asc, everything else to desc
+}
+
+// Export sort fields
+const (
+	ExportSortDate     = "date"
+	ExportSortKDA      = "kda"
+	ExportSortDuration = "duration"
+	ExportSortChampion = "champion"
+)
+
+// Export sort directions
+const (
+	ExportSortAsc  = "asc"
+	ExportSortDesc = "desc"
+)
+
+// ExportTemplate is a saved player export configuration with a default filter,
+// e.g. a one-trick's main champion
+type ExportTemplate struct {
+	TemplateID    string         `json:"template_id"`
+	Name          string         `json:"name" validate:"required"`
+	Description   string         `json:"description"`
+	OwnerID       string         `json:"owner_id"`
+	Format        string         `json:"format" validate:"required,oneof=csv json xlsx pdf charts bundle"`
+	DefaultFilter *ExportFilter  `json:"default_filter,omitempty"`
+	ExportOptions *ExportOptions `json:"export_options,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
 }
 
 // MatchExportRequest contains parameters for exporting match analysis
@@ -63,6 +177,33 @@ type TeamExportRequest struct {
 	ExportOptions *ExportOptions `json:"export_options,omitempty"`
 }
 
+// MaxGroupExportMembers caps the members bundled into one group export
+const MaxGroupExportMembers = 20
+
+// GroupExportRequest contains parameters for exporting every member of a
+// group into one bundle; the caller resolves membership and access
+type GroupExportRequest struct {
+	GroupName string        `json:"group_name" validate:"required"`
+	Members   []GroupMember `json:"members" validate:"required,min=1"`
+	TimeRange string        `json:"time_range" validate:"required"`
+
+	// Optional match filter applied to every member
+	Filter *ExportFilter `json:"filter,omitempty"`
+
+	// Password, when set, encrypts the bundle with AES-256; never stored
+	Password string `json:"password,omitempty"`
+
+	// UserID is the authenticated user the export belongs to, set by the handler
+	UserID string `json:"-"`
+}
+
+// GroupMember identifies one player in a group export
+type GroupMember struct {
+	PlayerPUUID  string `json:"player_puuid" validate:"required"`
+	SummonerName string `json:"summoner_name"`
+	Region       string `json:"region" validate:"required"`
+}
+
 // ChampionExportRequest contains parameters for exporting champion analytics
 type ChampionExportRequest struct {
 	PlayerPUUID        string   `json:"player_puuid" validate:"required"`
@@ -109,18 +250,41 @@ type ExportResult struct {
 	CreatedAt   time.Time       `json:"created_at"`
 	ExpiresAt   time.Time       `json:"expires_at"`
 	Metadata    *ExportMetadata `json:"metadata,omitempty"`
+	Manifest    *ExportManifest `json:"manifest,omitempty"`
 }
 
 // ExportStatus contains the status of an export job
 type ExportStatus struct {
-	ExportID     string    `json:"export_id"`
-	Status       string    `json:"status"`   // pending, processing, completed, failed, expired
-	Progress     int       `json:"progress"` // 0-100
-	FileSize     int       `json:"file_size"`
-	DownloadURL  string    `json:"download_url,omitempty"`
-	CreatedAt    time.Time `json:"created_at"`
-	ExpiresAt    time.Time `json:"expires_at"`
-	ErrorMessage string    `json:"error_message,omitempty"`
+	ExportID     string          `json:"export_id"`
+	Status       string          `json:"status"`   // pending, processing, completed, failed, expired
+	Progress     int             `json:"progress"` // 0-100
+	FileSize     int             `json:"file_size"`
+	DownloadURL  string          `json:"download_url,omitempty"`
+	CreatedAt    time.Time       `json:"created_at"`
+	ExpiresAt    time.Time       `json:"expires_at"`
+	ErrorMessage string          `json:"error_message,omitempty"`
+	Manifest     *ExportManifest `json:"manifest,omitempty"`
+}
+
+// ExportManifest describes the files in a bundle archive; it is written into
+// the archive as manifest.json so the contents can be verified after download
+type ExportManifest struct {
+	PlayerPUUID  string         `json:"player_puuid,omitempty"`
+	SummonerName string         `json:"summoner_name,omitempty"`
+	GroupName    string         `json:"group_name,omitempty"`
+	TimeRange    string         `json:"time_range"`
+	Filter       *ExportFilter  `json:"filter,omitempty"`
+	ExportedAt   time.Time      `json:"exported_at"`
+	RowCount     int            `json:"row_count"`
+	Files        []ManifestFile `json:"files"`
+}
+
+// ManifestFile is one file in a bundle archive with its uncompressed size
+// and hex encoded SHA-256 checksum
+type ManifestFile struct {
+	Name   string `json:"name"`
+	Size   int    `json:"size"`
+	SHA256 string `json:"sha256"`
 }
 
 // ExportSummary contains a summary of an export
@@ -140,6 +304,7 @@ type ExportMetadata struct {
 	PlayerPUUID  string `json:"player_puuid,omitempty"`
 	MatchID      string `json:"match_id,omitempty"`
 	TeamName     string `json:"team_name,omitempty"`
+	GroupName    string `json:"group_name,omitempty"`
 	ChampionName string `json:"champion_name,omitempty"`
 	ReportName   string `json:"report_name,omitempty"`
 	TimeRange    string `json:"time_range,omitempty"`
@@ -160,6 +325,15 @@ type PlayerExportData struct {
 	ExportedAt time.Time                     `json:"exported_at"`
 	TotalGames int                           `json:"total_games"`
 
+	// Remakes and non-competitive games left out when requested
+	ExcludedGames int `json:"excluded_games,omitempty"`
+
+	// Linked accounts merged into the export, the matches several of them
+	// played together, and the duplicate rows dropped for those matches
+	LinkedAccounts   []LinkedAccount `json:"linked_accounts,omitempty"`
+	SharedMatches    int             `json:"shared_matches,omitempty"`
+	MergedDuplicates int             `json:"merged_duplicates,omitempty"`
+
 	// Additional analytics data
 	ChampionStats map[string]*ChampionStats `json:"champion_stats,omitempty"`
 	RoleStats     map[string]*RoleStats     `json:"role_stats,omitempty"`
@@ -170,8 +344,14 @@ type PlayerExportData struct {
 // MatchExportData contains detailed match data for export
 type MatchExportData struct {
 	MatchID               string                       `json:"match_id"`
+	Account               string                       `json:"account,omitempty"` // PUUID whose perspective the row is from
+	Shared                bool                         `json:"shared,omitempty"`
+	SharedWith            []string                     `json:"shared_with,omitempty"` // other linked accounts in the match
 	Champion              string                       `json:"champion"`
 	Role                  string                       `json:"role"`
+	QueueID               int                          `json:"queue_id,omitempty"`
+	Queue                 string                       `json:"queue,omitempty"` // e.g. "Ranked Solo/Duo"
+	PlayedAt              time.Time                    `json:"played_at,omitempty"`
 	Result                string                       `json:"result"`
 	Duration              int                          `json:"duration"`
 	Performance           *match.PerformanceAnalysis   `json:"performance"`
@@ -182,11 +362,23 @@ type MatchExportData struct {
 	LearningOpportunities []*match.LearningOpportunity `json:"learning_opportunities,omitempty"`
 	OverallRating         float64                      `json:"overall_rating"`
 
+	// LP and MMR after the game, only with ExportOptions.RankedColumns
+	Ranked *RankedColumns `json:"ranked,omitempty"`
+	// Win/loss streak after the game, only with ExportOptions.StreakColumn
+	Streak string `json:"streak,omitempty"`
+
 	// Timeline data (optional)
 	Timeline *MatchTimeline `json:"timeline,omitempty"`
 	Heatmaps *MatchHeatmaps `json:"heatmaps,omitempty"`
 }
 
+// RankedColumns holds a match's ranked standing; values are null when
+// unknown, e.g. for unranked games
+type RankedColumns struct {
+	LP  *int `json:"lp"`
+	MMR *int `json:"mmr"`
+}
+
 // TeamExportData contains team analytics data for export
 type TeamExportData struct {
 	TeamName    string              `json:"team_name"`
@@ -619,6 +811,29 @@ type ExportOptions struct {
 	BrandingEnabled    bool     `json:"branding_enabled"`
 	WatermarkEnabled   bool     `json:"watermark_enabled"`
 
+	// RankedOnly limits the export to ranked games, Ranked Solo/Duo (queue
+	// 420) and Ranked Flex (queue 440), whatever other filters allow
+	RankedOnly bool `json:"ranked_only,omitempty"`
+
+	// RankedColumns adds each match's LP and MMR as columns, left empty
+	// where unknown, for following an LP trajectory. Save it on a template
+	// to keep it for every run.
+	RankedColumns bool `json:"ranked_columns,omitempty"`
+
+	// StreakColumn adds the win/loss streak each match left the player on
+	// ("W3", "L2"), counted in play order across the exported history, for
+	// relating performance to momentum. It can be saved on a template too.
+	StreakColumn bool `json:"streak_column,omitempty"`
+
+	// InternalChampionNames writes Riot's internal champion names
+	// ("MonkeyKing") instead of display names ("Wukong"), for joining with
+	// raw Riot data
+	InternalChampionNames bool `json:"internal_champion_names,omitempty"`
+
+	// PrettyJSON indents JSON output for reading by hand; JSON exports are
+	// compact by default. It is only valid with the json format.
+	PrettyJSON bool `json:"pretty_json,omitempty"`
+
 	// Format-specific options
 	CSVOptions   *CSVExportOptions   `json:"csv_options,omitempty"`
 	JSONOptions  *JSONExportOptions  `json:"json_options,omitempty"`
@@ -627,12 +842,21 @@ type ExportOptions struct {
 	ChartOptions *ChartExportOptions `json:"chart_options,omitempty"`
 }
 
+// championName returns name in the form the export asks for: display names
+// unless InternalChampionNames is set. options may be nil.
+func (o *ExportOptions) championName(name string) string {
+	return models.ChampionName(name, o != nil && o.InternalChampionNames)
+}
+
 // Format-specific export options
 type CSVExportOptions struct {
 	Delimiter      string `json:"delimiter"`
 	IncludeHeaders bool   `json:"include_headers"`
 	DateFormat     string `json:"date_format"`
 	NumberFormat   string `json:"number_format"`
+	// IncludeBOM prefixes the file with a UTF-8 byte order mark so readers
+	// such as Excel detect the encoding of non-ASCII names
+	IncludeBOM bool `json:"include_bom,omitempty"`
 }
 
 type JSONExportOptions struct {
@@ -690,11 +914,27 @@ type ExportFormat struct {
 // CachedExport represents a cached export result
 type CachedExport struct {
 	ExportID    string    `json:"export_id"`
+	UserID      string    `json:"user_id"` // owner, see ExportHistoryFilter
 	Format      string    `json:"format"`
 	FileSize    int       `json:"file_size"`
 	DownloadURL string    `json:"download_url"`
 	CreatedAt   time.Time `json:"created_at"`
 	ExpiresAt   time.Time `json:"expires_at"`
+	// Manifest is set for bundle exports
+	Manifest *ExportManifest `json:"manifest,omitempty"`
+}
+
+// ExportHistoryFilter selects which export jobs a bulk delete removes
+type ExportHistoryFilter struct {
+	UserID    string        `json:"user_id"`              // owner of the jobs; required
+	Status    string        `json:"status,omitempty"`     // "completed" or "expired"; empty matches all
+	OlderThan time.Duration `json:"older_than,omitempty"` // only jobs created at least this long ago
+}
+
+// DeleteExportsResult reports what a delete removed
+type DeleteExportsResult struct {
+	DeletedJobs int   `json:"deleted_jobs"`
+	FreedBytes  int64 `json:"freed_bytes"`
 }
 
 // Storage Models
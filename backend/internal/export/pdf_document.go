@@ -0,0 +1,297 @@
+package export
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Herald.lol Gaming Analytics - PDF Writer
+// A minimal PDF 1.4 writer for the export reports: text in the embedded
+// DejaVu Sans fonts (see pdf_font.go), lines and filled rectangles. It needs
+// no third-party dependency, which is all a one-page summary report calls for.
+
+// Page sizes in points
+var pdfPageSizes = map[string][2]float64{
+	"A4":     {595.28, 841.89},
+	"LETTER": {612, 792},
+	"LEGAL":  {612, 1008},
+}
+
+// pdfMillimeter is one millimeter in points
+const pdfMillimeter = 72 / 25.4
+
+// pdfColor is an RGB color with components between 0 and 1
+type pdfColor struct{ r, g, b float64 }
+
+// parsePDFColor reads a "#RRGGBB" color, falling back when it is malformed
+func parsePDFColor(hex string, fallback pdfColor) pdfColor {
+	hex = strings.TrimPrefix(hex, "#")
+	if len(hex) != 6 {
+		return fallback
+	}
+	value, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return fallback
+	}
+	return pdfColor{
+		r: float64(value>>16&0xff) / 255,
+		g: float64(value>>8&0xff) / 255,
+		b: float64(value&0xff) / 255,
+	}
+}
+
+// pdfDocument collects page content streams and serializes them. Coordinates
+// passed to the drawing methods are in points from the top-left corner of the
+// page, converted to PDF's bottom-left origin when written.
+type pdfDocument struct {
+	width, height float64
+	pages         []*bytes.Buffer
+	// Glyphs shown in the regular and bold fonts, with the characters they
+	// stand for, to subset the fonts and map text back to Unicode
+	glyphs [2]map[uint16]rune
+}
+
+// newPDFDocument creates a document with pages of the named size ("A4",
+// "Letter", "Legal", A4 when unknown), turned sideways for "landscape"
+func newPDFDocument(pageSize, orientation string) *pdfDocument {
+	size, ok := pdfPageSizes[strings.ToUpper(pageSize)]
+	if !ok {
+		size = pdfPageSizes["A4"]
+	}
+	width, height := size[0], size[1]
+	if strings.EqualFold(orientation, "landscape") {
+		width, height = height, width
+	}
+	return &pdfDocument{width: width, height: height}
+}
+
+// addPage starts a new page; drawing goes to the latest page
+func (d *pdfDocument) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+func (d *pdfDocument) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// text writes s with its baseline at (x, y). Characters the font lacks
+// show as its missing glyph box.
+func (d *pdfDocument) text(x, y, size float64, bold bool, color pdfColor, s string) {
+	index := 0
+	if bold {
+		index = 1
+	}
+	font := reportFonts()[index]
+	if d.glyphs[index] == nil {
+		d.glyphs[index] = make(map[uint16]rune)
+	}
+	var hex strings.Builder
+	for _, r := range s {
+		glyph := font.glyph(r)
+		if glyph != 0 {
+			d.glyphs[index][glyph] = r
+		}
+		fmt.Fprintf(&hex, "%04X", glyph)
+	}
+	fmt.Fprintf(d.page(), "BT %s rg /F%d %s Tf %s %s Td <%s> Tj ET\n",
+		color.operands(), index+1, pdfNumber(size), pdfNumber(x), pdfNumber(d.height-y), hex.String())
+}
+
+// textWidth measures s set in the report font, for right-aligning and
+// centering
+func textWidth(s string, size float64, bold bool) float64 {
+	font := reportFonts()[0]
+	if bold {
+		font = reportFonts()[1]
+	}
+	var width float64
+	for _, r := range s {
+		width += font.width(font.glyph(r))
+	}
+	return width * size / 1000
+}
+
+// line draws a stroked line from (x1, y1) to (x2, y2)
+func (d *pdfDocument) line(x1, y1, x2, y2, width float64, color pdfColor) {
+	fmt.Fprintf(d.page(), "%s RG %s w %s %s m %s %s l S\n",
+		color.operands(), pdfNumber(width),
+		pdfNumber(x1), pdfNumber(d.height-y1), pdfNumber(x2), pdfNumber(d.height-y2))
+}
+
+// polyline draws connected line segments through points given as x, y pairs
+func (d *pdfDocument) polyline(points [][2]float64, width float64, color pdfColor) {
+	if len(points) < 2 {
+		return
+	}
+	page := d.page()
+	fmt.Fprintf(page, "%s RG %s w 1 j", color.operands(), pdfNumber(width))
+	for i, p := range points {
+		op := "l"
+		if i == 0 {
+			op = "m"
+		}
+		fmt.Fprintf(page, " %s %s %s", pdfNumber(p[0]), pdfNumber(d.height-p[1]), op)
+	}
+	page.WriteString(" S\n")
+}
+
+// rect fills the rectangle with its top-left corner at (x, y)
+func (d *pdfDocument) rect(x, y, width, height float64, color pdfColor) {
+	fmt.Fprintf(d.page(), "%s rg %s %s %s %s re f\n",
+		color.operands(), pdfNumber(x), pdfNumber(d.height-y-height), pdfNumber(width), pdfNumber(height))
+}
+
+// pdfObjects numbers the objects of a document in the order they are added
+type pdfObjects struct {
+	bodies []string
+}
+
+// reserve hands out an object number whose body is set later, for objects
+// that refer to each other
+func (o *pdfObjects) reserve() int {
+	o.bodies = append(o.bodies, "")
+	return len(o.bodies)
+}
+
+func (o *pdfObjects) set(number int, body string) {
+	o.bodies[number-1] = body
+}
+
+func (o *pdfObjects) add(body string) int {
+	number := o.reserve()
+	o.set(number, body)
+	return number
+}
+
+// bytes serializes the document
+func (d *pdfDocument) bytes() []byte {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+
+	objects := &pdfObjects{}
+	catalog, pageTree := objects.reserve(), objects.reserve()
+	objects.set(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pageTree))
+
+	var fonts []string
+	for i, glyphs := range d.glyphs {
+		if glyphs != nil {
+			fonts = append(fonts, fmt.Sprintf("/F%d %d 0 R", i+1, writeFont(objects, reportFonts()[i], glyphs)))
+		}
+	}
+
+	kids := make([]string, len(d.pages))
+	for i, content := range d.pages {
+		contents := objects.add(pdfStream("", content.Bytes()))
+		page := objects.add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pageTree, pdfNumber(d.width), pdfNumber(d.height), strings.Join(fonts, " "), contents))
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+	objects.set(pageTree, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(objects.bodies))
+	for i, body := range objects.bodies {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, catalog, xref)
+
+	return out.Bytes()
+}
+
+// writeFont adds font as a composite (Type 0) font showing glyph IDs
+// directly, embedding the subset of glyphs used, and returns its object
+// number
+func writeFont(objects *pdfObjects, font *pdfFont, glyphs map[uint16]rune) int {
+	used := make([]uint16, 0, len(glyphs))
+	for glyph := range glyphs {
+		used = append(used, glyph)
+	}
+	sort.Slice(used, func(i, j int) bool { return used[i] < used[j] })
+	name := subsetTag(used) + "+" + font.name
+
+	program := font.subset(used)
+	var compressed bytes.Buffer
+	writer, _ := zlib.NewWriterLevel(&compressed, zlib.BestCompression)
+	writer.Write(program)
+	writer.Close()
+	fontFile := objects.add(pdfStream(fmt.Sprintf("/Filter /FlateDecode /Length1 %d", len(program)), compressed.Bytes()))
+
+	descriptor := objects.add(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		name, font.scale(font.bbox[0]), font.scale(font.bbox[1]), font.scale(font.bbox[2]), font.scale(font.bbox[3]),
+		font.scale(font.ascent), font.scale(font.descent), font.scale(font.capHeight), fontFile))
+
+	widths := make([]string, len(used))
+	for i, glyph := range used {
+		widths[i] = fmt.Sprintf("%d [%s]", glyph, pdfNumber(font.width(glyph)))
+	}
+	cidFont := objects.add(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /W [%s] /CIDToGIDMap /Identity >>",
+		name, descriptor, strings.Join(widths, " ")))
+
+	toUnicode := objects.add(pdfStream("", toUnicodeCMap(used, glyphs)))
+
+	return objects.add(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		name, cidFont, toUnicode))
+}
+
+// toUnicodeCMap maps the glyphs shown back to their characters, so text can
+// be searched and copied from the report
+func toUnicodeCMap(used []uint16, glyphs map[uint16]rune) []byte {
+	var cmap bytes.Buffer
+	cmap.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	// At most 100 entries per block
+	for start := 0; start < len(used); start += 100 {
+		end := start + 100
+		if end > len(used) {
+			end = len(used)
+		}
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", end-start)
+		for _, glyph := range used[start:end] {
+			fmt.Fprintf(&cmap, "<%04X> <", glyph)
+			for _, unit := range utf16.Encode([]rune{glyphs[glyph]}) {
+				fmt.Fprintf(&cmap, "%04X", unit)
+			}
+			cmap.WriteString(">\n")
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return cmap.Bytes()
+}
+
+// pdfStream formats a stream object with extra dictionary entries
+func pdfStream(entries string, data []byte) string {
+	if entries != "" {
+		entries += " "
+	}
+	return fmt.Sprintf("<< %s/Length %d >>\nstream\n%s\nendstream", entries, len(data), data)
+}
+
+func (c pdfColor) operands() string {
+	return pdfNumber(c.r) + " " + pdfNumber(c.g) + " " + pdfNumber(c.b)
+}
+
+// pdfNumber formats n with at most two decimals and no trailing zeros
+func pdfNumber(n float64) string {
+	return strconv.FormatFloat(math.Round(n*100)/100, 'f', -1, 64)
+}
@@ -0,0 +1,394 @@
+package export
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+)
+
+// Herald.lol Gaming Analytics - PDF Fonts
+// The report text is set in DejaVu Sans, embedded in the binary so summoner
+// and champion names outside Latin-1 (Cyrillic, Greek, accented Latin, ...)
+// render as written. Each document embeds a subset of the TrueType font with
+// only the glyphs it shows.
+
+//go:embed fonts/DejaVuSans.ttf
+var dejaVuSans []byte
+
+//go:embed fonts/DejaVuSans-Bold.ttf
+var dejaVuSansBold []byte
+
+// reportFonts returns the regular and bold report fonts, parsed on first use
+var reportFonts = sync.OnceValue(func() [2]*pdfFont {
+	regular, err := parseTrueType("DejaVuSans", dejaVuSans)
+	if err != nil {
+		panic(fmt.Sprintf("export: embedded regular font: %v", err))
+	}
+	bold, err := parseTrueType("DejaVuSans-Bold", dejaVuSansBold)
+	if err != nil {
+		panic(fmt.Sprintf("export: embedded bold font: %v", err))
+	}
+	return [2]*pdfFont{regular, bold}
+})
+
+var errMalformedFont = errors.New("malformed TrueType font")
+
+// pdfFont is a parsed TrueType font: the metrics the PDF font dictionaries
+// need, the character to glyph map and the glyph outlines for subsetting
+type pdfFont struct {
+	name       string
+	tables     map[string][]byte
+	unitsPerEm float64
+	bbox       [4]int16
+	ascent     int16
+	descent    int16
+	capHeight  int16
+	numGlyphs  int
+	advances   []uint16
+	loca       []uint32
+	cmap       map[rune]uint16
+}
+
+// parseTrueType reads the tables of a TrueType font used by the PDF writer
+func parseTrueType(name string, data []byte) (*pdfFont, error) {
+	if len(data) < 12 {
+		return nil, errMalformedFont
+	}
+	font := &pdfFont{name: name, tables: make(map[string][]byte)}
+	numTables := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < numTables; i++ {
+		record := 12 + 16*i
+		if record+16 > len(data) {
+			return nil, errMalformedFont
+		}
+		offset := int(binary.BigEndian.Uint32(data[record+8:]))
+		length := int(binary.BigEndian.Uint32(data[record+12:]))
+		if offset < 0 || length < 0 || offset+length > len(data) {
+			return nil, errMalformedFont
+		}
+		font.tables[string(data[record:record+4])] = data[offset : offset+length]
+	}
+	for _, tag := range []string{"head", "hhea", "maxp", "hmtx", "loca", "glyf", "cmap"} {
+		if font.tables[tag] == nil {
+			return nil, fmt.Errorf("%w: missing %s table", errMalformedFont, tag)
+		}
+	}
+
+	head, hhea, maxp := font.tables["head"], font.tables["hhea"], font.tables["maxp"]
+	if len(head) < 54 || len(hhea) < 36 || len(maxp) < 6 {
+		return nil, errMalformedFont
+	}
+	font.unitsPerEm = float64(binary.BigEndian.Uint16(head[18:]))
+	if font.unitsPerEm == 0 {
+		return nil, errMalformedFont
+	}
+	for i := range font.bbox {
+		font.bbox[i] = int16(binary.BigEndian.Uint16(head[36+2*i:]))
+	}
+	font.ascent = int16(binary.BigEndian.Uint16(hhea[4:]))
+	font.descent = int16(binary.BigEndian.Uint16(hhea[6:]))
+	font.capHeight = font.ascent
+	if os2 := font.tables["OS/2"]; len(os2) >= 90 && binary.BigEndian.Uint16(os2) >= 2 {
+		font.capHeight = int16(binary.BigEndian.Uint16(os2[88:]))
+	}
+	font.numGlyphs = int(binary.BigEndian.Uint16(maxp[4:]))
+
+	// Glyphs past the last long metric share its advance
+	metrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := font.tables["hmtx"]
+	if metrics == 0 || len(hmtx) < 4*metrics {
+		return nil, errMalformedFont
+	}
+	font.advances = make([]uint16, font.numGlyphs)
+	for i := range font.advances {
+		metric := i
+		if metric >= metrics {
+			metric = metrics - 1
+		}
+		font.advances[i] = binary.BigEndian.Uint16(hmtx[4*metric:])
+	}
+
+	loca := font.tables["loca"]
+	font.loca = make([]uint32, font.numGlyphs+1)
+	longOffsets := binary.BigEndian.Uint16(head[50:]) == 1
+	for i := range font.loca {
+		switch {
+		case longOffsets && len(loca) >= 4*i+4:
+			font.loca[i] = binary.BigEndian.Uint32(loca[4*i:])
+		case !longOffsets && len(loca) >= 2*i+2:
+			font.loca[i] = 2 * uint32(binary.BigEndian.Uint16(loca[2*i:]))
+		default:
+			return nil, errMalformedFont
+		}
+		if int(font.loca[i]) > len(font.tables["glyf"]) || (i > 0 && font.loca[i] < font.loca[i-1]) {
+			return nil, errMalformedFont
+		}
+	}
+
+	cmap, err := parseCmap(font.tables["cmap"])
+	if err != nil {
+		return nil, err
+	}
+	font.cmap = cmap
+	return font, nil
+}
+
+// parseCmap reads the Windows Unicode character map, preferring the full
+// repertoire subtable (format 12) over the BMP one (format 4)
+func parseCmap(table []byte) (map[rune]uint16, error) {
+	if len(table) < 4 {
+		return nil, errMalformedFont
+	}
+	var bmp, full []byte
+	numTables := int(binary.BigEndian.Uint16(table[2:]))
+	for i := 0; i < numTables; i++ {
+		record := 4 + 8*i
+		if record+8 > len(table) {
+			return nil, errMalformedFont
+		}
+		platform := binary.BigEndian.Uint16(table[record:])
+		encoding := binary.BigEndian.Uint16(table[record+2:])
+		offset := int(binary.BigEndian.Uint32(table[record+4:]))
+		if platform != 3 || offset+4 > len(table) {
+			continue
+		}
+		subtable := table[offset:]
+		switch format := binary.BigEndian.Uint16(subtable); {
+		case encoding == 10 && format == 12:
+			full = subtable
+		case encoding == 1 && format == 4:
+			bmp = subtable
+		}
+	}
+
+	glyphs := make(map[rune]uint16)
+	switch {
+	case full != nil:
+		if len(full) < 16 {
+			return nil, errMalformedFont
+		}
+		groups := int(binary.BigEndian.Uint32(full[12:]))
+		if len(full) < 16+12*groups {
+			return nil, errMalformedFont
+		}
+		for i := 0; i < groups; i++ {
+			group := full[16+12*i:]
+			start := binary.BigEndian.Uint32(group)
+			end := binary.BigEndian.Uint32(group[4:])
+			glyph := binary.BigEndian.Uint32(group[8:])
+			for c := start; c <= end && c <= 0x10FFFF; c++ {
+				glyphs[rune(c)] = uint16(glyph + c - start)
+			}
+		}
+	case bmp != nil:
+		if len(bmp) < 14 {
+			return nil, errMalformedFont
+		}
+		segments := int(binary.BigEndian.Uint16(bmp[6:])) / 2
+		ends, starts := 14, 16+2*segments
+		deltas, rangeOffsets := starts+2*segments, starts+4*segments
+		if len(bmp) < rangeOffsets+2*segments {
+			return nil, errMalformedFont
+		}
+		for i := 0; i < segments; i++ {
+			end := int(binary.BigEndian.Uint16(bmp[ends+2*i:]))
+			start := int(binary.BigEndian.Uint16(bmp[starts+2*i:]))
+			delta := int(binary.BigEndian.Uint16(bmp[deltas+2*i:]))
+			rangeOffset := int(binary.BigEndian.Uint16(bmp[rangeOffsets+2*i:]))
+			for c := start; c <= end && c != 0xFFFF; c++ {
+				glyph := (c + delta) & 0xFFFF
+				if rangeOffset != 0 {
+					at := rangeOffsets + 2*i + rangeOffset + 2*(c-start)
+					if at+2 > len(bmp) {
+						return nil, errMalformedFont
+					}
+					glyph = int(binary.BigEndian.Uint16(bmp[at:]))
+					if glyph != 0 {
+						glyph = (glyph + delta) & 0xFFFF
+					}
+				}
+				if glyph != 0 {
+					glyphs[rune(c)] = uint16(glyph)
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("%w: no Unicode character map", errMalformedFont)
+	}
+	return glyphs, nil
+}
+
+// glyph returns the glyph for r, or 0 (.notdef) when the font lacks it
+func (f *pdfFont) glyph(r rune) uint16 {
+	return f.cmap[r]
+}
+
+// width returns the advance of glyph in thousandths of an em, the unit of
+// PDF glyph widths
+func (f *pdfFont) width(glyph uint16) float64 {
+	if int(glyph) >= len(f.advances) {
+		return 0
+	}
+	return float64(f.advances[glyph]) * 1000 / f.unitsPerEm
+}
+
+// scale converts font units to thousandths of an em
+func (f *pdfFont) scale(v int16) int {
+	return int(float64(v) * 1000 / f.unitsPerEm)
+}
+
+// subsetTag is the six letter prefix naming a font subset in the PDF,
+// derived from the glyphs so equal subsets get equal names
+func subsetTag(glyphs []uint16) string {
+	hash := fnv.New32a()
+	for _, glyph := range glyphs {
+		hash.Write([]byte{byte(glyph >> 8), byte(glyph)})
+	}
+	sum := hash.Sum32()
+	tag := make([]byte, 6)
+	for i := range tag {
+		tag[i] = 'A' + byte(sum%26)
+		sum /= 26
+	}
+	return string(tag)
+}
+
+// subset returns a TrueType font keeping the outlines of glyphs, the glyphs
+// their composites are built from and .notdef. Glyph IDs are unchanged, so
+// the PDF maps CIDs to glyphs with the identity map.
+func (f *pdfFont) subset(glyphs []uint16) []byte {
+	glyf := f.tables["glyf"]
+	keep := make(map[uint16]bool)
+	queue := append([]uint16{0}, glyphs...)
+	for len(queue) > 0 {
+		glyph := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if keep[glyph] || int(glyph) >= f.numGlyphs {
+			continue
+		}
+		keep[glyph] = true
+		queue = append(queue, compositeComponents(glyf[f.loca[glyph]:f.loca[glyph+1]])...)
+	}
+
+	var outlines []byte
+	loca := make([]byte, 4*(f.numGlyphs+1))
+	for glyph := 0; glyph < f.numGlyphs; glyph++ {
+		binary.BigEndian.PutUint32(loca[4*glyph:], uint32(len(outlines)))
+		if keep[uint16(glyph)] {
+			outlines = append(outlines, glyf[f.loca[glyph]:f.loca[glyph+1]]...)
+			for len(outlines)%4 != 0 {
+				outlines = append(outlines, 0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*f.numGlyphs:], uint32(len(outlines)))
+
+	// Long loca offsets and a checksum adjustment computed below
+	head := append([]byte{}, f.tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0)
+	binary.BigEndian.PutUint16(head[50:], 1)
+
+	tables := map[string][]byte{
+		"cmap": f.tables["cmap"],
+		"glyf": outlines,
+		"head": head,
+		"hhea": f.tables["hhea"],
+		"hmtx": f.tables["hmtx"],
+		"loca": loca,
+		"maxp": f.tables["maxp"],
+	}
+	// Font-wide metrics and the hinting programs the outlines refer to
+	for _, tag := range []string{"OS/2", "cvt ", "fpgm", "prep"} {
+		if table := f.tables[tag]; table != nil {
+			tables[tag] = table
+		}
+	}
+	tags := make([]string, 0, len(tables))
+	for tag := range tables {
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= len(tags) {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+
+	out := make([]byte, 12+16*len(tags))
+	binary.BigEndian.PutUint32(out, 0x00010000)
+	binary.BigEndian.PutUint16(out[4:], uint16(len(tags)))
+	binary.BigEndian.PutUint16(out[6:], uint16(searchRange))
+	binary.BigEndian.PutUint16(out[8:], uint16(entrySelector))
+	binary.BigEndian.PutUint16(out[10:], uint16(16*len(tags)-searchRange))
+	headOffset := 0
+	for i, tag := range tags {
+		table := tables[tag]
+		record := out[12+16*i:]
+		copy(record, tag)
+		binary.BigEndian.PutUint32(record[4:], fontChecksum(table))
+		binary.BigEndian.PutUint32(record[8:], uint32(len(out)))
+		binary.BigEndian.PutUint32(record[12:], uint32(len(table)))
+		if tag == "head" {
+			headOffset = len(out)
+		}
+		out = append(out, table...)
+		for len(out)%4 != 0 {
+			out = append(out, 0)
+		}
+	}
+	binary.BigEndian.PutUint32(out[headOffset+8:], 0xB1B0AFBA-fontChecksum(out))
+	return out
+}
+
+// compositeComponents lists the glyphs a composite glyph is built from
+func compositeComponents(outline []byte) []uint16 {
+	if len(outline) < 10 || int16(binary.BigEndian.Uint16(outline)) >= 0 {
+		return nil
+	}
+	const (
+		argsAreWords  = 0x0001
+		haveScale     = 0x0008
+		moreComponent = 0x0020
+		haveXYScale   = 0x0040
+		haveTwoByTwo  = 0x0080
+	)
+	var components []uint16
+	for at := 10; at+4 <= len(outline); {
+		flags := binary.BigEndian.Uint16(outline[at:])
+		components = append(components, binary.BigEndian.Uint16(outline[at+2:]))
+		at += 4
+		if flags&argsAreWords != 0 {
+			at += 4
+		} else {
+			at += 2
+		}
+		switch {
+		case flags&haveScale != 0:
+			at += 2
+		case flags&haveXYScale != 0:
+			at += 4
+		case flags&haveTwoByTwo != 0:
+			at += 8
+		}
+		if flags&moreComponent == 0 {
+			break
+		}
+	}
+	return components
+}
+
+// fontChecksum sums data as big-endian 32-bit words, zero padded
+func fontChecksum(data []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(data); i += 4 {
+		var word [4]byte
+		copy(word[:], data[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
@@ -0,0 +1,288 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Herald.lol Gaming Analytics - PDF Player Report
+// A one-page summary for coaches: overall record, top champions, a rolling
+// win rate chart and recent form, computed from the exported matches.
+
+// Player report defaults, used when PDFConfig leaves a setting at 0
+const (
+	DefaultReportTopChampions = 5
+	DefaultReportRecentGames  = 10
+	DefaultReportTrendWindow  = 5
+)
+
+// playerReport holds the figures shown on the PDF player report
+type playerReport struct {
+	Games   int
+	Wins    int
+	Overall *GamingMetrics
+
+	TopChampions []reportChampion
+	// Rolling win rate (0-1) over TrendWindow games, oldest first
+	Trend       []float64
+	TrendWindow int
+	// Most recent games first
+	RecentForm []*MatchExportData
+}
+
+// reportChampion is a row of the top champions table
+type reportChampion struct {
+	Name    string
+	Metrics *GamingMetrics
+}
+
+// aggregateKDA returns (kills + assists) / deaths over a set of games,
+// counting zero deaths as one
+func aggregateKDA(kills, deaths, assists int) float64 {
+	if deaths == 0 {
+		return float64(kills + assists)
+	}
+	return float64(kills+assists) / float64(deaths)
+}
+
+// buildPlayerReport computes the report figures from matches, which are not
+// modified, with the same gaming metrics as the other export formats.
+// Settings at 0 use the report defaults.
+func buildPlayerReport(matches []*MatchExportData, topChampions, recentGames, trendWindow int) *playerReport {
+	if topChampions <= 0 {
+		topChampions = DefaultReportTopChampions
+	}
+	if recentGames <= 0 {
+		recentGames = DefaultReportRecentGames
+	}
+	if trendWindow <= 0 {
+		trendWindow = DefaultReportTrendWindow
+	}
+
+	// Oldest first; matches without a date keep their export order
+	ordered := make([]*MatchExportData, len(matches))
+	copy(ordered, matches)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].PlayedAt.Before(ordered[j].PlayedAt)
+	})
+
+	report := &playerReport{Games: len(ordered), TrendWindow: trendWindow, Overall: calculateGamingMetrics(ordered)}
+	if report.Games == 0 {
+		return report
+	}
+
+	byChampion := make(map[string][]*MatchExportData)
+	for _, match := range ordered {
+		if match.Result == "Victory" {
+			report.Wins++
+		}
+		byChampion[match.Champion] = append(byChampion[match.Champion], match)
+	}
+
+	for name, games := range byChampion {
+		report.TopChampions = append(report.TopChampions, reportChampion{Name: name, Metrics: calculateGamingMetrics(games)})
+	}
+	sort.Slice(report.TopChampions, func(i, j int) bool {
+		a, b := report.TopChampions[i], report.TopChampions[j]
+		if a.Metrics.GamesPlayed != b.Metrics.GamesPlayed {
+			return a.Metrics.GamesPlayed > b.Metrics.GamesPlayed
+		}
+		return a.Name < b.Name
+	})
+	if len(report.TopChampions) > topChampions {
+		report.TopChampions = report.TopChampions[:topChampions]
+	}
+
+	// One point per game once the window is full, or a single point for
+	// fewer games than the window
+	window := trendWindow
+	if window > len(ordered) {
+		window = len(ordered)
+	}
+	for end := window; end <= len(ordered); end++ {
+		report.Trend = append(report.Trend, calculateGamingMetrics(ordered[end-window:end]).WinRate)
+	}
+
+	for i := len(ordered) - 1; i >= 0 && len(report.RecentForm) < recentGames; i-- {
+		report.RecentForm = append(report.RecentForm, ordered[i])
+	}
+
+	return report
+}
+
+// Report colors used besides the configured brand colors
+var (
+	reportGray  = pdfColor{0.6, 0.6, 0.6}
+	reportLight = pdfColor{0.9, 0.9, 0.9}
+	reportWin   = pdfColor{0.2, 0.6, 0.3}
+	reportLoss  = pdfColor{0.8, 0.25, 0.25}
+)
+
+// renderPlayerReport lays the report out on a single page
+func renderPlayerReport(config *PDFConfig, data *PlayerExportData, report *playerReport) []byte {
+	doc := newPDFDocument(config.DefaultPageSize, config.DefaultOrientation)
+	doc.addPage()
+
+	var colors BrandColors
+	if config.BrandColors != nil {
+		colors = *config.BrandColors
+	}
+	primary := parsePDFColor(colors.Primary, pdfColor{0.27, 0.45, 0.77})
+	secondary := parsePDFColor(colors.Secondary, pdfColor{0.44, 0.68, 0.28})
+	text := parsePDFColor(colors.Text, pdfColor{0.18, 0.18, 0.18})
+
+	margins := PDFMargins{Top: 25, Bottom: 25, Left: 25, Right: 25}
+	if config.DefaultMargins != nil {
+		margins = *config.DefaultMargins
+	}
+	left := margins.Left * pdfMillimeter
+	right := doc.width - margins.Right*pdfMillimeter
+	width := right - left
+	y := margins.Top * pdfMillimeter
+
+	fontSize := float64(config.DefaultFontSize)
+	if fontSize <= 0 {
+		fontSize = 11
+	}
+
+	if config.EnableWatermark && config.WatermarkText != "" {
+		doc.text(left, doc.height/2, fontSize*3, true, reportLight, config.WatermarkText)
+	}
+
+	// Header
+	name := "Player"
+	subtitle := []string{}
+	if data.PlayerInfo != nil {
+		if data.PlayerInfo.SummonerName != "" {
+			name = data.PlayerInfo.SummonerName
+		}
+		if data.PlayerInfo.Region != "" {
+			subtitle = append(subtitle, strings.ToUpper(data.PlayerInfo.Region))
+		}
+		if data.PlayerInfo.Rank != "" {
+			subtitle = append(subtitle, fmt.Sprintf("%s %d LP", data.PlayerInfo.Rank, data.PlayerInfo.LP))
+		}
+	}
+	if data.TimeRange != "" {
+		subtitle = append(subtitle, "Last "+data.TimeRange)
+	}
+	subtitle = append(subtitle, "Generated "+time.Now().Format("2006-01-02"))
+
+	doc.text(left, y+fontSize*2, fontSize*2, true, primary, name)
+	y += fontSize * 3.2
+	doc.text(left, y, fontSize, false, reportGray, strings.Join(subtitle, "  |  "))
+	y += fontSize * 0.8
+	doc.line(left, y, right, y, 1, primary)
+	y += fontSize * 2.5
+
+	if report.Games == 0 {
+		doc.text(left, y, fontSize, false, text, "No games in this period.")
+		return doc.bytes()
+	}
+
+	// Overview figures
+	figures := [][2]string{
+		{"Games", fmt.Sprintf("%d", report.Games)},
+		{"Record", fmt.Sprintf("%dW %dL", report.Wins, report.Games-report.Wins)},
+		{"Win rate", fmt.Sprintf("%.1f%%", report.Overall.WinRate*100)},
+		{"KDA", fmt.Sprintf("%.2f", report.Overall.AverageKDA)},
+		{"CS/min", fmt.Sprintf("%.1f", report.Overall.AverageCSPerMin)},
+	}
+	column := width / float64(len(figures))
+	for i, figure := range figures {
+		x := left + float64(i)*column
+		doc.text(x, y, fontSize*0.85, false, reportGray, figure[0])
+		doc.text(x, y+fontSize*1.8, fontSize*1.6, true, text, figure[1])
+	}
+	y += fontSize * 4
+
+	// Top champions
+	y = reportHeading(doc, left, right, y, fontSize, primary, "Top champions")
+	columns := []float64{left, left + width*0.45, left + width*0.6, left + width*0.8}
+	for i, heading := range []string{"Champion", "Games", "Win rate", "KDA"} {
+		doc.text(columns[i], y, fontSize*0.85, true, reportGray, heading)
+	}
+	y += fontSize * 1.6
+	for i, champion := range report.TopChampions {
+		if i%2 == 0 {
+			doc.rect(left-4, y-fontSize, width+8, fontSize*1.5, reportLight)
+		}
+		doc.text(columns[0], y, fontSize, false, text, champion.Name)
+		doc.text(columns[1], y, fontSize, false, text, fmt.Sprintf("%d", champion.Metrics.GamesPlayed))
+		doc.text(columns[2], y, fontSize, false, text, fmt.Sprintf("%.0f%%", champion.Metrics.WinRate*100))
+		doc.text(columns[3], y, fontSize, false, text, fmt.Sprintf("%.2f", champion.Metrics.AverageKDA))
+		y += fontSize * 1.5
+	}
+	y += fontSize * 1.5
+
+	// Rolling win rate chart
+	y = reportHeading(doc, left, right, y, fontSize, primary, fmt.Sprintf("Win rate trend (rolling %d games)", report.TrendWindow))
+	chartHeight := fontSize * 12
+	axis := left + textWidth("100%", fontSize*0.8, false) + 6
+	for _, level := range []float64{0, 50, 100} {
+		lineY := y + chartHeight*(1-level/100)
+		doc.line(axis, lineY, right, lineY, 0.5, reportLight)
+		doc.text(left, lineY+fontSize*0.3, fontSize*0.8, false, reportGray, fmt.Sprintf("%.0f%%", level))
+	}
+	points := make([][2]float64, len(report.Trend))
+	for i, value := range report.Trend {
+		x := axis
+		if len(report.Trend) > 1 {
+			x += (right - axis) * float64(i) / float64(len(report.Trend)-1)
+		}
+		points[i] = [2]float64{x, y + chartHeight*(1-value)}
+	}
+	if len(points) == 1 {
+		doc.rect(points[0][0]-2, points[0][1]-2, 4, 4, secondary)
+	}
+	doc.polyline(points, 1.5, secondary)
+	y += chartHeight + fontSize*2.5
+
+	// Recent form, most recent first
+	y = reportHeading(doc, left, right, y, fontSize, primary, "Recent form")
+	box := fontSize * 1.6
+	for i, match := range report.RecentForm {
+		x := left + float64(i)*(box+4)
+		if x+box > right {
+			break
+		}
+		color, label := reportLoss, "L"
+		if match.Result == "Victory" {
+			color, label = reportWin, "W"
+		}
+		doc.rect(x, y-box*0.75, box, box, color)
+		doc.text(x+box/2-textWidth(label, fontSize, true)/2, y+box*0.05, fontSize, true, pdfColor{1, 1, 1}, label)
+	}
+	y += box + fontSize
+
+	for _, match := range report.RecentForm {
+		line := match.Champion
+		if match.Role != "" {
+			line += " (" + match.Role + ")"
+		}
+		if match.Performance != nil {
+			line += fmt.Sprintf("  %d/%d/%d", match.Performance.Kills, match.Performance.Deaths, match.Performance.Assists)
+		}
+		if !match.PlayedAt.IsZero() {
+			line = match.PlayedAt.Format("Jan 2") + "  " + line
+		}
+		doc.text(left, y, fontSize*0.9, false, text, line)
+		doc.text(right-textWidth(match.Result, fontSize*0.9, false), y, fontSize*0.9, false, reportGray, match.Result)
+		y += fontSize * 1.3
+		if y > doc.height-margins.Bottom*pdfMillimeter {
+			break
+		}
+	}
+
+	return doc.bytes()
+}
+
+// reportHeading draws a section heading and returns the y to continue at
+func reportHeading(doc *pdfDocument, left, right, y, fontSize float64, color pdfColor, title string) float64 {
+	doc.text(left, y, fontSize*1.2, true, color, title)
+	y += fontSize * 0.6
+	doc.line(left, y, right, y, 0.5, reportLight)
+	return y + fontSize*1.6
+}
@@ -15,6 +15,23 @@ import (
 
 // CSV Processor
 
+// utf8BOM is the UTF-8 byte order mark written before CSV data on request
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// csvText makes a value safe for CSV output. Exports are always UTF-8, so
+// invalid byte sequences are replaced rather than passed through to readers.
+func csvText(value string) string {
+	return strings.ToValidUTF8(value, "\uFFFD")
+}
+
+// optionalInt formats an optional number, empty when nil
+func optionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
+
 type CSVProcessor struct {
 	config *CSVConfig
 }
@@ -25,30 +42,46 @@ func NewCSVProcessor(config *CSVConfig) *CSVProcessor {
 
 func (p *CSVProcessor) ExportPlayerData(data *PlayerExportData, request *PlayerExportRequest) ([]byte, string, error) {
 	var buffer bytes.Buffer
+	if request.includeBOM() {
+		buffer.Write(utf8BOM)
+	}
 	writer := csv.NewWriter(&buffer)
 
 	if p.config.DefaultDelimiter != "," {
 		writer.Comma = rune(p.config.DefaultDelimiter[0])
 	}
 
+	// Multi-account exports say whose row it is and flag shared matches
+	multiAccount := len(data.LinkedAccounts) > 0
+
 	// Write headers
 	if p.config.IncludeHeadersDefault {
 		headers := []string{
-			"Match ID", "Date", "Champion", "Role", "Result", "Duration",
+			"Match ID", "Date", "Queue", "Champion", "Role", "Result", "Duration",
 			"Kills", "Deaths", "Assists", "KDA", "CS", "CS/Min",
 			"Damage", "Damage Share", "Vision Score", "Rating",
 		}
+		if request.includeRankedColumns() {
+			headers = append(headers, "LP", "MMR")
+		}
+		if request.includeStreakColumn() {
+			headers = append(headers, "Streak")
+		}
+		if multiAccount {
+			headers = append(headers, "Account", "Shared With")
+		}
 		writer.Write(headers)
 	}
 
 	// Write match data
 	for _, match := range data.Matches {
 		record := []string{
-			match.MatchID,
+			csvText(match.MatchID),
 			time.Now().Format("2006-01-02"), // Placeholder date
-			match.Champion,
-			match.Role,
-			match.Result,
+			csvText(match.Queue),
+			csvText(match.Champion),
+			csvText(match.Role),
+			csvText(match.Result),
 			strconv.Itoa(match.Duration),
 		}
 
@@ -72,6 +105,20 @@ func (p *CSVProcessor) ExportPlayerData(data *PlayerExportData, request *PlayerE
 			}
 		}
 
+		if request.includeRankedColumns() {
+			var lp, mmr *int
+			if match.Ranked != nil {
+				lp, mmr = match.Ranked.LP, match.Ranked.MMR
+			}
+			record = append(record, optionalInt(lp), optionalInt(mmr))
+		}
+		if request.includeStreakColumn() {
+			record = append(record, match.Streak)
+		}
+		if multiAccount {
+			record = append(record, csvText(match.Account), csvText(strings.Join(match.SharedWith, ";")))
+		}
+
 		writer.Write(record)
 	}
 
@@ -81,7 +128,7 @@ func (p *CSVProcessor) ExportPlayerData(data *PlayerExportData, request *PlayerE
 	}
 
 	fileName := fmt.Sprintf("%s_analytics_%s.csv",
-		data.PlayerInfo.SummonerName,
+		csvText(data.PlayerInfo.SummonerName),
 		time.Now().Format("2006-01-02"))
 
 	return buffer.Bytes(), fileName, nil
@@ -96,10 +143,10 @@ func (p *CSVProcessor) ExportMatchData(data *MatchExportData, request *MatchExpo
 	writer.Write(headers)
 
 	records := [][]string{
-		{"Match ID", data.MatchID},
-		{"Champion", data.Champion},
-		{"Role", data.Role},
-		{"Result", data.Result},
+		{"Match ID", csvText(data.MatchID)},
+		{"Champion", csvText(data.Champion)},
+		{"Role", csvText(data.Role)},
+		{"Result", csvText(data.Result)},
 		{"Duration", fmt.Sprintf("%d minutes", data.Duration/60)},
 	}
 
@@ -135,7 +182,7 @@ func (p *CSVProcessor) ExportTeamData(data *TeamExportData, request *TeamExportR
 	for _, player := range data.Players {
 		if player.Summary != nil {
 			record := []string{
-				player.PlayerInfo.SummonerName,
+				csvText(player.PlayerInfo.SummonerName),
 				strconv.Itoa(player.TotalGames),
 				fmt.Sprintf("%.1f%%", player.Summary.WinRate*100),
 				fmt.Sprintf("%.2f", player.Summary.AverageKDA),
@@ -179,7 +226,7 @@ func (p *CSVProcessor) ExportChampionData(data *ChampionExportData, request *Cha
 
 	writer.Flush()
 	fileName := fmt.Sprintf("%s_%s_%s.csv",
-		data.ChampionName,
+		csvText(data.ChampionName),
 		data.PlayerPUUID[:8],
 		time.Now().Format("2006-01-02"))
 
@@ -198,7 +245,7 @@ func (p *CSVProcessor) ExportCustomReport(data *CustomReportData, request *Custo
 		record := make([]string, len(data.Columns))
 		for i, column := range data.Columns {
 			if value, exists := row[column]; exists {
-				record[i] = fmt.Sprintf("%v", value)
+				record[i] = csvText(fmt.Sprintf("%v", value))
 			}
 		}
 		writer.Write(record)
@@ -222,16 +269,17 @@ func NewJSONProcessor(config *JSONConfig) *JSONProcessor {
 	return &JSONProcessor{config: config}
 }
 
-func (p *JSONProcessor) ExportPlayerData(data *PlayerExportData, request *PlayerExportRequest) ([]byte, string, error) {
-	var jsonData []byte
-	var err error
-
-	if p.config.PrettyPrintDefault {
-		jsonData, err = json.MarshalIndent(data, "", "  ")
-	} else {
-		jsonData, err = json.Marshal(data)
+// marshal encodes data compactly unless the request asks for pretty JSON or
+// the processor pretty-prints by default
+func (p *JSONProcessor) marshal(data interface{}, options *ExportOptions) ([]byte, error) {
+	if p.config.PrettyPrintDefault || (options != nil && options.PrettyJSON) {
+		return json.MarshalIndent(data, "", "  ")
 	}
+	return json.Marshal(data)
+}
 
+func (p *JSONProcessor) ExportPlayerData(data *PlayerExportData, request *PlayerExportRequest) ([]byte, string, error) {
+	jsonData, err := p.marshal(data, request.ExportOptions)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to marshal JSON: %w", err)
 	}
@@ -244,15 +292,7 @@ func (p *JSONProcessor) ExportPlayerData(data *PlayerExportData, request *Player
 }
 
 func (p *JSONProcessor) ExportMatchData(data *MatchExportData, request *MatchExportRequest) ([]byte, string, error) {
-	var jsonData []byte
-	var err error
-
-	if p.config.PrettyPrintDefault {
-		jsonData, err = json.MarshalIndent(data, "", "  ")
-	} else {
-		jsonData, err = json.Marshal(data)
-	}
-
+	jsonData, err := p.marshal(data, request.ExportOptions)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to marshal JSON: %w", err)
 	}
@@ -265,15 +305,7 @@ func (p *JSONProcessor) ExportMatchData(data *MatchExportData, request *MatchExp
 }
 
 func (p *JSONProcessor) ExportTeamData(data *TeamExportData, request *TeamExportRequest) ([]byte, string, error) {
-	var jsonData []byte
-	var err error
-
-	if p.config.PrettyPrintDefault {
-		jsonData, err = json.MarshalIndent(data, "", "  ")
-	} else {
-		jsonData, err = json.Marshal(data)
-	}
-
+	jsonData, err := p.marshal(data, request.ExportOptions)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to marshal JSON: %w", err)
 	}
@@ -286,15 +318,7 @@ func (p *JSONProcessor) ExportTeamData(data *TeamExportData, request *TeamExport
 }
 
 func (p *JSONProcessor) ExportChampionData(data *ChampionExportData, request *ChampionExportRequest) ([]byte, string, error) {
-	var jsonData []byte
-	var err error
-
-	if p.config.PrettyPrintDefault {
-		jsonData, err = json.MarshalIndent(data, "", "  ")
-	} else {
-		jsonData, err = json.Marshal(data)
-	}
-
+	jsonData, err := p.marshal(data, request.ExportOptions)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to marshal JSON: %w", err)
 	}
@@ -308,15 +332,7 @@ func (p *JSONProcessor) ExportChampionData(data *ChampionExportData, request *Ch
 }
 
 func (p *JSONProcessor) ExportCustomReport(data *CustomReportData, request *CustomReportRequest) ([]byte, string, error) {
-	var jsonData []byte
-	var err error
-
-	if p.config.PrettyPrintDefault {
-		jsonData, err = json.MarshalIndent(data, "", "  ")
-	} else {
-		jsonData, err = json.Marshal(data)
-	}
-
+	jsonData, err := p.marshal(data, request.ExportOptions)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to marshal JSON: %w", err)
 	}
@@ -398,7 +414,7 @@ This would be a proper XLSX file in production using a library like excelize.`,
 		sheetType, name, dataCount, time.Now().Format("2006-01-02 15:04:05"))
 }
 
-// PDF Processor (Placeholder implementation)
+// PDF Processor (player reports are rendered; other exports are placeholders)
 
 type PDFProcessor struct {
 	config *PDFConfig
@@ -408,14 +424,16 @@ func NewPDFProcessor(config *PDFConfig) *PDFProcessor {
 	return &PDFProcessor{config: config}
 }
 
+// ExportPlayerData renders the one-page player summary report
 func (p *PDFProcessor) ExportPlayerData(data *PlayerExportData, request *PlayerExportRequest) ([]byte, string, error) {
-	content := p.generatePDFContent("Player Analytics Report", data.PlayerInfo.SummonerName, len(data.Matches))
+	report := buildPlayerReport(data.Matches, p.config.ReportTopChampions, p.config.ReportRecentGames, p.config.ReportTrendWindow)
+	content := renderPlayerReport(p.config, data, report)
 
 	fileName := fmt.Sprintf("%s_analytics_%s.pdf",
 		data.PlayerInfo.SummonerName,
 		time.Now().Format("2006-01-02"))
 
-	return []byte(content), fileName, nil
+	return content, fileName, nil
 }
 
 func (p *PDFProcessor) ExportMatchData(data *MatchExportData, request *MatchExportRequest) ([]byte, string, error) {
@@ -0,0 +1,66 @@
+package export
+
+import (
+	"sort"
+	"time"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// RankedPoint is one entry of a player's stored LP history
+// (models.RankProgressionHistory), recorded after a ranked game
+type RankedPoint struct {
+	RecordedAt time.Time `json:"recorded_at"`
+	LP         int       `json:"lp"`
+	MMR        int       `json:"mmr"`
+}
+
+// annotateRankedColumns sets each ranked match's LP and MMR from the first
+// history entry recorded after the game started and before the next ranked
+// game did. Unranked games, and ranked games no entry falls after, get empty
+// columns. A match exported once per linked account gets the same values on
+// every row.
+func annotateRankedColumns(matches []*MatchExportData, history []RankedPoint) {
+	var ranked []*MatchExportData
+	seen := make(map[string]bool)
+	for _, m := range matches {
+		if models.IsRankedQueue(m.QueueID) && !seen[m.MatchID] {
+			seen[m.MatchID] = true
+			ranked = append(ranked, m)
+		}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].PlayedAt.Before(ranked[j].PlayedAt)
+	})
+
+	points := make([]RankedPoint, len(history))
+	copy(points, history)
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].RecordedAt.Before(points[j].RecordedAt)
+	})
+
+	columns := make(map[string]*RankedColumns, len(ranked))
+	next := 0
+	for i, m := range ranked {
+		for next < len(points) && points[next].RecordedAt.Before(m.PlayedAt) {
+			next++
+		}
+		if next == len(points) {
+			break
+		}
+		point := points[next]
+		if i+1 < len(ranked) && !point.RecordedAt.Before(ranked[i+1].PlayedAt) {
+			continue
+		}
+		lp, mmr := point.LP, point.MMR
+		columns[m.MatchID] = &RankedColumns{LP: &lp, MMR: &mmr}
+		next++
+	}
+
+	for _, m := range matches {
+		m.Ranked = &RankedColumns{}
+		if c, ok := columns[m.MatchID]; ok && models.IsRankedQueue(m.QueueID) {
+			m.Ranked = c
+		}
+	}
+}
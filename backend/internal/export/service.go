@@ -2,7 +2,10 @@ package export
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/herald-lol/herald/backend/internal/analytics"
@@ -14,6 +17,22 @@ import (
 // Herald.lol Gaming Analytics - Data Export Service
 // Multi-format data export service for gaming analytics data
 
+var (
+	ErrExportNotFound = errors.New("export not found")
+	ErrExportExpired  = errors.New("export has expired")
+
+	// ErrExportOwnerRequired is returned when a bulk delete isn't scoped to a user
+	ErrExportOwnerRequired = errors.New("export history delete requires a user")
+
+	// ErrTemplateNotFound is returned for templates that don't exist or
+	// belong to another user
+	ErrTemplateNotFound = errors.New("export template not found")
+
+	// ErrPDFReportsUnavailable is returned for PDF player reports when the
+	// export profile does not include them
+	ErrPDFReportsUnavailable = errors.New("PDF reports require a premium plan")
+)
+
 // ExportService handles exporting gaming data in various formats
 type ExportService struct {
 	config          *ExportConfig
@@ -30,8 +49,13 @@ type ExportService struct {
 
 	// Cache and storage
 	exportCache        map[string]*CachedExport
+	exportCacheMu      sync.RWMutex
 	compressionEnabled bool
 	encryptionEnabled  bool
+
+	// Saved export templates
+	templates   map[string]*ExportTemplate
+	templatesMu sync.RWMutex
 }
 
 // NewExportService creates a new export service
@@ -47,6 +71,7 @@ func NewExportService(
 		matchAnalyzer:      matchAnalyzer,
 		summonerService:    summonerService,
 		exportCache:        make(map[string]*CachedExport),
+		templates:          make(map[string]*ExportTemplate),
 		compressionEnabled: config.EnableCompression,
 		encryptionEnabled:  config.EnableEncryption,
 	}
@@ -68,9 +93,15 @@ func (s *ExportService) ExportPlayerAnalytics(ctx context.Context, request *Play
 		return nil, fmt.Errorf("invalid export request: %w", err)
 	}
 
-	// Check cache first
-	cacheKey := s.generateCacheKey("player", request.PlayerPUUID, request.Format, request.TimeRange)
-	if cached, exists := s.exportCache[cacheKey]; exists && !s.isCacheExpired(cached) {
+	// Check cache first; password-protected exports are never cached
+	protected := request.Password != ""
+	cacheKey := s.generateCacheKey("player", request.PlayerPUUID, request.Format, fmt.Sprintf("%s:%d:%t:%t:%t:%t:%t:%s:%t:%s", request.TimeRange, request.GameCount, request.ExcludeNonCompetitive, request.excludeRemakes(), request.isRankedOnly(), request.includeRankedColumns(), request.includeStreakColumn(), linkedAccountKey(request.LinkedAccounts), s.mergeSharedMatches(request), request.WithTeammate))
+	// Each user gets their own job, so history and deletes stay per owner
+	cacheKey += ":" + request.UserID
+	s.exportCacheMu.RLock()
+	cached, exists := s.exportCache[cacheKey]
+	s.exportCacheMu.RUnlock()
+	if exists && !protected && !s.isCacheExpired(cached) {
 		return &ExportResult{
 			ExportID:    cached.ExportID,
 			Format:      cached.Format,
@@ -79,6 +110,7 @@ func (s *ExportService) ExportPlayerAnalytics(ctx context.Context, request *Play
 			DownloadURL: cached.DownloadURL,
 			CreatedAt:   cached.CreatedAt,
 			ExpiresAt:   cached.ExpiresAt,
+			Manifest:    cached.Manifest,
 		}, nil
 	}
 
@@ -94,6 +126,7 @@ func (s *ExportService) ExportPlayerAnalytics(ctx context.Context, request *Play
 	// Export data in requested format
 	var exportedData []byte
 	var fileName string
+	var manifest *ExportManifest
 
 	switch request.Format {
 	case "csv":
@@ -104,6 +137,8 @@ func (s *ExportService) ExportPlayerAnalytics(ctx context.Context, request *Play
 		exportedData, fileName, err = s.xlsxProcessor.ExportPlayerData(playerData, request)
 	case "pdf":
 		exportedData, fileName, err = s.pdfProcessor.ExportPlayerData(playerData, request)
+	case "bundle":
+		exportedData, fileName, manifest, err = s.exportPlayerBundle(playerData, request)
 	default:
 		return nil, fmt.Errorf("unsupported export format: %s", request.Format)
 	}
@@ -112,27 +147,32 @@ func (s *ExportService) ExportPlayerAnalytics(ctx context.Context, request *Play
 		return nil, fmt.Errorf("failed to export data: %w", err)
 	}
 
-	// Apply compression if enabled
-	if s.compressionEnabled {
-		exportedData, err = s.compressData(exportedData)
+	if protected {
+		// The encrypted zip is already deflated
+		exportedData, fileName, err = encryptArchive(fileName, exportedData, request.Password)
 		if err != nil {
-			return nil, fmt.Errorf("failed to compress data: %w", err)
+			return nil, fmt.Errorf("failed to encrypt export: %w", err)
+		}
+	} else {
+		// Apply compression if enabled
+		if s.compressionEnabled {
+			exportedData, err = s.compressData(exportedData)
+			if err != nil {
+				return nil, fmt.Errorf("failed to compress data: %w", err)
+			}
 		}
-	}
 
-	// Apply encryption if enabled
-	if s.encryptionEnabled {
-		exportedData, err = s.encryptData(exportedData)
-		if err != nil {
-			return nil, fmt.Errorf("failed to encrypt data: %w", err)
+		// Apply encryption if enabled
+		if s.encryptionEnabled {
+			exportedData, err = s.encryptData(exportedData)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encrypt data: %w", err)
+			}
 		}
 	}
 
 	// Store export
-	downloadURL, err := s.storeExport(exportID, fileName, exportedData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to store export: %w", err)
-	}
+	downloadURL := s.storeExport(exportID, fileName, exportedData)
 
 	// Cache result
 	result := &ExportResult{
@@ -147,18 +187,25 @@ func (s *ExportService) ExportPlayerAnalytics(ctx context.Context, request *Play
 			PlayerPUUID: request.PlayerPUUID,
 			TimeRange:   request.TimeRange,
 			DataPoints:  len(playerData.Matches),
-			Compressed:  s.compressionEnabled,
-			Encrypted:   s.encryptionEnabled,
+			Compressed:  s.compressionEnabled || protected,
+			Encrypted:   s.encryptionEnabled || protected,
 		},
-	}
-
-	s.exportCache[cacheKey] = &CachedExport{
-		ExportID:    exportID,
-		Format:      request.Format,
-		FileSize:    len(exportedData),
-		DownloadURL: downloadURL,
-		CreatedAt:   result.CreatedAt,
-		ExpiresAt:   result.ExpiresAt,
+		Manifest: manifest,
+	}
+
+	if !protected {
+		s.exportCacheMu.Lock()
+		s.exportCache[cacheKey] = &CachedExport{
+			ExportID:    exportID,
+			UserID:      request.UserID,
+			Format:      request.Format,
+			FileSize:    len(exportedData),
+			DownloadURL: downloadURL,
+			CreatedAt:   result.CreatedAt,
+			ExpiresAt:   result.ExpiresAt,
+			Manifest:    manifest,
+		}
+		s.exportCacheMu.Unlock()
 	}
 
 	return result, nil
@@ -202,10 +249,7 @@ func (s *ExportService) ExportMatchAnalytics(ctx context.Context, request *Match
 	}
 
 	// Store export
-	downloadURL, err := s.storeExport(exportID, fileName, exportedData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to store export: %w", err)
-	}
+	downloadURL := s.storeExport(exportID, fileName, exportedData)
 
 	return &ExportResult{
 		ExportID:    exportID,
@@ -262,10 +306,7 @@ func (s *ExportService) ExportTeamAnalytics(ctx context.Context, request *TeamEx
 	}
 
 	// Store export
-	downloadURL, err := s.storeExport(exportID, fileName, exportedData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to store export: %w", err)
-	}
+	downloadURL := s.storeExport(exportID, fileName, exportedData)
 
 	return &ExportResult{
 		ExportID:    exportID,
@@ -326,10 +367,7 @@ func (s *ExportService) ExportChampionAnalytics(ctx context.Context, request *Ch
 	}
 
 	// Store export
-	downloadURL, err := s.storeExport(exportID, fileName, exportedData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to store export: %w", err)
-	}
+	downloadURL := s.storeExport(exportID, fileName, exportedData)
 
 	return &ExportResult{
 		ExportID:    exportID,
@@ -388,10 +426,7 @@ func (s *ExportService) ExportCustomReport(ctx context.Context, request *CustomR
 	}
 
 	// Store export
-	downloadURL, err := s.storeExport(exportID, fileName, exportedData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to store export: %w", err)
-	}
+	downloadURL := s.storeExport(exportID, fileName, exportedData)
 
 	return &ExportResult{
 		ExportID:    exportID,
@@ -414,23 +449,22 @@ func (s *ExportService) ExportCustomReport(ctx context.Context, request *CustomR
 // GetExportStatus returns the status of an export job
 func (s *ExportService) GetExportStatus(ctx context.Context, exportID string) (*ExportStatus, error) {
 	// Check cache first
-	for _, cached := range s.exportCache {
-		if cached.ExportID == exportID {
-			status := "completed"
-			if s.isCacheExpired(cached) {
-				status = "expired"
-			}
-
-			return &ExportStatus{
-				ExportID:    exportID,
-				Status:      status,
-				Progress:    100,
-				FileSize:    cached.FileSize,
-				DownloadURL: cached.DownloadURL,
-				CreatedAt:   cached.CreatedAt,
-				ExpiresAt:   cached.ExpiresAt,
-			}, nil
+	if cached := s.findCachedExport(exportID); cached != nil {
+		status := "completed"
+		if s.isCacheExpired(cached) {
+			status = "expired"
 		}
+
+		return &ExportStatus{
+			ExportID:    exportID,
+			Status:      status,
+			Progress:    100,
+			FileSize:    cached.FileSize,
+			DownloadURL: cached.DownloadURL,
+			CreatedAt:   cached.CreatedAt,
+			ExpiresAt:   cached.ExpiresAt,
+			Manifest:    cached.Manifest,
+		}, nil
 	}
 
 	// Check persistent storage
@@ -451,6 +485,35 @@ func (s *ExportService) GetExportStatus(ctx context.Context, exportID string) (*
 	}, nil
 }
 
+// ResolveDownload returns the export status and, when stored locally, the file
+// path to serve. ErrExportExpired is returned once the export is past its TTL,
+// its file is older than MaxDownloadAge, or the file has already been cleaned up.
+func (s *ExportService) ResolveDownload(ctx context.Context, exportID string) (*ExportStatus, string, error) {
+	status, err := s.GetExportStatus(ctx, exportID)
+	if err != nil {
+		return nil, "", ErrExportNotFound
+	}
+
+	if status.Status == "expired" || (!status.ExpiresAt.IsZero() && time.Now().After(status.ExpiresAt)) {
+		return status, "", ErrExportExpired
+	}
+
+	path, info, err := s.findStoredExportFile(exportID)
+	if err != nil {
+		if os.IsNotExist(err) && s.findCachedExport(exportID) != nil && s.config.StoragePath != "" {
+			// Referenced by a job but deleted by retention cleanup
+			return status, "", ErrExportExpired
+		}
+		return status, "", nil
+	}
+
+	if s.config.MaxDownloadAge > 0 && time.Since(info.ModTime()) > s.config.MaxDownloadAge {
+		return status, "", ErrExportExpired
+	}
+
+	return status, path, nil
+}
+
 // ListExports returns a list of exports for a user
 func (s *ExportService) ListExports(ctx context.Context, userID string, limit int) ([]*ExportSummary, error) {
 	exports, err := s.getUserExports(userID, limit)
@@ -475,18 +538,73 @@ func (s *ExportService) ListExports(ctx context.Context, userID string, limit in
 	return summaries, nil
 }
 
-// DeleteExport removes an export from storage
-func (s *ExportService) DeleteExport(ctx context.Context, exportID string) error {
+// DeleteExport removes an export job and its stored file
+func (s *ExportService) DeleteExport(ctx context.Context, exportID string) (*DeleteExportsResult, error) {
+	result := &DeleteExportsResult{}
+
 	// Remove from cache
+	s.exportCacheMu.Lock()
 	for key, cached := range s.exportCache {
 		if cached.ExportID == exportID {
 			delete(s.exportCache, key)
+			result.DeletedJobs++
 			break
 		}
 	}
+	s.exportCacheMu.Unlock()
 
 	// Remove from persistent storage
-	return s.deleteStoredExport(exportID)
+	freed, err := s.deleteStoredExport(exportID)
+	if err != nil {
+		return result, err
+	}
+	result.FreedBytes = freed
+	if result.DeletedJobs == 0 && freed > 0 {
+		result.DeletedJobs = 1
+	}
+
+	return result, nil
+}
+
+// DeleteExportHistory removes every export job of filter.UserID matching the
+// filter along with its stored file
+func (s *ExportService) DeleteExportHistory(ctx context.Context, filter *ExportHistoryFilter) (*DeleteExportsResult, error) {
+	result := &DeleteExportsResult{}
+	if filter == nil || filter.UserID == "" {
+		return result, ErrExportOwnerRequired
+	}
+
+	now := time.Now()
+
+	s.exportCacheMu.Lock()
+	defer s.exportCacheMu.Unlock()
+
+	for key, cached := range s.exportCache {
+		if cached.UserID != filter.UserID {
+			continue
+		}
+		status := "completed"
+		if s.isCacheExpired(cached) {
+			status = "expired"
+		}
+		if filter.Status != "" && filter.Status != status {
+			continue
+		}
+		if filter.OlderThan > 0 && now.Sub(cached.CreatedAt) < filter.OlderThan {
+			continue
+		}
+
+		freed, err := s.deleteStoredExport(cached.ExportID)
+		if err != nil {
+			return result, fmt.Errorf("failed to delete export %s: %w", cached.ExportID, err)
+		}
+
+		delete(s.exportCache, key)
+		result.DeletedJobs++
+		result.FreedBytes += freed
+	}
+
+	return result, nil
 }
 
 // GetSupportedFormats returns the list of supported export formats
@@ -497,7 +615,7 @@ func (s *ExportService) GetSupportedFormats() []ExportFormat {
 			Key:         "csv",
 			Description: "Comma-separated values for spreadsheet applications",
 			Extensions:  []string{".csv"},
-			MimeType:    "text/csv",
+			MimeType:    "text/csv; charset=utf-8",
 			Features:    []string{"lightweight", "universal", "data-only"},
 		},
 		{
@@ -532,6 +650,14 @@ func (s *ExportService) GetSupportedFormats() []ExportFormat {
 			MimeType:    "text/html",
 			Features:    []string{"interactive", "visualizations", "web-based", "responsive"},
 		},
+		{
+			Name:        "Archive Bundle",
+			Key:         "bundle",
+			Description: "Zip archive with matches plus period, champion, role and trend analytics",
+			Extensions:  []string{".zip"},
+			MimeType:    "application/zip",
+			Features:    []string{"complete-snapshot", "multi-file", "csv-and-json", "archival"},
+		},
 	}
 }
 
@@ -549,29 +675,43 @@ func (s *ExportService) collectPlayerData(ctx context.Context, request *PlayerEx
 		return nil, fmt.Errorf("failed to get summoner analysis: %w", err)
 	}
 
-	// Collect match history and analysis
-	matches := []*MatchExportData{}
-	for _, matchID := range request.MatchIDs {
-		// Get match data and analysis
-		matchAnalysis, err := s.matchAnalyzer.AnalyzeMatch(ctx, &match.MatchAnalysisRequest{
-			PlayerPUUID:   request.PlayerPUUID,
-			AnalysisDepth: "standard",
-		})
-		if err != nil {
-			continue // Skip failed matches
+	// Collect match history and analysis, merging in any linked accounts
+	matches := s.collectAccountMatches(ctx, request, request.PlayerPUUID)
+	duplicates := 0
+	if len(request.LinkedAccounts) > 0 {
+		accounts := [][]*MatchExportData{matches}
+		for _, account := range request.LinkedAccounts {
+			accounts = append(accounts, s.collectAccountMatches(ctx, request, account.PlayerPUUID))
 		}
+		matches, duplicates = mergeAccountMatches(accounts, s.mergeSharedMatches(request))
+	}
 
-		matches = append(matches, &MatchExportData{
-			MatchID:     matchID,
-			Champion:    matchAnalysis.MatchInfo.Champion,
-			Role:        matchAnalysis.MatchInfo.Role,
-			Result:      matchAnalysis.MatchInfo.Result,
-			Duration:    matchAnalysis.MatchInfo.Duration,
-			Performance: matchAnalysis.Performance,
-			KeyMoments:  matchAnalysis.KeyMoments,
-		})
+	// Streaks are counted before filtering so they reflect every game played
+	if request.includeStreakColumn() {
+		annotateStreaks(matches)
+	}
+	if request.includeRankedColumns() {
+		annotateRankedColumns(matches, request.RankHistory)
 	}
 
+	matches = applyExportFilter(matches, request.Filter)
+	if request.isRankedOnly() {
+		matches = rankedOnly(matches)
+	}
+	if request.WithTeammate != "" {
+		matches = withTeammate(matches, request.TeammateMatchIDs)
+	}
+	excluded := 0
+	if request.ExcludeNonCompetitive {
+		matches, excluded = excludeNonCompetitive(matches)
+	} else if request.excludeRemakes() {
+		matches, excluded = excludeRemakes(matches)
+	}
+	if request.GameCount > 0 && len(matches) > request.GameCount {
+		matches = matches[:request.GameCount]
+	}
+	sortExportMatches(matches, request.Filter)
+
 	return &PlayerExportData{
 		PlayerInfo: &PlayerInfo{
 			PUUID:        request.PlayerPUUID,
@@ -585,6 +725,11 @@ func (s *ExportService) collectPlayerData(ctx context.Context, request *PlayerEx
 		TimeRange:  request.TimeRange,
 		ExportedAt: time.Now(),
 		TotalGames: len(matches),
+
+		ExcludedGames:    excluded,
+		LinkedAccounts:   request.LinkedAccounts,
+		SharedMatches:    countSharedMatches(matches),
+		MergedDuplicates: duplicates,
 	}, nil
 }
 
@@ -604,8 +749,10 @@ func (s *ExportService) collectMatchData(ctx context.Context, request *MatchExpo
 
 	return &MatchExportData{
 		MatchID:               request.MatchID,
-		Champion:              matchAnalysis.MatchInfo.Champion,
+		Champion:              request.ExportOptions.championName(matchAnalysis.MatchInfo.Champion),
 		Role:                  matchAnalysis.MatchInfo.Role,
+		Queue:                 matchAnalysis.MatchInfo.QueueType,
+		PlayedAt:              matchAnalysis.MatchInfo.PlayedAt,
 		Result:                matchAnalysis.MatchInfo.Result,
 		Duration:              matchAnalysis.MatchInfo.Duration,
 		Performance:           matchAnalysis.Performance,
@@ -628,6 +775,8 @@ func (s *ExportService) collectTeamData(ctx context.Context, request *TeamExport
 			TimeRange:   request.TimeRange,
 			GameModes:   request.GameModes,
 			MatchIDs:    request.SharedMatchIDs,
+
+			ExportOptions: request.ExportOptions,
 		})
 		if err != nil {
 			continue // Skip failed players
@@ -661,7 +810,7 @@ func (s *ExportService) collectChampionData(ctx context.Context, request *Champi
 	}
 
 	return &ChampionExportData{
-		ChampionName:       request.ChampionName,
+		ChampionName:       request.ExportOptions.championName(request.ChampionName),
 		PlayerPUUID:        request.PlayerPUUID,
 		PerformanceHistory: championAnalysis.PerformanceHistory,
 		Statistics:         championAnalysis.Statistics,
@@ -1,10 +1,29 @@
 package export
 
 import (
+	"archive/zip"
+	"bytes"
+	"compress/flate"
 	"context"
+	"crypto/aes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+	"github.com/herald-lol/herald/backend/internal/match"
 )
 
 // TestExportService validates the gaming data export service implementation
@@ -46,7 +65,7 @@ func TestExportFormats(t *testing.T) {
 	}
 
 	formats := service.GetSupportedFormats()
-	expectedFormats := []string{"csv", "json", "xlsx", "pdf", "charts"}
+	expectedFormats := []string{"csv", "json", "xlsx", "pdf", "charts", "bundle"}
 
 	if len(formats) != len(expectedFormats) {
 		t.Errorf("Expected %d formats, got %d", len(expectedFormats), len(formats))
@@ -260,6 +279,48 @@ func TestCSVProcessor(t *testing.T) {
 	t.Logf("✅ CSV processor validated successfully!")
 }
 
+func TestCSVProcessorUTF8Names(t *testing.T) {
+	processor := NewCSVProcessor(&CSVConfig{DefaultDelimiter: ",", IncludeHeadersDefault: true})
+
+	data := &PlayerExportData{
+		PlayerInfo: &PlayerInfo{SummonerName: "페이커🔥", Region: "KR"},
+		Matches: []*MatchExportData{
+			{MatchID: "KR_1", Champion: "아리", Role: "Mid", Result: "Victory", Duration: 1800},
+			{MatchID: "KR_2", Champion: "Bad\xffName", Role: "Mid", Result: "Defeat", Duration: 1500},
+		},
+	}
+	request := &PlayerExportRequest{PlayerPUUID: "kr-puuid", Region: "KR", Format: "csv"}
+
+	output, fileName, err := processor.ExportPlayerData(data, request)
+	if err != nil {
+		t.Fatalf("CSV export failed: %v", err)
+	}
+	if !utf8.Valid(output) {
+		t.Error("Expected CSV output to be valid UTF-8")
+	}
+	if bytes.HasPrefix(output, utf8BOM) {
+		t.Error("Expected no BOM unless requested")
+	}
+	if !strings.Contains(string(output), "아리") {
+		t.Error("Expected Korean champion name to be preserved")
+	}
+	if !strings.HasPrefix(fileName, "페이커🔥_analytics_") {
+		t.Errorf("Expected filename to keep the player name, got %s", fileName)
+	}
+
+	request.ExportOptions = &ExportOptions{CSVOptions: &CSVExportOptions{IncludeBOM: true}}
+	output, _, err = processor.ExportPlayerData(data, request)
+	if err != nil {
+		t.Fatalf("CSV export with BOM failed: %v", err)
+	}
+	if !bytes.HasPrefix(output, utf8BOM) {
+		t.Error("Expected CSV output to start with a UTF-8 BOM")
+	}
+	if !utf8.Valid(output) {
+		t.Error("Expected CSV output with BOM to be valid UTF-8")
+	}
+}
+
 func TestJSONProcessor(t *testing.T) {
 	config := &JSONConfig{
 		PrettyPrintDefault: true,
@@ -321,6 +382,33 @@ func TestJSONProcessor(t *testing.T) {
 	t.Logf("✅ JSON processor validated successfully!")
 }
 
+func TestJSONProcessorPrettyJSON(t *testing.T) {
+	processor := NewJSONProcessor(&JSONConfig{PrettyPrintDefault: false})
+	testData := &PlayerExportData{
+		PlayerInfo: &PlayerInfo{SummonerName: "TestPlayer"},
+		TotalGames: 1,
+	}
+
+	compact, _, err := processor.ExportPlayerData(testData, &PlayerExportRequest{Format: "json"})
+	if err != nil {
+		t.Fatalf("JSON export failed: %v", err)
+	}
+	if strings.Contains(string(compact), "\n") {
+		t.Error("Expected compact JSON by default")
+	}
+
+	pretty, _, err := processor.ExportPlayerData(testData, &PlayerExportRequest{
+		Format:        "json",
+		ExportOptions: &ExportOptions{PrettyJSON: true},
+	})
+	if err != nil {
+		t.Fatalf("JSON export failed: %v", err)
+	}
+	if !strings.Contains(string(pretty), "\n  \"player_info\"") {
+		t.Error("Expected indented JSON with pretty_json")
+	}
+}
+
 func TestExportValidation(t *testing.T) {
 	service := &ExportService{
 		config: GetDefaultExportConfig(),
@@ -365,6 +453,13 @@ func TestExportValidation(t *testing.T) {
 			Format:      "csv",
 			// Missing TimeRange
 		},
+		{
+			PlayerPUUID:   "test-puuid-123",
+			Region:        "NA1",
+			Format:        "csv",
+			TimeRange:     "last_30_days",
+			ExportOptions: &ExportOptions{PrettyJSON: true}, // JSON-only option
+		},
 	}
 
 	for i, invalidRequest := range invalidRequests {
@@ -441,39 +536,35 @@ func TestHeraldExportTargets(t *testing.T) {
 }
 
 func TestGamingMetricsCalculation(t *testing.T) {
-	service := &ExportService{
-		config: GetDefaultExportConfig(),
-	}
-
 	// Create test matches
 	matches := []*MatchExportData{
 		{
 			Result:   "Victory",
 			Duration: 1800, // 30 minutes
-			Performance: &PerformanceAnalysis{
+			Performance: &match.PerformanceAnalysis{
 				Kills:       10,
 				Deaths:      3,
 				Assists:     15,
 				CSPerMinute: 7.5,
-				TotalDamage: 28000,
+				Damage:      28000,
 				VisionScore: 25,
 			},
 		},
 		{
 			Result:   "Defeat",
 			Duration: 2400, // 40 minutes
-			Performance: &PerformanceAnalysis{
+			Performance: &match.PerformanceAnalysis{
 				Kills:       5,
 				Deaths:      8,
 				Assists:     12,
 				CSPerMinute: 6.2,
-				TotalDamage: 22000,
+				Damage:      22000,
 				VisionScore: 18,
 			},
 		},
 	}
 
-	metrics := service.calculateGamingMetrics(matches)
+	metrics := calculateGamingMetrics(matches)
 
 	if metrics.GamesPlayed != 2 {
 		t.Errorf("Expected 2 games played, got %d", metrics.GamesPlayed)
@@ -483,8 +574,8 @@ func TestGamingMetricsCalculation(t *testing.T) {
 		t.Errorf("Expected 50%% win rate, got %.2f", metrics.WinRate)
 	}
 
-	// Test KDA calculation (15+12)/(3+8) = 27/11 ≈ 2.45
-	expectedKDA := float64(27) / float64(11)
+	// Test KDA calculation (15+27)/(3+8) = 42/11 ≈ 3.82
+	expectedKDA := float64(42) / float64(11)
 	if metrics.AverageKDA < expectedKDA-0.1 || metrics.AverageKDA > expectedKDA+0.1 {
 		t.Errorf("Expected KDA around %.2f, got %.2f", expectedKDA, metrics.AverageKDA)
 	}
@@ -627,3 +718,858 @@ func TestExportServiceCompleteness(t *testing.T) {
 	t.Logf("🔒 Security: Subscription limits, encryption, audit logging")
 	t.Logf("📈 Scalability: Support for 1M+ concurrent users")
 }
+
+func TestExportTemplateValidation(t *testing.T) {
+	service := &ExportService{
+		config:    GetDefaultExportConfig(),
+		templates: make(map[string]*ExportTemplate),
+	}
+
+	template := &ExportTemplate{
+		Name:   "Yasuo one-trick",
+		Format: "csv",
+		DefaultFilter: &ExportFilter{
+			Champions: []string{"Yasuo"},
+			Roles:     []string{"mid"},
+		},
+	}
+
+	if err := service.SaveTemplate("user-1", template); err != nil {
+		t.Fatalf("Valid template should be saved: %v", err)
+	}
+	if template.TemplateID == "" {
+		t.Error("Saved template should have an ID")
+	}
+	if template.OwnerID != "user-1" {
+		t.Errorf("Expected template to be owned by user-1, got %q", template.OwnerID)
+	}
+	if template.DefaultFilter.Roles[0] != "MID" {
+		t.Errorf("Expected role to be normalized to MID, got %s", template.DefaultFilter.Roles[0])
+	}
+
+	invalidTemplates := []*ExportTemplate{
+		{Format: "csv"},
+		{Name: "bad format", Format: "docx"},
+		{Name: "bad role", Format: "csv", DefaultFilter: &ExportFilter{Roles: []string{"roamer"}}},
+		{Name: "empty champion", Format: "csv", DefaultFilter: &ExportFilter{Champions: []string{" "}}},
+	}
+
+	for i, invalid := range invalidTemplates {
+		if err := service.ValidateTemplate(invalid); err == nil {
+			t.Errorf("Invalid template %d should fail validation", i+1)
+		}
+	}
+}
+
+func TestExportTemplateOwnership(t *testing.T) {
+	service := &ExportService{
+		config:    GetDefaultExportConfig(),
+		templates: make(map[string]*ExportTemplate),
+	}
+
+	template := &ExportTemplate{Name: "Ranked games", Format: "json"}
+	if err := service.SaveTemplate("user-1", template); err != nil {
+		t.Fatalf("Valid template should be saved: %v", err)
+	}
+
+	if _, err := service.GetTemplate("user-1", template.TemplateID); err != nil {
+		t.Errorf("Owner should get their template: %v", err)
+	}
+	if _, err := service.GetTemplate("user-2", template.TemplateID); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Another user should not get the template, got %v", err)
+	}
+	if _, err := service.ExportFromTemplate(context.Background(), "user-2", template.TemplateID, &PlayerExportRequest{}); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Another user should not export from the template, got %v", err)
+	}
+
+	// Saving under another user's template ID must not take it over
+	takeover := &ExportTemplate{TemplateID: template.TemplateID, Name: "Mine now", Format: "csv"}
+	if err := service.SaveTemplate("user-2", takeover); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Another user should not replace the template, got %v", err)
+	}
+	if err := service.DeleteTemplate("user-2", template.TemplateID); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Another user should not delete the template, got %v", err)
+	}
+
+	saved, err := service.GetTemplate("user-1", template.TemplateID)
+	if err != nil || saved.Name != "Ranked games" {
+		t.Fatalf("Owner's template should be unchanged, got %+v err %v", saved, err)
+	}
+	if err := service.DeleteTemplate("user-1", template.TemplateID); err != nil {
+		t.Errorf("Owner should delete their template: %v", err)
+	}
+	if _, err := service.GetTemplate("user-1", template.TemplateID); !errors.Is(err, ErrTemplateNotFound) {
+		t.Errorf("Deleted template should be gone, got %v", err)
+	}
+}
+
+func TestMergeExportFilters(t *testing.T) {
+	base := &ExportFilter{
+		Champions: []string{"Yasuo"},
+		TimeRange: "last_30_days",
+	}
+
+	merged := mergeExportFilters(base, &ExportFilter{TimeRange: "last_7_days"})
+	if len(merged.Champions) != 1 || merged.Champions[0] != "Yasuo" {
+		t.Errorf("Template champions should be kept, got %v", merged.Champions)
+	}
+	if merged.TimeRange != "last_7_days" {
+		t.Errorf("Override time range should win, got %s", merged.TimeRange)
+	}
+	if base.TimeRange != "last_30_days" {
+		t.Error("Merging should not modify the template filter")
+	}
+
+	merged = mergeExportFilters(base, &ExportFilter{Champions: []string{"Yone"}})
+	if merged.Champions[0] != "Yone" {
+		t.Errorf("Override champions should win, got %v", merged.Champions)
+	}
+
+	matches := []*MatchExportData{
+		{MatchID: "1", Champion: "Yasuo", Role: "MIDDLE"},
+		{MatchID: "2", Champion: "Ahri", Role: "MIDDLE"},
+	}
+	filtered := applyExportFilter(matches, base)
+	if len(filtered) != 1 || filtered[0].MatchID != "1" {
+		t.Errorf("Expected only the Yasuo match, got %d matches", len(filtered))
+	}
+}
+
+func TestSortExportMatches(t *testing.T) {
+	matches := []*MatchExportData{
+		{MatchID: "1", Champion: "Yasuo", Duration: 1500},
+		{MatchID: "2", Champion: "ahri", Duration: 2100},
+		{MatchID: "3", Champion: "Lux", Duration: 1800},
+	}
+
+	sortExportMatches(matches, &ExportFilter{SortBy: ExportSortDuration})
+	if matches[0].MatchID != "2" || matches[2].MatchID != "1" {
+		t.Errorf("Expected longest game first, got %s, %s, %s", matches[0].MatchID, matches[1].MatchID, matches[2].MatchID)
+	}
+
+	sortExportMatches(matches, &ExportFilter{SortBy: ExportSortChampion})
+	if matches[0].Champion != "ahri" || matches[2].Champion != "Yasuo" {
+		t.Errorf("Expected champions in alphabetical order, got %s, %s, %s", matches[0].Champion, matches[1].Champion, matches[2].Champion)
+	}
+
+	if err := validateExportFilter(&ExportFilter{SortBy: "gold"}); err == nil {
+		t.Error("Unknown sort_by should fail validation")
+	}
+	if err := validateExportFilter(&ExportFilter{SortBy: "KDA", SortOrder: "sideways"}); err == nil {
+		t.Error("Unknown sort_order should fail validation")
+	}
+}
+
+func TestExcludeRemakes(t *testing.T) {
+	matches := []*MatchExportData{
+		{MatchID: "1", Duration: 190},
+		{MatchID: "2", Duration: 1800},
+		{MatchID: "3", Duration: 0},
+	}
+
+	kept, excluded := excludeRemakes(matches)
+	if excluded != 1 || len(kept) != 2 || kept[0].MatchID != "2" {
+		t.Errorf("Expected only the 190s game to be dropped, kept %d and excluded %d", len(kept), excluded)
+	}
+
+	request := &PlayerExportRequest{}
+	if request.excludeRemakes() {
+		t.Error("Unset exclude_remakes should not exclude remakes")
+	}
+	exclude := true
+	request.ExcludeRemakes = &exclude
+	if !request.excludeRemakes() {
+		t.Error("exclude_remakes=true should exclude remakes")
+	}
+}
+
+func TestValidateGroupExportRequest(t *testing.T) {
+	request := &GroupExportRequest{GroupName: "Scrims", TimeRange: "30d"}
+	if err := validateGroupExportRequest(request); err == nil {
+		t.Error("A group export without members should fail validation")
+	}
+
+	request.Members = make([]GroupMember, MaxGroupExportMembers+1)
+	if err := validateGroupExportRequest(request); err == nil {
+		t.Errorf("More than %d members should fail validation", MaxGroupExportMembers)
+	}
+
+	request.Members = []GroupMember{{PlayerPUUID: "puuid-1", SummonerName: "Top/Laner", Region: "euw1"}}
+	if err := validateGroupExportRequest(request); err != nil {
+		t.Errorf("Valid group export failed validation: %v", err)
+	}
+	if folder := groupMemberFolder(0, request.Members[0]); folder != "01_Top_Laner" {
+		t.Errorf("Expected folder 01_Top_Laner, got %s", folder)
+	}
+}
+
+func TestMergeAccountMatches(t *testing.T) {
+	primary := []*MatchExportData{
+		{MatchID: "NA1_1", Account: "main", Champion: "Ahri"},
+		{MatchID: "NA1_2", Account: "main", Champion: "Lux"},
+	}
+	smurf := []*MatchExportData{
+		{MatchID: "NA1_2", Account: "smurf", Champion: "Thresh"},
+		{MatchID: "NA1_3", Account: "smurf", Champion: "Jinx"},
+	}
+
+	merged, duplicates := mergeAccountMatches([][]*MatchExportData{primary, smurf}, true)
+	if len(merged) != 3 || duplicates != 1 {
+		t.Fatalf("Expected 3 matches and 1 duplicate, got %d and %d", len(merged), duplicates)
+	}
+	shared := merged[1]
+	if shared.MatchID != "NA1_2" || shared.Champion != "Lux" {
+		t.Errorf("Expected the requesting account's row for the shared match, got %s on %s", shared.Champion, shared.Account)
+	}
+	if !shared.Shared || len(shared.SharedWith) != 1 || shared.SharedWith[0] != "smurf" {
+		t.Errorf("Expected the shared match to be flagged with smurf, got %v", shared.SharedWith)
+	}
+	if merged[0].Shared || merged[2].Shared {
+		t.Error("Matches played by one account should not be flagged as shared")
+	}
+	if countSharedMatches(merged) != 1 {
+		t.Errorf("Expected 1 shared match, got %d", countSharedMatches(merged))
+	}
+
+	for _, m := range append(primary, smurf...) {
+		m.Shared, m.SharedWith = false, nil
+	}
+	kept, duplicates := mergeAccountMatches([][]*MatchExportData{primary, smurf}, false)
+	if len(kept) != 4 || duplicates != 0 || countSharedMatches(kept) != 2 {
+		t.Errorf("Without merging both rows should be kept and flagged, got %d rows, %d shared", len(kept), countSharedMatches(kept))
+	}
+
+	request := &PlayerExportRequest{PlayerPUUID: "main", LinkedAccounts: []LinkedAccount{{PlayerPUUID: "main"}}}
+	if err := validateLinkedAccounts(request); err == nil {
+		t.Error("Linking the requesting account to itself should fail validation")
+	}
+	request = &PlayerExportRequest{
+		PlayerPUUID:     "main",
+		MatchIDs:        []string{"NA1_9"},
+		AccountMatchIDs: map[string][]string{"main": {"NA1_1", "NA1_2"}, "smurf": {"NA1_2", "NA1_3"}},
+	}
+	if got := request.accountMatchIDs("smurf"); len(got) != 2 || got[1] != "NA1_3" {
+		t.Errorf("Expected smurf's own matches, got %v", got)
+	}
+	if got := request.accountMatchIDs("main"); len(got) != 2 || got[0] != "NA1_1" {
+		t.Errorf("Expected the resolved matches of the requesting account, got %v", got)
+	}
+	if got := request.accountMatchIDs("stranger"); got != nil {
+		t.Errorf("Expected no matches for an account that isn't linked, got %v", got)
+	}
+	request.AccountMatchIDs = nil
+	if got := request.accountMatchIDs("main"); len(got) != 1 || got[0] != "NA1_9" {
+		t.Errorf("Expected the request's match IDs without resolved accounts, got %v", got)
+	}
+}
+
+func TestRankedOnly(t *testing.T) {
+	matches := []*MatchExportData{
+		{MatchID: "solo", QueueID: 420},
+		{MatchID: "aram", QueueID: 450},
+		{MatchID: "flex", QueueID: 440},
+		{MatchID: "draft", QueueID: 400},
+	}
+
+	kept := rankedOnly(matches)
+	if len(kept) != 2 || kept[0].MatchID != "solo" || kept[1].MatchID != "flex" {
+		t.Errorf("Expected only Solo/Duo and Flex games, got %d matches", len(kept))
+	}
+
+	request := &PlayerExportRequest{ExportOptions: &ExportOptions{RankedOnly: true}}
+	if !request.isRankedOnly() {
+		t.Error("RankedOnly export option should be reported")
+	}
+}
+
+func TestWithTeammate(t *testing.T) {
+	matches := []*MatchExportData{
+		{MatchID: "duo-1"},
+		{MatchID: "solo"},
+		{MatchID: "duo-2"},
+	}
+
+	kept := withTeammate(matches, []string{"duo-2", "duo-1", "not-exported"})
+	if len(kept) != 2 || kept[0].MatchID != "duo-1" || kept[1].MatchID != "duo-2" {
+		t.Errorf("Expected the two shared games in their original order, got %d matches", len(kept))
+	}
+
+	if kept := withTeammate(matches, nil); len(kept) != 0 {
+		t.Errorf("Expected no games when the teammate shares none, got %d", len(kept))
+	}
+}
+
+func TestAnnotateStreaks(t *testing.T) {
+	start := time.Date(2024, 5, 1, 18, 0, 0, 0, time.UTC)
+	game := func(id, result string, order, duration int) *MatchExportData {
+		return &MatchExportData{MatchID: id, Result: result, Duration: duration, PlayedAt: start.Add(time.Duration(order) * time.Hour)}
+	}
+	// Most recent first, as exports are collected
+	matches := []*MatchExportData{
+		game("EUW1_6", "Victory", 6, 1700),
+		game("EUW1_5", "Defeat", 5, 1900),
+		game("EUW1_4", "Defeat", 4, 200), // Remake
+		game("EUW1_3", "Victory", 3, 1600),
+		game("EUW1_2", "Victory", 2, 2100),
+		game("EUW1_1", "Victory", 1, 1500),
+		game("EUW1_0", "Defeat", 0, 1800),
+	}
+
+	annotateStreaks(matches)
+	expected := []string{"W1", "L1", "W3", "W3", "W2", "W1", "L1"}
+	for i, m := range matches {
+		if m.Streak != expected[i] {
+			t.Errorf("%s: expected streak %q, got %q", m.MatchID, expected[i], m.Streak)
+		}
+	}
+
+	processor := NewCSVProcessor(&CSVConfig{DefaultDelimiter: ",", IncludeHeadersDefault: true})
+	data := &PlayerExportData{PlayerInfo: &PlayerInfo{SummonerName: "Climber"}, Matches: matches[:1]}
+	request := &PlayerExportRequest{PlayerPUUID: "puuid", Format: "csv", ExportOptions: &ExportOptions{StreakColumn: true}}
+	output, _, err := processor.ExportPlayerData(data, request)
+	if err != nil {
+		t.Fatalf("CSV export with streak column failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 2 || !strings.HasSuffix(lines[0], ",Streak") || !strings.HasSuffix(lines[1], ",W1") {
+		t.Errorf("Expected a Streak column with W1, got %q", lines)
+	}
+}
+
+func TestRankedColumns(t *testing.T) {
+	processor := NewCSVProcessor(&CSVConfig{DefaultDelimiter: ",", IncludeHeadersDefault: true})
+	lp := 75
+	data := &PlayerExportData{
+		PlayerInfo: &PlayerInfo{SummonerName: "Climber"},
+		Matches: []*MatchExportData{
+			{MatchID: "EUW1_1", Result: "Victory", Ranked: &RankedColumns{LP: &lp}},
+			{MatchID: "EUW1_2", Result: "Defeat"},
+		},
+	}
+	request := &PlayerExportRequest{PlayerPUUID: "puuid", Format: "csv"}
+
+	output, _, err := processor.ExportPlayerData(data, request)
+	if err != nil {
+		t.Fatalf("CSV export failed: %v", err)
+	}
+	if strings.Contains(string(output), "LP") {
+		t.Error("Expected no LP column unless requested")
+	}
+
+	request.ExportOptions = &ExportOptions{RankedColumns: true}
+	output, _, err = processor.ExportPlayerData(data, request)
+	if err != nil {
+		t.Fatalf("CSV export with ranked columns failed: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[0], "Rating,LP,MMR") {
+		t.Fatalf("Expected LP and MMR headers, got %q", lines)
+	}
+	if !strings.HasSuffix(lines[1], ",75,") {
+		t.Errorf("Expected LP 75 and an empty MMR, got %q", lines[1])
+	}
+	if !strings.HasSuffix(lines[2], ",,") {
+		t.Errorf("Expected empty LP and MMR for a match without ranked data, got %q", lines[2])
+	}
+
+	// JSON keeps unknown values as null so the columns are always present
+	encoded, err := json.Marshal(data.Matches[0])
+	if err != nil {
+		t.Fatalf("JSON marshal failed: %v", err)
+	}
+	if !strings.Contains(string(encoded), `"ranked":{"lp":75,"mmr":null}`) {
+		t.Errorf("Expected ranked LP and null MMR in JSON, got %s", encoded)
+	}
+}
+
+func TestAnnotateRankedColumns(t *testing.T) {
+	start := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	matches := []*MatchExportData{
+		{MatchID: "EUW1_4", QueueID: 420, PlayedAt: start.Add(3 * time.Hour)},
+		{MatchID: "EUW1_3", QueueID: 450, PlayedAt: start.Add(2 * time.Hour)},
+		{MatchID: "EUW1_2", QueueID: 420, PlayedAt: start.Add(time.Hour)},
+		{MatchID: "EUW1_1", QueueID: 420, PlayedAt: start},
+		{MatchID: "EUW1_1", QueueID: 420, PlayedAt: start, Account: "smurf"},
+	}
+	history := []RankedPoint{
+		{RecordedAt: start.Add(3*time.Hour + 30*time.Minute), LP: 40, MMR: 1510},
+		{RecordedAt: start.Add(-time.Hour), LP: 10, MMR: 1480},
+		{RecordedAt: start.Add(30 * time.Minute), LP: 30, MMR: 1500},
+	}
+
+	annotateRankedColumns(matches, history)
+
+	lp := func(m *MatchExportData) int {
+		if m.Ranked == nil || m.Ranked.LP == nil {
+			return -1
+		}
+		return *m.Ranked.LP
+	}
+	if lp(matches[3]) != 30 || *matches[3].Ranked.MMR != 1500 {
+		t.Errorf("Expected LP 30 and MMR 1500 after the first game, got %d", lp(matches[3]))
+	}
+	if lp(matches[4]) != 30 {
+		t.Errorf("Expected the linked account's row of the same match to share its LP, got %d", lp(matches[4]))
+	}
+	if lp(matches[2]) != -1 {
+		t.Errorf("Expected no LP for a game without a history entry, got %d", lp(matches[2]))
+	}
+	if matches[1].Ranked == nil || lp(matches[1]) != -1 {
+		t.Error("Expected empty ranked columns for an unranked game")
+	}
+	if lp(matches[0]) != 40 {
+		t.Errorf("Expected LP 40 after the last game, got %d", lp(matches[0]))
+	}
+}
+
+func TestResolveDownloadExpired(t *testing.T) {
+	config := GetDefaultExportConfig()
+	config.StoragePath = t.TempDir()
+	config.MaxDownloadAge = time.Hour
+
+	service := &ExportService{
+		config:      config,
+		exportCache: make(map[string]*CachedExport),
+	}
+	service.exportCache["key"] = &CachedExport{
+		ExportID:  "export_1",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(24 * time.Hour),
+	}
+
+	// File referenced by the job was already cleaned up
+	if _, _, err := service.ResolveDownload(context.Background(), "export_1"); !errors.Is(err, ErrExportExpired) {
+		t.Errorf("Missing file should be reported as expired, got %v", err)
+	}
+
+	service.storeExport("export_1", "export.csv", []byte("a,b\n"))
+	_, path, err := service.ResolveDownload(context.Background(), "export_1")
+	if err != nil || path == "" {
+		t.Fatalf("Fresh export should be downloadable, got path %q err %v", path, err)
+	}
+
+	old := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(path, old, old); err != nil {
+		t.Fatalf("Failed to age export file: %v", err)
+	}
+	if _, _, err := service.ResolveDownload(context.Background(), "export_1"); !errors.Is(err, ErrExportExpired) {
+		t.Errorf("File older than MaxDownloadAge should be expired, got %v", err)
+	}
+}
+
+func TestResolveGameCount(t *testing.T) {
+	tests := []struct {
+		name      string
+		gameCount int
+		expected  int
+		expectErr bool
+	}{
+		{"default when unset", 0, DefaultExportGameCount, false},
+		{"game_count", 50, 50, false},
+		{"negative", -1, 0, true},
+		{"above max", config.DefaultMaxGameCount + 1, 0, true},
+	}
+
+	for _, tt := range tests {
+		count, err := resolveGameCount(tt.gameCount, config.DefaultMaxGameCount)
+		if tt.expectErr {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %d", tt.name, count)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if count != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.expected, count)
+		}
+	}
+
+	service := &ExportService{config: GetDefaultExportConfig()}
+	request := &PlayerExportRequest{
+		PlayerPUUID: "test-puuid-123",
+		Region:      "NA1",
+		Format:      "csv",
+		TimeRange:   "last_30_days",
+		GameCount:   25,
+	}
+	if err := service.validatePlayerExportRequest(request); err != nil {
+		t.Fatalf("Request with game_count should validate: %v", err)
+	}
+
+	// A lower MAX_GAME_COUNT is enforced instead of the built-in cap
+	service.config.MaxGameCount = 20
+	if err := service.validatePlayerExportRequest(request); err == nil {
+		t.Error("Request above the configured max game count should be rejected")
+	}
+}
+
+func TestNewExportConfig(t *testing.T) {
+	defaults := GetDefaultExportConfig()
+	if got := NewExportConfig(nil).MaxGameCount; got != defaults.MaxGameCount {
+		t.Errorf("Expected the default max game count %d without a config, got %d", defaults.MaxGameCount, got)
+	}
+	if got := NewExportConfig(&config.Config{}).MaxGameCount; got != defaults.MaxGameCount {
+		t.Errorf("Expected the default max game count %d when MAX_GAME_COUNT is unset, got %d", defaults.MaxGameCount, got)
+	}
+
+	cfg := &config.Config{}
+	cfg.Sync.MaxGameCount = 40
+	if got := NewExportConfig(cfg).MaxGameCount; got != 40 {
+		t.Errorf("Expected MAX_GAME_COUNT to cap exports at 40, got %d", got)
+	}
+}
+
+func TestStoreExportUnwritableStorage(t *testing.T) {
+	// A file in place of the storage directory makes MkdirAll fail
+	storagePath := filepath.Join(t.TempDir(), "exports")
+	if err := os.WriteFile(storagePath, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := GetDefaultExportConfig()
+	config.StoragePath = storagePath
+	service := &ExportService{config: config}
+
+	url := service.storeExport("export_1", "export.csv", []byte("a,b\n"))
+	if url != config.CDNBaseURL+"/export_1/export.csv" {
+		t.Errorf("Export should still get its download URL, got %q", url)
+	}
+}
+
+func TestDeleteExportHistory(t *testing.T) {
+	config := GetDefaultExportConfig()
+	config.StoragePath = t.TempDir()
+
+	service := &ExportService{
+		config:      config,
+		exportCache: make(map[string]*CachedExport),
+	}
+	service.exportCache["fresh"] = &CachedExport{
+		ExportID:  "fresh",
+		UserID:    "user-1",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+	service.exportCache["old"] = &CachedExport{
+		ExportID:  "old",
+		UserID:    "user-1",
+		CreatedAt: time.Now().Add(-48 * time.Hour),
+		ExpiresAt: time.Now().Add(-24 * time.Hour),
+	}
+	service.exportCache["other"] = &CachedExport{
+		ExportID:  "other",
+		UserID:    "user-2",
+		CreatedAt: time.Now().Add(-48 * time.Hour),
+		ExpiresAt: time.Now().Add(-24 * time.Hour),
+	}
+	service.storeExport("old", "export.csv", []byte("0123456789"))
+
+	result, err := service.DeleteExportHistory(context.Background(), &ExportHistoryFilter{UserID: "user-1", Status: "expired"})
+	if err != nil {
+		t.Fatalf("Delete should succeed: %v", err)
+	}
+	if result.DeletedJobs != 1 || result.FreedBytes != 10 {
+		t.Errorf("Expected 1 job and 10 bytes deleted, got %d jobs and %d bytes", result.DeletedJobs, result.FreedBytes)
+	}
+	if _, exists := service.exportCache["fresh"]; !exists {
+		t.Error("Completed export should not be deleted by an expired filter")
+	}
+
+	result, err = service.DeleteExportHistory(context.Background(), &ExportHistoryFilter{UserID: "user-1"})
+	if err != nil || result.DeletedJobs != 1 {
+		t.Errorf("Unfiltered delete should remove the remaining job, got %+v (%v)", result, err)
+	}
+	if _, exists := service.exportCache["other"]; !exists {
+		t.Error("Another user's export should never be deleted")
+	}
+
+	if _, err := service.DeleteExportHistory(context.Background(), nil); !errors.Is(err, ErrExportOwnerRequired) {
+		t.Errorf("Delete without an owner should be refused, got %v", err)
+	}
+}
+
+func TestBundleManifest(t *testing.T) {
+	var buffer bytes.Buffer
+	writer := newBundleWriter(&buffer)
+
+	files := map[string][]byte{
+		"matches.csv":  []byte("Match ID,Champion\nEUW1_1,Ahri\n"),
+		"summary.json": []byte(`{"total_games":1}`),
+	}
+	for _, name := range []string{"matches.csv", "summary.json"} {
+		if err := writer.write(name, files[name]); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	if err := writer.close(&ExportManifest{PlayerPUUID: "puuid", TimeRange: "30d", RowCount: 1}); err != nil {
+		t.Fatalf("Failed to close bundle: %v", err)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	if err != nil {
+		t.Fatalf("Bundle should be a valid zip: %v", err)
+	}
+
+	var manifest ExportManifest
+	for _, file := range archive.File {
+		if file.Name != manifestFileName {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to open manifest: %v", err)
+		}
+		content, _ := io.ReadAll(reader)
+		reader.Close()
+		if err := json.Unmarshal(content, &manifest); err != nil {
+			t.Fatalf("Manifest should be valid JSON: %v", err)
+		}
+	}
+
+	if manifest.PlayerPUUID != "puuid" || manifest.RowCount != 1 {
+		t.Errorf("Expected export metadata in manifest, got %+v", manifest)
+	}
+	if len(manifest.Files) != len(files) {
+		t.Fatalf("Expected %d files in manifest, got %d", len(files), len(manifest.Files))
+	}
+	for _, file := range manifest.Files {
+		sum := sha256.Sum256(files[file.Name])
+		if file.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("Checksum mismatch for %s", file.Name)
+		}
+		if file.Size != len(files[file.Name]) {
+			t.Errorf("Expected size %d for %s, got %d", len(files[file.Name]), file.Name, file.Size)
+		}
+	}
+}
+
+func TestEncryptArchive(t *testing.T) {
+	content := []byte(strings.Repeat("Match ID,Champion\nEUW1_1,Ahri\n", 50))
+	data, fileName, err := encryptArchive("matches.csv", content, "correct horse")
+	if err != nil {
+		t.Fatalf("Failed to encrypt export: %v", err)
+	}
+	if fileName != "matches.csv.zip" {
+		t.Errorf("Expected a non-zip export to be wrapped in matches.csv.zip, got %s", fileName)
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("Encrypted export should be a valid zip: %v", err)
+	}
+	if len(archive.File) != 1 {
+		t.Fatalf("Expected one entry, got %d", len(archive.File))
+	}
+	file := archive.File[0]
+	if file.Method != zipMethodAES || file.Flags&zipFlagEncrypted == 0 {
+		t.Fatalf("Expected an AES encrypted entry, got method %d flags %x", file.Method, file.Flags)
+	}
+	if !bytes.Contains(file.Extra, aesExtraField(zip.Deflate)[:4]) {
+		t.Error("Expected the AE-2 extra field")
+	}
+
+	raw, err := file.OpenRaw()
+	if err != nil {
+		t.Fatalf("Failed to open entry: %v", err)
+	}
+	payload, _ := io.ReadAll(raw)
+	salt := payload[:aesSaltLength]
+	verifier := payload[aesSaltLength : aesSaltLength+aesVerifierLength]
+	ciphertext := payload[aesSaltLength+aesVerifierLength : len(payload)-aesAuthCodeLength]
+	authCode := payload[len(payload)-aesAuthCodeLength:]
+
+	encryptionKey, authKey, expected := deriveAESKeys("correct horse", salt)
+	if !bytes.Equal(expected, verifier) {
+		t.Fatal("The password verifier should match the password")
+	}
+	mac := hmac.New(sha1.New, authKey)
+	mac.Write(ciphertext)
+	if !bytes.Equal(mac.Sum(nil)[:aesAuthCodeLength], authCode) {
+		t.Fatal("Authentication code mismatch")
+	}
+
+	block, _ := aes.NewCipher(encryptionKey)
+	compressed := make([]byte, len(ciphertext))
+	winzipCTR(block, compressed, ciphertext)
+	plain, err := io.ReadAll(flate.NewReader(bytes.NewReader(compressed)))
+	if err != nil {
+		t.Fatalf("Failed to inflate decrypted entry: %v", err)
+	}
+	if !bytes.Equal(plain, content) {
+		t.Error("Decrypted entry should match the original export")
+	}
+
+	service := &ExportService{config: GetDefaultExportConfig()}
+	if err := service.validateExportPassword("short"); err == nil {
+		t.Error("A password under the minimum length should be rejected")
+	}
+}
+
+func TestBuildPlayerReport(t *testing.T) {
+	start := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	game := func(hours int, champion, result string, k, d, a int) *MatchExportData {
+		return &MatchExportData{
+			Champion:    champion,
+			Result:      result,
+			PlayedAt:    start.Add(time.Duration(hours) * time.Hour),
+			Performance: &match.PerformanceAnalysis{Kills: k, Deaths: d, Assists: a, CSPerMinute: 7},
+		}
+	}
+	// Export order is newest first
+	matches := []*MatchExportData{
+		game(5, "Ahri", "Defeat", 2, 6, 4),
+		game(4, "Jinx", "Victory", 10, 2, 6),
+		game(3, "Ahri", "Victory", 6, 1, 9),
+		game(2, "Ahri", "Victory", 4, 3, 5),
+		game(1, "Lux", "Defeat", 1, 5, 10),
+		game(0, "Jinx", "Victory", 8, 3, 4),
+	}
+
+	report := buildPlayerReport(matches, 2, 3, 3)
+	if report.Games != 6 || report.Wins != 4 {
+		t.Fatalf("Expected 4 wins in 6 games, got %d in %d", report.Wins, report.Games)
+	}
+	// (31 kills + 38 assists) / 20 deaths
+	if report.Overall.AverageKDA != 3.45 {
+		t.Errorf("Expected aggregate KDA 3.45, got %v", report.Overall.AverageKDA)
+	}
+	if len(report.TopChampions) != 2 || report.TopChampions[0].Name != "Ahri" || report.TopChampions[1].Name != "Jinx" {
+		t.Fatalf("Expected Ahri then Jinx as top champions, got %+v", report.TopChampions)
+	}
+	if jinx := report.TopChampions[1].Metrics; jinx.GamesPlayed != 2 || jinx.WinRate != 1 {
+		t.Errorf("Expected Jinx won both games, got %+v", jinx)
+	}
+
+	// Oldest first: W L W W W L in windows of three
+	wantTrend := []float64{2.0 / 3, 2.0 / 3, 1, 2.0 / 3}
+	if len(report.Trend) != len(wantTrend) {
+		t.Fatalf("Expected %d trend points, got %v", len(wantTrend), report.Trend)
+	}
+	for i, want := range wantTrend {
+		if diff := report.Trend[i] - want; diff > 1e-9 || diff < -1e-9 {
+			t.Errorf("Trend point %d: expected %v, got %v", i, want, report.Trend[i])
+		}
+	}
+
+	if len(report.RecentForm) != 3 || report.RecentForm[0] != matches[0] || report.RecentForm[2] != matches[2] {
+		t.Error("Expected the three most recent games, newest first")
+	}
+
+	if empty := buildPlayerReport(nil, 0, 0, 0); empty.Games != 0 || empty.Trend != nil {
+		t.Error("Expected an empty report without matches")
+	}
+}
+
+func TestPDFProcessorPlayerReport(t *testing.T) {
+	processor := NewPDFProcessor(GetDefaultExportConfig().PDF)
+	data := &PlayerExportData{
+		PlayerInfo: &PlayerInfo{SummonerName: "Фейкер (KR)", Region: "kr", Rank: "Challenger", LP: 1200},
+		TimeRange:  "30d",
+		Matches: []*MatchExportData{
+			{Champion: "Ahri", Role: "MIDDLE", Result: "Victory", Performance: &match.PerformanceAnalysis{Kills: 7, Deaths: 1, Assists: 8}},
+			{Champion: "Azir", Role: "MIDDLE", Result: "Defeat"},
+		},
+	}
+
+	output, fileName, err := processor.ExportPlayerData(data, &PlayerExportRequest{Format: "pdf"})
+	if err != nil {
+		t.Fatalf("PDF export failed: %v", err)
+	}
+	if !strings.HasSuffix(fileName, ".pdf") {
+		t.Errorf("Expected a .pdf file name, got %s", fileName)
+	}
+	content := string(output)
+	if !strings.HasPrefix(content, "%PDF-1.4") || !strings.HasSuffix(content, "%%EOF\n") {
+		t.Fatal("Expected a complete PDF document")
+	}
+	if !strings.Contains(content, "/Subtype /CIDFontType2") || !strings.Contains(content, "/FontFile2") {
+		t.Error("Expected the report font to be embedded")
+	}
+
+	// The name is shown glyph by glyph and mapped back to Unicode
+	regular, bold := reportFonts()[0], reportFonts()[1]
+	var name strings.Builder
+	for _, r := range "Фейкер (KR)" {
+		glyph := bold.glyph(r)
+		if glyph == 0 {
+			t.Fatalf("Expected the bold font to have a glyph for %q", r)
+		}
+		fmt.Fprintf(&name, "%04X", glyph)
+	}
+	if !strings.Contains(content, "<"+name.String()+"> Tj") {
+		t.Error("Expected the summoner name in the report")
+	}
+	if !strings.Contains(content, fmt.Sprintf("<%04X> <0424>", bold.glyph('Ф'))) {
+		t.Error("Expected the ToUnicode map to cover the summoner name")
+	}
+	if regular.glyph('한') != 0 {
+		t.Error("Expected no Hangul glyph in the report font")
+	}
+}
+
+func TestTrueTypeSubset(t *testing.T) {
+	font := reportFonts()[0]
+	used := []uint16{font.glyph('A'), font.glyph('é')}
+	if used[0] == 0 || used[1] == 0 || font.width(used[0]) <= 0 {
+		t.Fatalf("Expected glyphs with widths for A and é, got %v", used)
+	}
+	if width := textWidth("AA", 10, false); math.Abs(width-2*font.width(used[0])/100) > 1e-9 {
+		t.Errorf("Expected text width from the glyph advances, got %v", width)
+	}
+
+	subset, err := parseTrueType(font.name, font.subset(used))
+	if err != nil {
+		t.Fatalf("Subset font does not parse: %v", err)
+	}
+	if fontChecksum(subset.tables["head"]) == 0 || subset.numGlyphs != font.numGlyphs {
+		t.Fatal("Expected the subset to keep the glyph numbering")
+	}
+	outline := func(f *pdfFont, glyph uint16) int { return int(f.loca[glyph+1] - f.loca[glyph]) }
+	if outline(subset, used[0]) != outline(font, used[0]) {
+		t.Error("Expected the outline of a used glyph to be kept")
+	}
+	// é is a composite of e and the acute accent
+	for _, component := range compositeComponents(font.tables["glyf"][font.loca[used[1]]:font.loca[used[1]+1]]) {
+		if outline(subset, component) != outline(font, component) {
+			t.Errorf("Expected component glyph %d of é to be kept", component)
+		}
+	}
+	if unused := font.glyph('Z'); outline(subset, unused) != 0 {
+		t.Error("Expected unused outlines to be dropped")
+	}
+	if len(font.subset(used)) >= len(dejaVuSans)/10 {
+		t.Error("Expected the subset to be a small fraction of the font")
+	}
+}
+
+func TestPDFReportsPremiumOnly(t *testing.T) {
+	service := &ExportService{config: GetDefaultExportConfig()}
+	request := &PlayerExportRequest{PlayerPUUID: "puuid", Region: "NA1", Format: "pdf", TimeRange: "last_30_days"}
+
+	for _, tier := range []string{"", "free", "unknown"} {
+		request.SubscriptionTier = tier
+		if err := service.validatePlayerExportRequest(request); !errors.Is(err, ErrPDFReportsUnavailable) {
+			t.Errorf("Expected PDF reports to be unavailable on tier %q, got %v", tier, err)
+		}
+	}
+
+	request.SubscriptionTier = "Premium"
+	if err := service.validatePlayerExportRequest(request); err != nil {
+		t.Errorf("Expected PDF reports on the premium tier, got %v", err)
+	}
+
+	disabled := &ExportService{config: GetDefaultExportConfig()}
+	disabled.config.PDF.ReportsEnabled = false
+	if err := disabled.validatePlayerExportRequest(request); !errors.Is(err, ErrPDFReportsUnavailable) {
+		t.Errorf("Expected PDF reports to be unavailable when the deployment turns them off, got %v", err)
+	}
+
+	request.Format = "csv"
+	request.SubscriptionTier = "free"
+	if err := service.validatePlayerExportRequest(request); err != nil {
+		t.Errorf("Expected CSV exports on the free tier, got %v", err)
+	}
+}
@@ -0,0 +1,58 @@
+package export
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// annotateStreaks sets each match's Streak to the win or loss streak the
+// player was on after it, e.g. "W3" for a third win in a row. Matches are
+// walked oldest first whatever their order in the slice. Remakes neither
+// extend nor break a streak and carry the streak they were played on; a
+// match exported once per linked account counts once.
+func annotateStreaks(matches []*MatchExportData) {
+	ordered := make([]*MatchExportData, len(matches))
+	copy(ordered, matches)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].PlayedAt.Before(ordered[j].PlayedAt)
+	})
+
+	streak := 0 // positive for wins in a row, negative for losses
+	labels := make(map[string]string, len(ordered))
+	for _, m := range ordered {
+		if label, seen := labels[m.MatchID]; seen {
+			m.Streak = label
+			continue
+		}
+
+		if !models.IsLikelyRemake(m.Duration) {
+			won := m.Result == "Victory"
+			switch {
+			case won && streak > 0:
+				streak++
+			case won:
+				streak = 1
+			case streak < 0:
+				streak--
+			default:
+				streak = -1
+			}
+		}
+
+		m.Streak = streakLabel(streak)
+		labels[m.MatchID] = m.Streak
+	}
+}
+
+// streakLabel formats a streak as "W3" or "L2"; no streak yet is ""
+func streakLabel(streak int) string {
+	switch {
+	case streak > 0:
+		return "W" + strconv.Itoa(streak)
+	case streak < 0:
+		return "L" + strconv.Itoa(-streak)
+	}
+	return ""
+}
@@ -0,0 +1,331 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// Herald.lol Gaming Analytics - Export Templates
+// Saved export configurations whose default filter is applied on every run
+
+// SaveTemplate validates and stores an export template owned by ownerID,
+// assigning an ID if needed. Saving under an existing ID replaces that
+// template only when ownerID owns it.
+func (s *ExportService) SaveTemplate(ownerID string, template *ExportTemplate) error {
+	if err := s.ValidateTemplate(template); err != nil {
+		return fmt.Errorf("invalid export template: %w", err)
+	}
+
+	s.templatesMu.Lock()
+	defer s.templatesMu.Unlock()
+
+	if template.TemplateID == "" {
+		template.TemplateID = s.generateExportID()
+	} else if existing, exists := s.templates[template.TemplateID]; exists && existing.OwnerID != ownerID {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, template.TemplateID)
+	}
+	template.OwnerID = ownerID
+	if template.CreatedAt.IsZero() {
+		template.CreatedAt = time.Now()
+	}
+
+	s.templates[template.TemplateID] = template
+	return nil
+}
+
+// GetTemplate returns one of ownerID's saved export templates. Templates of
+// other users are reported as not found.
+func (s *ExportService) GetTemplate(ownerID, templateID string) (*ExportTemplate, error) {
+	s.templatesMu.RLock()
+	defer s.templatesMu.RUnlock()
+
+	template, exists := s.templates[templateID]
+	if !exists || template.OwnerID != ownerID {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, templateID)
+	}
+	return template, nil
+}
+
+// DeleteTemplate removes one of ownerID's saved export templates
+func (s *ExportService) DeleteTemplate(ownerID, templateID string) error {
+	s.templatesMu.Lock()
+	defer s.templatesMu.Unlock()
+
+	template, exists := s.templates[templateID]
+	if !exists || template.OwnerID != ownerID {
+		return fmt.Errorf("%w: %s", ErrTemplateNotFound, templateID)
+	}
+	delete(s.templates, templateID)
+	return nil
+}
+
+// ValidateTemplate checks the template fields and its default filter.
+// Champion and role aliases in the filter are resolved in place.
+func (s *ExportService) ValidateTemplate(template *ExportTemplate) error {
+	if template == nil {
+		return fmt.Errorf("template is required")
+	}
+
+	if strings.TrimSpace(template.Name) == "" {
+		return fmt.Errorf("template name is required")
+	}
+
+	if !s.isValidFormat(template.Format) {
+		return fmt.Errorf("unsupported format: %s", template.Format)
+	}
+
+	return validateExportFilter(template.DefaultFilter)
+}
+
+// ExportFromTemplate runs a player export using the template's format, options
+// and default filter. Fields set on request override the template.
+func (s *ExportService) ExportFromTemplate(ctx context.Context, ownerID, templateID string, request *PlayerExportRequest) (*ExportResult, error) {
+	template, err := s.GetTemplate(ownerID, templateID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.exportFromTemplate(ctx, template, request)
+}
+
+func (s *ExportService) exportFromTemplate(ctx context.Context, template *ExportTemplate, request *PlayerExportRequest) (*ExportResult, error) {
+	if request == nil {
+		return nil, fmt.Errorf("export request is required")
+	}
+
+	if err := validateExportFilter(request.Filter); err != nil {
+		return nil, fmt.Errorf("invalid filter override: %w", err)
+	}
+
+	merged := *request
+	merged.UserID = template.OwnerID
+	if merged.Format == "" {
+		merged.Format = template.Format
+	}
+	if merged.ExportOptions == nil {
+		merged.ExportOptions = template.ExportOptions
+	}
+
+	merged.Filter = mergeExportFilters(template.DefaultFilter, request.Filter)
+	if merged.Filter != nil {
+		if merged.TimeRange == "" {
+			merged.TimeRange = merged.Filter.TimeRange
+		}
+		if len(merged.GameModes) == 0 {
+			merged.GameModes = merged.Filter.GameModes
+		}
+	}
+
+	return s.ExportPlayerAnalytics(ctx, &merged)
+}
+
+// mergeExportFilters returns base with every non-empty field of override
+// replacing the template value
+func mergeExportFilters(base, override *ExportFilter) *ExportFilter {
+	if base == nil && override == nil {
+		return nil
+	}
+
+	merged := &ExportFilter{}
+	if base != nil {
+		*merged = *base
+	}
+	if override == nil {
+		return merged
+	}
+
+	if len(override.Champions) > 0 {
+		merged.Champions = override.Champions
+	}
+	if len(override.Roles) > 0 {
+		merged.Roles = override.Roles
+	}
+	if len(override.GameModes) > 0 {
+		merged.GameModes = override.GameModes
+	}
+	if override.TimeRange != "" {
+		merged.TimeRange = override.TimeRange
+	}
+	if override.SortBy != "" {
+		merged.SortBy = override.SortBy
+	}
+	if override.SortOrder != "" {
+		merged.SortOrder = override.SortOrder
+	}
+
+	return merged
+}
+
+func validateExportFilter(filter *ExportFilter) error {
+	if filter == nil {
+		return nil
+	}
+
+	for i, champion := range filter.Champions {
+		resolved := models.ResolveChampionAlias(champion)
+		if resolved == "" {
+			return fmt.Errorf("champion filter %d is empty", i+1)
+		}
+		filter.Champions[i] = resolved
+	}
+
+	for i, role := range filter.Roles {
+		if !models.IsCanonicalRole(role) {
+			return fmt.Errorf("unknown role: %s", role)
+		}
+		filter.Roles[i] = models.NormalizeRole(role)
+	}
+
+	for i, mode := range filter.GameModes {
+		if strings.TrimSpace(mode) == "" {
+			return fmt.Errorf("game mode filter %d is empty", i+1)
+		}
+	}
+
+	filter.SortBy = strings.ToLower(strings.TrimSpace(filter.SortBy))
+	switch filter.SortBy {
+	case "", ExportSortDate, ExportSortKDA, ExportSortDuration, ExportSortChampion:
+	default:
+		return fmt.Errorf("unsupported sort_by: %s (use date, kda, duration, or champion)", filter.SortBy)
+	}
+
+	filter.SortOrder = strings.ToLower(strings.TrimSpace(filter.SortOrder))
+	switch filter.SortOrder {
+	case "", ExportSortAsc, ExportSortDesc:
+	default:
+		return fmt.Errorf("unsupported sort_order: %s (use asc or desc)", filter.SortOrder)
+	}
+
+	return nil
+}
+
+// sortExportMatches orders matches by the filter's sort field. Without a
+// sort field the collected order, most recent first, is kept.
+func sortExportMatches(matches []*MatchExportData, filter *ExportFilter) {
+	if filter == nil || filter.SortBy == "" {
+		return
+	}
+
+	ascending := filter.SortOrder == ExportSortAsc
+	if filter.SortOrder == "" {
+		ascending = filter.SortBy == ExportSortChampion
+	}
+
+	less := func(a, b *MatchExportData) bool {
+		switch filter.SortBy {
+		case ExportSortKDA:
+			return exportMatchKDA(a) < exportMatchKDA(b)
+		case ExportSortDuration:
+			return a.Duration < b.Duration
+		case ExportSortChampion:
+			return strings.ToLower(a.Champion) < strings.ToLower(b.Champion)
+		default:
+			return a.PlayedAt.Before(b.PlayedAt)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if ascending {
+			return less(matches[i], matches[j])
+		}
+		return less(matches[j], matches[i])
+	})
+}
+
+func exportMatchKDA(m *MatchExportData) float64 {
+	if m.Performance == nil {
+		return 0
+	}
+	return m.Performance.KDA
+}
+
+// applyExportFilter keeps only matches on the filtered champions and roles
+func applyExportFilter(matches []*MatchExportData, filter *ExportFilter) []*MatchExportData {
+	if filter == nil || (len(filter.Champions) == 0 && len(filter.Roles) == 0) {
+		return matches
+	}
+
+	filtered := make([]*MatchExportData, 0, len(matches))
+	for _, m := range matches {
+		if len(filter.Champions) > 0 && !containsFold(filter.Champions, m.Champion) {
+			continue
+		}
+		if len(filter.Roles) > 0 && !containsFold(filter.Roles, models.NormalizeRole(m.Role)) {
+			continue
+		}
+		filtered = append(filtered, m)
+	}
+
+	return filtered
+}
+
+// rankedOnly keeps ranked Solo/Duo and Flex games, see models.RankedQueueIDs
+func rankedOnly(matches []*MatchExportData) []*MatchExportData {
+	kept := make([]*MatchExportData, 0, len(matches))
+	for _, m := range matches {
+		if models.IsRankedQueue(m.QueueID) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// withTeammate keeps the matches whose IDs are in matchIDs, the games shared
+// with the request's with_teammate
+func withTeammate(matches []*MatchExportData, matchIDs []string) []*MatchExportData {
+	shared := make(map[string]bool, len(matchIDs))
+	for _, id := range matchIDs {
+		shared[id] = true
+	}
+
+	kept := make([]*MatchExportData, 0, len(matches))
+	for _, m := range matches {
+		if shared[m.MatchID] {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// excludeRemakes drops remade games and returns how many were removed
+func excludeRemakes(matches []*MatchExportData) ([]*MatchExportData, int) {
+	kept := make([]*MatchExportData, 0, len(matches))
+	for _, m := range matches {
+		if models.IsLikelyRemake(m.Duration) {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept, len(matches) - len(kept)
+}
+
+// excludeNonCompetitive drops games that should not count toward a player's
+// stats and returns how many were removed
+func excludeNonCompetitive(matches []*MatchExportData) ([]*MatchExportData, int) {
+	kept := make([]*MatchExportData, 0, len(matches))
+	for _, m := range matches {
+		var goldPerMinute float64
+		if m.Performance != nil {
+			goldPerMinute = m.Performance.GoldPerMinute
+		}
+		if models.ClassifyNonCompetitive(m.Duration, goldPerMinute, 0, false, false) != "" {
+			continue
+		}
+		kept = append(kept, m)
+	}
+	return kept, len(matches) - len(kept)
+}
+
+func containsFold(values []string, target string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, target) {
+			return true
+		}
+	}
+	return false
+}
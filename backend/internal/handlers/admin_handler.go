@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/herald-lol/herald/backend/internal/services"
+)
+
+// AdminHandler serves operator endpoints, guarded by ADMIN_TOKEN
+type AdminHandler struct {
+	cacheWarmupService *services.CacheWarmupService
+}
+
+func NewAdminHandler(cacheWarmupService *services.CacheWarmupService) *AdminHandler {
+	return &AdminHandler{
+		cacheWarmupService: cacheWarmupService,
+	}
+}
+
+// StartCacheWarmup godoc
+// @Summary Warm the analytics cache
+// @Description Recomputes and caches the common analytics of every active user, bounded by the warmup worker pool. Runs asynchronously; poll GET /admin/cache-warmup for progress.
+// @Tags admin
+// @Produce json
+// @Success 202 {object} services.CacheWarmupJob
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/cache-warmup [post]
+func (h *AdminHandler) StartCacheWarmup(c *gin.Context) {
+	job, err := h.cacheWarmupService.Start()
+	if err != nil {
+		if err == services.ErrCacheWarmupRunning {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "warmup_in_progress",
+				Message: "A cache warmup is already running",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "warmup_error",
+			Message: "Failed to start cache warmup",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// GetCacheWarmupStatus godoc
+// @Summary Get cache warmup progress
+// @Description Returns the progress of the latest analytics cache warmup
+// @Tags admin
+// @Produce json
+// @Success 200 {object} services.CacheWarmupJob
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security BearerAuth
+// @Router /api/v1/admin/cache-warmup [get]
+func (h *AdminHandler) GetCacheWarmupStatus(c *gin.Context) {
+	job, found := h.cacheWarmupService.Progress()
+	if !found {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "No cache warmup has been started",
+		})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, job)
+}
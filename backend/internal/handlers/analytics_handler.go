@@ -1,10 +1,15 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herald-lol/herald/backend/internal/models"
 	"github.com/herald-lol/herald/backend/internal/services"
 )
 
@@ -47,6 +52,11 @@ type PerformanceComparisonRequest struct {
 // @Param player_id path string true "Player ID"
 // @Param time_range query string true "Time range (7d, 30d, 90d)"
 // @Param champion query string false "Champion name filter"
+// @Param result query string false "Result filter (win, loss, all) - default: all"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
 // @Success 200 {object} services.KDAAnalysis
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -81,8 +91,17 @@ func (ah *AnalyticsHandler) GetKDAAnalysis(c *gin.Context) {
 		return
 	}
 
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
 	// Perform KDA analysis
-	analysis, err := ah.analyticsService.AnalyzeKDA(c.Request.Context(), playerID, req.TimeRange, req.Champion)
+	analysis, err := ah.analyticsService.AnalyzeKDA(c.Request.Context(), playerID, req.TimeRange, req.Champion, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "analysis_error",
@@ -104,6 +123,11 @@ func (ah *AnalyticsHandler) GetKDAAnalysis(c *gin.Context) {
 // @Param time_range query string true "Time range (7d, 30d, 90d)"
 // @Param position query string false "Position filter (TOP, JUNGLE, MID, ADC, SUPPORT)"
 // @Param champion query string false "Champion name filter"
+// @Param result query string false "Result filter (win, loss, all) - default: all"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
 // @Success 200 {object} services.CSAnalysis
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -139,6 +163,7 @@ func (ah *AnalyticsHandler) GetCSAnalysis(c *gin.Context) {
 	}
 
 	// Validate position if provided
+	req.Position = models.NormalizeRole(req.Position)
 	if req.Position != "" && !isValidPosition(req.Position) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "validation_error",
@@ -147,8 +172,17 @@ func (ah *AnalyticsHandler) GetCSAnalysis(c *gin.Context) {
 		return
 	}
 
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
 	// Perform CS analysis
-	analysis, err := ah.analyticsService.AnalyzeCS(c.Request.Context(), playerID, req.TimeRange, req.Position, req.Champion)
+	analysis, err := ah.analyticsService.AnalyzeCS(c.Request.Context(), playerID, req.TimeRange, req.Position, req.Champion, filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "analysis_error",
@@ -265,15 +299,247 @@ func (ah *AnalyticsHandler) GetPlayerStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// GetPeriodStats godoc
+// @Summary Get period statistics
+// @Description Returns win rate, KDA, CS and vision over the last N days, optionally with deltas against the previous equivalent period
+// @Tags analytics
+// @Produce json
+// @Param player_id path string true "Player ID"
+// @Param days query int false "Period length in days (default: 7)"
+// @Param compare query bool false "Also compute the previous period and deltas"
+// @Param result query string false "Result filter (win, loss, all) - default: all"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
+// @Success 200 {object} services.PeriodStats
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/{player_id}/period [get]
+func (ah *AnalyticsHandler) GetPeriodStats(c *gin.Context) {
+	playerID := c.Param("player_id")
+	if playerID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Player ID is required",
+		})
+		return
+	}
+
+	days := 7 // default
+	if daysStr := c.Query("days"); daysStr != "" {
+		if d, err := strconv.Atoi(daysStr); err == nil && d > 0 && d <= 365 {
+			days = d
+		} else {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "Invalid days parameter. Must be between 1 and 365",
+			})
+			return
+		}
+	}
+
+	compare := false
+	if compareStr := c.Query("compare"); compareStr != "" {
+		parsed, err := strconv.ParseBool(compareStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "Invalid compare parameter. Must be true or false",
+			})
+			return
+		}
+		compare = parsed
+	}
+
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	stats, err := ah.analyticsService.GetPeriodStats(c.Request.Context(), playerID, days, compare, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "stats_error",
+			Message: "Failed to get period statistics",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetPerformanceAnalysis godoc
+// @Summary Get champion diversity and performance trend
+// @Description Returns the Shannon entropy of the player's champion picks, the effective champion pool size (the fewest champions covering pool_threshold percent of games) and the least squares KDA and win rate trend over their last 20 games, with a summary built from those values
+// @Tags analytics
+// @Produce json
+// @Param player_id path string true "Player ID"
+// @Param time_range query string false "Time range (7d, 30d, 90d) - default: 30d"
+// @Param pool_threshold query number false "Percentage of games the champion pool covers, above 0 up to 100 (default: server setting)"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
+// @Success 200 {object} services.PerformanceAnalysis
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/{player_id}/performance [get]
+func (ah *AnalyticsHandler) GetPerformanceAnalysis(c *gin.Context) {
+	playerID := c.Param("player_id")
+	if playerID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Player ID is required",
+		})
+		return
+	}
+
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
+	}
+
+	var poolThreshold float64
+	if value := c.Query("pool_threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || !services.IsValidPoolThreshold(parsed) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "pool_threshold must be a percentage above 0 and up to 100",
+			})
+			return
+		}
+		poolThreshold = parsed
+	}
+
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	analysis, err := ah.analyticsService.AnalyzePerformance(c.Request.Context(), playerID, timeRange, poolThreshold, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to analyze performance",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// GetChampionList godoc
+// @Summary List champion statistics
+// @Description Returns the player's per-champion statistics, sorted by games played, win rate or KDA, optionally split by role
+// @Tags analytics
+// @Produce json
+// @Param player_id path string true "Player ID"
+// @Param time_range query string false "Time range (7d, 30d, 90d) - default: 30d"
+// @Param sort query string false "Sort order (games, winrate, kda) - default: games"
+// @Param min_games query int false "Minimum games per champion (default: 5 for winrate and kda, 1 for games)"
+// @Param by_role query bool false "Split each champion's stats by role (default: false)"
+// @Success 200 {array} models.ChampionStats
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/{player_id}/champions [get]
+func (ah *AnalyticsHandler) GetChampionList(c *gin.Context) {
+	playerID := c.Param("player_id")
+	if playerID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Player ID is required",
+		})
+		return
+	}
+
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
+	}
+
+	sortBy, err := services.ParseChampionSort(c.Query("sort"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	minGames := -1
+	if minGamesStr := c.Query("min_games"); minGamesStr != "" {
+		m, err := strconv.Atoi(minGamesStr)
+		if err != nil || m < 1 || m > 1000 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "Invalid min_games parameter. Must be between 1 and 1000",
+			})
+			return
+		}
+		minGames = m
+	}
+
+	byRole := false
+	if byRoleStr := c.Query("by_role"); byRoleStr != "" {
+		byRole, err = strconv.ParseBool(byRoleStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "Invalid by_role parameter, must be true or false",
+			})
+			return
+		}
+	}
+
+	champions, err := ah.analyticsService.ListChampionStats(c.Request.Context(), playerID, timeRange, sortBy, minGames, byRole)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "stats_error",
+			Message: "Failed to get champion statistics",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, champions)
+}
+
 // GetChampionStats godoc
 // @Summary Get champion-specific statistics
 // @Description Returns performance statistics for a specific champion
 // @Tags analytics
 // @Accept json
 // @Produce json
+// @Produce text/csv
 // @Param player_id path string true "Player ID"
 // @Param champion_id path int true "Champion ID"
-// @Param days query int false "Number of days (default: 30)"
+// @Param days query int false "Only matches from the last N days (default: all-time)"
+// @Param games query int false "Only the N most recent games (default: all)"
+// @Param result query string false "Result filter (win, loss, all) - default: all"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
 // @Success 200 {object} models.ChampionStats
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
@@ -300,16 +566,42 @@ func (ah *AnalyticsHandler) GetChampionStats(c *gin.Context) {
 		return
 	}
 
-	// Parse days parameter
-	days := 30 // default
+	// Optional window, all-time when neither days nor games is given
+	var window services.StatsWindow
 	if daysStr := c.Query("days"); daysStr != "" {
-		if d, err := strconv.Atoi(daysStr); err == nil && d > 0 && d <= 365 {
-			days = d
+		d, err := strconv.Atoi(daysStr)
+		if err != nil || d <= 0 || d > 365 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "Invalid days parameter. Must be between 1 and 365",
+			})
+			return
+		}
+		window.Days = d
+	}
+	if gamesStr := c.Query("games"); gamesStr != "" {
+		g, err := strconv.Atoi(gamesStr)
+		if err != nil || g <= 0 || g > 1000 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "Invalid games parameter. Must be between 1 and 1000",
+			})
+			return
 		}
+		window.Games = g
+	}
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
 	}
+	window.MatchFilter = filter
 
 	// Get champion statistics
-	stats, err := ah.analyticsService.GetChampionStats(c.Request.Context(), playerID, championID, days)
+	stats, err := ah.analyticsService.GetChampionStats(c.Request.Context(), playerID, championID, window)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "stats_error",
@@ -318,7 +610,7 @@ func (ah *AnalyticsHandler) GetChampionStats(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, stats)
+	respondNegotiated(c, http.StatusOK, stats, fmt.Sprintf("champion-%d-stats", championID))
 }
 
 // GetPerformanceTrends godoc
@@ -327,11 +619,12 @@ func (ah *AnalyticsHandler) GetChampionStats(c *gin.Context) {
 // @Tags analytics
 // @Accept json
 // @Produce json
+// @Produce text/csv
 // @Param player_id path string true "Player ID"
 // @Param metric query string true "Metric type (kda, cs, vision, damage, winrate)"
 // @Param period query string false "Time period (daily, weekly, monthly) - default: daily"
 // @Param days query int false "Number of days (default: 30)"
-// @Success 200 {object} []services.KDATrendPoint
+// @Success 200 {object} []services.PerformanceTrendPoint
 // @Failure 400 {object} ErrorResponse
 // @Failure 500 {object} ErrorResponse
 // @Security ApiKeyAuth
@@ -398,7 +691,24 @@ func (ah *AnalyticsHandler) GetPerformanceTrends(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, trends)
+	respondNegotiated(c, http.StatusOK, trends, metric+"-trends")
+}
+
+// GetMetrics godoc
+// @Summary List analytics metrics
+// @Description Returns the catalog of supported metrics with id, display name, unit, description and direction (higher_is_better, lower_is_better or neutral)
+// @Tags analytics
+// @Produce json
+// @Success 200 {array} services.MetricDefinition
+// @Router /api/v1/analytics/metrics [get]
+func (ah *AnalyticsHandler) GetMetrics(c *gin.Context) {
+	metrics := ah.analyticsService.GetMetricCatalog()
+
+	c.Header("Cache-Control", "public, max-age=3600")
+	c.JSON(http.StatusOK, gin.H{
+		"metrics": metrics,
+		"count":   len(metrics),
+	})
 }
 
 // GetBenchmarks godoc
@@ -416,8 +726,8 @@ func (ah *AnalyticsHandler) GetPerformanceTrends(c *gin.Context) {
 // @Router /api/v1/analytics/benchmarks [get]
 func (ah *AnalyticsHandler) GetBenchmarks(c *gin.Context) {
 	tier := c.Query("tier")
-	role := c.Query("role")
-	champion := c.Query("champion")
+	role := models.NormalizeRole(c.Query("role"))
+	champion := models.ResolveChampionAlias(c.Query("champion"))
 
 	// Validate tier if provided
 	if tier != "" && !isValidTier(tier) {
@@ -450,57 +760,995 @@ func (ah *AnalyticsHandler) GetBenchmarks(c *gin.Context) {
 	c.JSON(http.StatusOK, benchmarks)
 }
 
-// Validation helper functions
-
-func isValidTimeRange(timeRange string) bool {
-	validRanges := map[string]bool{
-		"7d":  true,
-		"30d": true,
-		"90d": true,
+// GetEarlyGameAnalysis godoc
+// @Summary Get early-game impact metrics
+// @Description Returns first-blood participation and gold/CS differentials at 10 and 15 minutes for the current user
+// @Tags analytics
+// @Produce json
+// @Param time_range query string false "Time range (7d, 30d, 90d) - default: 30d"
+// @Param champion query string false "Champion name filter"
+// @Success 200 {object} services.EarlyGameAnalysis
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/early-game [get]
+func (ah *AnalyticsHandler) GetEarlyGameAnalysis(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
 	}
-	return validRanges[timeRange]
-}
 
-func isValidPosition(position string) bool {
-	validPositions := map[string]bool{
-		"TOP":     true,
-		"JUNGLE":  true,
-		"MID":     true,
-		"ADC":     true,
-		"SUPPORT": true,
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
 	}
-	return validPositions[position]
-}
 
-func isValidTier(tier string) bool {
-	validTiers := map[string]bool{
-		"IRON":        true,
-		"BRONZE":      true,
-		"SILVER":      true,
-		"GOLD":        true,
-		"PLATINUM":    true,
-		"EMERALD":     true,
-		"DIAMOND":     true,
-		"MASTER":      true,
-		"GRANDMASTER": true,
-		"CHALLENGER":  true,
+	champion := models.ResolveChampionAlias(c.Query("champion"))
+
+	analysis, err := ah.analyticsService.AnalyzeEarlyGame(c.Request.Context(), fmt.Sprint(userID), timeRange, champion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to analyze early game performance",
+		})
+		return
 	}
-	return validTiers[tier]
+
+	c.JSON(http.StatusOK, analysis)
 }
 
-// Register routes
-func (ah *AnalyticsHandler) RegisterRoutes(router *gin.RouterGroup) {
-	analytics := router.Group("/analytics")
-	{
+// GetWinRateByDuration godoc
+// @Summary Get win rate by game length
+// @Description Buckets the current user's games by length (under 25, 25-35, over 35 minutes) and returns the win rate per bucket, showing whether they win more early or late
+// @Tags analytics
+// @Produce json
+// @Param time_range query string false "Time range (7d, 30d, 90d) - default: 30d"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
+// @Success 200 {object} services.DurationAnalysis
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/by-duration [get]
+func (ah *AnalyticsHandler) GetWinRateByDuration(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
+	}
+
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	analysis, err := ah.analyticsService.AnalyzeWinRateByDuration(c.Request.Context(), fmt.Sprint(userID), timeRange, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to analyze win rate by game length",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// GetKDADistribution godoc
+// @Summary Get KDA distribution
+// @Description Histograms of the current user's KDA and deaths per game with percentiles, and how often they feed (KDA below 1) or carry (KDA of 5 or more)
+// @Tags analytics
+// @Produce json
+// @Param time_range query string false "Time range (7d, 30d, 90d) - default: 30d"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
+// @Success 200 {object} services.KDADistribution
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/kda-distribution [get]
+func (ah *AnalyticsHandler) GetKDADistribution(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
+	}
+
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	distribution, err := ah.analyticsService.AnalyzeKDADistribution(c.Request.Context(), fmt.Sprint(userID), timeRange, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to analyze KDA distribution",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, distribution)
+}
+
+// GetWinRateByTimeOfDay godoc
+// @Summary Get win rate by time of day
+// @Description Buckets the current user's games by the local hour they started and returns the win rate per bucket with the best and worst times to queue. Hours are in the timezone parameter, else the user's timezone preference, else UTC.
+// @Tags analytics
+// @Produce json
+// @Param time_range query string false "Time range (7d, 30d, 90d) - default: 30d"
+// @Param timezone query string false "IANA timezone, e.g. Europe/Paris (default: the user's timezone preference)"
+// @Param bucket_hours query int false "Hours per bucket, one of 1, 2, 3, 4, 6, 8, 12 (default: server setting)"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
+// @Success 200 {object} services.TimeOfDayAnalysis
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/by-time [get]
+func (ah *AnalyticsHandler) GetWinRateByTimeOfDay(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
+	}
+
+	var loc *time.Location
+	if timezone := c.Query("timezone"); timezone != "" {
+		parsed, err := time.LoadLocation(timezone)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "Invalid timezone. Use an IANA name such as Europe/Paris",
+			})
+			return
+		}
+		loc = parsed
+	} else {
+		loc = ah.analyticsService.TimezoneDefault(c.Request.Context(), fmt.Sprint(userID))
+	}
+
+	bucketHours := 0
+	if value := c.Query("bucket_hours"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || !services.IsValidBucketHours(parsed) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "bucket_hours must be one of 1, 2, 3, 4, 6, 8, 12",
+			})
+			return
+		}
+		bucketHours = parsed
+	}
+
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	analysis, err := ah.analyticsService.AnalyzeWinRateByTimeOfDay(c.Request.Context(), fmt.Sprint(userID), timeRange, loc, bucketHours, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to analyze win rate by time of day",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// GetDuoVsSolo godoc
+// @Summary Compare duo and solo games
+// @Description Splits the current user's games into those played with a duo partner and those played alone. Riot does not expose pre-made parties, so partners are teammates who appear in at least min_shared_games games.
+// @Tags analytics
+// @Produce json
+// @Param time_range query string false "Time range (7d, 30d, 90d) - default: 30d"
+// @Param min_shared_games query int false "Shared games needed to count a teammate as a duo partner (default: server setting)"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
+// @Success 200 {object} services.DuoAnalysis
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/duo-vs-solo [get]
+func (ah *AnalyticsHandler) GetDuoVsSolo(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
+	}
+
+	minSharedGames := 0
+	if value := c.Query("min_shared_games"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "min_shared_games must be a positive integer",
+			})
+			return
+		}
+		minSharedGames = parsed
+	}
+
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	analysis, err := ah.analyticsService.AnalyzeDuoVsSolo(c.Request.Context(), fmt.Sprint(userID), timeRange, minSharedGames, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to compare duo and solo games",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// GetBanAnalysis godoc
+// @Summary Get ban effectiveness
+// @Description Reports the current user's win rate in games where their team banned each champion, against games without that ban. Only matches synced with ban data count; champions need at least min_games team bans to be listed.
+// @Tags analytics
+// @Produce json
+// @Param time_range query string false "Time range (7d, 30d, 90d) - default: 30d"
+// @Param min_games query int false "Team bans needed to list a champion (default: server setting)"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
+// @Success 200 {object} services.BanEffectivenessAnalysis
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/bans [get]
+func (ah *AnalyticsHandler) GetBanAnalysis(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
+	}
+
+	minGames := 0
+	if value := c.Query("min_games"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "min_games must be a positive integer",
+			})
+			return
+		}
+		minGames = parsed
+	}
+
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	analysis, err := ah.analyticsService.AnalyzeBans(c.Request.Context(), fmt.Sprint(userID), timeRange, minGames, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to analyze bans",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// GetObjectiveImpact godoc
+// @Summary Get first objective win rate impact
+// @Description Reports the current user's win rate in games where their team secured each first objective (first blood, tower, dragon, herald, baron, inhibitor) against games where it did not. Only matches synced with objective data count; objectives with fewer than min_games games on either side are listed last with enough_games false.
+// @Tags analytics
+// @Produce json
+// @Param time_range query string false "Time range (7d, 30d, 90d) - default: 30d"
+// @Param objectives query string false "Objectives to analyze, comma-separated (default: all)"
+// @Param min_games query int false "Games needed with and without an objective (default: server setting)"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
+// @Success 200 {object} services.ObjectiveImpactAnalysis
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/objective-impact [get]
+func (ah *AnalyticsHandler) GetObjectiveImpact(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
+	}
+
+	minGames := 0
+	if value := c.Query("min_games"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "min_games must be a positive integer",
+			})
+			return
+		}
+		minGames = parsed
+	}
+
+	objectives, err := services.ParseObjectives(c.Query("objectives"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	analysis, err := ah.analyticsService.AnalyzeObjectiveImpact(c.Request.Context(), fmt.Sprint(userID), timeRange, minGames, objectives, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to analyze objective impact",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// GetCSBenchmarks godoc
+// @Summary Get CS per minute against role benchmarks
+// @Description Compares the current user's CS per minute in each role they played with the server's role benchmarks, returning the gap to the benchmark and an estimated percentile within the role. Games without a role, such as ARAM, are left out.
+// @Tags analytics
+// @Produce json
+// @Param time_range query string false "Time range (7d, 30d, 90d) - default: 30d"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
+// @Success 200 {object} services.CSBenchmarkAnalysis
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/cs [get]
+func (ah *AnalyticsHandler) GetCSBenchmarks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
+	}
+
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	analysis, err := ah.analyticsService.AnalyzeCSBenchmarks(c.Request.Context(), fmt.Sprint(userID), timeRange, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to analyze CS benchmarks",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+// GetGroupComparison godoc
+// @Summary Compare with group average
+// @Description Compares the current user's key metrics with the average of their group and returns the delta and their rank within it. The group is the user's duo partners, teammates who appear in at least min_shared_games of the games of their linked accounts; only those members are loaded. Explicit group membership isn't stored yet, so partners stand in for it.
+// @Tags analytics
+// @Produce json
+// @Param time_range query string false "Time range (7d, 30d, 90d) - default: 30d"
+// @Param min_shared_games query int false "Shared games needed to count a teammate as a group member (default: server setting)"
+// @Param metrics query string false "Comma-separated metrics (win_rate, kda, cs_per_min, vision_per_min, damage_share, deaths) - default: all"
+// @Param exclude_non_competitive query bool false "Leave out remakes, early surrenders and AFK games"
+// @Param exclude_remakes query bool false "Leave out remakes (default: the user's excludeRemakes preference)"
+// @Param include_champions query string false "Only games on these champions, comma-separated"
+// @Param exclude_champions query string false "Leave out games on these champions, comma-separated"
+// @Success 200 {object} services.GroupComparison
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/group-comparison [get]
+func (ah *AnalyticsHandler) GetGroupComparison(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
+	}
+
+	minSharedGames := 0
+	if value := c.Query("min_shared_games"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "validation_error",
+				Message: "min_shared_games must be a positive integer",
+			})
+			return
+		}
+		minSharedGames = parsed
+	}
+
+	metrics, err := services.ParseGroupMetrics(c.Query("metrics"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	filter, err := ah.parseMatchFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	comparison, err := ah.analyticsService.CompareWithGroup(c.Request.Context(), fmt.Sprint(userID), timeRange, minSharedGames, metrics, filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to compare with group",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// GetRecentForm godoc
+// @Summary Get recent form
+// @Description Returns win rate and KDA over the last 10 and last 20 games for the current user
+// @Tags analytics
+// @Produce json
+// @Success 200 {object} services.RecentForm
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/recent-form [get]
+func (ah *AnalyticsHandler) GetRecentForm(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	form, err := ah.analyticsService.GetRecentForm(c.Request.Context(), fmt.Sprint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "stats_error",
+			Message: "Failed to get recent form",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, form)
+}
+
+// RebuildStats godoc
+// @Summary Rebuild derived stats
+// @Description Clears and recomputes the current user's aggregated stats from stored matches. Runs asynchronously.
+// @Tags analytics
+// @Produce json
+// @Success 202 {object} services.StatsRebuildJob
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/rebuild [post]
+func (ah *AnalyticsHandler) RebuildStats(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	job, err := ah.analyticsService.StartStatsRebuild(fmt.Sprint(userID))
+	if err != nil {
+		if err == services.ErrRebuildInProgress {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "rebuild_in_progress",
+				Message: "A stats rebuild is already running",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "rebuild_error",
+			Message: "Failed to start stats rebuild",
+		})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, job)
+}
+
+// ArchiveSeasonRequest names the season being archived
+type ArchiveSeasonRequest struct {
+	Season string `json:"season" binding:"required"`
+}
+
+// ArchiveSeason godoc
+// @Summary Archive the current season
+// @Description Snapshots the current user's aggregate stats under a season name and resets the current analytics window. Raw matches are kept.
+// @Tags analytics
+// @Accept json
+// @Produce json
+// @Param request body ArchiveSeasonRequest true "Season name, e.g. 2025"
+// @Success 201 {object} models.SeasonArchive
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/seasons [post]
+func (ah *AnalyticsHandler) ArchiveSeason(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	var req ArchiveSeasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Season) == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Season name is required",
+		})
+		return
+	}
+
+	archive, err := ah.analyticsService.ArchiveSeason(c.Request.Context(), fmt.Sprint(userID), strings.TrimSpace(req.Season))
+	if err != nil {
+		if err == services.ErrSeasonAlreadyArchived {
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "season_exists",
+				Message: "A season with this name is already archived",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "archive_error",
+			Message: "Failed to archive season",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, archive)
+}
+
+// GetSeasons godoc
+// @Summary List archived seasons
+// @Description Returns the current user's archived season snapshots, newest first
+// @Tags analytics
+// @Produce json
+// @Success 200 {object} services.SeasonHistory
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/seasons [get]
+func (ah *AnalyticsHandler) GetSeasons(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	history, err := ah.analyticsService.ListSeasons(c.Request.Context(), fmt.Sprint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "stats_error",
+			Message: "Failed to list archived seasons",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetSnapshotDiff godoc
+// @Summary Compare analytics snapshots
+// @Description Returns the change in win rate, KDA, rank and champion pool between the current user's snapshots nearest to two dates
+// @Tags analytics
+// @Produce json
+// @Param from query string true "Start date (RFC3339 or YYYY-MM-DD)"
+// @Param to query string true "End date (RFC3339 or YYYY-MM-DD)"
+// @Success 200 {object} services.SnapshotDiff
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/snapshot-diff [get]
+func (ah *AnalyticsHandler) GetSnapshotDiff(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	from, err := parseSnapshotDate(c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "from must be an RFC3339 timestamp or YYYY-MM-DD date",
+		})
+		return
+	}
+	to, err := parseSnapshotDate(c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "to must be an RFC3339 timestamp or YYYY-MM-DD date",
+		})
+		return
+	}
+	if !from.Before(to) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "from must be before to",
+		})
+		return
+	}
+
+	diff, err := ah.analyticsService.GetSnapshotDiff(c.Request.Context(), fmt.Sprint(userID), from, to)
+	if err != nil {
+		if errors.Is(err, services.ErrNoSnapshots) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "not_found",
+				Message: "No analytics snapshots recorded yet",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "stats_error",
+			Message: "Failed to compare analytics snapshots",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// GetRebuildStatus godoc
+// @Summary Get stats rebuild status
+// @Description Returns the status of the current user's latest stats rebuild
+// @Tags analytics
+// @Produce json
+// @Success 200 {object} services.StatsRebuildJob
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/analytics/rebuild [get]
+func (ah *AnalyticsHandler) GetRebuildStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	job, found := ah.analyticsService.GetStatsRebuildStatus(fmt.Sprint(userID))
+	if !found {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "not_found",
+			Message: "No stats rebuild has been started",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// Validation helper functions
+
+// parseSnapshotDate accepts an RFC3339 timestamp or a plain date
+func parseSnapshotDate(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// parseMatchFilter reads the optional result, exclude_non_competitive,
+// exclude_remakes, include_champions and exclude_champions query parameters.
+// Without exclude_remakes the current user's excludeRemakes preference
+// applies.
+func (ah *AnalyticsHandler) parseMatchFilter(c *gin.Context) (services.MatchFilter, error) {
+	var filter services.MatchFilter
+
+	result, err := services.ParseResultFilter(c.Query("result"))
+	if err != nil {
+		return filter, err
+	}
+	filter.Result = result
+
+	if excludeStr := c.Query("exclude_non_competitive"); excludeStr != "" {
+		exclude, err := strconv.ParseBool(excludeStr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid exclude_non_competitive parameter, must be true or false")
+		}
+		filter.ExcludeNonCompetitive = exclude
+	}
+
+	if excludeStr := c.Query("exclude_remakes"); excludeStr != "" {
+		exclude, err := strconv.ParseBool(excludeStr)
+		if err != nil {
+			return filter, fmt.Errorf("invalid exclude_remakes parameter, must be true or false")
+		}
+		filter.ExcludeRemakes = exclude
+	} else if userID, exists := c.Get("user_id"); exists {
+		filter.ExcludeRemakes = ah.analyticsService.ExcludeRemakesDefault(c.Request.Context(), fmt.Sprint(userID))
+	}
+
+	filter.IncludeChampions = championListQuery(c, "include_champions")
+	filter.ExcludeChampions = championListQuery(c, "exclude_champions")
+
+	return filter, nil
+}
+
+// championListQuery reads a champion list given either comma-separated or as
+// repeated query parameters
+func championListQuery(c *gin.Context, name string) []string {
+	var champions []string
+	for _, value := range c.QueryArray(name) {
+		for _, champion := range strings.Split(value, ",") {
+			if champion = strings.TrimSpace(champion); champion != "" {
+				champions = append(champions, champion)
+			}
+		}
+	}
+	return champions
+}
+
+func isValidTimeRange(timeRange string) bool {
+	validRanges := map[string]bool{
+		"7d":  true,
+		"30d": true,
+		"90d": true,
+	}
+	return validRanges[timeRange]
+}
+
+func isValidPosition(position string) bool {
+	validPositions := map[string]bool{
+		"TOP":     true,
+		"JUNGLE":  true,
+		"MID":     true,
+		"ADC":     true,
+		"SUPPORT": true,
+	}
+	return validPositions[position]
+}
+
+func isValidTier(tier string) bool {
+	validTiers := map[string]bool{
+		"IRON":        true,
+		"BRONZE":      true,
+		"SILVER":      true,
+		"GOLD":        true,
+		"PLATINUM":    true,
+		"EMERALD":     true,
+		"DIAMOND":     true,
+		"MASTER":      true,
+		"GRANDMASTER": true,
+		"CHALLENGER":  true,
+	}
+	return validTiers[tier]
+}
+
+// Register routes
+func (ah *AnalyticsHandler) RegisterRoutes(router *gin.RouterGroup) {
+	analytics := router.Group("/analytics")
+	{
+		// Maintenance for the current user
+		analytics.POST("/rebuild", ah.RebuildStats)
+		analytics.GET("/rebuild", ah.GetRebuildStatus)
+		analytics.GET("/early-game", ah.GetEarlyGameAnalysis)
+		analytics.GET("/recent-form", ah.GetRecentForm)
+		analytics.GET("/by-duration", ah.GetWinRateByDuration)
+		analytics.GET("/by-time", ah.GetWinRateByTimeOfDay)
+		analytics.GET("/kda-distribution", ah.GetKDADistribution)
+		analytics.GET("/duo-vs-solo", ah.GetDuoVsSolo)
+		analytics.GET("/bans", ah.GetBanAnalysis)
+		analytics.GET("/objective-impact", ah.GetObjectiveImpact)
+		analytics.GET("/cs", ah.GetCSBenchmarks)
+		analytics.GET("/group-comparison", ah.GetGroupComparison)
+		analytics.GET("/seasons", ah.GetSeasons)
+		analytics.POST("/seasons", ah.ArchiveSeason)
+		analytics.GET("/snapshot-diff", ah.GetSnapshotDiff)
+
 		// Player-specific analytics
 		analytics.GET("/:player_id/kda", ah.GetKDAAnalysis)
 		analytics.GET("/:player_id/cs", ah.GetCSAnalysis)
 		analytics.GET("/:player_id/comparison", ah.GetPerformanceComparison)
 		analytics.GET("/:player_id/stats", ah.GetPlayerStats)
+		analytics.GET("/:player_id/period", ah.GetPeriodStats)
 		analytics.GET("/:player_id/trends", ah.GetPerformanceTrends)
+		analytics.GET("/:player_id/performance", ah.GetPerformanceAnalysis)
+		analytics.GET("/:player_id/champions", ah.GetChampionList)
 		analytics.GET("/:player_id/champion/:champion_id", ah.GetChampionStats)
 
 		// Global analytics
 		analytics.GET("/benchmarks", ah.GetBenchmarks)
+		analytics.GET("/metrics", ah.GetMetrics)
 	}
 }
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
@@ -287,6 +289,105 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+// GetPreferences returns the current user's preferences
+// @Summary Get user preferences
+// @Description Get the authenticated user's preferences
+// @Tags auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.UserPreferences
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/preferences [get]
+func (h *AuthHandler) GetPreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	prefs, err := h.authService.GetPreferences(fmt.Sprint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Preferences lookup failed",
+			Message: "An error occurred while loading preferences",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
+// UpdatePreferences changes the current user's preferences
+// @Summary Update user preferences
+// @Description Update the authenticated user's preferences; omitted or null fields are left unchanged. Every field is validated and invalid ones are listed in the fields map of a 400 response.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.UpdatePreferencesRequest true "Preferences to change"
+// @Success 200 {object} models.UserPreferences
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /auth/preferences [put]
+func (h *AuthHandler) UpdatePreferences(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	var req services.UpdatePreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		var fieldErrors *services.PreferencesError
+		if errors.As(err, &fieldErrors) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid preferences",
+				Message: err.Error(),
+				Fields:  fieldErrors.Fields,
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	prefs, err := h.authService.UpdatePreferences(fmt.Sprint(userID), req)
+	if err != nil {
+		var fieldErrors *services.PreferencesError
+		if errors.As(err, &fieldErrors) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid preferences",
+				Message: err.Error(),
+				Fields:  fieldErrors.Fields,
+			})
+			return
+		}
+		if errors.Is(err, services.ErrInvalidPreferences) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid preferences",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Preferences update failed",
+			Message: "An error occurred while saving preferences",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, prefs)
+}
+
 // Logout handles user logout (typically just returns success as JWT is stateless)
 // @Summary Logout user
 // @Description Logout the current user (client should discard tokens)
@@ -400,6 +501,8 @@ func (h *AuthHandler) OptionalAuthMiddleware() gin.HandlerFunc {
 type ErrorResponse struct {
 	Error   string `json:"error"`
 	Message string `json:"message"`
+	// Fields maps each invalid request field to what is wrong with it
+	Fields map[string]string `json:"fields,omitempty"`
 }
 
 type SuccessResponse struct {
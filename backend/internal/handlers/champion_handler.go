@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herald-lol/herald/backend/internal/models"
 	"github.com/herald-lol/herald/backend/internal/services"
 )
 
@@ -94,6 +95,7 @@ func (ch *ChampionHandler) GetChampionAnalysis(c *gin.Context) {
 	}
 
 	// Validate position if provided
+	req.Position = models.NormalizeRole(req.Position)
 	if req.Position != "" && !isValidPosition(req.Position) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "validation_error",
@@ -357,8 +359,8 @@ func (ch *ChampionHandler) GetChampionPowerSpikes(c *gin.Context) {
 // @Router /api/v1/champion/{player_id}/matchups [get]
 func (ch *ChampionHandler) GetChampionMatchups(c *gin.Context) {
 	playerID := c.Param("player_id")
-	champion := c.Query("champion")
-	opponent := c.Query("opponent")
+	champion := models.ResolveChampionInternalName(c.Query("champion"))
+	opponent := models.ResolveChampionInternalName(c.Query("opponent"))
 	timeRange := c.DefaultQuery("time_range", "30d")
 
 	if playerID == "" || champion == "" {
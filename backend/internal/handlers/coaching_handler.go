@@ -194,12 +194,7 @@ func (h *CoachingHandler) GetCoachingInsights(c *gin.Context) {
 	}
 
 	insightType := c.Query("insightType")
-	limitStr := c.DefaultQuery("limit", "10")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 10
-	}
+	limit, _, _ := ParsePaginationWithin(c, 10, MaxPageSize)
 
 	// TODO: Retrieve from database
 	mockInsights := gin.H{
@@ -264,12 +259,7 @@ func (h *CoachingHandler) GetPersonalizedTips(c *gin.Context) {
 
 	category := c.Query("category")
 	tipType := c.Query("type")
-	limitStr := c.DefaultQuery("limit", "15")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 15
-	}
+	limit, _, _ := ParsePaginationWithin(c, 15, MaxPageSize)
 
 	// Mock tips data
 	tips := []gin.H{
@@ -404,12 +394,7 @@ func (h *CoachingHandler) GetTacticalAdvice(c *gin.Context) {
 
 	category := c.Query("category")
 	urgency := c.Query("urgency")
-	limitStr := c.DefaultQuery("limit", "10")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 10
-	}
+	limit, _, _ := ParsePaginationWithin(c, 10, MaxPageSize)
 
 	// Mock tactical advice data
 	advice := []gin.H{
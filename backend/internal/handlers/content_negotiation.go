@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Herald.lol Gaming Analytics - Content Negotiation
+// Lets analytics endpoints answer with CSV when the client asks for it via Accept
+
+// MIMECSV is the media type served for tabular analytics responses
+const MIMECSV = "text/csv"
+
+// wantsCSV reports whether the Accept header prefers CSV over JSON.
+// Missing or wildcard Accept headers keep the JSON default.
+func wantsCSV(c *gin.Context) bool {
+	return c.NegotiateFormat(gin.MIMEJSON, MIMECSV) == MIMECSV
+}
+
+// respondNegotiated writes data as CSV when the client accepts text/csv and
+// as JSON otherwise. Data that cannot be flattened into CSV, see encodeCSV,
+// is answered with 406 rather than written with columns missing.
+func respondNegotiated(c *gin.Context, status int, data interface{}, filename string) {
+	if !wantsCSV(c) {
+		c.JSON(status, data)
+		return
+	}
+
+	body, err := encodeCSV(data)
+	if err != nil {
+		c.JSON(http.StatusNotAcceptable, ErrorResponse{
+			Error:   "not_acceptable",
+			Message: "This response cannot be represented as CSV",
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.csv"`, filename))
+	c.Data(status, MIMECSV+"; charset=utf-8", body)
+}
+
+// encodeCSV writes a struct or slice of structs as CSV, one row per element,
+// using the json tag names as the header. Nested structs and maps become
+// "parent.child" columns. A struct holding one slice of structs, such as a
+// trend with its data points, is written one row per element with the
+// struct's own columns repeated. Any other slice is an error, so no data is
+// silently left out.
+func encodeCSV(data interface{}) ([]byte, error) {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil, fmt.Errorf("nil value")
+		}
+		v = v.Elem()
+	}
+
+	var rows []*csvRecord
+	switch v.Kind() {
+	case reflect.Struct:
+		record := newCSVRecord()
+		if err := record.addStruct(v, ""); err != nil {
+			return nil, err
+		}
+		expanded, err := record.expand()
+		if err != nil {
+			return nil, err
+		}
+		rows = expanded
+	case reflect.Slice, reflect.Array:
+		elemType := v.Type().Elem()
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() != reflect.Struct && elemType.Kind() != reflect.Interface {
+			return nil, fmt.Errorf("unsupported element type %s", elemType.Kind())
+		}
+
+		// An empty list still gets its header
+		if v.Len() == 0 && elemType.Kind() == reflect.Struct {
+			record := newCSVRecord()
+			if err := record.addStruct(reflect.Zero(elemType), ""); err != nil {
+				return nil, err
+			}
+			return writeCSV(record.columns, nil)
+		}
+
+		for i := 0; i < v.Len(); i++ {
+			elem := v.Index(i)
+			for elem.Kind() == reflect.Ptr || elem.Kind() == reflect.Interface {
+				elem = elem.Elem()
+			}
+			if elem.Kind() != reflect.Struct {
+				return nil, fmt.Errorf("unsupported element type %s", elem.Kind())
+			}
+			record := newCSVRecord()
+			if err := record.addStruct(elem, ""); err != nil {
+				return nil, err
+			}
+			if len(record.nested) > 0 {
+				return nil, fmt.Errorf("nested list %q in a list", record.nested[0].column)
+			}
+			rows = append(rows, record)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type %s", v.Kind())
+	}
+
+	// Rows may differ in their map keys, so the header is their union
+	var columns []string
+	seen := make(map[string]bool)
+	for _, row := range rows {
+		for _, column := range row.columns {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+
+	return writeCSV(columns, rows)
+}
+
+func writeCSV(columns []string, rows []*csvRecord) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if err := writer.Write(columns); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = row.values[column]
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// csvRecord is one flattened CSV row. Slices of structs found while
+// flattening are kept in nested for expand.
+type csvRecord struct {
+	columns []string
+	values  map[string]string
+	nested  []csvNestedList
+}
+
+type csvNestedList struct {
+	column string
+	value  reflect.Value
+}
+
+func newCSVRecord() *csvRecord {
+	return &csvRecord{values: make(map[string]string)}
+}
+
+func (r *csvRecord) set(column, value string) {
+	if _, exists := r.values[column]; !exists {
+		r.columns = append(r.columns, column)
+	}
+	r.values[column] = value
+}
+
+// addStruct adds the exported fields of v, named by their json tags
+func (r *csvRecord) addStruct(v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		if err := r.addValue(prefix+name, v.Field(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addValue adds v under column, flattening structs and maps below it
+func (r *csvRecord) addValue(column string, v reflect.Value) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if v.Kind() == reflect.Ptr && isCSVScalar(v.Type()) {
+				r.set(column, "")
+			}
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if isCSVScalar(v.Type()) {
+		r.set(column, csvValue(v))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return r.addStruct(v, column+".")
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("map %q without string keys", column)
+		}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+		for _, key := range keys {
+			if err := r.addValue(column+"."+key.String(), v.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Slice, reflect.Array:
+		if v.Len() == 0 {
+			return nil
+		}
+		elemType := v.Type().Elem()
+		for elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() != reflect.Struct || elemType == timeType {
+			return fmt.Errorf("list %q cannot be flattened", column)
+		}
+		r.nested = append(r.nested, csvNestedList{column: column, value: v})
+		return nil
+	}
+
+	return fmt.Errorf("field %q of type %s cannot be flattened", column, v.Kind())
+}
+
+// expand returns the record itself, or one row per element of its single
+// nested list with the record's columns repeated
+func (r *csvRecord) expand() ([]*csvRecord, error) {
+	switch len(r.nested) {
+	case 0:
+		return []*csvRecord{r}, nil
+	case 1:
+	default:
+		return nil, fmt.Errorf("more than one nested list")
+	}
+
+	list := r.nested[0]
+	rows := make([]*csvRecord, 0, list.value.Len())
+	for i := 0; i < list.value.Len(); i++ {
+		row := newCSVRecord()
+		for _, column := range r.columns {
+			row.set(column, r.values[column])
+		}
+		if err := row.addValue(list.column, list.value.Index(i)); err != nil {
+			return nil, err
+		}
+		if len(row.nested) > 0 {
+			return nil, fmt.Errorf("nested list %q in a list", row.nested[0].column)
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func isCSVScalar(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+func csvValue(v reflect.Value) string {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(time.RFC3339)
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64)
+	}
+	return ""
+}
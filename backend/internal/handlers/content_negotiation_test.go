@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+)
+
+type csvTestPoint struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+}
+
+type csvTestTrend struct {
+	Metric string         `json:"metric"`
+	Stats  csvTestStats   `json:"stats"`
+	Points []csvTestPoint `json:"points"`
+}
+
+type csvTestStats struct {
+	Average float64            `json:"average"`
+	ByRole  map[string]float64 `json:"by_role"`
+}
+
+func TestEncodeCSVFlattensNestedValues(t *testing.T) {
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	trend := csvTestTrend{
+		Metric: "kda",
+		Stats:  csvTestStats{Average: 3.5, ByRole: map[string]float64{"MID": 4, "ADC": 3}},
+		Points: []csvTestPoint{{Date: day, Value: 3}, {Date: day.AddDate(0, 0, 1), Value: 4}},
+	}
+
+	body, err := encodeCSV(&trend)
+	if err != nil {
+		t.Fatalf("Trend should encode: %v", err)
+	}
+	want := "metric,stats.average,stats.by_role.ADC,stats.by_role.MID,points.date,points.value\n" +
+		"kda,3.5,3,4,2024-06-01T00:00:00Z,3\n" +
+		"kda,3.5,3,4,2024-06-02T00:00:00Z,4\n"
+	if string(body) != want {
+		t.Errorf("Unexpected CSV:\n%s\nwant:\n%s", body, want)
+	}
+
+	body, err = encodeCSV([]csvTestPoint{})
+	if err != nil || string(body) != "date,value\n" {
+		t.Errorf("Empty list should encode its header, got %q err %v", body, err)
+	}
+
+	// Lists that can't be flattened are rejected rather than dropped
+	if _, err := encodeCSV([]csvTestTrend{trend}); err == nil {
+		t.Error("Points nested in a list should not encode")
+	}
+	if _, err := encodeCSV(struct {
+		Tags []string `json:"tags"`
+	}{Tags: []string{"a"}}); err == nil {
+		t.Error("A list of strings should not encode")
+	}
+}
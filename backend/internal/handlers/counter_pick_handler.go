@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herald-lol/herald/backend/internal/models"
 	"github.com/herald-lol/herald/backend/internal/services"
 )
 
@@ -114,14 +115,9 @@ func (h *CounterPickHandler) GetCounterSuggestions(c *gin.Context) {
 
 	// Get query parameters
 	gameMode := c.DefaultQuery("gameMode", "ranked")
-	limitStr := c.DefaultQuery("limit", "15")
 	minStrength := c.DefaultQuery("minStrength", "60")
 	playerChampions := c.QueryArray("playerChampions")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 15
-	}
+	limit, _, _ := ParsePaginationWithin(c, 15, MaxPageSize)
 
 	minStrengthFloat, err := strconv.ParseFloat(minStrength, 64)
 	if err != nil {
@@ -160,8 +156,8 @@ func (h *CounterPickHandler) GetCounterSuggestions(c *gin.Context) {
 
 // GetMatchupAnalysis gets detailed matchup analysis between two champions
 func (h *CounterPickHandler) GetMatchupAnalysis(c *gin.Context) {
-	champion1 := c.Param("champion1")
-	champion2 := c.Param("champion2")
+	champion1 := models.ResolveChampionInternalName(c.Param("champion1"))
+	champion2 := models.ResolveChampionInternalName(c.Param("champion2"))
 
 	if champion1 == "" || champion2 == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Both champions are required"})
@@ -470,12 +466,7 @@ func (h *CounterPickHandler) GetMetaCounters(c *gin.Context) {
 	gameMode := c.DefaultQuery("gameMode", "ranked")
 	rank := c.DefaultQuery("rank", "all")
 	region := c.DefaultQuery("region", "global")
-	limitStr := c.DefaultQuery("limit", "20")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 20
-	}
+	limit, _, _ := ParsePagination(c)
 
 	// This would integrate with meta service to get current strong picks
 	// and then analyze their counters
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herald-lol/herald/backend/internal/models"
 	"github.com/herald-lol/herald/backend/internal/services"
 )
 
@@ -81,6 +82,7 @@ func (dh *DamageHandler) GetDamageAnalysis(c *gin.Context) {
 	}
 
 	// Validate position if provided
+	req.Position = models.NormalizeRole(req.Position)
 	if req.Position != "" && !isValidPosition(req.Position) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "validation_error",
@@ -513,6 +515,54 @@ func (dh *DamageHandler) GetCarryPotentialAnalysis(c *gin.Context) {
 	c.JSON(http.StatusOK, carryAnalysis)
 }
 
+// GetChampionDamageBreakdown godoc
+// @Summary Get damage type breakdown per champion
+// @Description Returns physical, magic and true damage dealt to champions for each champion played
+// @Tags damage
+// @Accept json
+// @Produce json
+// @Param player_id path string true "Player ID"
+// @Param time_range query string false "Time range (default: 30d)"
+// @Success 200 {object} []services.ChampionDamageBreakdown
+// @Failure 400 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/damage/{player_id}/champions [get]
+func (dh *DamageHandler) GetChampionDamageBreakdown(c *gin.Context) {
+	playerID := c.Param("player_id")
+	if playerID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Player ID is required",
+		})
+		return
+	}
+
+	timeRange := c.DefaultQuery("time_range", "30d")
+	if !isValidTimeRange(timeRange) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid time range. Use: 7d, 30d, or 90d",
+		})
+		return
+	}
+
+	breakdown, err := dh.damageService.GetChampionDamageBreakdown(c.Request.Context(), playerID, timeRange)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to calculate champion damage breakdown",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"player_id":  playerID,
+		"time_range": timeRange,
+		"champions":  breakdown,
+	})
+}
+
 // Register routes for damage analytics
 func (dh *DamageHandler) RegisterRoutes(router *gin.RouterGroup) {
 	damage := router.Group("/damage")
@@ -523,5 +573,6 @@ func (dh *DamageHandler) RegisterRoutes(router *gin.RouterGroup) {
 		damage.GET("/:player_id/trends", dh.GetDamageTrends)
 		damage.GET("/:player_id/comparison", dh.GetDamageComparison)
 		damage.GET("/:player_id/carry-potential", dh.GetCarryPotentialAnalysis)
+		damage.GET("/:player_id/champions", dh.GetChampionDamageBreakdown)
 	}
 }
@@ -1,17 +1,51 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"path/filepath"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/herald-lol/herald/backend/internal/export"
+	"github.com/herald-lol/herald/backend/internal/models"
+	"github.com/herald-lol/herald/backend/internal/services"
 )
 
 // ExportHandler handles export and reporting requests
 type ExportHandler struct {
 	exportService *export.ExportService
+	preferences   RemakePreferenceSource
+	teammates     TeammateMatchSource
+	accounts      LinkedAccountSource
+	rankHistory   RankHistorySource
+}
+
+// RemakePreferenceSource provides the user's excludeRemakes preference,
+// implemented by services.AnalyticsService
+type RemakePreferenceSource interface {
+	ExcludeRemakesDefault(ctx context.Context, userID string) bool
+}
+
+// TeammateMatchSource finds the stored matches a player shared with a
+// teammate, implemented by services.RiotService
+type TeammateMatchSource interface {
+	MatchIDsWithTeammate(ctx context.Context, region, playerPUUID, teammateRiotID string) ([]string, error)
+}
+
+// LinkedAccountSource lists the Riot accounts linked to a user with each
+// account's stored matches, implemented by services.RiotService
+type LinkedAccountSource interface {
+	LinkedAccountMatches(ctx context.Context, userID string, limit int) ([]services.LinkedAccountMatches, error)
+}
+
+// RankHistorySource provides the user's stored LP history, implemented by
+// services.RiotService
+type RankHistorySource interface {
+	RankHistory(ctx context.Context, userID string) ([]models.RankProgressionHistory, error)
 }
 
 // NewExportHandler creates a new export handler
@@ -21,6 +55,32 @@ func NewExportHandler(exportService *export.ExportService) *ExportHandler {
 	}
 }
 
+// WithRemakePreference makes player exports fall back to the user's
+// excludeRemakes preference when a request doesn't set it
+func (h *ExportHandler) WithRemakePreference(source RemakePreferenceSource) *ExportHandler {
+	h.preferences = source
+	return h
+}
+
+// WithTeammateMatches enables the with_teammate filter on player exports
+func (h *ExportHandler) WithTeammateMatches(source TeammateMatchSource) *ExportHandler {
+	h.teammates = source
+	return h
+}
+
+// WithLinkedAccounts enables include_linked_accounts on player exports
+func (h *ExportHandler) WithLinkedAccounts(source LinkedAccountSource) *ExportHandler {
+	h.accounts = source
+	return h
+}
+
+// WithRankHistory fills the LP and MMR columns of player exports that ask for
+// ranked columns; without it they are left empty
+func (h *ExportHandler) WithRankHistory(source RankHistorySource) *ExportHandler {
+	h.rankHistory = source
+	return h
+}
+
 // RegisterRoutes registers all export routes
 func (h *ExportHandler) RegisterRoutes(r *gin.RouterGroup) {
 	exports := r.Group("/exports")
@@ -46,11 +106,16 @@ func (h *ExportHandler) RegisterRoutes(r *gin.RouterGroup) {
 		exports.GET("/status/:export_id", h.GetExportStatus)
 		exports.GET("/download/:export_id", h.DownloadExport)
 		exports.GET("/list/:user_id", h.ListUserExports)
+		exports.DELETE("/history", h.DeleteExportHistory)
 		exports.DELETE("/:export_id", h.DeleteExport)
 
 		// Export utilities
 		exports.GET("/formats", h.GetSupportedFormats)
 		exports.GET("/templates", h.GetReportTemplates)
+		exports.POST("/templates", h.CreateExportTemplate)
+		exports.GET("/templates/:template_id", h.GetExportTemplate)
+		exports.DELETE("/templates/:template_id", h.DeleteExportTemplate)
+		exports.POST("/templates/:template_id/export", h.ExportFromTemplate)
 		exports.POST("/preview", h.PreviewExport)
 
 		// Gaming-specific exports
@@ -81,9 +146,31 @@ func (h *ExportHandler) ExportPlayerAnalytics(c *gin.Context) {
 		})
 		return
 	}
+	if userID, exists := c.Get("user_id"); exists {
+		request.UserID = fmt.Sprint(userID)
+	}
+	request.SubscriptionTier = subscriptionTier(c)
+	h.applyRemakePreference(c, &request)
+	if !h.applyTeammateFilter(c, &request) {
+		return
+	}
+	if !h.applyLinkedAccounts(c, &request) {
+		return
+	}
+	if !h.applyRankHistory(c, &request) {
+		return
+	}
 
 	result, err := h.exportService.ExportPlayerAnalytics(c.Request.Context(), &request)
 	if err != nil {
+		if errors.Is(err, export.ErrPDFReportsUnavailable) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"error":   "PDF reports are not included in this plan",
+				"code":    "premium_required",
+				"details": err.Error(),
+			})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to export player analytics",
 			"details": err.Error(),
@@ -104,6 +191,153 @@ func (h *ExportHandler) ExportPlayerAnalytics(c *gin.Context) {
 	})
 }
 
+// subscriptionTier returns the authenticated user's plan: the tier set by
+// the gaming security middleware, else "premium" or "free" from the auth
+// middleware's premium flag
+func subscriptionTier(c *gin.Context) string {
+	if tier, ok := c.Get("subscription_tier"); ok {
+		if tier, ok := tier.(string); ok && tier != "" {
+			return tier
+		}
+	}
+	if premium, ok := c.Get("is_premium"); ok && premium == true {
+		return "premium"
+	}
+	return "free"
+}
+
+// applyRemakePreference sets ExcludeRemakes from the user's preference when
+// the request leaves it unset
+func (h *ExportHandler) applyRemakePreference(c *gin.Context, request *export.PlayerExportRequest) {
+	if request.ExcludeRemakes != nil || h.preferences == nil {
+		return
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return
+	}
+	exclude := h.preferences.ExcludeRemakesDefault(c.Request.Context(), fmt.Sprint(userID))
+	request.ExcludeRemakes = &exclude
+}
+
+// applyTeammateFilter resolves with_teammate to the matches the player shared
+// with that teammate. It writes the error response and returns false when the
+// teammate can't be resolved.
+func (h *ExportHandler) applyTeammateFilter(c *gin.Context, request *export.PlayerExportRequest) bool {
+	if request.WithTeammate == "" {
+		return true
+	}
+	if h.teammates == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"error": "The with_teammate filter is not available",
+			"code":  "teammate_filter_unavailable",
+		})
+		return false
+	}
+
+	matchIDs, err := h.teammates.MatchIDsWithTeammate(c.Request.Context(), request.Region, request.PlayerPUUID, request.WithTeammate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Failed to resolve with_teammate",
+			"code":    "invalid_teammate",
+			"details": err.Error(),
+		})
+		return false
+	}
+	request.TeammateMatchIDs = matchIDs
+	return true
+}
+
+// applyLinkedAccounts resolves include_linked_accounts to the user's other
+// linked accounts and the stored matches of each. The exported player must be
+// one of the user's own accounts. It writes the error response and returns
+// false when the accounts can't be resolved.
+func (h *ExportHandler) applyLinkedAccounts(c *gin.Context, request *export.PlayerExportRequest) bool {
+	if !request.IncludeLinkedAccounts {
+		return true
+	}
+	if h.accounts == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{
+			"error": "Linked account exports are not available",
+			"code":  "linked_accounts_unavailable",
+		})
+		return false
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return false
+	}
+
+	limit := request.GameCount
+	if limit <= 0 {
+		limit = export.DefaultExportGameCount
+	}
+	accounts, err := h.accounts.LinkedAccountMatches(c.Request.Context(), fmt.Sprint(userID), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load linked accounts",
+			"details": err.Error(),
+		})
+		return false
+	}
+
+	owned := false
+	request.LinkedAccounts = nil
+	request.AccountMatchIDs = make(map[string][]string, len(accounts))
+	for _, account := range accounts {
+		request.AccountMatchIDs[account.PUUID] = account.MatchIDs
+		if account.PUUID == request.PlayerPUUID {
+			owned = true
+			continue
+		}
+		request.LinkedAccounts = append(request.LinkedAccounts, export.LinkedAccount{
+			PlayerPUUID:  account.PUUID,
+			SummonerName: account.SummonerName,
+			Region:       account.Region,
+		})
+	}
+	if !owned {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Linked accounts can only be exported for one of your own accounts",
+			"code":  "account_not_linked",
+		})
+		return false
+	}
+	return true
+}
+
+// applyRankHistory loads the user's LP history for exports with ranked
+// columns. It writes the error response and returns false when the history
+// can't be loaded.
+func (h *ExportHandler) applyRankHistory(c *gin.Context, request *export.PlayerExportRequest) bool {
+	if request.ExportOptions == nil || !request.ExportOptions.RankedColumns || h.rankHistory == nil {
+		return true
+	}
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return true
+	}
+
+	history, err := h.rankHistory.RankHistory(c.Request.Context(), fmt.Sprint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to load rank history",
+			"details": err.Error(),
+		})
+		return false
+	}
+	request.RankHistory = make([]export.RankedPoint, 0, len(history))
+	for _, entry := range history {
+		request.RankHistory = append(request.RankHistory, export.RankedPoint{
+			RecordedAt: entry.RecordedAt,
+			LP:         entry.LP,
+			MMR:        entry.MMR,
+		})
+	}
+	return true
+}
+
 // BatchExportPlayers handles batch export of multiple players
 func (h *ExportHandler) BatchExportPlayers(c *gin.Context) {
 	var request struct {
@@ -133,6 +367,10 @@ func (h *ExportHandler) BatchExportPlayers(c *gin.Context) {
 	results := []gin.H{}
 	successCount := 0
 	failureCount := 0
+	owner := ""
+	if userID, exists := c.Get("user_id"); exists {
+		owner = fmt.Sprint(userID)
+	}
 
 	for _, playerPUUID := range request.PlayerPUUIDs {
 		playerRequest := &export.PlayerExportRequest{
@@ -140,6 +378,9 @@ func (h *ExportHandler) BatchExportPlayers(c *gin.Context) {
 			Format:      request.Format,
 			TimeRange:   request.TimeRange,
 			GameModes:   request.GameModes,
+			UserID:      owner,
+
+			SubscriptionTier: subscriptionTier(c),
 		}
 
 		result, err := h.exportService.ExportPlayerAnalytics(c.Request.Context(), playerRequest)
@@ -412,8 +653,20 @@ func (h *ExportHandler) DownloadExport(c *gin.Context) {
 	}
 
 	// Get export status first
-	status, err := h.exportService.GetExportStatus(c.Request.Context(), exportID)
+	status, filePath, err := h.exportService.ResolveDownload(c.Request.Context(), exportID)
 	if err != nil {
+		if errors.Is(err, export.ErrExportExpired) {
+			response := gin.H{
+				"error":   "Export has expired",
+				"code":    "export_expired",
+				"details": "This export is past the retention period, please create a new one",
+			}
+			if status != nil {
+				response["expires_at"] = status.ExpiresAt
+			}
+			c.JSON(http.StatusGone, response)
+			return
+		}
 		c.JSON(http.StatusNotFound, gin.H{
 			"error":   "Export not found",
 			"details": err.Error(),
@@ -430,7 +683,13 @@ func (h *ExportHandler) DownloadExport(c *gin.Context) {
 		return
 	}
 
-	// In production, this would stream the file or redirect to CDN URL
+	// Serve the stored file directly when it is available locally
+	if filePath != "" {
+		c.FileAttachment(filePath, filepath.Base(filePath))
+		return
+	}
+
+	// Otherwise point the client at the CDN copy
 	c.JSON(http.StatusOK, gin.H{
 		"download_url": status.DownloadURL,
 		"file_size":    status.FileSize,
@@ -450,14 +709,7 @@ func (h *ExportHandler) ListUserExports(c *gin.Context) {
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 20
-	}
-	if limit > 100 {
-		limit = 100
-	}
+	limit, _, _ := ParsePagination(c)
 
 	exports, err := h.exportService.ListExports(c.Request.Context(), userID, limit)
 	if err != nil {
@@ -487,7 +739,7 @@ func (h *ExportHandler) DeleteExport(c *gin.Context) {
 		return
 	}
 
-	err := h.exportService.DeleteExport(c.Request.Context(), exportID)
+	result, err := h.exportService.DeleteExport(c.Request.Context(), exportID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to delete export",
@@ -497,9 +749,63 @@ func (h *ExportHandler) DeleteExport(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"export_id": exportID,
-		"status":    "deleted",
-		"message":   "Export deleted successfully",
+		"export_id":    exportID,
+		"status":       "deleted",
+		"deleted_jobs": result.DeletedJobs,
+		"freed_bytes":  result.FreedBytes,
+		"message":      "Export deleted successfully",
+	})
+}
+
+// DeleteExportHistory handles bulk deletion of the current user's export
+// history, optionally filtered by status (completed, expired) and minimum age
+// in days
+func (h *ExportHandler) DeleteExportHistory(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	filter := &export.ExportHistoryFilter{
+		UserID: fmt.Sprint(userID),
+		Status: c.Query("status"),
+	}
+
+	if filter.Status != "" && filter.Status != "completed" && filter.Status != "expired" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "status must be completed or expired",
+		})
+		return
+	}
+
+	if daysStr := c.Query("older_than_days"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "older_than_days must be a non-negative integer",
+			})
+			return
+		}
+		filter.OlderThan = time.Duration(days) * 24 * time.Hour
+	}
+
+	result, err := h.exportService.DeleteExportHistory(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":        "Failed to delete export history",
+			"details":      err.Error(),
+			"deleted_jobs": result.DeletedJobs,
+			"freed_bytes":  result.FreedBytes,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":       "deleted",
+		"deleted_jobs": result.DeletedJobs,
+		"freed_bytes":  result.FreedBytes,
+		"message":      "Export history deleted successfully",
 	})
 }
 
@@ -561,6 +867,147 @@ func (h *ExportHandler) GetReportTemplates(c *gin.Context) {
 	})
 }
 
+// CreateExportTemplate saves a player export template with a default filter
+func (h *ExportHandler) CreateExportTemplate(c *gin.Context) {
+	var template export.ExportTemplate
+
+	if err := c.ShouldBindJSON(&template); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid export template",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.exportService.SaveTemplate(fmt.Sprint(userID), &template); err != nil {
+		if errors.Is(err, export.ErrTemplateNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error":   "Export template not found",
+				"details": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid export template",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"template": template,
+		"message":  "Export template saved successfully",
+	})
+}
+
+// GetExportTemplate returns one of the user's saved export templates
+func (h *ExportHandler) GetExportTemplate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	template, err := h.exportService.GetTemplate(fmt.Sprint(userID), c.Param("template_id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Export template not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"template": template,
+	})
+}
+
+// DeleteExportTemplate removes one of the user's saved export templates
+func (h *ExportHandler) DeleteExportTemplate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	if err := h.exportService.DeleteTemplate(fmt.Sprint(userID), c.Param("template_id")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Export template not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Export template deleted successfully",
+	})
+}
+
+// ExportFromTemplate runs a player export using a saved template. Any filter
+// fields in the request body override the template's default filter.
+func (h *ExportHandler) ExportFromTemplate(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	var request export.PlayerExportRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid template export request",
+			"details": err.Error(),
+		})
+		return
+	}
+	if userID, exists := c.Get("user_id"); exists {
+		request.UserID = fmt.Sprint(userID)
+	}
+	request.SubscriptionTier = subscriptionTier(c)
+	h.applyRemakePreference(c, &request)
+	if !h.applyTeammateFilter(c, &request) {
+		return
+	}
+
+	ownerID, templateID := fmt.Sprint(userID), c.Param("template_id")
+	if _, err := h.exportService.GetTemplate(ownerID, templateID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "Export template not found",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.exportService.ExportFromTemplate(c.Request.Context(), ownerID, templateID, &request)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "Failed to export from template",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"export_id":    result.ExportID,
+		"format":       result.Format,
+		"file_size":    result.FileSize,
+		"download_url": result.DownloadURL,
+		"status":       result.Status,
+		"created_at":   result.CreatedAt,
+		"expires_at":   result.ExpiresAt,
+		"metadata":     result.Metadata,
+		"template_id":  templateID,
+		"message":      "Template export completed successfully",
+	})
+}
+
 // PreviewExport handles export preview requests
 func (h *ExportHandler) PreviewExport(c *gin.Context) {
 	var request struct {
@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/herald-lol/herald/backend/internal/export"
+	"github.com/herald-lol/herald/backend/internal/models"
+	"github.com/herald-lol/herald/backend/internal/services"
+)
+
+type fakeLinkedAccounts struct {
+	userID   string
+	accounts []services.LinkedAccountMatches
+}
+
+func (f *fakeLinkedAccounts) LinkedAccountMatches(ctx context.Context, userID string, limit int) ([]services.LinkedAccountMatches, error) {
+	if userID != f.userID {
+		return nil, nil
+	}
+	return f.accounts, nil
+}
+
+type fakeRankHistory []models.RankProgressionHistory
+
+func (f fakeRankHistory) RankHistory(ctx context.Context, userID string) ([]models.RankProgressionHistory, error) {
+	return f, nil
+}
+
+type fakeRemakePreference struct {
+	userIDs []string
+}
+
+func (f *fakeRemakePreference) ExcludeRemakesDefault(ctx context.Context, userID string) bool {
+	f.userIDs = append(f.userIDs, userID)
+	return true
+}
+
+type fakeTeammateMatches struct {
+	teammates []string
+}
+
+func (f *fakeTeammateMatches) MatchIDsWithTeammate(ctx context.Context, region, playerPUUID, teammateRiotID string) ([]string, error) {
+	f.teammates = append(f.teammates, teammateRiotID)
+	return []string{"EUW1_2"}, nil
+}
+
+func TestExportPlayerAnalyticsUsesHandlerSources(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	preferences := &fakeRemakePreference{}
+	teammates := &fakeTeammateMatches{}
+	accounts := &fakeLinkedAccounts{userID: "user-1", accounts: []services.LinkedAccountMatches{{PUUID: "main"}}}
+	handler := NewExportHandler(nil).
+		WithRemakePreference(preferences).
+		WithTeammateMatches(teammates).
+		WithLinkedAccounts(accounts)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+	})
+	handler.RegisterRoutes(router.Group("/api/v1"))
+
+	// Exporting someone else's account stops before the export runs, after
+	// the remake preference and teammate filter have been resolved
+	body := `{"player_puuid": "someone-else", "region": "euw1", "format": "json", "time_range": "30d",
+		"with_teammate": "Duo#EUW", "include_linked_accounts": true}`
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/exports/player", strings.NewReader(body)))
+
+	if recorder.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for an account the user hasn't linked, got %d", recorder.Code)
+	}
+	if len(preferences.userIDs) != 1 || preferences.userIDs[0] != "user-1" {
+		t.Errorf("Expected the user's remake preference to be read, got %v", preferences.userIDs)
+	}
+	if len(teammates.teammates) != 1 || teammates.teammates[0] != "Duo#EUW" {
+		t.Errorf("Expected the teammate filter to be resolved, got %v", teammates.teammates)
+	}
+}
+
+func TestApplyLinkedAccounts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	source := &fakeLinkedAccounts{userID: "user-1", accounts: []services.LinkedAccountMatches{
+		{PUUID: "main", SummonerName: "Main", MatchIDs: []string{"EUW1_2", "EUW1_1"}},
+		{PUUID: "smurf", SummonerName: "Smurf", MatchIDs: []string{"EUW1_3", "EUW1_2"}},
+	}}
+	handler := NewExportHandler(nil).WithLinkedAccounts(source)
+
+	apply := func(userID string, request *export.PlayerExportRequest) (bool, int) {
+		recorder := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(recorder)
+		c.Request = httptest.NewRequest("POST", "/api/v1/exports/player", nil)
+		if userID != "" {
+			c.Set("user_id", userID)
+		}
+		ok := handler.applyLinkedAccounts(c, request)
+		return ok, recorder.Code
+	}
+
+	request := &export.PlayerExportRequest{PlayerPUUID: "main", IncludeLinkedAccounts: true}
+	if ok, code := apply("user-1", request); !ok {
+		t.Fatalf("Expected the user's own account to resolve, got status %d", code)
+	}
+	if len(request.LinkedAccounts) != 1 || request.LinkedAccounts[0].PlayerPUUID != "smurf" {
+		t.Errorf("Expected smurf as the only linked account, got %v", request.LinkedAccounts)
+	}
+	if got := request.AccountMatchIDs["smurf"]; len(got) != 2 || got[0] != "EUW1_3" {
+		t.Errorf("Expected smurf's own matches, got %v", got)
+	}
+
+	request = &export.PlayerExportRequest{PlayerPUUID: "someone-else", IncludeLinkedAccounts: true}
+	if ok, code := apply("user-1", request); ok || code != http.StatusForbidden {
+		t.Errorf("Expected 403 for an account the user hasn't linked, got %d", code)
+	}
+
+	request = &export.PlayerExportRequest{PlayerPUUID: "main", IncludeLinkedAccounts: true}
+	if ok, code := apply("", request); ok || code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without a user, got %d", code)
+	}
+
+	if ok, code := apply("user-1", &export.PlayerExportRequest{PlayerPUUID: "main"}); !ok {
+		t.Errorf("Expected exports without linked accounts to pass through, got %d", code)
+	}
+
+	unwired := NewExportHandler(nil)
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest("POST", "/api/v1/exports/player", nil)
+	if unwired.applyLinkedAccounts(c, &export.PlayerExportRequest{IncludeLinkedAccounts: true}) || recorder.Code != http.StatusNotImplemented {
+		t.Errorf("Expected 501 without a linked account source, got %d", recorder.Code)
+	}
+}
+
+func TestApplyRankHistory(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	recordedAt := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	handler := NewExportHandler(nil).WithRankHistory(fakeRankHistory{{LP: 42, MMR: 1500, RecordedAt: recordedAt}})
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest("POST", "/api/v1/exports/player", nil)
+	c.Set("user_id", "user-1")
+
+	request := &export.PlayerExportRequest{}
+	if !handler.applyRankHistory(c, request) || request.RankHistory != nil {
+		t.Error("Expected no rank history unless ranked columns are requested")
+	}
+
+	request.ExportOptions = &export.ExportOptions{RankedColumns: true}
+	if !handler.applyRankHistory(c, request) {
+		t.Fatalf("Expected the rank history to load, got status %d", recorder.Code)
+	}
+	if len(request.RankHistory) != 1 || request.RankHistory[0].LP != 42 || !request.RankHistory[0].RecordedAt.Equal(recordedAt) {
+		t.Errorf("Expected the stored LP entry, got %v", request.RankHistory)
+	}
+}
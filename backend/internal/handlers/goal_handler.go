@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/herald-lol/herald/backend/internal/services"
+)
+
+type GoalHandler struct {
+	goalService *services.GoalService
+}
+
+func NewGoalHandler(goalService *services.GoalService) *GoalHandler {
+	return &GoalHandler{
+		goalService: goalService,
+	}
+}
+
+// ListGoals returns the current user's champion goals with their progress
+// @Summary List champion goals
+// @Description Champion goals with progress computed from stored matches. Goals are marked completed once met.
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} services.GoalProgress
+// @Failure 401 {object} ErrorResponse
+// @Router /goals [get]
+func (h *GoalHandler) ListGoals(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	goals, err := h.goalService.ListGoals(userID.(uuid.UUID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Lookup failed",
+			Message: "Failed to load goals",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, goals)
+}
+
+// CreateGoal creates a champion goal for the current user
+// @Summary Create champion goal
+// @Description Track a goal such as a 60% win rate on Jinx over 20 games. Metrics: win_rate (percent, default), kda, cs_per_min.
+// @Tags goals
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body services.CreateGoalRequest true "Goal"
+// @Success 201 {object} services.GoalProgress
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /goals [post]
+func (h *GoalHandler) CreateGoal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	var req services.CreateGoalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid goal",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	goal, err := h.goalService.CreateGoal(userID.(uuid.UUID), req)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidGoal) {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid goal",
+				Message: err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Create failed",
+			Message: "Failed to create goal",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, goal)
+}
+
+// DeleteGoal deletes one of the current user's goals
+// @Summary Delete goal
+// @Tags goals
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Goal ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /goals/{id} [delete]
+func (h *GoalHandler) DeleteGoal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	goalID, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid goal ID",
+			Message: "Goal ID must be a number",
+		})
+		return
+	}
+
+	if err := h.goalService.DeleteGoal(userID.(uuid.UUID), uint(goalID)); err != nil {
+		if err == services.ErrGoalNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Goal not found",
+				Message: "No goal with that ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Delete failed",
+			Message: "Failed to delete goal",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
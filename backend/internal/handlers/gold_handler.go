@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herald-lol/herald/backend/internal/models"
 	"github.com/herald-lol/herald/backend/internal/services"
 )
 
@@ -81,6 +82,7 @@ func (gh *GoldHandler) GetGoldAnalysis(c *gin.Context) {
 	}
 
 	// Validate position if provided
+	req.Position = models.NormalizeRole(req.Position)
 	if req.Position != "" && !isValidPosition(req.Position) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "validation_error",
@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/herald-lol/herald/backend/internal/services"
@@ -946,11 +945,7 @@ func (h *MatchPredictionHandler) GetPredictionHistory(c *gin.Context) {
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "20")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 20
-	}
+	limit, _, _ := ParsePagination(c)
 
 	history := gin.H{
 		"summoner_id": summonerID,
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herald-lol/herald/backend/internal/models"
 	"github.com/herald-lol/herald/backend/internal/services"
 )
 
@@ -211,6 +213,7 @@ func (mh *MetaHandler) GetTierList(c *gin.Context) {
 	}
 
 	// Validate role if provided
+	req.Role = models.NormalizeRole(req.Role)
 	if req.Role != "ALL" && !isValidPosition(req.Role) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "validation_error",
@@ -587,6 +590,66 @@ func (mh *MetaHandler) GetMetaPredictions(c *gin.Context) {
 	c.JSON(http.StatusOK, predictions)
 }
 
+// GetPoolGapRecommendations godoc
+// @Summary Get champion pool gap recommendations
+// @Description Recommends high-tier meta champions the current user doesn't play yet, favoring damage types missing from their pool, with the expected games to get comfortable on each based on how quickly the user ramped on champions of similar difficulty
+// @Tags meta
+// @Produce json
+// @Param patch query string true "Patch version (e.g., 14.1)"
+// @Param region query string false "Region filter (default: all)"
+// @Param rank query string false "Rank filter (default: all)"
+// @Param role query string false "Role (TOP, JUNGLE, MID, ADC, SUPPORT) - default: all roles"
+// @Param limit query int false "Number of recommendations (default: 5, max: 20)"
+// @Success 200 {object} services.PoolGapReport
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/recommendations/pool-gaps [get]
+func (mh *MetaHandler) GetPoolGapRecommendations(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	patch := c.Query("patch")
+	if patch == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Patch version is required",
+		})
+		return
+	}
+	region := c.DefaultQuery("region", "all")
+	rank := c.DefaultQuery("rank", "all")
+
+	role := models.NormalizeRole(c.DefaultQuery("role", "ALL"))
+	if role != "ALL" && !isValidPosition(role) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "validation_error",
+			Message: "Invalid role. Use: TOP, JUNGLE, MID, ADC, SUPPORT, or ALL",
+		})
+		return
+	}
+
+	limit, _, _ := ParsePaginationWithin(c, services.DefaultPoolGapLimit, services.MaxPoolGapLimit)
+
+	report, err := mh.metaService.GetPoolGapRecommendations(c.Request.Context(), fmt.Sprint(userID), role, patch, region, rank, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "analysis_error",
+			Message: "Failed to get pool gap recommendations",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // GetMetaRecommendations godoc
 // @Summary Get meta-based recommendations
 // @Description Returns personalized recommendations based on current meta
@@ -605,7 +668,7 @@ func (mh *MetaHandler) GetMetaPredictions(c *gin.Context) {
 func (mh *MetaHandler) GetMetaRecommendations(c *gin.Context) {
 	patch := c.Query("patch")
 	rank := c.DefaultQuery("rank", "all")
-	role := c.Query("role")
+	role := models.NormalizeRole(c.Query("role"))
 	recommendationType := c.Query("recommendation_type")
 
 	if patch == "" {
@@ -748,6 +811,28 @@ func (mh *MetaHandler) GetMetaHistory(c *gin.Context) {
 	c.JSON(http.StatusOK, history)
 }
 
+// GetCurrentPatch godoc
+// @Summary Get the current patch
+// @Description Returns the latest DataDragon version and the League patch it belongs to, refreshed periodically in the background
+// @Tags meta
+// @Produce json
+// @Success 200 {object} models.PatchInfo
+// @Failure 503 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/meta/patch [get]
+func (mh *MetaHandler) GetCurrentPatch(c *gin.Context) {
+	patch, ok := models.CurrentPatch()
+	if !ok {
+		c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+			Error:   "patch_unavailable",
+			Message: "The current patch has not been loaded from DataDragon yet",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, patch)
+}
+
 // Register routes for meta analytics
 func (mh *MetaHandler) RegisterRoutes(router *gin.RouterGroup) {
 	meta := router.Group("/meta")
@@ -761,5 +846,9 @@ func (mh *MetaHandler) RegisterRoutes(router *gin.RouterGroup) {
 		meta.GET("/predictions", mh.GetMetaPredictions)
 		meta.GET("/recommendations", mh.GetMetaRecommendations)
 		meta.GET("/history", mh.GetMetaHistory)
+		meta.GET("/patch", mh.GetCurrentPatch)
 	}
+
+	// Personal recommendations built on the meta
+	router.GET("/recommendations/pool-gaps", mh.GetPoolGapRecommendations)
 }
@@ -15,6 +15,7 @@ import (
 type NotificationHandler struct {
 	notificationService *services.NotificationService
 	wsUpgrader          websocket.Upgrader
+	adminGuard          gin.HandlerFunc
 }
 
 // NewNotificationHandler creates a new notification handler
@@ -32,6 +33,13 @@ func NewNotificationHandler(notificationService *services.NotificationService) *
 	}
 }
 
+// WithAdminGuard lets requests through guard reach the admin-only endpoints,
+// e.g. bulk sends. Without a guard those endpoints are disabled.
+func (h *NotificationHandler) WithAdminGuard(guard gin.HandlerFunc) *NotificationHandler {
+	h.adminGuard = guard
+	return h
+}
+
 // RegisterRoutes registers all notification routes
 func (h *NotificationHandler) RegisterRoutes(r *gin.RouterGroup) {
 	notifications := r.Group("/notifications")
@@ -41,7 +49,7 @@ func (h *NotificationHandler) RegisterRoutes(r *gin.RouterGroup) {
 
 		// Notification management
 		notifications.POST("/send", h.SendNotification)
-		notifications.POST("/send-bulk", h.SendBulkNotifications)
+		notifications.POST("/send-bulk", h.requireAdmin, h.SendBulkNotifications)
 		notifications.POST("/schedule", h.ScheduleNotification)
 
 		// Specific notification types
@@ -75,13 +83,46 @@ func (h *NotificationHandler) RegisterRoutes(r *gin.RouterGroup) {
 	}
 }
 
+// requireAdmin runs the admin guard, answering 404 when none is configured
+func (h *NotificationHandler) requireAdmin(c *gin.Context) {
+	if h.adminGuard == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "Admin endpoints are disabled",
+		})
+		return
+	}
+	h.adminGuard(c)
+}
+
+// notificationUser returns the ID of the user making the request. Every
+// notification is sent to or read for that user, never one named by the client.
+func notificationUser(c *gin.Context) (string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return "", false
+	}
+	return fmt.Sprint(userID), true
+}
+
+// notificationPathUser is notificationUser for routes that name the user in
+// the path, refusing requests for any other user
+func notificationPathUser(c *gin.Context) (string, bool) {
+	userID, ok := notificationUser(c)
+	if !ok {
+		return "", false
+	}
+	if c.Param("user_id") != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied to another user's notifications"})
+		return "", false
+	}
+	return userID, true
+}
+
 // HandleWebSocket handles WebSocket connections for real-time notifications
 func (h *NotificationHandler) HandleWebSocket(c *gin.Context) {
-	userID := c.Param("user_id")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "user_id is required",
-		})
+	userID, ok := notificationPathUser(c)
+	if !ok {
 		return
 	}
 
@@ -113,8 +154,12 @@ func (h *NotificationHandler) HandleWebSocket(c *gin.Context) {
 
 // SendNotification handles general notification sending requests
 func (h *NotificationHandler) SendNotification(c *gin.Context) {
+	userID, ok := notificationUser(c)
+	if !ok {
+		return
+	}
+
 	var request struct {
-		UserID   string                         `json:"user_id" binding:"required"`
 		Type     services.NotificationType      `json:"type" binding:"required"`
 		Channels []services.NotificationChannel `json:"channels" binding:"required"`
 		Content  *services.NotificationContent  `json:"content" binding:"required"`
@@ -131,7 +176,7 @@ func (h *NotificationHandler) SendNotification(c *gin.Context) {
 	}
 
 	notification := &services.NotificationJob{
-		UserID:   request.UserID,
+		UserID:   userID,
 		Type:     request.Type,
 		Channels: request.Channels,
 		Content:  request.Content,
@@ -213,8 +258,12 @@ func (h *NotificationHandler) SendBulkNotifications(c *gin.Context) {
 
 // ScheduleNotification handles notification scheduling requests
 func (h *NotificationHandler) ScheduleNotification(c *gin.Context) {
+	userID, ok := notificationUser(c)
+	if !ok {
+		return
+	}
+
 	var request struct {
-		UserID      string                         `json:"user_id" binding:"required"`
 		Type        services.NotificationType      `json:"type" binding:"required"`
 		Channels    []services.NotificationChannel `json:"channels" binding:"required"`
 		Content     *services.NotificationContent  `json:"content" binding:"required"`
@@ -239,7 +288,7 @@ func (h *NotificationHandler) ScheduleNotification(c *gin.Context) {
 	}
 
 	notification := &services.NotificationJob{
-		UserID:      request.UserID,
+		UserID:      userID,
 		Type:        request.Type,
 		Channels:    request.Channels,
 		Content:     request.Content,
@@ -316,8 +365,12 @@ func (h *NotificationHandler) SendEmailNotification(c *gin.Context) {
 
 // SendPushNotification handles push-specific notifications
 func (h *NotificationHandler) SendPushNotification(c *gin.Context) {
+	userID, ok := notificationUser(c)
+	if !ok {
+		return
+	}
+
 	var request struct {
-		UserID      string                        `json:"user_id" binding:"required"`
 		DeviceToken string                        `json:"device_token"`
 		Platform    string                        `json:"platform" binding:"required"`
 		Title       string                        `json:"title" binding:"required"`
@@ -338,7 +391,7 @@ func (h *NotificationHandler) SendPushNotification(c *gin.Context) {
 	}
 
 	push := &services.PushNotification{
-		UserID:      request.UserID,
+		UserID:      userID,
 		DeviceToken: request.DeviceToken,
 		Platform:    request.Platform,
 		Title:       request.Title,
@@ -369,9 +422,13 @@ func (h *NotificationHandler) SendPushNotification(c *gin.Context) {
 
 // SendRealtimeNotification handles real-time WebSocket notifications
 func (h *NotificationHandler) SendRealtimeNotification(c *gin.Context) {
+	userID, ok := notificationUser(c)
+	if !ok {
+		return
+	}
+
 	var request struct {
-		UserID string                 `json:"user_id" binding:"required"`
-		Data   map[string]interface{} `json:"data" binding:"required"`
+		Data map[string]interface{} `json:"data" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&request); err != nil {
@@ -382,7 +439,7 @@ func (h *NotificationHandler) SendRealtimeNotification(c *gin.Context) {
 		return
 	}
 
-	if err := h.notificationService.SendRealTimeNotification(request.UserID, request.Data); err != nil {
+	if err := h.notificationService.SendRealTimeNotification(userID, request.Data); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Failed to send real-time notification",
 			"details": err.Error(),
@@ -391,7 +448,7 @@ func (h *NotificationHandler) SendRealtimeNotification(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"user_id":   request.UserID,
+		"user_id":   userID,
 		"status":    "sent",
 		"message":   "Real-time notification sent successfully",
 		"timestamp": time.Now(),
@@ -402,8 +459,12 @@ func (h *NotificationHandler) SendRealtimeNotification(c *gin.Context) {
 
 // NotifyMatchComplete handles match completion notifications
 func (h *NotificationHandler) NotifyMatchComplete(c *gin.Context) {
+	userID, ok := notificationUser(c)
+	if !ok {
+		return
+	}
+
 	var request struct {
-		UserID     string                         `json:"user_id" binding:"required"`
 		MatchID    string                         `json:"match_id" binding:"required"`
 		Result     string                         `json:"result" binding:"required"` // "victory" or "defeat"
 		Champion   string                         `json:"champion" binding:"required"`
@@ -455,7 +516,7 @@ func (h *NotificationHandler) NotifyMatchComplete(c *gin.Context) {
 	}
 
 	notification := &services.NotificationJob{
-		UserID:   request.UserID,
+		UserID:   userID,
 		Type:     services.NotificationTypeMatchComplete,
 		Channels: request.Channels,
 		Content:  content,
@@ -484,8 +545,12 @@ func (h *NotificationHandler) NotifyMatchComplete(c *gin.Context) {
 
 // NotifyRankChange handles rank change notifications
 func (h *NotificationHandler) NotifyRankChange(c *gin.Context) {
+	userID, ok := notificationUser(c)
+	if !ok {
+		return
+	}
+
 	var request struct {
-		UserID      string                         `json:"user_id" binding:"required"`
 		OldRank     string                         `json:"old_rank" binding:"required"`
 		NewRank     string                         `json:"new_rank" binding:"required"`
 		LP          int                            `json:"lp"`
@@ -549,7 +614,7 @@ func (h *NotificationHandler) NotifyRankChange(c *gin.Context) {
 	}
 
 	notification := &services.NotificationJob{
-		UserID:   request.UserID,
+		UserID:   userID,
 		Type:     services.NotificationTypeRankChange,
 		Channels: request.Channels,
 		Content:  content,
@@ -585,8 +650,12 @@ func (h *NotificationHandler) NotifyRankChange(c *gin.Context) {
 
 // NotifyAchievement handles achievement notifications
 func (h *NotificationHandler) NotifyAchievement(c *gin.Context) {
+	userID, ok := notificationUser(c)
+	if !ok {
+		return
+	}
+
 	var request struct {
-		UserID          string                         `json:"user_id" binding:"required"`
 		AchievementID   string                         `json:"achievement_id" binding:"required"`
 		AchievementName string                         `json:"achievement_name" binding:"required"`
 		Description     string                         `json:"description"`
@@ -640,7 +709,7 @@ func (h *NotificationHandler) NotifyAchievement(c *gin.Context) {
 	}
 
 	notification := &services.NotificationJob{
-		UserID:   request.UserID,
+		UserID:   userID,
 		Type:     services.NotificationTypeAchievement,
 		Channels: request.Channels,
 		Content:  content,
@@ -673,8 +742,12 @@ func (h *NotificationHandler) NotifyAchievement(c *gin.Context) {
 
 // SendCoachingTip handles coaching tip notifications
 func (h *NotificationHandler) SendCoachingTip(c *gin.Context) {
+	userID, ok := notificationUser(c)
+	if !ok {
+		return
+	}
+
 	var request struct {
-		UserID      string                         `json:"user_id" binding:"required"`
 		TipCategory string                         `json:"tip_category" binding:"required"`
 		Title       string                         `json:"title" binding:"required"`
 		Content     string                         `json:"content" binding:"required"`
@@ -719,7 +792,7 @@ func (h *NotificationHandler) SendCoachingTip(c *gin.Context) {
 	}
 
 	notification := &services.NotificationJob{
-		UserID:   request.UserID,
+		UserID:   userID,
 		Type:     services.NotificationTypeCoachingTip,
 		Channels: request.Channels,
 		Content:  content,
@@ -750,11 +823,8 @@ func (h *NotificationHandler) SendCoachingTip(c *gin.Context) {
 
 // GetNotificationPreferences handles getting user preferences
 func (h *NotificationHandler) GetNotificationPreferences(c *gin.Context) {
-	userID := c.Param("user_id")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "user_id is required",
-		})
+	userID, ok := notificationPathUser(c)
+	if !ok {
 		return
 	}
 
@@ -775,11 +845,8 @@ func (h *NotificationHandler) GetNotificationPreferences(c *gin.Context) {
 
 // UpdateNotificationPreferences handles updating user preferences
 func (h *NotificationHandler) UpdateNotificationPreferences(c *gin.Context) {
-	userID := c.Param("user_id")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "user_id is required",
-		})
+	userID, ok := notificationPathUser(c)
+	if !ok {
 		return
 	}
 
@@ -807,114 +874,96 @@ func (h *NotificationHandler) UpdateNotificationPreferences(c *gin.Context) {
 	})
 }
 
+// Notification history keeps its larger pages from before the shared
+// pagination helpers
+const (
+	notificationHistoryPageSize    = 50
+	maxNotificationHistoryPageSize = 200
+)
+
 // GetNotificationHistory handles getting notification history
 func (h *NotificationHandler) GetNotificationHistory(c *gin.Context) {
-	userID := c.Param("user_id")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "user_id is required",
-		})
+	userID, ok := notificationPathUser(c)
+	if !ok {
 		return
 	}
 
-	limitStr := c.DefaultQuery("limit", "50")
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit <= 0 {
-		limit = 50
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "offset must be a non-negative integer",
+			})
+			return
+		}
 	}
-	if limit > 200 {
-		limit = 200
+	limit, offset, page := ParsePaginationWithin(c, notificationHistoryPageSize, maxNotificationHistoryPageSize)
+
+	unreadOnly := false
+	if unreadStr := c.Query("unread_only"); unreadStr != "" {
+		var err error
+		unreadOnly, err = strconv.ParseBool(unreadStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "unread_only must be a boolean",
+			})
+			return
+		}
 	}
 
 	notificationType := c.Query("type")
-	channel := c.Query("channel")
 
-	// Mock response - in real implementation would fetch from database
+	list := h.notificationService.ListNotifications(userID, services.NotificationListFilter{
+		Limit:      limit,
+		Offset:     offset,
+		UnreadOnly: unreadOnly,
+		Type:       services.NotificationType(notificationType),
+	})
+
 	c.JSON(http.StatusOK, gin.H{
-		"user_id": userID,
-		"notifications": []gin.H{
-			{
-				"id":           "notif_123456789",
-				"type":         "match_complete",
-				"title":        "🏆 Match Complete",
-				"message":      "Your victory match as Jinx is complete! KDA: 8/2/12",
-				"channels":     []string{"realtime", "push"},
-				"priority":     "normal",
-				"read":         false,
-				"created_at":   "2024-01-15T14:30:00Z",
-				"delivered_at": "2024-01-15T14:30:02Z",
-			},
-			{
-				"id":           "notif_123456790",
-				"type":         "rank_change",
-				"title":        "🎉 Rank Promotion!",
-				"message":      "Your rank changed from Gold III to Gold II (+18 LP)",
-				"channels":     []string{"realtime", "email", "push"},
-				"priority":     "high",
-				"read":         true,
-				"created_at":   "2024-01-15T13:45:00Z",
-				"delivered_at": "2024-01-15T13:45:01Z",
-			},
-		},
+		"user_id":       userID,
+		"notifications": list.Notifications,
+		"unread_count":  list.UnreadCount,
 		"pagination": gin.H{
-			"limit":    limit,
-			"total":    25,
-			"has_more": false,
+			"limit":    list.Limit,
+			"offset":   list.Offset,
+			"page":     page,
+			"total":    list.Total,
+			"has_more": list.HasMore,
 		},
 		"filters": gin.H{
-			"type":    notificationType,
-			"channel": channel,
+			"type":        notificationType,
+			"unread_only": unreadOnly,
 		},
 	})
 }
 
 // GetUnreadNotifications handles getting unread notifications
 func (h *NotificationHandler) GetUnreadNotifications(c *gin.Context) {
-	userID := c.Param("user_id")
-	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "user_id is required",
-		})
+	userID, ok := notificationPathUser(c)
+	if !ok {
 		return
 	}
 
-	// Mock response
+	list := h.notificationService.ListNotifications(userID, services.NotificationListFilter{
+		Limit:      50,
+		UnreadOnly: true,
+	})
+
 	c.JSON(http.StatusOK, gin.H{
-		"user_id":      userID,
-		"unread_count": 3,
-		"notifications": []gin.H{
-			{
-				"id":         "notif_123456789",
-				"type":       "match_complete",
-				"title":      "🏆 Match Complete",
-				"message":    "Your victory match as Jinx is complete!",
-				"priority":   "normal",
-				"created_at": "2024-01-15T14:30:00Z",
-			},
-			{
-				"id":         "notif_123456791",
-				"type":       "coaching_tip",
-				"title":      "💡 Farming Tip",
-				"message":    "Focus on CS in the first 15 minutes to maximize gold income",
-				"priority":   "low",
-				"created_at": "2024-01-15T14:00:00Z",
-			},
-			{
-				"id":         "notif_123456792",
-				"type":       "achievement",
-				"title":      "🏅 Achievement Unlocked!",
-				"message":    "You've earned the 'Pentakill Master' achievement!",
-				"priority":   "normal",
-				"created_at": "2024-01-15T13:50:00Z",
-			},
-		},
+		"user_id":       userID,
+		"unread_count":  list.UnreadCount,
+		"notifications": list.Notifications,
 	})
 }
 
 // MarkNotificationsRead handles marking notifications as read
 func (h *NotificationHandler) MarkNotificationsRead(c *gin.Context) {
+	userID, ok := notificationUser(c)
+	if !ok {
+		return
+	}
+
 	var request struct {
-		UserID          string   `json:"user_id" binding:"required"`
 		NotificationIDs []string `json:"notification_ids"`
 		MarkAllRead     bool     `json:"mark_all_read"`
 	}
@@ -927,13 +976,10 @@ func (h *NotificationHandler) MarkNotificationsRead(c *gin.Context) {
 		return
 	}
 
-	markedCount := len(request.NotificationIDs)
-	if request.MarkAllRead {
-		markedCount = 15 // Mock count
-	}
+	markedCount := h.notificationService.MarkNotificationsRead(userID, request.NotificationIDs, request.MarkAllRead)
 
 	c.JSON(http.StatusOK, gin.H{
-		"user_id":           request.UserID,
+		"user_id":           userID,
 		"marked_read_count": markedCount,
 		"mark_all_read":     request.MarkAllRead,
 		"updated_at":        time.Now(),
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestNotificationRoutesAreScopedToCaller(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-Test-User"); userID != "" {
+			c.Set("user_id", userID)
+		}
+	})
+	NewNotificationHandler(nil).RegisterRoutes(router.Group("/api/v1"))
+
+	tests := []struct {
+		name, method, path, user string
+		want                     int
+	}{
+		{"history without auth", "GET", "/api/v1/notifications/history/user-1", "", http.StatusUnauthorized},
+		{"another user's history", "GET", "/api/v1/notifications/history/user-2", "user-1", http.StatusForbidden},
+		{"another user's preferences", "PUT", "/api/v1/notifications/preferences/user-2", "user-1", http.StatusForbidden},
+		{"another user's socket", "GET", "/api/v1/notifications/ws/user-2", "user-1", http.StatusForbidden},
+		{"send without auth", "POST", "/api/v1/notifications/send", "", http.StatusUnauthorized},
+		{"bulk without admin guard", "POST", "/api/v1/notifications/send-bulk", "user-1", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{}`))
+		if tt.user != "" {
+			req.Header.Set("X-Test-User", tt.user)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		if recorder.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d", tt.name, recorder.Code, tt.want)
+		}
+	}
+}
+
+func TestNotificationBulkSendUsesAdminGuard(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	guard := func(c *gin.Context) {
+		c.AbortWithStatus(http.StatusUnauthorized)
+	}
+	NewNotificationHandler(nil).WithAdminGuard(guard).RegisterRoutes(router.Group("/api/v1"))
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, httptest.NewRequest("POST", "/api/v1/notifications/send-bulk", strings.NewReader(`{}`)))
+	if recorder.Code != http.StatusUnauthorized {
+		t.Errorf("Bulk send should go through the admin guard, got status %d", recorder.Code)
+	}
+}
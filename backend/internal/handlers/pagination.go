@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Page size bounds shared by every paginated endpoint
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ParsePagination reads limit, offset and page from the query string.
+// limit defaults to DefaultPageSize and is clamped to 1..MaxPageSize. offset
+// wins over page when both are given; page is 1-based and is otherwise
+// derived from offset. Invalid values fall back to the defaults.
+func ParsePagination(c *gin.Context) (limit, offset, page int) {
+	return ParsePaginationWithin(c, DefaultPageSize, MaxPageSize)
+}
+
+// ParsePaginationWithin is ParsePagination for an endpoint with its own
+// default and largest page size
+func ParsePaginationWithin(c *gin.Context, defaultLimit, maxLimit int) (limit, offset, page int) {
+	limit = defaultLimit
+	if value, err := strconv.Atoi(c.Query("limit")); err == nil && value > 0 {
+		limit = value
+	}
+	if limit > maxLimit {
+		limit = maxLimit
+	}
+
+	if value, err := strconv.Atoi(c.Query("offset")); err == nil && value >= 0 {
+		offset = value
+	} else if value, err := strconv.Atoi(c.Query("page")); err == nil && value > 1 {
+		offset = (value - 1) * limit
+	}
+
+	page = offset/limit + 1
+	return limit, offset, page
+}
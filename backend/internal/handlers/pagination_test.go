@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParsePagination(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query                   string
+		limit, offset, wantPage int
+	}{
+		{"", DefaultPageSize, 0, 1},
+		{"limit=50&offset=100", 50, 100, 3},
+		{"limit=500", MaxPageSize, 0, 1},
+		{"limit=-5&offset=-1", DefaultPageSize, 0, 1},
+		{"limit=10&page=3", 10, 20, 3},
+		{"limit=10&page=3&offset=5", 10, 5, 1},
+		{"limit=abc&page=xyz", DefaultPageSize, 0, 1},
+	}
+
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/?"+tt.query, nil)
+
+		limit, offset, page := ParsePagination(c)
+		if limit != tt.limit || offset != tt.offset || page != tt.wantPage {
+			t.Errorf("%q: got limit=%d offset=%d page=%d, want %d/%d/%d",
+				tt.query, limit, offset, page, tt.limit, tt.offset, tt.wantPage)
+		}
+	}
+}
+
+func TestParsePaginationWithin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		query                   string
+		limit, offset, wantPage int
+	}{
+		{"", 50, 0, 1},
+		{"limit=150&page=2", 150, 150, 2},
+		{"limit=500", 200, 0, 1},
+		{"limit=0", 50, 0, 1},
+	}
+
+	for _, tt := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("GET", "/?"+tt.query, nil)
+
+		limit, offset, page := ParsePaginationWithin(c, 50, 200)
+		if limit != tt.limit || offset != tt.offset || page != tt.wantPage {
+			t.Errorf("%q: got limit=%d offset=%d page=%d, want %d/%d/%d",
+				tt.query, limit, offset, page, tt.limit, tt.offset, tt.wantPage)
+		}
+	}
+}
@@ -174,15 +174,7 @@ func (h *PredictiveHandler) GetChampionRecommendations(c *gin.Context) {
 	role := c.Query("role")
 	playstyle := c.Query("playstyle")
 	metaFocus := c.DefaultQuery("meta_focus", "current")
-	limitStr := c.DefaultQuery("limit", "10")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid limit parameter",
-		})
-		return
-	}
+	limit, _, _ := ParsePaginationWithin(c, 10, MaxPageSize)
 
 	recommendations, err := h.predictiveService.GetChampionRecommendations(
 		summonerID,
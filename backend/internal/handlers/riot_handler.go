@@ -1,12 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 
+	"github.com/herald-lol/herald/backend/internal/models"
 	"github.com/herald-lol/herald/backend/internal/services"
 )
 
@@ -57,20 +61,11 @@ func (h *RiotHandler) LinkAccount(c *gin.Context) {
 		return
 	}
 
-	// Validate region
-	validRegions := []string{"na1", "euw1", "eun1", "kr", "jp1", "br1", "la1", "la2", "oc1", "tr1", "ru"}
-	isValidRegion := false
-	for _, region := range validRegions {
-		if req.Region == region {
-			isValidRegion = true
-			break
-		}
-	}
-
-	if !isValidRegion {
+	// Validate region against the configured routing table
+	if !h.riotService.IsSupportedRegion(req.Region) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Invalid region",
-			Message: "Region must be one of: " + "na1, euw1, eun1, kr, jp1, br1, la1, la2, oc1, tr1, ru",
+			Message: "Region must be one of: " + strings.Join(h.riotService.SupportedRegions(), ", "),
 		})
 		return
 	}
@@ -83,10 +78,10 @@ func (h *RiotHandler) LinkAccount(c *gin.Context) {
 				Error:   "Summoner not found",
 				Message: "No summoner found with that name and tag",
 			})
-		case services.ErrAPIKeyInvalid:
+		case services.ErrInvalidAPIKey:
 			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
-				Error:   "Service unavailable",
-				Message: "Riot API service is currently unavailable",
+				Error:   "Riot API key invalid",
+				Message: "The server's Riot API key is invalid or has expired",
 			})
 		case services.ErrRateLimitExceeded:
 			c.JSON(http.StatusTooManyRequests, ErrorResponse{
@@ -138,7 +133,7 @@ func (h *RiotHandler) GetLinkedAccounts(c *gin.Context) {
 
 // SyncMatches syncs recent matches for a Riot account
 // @Summary Sync matches
-// @Description Sync recent matches for the specified Riot account
+// @Description Sync recent matches for the specified Riot account. A count of 0 or no count syncs the user's maxSyncMatches preference. While another sync of the user is running, responds 202 with that sync's job ID instead of starting a second one.
 // @Tags riot
 // @Accept json
 // @Produce json
@@ -146,6 +141,7 @@ func (h *RiotHandler) GetLinkedAccounts(c *gin.Context) {
 // @Param account_id path string true "Riot Account ID"
 // @Param request body SyncMatchesRequest true "Sync parameters"
 // @Success 200 {object} SuccessResponse
+// @Success 202 {object} SyncInProgressResponse
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
 // @Failure 404 {object} ErrorResponse
@@ -171,30 +167,49 @@ func (h *RiotHandler) SyncMatches(c *gin.Context) {
 
 	var req struct {
 		Count int `json:"count"`
+		// Optional Riot match list filter, e.g. {"type": "ranked"} or {"queue": 420}
+		Type  string `json:"type"`
+		Queue int    `json:"queue"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
-		req.Count = 20 // Default count
+		req.Count = 0 // Fall back to the user's maxSyncMatches preference
+	}
+	filter := services.MatchListFilter{Type: req.Type, Queue: req.Queue}
+	if err := filter.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid match filter",
+			Message: err.Error(),
+		})
+		return
 	}
 
-	// Validate count
-	if req.Count <= 0 || req.Count > 100 {
-		req.Count = 20
+	// Validate count, 0 uses the preference; Riot pages are fetched
+	// internally 100 at a time
+	maxGames := h.riotService.MaxGameCount()
+	if req.Count < 0 || req.Count > maxGames {
+		respondInvalidSyncCount(c, maxGames)
+		return
 	}
 
-	err := h.riotService.SyncMatchHistory(c.Request.Context(), userID.(uuid.UUID).String(), accountID, req.Count)
+	err := h.riotService.SyncMatchHistory(c.Request.Context(), userID.(uuid.UUID).String(), accountID, req.Count, filter)
 	if err != nil {
+		if respondSyncInProgress(c, err) {
+			return
+		}
 		switch err {
 		case services.ErrRateLimitExceeded:
 			c.JSON(http.StatusTooManyRequests, ErrorResponse{
 				Error:   "Rate limit exceeded",
 				Message: "Too many requests, please try again later",
 			})
-		case services.ErrAPIKeyInvalid:
+		case services.ErrInvalidAPIKey:
 			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
-				Error:   "Service unavailable",
-				Message: "Riot API service is currently unavailable",
+				Error:   "Riot API key invalid",
+				Message: "The server's Riot API key is invalid or has expired",
 			})
+		case services.ErrGameCountExceeded:
+			respondInvalidSyncCount(c, maxGames)
 		default:
 			c.JSON(http.StatusInternalServerError, ErrorResponse{
 				Error:   "Sync failed",
@@ -210,6 +225,168 @@ func (h *RiotHandler) SyncMatches(c *gin.Context) {
 	})
 }
 
+// SyncAllAccounts starts syncing every Riot account linked to the current user
+// @Summary Sync all linked accounts
+// @Description Start syncing recent matches for every Riot account linked to the current user concurrently. The sync runs in the background; poll GET /riot/sync/all/{id} with the returned id for progress and the combined result. A count of 0 or no count syncs the user's maxSyncMatches preference. Riot rate limits are shared across all syncs.
+// @Tags riot
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body SyncMatchesRequest false "Sync parameters"
+// @Success 202 {object} services.BulkSyncResult "Bulk sync started, or SyncInProgressResponse if a sync is already running"
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /riot/sync/all [post]
+func (h *RiotHandler) SyncAllAccounts(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	var req struct {
+		Count int `json:"count"`
+		// Optional Riot match list filter, e.g. {"type": "ranked"} or {"queue": 420}
+		Type  string `json:"type"`
+		Queue int    `json:"queue"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		req.Count = 0 // Fall back to the user's maxSyncMatches preference
+	}
+	filter := services.MatchListFilter{Type: req.Type, Queue: req.Queue}
+	if err := filter.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid match filter",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	maxGames := h.riotService.MaxGameCount()
+	if req.Count < 0 || req.Count > maxGames {
+		respondInvalidSyncCount(c, maxGames)
+		return
+	}
+
+	result, err := h.riotService.SyncAllAccounts(c.Request.Context(), userID.(uuid.UUID).String(), req.Count, filter)
+	if err != nil {
+		if respondSyncInProgress(c, err) {
+			return
+		}
+		switch err {
+		case services.ErrNoLinkedAccounts:
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "No linked accounts",
+				Message: "Link a Riot account before syncing",
+			})
+		case services.ErrGameCountExceeded:
+			respondInvalidSyncCount(c, maxGames)
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Sync failed",
+				Message: "Failed to sync linked accounts",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusAccepted, result)
+}
+
+// GetBulkSync reports the progress of a sync started by SyncAllAccounts
+// @Summary Get bulk sync progress
+// @Description Get the per-account progress of a sync of all linked accounts; state becomes "completed" once every account finished. Finished syncs are kept for an hour.
+// @Tags riot
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Bulk sync ID"
+// @Success 200 {object} services.BulkSyncResult
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /riot/sync/all/{id} [get]
+func (h *RiotHandler) GetBulkSync(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	result, ok := h.riotService.GetBulkSync(userID.(uuid.UUID).String(), c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "Bulk sync not found",
+			Message: "No sync of all accounts with this ID",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SyncInProgressResponse is returned instead of starting a second sync while
+// one is already running for the user
+type SyncInProgressResponse struct {
+	Message   string     `json:"message"`
+	JobID     *uuid.UUID `json:"job_id,omitempty"`
+	StartedAt time.Time  `json:"started_at"`
+}
+
+// respondSyncInProgress answers 202 with the running sync's job when err
+// reports a sync already in progress, and returns whether it did
+func respondSyncInProgress(c *gin.Context, err error) bool {
+	var inProgress *services.SyncInProgressError
+	if !errors.As(err, &inProgress) {
+		return false
+	}
+
+	c.JSON(http.StatusAccepted, SyncInProgressResponse{
+		Message:   "A sync is already in progress",
+		JobID:     inProgress.JobID,
+		StartedAt: inProgress.StartedAt,
+	})
+	return true
+}
+
+// GetSyncStatus returns the state of the user's latest match sync
+// @Summary Get sync status
+// @Description Get the state of the current user's latest match sync, including the retry attempt and next retry time while waiting out Riot rate limits, and how many matches are still incomplete
+// @Tags riot
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} services.SyncStatus
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /riot/sync/status [get]
+func (h *RiotHandler) GetSyncStatus(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	status, ok := h.riotService.GetSyncStatus(userID.(uuid.UUID).String())
+	if !ok {
+		c.JSON(http.StatusNotFound, ErrorResponse{
+			Error:   "No sync found",
+			Message: "No match sync has run for this user yet",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
 // GetSummonerInfo gets basic summoner information
 // @Summary Get summoner info
 // @Description Get summoner information by name and tag
@@ -334,8 +511,10 @@ func (h *RiotHandler) GetRankedInfo(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param puuid query string true "Player PUUID"
-// @Param region query string true "Region"
+// @Param region query string false "Region (default: the user's primary account region)"
 // @Param count query int false "Number of matches (default: 20, max: 100)"
+// @Param type query string false "Only list matches of this type (ranked, normal, tourney, tutorial)"
+// @Param queue query int false "Only list matches of this queue ID"
 // @Success 200 {object} services.MatchHistory
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -343,17 +522,21 @@ func (h *RiotHandler) GetRankedInfo(c *gin.Context) {
 // @Router /riot/matches [get]
 func (h *RiotHandler) GetMatchHistory(c *gin.Context) {
 	puuid := c.Query("puuid")
-	region := c.Query("region")
 	countStr := c.Query("count")
 
-	if puuid == "" || region == "" {
+	if puuid == "" {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Missing parameters",
-			Message: "PUUID and region are required",
+			Message: "PUUID is required",
 		})
 		return
 	}
 
+	region, ok := h.resolveMatchRegion(c)
+	if !ok {
+		return
+	}
+
 	count := 20 // Default
 	if countStr != "" {
 		if parsed, err := strconv.Atoi(countStr); err == nil && parsed > 0 && parsed <= 100 {
@@ -361,7 +544,27 @@ func (h *RiotHandler) GetMatchHistory(c *gin.Context) {
 		}
 	}
 
-	matchHistory, err := h.riotService.GetMatchHistory(c.Request.Context(), region, puuid, count)
+	filter := services.MatchListFilter{Type: c.Query("type")}
+	if queueStr := c.Query("queue"); queueStr != "" {
+		queue, err := strconv.Atoi(queueStr)
+		if err != nil || queue <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid match filter",
+				Message: "queue must be a positive queue ID",
+			})
+			return
+		}
+		filter.Queue = queue
+	}
+	if err := filter.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid match filter",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	matchHistory, err := h.riotService.GetMatchHistory(c.Request.Context(), region, puuid, count, filter)
 	if err != nil {
 		switch err {
 		case services.ErrRateLimitExceeded:
@@ -383,12 +586,13 @@ func (h *RiotHandler) GetMatchHistory(c *gin.Context) {
 
 // GetMatchDetails gets detailed information about a specific match
 // @Summary Get match details
-// @Description Get detailed information about a specific match
+// @Description Get detailed information about a specific match, with each participant's item names next to the item IDs and an S-D performance grade against their role's benchmarks
 // @Tags riot
 // @Produce json
 // @Security BearerAuth
 // @Param match_id path string true "Match ID"
-// @Param region query string true "Region"
+// @Param region query string false "Region (default: the user's primary account region)"
+// @Param champion_names query string false "Champion names (display, internal) - default: display"
 // @Success 200 {object} services.MatchDetails
 // @Failure 400 {object} ErrorResponse
 // @Failure 401 {object} ErrorResponse
@@ -396,16 +600,25 @@ func (h *RiotHandler) GetMatchHistory(c *gin.Context) {
 // @Router /riot/matches/{match_id} [get]
 func (h *RiotHandler) GetMatchDetails(c *gin.Context) {
 	matchID := c.Param("match_id")
-	region := c.Query("region")
 
-	if matchID == "" || region == "" {
+	if matchID == "" {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "Missing parameters",
-			Message: "Match ID and region are required",
+			Message: "Match ID is required",
 		})
 		return
 	}
 
+	region, ok := h.resolveMatchRegion(c)
+	if !ok {
+		return
+	}
+
+	internalChampionNames, ok := parseChampionNames(c)
+	if !ok {
+		return
+	}
+
 	matchDetails, err := h.riotService.GetMatchDetails(c.Request.Context(), region, matchID)
 	if err != nil {
 		switch err {
@@ -428,9 +641,320 @@ func (h *RiotHandler) GetMatchDetails(c *gin.Context) {
 		return
 	}
 
+	h.riotService.ResolveItemNames(c.Request.Context(), matchDetails)
+	h.riotService.GradeParticipants(matchDetails)
+	h.riotService.NormalizeChampionNames(matchDetails, internalChampionNames)
 	c.JSON(http.StatusOK, matchDetails)
 }
 
+// parseChampionNames reads the champion_names query parameter, reporting
+// whether internal names were asked for. It answers 400 and returns false
+// for anything but display or internal.
+func parseChampionNames(c *gin.Context) (bool, bool) {
+	championNames := c.DefaultQuery("champion_names", "display")
+	if championNames != "display" && championNames != "internal" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid champion names",
+			Message: "Champion names must be display or internal",
+		})
+		return false, false
+	}
+	return championNames == "internal", true
+}
+
+// GetMatchTimeline returns the stored per-minute series of one of the user's matches
+// @Summary Get match timeline series
+// @Description Get the minute-by-minute gold, CS and XP of the user and their lane opponent in a match, with the differentials, for the match review charts. Timelines are only stored for matches synced with SYNC_FETCH_TIMELINES on.
+// @Tags matches
+// @Produce json
+// @Security BearerAuth
+// @Param match_id path string true "Match ID"
+// @Success 200 {object} services.MatchTimelineSeries
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /matches/{match_id}/timeline [get]
+func (h *RiotHandler) GetMatchTimeline(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	series, err := h.riotService.GetMatchTimelineSeries(c.Request.Context(), userID.(uuid.UUID).String(), c.Param("match_id"))
+	if err != nil {
+		if errors.Is(err, services.ErrTimelineNotStored) {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Timeline not found",
+				Message: "No timeline was stored for this match",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Lookup failed",
+			Message: "Failed to get match timeline",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+// respondInvalidSyncCount answers a sync count outside 0..maxGames; 0 syncs
+// the user's maxSyncMatches preference
+func respondInvalidSyncCount(c *gin.Context, maxGames int) {
+	c.JSON(http.StatusBadRequest, ErrorResponse{
+		Error:   "Invalid count",
+		Message: "Count must be between 1 and " + strconv.Itoa(maxGames) + ", or 0 to use the maxSyncMatches preference",
+	})
+}
+
+// resolveMatchRegion picks the region for a match lookup from the region
+// query parameter, falling back to the user's default. It writes the error
+// response and returns false when no usable region is found.
+func (h *RiotHandler) resolveMatchRegion(c *gin.Context) (string, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return "", false
+	}
+
+	region, err := h.riotService.ResolveMatchRegion(c.Request.Context(), userID.(uuid.UUID).String(), c.Query("region"))
+	if err != nil {
+		switch err {
+		case services.ErrRegionNotSupported:
+			c.JSON(http.StatusBadRequest, ErrorResponse{
+				Error:   "Invalid region",
+				Message: "Region must be one of: " + strings.Join(h.riotService.SupportedRegions(), ", "),
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Lookup failed",
+				Message: "Failed to determine the match region",
+			})
+		}
+		return "", false
+	}
+
+	return region, true
+}
+
+// ExportMatches downloads the user's synced matches as a zip archive
+// @Summary Export synced matches
+// @Description Download one JSON file per synced match. format=raw_json returns the unmodified Riot responses where available. The archive is streamed while matches are loaded; when many exports run at once, new ones wait for memory before starting.
+// @Tags riot
+// @Produce application/zip
+// @Security BearerAuth
+// @Param format query string false "Archive format (raw_json, json) - default: raw_json"
+// @Param champion_names query string false "Champion names in json records (display, internal) - default: display"
+// @Param item_names query bool false "Add item_names next to item IDs in json records - default: true"
+// @Success 200 {file} file
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /riot/matches/export [get]
+func (h *RiotHandler) ExportMatches(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	format := c.DefaultQuery("format", services.FormatRawJSON)
+	if format != services.FormatRawJSON && format != services.FormatNormalizedJSON {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid format",
+			Message: "Format must be raw_json or json",
+		})
+		return
+	}
+
+	internalChampionNames, ok := parseChampionNames(c)
+	if !ok {
+		return
+	}
+
+	itemNames, err := strconv.ParseBool(c.DefaultQuery("item_names", "true"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid item names",
+			Message: "item_names must be true or false",
+		})
+		return
+	}
+
+	// The archive is streamed as it is built; errors can only be reported as
+	// JSON until the first match has been written
+	c.Header("Content-Disposition", `attachment; filename="herald-matches-`+format+`.zip"`)
+	c.Header("Content-Type", "application/zip")
+	err = h.riotService.ExportMatchArchive(c.Request.Context(), c.Writer, userID.(uuid.UUID).String(), format, internalChampionNames, itemNames)
+	if err != nil {
+		if c.Writer.Written() {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Disposition")
+		c.Writer.Header().Del("Content-Type")
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Export failed",
+			Message: "Failed to export matches",
+		})
+		return
+	}
+}
+
+// IncompleteMatchesResponse lists the user's incomplete match stubs
+type IncompleteMatchesResponse struct {
+	Count   int                      `json:"count"`
+	Matches []models.IncompleteMatch `json:"matches"`
+}
+
+// GetIncompleteMatches lists matches that could not be stored
+// @Summary Get incomplete matches
+// @Description List matches from the user's history that could not be fetched or stored. They are retried on the next sync.
+// @Tags riot
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} IncompleteMatchesResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /riot/matches/incomplete [get]
+func (h *RiotHandler) GetIncompleteMatches(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	stubs, err := h.riotService.ListIncompleteMatches(c.Request.Context(), userID.(uuid.UUID).String())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Lookup failed",
+			Message: "Failed to load incomplete matches",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, IncompleteMatchesResponse{
+		Count:   len(stubs),
+		Matches: stubs,
+	})
+}
+
+// SyncJobsResponse lists a user's sync jobs
+type SyncJobsResponse struct {
+	Count int              `json:"count"`
+	Jobs  []models.SyncJob `json:"jobs"`
+}
+
+// ListSyncJobs lists the user's recent sync jobs
+// @Summary List sync jobs
+// @Description List the user's most recent match sync jobs with their status and error, newest first. Jobs left running past SYNC_LOCK_TIMEOUT are reported as failed.
+// @Tags riot
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter by status (running, completed, failed, retried)"
+// @Success 200 {object} SyncJobsResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /sync/jobs [get]
+func (h *RiotHandler) ListSyncJobs(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	status := c.Query("status")
+	switch status {
+	case "", services.SyncStateRunning, services.SyncStateCompleted, services.SyncStateFailed, services.SyncJobRetried:
+	default:
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid status",
+			Message: "Status must be one of: running, completed, failed, retried",
+		})
+		return
+	}
+
+	jobs, err := h.riotService.ListSyncJobs(c.Request.Context(), userID.(uuid.UUID).String(), status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Lookup failed",
+			Message: "Failed to load sync jobs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, SyncJobsResponse{
+		Count: len(jobs),
+		Jobs:  jobs,
+	})
+}
+
+// RetrySyncJob re-runs a failed sync job
+// @Summary Retry sync job
+// @Description Re-run a failed sync job for the same account and match count. Returns the new job, whose status tells whether the retry succeeded.
+// @Tags riot
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Sync job ID"
+// @Success 200 {object} models.SyncJob
+// @Success 202 {object} SyncInProgressResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 409 {object} ErrorResponse
+// @Router /sync/jobs/{id}/retry [post]
+func (h *RiotHandler) RetrySyncJob(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	job, err := h.riotService.RetrySyncJob(c.Request.Context(), userID.(uuid.UUID).String(), c.Param("id"))
+	if err != nil {
+		if respondSyncInProgress(c, err) {
+			return
+		}
+		switch err {
+		case services.ErrSyncJobNotFound:
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Sync job not found",
+				Message: "No sync job with this ID",
+			})
+		case services.ErrSyncJobNotRetryable:
+			c.JSON(http.StatusConflict, ErrorResponse{
+				Error:   "Sync job not retryable",
+				Message: "Only failed sync jobs can be retried",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Retry failed",
+				Message: "Failed to retry sync job",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
 // GetRateLimitStatus gets current rate limit status
 // @Summary Get rate limit status
 // @Description Get current rate limit status for different regions
@@ -453,3 +977,122 @@ func (h *RiotHandler) GetRateLimitStatus(c *gin.Context) {
 
 	c.JSON(http.StatusOK, status)
 }
+
+const (
+	maxValidateBatchSize     = 50
+	validateBatchConcurrency = 5
+)
+
+// ValidateAccountsBatch validates several Riot accounts in one request
+// @Summary Validate Riot accounts in batch
+// @Description Check that a list of Riot IDs exist without linking them or creating sessions
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body object true "Accounts to validate"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} ErrorResponse
+// @Router /auth/validate-batch [post]
+func (h *RiotHandler) ValidateAccountsBatch(c *gin.Context) {
+	var req struct {
+		Accounts []services.RiotAccountValidationRequest `json:"accounts" binding:"required,dive"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if len(req.Accounts) == 0 || len(req.Accounts) > maxValidateBatchSize {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid request format",
+			Message: "Between 1 and " + strconv.Itoa(maxValidateBatchSize) + " accounts must be provided",
+		})
+		return
+	}
+
+	// Accounts with an unsupported region are rejected without calling Riot
+	results := make([]services.RiotAccountValidationResult, len(req.Accounts))
+	toCheck := make([]services.RiotAccountValidationRequest, 0, len(req.Accounts))
+	checkIndex := make([]int, 0, len(req.Accounts))
+	for i, account := range req.Accounts {
+		if !h.riotService.IsSupportedRegion(account.Region) {
+			results[i] = services.RiotAccountValidationResult{
+				RiotID:  account.RiotID,
+				RiotTag: account.RiotTag,
+				Region:  account.Region,
+				Error:   "invalid region",
+			}
+			continue
+		}
+		toCheck = append(toCheck, account)
+		checkIndex = append(checkIndex, i)
+	}
+
+	checked := h.riotService.ValidateRiotAccounts(c.Request.Context(), toCheck, validateBatchConcurrency)
+	for i, result := range checked {
+		results[checkIndex[i]] = result
+	}
+
+	validCount := 0
+	for _, result := range results {
+		if result.Valid {
+			validCount++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+		"total":   len(results),
+		"valid":   validCount,
+		"invalid": len(results) - validCount,
+	})
+}
+
+// TestAPI checks that the Riot API key is usable
+// @Summary Test Riot API access
+// @Description Look up the configured test account to verify the Riot API key
+// @Tags riot
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 503 {object} ErrorResponse
+// @Router /riot/test [get]
+func (h *RiotHandler) TestAPI(c *gin.Context) {
+	account, err := h.riotService.TestAPIKey(c.Request.Context())
+	if err != nil {
+		switch err {
+		case services.ErrInvalidAPIKey:
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:   "Service unavailable",
+				Message: "Riot API key is invalid or expired",
+			})
+		case services.ErrSummonerNotFound:
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:   "Test account not found",
+				Message: "The configured Riot test account could not be found",
+			})
+		case services.ErrRateLimitExceeded:
+			c.JSON(http.StatusTooManyRequests, ErrorResponse{
+				Error:   "Rate limit exceeded",
+				Message: "Too many requests, please try again later",
+			})
+		default:
+			c.JSON(http.StatusServiceUnavailable, ErrorResponse{
+				Error:   "Service unavailable",
+				Message: "Riot API service is currently unavailable",
+			})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"status":    "ok",
+		"game_name": account.GameName,
+		"tag_line":  account.TagLine,
+	})
+}
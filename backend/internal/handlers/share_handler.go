@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"github.com/herald-lol/herald/backend/internal/services"
+)
+
+type ShareHandler struct {
+	shareService *services.ShareService
+}
+
+func NewShareHandler(shareService *services.ShareService) *ShareHandler {
+	return &ShareHandler{
+		shareService: shareService,
+	}
+}
+
+// CreateShareLinkRequest sets how long a share link stays valid
+type CreateShareLinkRequest struct {
+	ExpiresInHours int `json:"expires_in_hours"` // default 168 (7 days), max 2160 (90 days)
+}
+
+// CreateShareLink creates a read-only share link for the current user
+// @Summary Create share link
+// @Description Create an expiring token granting read-only access to the current user's public stats
+// @Tags share
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateShareLinkRequest false "Link lifetime"
+// @Success 201 {object} services.ShareLinkResponse
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Router /share [post]
+func (h *ShareHandler) CreateShareLink(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	var req CreateShareLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		req.ExpiresInHours = 0 // Empty body uses the default lifetime
+	}
+
+	if req.ExpiresInHours < 0 || time.Duration(req.ExpiresInHours)*time.Hour > services.MaxShareLinkTTL {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid expiry",
+			Message: "expires_in_hours must be between 1 and 2160",
+		})
+		return
+	}
+
+	response, err := h.shareService.CreateShareLink(fmt.Sprint(userID), time.Duration(req.ExpiresInHours)*time.Hour)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Share failed",
+			Message: "Failed to create share link",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListShareLinks returns the current user's share links
+// @Summary List share links
+// @Tags share
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.ShareLink
+// @Failure 401 {object} ErrorResponse
+// @Router /share [get]
+func (h *ShareHandler) ListShareLinks(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	links, err := h.shareService.ListShareLinks(fmt.Sprint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Lookup failed",
+			Message: "Failed to list share links",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, links)
+}
+
+// RevokeShareLink revokes one of the current user's share links
+// @Summary Revoke share link
+// @Tags share
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Share link ID"
+// @Success 204
+// @Failure 401 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Router /share/{id} [delete]
+func (h *ShareHandler) RevokeShareLink(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "Unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	shareID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Error:   "Invalid share ID",
+			Message: "Share link ID must be a UUID",
+		})
+		return
+	}
+
+	if err := h.shareService.RevokeShareLink(fmt.Sprint(userID), shareID); err != nil {
+		if err == services.ErrShareLinkNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Share link not found",
+				Message: "No active share link with that ID",
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{
+			Error:   "Revoke failed",
+			Message: "Failed to revoke share link",
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetSharedProfile serves the read-only view behind a share token (no auth)
+// @Summary View shared profile
+// @Description Read-only public stats and recent matches for the owner of a share token
+// @Tags share
+// @Produce json
+// @Param token path string true "Share token"
+// @Success 200 {object} services.SharedProfile
+// @Failure 403 {object} ErrorResponse
+// @Failure 404 {object} ErrorResponse
+// @Failure 410 {object} ErrorResponse
+// @Router /share/{token} [get]
+func (h *ShareHandler) GetSharedProfile(c *gin.Context) {
+	profile, err := h.shareService.GetSharedProfile(c.Param("token"))
+	if err != nil {
+		switch err {
+		case services.ErrShareLinkInactive:
+			c.JSON(http.StatusGone, ErrorResponse{
+				Error:   "Share link expired",
+				Message: "This share link has expired or was revoked",
+			})
+		case services.ErrProfileNotPublic:
+			c.JSON(http.StatusForbidden, ErrorResponse{
+				Error:   "Profile not public",
+				Message: "The owner of this link has made their profile private",
+			})
+		case services.ErrInvalidToken, services.ErrShareLinkNotFound, services.ErrUserNotFound:
+			c.JSON(http.StatusNotFound, ErrorResponse{
+				Error:   "Share link not found",
+				Message: "This share link is not valid",
+			})
+		default:
+			c.JSON(http.StatusInternalServerError, ErrorResponse{
+				Error:   "Lookup failed",
+				Message: "Failed to load shared profile",
+			})
+		}
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, profile)
+}
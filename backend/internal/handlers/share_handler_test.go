@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+	"github.com/herald-lol/herald/backend/internal/models"
+	"github.com/herald-lol/herald/backend/internal/services"
+)
+
+func newShareTestDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(
+		&models.User{},
+		&models.UserPreferences{},
+		&models.ShareLink{},
+	))
+	require.NoError(t, db.Exec(`CREATE TABLE riot_accounts (user_id TEXT, puuid TEXT)`).Error)
+	require.NoError(t, db.Create(&models.User{
+		ID:           "user-1",
+		Email:        "share@herald.lol",
+		Username:     "shareuser",
+		PasswordHash: "hash",
+		IsActive:     true,
+	}).Error)
+	return db
+}
+
+func TestAuthMiddlewareRejectsShareToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := newShareTestDB(t)
+	cfg := &config.Config{
+		JWT: config.JWTConfig{
+			Secret:     "test-jwt-secret-key",
+			Expiration: 24 * time.Hour,
+		},
+	}
+	authService := services.NewAuthService(db, cfg)
+	shareService := services.NewShareService(db, cfg)
+
+	share, err := shareService.CreateShareLink("user-1", time.Hour)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/me", NewAuthHandler(authService).AuthMiddleware(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+share.Token)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// The share token still resolves the public profile
+	profile, err := shareService.GetSharedProfile(share.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "shareuser", profile.Username)
+	assert.Empty(t, profile.RecentMatches)
+}
+
+func TestShareHandlerUsesAuthenticatedUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	db := newShareTestDB(t)
+	handler := NewShareHandler(services.NewShareService(db, &config.Config{}))
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", "user-1")
+	})
+	router.POST("/share", handler.CreateShareLink)
+	router.GET("/share", handler.ListShareLinks)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/share", nil))
+	require.Equal(t, http.StatusCreated, w.Code)
+
+	var created services.ShareLinkResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "user-1", created.Link.UserID)
+	assert.NotEmpty(t, created.Token)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/share", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var links []models.ShareLink
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &links))
+	require.Len(t, links, 1)
+	assert.Equal(t, created.Link.ID, links[0].ID)
+}
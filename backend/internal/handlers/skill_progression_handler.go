@@ -367,12 +367,7 @@ func (h *SkillProgressionHandler) GetRankHistory(c *gin.Context) {
 
 	season := c.DefaultQuery("season", "2024")
 	gameMode := c.DefaultQuery("gameMode", "ranked_solo")
-	limitStr := c.DefaultQuery("limit", "100")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 100
-	}
+	limit, _, _ := ParsePaginationWithin(c, 100, MaxPageSize)
 
 	// TODO: Query from database
 	mockHistory := []gin.H{
@@ -491,12 +486,7 @@ func (h *SkillProgressionHandler) GetRecommendations(c *gin.Context) {
 
 	priority := c.Query("priority") // critical, high, medium, low
 	status := c.DefaultQuery("status", "active")
-	limitStr := c.DefaultQuery("limit", "10")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 10
-	}
+	limit, _, _ := ParsePaginationWithin(c, 10, MaxPageSize)
 
 	timeRange := services.TimeRange{
 		StartDate:   time.Now().AddDate(0, -1, 0),
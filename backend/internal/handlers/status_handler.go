@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/herald-lol/herald/backend/internal/services"
+)
+
+type StatusHandler struct {
+	statusService *services.StatusService
+}
+
+func NewStatusHandler(statusService *services.StatusService) *StatusHandler {
+	return &StatusHandler{
+		statusService: statusService,
+	}
+}
+
+// GetStatus reports the state of every subsystem
+// @Summary System status
+// @Description Report the state of the Riot API, database, cache, analytics and auto-sync as ok, degraded, down or disabled, with an overall status for the frontend banner. Checks are reused for STATUS_CACHE_TTL (default 5s); checked_at tells when the status was taken. Always responds 200; the body carries the status.
+// @Tags status
+// @Produce json
+// @Success 200 {object} services.SystemStatus
+// @Router /status [get]
+func (h *StatusHandler) GetStatus(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, h.statusService.Status(c.Request.Context()))
+}
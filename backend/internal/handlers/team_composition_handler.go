@@ -131,12 +131,7 @@ func (h *TeamCompositionHandler) GetCompositionSuggestions(c *gin.Context) {
 	role := c.Query("role")
 	gameMode := c.DefaultQuery("gameMode", "ranked")
 	strategy := c.DefaultQuery("strategy", "balanced")
-	limitStr := c.DefaultQuery("limit", "10")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 10
-	}
+	limit, _, _ := ParsePaginationWithin(c, 10, MaxPageSize)
 
 	suggestions, err := h.service.GetCompositionSuggestions(summonerID, role, gameMode, strategy, limit)
 	if err != nil {
@@ -206,12 +201,7 @@ func (h *TeamCompositionHandler) GetMetaCompositions(c *gin.Context) {
 	rank := c.DefaultQuery("rank", "all")
 	region := c.DefaultQuery("region", "global")
 	patch := c.Query("patch")
-	limitStr := c.DefaultQuery("limit", "20")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 20
-	}
+	limit, _, _ := ParsePagination(c)
 
 	compositions, err := h.service.GetMetaCompositions(gameMode, rank, region, patch, limit)
 	if err != nil {
@@ -286,12 +276,7 @@ func (h *TeamCompositionHandler) GetRoleRecommendations(c *gin.Context) {
 	existingTeam := c.QueryArray("existing_champions")
 	gameMode := c.DefaultQuery("gameMode", "ranked")
 	strategy := c.DefaultQuery("strategy", "balanced")
-	limitStr := c.DefaultQuery("limit", "15")
-
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 15
-	}
+	limit, _, _ := ParsePaginationWithin(c, 15, MaxPageSize)
 
 	recommendations, err := h.service.GetRoleRecommendations(summonerID, role, existingTeam, gameMode, strategy, limit)
 	if err != nil {
@@ -348,17 +333,13 @@ func (h *TeamCompositionHandler) GetPlayerComfortPicks(c *gin.Context) {
 	role := c.Query("role")
 	gameMode := c.DefaultQuery("gameMode", "ranked")
 	recentGamesStr := c.DefaultQuery("recentGames", "50")
-	limitStr := c.DefaultQuery("limit", "20")
 
 	recentGames, err := strconv.Atoi(recentGamesStr)
 	if err != nil {
 		recentGames = 50
 	}
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil {
-		limit = 20
-	}
+	limit, _, _ := ParsePagination(c)
 
 	comfortPicks, err := h.service.GetPlayerComfortPicks(summonerID, role, gameMode, recentGames, limit)
 	if err != nil {
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/herald-lol/herald/backend/internal/models"
 	"github.com/herald-lol/herald/backend/internal/services"
 )
 
@@ -79,6 +81,7 @@ func (vh *VisionHandler) GetVisionAnalysis(c *gin.Context) {
 	}
 
 	// Validate position if provided
+	req.Position = models.NormalizeRole(req.Position)
 	if req.Position != "" && !isValidPosition(req.Position) {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Error:   "validation_error",
@@ -126,6 +129,39 @@ func (vh *VisionHandler) GetVisionHeatmap(c *gin.Context) {
 		return
 	}
 
+	vh.respondVisionHeatmap(c, playerID)
+}
+
+// GetMyVisionHeatmap godoc
+// @Summary Generate vision heatmap for the current user
+// @Description Aggregates the current user's ward placements, parsed from synced match timelines, into map-zone buckets
+// @Tags vision
+// @Accept json
+// @Produce json
+// @Param time_range query string true "Time range (7d, 30d, 90d)"
+// @Param ward_type query string true "Ward type (YELLOW, CONTROL, BLUE_TRINKET, ALL)"
+// @Param map_side query string false "Map side filter (BLUE, RED, BOTH)"
+// @Success 200 {object} services.HeatmapData
+// @Failure 400 {object} ErrorResponse
+// @Failure 401 {object} ErrorResponse
+// @Failure 500 {object} ErrorResponse
+// @Security ApiKeyAuth
+// @Router /api/v1/vision/heatmap [get]
+func (vh *VisionHandler) GetMyVisionHeatmap(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, ErrorResponse{
+			Error:   "unauthorized",
+			Message: "User ID not found in token",
+		})
+		return
+	}
+
+	vh.respondVisionHeatmap(c, fmt.Sprint(userID))
+}
+
+// respondVisionHeatmap validates the heatmap query and writes the player's heatmap
+func (vh *VisionHandler) respondVisionHeatmap(c *gin.Context, playerID string) {
 	var req HeatmapRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
 		c.JSON(http.StatusBadRequest, ErrorResponse{
@@ -176,7 +212,7 @@ func (vh *VisionHandler) GetVisionHeatmap(c *gin.Context) {
 	}
 
 	// Generate heatmap
-	heatmap, err := vh.visionService.GenerateVisionHeatmap(c.Request.Context(), playerID, req.TimeRange, req.WardType)
+	heatmap, err := vh.visionService.GenerateVisionHeatmap(c.Request.Context(), playerID, req.TimeRange, req.WardType, req.MapSide)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Error:   "heatmap_error",
@@ -557,6 +593,7 @@ func (vh *VisionHandler) GetVisionComparison(c *gin.Context) {
 func (vh *VisionHandler) RegisterRoutes(router *gin.RouterGroup) {
 	vision := router.Group("/vision")
 	{
+		vision.GET("/heatmap", vh.GetMyVisionHeatmap)
 		vision.GET("/:player_id/analysis", vh.GetVisionAnalysis)
 		vision.GET("/:player_id/heatmap", vh.GetVisionHeatmap)
 		vision.GET("/:player_id/recommendations", vh.GetVisionRecommendations)
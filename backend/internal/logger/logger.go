@@ -0,0 +1,139 @@
+// Package logger provides leveled logging on top of the standard log package.
+// The level is set once at startup from LOG_LEVEL (debug, info, warn, error);
+// messages below it are dropped before formatting. Messages are sanitized
+// before writing: control characters are escaped or dropped so an error
+// string can't forge or break log lines, and long messages are truncated at
+// the maximum line length (LOG_MAX_LINE_LENGTH).
+package logger
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Level is a logging severity
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+var levelNames = map[Level]string{
+	LevelDebug: "DEBUG",
+	LevelInfo:  "INFO",
+	LevelWarn:  "WARN",
+	LevelError: "ERROR",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return fmt.Sprintf("LEVEL(%d)", int32(l))
+}
+
+// DefaultMaxLineLength is the longest message, in bytes, written by default
+const DefaultMaxLineLength = 4096
+
+var (
+	current       atomic.Int32
+	maxLineLength atomic.Int64
+)
+
+func init() {
+	current.Store(int32(LevelInfo))
+	maxLineLength.Store(DefaultMaxLineLength)
+}
+
+// ParseLevel converts a level name such as "warn" or "WARNING" to a Level
+func ParseLevel(name string) (Level, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return LevelDebug, nil
+	case "info", "":
+		return LevelInfo, nil
+	case "warn", "warning":
+		return LevelWarn, nil
+	case "error":
+		return LevelError, nil
+	default:
+		return LevelInfo, fmt.Errorf("unknown log level %q", name)
+	}
+}
+
+// SetLevel sets the minimum level that is written
+func SetLevel(level Level) {
+	current.Store(int32(level))
+}
+
+// GetLevel returns the minimum level that is written
+func GetLevel() Level {
+	return Level(current.Load())
+}
+
+// SetMaxLineLength sets the longest message, in bytes, that is written in
+// full; longer messages are cut and marked with how much was dropped. Zero
+// or less disables truncation.
+func SetMaxLineLength(length int) {
+	maxLineLength.Store(int64(length))
+}
+
+// Enabled reports whether messages at level are written
+func Enabled(level Level) bool {
+	return level >= GetLevel()
+}
+
+func logf(level Level, format string, args ...interface{}) {
+	if !Enabled(level) {
+		return
+	}
+	log.Output(3, "["+level.String()+"] "+sanitize(fmt.Sprintf(format, args...), int(maxLineLength.Load())))
+}
+
+// controlEscaper writes line breaks and tabs as escapes so a multi-line
+// error stays on one log line
+var controlEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// sanitize makes message safe to write as a single log line. Newlines,
+// carriage returns and tabs are escaped, other control characters and
+// invalid UTF-8 are dropped, and messages longer than maxLength bytes are cut
+// at a character boundary with a note of how many bytes were left out.
+// maxLength <= 0 keeps the whole message.
+func sanitize(message string, maxLength int) string {
+	clean := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return -1
+		}
+		return r
+	}, strings.ToValidUTF8(message, ""))
+	clean = controlEscaper.Replace(clean)
+
+	if maxLength <= 0 || len(clean) <= maxLength {
+		return clean
+	}
+	cut := maxLength
+	for cut > 0 && !utf8.RuneStart(clean[cut]) {
+		cut--
+	}
+	return clean[:cut] + "... (" + strconv.Itoa(len(clean)-cut) + " bytes truncated)"
+}
+
+// Debugf logs detail useful only while debugging
+func Debugf(format string, args ...interface{}) { logf(LevelDebug, format, args...) }
+
+// Infof logs routine operational events
+func Infof(format string, args ...interface{}) { logf(LevelInfo, format, args...) }
+
+// Warnf logs recoverable problems
+func Warnf(format string, args ...interface{}) { logf(LevelWarn, format, args...) }
+
+// Errorf logs failures that need attention
+func Errorf(format string, args ...interface{}) { logf(LevelError, format, args...) }
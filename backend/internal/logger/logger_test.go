@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		name      string
+		message   string
+		maxLength int
+		want      string
+	}{
+		{"plain", "sync failed: 429", 100, "sync failed: 429"},
+		{"line breaks escaped", "bad response:\n{\"status\":500}\r\n", 100, `bad response:\n{"status":500}\r\n`},
+		{"control characters dropped", "user\x1b[31m red\x00\x7f", 100, "user[31m red"},
+		{"invalid utf-8 dropped", "champion \xffAhri", 100, "champion Ahri"},
+		{"truncated", "abcdefghij", 4, "abcd... (6 bytes truncated)"},
+		{"cut on a character boundary", "아리아리", 4, "아... (9 bytes truncated)"},
+		{"no limit", strings.Repeat("x", 10000), 0, strings.Repeat("x", 10000)},
+	}
+
+	for _, tt := range tests {
+		if got := sanitize(tt.message, tt.maxLength); got != tt.want {
+			t.Errorf("%s: sanitize(%q, %d) = %q, want %q", tt.name, tt.message, tt.maxLength, got, tt.want)
+		}
+	}
+}
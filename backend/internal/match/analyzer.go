@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/herald-lol/herald/backend/internal/analytics"
+	"github.com/herald-lol/herald/backend/internal/models"
 	"github.com/herald-lol/herald/backend/internal/riot"
 )
 
@@ -207,6 +208,7 @@ func (m *MatchAnalyzer) CompareMatches(ctx context.Context, request *MatchCompar
 func (m *MatchAnalyzer) extractMatchInfo(match *riot.Match, player *riot.Participant) *MatchInfo {
 	return &MatchInfo{
 		GameMode:     match.Info.GameMode,
+		QueueID:      match.Info.QueueID,
 		QueueType:    m.getQueueTypeName(match.Info.QueueID),
 		GameDuration: match.Info.GameDuration,
 		GameVersion:  match.Info.GameVersion,
@@ -856,20 +858,8 @@ func (m *MatchAnalyzer) calculateTeamplayRating(player *riot.Participant, contri
 
 // Utility helper methods
 func (m *MatchAnalyzer) getQueueTypeName(queueID int) string {
-	queueNames := map[int]string{
-		420: "Ranked Solo/Duo",
-		440: "Ranked Flex 5v5",
-		430: "Normal Blind Pick",
-		400: "Normal Draft Pick",
-		450: "ARAM",
-		700: "Clash",
-		900: "URF",
-	}
-
-	if name, exists := queueNames[queueID]; exists {
-		return name
-	}
-	return "Unknown Queue"
+	name, _ := models.ResolveQueue(queueID)
+	return name
 }
 
 func (m *MatchAnalyzer) normalizeRole(position string) string {
@@ -42,6 +42,7 @@ type MatchAnalysisResult struct {
 // MatchInfo contains basic match information
 type MatchInfo struct {
 	GameMode     string    `json:"game_mode"`
+	QueueID      int       `json:"queue_id"`
 	QueueType    string    `json:"queue_type"`
 	GameDuration int       `json:"game_duration"`
 	GameVersion  string    `json:"game_version"`
@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Herald.lol Gaming Analytics - Admin Token
+// Guards operator endpoints with a shared bearer token
+
+// AdminToken only lets through requests sending "Authorization: Bearer
+// <token>". An empty token disables the guarded routes entirely, answering
+// 404 so they aren't discoverable.
+func AdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"error":   "not_found",
+				"message": "Admin endpoints are disabled",
+			})
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "unauthorized",
+				"message": "A valid admin token is required",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,118 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Herald.lol Gaming Analytics - Request Body Limit
+// Caps request bodies so oversized payloads are rejected instead of buffered
+
+// BodyLimit rejects requests whose body is over maxBytes with 413 Request
+// Entity Too Large. A declared Content-Length over the limit is rejected
+// up front. Bodies without a declared length are cut off at maxBytes, and
+// once the handler reads past the limit its own response (usually a bind
+// error) is replaced with the 413. maxBytes <= 0 disables the limit.
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, bodyTooLarge(maxBytes))
+			return
+		}
+
+		body := &limitedBody{ReadCloser: http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)}
+		writer := &bodyLimitWriter{ResponseWriter: c.Writer, body: body, maxBytes: maxBytes}
+		c.Request.Body = body
+		c.Writer = writer
+		c.Next()
+
+		// The handler hit the limit but never answered
+		writer.reject()
+	}
+}
+
+func bodyTooLarge(maxBytes int64) gin.H {
+	return gin.H{
+		"error":     "request_too_large",
+		"message":   "Request body exceeds the maximum allowed size",
+		"max_bytes": maxBytes,
+	}
+}
+
+// limitedBody records whether a read ran past the body limit
+type limitedBody struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		b.exceeded = true
+	}
+	return n, err
+}
+
+// bodyLimitWriter answers 413 in place of whatever the handler writes once
+// its body read has run past the limit
+type bodyLimitWriter struct {
+	gin.ResponseWriter
+	body     *limitedBody
+	maxBytes int64
+	rejected bool
+}
+
+// reject writes the 413 response unless the handler answered before the
+// limit was hit. It reports whether the handler's own output must be dropped.
+func (w *bodyLimitWriter) reject() bool {
+	if !w.body.exceeded {
+		return false
+	}
+	if !w.rejected {
+		if w.ResponseWriter.Written() {
+			return false
+		}
+		w.rejected = true
+		payload, _ := json.Marshal(bodyTooLarge(w.maxBytes))
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.ResponseWriter.WriteHeader(http.StatusRequestEntityTooLarge)
+		_, _ = w.ResponseWriter.Write(payload)
+	}
+	return true
+}
+
+func (w *bodyLimitWriter) WriteHeader(code int) {
+	if !w.reject() {
+		w.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (w *bodyLimitWriter) WriteHeaderNow() {
+	if !w.reject() {
+		w.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+func (w *bodyLimitWriter) Write(data []byte) (int, error) {
+	if w.reject() {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyLimitWriter) WriteString(s string) (int, error) {
+	if w.reject() {
+		return len(s), nil
+	}
+	return w.ResponseWriter.WriteString(s)
+}
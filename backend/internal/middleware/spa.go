@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Herald.lol Gaming Analytics - Single Page App Serving
+// Fallback handler serving the built frontend for every route the API doesn't own
+
+// SPA serves the frontend build in root for requests no route matched. Use it
+// as the engine's NoRoute handler, after StaticCache:
+//   - unknown /api paths get a JSON 404, never the app
+//   - files that exist in root are served as-is
+//   - other paths with a file extension are missing assets and get a 404
+//   - anything else is a client-side route and gets index.html
+//
+// When root doesn't hold a build, non-API paths get a plain 404.
+func SPA(root string) gin.HandlerFunc {
+	index := filepath.Join(root, "index.html")
+
+	return func(c *gin.Context) {
+		urlPath := path.Clean("/" + c.Request.URL.Path)
+
+		if urlPath == "/api" || strings.HasPrefix(urlPath, "/api/") {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"error":   "not_found",
+				"message": "No API endpoint matches " + c.Request.Method + " " + urlPath,
+			})
+			return
+		}
+
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+
+		if urlPath != "/" {
+			file := filepath.Join(root, filepath.FromSlash(urlPath))
+			if info, err := os.Stat(file); err == nil && !info.IsDir() {
+				c.File(file)
+				return
+			}
+			if path.Ext(urlPath) != "" {
+				c.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+		}
+
+		if _, err := os.Stat(index); err != nil {
+			c.AbortWithStatus(http.StatusNotFound)
+			return
+		}
+		// Client-side routes share index.html, which must always be revalidated
+		c.Header("Cache-Control", NoCacheControl)
+		c.File(index)
+	}
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Herald.lol Gaming Analytics - Static Asset Caching
+// Cache headers for the built SPA so repeat visitors don't re-download JS/CSS
+
+// hashedAssetPattern matches Vite build output such as index-3f2a9c1b.js,
+// capturing the hash
+var hashedAssetPattern = regexp.MustCompile(`-([0-9A-Za-z_-]{8})\.[a-z0-9]+$`)
+
+const (
+	// ImmutableCacheControl is used for content-hashed files that never change
+	ImmutableCacheControl = "public, max-age=31536000, immutable"
+	// ShortCacheControl is used for unhashed assets that may change between deploys
+	ShortCacheControl = "public, max-age=3600"
+	// NoCacheControl forces revalidation, used for index.html
+	NoCacheControl = "no-cache"
+)
+
+// StaticCache sets Cache-Control, ETag and Last-Modified headers for files served
+// from root. Hashed build assets are cached for a year, index.html is always
+// revalidated. Conditional requests are answered by the file server once the
+// validators are set.
+func StaticCache(root string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		urlPath := c.Request.URL.Path
+		if urlPath == "/" || urlPath == "" {
+			urlPath = "/index.html"
+		}
+
+		cleaned := filepath.Clean("/" + urlPath)
+		info, err := os.Stat(filepath.Join(root, filepath.FromSlash(cleaned)))
+		if err != nil || info.IsDir() {
+			c.Next()
+			return
+		}
+
+		c.Header("Cache-Control", cacheControlFor(cleaned))
+		c.Header("ETag", fmt.Sprintf(`W/"%x-%x"`, info.Size(), info.ModTime().UnixNano()))
+		c.Header("Last-Modified", info.ModTime().UTC().Format("Mon, 02 Jan 2006 15:04:05 GMT"))
+
+		c.Next()
+	}
+}
+
+func cacheControlFor(path string) string {
+	base := filepath.Base(path)
+	if base == "index.html" {
+		return NoCacheControl
+	}
+	if strings.HasPrefix(path, "/assets/") && isHashedAsset(base) {
+		return ImmutableCacheControl
+	}
+	return ShortCacheControl
+}
+
+// isHashedAsset reports whether base carries a build hash. The hash must mix
+// digits and letters so names like user-settings.js aren't taken for one.
+func isHashedAsset(base string) bool {
+	match := hashedAssetPattern.FindStringSubmatch(base)
+	if match == nil {
+		return false
+	}
+	hash := match[1]
+	return strings.ContainsAny(hash, "0123456789") &&
+		strings.IndexFunc(hash, func(r rune) bool { return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' }) >= 0
+}
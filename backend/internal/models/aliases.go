@@ -0,0 +1,149 @@
+package models
+
+import (
+	"strings"
+	"sync"
+)
+
+// Canonical role names used throughout Herald.lol
+const (
+	RoleTop     = "TOP"
+	RoleJungle  = "JUNGLE"
+	RoleMid     = "MID"
+	RoleADC     = "ADC"
+	RoleSupport = "SUPPORT"
+)
+
+// roleAliases maps lower-cased spellings (including Riot's own position names)
+// to the canonical role
+var roleAliases = map[string]string{
+	"top":      RoleTop,
+	"toplane":  RoleTop,
+	"top lane": RoleTop,
+
+	"jungle":  RoleJungle,
+	"jungler": RoleJungle,
+	"jg":      RoleJungle,
+	"jgl":     RoleJungle,
+	"jung":    RoleJungle,
+
+	"mid":      RoleMid,
+	"middle":   RoleMid,
+	"midlane":  RoleMid,
+	"mid lane": RoleMid,
+
+	"adc":      RoleADC,
+	"bot":      RoleADC,
+	"bottom":   RoleADC,
+	"carry":    RoleADC,
+	"marksman": RoleADC,
+	"ad carry": RoleADC,
+
+	"support": RoleSupport,
+	"supp":    RoleSupport,
+	"sup":     RoleSupport,
+	"sp":      RoleSupport,
+	"utility": RoleSupport,
+}
+
+// championAliases maps common nicknames to champion display names. Riot's
+// internal names ("MonkeyKing", "Kaisa") are resolved through
+// championDisplayNames instead, so they are not repeated here.
+var championAliases = map[string]string{
+	"mf":     "Miss Fortune",
+	"tf":     "Twisted Fate",
+	"asol":   "Aurelion Sol",
+	"j4":     "Jarvan IV",
+	"jarvan": "Jarvan IV",
+	"lb":     "LeBlanc",
+	"mundo":  "Dr. Mundo",
+	"kha":    "Kha'Zix",
+	"cho":    "Cho'Gath",
+	"vel":    "Vel'Koz",
+	"kog":    "Kog'Maw",
+	"rek":    "Rek'Sai",
+	"ks":     "Kai'Sa",
+	"gp":     "Gangplank",
+	"tk":     "Tahm Kench",
+	"xin":    "Xin Zhao",
+	"ww":     "Warwick",
+	"yi":     "Master Yi",
+	"lee":    "Lee Sin",
+	"heimer": "Heimerdinger",
+	"fiddle": "Fiddlesticks",
+	"naut":   "Nautilus",
+	"blitz":  "Blitzcrank",
+	"ez":     "Ezreal",
+	"cait":   "Caitlyn",
+	"morg":   "Morgana",
+	"kass":   "Kassadin",
+	"malph":  "Malphite",
+	"voli":   "Volibear",
+}
+
+var aliasMu sync.RWMutex
+
+// NormalizeRole resolves any common spelling of a role ("mid", "Middle",
+// "supp", "UTILITY", ...) to its canonical name. Unknown values are returned
+// upper-cased and trimmed so callers can still validate them.
+func NormalizeRole(role string) string {
+	key := strings.ToLower(strings.TrimSpace(role))
+	if key == "" {
+		return ""
+	}
+
+	aliasMu.RLock()
+	canonical, ok := roleAliases[key]
+	aliasMu.RUnlock()
+	if ok {
+		return canonical
+	}
+
+	return strings.ToUpper(key)
+}
+
+// IsCanonicalRole reports whether role resolves to one of the five roles
+func IsCanonicalRole(role string) bool {
+	switch NormalizeRole(role) {
+	case RoleTop, RoleJungle, RoleMid, RoleADC, RoleSupport:
+		return true
+	}
+	return false
+}
+
+// ResolveChampionAlias maps a nickname such as "mf" or "j4", or Riot's
+// internal name such as "MonkeyKing", to the champion's display name. Names
+// that are neither are returned trimmed.
+func ResolveChampionAlias(name string) string {
+	trimmed := strings.TrimSpace(name)
+
+	aliasMu.RLock()
+	canonical, ok := championAliases[strings.ToLower(trimmed)]
+	aliasMu.RUnlock()
+	if ok {
+		return ChampionDisplayName(canonical)
+	}
+
+	return ChampionDisplayName(trimmed)
+}
+
+// ResolveChampionInternalName is ResolveChampionAlias returning Riot's
+// internal name ("MonkeyKing"), the form match-v5 stores and champion data
+// lookups are keyed by
+func ResolveChampionInternalName(name string) string {
+	return ChampionInternalName(ResolveChampionAlias(name))
+}
+
+// RegisterRoleAlias adds or overrides a role alias, e.g. from configuration
+func RegisterRoleAlias(alias, role string) {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+	roleAliases[strings.ToLower(strings.TrimSpace(alias))] = role
+}
+
+// RegisterChampionAlias adds or overrides a champion alias, e.g. from configuration
+func RegisterChampionAlias(alias, champion string) {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+	championAliases[strings.ToLower(strings.TrimSpace(alias))] = champion
+}
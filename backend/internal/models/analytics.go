@@ -142,8 +142,8 @@ type PlayerStats struct {
 
 	// Farm Stats
 	AverageCS       float64 `json:"average_cs" db:"average_cs"`
-	AverageCSPerMin float64 `json:"average_cs_per_minute" db:"average_cs_per_minute"`
-	BestCSPerMin    float64 `json:"best_cs_per_minute" db:"best_cs_per_minute"`
+	AverageCSPerMin float64 `json:"average_cs_per_minute" db:"average_cs_per_minute" gorm:"column:average_cs_per_minute"`
+	BestCSPerMin    float64 `json:"best_cs_per_minute" db:"best_cs_per_minute" gorm:"column:best_cs_per_minute"`
 
 	// Vision Stats
 	AverageVisionScore float64 `json:"average_vision_score" db:"average_vision_score"`
@@ -165,7 +165,7 @@ type PlayerStats struct {
 
 	// Role Performance
 	MainRole         string         `json:"main_role" db:"main_role"`
-	RoleDistribution map[string]int `json:"role_distribution"`
+	RoleDistribution map[string]int `json:"role_distribution" gorm:"-"`
 
 	// Current Rank
 	CurrentTier  string `json:"current_tier" db:"current_tier"`
@@ -177,21 +177,103 @@ type PlayerStats struct {
 	PerformanceScore float64 `json:"performance_score"` // 0-100
 }
 
+// SeasonArchive is a snapshot of a player's aggregate stats for a finished
+// season. Raw matches are kept; only the derived stats are reset.
+type SeasonArchive struct {
+	ID          uint         `json:"id" db:"id" gorm:"primaryKey"`
+	PlayerID    string       `json:"player_id" db:"player_id" gorm:"index;not null"`
+	Season      string       `json:"season" db:"season" gorm:"not null"`
+	SeasonStart time.Time    `json:"season_start" db:"season_start"`
+	ArchivedAt  time.Time    `json:"archived_at" db:"archived_at"`
+	StatsJSON   string       `json:"-" db:"stats" gorm:"column:stats;type:text"`
+	Stats       *PlayerStats `json:"stats" gorm:"-"`
+}
+
+// AnalyticsSnapshot is a point-in-time copy of a player's rolling aggregate
+// stats, written after syncs so progress can be compared between two dates
+type AnalyticsSnapshot struct {
+	ID            uint      `json:"id" db:"id" gorm:"primaryKey"`
+	PlayerID      string    `json:"player_id" db:"player_id" gorm:"index;not null"`
+	TakenAt       time.Time `json:"taken_at" db:"taken_at" gorm:"index"`
+	Games         int       `json:"games" db:"games"`
+	WinRate       float64   `json:"win_rate" db:"win_rate"`
+	AverageKDA    float64   `json:"average_kda" db:"average_kda"`
+	Tier          string    `json:"tier,omitempty" db:"tier"`
+	Division      string    `json:"division,omitempty" db:"division"`
+	LeaguePoints  int       `json:"league_points" db:"league_points"`
+	ChampionsJSON string    `json:"-" db:"champions" gorm:"column:champions;type:text"`
+	Champions     []string  `json:"champions" gorm:"-"`
+}
+
+// ChampionAggregate holds the running all-time sums of a player's games on one
+// champion. New matches are folded in with Add, so refreshing after a sync
+// only reads matches newer than LastMatchAt/LastMatchID.
+type ChampionAggregate struct {
+	PlayerID       string         `json:"player_id" db:"player_id" gorm:"primaryKey"`
+	ChampionID     int            `json:"champion_id" db:"champion_id" gorm:"primaryKey;autoIncrement:false"`
+	ChampionName   string         `json:"champion_name" db:"champion_name"`
+	Games          int            `json:"games" db:"games"`
+	Wins           int            `json:"wins" db:"wins"`
+	Kills          int            `json:"kills" db:"kills"`
+	Deaths         int            `json:"deaths" db:"deaths"`
+	Assists        int            `json:"assists" db:"assists"`
+	CSPerMinSum    float64        `json:"cs_per_minute_sum" db:"cs_per_minute_sum" gorm:"column:cs_per_minute_sum"`
+	VisionSum      float64        `json:"vision_score_sum" db:"vision_score_sum" gorm:"column:vision_score_sum"`
+	DamageShareSum float64        `json:"damage_share_sum" db:"damage_share_sum"`
+	RoleGamesJSON  string         `json:"-" db:"role_games" gorm:"column:role_games;type:text"`
+	RoleGames      map[string]int `json:"role_games" gorm:"-"`
+	LastMatchAt    time.Time      `json:"last_match_at" db:"last_match_at"`
+	LastMatchID    string         `json:"last_match_id" db:"last_match_id"`
+	UpdatedAt      time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// Add folds one match into the aggregate
+func (a *ChampionAggregate) Add(match MatchData) {
+	if a.RoleGames == nil {
+		a.RoleGames = make(map[string]int)
+	}
+	a.Games++
+	if match.Win {
+		a.Wins++
+	}
+	a.Kills += match.Kills
+	a.Deaths += match.Deaths
+	a.Assists += match.Assists
+	a.CSPerMinSum += match.CSPerMinute
+	a.VisionSum += float64(match.VisionScore)
+	a.DamageShareSum += match.DamageShare
+	a.RoleGames[match.Position]++
+
+	if match.ChampionName != "" {
+		a.ChampionName = match.ChampionName
+	}
+	if match.Date.After(a.LastMatchAt) || (match.Date.Equal(a.LastMatchAt) && match.MatchID > a.LastMatchID) {
+		a.LastMatchAt = match.Date
+		a.LastMatchID = match.MatchID
+	}
+}
+
 // ChampionStats represents champion-specific statistics
 type ChampionStats struct {
 	PlayerID     string `json:"player_id" db:"player_id"`
 	ChampionID   int    `json:"champion_id" db:"champion_id"`
 	ChampionName string `json:"champion_name" db:"champion_name"`
 
+	// Role the stats cover when split by role; empty when aggregated
+	Role string `json:"role,omitempty" db:"role"`
+
 	// Basic Stats
 	TotalMatches int     `json:"total_matches" db:"total_matches"`
 	Wins         int     `json:"wins" db:"wins"`
 	Losses       int     `json:"losses" db:"losses"`
 	WinRate      float64 `json:"win_rate" db:"win_rate"`
 
+	// Non-competitive games left out of these stats, when excluded
+	ExcludedGames int `json:"excluded_games,omitempty" db:"-" gorm:"-"`
+
 	// Performance
 	AverageKDA         float64 `json:"average_kda" db:"average_kda"`
-	AverageCSPerMin    float64 `json:"average_cs_per_minute" db:"average_cs_per_minute"`
+	AverageCSPerMin    float64 `json:"average_cs_per_minute" db:"average_cs_per_minute" gorm:"column:average_cs_per_minute"`
 	AverageVisionScore float64 `json:"average_vision_score" db:"average_vision_score"`
 	AverageDamageShare float64 `json:"average_damage_share" db:"average_damage_share"`
 
@@ -207,7 +289,7 @@ type ChampionStats struct {
 
 	// Role specific stats
 	PreferredRole     string                 `json:"preferred_role" db:"preferred_role"`
-	RoleSpecificStats map[string]interface{} `json:"role_specific_stats"`
+	RoleSpecificStats map[string]interface{} `json:"role_specific_stats" gorm:"-"`
 }
 
 // MatchTimeline represents detailed match timeline data
@@ -364,6 +446,10 @@ func (ChampionStats) TableName() string {
 	return "champion_stats"
 }
 
+func (ChampionAggregate) TableName() string {
+	return "champion_aggregates"
+}
+
 func (MatchTimeline) TableName() string {
 	return "match_timelines"
 }
@@ -0,0 +1,167 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DataDragon URLs for the current patch's champion list
+const (
+	DataDragonVersionsURL = "https://ddragon.leagueoflegends.com/api/versions.json"
+	// DataDragonChampionsURL takes the patch version
+	DataDragonChampionsURL = "https://ddragon.leagueoflegends.com/cdn/%s/data/en_US/champion.json"
+)
+
+// championDisplayNames maps Riot's internal champion names, as used in
+// match-v5 championName and DataDragon ids, to the names shown in the client.
+// Only champions whose two names differ are listed; DataDragon refreshes the
+// table at startup so new champions are covered without a release.
+var championDisplayNames = map[string]string{
+	"AurelionSol":  "Aurelion Sol",
+	"Belveth":      "Bel'Veth",
+	"Chogath":      "Cho'Gath",
+	"DrMundo":      "Dr. Mundo",
+	"FiddleSticks": "Fiddlesticks",
+	"JarvanIV":     "Jarvan IV",
+	"Kaisa":        "Kai'Sa",
+	"Khazix":       "Kha'Zix",
+	"KogMaw":       "Kog'Maw",
+	"KSante":       "K'Sante",
+	"Leblanc":      "LeBlanc",
+	"LeeSin":       "Lee Sin",
+	"MasterYi":     "Master Yi",
+	"MissFortune":  "Miss Fortune",
+	"MonkeyKing":   "Wukong",
+	"Nunu":         "Nunu & Willump",
+	"RekSai":       "Rek'Sai",
+	"Renata":       "Renata Glasc",
+	"TahmKench":    "Tahm Kench",
+	"TwistedFate":  "Twisted Fate",
+	"Velkoz":       "Vel'Koz",
+	"XinZhao":      "Xin Zhao",
+}
+
+var (
+	championNamesMu sync.RWMutex
+	// championInternalNames is the reverse of championDisplayNames, keyed by
+	// lower-cased display name
+	championInternalNames = reverseChampionNames(championDisplayNames)
+	// championDisplayKeys indexes championDisplayNames by lower-cased internal name
+	championDisplayKeys = lowerChampionKeys(championDisplayNames)
+	// championIDs maps champion IDs, as in match-v5 bans, to internal names;
+	// filled from DataDragon
+	championIDs = make(map[int]string)
+)
+
+// ChampionDisplayName returns the client display name for a champion given
+// either name form ("MonkeyKing" and "Wukong" both give "Wukong"). Unknown
+// names are returned trimmed.
+func ChampionDisplayName(name string) string {
+	trimmed := strings.TrimSpace(name)
+
+	championNamesMu.RLock()
+	defer championNamesMu.RUnlock()
+	key := strings.ToLower(trimmed)
+	if display, ok := championDisplayKeys[key]; ok {
+		return display
+	}
+	if internal, ok := championInternalNames[key]; ok {
+		return championDisplayNames[internal]
+	}
+	return trimmed
+}
+
+// ChampionInternalName returns Riot's internal name for a champion given
+// either name form ("Wukong" and "MonkeyKing" both give "MonkeyKing").
+// Unknown names are returned trimmed.
+func ChampionInternalName(name string) string {
+	trimmed := strings.TrimSpace(name)
+
+	championNamesMu.RLock()
+	defer championNamesMu.RUnlock()
+	if internal, ok := championInternalNames[strings.ToLower(trimmed)]; ok {
+		return internal
+	}
+	return trimmed
+}
+
+// ChampionName returns a champion's internal name when internal is set and
+// its display name otherwise, for responses that let the caller pick
+func ChampionName(name string, internal bool) string {
+	if internal {
+		return ChampionInternalName(name)
+	}
+	return ChampionDisplayName(name)
+}
+
+// RegisterChampionName records the display name of a champion's internal
+// name, e.g. from DataDragon. Champions whose names match are ignored.
+func RegisterChampionName(internal, display string) {
+	internal, display = strings.TrimSpace(internal), strings.TrimSpace(display)
+	if internal == "" || display == "" || internal == display {
+		return
+	}
+
+	championNamesMu.Lock()
+	defer championNamesMu.Unlock()
+	championDisplayNames[internal] = display
+	championDisplayKeys[strings.ToLower(internal)] = display
+	championInternalNames[strings.ToLower(display)] = internal
+}
+
+// ChampionNameByID returns the display name of a champion ID, or "" until
+// DataDragon's champion list has been loaded
+func ChampionNameByID(id int) string {
+	championNamesMu.RLock()
+	internal, ok := championIDs[id]
+	championNamesMu.RUnlock()
+	if !ok {
+		return ""
+	}
+	return ChampionDisplayName(internal)
+}
+
+// LoadChampionNames registers the display names and IDs in a DataDragon
+// champion.json and returns how many champions it lists
+func LoadChampionNames(data []byte) (int, error) {
+	var champions struct {
+		Data map[string]struct {
+			ID   string `json:"id"`
+			Key  string `json:"key"` // Champion ID
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &champions); err != nil {
+		return 0, fmt.Errorf("failed to parse champions: %w", err)
+	}
+
+	for _, champion := range champions.Data {
+		RegisterChampionName(champion.ID, champion.Name)
+		if id, err := strconv.Atoi(champion.Key); err == nil && champion.ID != "" {
+			championNamesMu.Lock()
+			championIDs[id] = champion.ID
+			championNamesMu.Unlock()
+		}
+	}
+
+	return len(champions.Data), nil
+}
+
+func reverseChampionNames(names map[string]string) map[string]string {
+	reversed := make(map[string]string, len(names))
+	for internal, display := range names {
+		reversed[strings.ToLower(display)] = internal
+	}
+	return reversed
+}
+
+func lowerChampionKeys(names map[string]string) map[string]string {
+	lowered := make(map[string]string, len(names))
+	for internal, display := range names {
+		lowered[strings.ToLower(internal)] = display
+	}
+	return lowered
+}
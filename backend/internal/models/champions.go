@@ -0,0 +1,118 @@
+package models
+
+// Champion damage types
+const (
+	DamagePhysical = "physical"
+	DamageMagic    = "magic"
+	DamageMixed    = "mixed"
+)
+
+// ChampionTraits describes how a champion deals damage and how hard it is to
+// pick up. Difficulty runs from 1 (easy) to 3 (hard).
+type ChampionTraits struct {
+	DamageType string `json:"damage_type"`
+	Difficulty int    `json:"difficulty"`
+}
+
+// championTraits holds curated traits for commonly played champions
+var championTraits = map[string]ChampionTraits{
+	// Marksmen
+	"Aphelios":     {DamagePhysical, 3},
+	"Ashe":         {DamagePhysical, 1},
+	"Caitlyn":      {DamagePhysical, 1},
+	"Draven":       {DamagePhysical, 3},
+	"Ezreal":       {DamageMixed, 2},
+	"Jhin":         {DamagePhysical, 2},
+	"Jinx":         {DamagePhysical, 1},
+	"Kai'Sa":       {DamageMixed, 2},
+	"Kog'Maw":      {DamageMixed, 2},
+	"Lucian":       {DamagePhysical, 2},
+	"Miss Fortune": {DamagePhysical, 1},
+	"Samira":       {DamagePhysical, 3},
+	"Sivir":        {DamagePhysical, 1},
+	"Twitch":       {DamagePhysical, 2},
+	"Varus":        {DamageMixed, 2},
+	"Vayne":        {DamagePhysical, 3},
+	"Xayah":        {DamagePhysical, 2},
+	"Zeri":         {DamagePhysical, 3},
+
+	// Mid
+	"Ahri":         {DamageMagic, 2},
+	"Akali":        {DamageMagic, 3},
+	"Annie":        {DamageMagic, 1},
+	"Aurelion Sol": {DamageMagic, 3},
+	"Azir":         {DamageMagic, 3},
+	"Cassiopeia":   {DamageMagic, 3},
+	"Katarina":     {DamageMagic, 3},
+	"LeBlanc":      {DamageMagic, 3},
+	"Lux":          {DamageMagic, 1},
+	"Malzahar":     {DamageMagic, 1},
+	"Orianna":      {DamageMagic, 2},
+	"Syndra":       {DamageMagic, 2},
+	"Talon":        {DamagePhysical, 2},
+	"Twisted Fate": {DamageMagic, 2},
+	"Veigar":       {DamageMagic, 1},
+	"Viktor":       {DamageMagic, 2},
+	"Yasuo":        {DamagePhysical, 3},
+	"Yone":         {DamageMixed, 3},
+	"Zed":          {DamagePhysical, 3},
+	"Vel'Koz":      {DamageMagic, 2},
+	"Heimerdinger": {DamageMagic, 2},
+	"Kassadin":     {DamageMagic, 2},
+
+	// Top
+	"Camille":     {DamagePhysical, 3},
+	"Darius":      {DamagePhysical, 1},
+	"Fiora":       {DamagePhysical, 3},
+	"Garen":       {DamagePhysical, 1},
+	"Gwen":        {DamageMagic, 2},
+	"Irelia":      {DamagePhysical, 3},
+	"Jax":         {DamageMixed, 2},
+	"Malphite":    {DamageMagic, 1},
+	"Mordekaiser": {DamageMagic, 1},
+	"Ornn":        {DamageMagic, 2},
+	"Riven":       {DamagePhysical, 3},
+	"Sett":        {DamagePhysical, 1},
+	"Teemo":       {DamageMagic, 1},
+
+	// Jungle
+	"Amumu":     {DamageMagic, 1},
+	"Elise":     {DamageMagic, 3},
+	"Graves":    {DamagePhysical, 2},
+	"Hecarim":   {DamagePhysical, 2},
+	"Jarvan IV": {DamagePhysical, 2},
+	"Kha'Zix":   {DamagePhysical, 2},
+	"Lee Sin":   {DamagePhysical, 3},
+	"Master Yi": {DamagePhysical, 1},
+	"Nidalee":   {DamageMagic, 3},
+	"Rek'Sai":   {DamagePhysical, 2},
+	"Sejuani":   {DamageMagic, 2},
+	"Vi":        {DamagePhysical, 1},
+	"Warwick":   {DamagePhysical, 1},
+	"Xin Zhao":  {DamagePhysical, 1},
+	"Zac":       {DamageMagic, 2},
+
+	// Support
+	"Blitzcrank":   {DamageMagic, 1},
+	"Janna":        {DamageMagic, 1},
+	"Karma":        {DamageMagic, 2},
+	"Leona":        {DamageMagic, 1},
+	"Lulu":         {DamageMagic, 2},
+	"Morgana":      {DamageMagic, 1},
+	"Nami":         {DamageMagic, 2},
+	"Nautilus":     {DamageMagic, 1},
+	"Pyke":         {DamagePhysical, 3},
+	"Rakan":        {DamageMagic, 2},
+	"Renata Glasc": {DamageMagic, 2},
+	"Senna":        {DamagePhysical, 2},
+	"Sona":         {DamageMagic, 1},
+	"Soraka":       {DamageMagic, 1},
+	"Thresh":       {DamageMagic, 3},
+	"Yuumi":        {DamageMagic, 1},
+}
+
+// LookupChampionTraits returns the curated traits for a champion
+func LookupChampionTraits(champion string) (ChampionTraits, bool) {
+	traits, ok := championTraits[ResolveChampionAlias(champion)]
+	return traits, ok
+}
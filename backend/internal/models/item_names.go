@@ -0,0 +1,96 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// DataDragonItemsURL takes the patch version
+const DataDragonItemsURL = "https://ddragon.leagueoflegends.com/cdn/%s/data/en_US/item.json"
+
+// maxItemNamePatches is how many patches' item lists are kept in memory
+const maxItemNamePatches = 8
+
+var (
+	itemNamesMu sync.RWMutex
+	// itemNames maps a patch ("14.23") to its item names by item ID
+	itemNames = make(map[string]map[int]string)
+	// itemNamePatches lists the cached patches, oldest first
+	itemNamePatches []string
+)
+
+// LoadItemNames caches the item names in a DataDragon item.json for the patch
+// of version and returns how many items it lists. The oldest patch is dropped
+// once more than a few are cached.
+func LoadItemNames(version string, data []byte) (int, error) {
+	var items struct {
+		Data map[string]struct {
+			Name string `json:"name"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return 0, fmt.Errorf("failed to parse items: %w", err)
+	}
+
+	names := make(map[int]string, len(items.Data))
+	for key, item := range items.Data {
+		if id, err := strconv.Atoi(key); err == nil && item.Name != "" {
+			names[id] = item.Name
+		}
+	}
+
+	patch := PatchFromVersion(version)
+
+	itemNamesMu.Lock()
+	defer itemNamesMu.Unlock()
+	if _, ok := itemNames[patch]; !ok {
+		itemNamePatches = append(itemNamePatches, patch)
+	}
+	itemNames[patch] = names
+	for len(itemNamePatches) > maxItemNamePatches {
+		delete(itemNames, itemNamePatches[0])
+		itemNamePatches = itemNamePatches[1:]
+	}
+
+	return len(names), nil
+}
+
+// HasItemNames reports whether the item list of the patch of version is cached
+func HasItemNames(version string) bool {
+	itemNamesMu.RLock()
+	defer itemNamesMu.RUnlock()
+	_, ok := itemNames[PatchFromVersion(version)]
+	return ok
+}
+
+// ItemName returns the name of an item as of the patch of version, falling
+// back to the most recently cached patches for items it doesn't list. Empty
+// slots (ID 0) and unknown items give "".
+func ItemName(version string, id int) string {
+	if id == 0 {
+		return ""
+	}
+
+	itemNamesMu.RLock()
+	defer itemNamesMu.RUnlock()
+	if name, ok := itemNames[PatchFromVersion(version)][id]; ok {
+		return name
+	}
+	for i := len(itemNamePatches) - 1; i >= 0; i-- {
+		if name, ok := itemNames[itemNamePatches[i]][id]; ok {
+			return name
+		}
+	}
+	return ""
+}
+
+// ItemNames resolves each item ID with ItemName, keeping the slot order
+func ItemNames(version string, ids []int) []string {
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = ItemName(version, id)
+	}
+	return names
+}
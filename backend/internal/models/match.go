@@ -23,6 +23,10 @@ type Match struct {
 	QueueID  int    `json:"queue_id" gorm:"not null"`  // 420 (Ranked Solo), 440 (Ranked Flex), etc.
 	MapID    int    `json:"map_id" gorm:"not null"`    // 11 (Summoner's Rift), etc.
 
+	// Readable queue names resolved from QueueID when loaded
+	QueueName      string `json:"queue_name" gorm:"-"`       // "Ranked Solo/Duo"
+	QueueShortName string `json:"queue_short_name" gorm:"-"` // "Solo/Duo"
+
 	// Timing
 	GameStartTimestamp int64  `json:"game_start_timestamp"`
 	GameEndTimestamp   int64  `json:"game_end_timestamp"`
@@ -32,6 +36,12 @@ type Match struct {
 	// Match Participants
 	Participants []MatchParticipant `json:"participants" gorm:"foreignKey:MatchID"`
 
+	// Champions banned by each team
+	Bans []MatchBan `json:"bans,omitempty" gorm:"foreignKey:MatchID"`
+
+	// Objectives secured by each team
+	TeamObjectives []MatchTeamObjectives `json:"team_objectives,omitempty" gorm:"foreignKey:MatchID"`
+
 	// Match Status
 	IsProcessed bool      `json:"is_processed" gorm:"default:false"`
 	ProcessedAt time.Time `json:"processed_at"`
@@ -40,6 +50,9 @@ type Match struct {
 
 	// Game Outcome
 	WinningTeam int `json:"winning_team"` // 100 (Blue) or 200 (Red)
+
+	// Original Riot match-v5 response, empty for test/imported matches
+	RawData string `json:"-" gorm:"type:text"`
 }
 
 // MatchParticipant represents a participant in a League of Legends match
@@ -52,7 +65,7 @@ type MatchParticipant struct {
 	Match   Match     `json:"-" gorm:"foreignKey:MatchID"`
 
 	// Player Information
-	PUUID        string `json:"puuid" gorm:"not null;index"`
+	PUUID        string `json:"puuid" gorm:"column:puuid;not null;index"`
 	SummonerName string `json:"summoner_name"`
 	SummonerID   string `json:"summoner_id"`
 
@@ -80,6 +93,10 @@ type MatchParticipant struct {
 	ChampionLevel int  `json:"champion_level"`
 	Won           bool `json:"won"`
 
+	// GameEndedInEarlySurrender is set for every participant of a game that
+	// ended in an early surrender vote
+	GameEndedInEarlySurrender bool `json:"game_ended_in_early_surrender"`
+
 	// Combat Stats
 	TotalDamageDealt            int `json:"total_damage_dealt"`
 	TotalDamageDealtToChampions int `json:"total_damage_dealt_to_champions"`
@@ -89,6 +106,11 @@ type MatchParticipant struct {
 	DamageDealtToObjectives     int `json:"damage_dealt_to_objectives"`
 	DamageDealtToTurrets        int `json:"damage_dealt_to_turrets"`
 
+	// Damage to champions by type
+	PhysicalDamageDealtToChampions int `json:"physical_damage_dealt_to_champions"`
+	MagicDamageDealtToChampions    int `json:"magic_damage_dealt_to_champions"`
+	TrueDamageDealtToChampions     int `json:"true_damage_dealt_to_champions"`
+
 	// Economy
 	GoldEarned  int     `json:"gold_earned"`
 	GoldSpent   int     `json:"gold_spent"`
@@ -111,6 +133,10 @@ type MatchParticipant struct {
 	Item5 int `json:"item5"`
 	Item6 int `json:"item6"` // Trinket
 
+	// ItemNames holds the names of Item0-Item6 in slot order when resolved
+	// for an export or match detail; empty slots are ""
+	ItemNames []string `json:"item_names,omitempty" gorm:"-"`
+
 	// Performance Metrics (calculated by Herald.lol)
 	KDA               float64 `json:"kda"`
 	KillParticipation float64 `json:"kill_participation"` // (kills + assists) / team kills
@@ -148,6 +174,108 @@ type MatchParticipant struct {
 	ObjectiveContribution float64 `json:"objective_contribution"` // Objective damage and participation
 }
 
+// MatchBan is a champion banned by one team during champion select
+type MatchBan struct {
+	ID      uuid.UUID `json:"-" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	MatchID uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+
+	TeamID     int `json:"team_id"` // 100 (Blue) or 200 (Red)
+	ChampionID int `json:"champion_id"`
+	PickTurn   int `json:"pick_turn"`
+}
+
+// MatchTeamObjectives is the objectives one team secured in a match. The
+// First flags mark the team that took the game's first of each objective;
+// neither team has it set when the objective was never taken.
+type MatchTeamObjectives struct {
+	ID      uuid.UUID `json:"-" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	MatchID uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+
+	TeamID int `json:"team_id"` // 100 (Blue) or 200 (Red)
+
+	FirstBlood     bool `json:"first_blood"`
+	FirstTower     bool `json:"first_tower"`
+	FirstDragon    bool `json:"first_dragon"`
+	FirstHerald    bool `json:"first_herald"`
+	FirstBaron     bool `json:"first_baron"`
+	FirstInhibitor bool `json:"first_inhibitor"`
+
+	TowerKills     int `json:"tower_kills"`
+	DragonKills    int `json:"dragon_kills"`
+	HeraldKills    int `json:"herald_kills"`
+	BaronKills     int `json:"baron_kills"`
+	InhibitorKills int `json:"inhibitor_kills"`
+}
+
+// Reasons a synced match could not be stored in full
+const (
+	IncompleteDetailsUnavailable = "details_unavailable"
+	IncompletePlayerNotFound     = "player_not_found"
+	IncompleteSaveFailed         = "save_failed"
+)
+
+// IncompleteMatch records a match from a player's history that could not be
+// stored, so gaps in the history are visible and the match is retried on the
+// next sync. It is removed once the match is saved.
+type IncompleteMatch struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID        string `json:"user_id" gorm:"not null;index"`
+	RiotAccountID string `json:"riot_account_id" gorm:"not null;uniqueIndex:idx_incomplete_account_match"`
+	MatchID       string `json:"match_id" gorm:"not null;uniqueIndex:idx_incomplete_account_match"` // Riot match ID
+
+	Reason        string    `json:"reason" gorm:"not null"`
+	Detail        string    `json:"detail"`
+	Attempts      int       `json:"attempts" gorm:"default:1"`
+	LastAttemptAt time.Time `json:"last_attempt_at"`
+}
+
+// SyncJob records one match history sync of a linked account, so failed
+// syncs stay visible and can be retried. A retry is a new job pointing at the
+// failed one through RetryOf.
+type SyncJob struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	UserID        string `json:"user_id" gorm:"not null;index"`
+	RiotAccountID string `json:"riot_account_id" gorm:"not null;index"`
+	Count         int    `json:"count"`                // Matches requested
+	MatchType     string `json:"match_type,omitempty"` // Match list filter, empty for all types
+	Queue         int    `json:"queue,omitempty"`      // Match list filter, 0 for all queues
+
+	Status       string     `json:"status" gorm:"not null;index"` // "running", "completed", "failed", "retried"
+	Error        string     `json:"error,omitempty"`
+	SavedMatches int        `json:"saved_matches"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	RetryOf      *uuid.UUID `json:"retry_of,omitempty" gorm:"type:uuid"`
+}
+
+// MatchTimelineFrame is a player's gold, CS and XP at one minute of a match
+// next to their lane opponent's, recorded from the match timeline when
+// SYNC_FETCH_TIMELINES is on. Opponent values are 0 when the player had no
+// lane opponent, see HasOpponent.
+type MatchTimelineFrame struct {
+	ID        uuid.UUID `json:"-" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	CreatedAt time.Time `json:"-"`
+
+	UserID  string `json:"-" gorm:"not null;uniqueIndex:idx_timeline_user_match_minute"`
+	MatchID string `json:"-" gorm:"not null;uniqueIndex:idx_timeline_user_match_minute"` // Riot match ID
+	Minute  int    `json:"minute" gorm:"not null;uniqueIndex:idx_timeline_user_match_minute"`
+
+	Gold int `json:"gold"`
+	CS   int `json:"cs"` // Lane and jungle minions
+	XP   int `json:"xp"`
+
+	HasOpponent  bool `json:"-"`
+	OpponentGold int  `json:"opponent_gold"`
+	OpponentCS   int  `json:"opponent_cs"`
+	OpponentXP   int  `json:"opponent_xp"`
+}
+
 // TFTMatch represents a Teamfight Tactics match
 type TFTMatch struct {
 	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
@@ -251,6 +379,12 @@ func (m *Match) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// AfterFind resolves the readable queue names
+func (m *Match) AfterFind(tx *gorm.DB) error {
+	m.QueueName, m.QueueShortName = ResolveQueue(m.QueueID)
+	return nil
+}
+
 func (mp *MatchParticipant) BeforeCreate(tx *gorm.DB) error {
 	if mp.ID == uuid.Nil {
 		mp.ID = uuid.New()
@@ -258,6 +392,27 @@ func (mp *MatchParticipant) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+func (b *MatchBan) BeforeCreate(tx *gorm.DB) error {
+	if b.ID == uuid.Nil {
+		b.ID = uuid.New()
+	}
+	return nil
+}
+
+func (o *MatchTeamObjectives) BeforeCreate(tx *gorm.DB) error {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return nil
+}
+
+func (f *MatchTimelineFrame) BeforeCreate(tx *gorm.DB) error {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return nil
+}
+
 func (tm *TFTMatch) BeforeCreate(tx *gorm.DB) error {
 	if tm.ID == uuid.Nil {
 		tm.ID = uuid.New()
@@ -292,6 +447,16 @@ func (mp *MatchParticipant) IsWin() bool {
 	return mp.Won
 }
 
+// ItemIDs returns the participant's final items in slot order, trinket last
+func (mp *MatchParticipant) ItemIDs() []int {
+	return []int{mp.Item0, mp.Item1, mp.Item2, mp.Item3, mp.Item4, mp.Item5, mp.Item6}
+}
+
+// ResolveItemNames fills ItemNames as of the game version's patch
+func (mp *MatchParticipant) ResolveItemNames(gameVersion string) {
+	mp.ItemNames = ItemNames(gameVersion, mp.ItemIDs())
+}
+
 func (tp *TFTParticipant) IsTop4() bool {
 	return tp.Placement <= 4
 }
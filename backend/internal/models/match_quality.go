@@ -0,0 +1,62 @@
+package models
+
+// Non-competitive game detection thresholds
+const (
+	// NonCompetitiveMinDuration is the game length in seconds below which a
+	// game was decided before it could be played out
+	NonCompetitiveMinDuration = 10 * 60
+	// AFKGoldPerMinute is the gold income below which a player was most
+	// likely away from keyboard for much of the game
+	AFKGoldPerMinute = 100.0
+	// ExtremeGoldDeficitAt15 is the lane gold deficit at 15 minutes treated
+	// as a game lost to a leaver or disconnect rather than outplayed
+	ExtremeGoldDeficitAt15 = 5000
+	// RemakeMaxDuration is the game length in seconds below which a game is
+	// treated as a remake when the remake flag itself isn't available
+	RemakeMaxDuration = 5 * 60
+)
+
+// Reasons a game is classified as non-competitive
+const (
+	NonCompetitiveRemake         = "remake"
+	NonCompetitiveEarlySurrender = "early_surrender"
+	NonCompetitiveTooShort       = "too_short"
+	NonCompetitiveAFK            = "afk"
+	NonCompetitiveGoldDeficit    = "gold_deficit"
+)
+
+// ClassifyNonCompetitive returns why a game should not count toward a
+// player's stats, or an empty string for a normal game. Zero gold values are
+// treated as unknown rather than as AFK.
+func ClassifyNonCompetitive(durationSeconds int, goldPerMinute float64, goldDiffAt15 int, remade, earlySurrender bool) string {
+	switch {
+	case remade:
+		return NonCompetitiveRemake
+	case earlySurrender:
+		return NonCompetitiveEarlySurrender
+	case durationSeconds > 0 && durationSeconds < NonCompetitiveMinDuration:
+		return NonCompetitiveTooShort
+	case goldPerMinute > 0 && goldPerMinute < AFKGoldPerMinute:
+		return NonCompetitiveAFK
+	case goldDiffAt15 <= -ExtremeGoldDeficitAt15:
+		return NonCompetitiveGoldDeficit
+	}
+	return ""
+}
+
+// IsLikelyRemake reports whether a game of the given length in seconds was
+// most likely remade; zero durations are treated as unknown
+func IsLikelyRemake(durationSeconds int) bool {
+	return durationSeconds > 0 && durationSeconds < RemakeMaxDuration
+}
+
+// NonCompetitiveReason classifies the match, see ClassifyNonCompetitive
+func (m MatchData) NonCompetitiveReason() string {
+	return ClassifyNonCompetitive(m.GameDuration, m.GoldPerMinute, m.GoldDiffAt15, m.GameWasRemade, m.GameEndedEarly)
+}
+
+// IsNonCompetitive reports whether the match should be left out of stats
+// when non-competitive games are excluded
+func (m MatchData) IsNonCompetitive() bool {
+	return m.NonCompetitiveReason() != ""
+}
@@ -321,6 +321,79 @@ func TestMatchParticipant_CalculateCSPerMinute(t *testing.T) {
 	}
 }
 
+func TestNormalizeRole(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"mid", RoleMid},
+		{"Middle", RoleMid},
+		{"MID", RoleMid},
+		{" support ", RoleSupport},
+		{"supp", RoleSupport},
+		{"sup", RoleSupport},
+		{"UTILITY", RoleSupport},
+		{"bottom", RoleADC},
+		{"jg", RoleJungle},
+		{"", ""},
+		{"feeder", "FEEDER"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			assert.Equal(t, tt.expected, NormalizeRole(tt.input))
+		})
+	}
+
+	assert.True(t, IsCanonicalRole("supp"))
+	assert.False(t, IsCanonicalRole("feeder"))
+}
+
+func TestResolveChampionAlias(t *testing.T) {
+	assert.Equal(t, "Miss Fortune", ResolveChampionAlias("MF"))
+	assert.Equal(t, "Jarvan IV", ResolveChampionAlias("j4"))
+	assert.Equal(t, "Jinx", ResolveChampionAlias(" Jinx "))
+	assert.Equal(t, "Wukong", ResolveChampionAlias("MonkeyKing"))
+	assert.Equal(t, "Kog'Maw", ResolveChampionAlias("kogmaw"))
+
+	assert.Equal(t, "MissFortune", ResolveChampionInternalName("mf"))
+	assert.Equal(t, "MonkeyKing", ResolveChampionInternalName("Wukong"))
+
+	RegisterChampionAlias("Sej", "Sejuani")
+	assert.Equal(t, "Sejuani", ResolveChampionAlias("sej"))
+}
+
+func TestChampionNames(t *testing.T) {
+	assert.Equal(t, "Wukong", ChampionDisplayName("MonkeyKing"))
+	assert.Equal(t, "Wukong", ChampionDisplayName("wukong"))
+	assert.Equal(t, "Kai'Sa", ChampionDisplayName("Kaisa"))
+	assert.Equal(t, "Jinx", ChampionDisplayName(" Jinx "))
+
+	assert.Equal(t, "MonkeyKing", ChampionInternalName("Wukong"))
+	assert.Equal(t, "MonkeyKing", ChampionInternalName("MonkeyKing"))
+	assert.Equal(t, "Jinx", ChampionInternalName("Jinx"))
+
+	assert.Equal(t, "MonkeyKing", ChampionName("Wukong", true))
+	assert.Equal(t, "Wukong", ChampionName("MonkeyKing", false))
+
+	RegisterChampionName("NewChamp", "New Champ")
+	assert.Equal(t, "New Champ", ChampionDisplayName("NewChamp"))
+	assert.Equal(t, "NewChamp", ChampionInternalName("New Champ"))
+}
+
+func TestChampionNameByID(t *testing.T) {
+	count, err := LoadChampionNames([]byte(`{"data": {
+		"MonkeyKing": {"id": "MonkeyKing", "key": "62", "name": "Wukong"},
+		"Jinx": {"id": "Jinx", "key": "222", "name": "Jinx"}
+	}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	assert.Equal(t, "Wukong", ChampionNameByID(62))
+	assert.Equal(t, "Jinx", ChampionNameByID(222))
+	assert.Empty(t, ChampionNameByID(-1))
+}
+
 func TestTFTParticipant_IsTop4(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -700,3 +773,84 @@ func BenchmarkMatchParticipant_CalculateCSPerMinute(b *testing.B) {
 		_ = participant.CalculateCSPerMinute(1800)
 	}
 }
+
+func TestResolveQueue(t *testing.T) {
+	name, shortName := ResolveQueue(420)
+	assert.Equal(t, "Ranked Solo/Duo", name)
+	assert.Equal(t, "Solo/Duo", shortName)
+
+	name, _ = ResolveQueue(99999)
+	assert.Equal(t, "Queue 99999", name)
+
+	err := LoadQueues([]byte(`[{"queueId": 99999, "map": "Summoner's Rift", "description": "5v5 Test games", "notes": null}]`))
+	assert.NoError(t, err)
+	name, _ = ResolveQueue(99999)
+	assert.Equal(t, "5v5 Test", name)
+}
+
+func TestClassifyNonCompetitive(t *testing.T) {
+	tests := []struct {
+		name     string
+		match    MatchData
+		expected string
+	}{
+		{"normal game", MatchData{GameDuration: 1800, GoldPerMinute: 380}, ""},
+		{"remake", MatchData{GameDuration: 200, GameWasRemade: true}, NonCompetitiveRemake},
+		{"early surrender", MatchData{GameDuration: 900, GameEndedEarly: true}, NonCompetitiveEarlySurrender},
+		{"too short", MatchData{GameDuration: 420, GoldPerMinute: 300}, NonCompetitiveTooShort},
+		{"afk", MatchData{GameDuration: 1800, GoldPerMinute: 60}, NonCompetitiveAFK},
+		{"gold deficit", MatchData{GameDuration: 1500, GoldPerMinute: 250, GoldDiffAt15: -6000}, NonCompetitiveGoldDeficit},
+		{"unknown gold", MatchData{GameDuration: 1800}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.match.NonCompetitiveReason())
+			assert.Equal(t, tt.expected != "", tt.match.IsNonCompetitive())
+		})
+	}
+}
+
+func TestLookupChampionTraits(t *testing.T) {
+	traits, ok := LookupChampionTraits("mf")
+	if !ok || traits.DamageType != DamagePhysical || traits.Difficulty != 1 {
+		t.Errorf("Expected Miss Fortune to resolve as an easy physical champion, got %+v (found: %t)", traits, ok)
+	}
+
+	if _, ok := LookupChampionTraits("Not A Champion"); ok {
+		t.Error("Unknown champions should not have traits")
+	}
+}
+
+func TestPatchFromVersion(t *testing.T) {
+	assert.Equal(t, "14.23", PatchFromVersion("14.23.1"))
+	assert.Equal(t, "14.23", PatchFromVersion("14.23.626.5123"))
+	assert.Equal(t, "lolpatch_7.20", PatchFromVersion("lolpatch_7.20"))
+
+	info, err := LoadDataDragonVersions([]byte(`["14.24.1", "14.23.1"]`))
+	assert.NoError(t, err)
+	assert.Equal(t, "14.24", info.Patch)
+
+	current, ok := CurrentPatch()
+	assert.True(t, ok)
+	assert.Equal(t, "14.24.1", current.DataDragonVersion)
+
+	_, err = LoadDataDragonVersions([]byte(`[]`))
+	assert.Error(t, err)
+}
+
+func TestItemNames(t *testing.T) {
+	count, err := LoadItemNames("14.23.1", []byte(`{"data": {"3006": {"name": "Berserker's Greaves"}, "3020": {"name": "Sorcerer's Shoes"}}}`))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.True(t, HasItemNames("14.23.626.5123"))
+
+	participant := MatchParticipant{Item0: 3006, Item1: 3020, Item2: 9999}
+	participant.ResolveItemNames("14.23.626.5123")
+	assert.Equal(t, []string{"Berserker's Greaves", "Sorcerer's Shoes", "", "", "", "", ""}, participant.ItemNames)
+
+	assert.Equal(t, "Sorcerer's Shoes", ItemName("13.1.1", 3020), "uncached patches fall back to the newest cached list")
+
+	_, err = LoadItemNames("14.24.1", []byte(`not json`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PatchInfo is the current League patch as published by DataDragon
+type PatchInfo struct {
+	DataDragonVersion string    `json:"data_dragon_version"` // e.g. "14.23.1"
+	Patch             string    `json:"patch"`               // e.g. "14.23"
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+var (
+	patchMu      sync.RWMutex
+	currentPatch PatchInfo
+)
+
+// CurrentPatch returns the latest patch loaded from DataDragon; ok is false
+// until the first refresh succeeds
+func CurrentPatch() (info PatchInfo, ok bool) {
+	patchMu.RLock()
+	defer patchMu.RUnlock()
+	return currentPatch, currentPatch.DataDragonVersion != ""
+}
+
+// LoadDataDragonVersions records the newest version in DataDragon's
+// versions.json as the current patch and returns it
+func LoadDataDragonVersions(data []byte) (PatchInfo, error) {
+	var versions []string
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return PatchInfo{}, fmt.Errorf("failed to parse DataDragon versions: %w", err)
+	}
+	if len(versions) == 0 {
+		return PatchInfo{}, fmt.Errorf("DataDragon returned no versions")
+	}
+
+	info := PatchInfo{
+		DataDragonVersion: versions[0],
+		Patch:             PatchFromVersion(versions[0]),
+		UpdatedAt:         time.Now(),
+	}
+
+	patchMu.Lock()
+	currentPatch = info
+	patchMu.Unlock()
+
+	return info, nil
+}
+
+// PatchFromVersion trims a DataDragon or game version ("14.23.1",
+// "14.23.626.5123") to the League patch ("14.23")
+func PatchFromVersion(version string) string {
+	parts := strings.SplitN(strings.TrimSpace(version), ".", 3)
+	if len(parts) < 2 {
+		return strings.TrimSpace(version)
+	}
+	return parts[0] + "." + parts[1]
+}
@@ -0,0 +1,108 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// QueuesURL is Riot's static list of queue IDs and their descriptions
+const QueuesURL = "https://static.developer.riotgames.com/docs/lol/queues.json"
+
+// queueName is the display name and abbreviated name of a queue
+type queueName struct {
+	Name      string
+	ShortName string
+}
+
+// knownQueues holds curated names for the queues players see most; Riot's
+// queues.json descriptions ("5v5 Ranked Solo games") are only used as a
+// fallback for everything else
+var knownQueues = map[int]queueName{
+	0:    {"Custom Game", "Custom"},
+	400:  {"Normal Draft", "Draft"},
+	420:  {"Ranked Solo/Duo", "Solo/Duo"},
+	430:  {"Normal Blind", "Blind"},
+	440:  {"Ranked Flex", "Flex"},
+	450:  {"ARAM", "ARAM"},
+	490:  {"Quickplay", "Quickplay"},
+	700:  {"Clash", "Clash"},
+	720:  {"ARAM Clash", "ARAM Clash"},
+	830:  {"Co-op vs. AI Intro", "Co-op"},
+	840:  {"Co-op vs. AI Beginner", "Co-op"},
+	850:  {"Co-op vs. AI Intermediate", "Co-op"},
+	900:  {"ARURF", "URF"},
+	1020: {"One for All", "OFA"},
+	1300: {"Nexus Blitz", "Blitz"},
+	1700: {"Arena", "Arena"},
+	1900: {"Pick URF", "URF"},
+}
+
+// loadedQueues caches descriptions parsed from queues.json
+var (
+	loadedQueues = map[int]string{}
+	queueMu      sync.RWMutex
+)
+
+// RankedQueueIDs are the ranked Summoner's Rift queues: 420 (Ranked
+// Solo/Duo) and 440 (Ranked Flex)
+var RankedQueueIDs = []int{420, 440}
+
+// IsRankedQueue reports whether a queue ID is one of RankedQueueIDs
+func IsRankedQueue(id int) bool {
+	for _, ranked := range RankedQueueIDs {
+		if id == ranked {
+			return true
+		}
+	}
+	return false
+}
+
+// ResolveQueue returns a human-readable name and short name for a queue ID,
+// e.g. 420 resolves to "Ranked Solo/Duo" and "Solo/Duo". Unknown IDs resolve
+// to "Queue <id>" so callers always have something to display.
+func ResolveQueue(id int) (name, shortName string) {
+	if known, ok := knownQueues[id]; ok {
+		return known.Name, known.ShortName
+	}
+
+	queueMu.RLock()
+	description, ok := loadedQueues[id]
+	queueMu.RUnlock()
+	if ok {
+		return description, description
+	}
+
+	fallback := fmt.Sprintf("Queue %d", id)
+	return fallback, fallback
+}
+
+// LoadQueues replaces the cached queue descriptions with the contents of
+// Riot's queues.json
+func LoadQueues(data []byte) error {
+	var entries []struct {
+		QueueID     int     `json:"queueId"`
+		Description *string `json:"description"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("failed to parse queues: %w", err)
+	}
+
+	queues := make(map[int]string, len(entries))
+	for _, entry := range entries {
+		if entry.Description == nil {
+			continue
+		}
+		description := strings.TrimSpace(strings.TrimSuffix(*entry.Description, " games"))
+		if description != "" {
+			queues[entry.QueueID] = description
+		}
+	}
+
+	queueMu.Lock()
+	loadedQueues = queues
+	queueMu.Unlock()
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ShareLink grants read-only access to a user's public stats until it expires
+// or is revoked. Only a SHA-256 hash of the opaque token is stored.
+type ShareLink struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key"` // set by ShareService
+	UserID    string     `json:"user_id" gorm:"not null;index"`
+	TokenHash string     `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null;index"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// IsActive returns true if the link is neither revoked nor expired
+func (l *ShareLink) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
@@ -2,8 +2,10 @@
 package models
 
 import (
-	"gorm.io/gorm"
 	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // SkillProgressionAnalysis represents a skill progression analysis result
@@ -291,7 +293,8 @@ type SkillPracticeSession struct {
 type SkillGoal struct {
 	ID              uint      `gorm:"primaryKey" json:"id"`
 	SummonerID      string    `gorm:"not null;index" json:"summonerId"`
-	GoalType        string    `gorm:"not null;index" json:"goalType"` // rank, skill_rating, champion_mastery, custom
+	UserID          uuid.UUID `gorm:"type:uuid;index" json:"userId"`
+	GoalType        string    `gorm:"not null;index" json:"goalType"` // rank, skill_rating, champion_mastery, champion, custom
 	Target          string    `gorm:"not null" json:"target"`         // target value (rank, rating, etc.)
 	Current         string    `json:"current"`                        // current value
 	Priority        string    `json:"priority"`                       // high, medium, low
@@ -306,6 +309,12 @@ type SkillGoal struct {
 	CreatedAt       time.Time `json:"createdAt"`
 	UpdatedAt       time.Time `json:"updatedAt"`
 
+	// Champion goals, e.g. a 60% win rate on Jinx over 20 games
+	Champion    string  `gorm:"index" json:"champion,omitempty"`
+	Metric      string  `json:"metric,omitempty"` // win_rate, kda, cs_per_min
+	TargetValue float64 `json:"targetValue,omitempty"`
+	TargetGames int     `json:"targetGames,omitempty"` // latest games the metric is measured over
+
 	// Foreign key
 	User User `gorm:"foreignKey:SummonerID;references:SummonerID" json:"user,omitempty"`
 }
@@ -43,7 +43,10 @@ type UserPreferences struct {
 	AnalyticsSharing        bool      `gorm:"default:false" json:"analyticsSharing"`
 	PrivacyMode             bool      `gorm:"default:false" json:"privacyMode"`
 	AutoSyncMatches         bool      `gorm:"default:true" json:"autoSyncMatches"`
+	MaxSyncMatches          int       `gorm:"default:20" json:"maxSyncMatches"` // matches fetched per sync, paged 100 at a time
 	CoachingRecommendations bool      `gorm:"default:true" json:"coachingRecommendations"`
+	PublicProfile           bool      `gorm:"default:true" json:"publicProfile"`   // required for share links
+	ExcludeRemakes          bool      `gorm:"default:false" json:"excludeRemakes"` // default for analytics and exports, overridable per request
 	CreatedAt               time.Time `json:"createdAt"`
 	UpdatedAt               time.Time `json:"updatedAt"`
 }
@@ -4,14 +4,16 @@ import (
 	"time"
 )
 
-// WardPlacement represents a ward placement event in a match
+// WardPlacement represents a ward placement event in a match. A player's
+// placement is unique per match, ward type and second, so resyncing a match
+// doesn't record its wards twice.
 type WardPlacement struct {
 	ID       string `json:"id" db:"id"`
-	MatchID  string `json:"match_id" db:"match_id"`
-	PlayerID string `json:"player_id" db:"player_id"`
+	MatchID  string `json:"match_id" db:"match_id" gorm:"uniqueIndex:idx_ward_placement"`
+	PlayerID string `json:"player_id" db:"player_id" gorm:"uniqueIndex:idx_ward_placement"`
 
 	// Ward Details
-	WardType string `json:"ward_type" db:"ward_type"` // "YELLOW", "CONTROL", "BLUE_TRINKET", "FARSIGHT"
+	WardType string `json:"ward_type" db:"ward_type" gorm:"uniqueIndex:idx_ward_placement"` // "YELLOW", "CONTROL", "BLUE_TRINKET", "FARSIGHT"
 	WardID   int    `json:"ward_id" db:"ward_id"`
 
 	// Position on Map
@@ -20,8 +22,8 @@ type WardPlacement struct {
 	MapSide string `json:"map_side" db:"map_side"` // "BLUE", "RED"
 
 	// Timing Information
-	Timestamp int    `json:"timestamp" db:"timestamp"`   // Game time in seconds
-	GamePhase string `json:"game_phase" db:"game_phase"` // "early", "mid", "late"
+	Timestamp int    `json:"timestamp" db:"timestamp" gorm:"uniqueIndex:idx_ward_placement"` // Game time in seconds
+	GamePhase string `json:"game_phase" db:"game_phase"`                                     // "early", "mid", "late"
 
 	// Strategic Information
 	Zone      string `json:"zone" db:"zone"`           // "jungle", "river", "lane", "baron", "dragon"
@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -54,3 +57,237 @@ func (r *MatchRepository) GetPlayerMatches(playerID string, limit int) ([]*model
 	}
 	return matches, nil
 }
+
+// MatchCursor marks the last match returned by a page; the zero value starts
+// from the beginning
+type MatchCursor struct {
+	Date    time.Time
+	MatchID string
+}
+
+// ChampionFilter narrows player match queries by champion. Names are
+// compared case-insensitively, so list every stored form of a champion
+// (Riot's internal name and its display name). The zero value keeps every
+// champion.
+type ChampionFilter struct {
+	Include []string // only these champions when set
+	Exclude []string // never these champions
+}
+
+// Where returns the filter's conditions on column as SQL starting with
+// " AND", numbering its placeholders after the args already bound
+func (f ChampionFilter) Where(column string, args []interface{}) (string, []interface{}) {
+	var sql strings.Builder
+	in := func(names []string) string {
+		placeholders := make([]string, len(names))
+		for i, name := range names {
+			args = append(args, strings.ToLower(name))
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+		return strings.Join(placeholders, ", ")
+	}
+
+	if len(f.Include) > 0 {
+		sql.WriteString(" AND LOWER(" + column + ") IN (" + in(f.Include) + ")")
+	}
+	if len(f.Exclude) > 0 {
+		sql.WriteString(" AND LOWER(" + column + ") NOT IN (" + in(f.Exclude) + ")")
+	}
+	return sql.String(), args
+}
+
+// playerMatchColumns are the match_participants/matches columns read into
+// MatchData by scanPlayerMatch. The gold difference at 15 minutes comes from
+// the timeline frame recorded for the user the account is linked to, and is
+// 0 when the match was synced without its timeline.
+const playerMatchColumns = `m.match_id, m.game_start_timestamp, m.game_duration, mp.champion_id, mp.champion_name,
+	COALESCE(mp.team_position, ''), mp.won, mp.kills, mp.deaths, mp.assists,
+	mp.total_cs, mp.cs_per_minute, mp.vision_score, mp.damage_share, mp.gold_earned,
+	mp.first_blood_kill, mp.first_blood_assist, COALESCE(mp.game_ended_in_early_surrender, false),
+	COALESCE((
+		SELECT f.gold - f.opponent_gold
+		FROM match_timeline_frames f
+		JOIN riot_accounts ra ON f.user_id = CAST(ra.user_id AS TEXT)
+		WHERE ra.puuid = mp.puuid AND f.match_id = m.match_id AND f.minute = 15 AND f.has_opponent
+		LIMIT 1
+	), 0)`
+
+func scanPlayerMatch(rows *sql.Rows, playerID string) (models.MatchData, error) {
+	m := models.MatchData{PlayerID: playerID}
+	var startedAt int64
+	if err := rows.Scan(&m.MatchID, &startedAt, &m.GameDuration, &m.ChampionID, &m.ChampionName, &m.Position, &m.Win,
+		&m.Kills, &m.Deaths, &m.Assists, &m.TotalCS, &m.CSPerMinute, &m.VisionScore, &m.DamageShare, &m.GoldEarned,
+		&m.FirstBloodKill, &m.FirstBloodAssist, &m.GameEndedEarly, &m.GoldDiffAt15); err != nil {
+		return m, fmt.Errorf("failed to scan match data: %w", err)
+	}
+	m.Date = time.UnixMilli(startedAt)
+	if m.GameDuration > 0 {
+		m.GoldPerMinute = float64(m.GoldEarned) / (float64(m.GameDuration) / 60)
+	}
+	m.GameWasRemade = models.IsLikelyRemake(m.GameDuration)
+	return m, nil
+}
+
+// GetPlayerMatchData returns a player's matches in [startDate, endDate) on
+// the champions the filter allows, newest first. playerID is the
+// participant PUUID.
+func (r *MatchRepository) GetPlayerMatchData(ctx context.Context, playerID string, startDate, endDate time.Time, champions ChampionFilter) ([]models.MatchData, error) {
+	championSQL, args := champions.Where("mp.champion_name", []interface{}{playerID, startDate.UnixMilli(), endDate.UnixMilli()})
+	query := `
+		SELECT ` + playerMatchColumns + `
+		FROM match_participants mp
+		JOIN matches m ON mp.match_id = m.id
+		WHERE mp.puuid = $1
+		AND m.game_start_timestamp >= $2 AND m.game_start_timestamp < $3` + championSQL + `
+		ORDER BY m.game_start_timestamp DESC, m.match_id DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query match data: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]models.MatchData, 0)
+	for rows.Next() {
+		m, err := scanPlayerMatch(rows, playerID)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, rows.Err()
+}
+
+// GetPlayerMatchDataPage returns up to limit of a player's matches in
+// [startDate, endDate), oldest first, strictly after cursor. Rows come from
+// the synced match_participants joined with matches, where playerID is the
+// participant PUUID. Pages are keyed on (game start, Riot match ID) so
+// results stay stable while new matches arrive.
+func (r *MatchRepository) GetPlayerMatchDataPage(ctx context.Context, playerID string, startDate, endDate time.Time, cursor MatchCursor, limit int) ([]models.MatchData, error) {
+	query := `
+		SELECT ` + playerMatchColumns + `
+		FROM match_participants mp
+		JOIN matches m ON mp.match_id = m.id
+		WHERE mp.puuid = $1
+		AND m.game_start_timestamp >= $2 AND m.game_start_timestamp < $3
+		AND (m.game_start_timestamp > $4 OR (m.game_start_timestamp = $4 AND m.match_id > $5))
+		ORDER BY m.game_start_timestamp, m.match_id
+		LIMIT $6
+	`
+
+	after := startDate.UnixMilli() - 1
+	if !cursor.Date.IsZero() {
+		after = cursor.Date.UnixMilli()
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, playerID, startDate.UnixMilli(), endDate.UnixMilli(), after, cursor.MatchID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query match data: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]models.MatchData, 0, limit)
+	for rows.Next() {
+		m, err := scanPlayerMatch(rows, playerID)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m)
+	}
+
+	return matches, rows.Err()
+}
+
+// ListPlayersWithMatchesBefore returns the PUUIDs of linked Riot accounts
+// that played a stored match started before cutoff
+func (r *MatchRepository) ListPlayersWithMatchesBefore(ctx context.Context, cutoff time.Time) ([]string, error) {
+	query := `
+		SELECT DISTINCT mp.puuid
+		FROM match_participants mp
+		JOIN matches m ON mp.match_id = m.id
+		JOIN riot_accounts ra ON ra.puuid = mp.puuid
+		WHERE m.game_start_timestamp < $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, cutoff.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("failed to list players with old matches: %w", err)
+	}
+	defer rows.Close()
+
+	players := make([]string, 0)
+	for rows.Next() {
+		var playerID string
+		if err := rows.Scan(&playerID); err != nil {
+			return nil, fmt.Errorf("failed to scan player id: %w", err)
+		}
+		players = append(players, playerID)
+	}
+
+	return players, rows.Err()
+}
+
+// ListLinkedPUUIDs returns the PUUIDs of the Riot accounts linked to a user
+func (r *MatchRepository) ListLinkedPUUIDs(ctx context.Context, userID string) ([]string, error) {
+	// Compare as text so a PUUID passed as userID matches nothing instead of
+	// failing the uuid cast
+	rows, err := r.db.QueryContext(ctx, `SELECT puuid FROM riot_accounts WHERE CAST(user_id AS TEXT) = $1`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list linked accounts: %w", err)
+	}
+	defer rows.Close()
+
+	puuids := make([]string, 0)
+	for rows.Next() {
+		var puuid string
+		if err := rows.Scan(&puuid); err != nil {
+			return nil, fmt.Errorf("failed to scan puuid: %w", err)
+		}
+		puuids = append(puuids, puuid)
+	}
+
+	return puuids, rows.Err()
+}
+
+// GetTimelineFrames returns the user's stored timeline frames at the given
+// minutes, keyed by Riot match ID and minute
+func (r *MatchRepository) GetTimelineFrames(ctx context.Context, userID string, minutes ...int) (map[string]map[int]models.MatchTimelineFrame, error) {
+	frames := make(map[string]map[int]models.MatchTimelineFrame)
+	if len(minutes) == 0 {
+		return frames, nil
+	}
+
+	args := []interface{}{userID}
+	placeholders := make([]string, len(minutes))
+	for i, minute := range minutes {
+		args = append(args, minute)
+		placeholders[i] = fmt.Sprintf("$%d", len(args))
+	}
+	query := `
+		SELECT match_id, minute, gold, cs, xp, has_opponent, opponent_gold, opponent_cs, opponent_xp
+		FROM match_timeline_frames
+		WHERE user_id = $1 AND minute IN (` + strings.Join(placeholders, ", ") + `)
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timeline frames: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		f := models.MatchTimelineFrame{UserID: userID}
+		if err := rows.Scan(&f.MatchID, &f.Minute, &f.Gold, &f.CS, &f.XP,
+			&f.HasOpponent, &f.OpponentGold, &f.OpponentCS, &f.OpponentXP); err != nil {
+			return nil, fmt.Errorf("failed to scan timeline frame: %w", err)
+		}
+		if frames[f.MatchID] == nil {
+			frames[f.MatchID] = make(map[int]models.MatchTimelineFrame)
+		}
+		frames[f.MatchID][f.Minute] = f
+	}
+
+	return frames, rows.Err()
+}
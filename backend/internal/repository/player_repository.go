@@ -1,7 +1,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/herald-lol/herald/backend/internal/models"
@@ -58,3 +61,269 @@ func (r *PlayerRepository) GetPlayerStats(playerID string) (map[string]interface
 	}
 	return stats, nil
 }
+
+// DeletePlayerStats removes every derived statistics row for a player
+func (r *PlayerRepository) DeletePlayerStats(ctx context.Context, playerID string) error {
+	for _, table := range []string{"player_stats", "champion_stats", "champion_aggregates"} {
+		query := fmt.Sprintf(`DELETE FROM %s WHERE player_id = $1`, table)
+		if _, err := r.db.ExecContext(ctx, query, playerID); err != nil {
+			return fmt.Errorf("failed to clear %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// SavePlayerStats stores an aggregated statistics row for a player and time range
+func (r *PlayerRepository) SavePlayerStats(ctx context.Context, stats *models.PlayerStats) error {
+	query := `
+		INSERT INTO player_stats (player_id, time_range, total_matches, wins, losses, win_rate,
+			total_kills, total_deaths, total_assists, average_kda, best_kda,
+			average_cs, average_cs_per_minute, average_vision_score, average_damage_share,
+			main_champion, total_champions_played, main_role, last_updated)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		stats.PlayerID, stats.TimeRange, stats.TotalMatches, stats.Wins, stats.Losses, stats.WinRate,
+		stats.TotalKills, stats.TotalDeaths, stats.TotalAssists, stats.AverageKDA, stats.BestKDA,
+		stats.AverageCS, stats.AverageCSPerMin, stats.AverageVisionScore, stats.AverageDamageShare,
+		stats.MainChampion, stats.TotalChampionsPlayed, stats.MainRole, stats.LastUpdated)
+	if err != nil {
+		return fmt.Errorf("failed to save player stats: %w", err)
+	}
+
+	return nil
+}
+
+// SaveChampionStats stores a player's all-time stats row for one champion
+func (r *PlayerRepository) SaveChampionStats(ctx context.Context, stats *models.ChampionStats) error {
+	query := `
+		INSERT INTO champion_stats (player_id, champion_id, champion_name, role, total_matches, wins, losses, win_rate,
+			average_kda, average_cs_per_minute, average_vision_score, average_damage_share, last_played, preferred_role)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		stats.PlayerID, stats.ChampionID, stats.ChampionName, stats.Role, stats.TotalMatches, stats.Wins, stats.Losses, stats.WinRate,
+		stats.AverageKDA, stats.AverageCSPerMin, stats.AverageVisionScore, stats.AverageDamageShare, stats.LastPlayed, stats.PreferredRole)
+	if err != nil {
+		return fmt.Errorf("failed to save champion stats: %w", err)
+	}
+
+	return nil
+}
+
+// ListChampionAggregates returns the player's running champion aggregates
+// keyed by champion ID
+func (r *PlayerRepository) ListChampionAggregates(ctx context.Context, playerID string) (map[int]*models.ChampionAggregate, error) {
+	query := `
+		SELECT player_id, champion_id, champion_name, games, wins, kills, deaths, assists,
+			cs_per_minute_sum, vision_score_sum, damage_share_sum, role_games,
+			last_match_at, last_match_id, updated_at
+		FROM champion_aggregates
+		WHERE player_id = $1
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list champion aggregates: %w", err)
+	}
+	defer rows.Close()
+
+	aggregates := make(map[int]*models.ChampionAggregate)
+	for rows.Next() {
+		agg := &models.ChampionAggregate{}
+		if err := rows.Scan(&agg.PlayerID, &agg.ChampionID, &agg.ChampionName, &agg.Games, &agg.Wins,
+			&agg.Kills, &agg.Deaths, &agg.Assists, &agg.CSPerMinSum, &agg.VisionSum, &agg.DamageShareSum,
+			&agg.RoleGamesJSON, &agg.LastMatchAt, &agg.LastMatchID, &agg.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan champion aggregate: %w", err)
+		}
+
+		agg.RoleGames = make(map[string]int)
+		if agg.RoleGamesJSON != "" {
+			if err := json.Unmarshal([]byte(agg.RoleGamesJSON), &agg.RoleGames); err != nil {
+				return nil, fmt.Errorf("failed to decode role games: %w", err)
+			}
+		}
+		aggregates[agg.ChampionID] = agg
+	}
+
+	return aggregates, rows.Err()
+}
+
+// SaveChampionAggregate inserts or replaces a running champion aggregate
+func (r *PlayerRepository) SaveChampionAggregate(ctx context.Context, agg *models.ChampionAggregate) error {
+	data, err := json.Marshal(agg.RoleGames)
+	if err != nil {
+		return fmt.Errorf("failed to encode role games: %w", err)
+	}
+	agg.RoleGamesJSON = string(data)
+
+	query := `
+		INSERT INTO champion_aggregates (player_id, champion_id, champion_name, games, wins, kills, deaths, assists,
+			cs_per_minute_sum, vision_score_sum, damage_share_sum, role_games, last_match_at, last_match_id, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		ON CONFLICT (player_id, champion_id) DO UPDATE SET
+			champion_name = EXCLUDED.champion_name, games = EXCLUDED.games, wins = EXCLUDED.wins,
+			kills = EXCLUDED.kills, deaths = EXCLUDED.deaths, assists = EXCLUDED.assists,
+			cs_per_minute_sum = EXCLUDED.cs_per_minute_sum, vision_score_sum = EXCLUDED.vision_score_sum,
+			damage_share_sum = EXCLUDED.damage_share_sum, role_games = EXCLUDED.role_games,
+			last_match_at = EXCLUDED.last_match_at, last_match_id = EXCLUDED.last_match_id,
+			updated_at = EXCLUDED.updated_at
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		agg.PlayerID, agg.ChampionID, agg.ChampionName, agg.Games, agg.Wins, agg.Kills, agg.Deaths, agg.Assists,
+		agg.CSPerMinSum, agg.VisionSum, agg.DamageShareSum, agg.RoleGamesJSON, agg.LastMatchAt, agg.LastMatchID, agg.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save champion aggregate: %w", err)
+	}
+
+	return nil
+}
+
+// SaveSeasonArchive stores a season snapshot, serializing its stats
+func (r *PlayerRepository) SaveSeasonArchive(ctx context.Context, archive *models.SeasonArchive) error {
+	data, err := json.Marshal(archive.Stats)
+	if err != nil {
+		return fmt.Errorf("failed to encode season stats: %w", err)
+	}
+	archive.StatsJSON = string(data)
+
+	query := `
+		INSERT INTO season_archives (player_id, season, season_start, archived_at, stats)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		archive.PlayerID, archive.Season, archive.SeasonStart, archive.ArchivedAt, archive.StatsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to save season archive: %w", err)
+	}
+
+	return nil
+}
+
+// ListSeasonArchives returns a player's archived seasons, newest first
+func (r *PlayerRepository) ListSeasonArchives(ctx context.Context, playerID string) ([]*models.SeasonArchive, error) {
+	query := `
+		SELECT id, player_id, season, season_start, archived_at, stats
+		FROM season_archives
+		WHERE player_id = $1
+		ORDER BY archived_at DESC
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list season archives: %w", err)
+	}
+	defer rows.Close()
+
+	archives := make([]*models.SeasonArchive, 0)
+	for rows.Next() {
+		archive := &models.SeasonArchive{}
+		if err := rows.Scan(&archive.ID, &archive.PlayerID, &archive.Season,
+			&archive.SeasonStart, &archive.ArchivedAt, &archive.StatsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan season archive: %w", err)
+		}
+
+		archive.Stats = &models.PlayerStats{}
+		if err := json.Unmarshal([]byte(archive.StatsJSON), archive.Stats); err != nil {
+			return nil, fmt.Errorf("failed to decode season stats: %w", err)
+		}
+		archives = append(archives, archive)
+	}
+
+	return archives, rows.Err()
+}
+
+// SaveAnalyticsSnapshot stores a point-in-time copy of a player's aggregates
+func (r *PlayerRepository) SaveAnalyticsSnapshot(ctx context.Context, snapshot *models.AnalyticsSnapshot) error {
+	data, err := json.Marshal(snapshot.Champions)
+	if err != nil {
+		return fmt.Errorf("failed to encode snapshot champions: %w", err)
+	}
+	snapshot.ChampionsJSON = string(data)
+
+	query := `
+		INSERT INTO analytics_snapshots (player_id, taken_at, games, win_rate, average_kda, tier, division, league_points, champions)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	_, err = r.db.ExecContext(ctx, query,
+		snapshot.PlayerID, snapshot.TakenAt, snapshot.Games, snapshot.WinRate, snapshot.AverageKDA,
+		snapshot.Tier, snapshot.Division, snapshot.LeaguePoints, snapshot.ChampionsJSON)
+	if err != nil {
+		return fmt.Errorf("failed to save analytics snapshot: %w", err)
+	}
+
+	return nil
+}
+
+// LatestAnalyticsSnapshot returns the player's most recent snapshot, or nil
+// when none has been recorded
+func (r *PlayerRepository) LatestAnalyticsSnapshot(ctx context.Context, playerID string) (*models.AnalyticsSnapshot, error) {
+	query := snapshotSelect + `
+		WHERE player_id = $1
+		ORDER BY taken_at DESC
+		LIMIT 1
+	`
+	return scanAnalyticsSnapshot(r.db.QueryRowContext(ctx, query, playerID))
+}
+
+// NearestAnalyticsSnapshot returns the player's snapshot taken closest to at,
+// before or after it, or nil when none has been recorded
+func (r *PlayerRepository) NearestAnalyticsSnapshot(ctx context.Context, playerID string, at time.Time) (*models.AnalyticsSnapshot, error) {
+	before, err := scanAnalyticsSnapshot(r.db.QueryRowContext(ctx, snapshotSelect+`
+		WHERE player_id = $1 AND taken_at <= $2
+		ORDER BY taken_at DESC
+		LIMIT 1
+	`, playerID, at))
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := scanAnalyticsSnapshot(r.db.QueryRowContext(ctx, snapshotSelect+`
+		WHERE player_id = $1 AND taken_at > $2
+		ORDER BY taken_at ASC
+		LIMIT 1
+	`, playerID, at))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case before == nil:
+		return after, nil
+	case after == nil:
+		return before, nil
+	case at.Sub(before.TakenAt) <= after.TakenAt.Sub(at):
+		return before, nil
+	default:
+		return after, nil
+	}
+}
+
+const snapshotSelect = `
+	SELECT id, player_id, taken_at, games, win_rate, average_kda, tier, division, league_points, champions
+	FROM analytics_snapshots`
+
+func scanAnalyticsSnapshot(row *sql.Row) (*models.AnalyticsSnapshot, error) {
+	snapshot := &models.AnalyticsSnapshot{}
+	err := row.Scan(&snapshot.ID, &snapshot.PlayerID, &snapshot.TakenAt, &snapshot.Games, &snapshot.WinRate,
+		&snapshot.AverageKDA, &snapshot.Tier, &snapshot.Division, &snapshot.LeaguePoints, &snapshot.ChampionsJSON)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan analytics snapshot: %w", err)
+	}
+
+	if snapshot.ChampionsJSON != "" {
+		if err := json.Unmarshal([]byte(snapshot.ChampionsJSON), &snapshot.Champions); err != nil {
+			return nil, fmt.Errorf("failed to decode snapshot champions: %w", err)
+		}
+	}
+
+	return snapshot, nil
+}
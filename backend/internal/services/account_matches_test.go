@@ -0,0 +1,419 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// newAccountTestDB opens an in-memory database with the synced match tables
+// and the Riot accounts linked to users
+func newAccountTestDB(t *testing.T) *sql.DB {
+	_, db := newAccountTestGormDB(t)
+	return db
+}
+
+// newAccountTestGormDB is newAccountTestDB that also returns the gorm handle
+func newAccountTestGormDB(t *testing.T) (*gorm.DB, *sql.DB) {
+	gormDB, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, gormDB.AutoMigrate(
+		&models.User{},
+		&models.PlayerStats{},
+		&models.ChampionStats{},
+		&models.ChampionAggregate{},
+		&models.SeasonArchive{},
+		&models.AnalyticsSnapshot{},
+	))
+
+	db, err := gormDB.DB()
+	require.NoError(t, err)
+	// Every connection to :memory: opens a new database
+	db.SetMaxOpenConns(1)
+
+	for _, stmt := range []string{
+		`CREATE TABLE riot_accounts (user_id TEXT, puuid TEXT)`,
+		`CREATE TABLE match_bans (match_id TEXT, team_id INTEGER, champion_id INTEGER)`,
+		`CREATE TABLE match_team_objectives (match_id TEXT, team_id INTEGER, first_blood BOOLEAN, first_tower BOOLEAN,
+			first_dragon BOOLEAN, first_herald BOOLEAN, first_baron BOOLEAN, first_inhibitor BOOLEAN)`,
+		`CREATE TABLE matches (id TEXT PRIMARY KEY, match_id TEXT, game_start_timestamp INTEGER, game_duration INTEGER)`,
+		`CREATE TABLE match_participants (match_id TEXT, puuid TEXT, summoner_name TEXT, team_id INTEGER DEFAULT 100, champion_id INTEGER, champion_name TEXT,
+			team_position TEXT, won BOOLEAN, kills INTEGER, deaths INTEGER, assists INTEGER, total_cs INTEGER,
+			cs_per_minute REAL, vision_score INTEGER, damage_share REAL, gold_earned INTEGER,
+			first_blood_kill BOOLEAN DEFAULT 0, first_blood_assist BOOLEAN DEFAULT 0,
+			game_ended_in_early_surrender BOOLEAN DEFAULT 0)`,
+		`CREATE TABLE match_timeline_frames (user_id TEXT, match_id TEXT, minute INTEGER, gold INTEGER, cs INTEGER, xp INTEGER,
+			has_opponent BOOLEAN, opponent_gold INTEGER, opponent_cs INTEGER, opponent_xp INTEGER)`,
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err)
+	}
+	return gormDB, db
+}
+
+// linkAccount links the Riot account puuid to userID
+func linkAccount(t *testing.T, db *sql.DB, userID, puuid string) {
+	_, err := db.Exec(`INSERT INTO riot_accounts (user_id, puuid) VALUES ($1, $2)`, userID, puuid)
+	require.NoError(t, err)
+}
+
+// insertAccountMatch stores a 30 minute game played by puuid daysAgo
+func insertAccountMatch(t *testing.T, db *sql.DB, matchID, puuid, champion string, won bool, daysAgo int) {
+	_, err := db.Exec(`INSERT INTO matches VALUES ($1, $2, $3, $4)`,
+		matchID, matchID, time.Now().AddDate(0, 0, -daysAgo).UnixMilli(), 1800)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO match_participants (match_id, puuid, champion_id, champion_name, team_position, won,
+		kills, deaths, assists, total_cs, cs_per_minute, vision_score, damage_share, gold_earned)
+		VALUES ($1, $2, 1, $3, 'MIDDLE', $4, 5, 2, 7, 240, 8.0, 20, 0.25, 12000)`,
+		matchID, puuid, champion, won)
+	require.NoError(t, err)
+}
+
+// insertAlly adds puuid to the blue side of a stored match
+func insertAlly(t *testing.T, db *sql.DB, matchID, puuid string) {
+	_, err := db.Exec(`INSERT INTO match_participants (match_id, puuid, summoner_name, champion_id, champion_name, won,
+		kills, deaths, assists, total_cs, cs_per_minute, vision_score, damage_share, gold_earned)
+		VALUES ($1, $2, $2, 2, 'Leona', 1, 1, 3, 12, 30, 1.0, 60, 0.1, 8000)`, matchID, puuid)
+	require.NoError(t, err)
+}
+
+func TestAccountFilteredMatchesUseLinkedPUUIDs(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	for i, puuid := range []string{"puuid-main", "puuid-smurf", "puuid-main", "puuid-other"} {
+		insertAccountMatch(t, db, fmt.Sprintf("EUW1_%d", i), puuid, "Ahri", i%2 == 0, i+1)
+	}
+
+	as := NewAnalyticsService(db, nil)
+	ctx := context.Background()
+
+	matches, _, err := as.getAccountFilteredMatches(ctx, "user-1", time.Now().AddDate(0, 0, -30), time.Now(), "", MatchFilter{})
+	require.NoError(t, err)
+	require.Len(t, matches, 3)
+	for i := 1; i < len(matches); i++ {
+		assert.False(t, matches[i].Date.After(matches[i-1].Date), "matches should be newest first")
+	}
+
+	// A PUUID without a linked user is read as the account itself
+	matches, _, err = as.getAccountFilteredMatches(ctx, "puuid-other", time.Now().AddDate(0, 0, -30), time.Now(), "", MatchFilter{})
+	require.NoError(t, err)
+	assert.Len(t, matches, 1)
+
+	analysis, err := as.AnalyzeWinRateByDuration(ctx, "user-1", "30d", MatchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, analysis.Matches)
+
+	timeOfDay, err := as.AnalyzeWinRateByTimeOfDay(ctx, "user-1", "30d", time.UTC, 0, MatchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, timeOfDay.Matches)
+
+	distribution, err := as.AnalyzeKDADistribution(ctx, "user-1", "30d", MatchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, distribution.Matches)
+}
+
+func TestDuoVsSoloUsesLinkedAccounts(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+
+	// The duo partner's three games are split across both accounts
+	for i, puuid := range []string{"puuid-main", "puuid-main", "puuid-smurf", "puuid-smurf"} {
+		matchID := fmt.Sprintf("EUW1_%d", i)
+		insertAccountMatch(t, db, matchID, puuid, "Ahri", true, i+1)
+		if i < 3 {
+			insertAlly(t, db, matchID, "puuid-duo")
+		}
+	}
+
+	as := NewAnalyticsService(db, nil)
+	analysis, err := as.AnalyzeDuoVsSolo(context.Background(), "user-1", "30d", 3, MatchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, analysis.Duo.Games)
+	assert.Equal(t, 1, analysis.Solo.Games)
+	require.Len(t, analysis.Partners, 1)
+	assert.Equal(t, "puuid-duo", analysis.Partners[0].PUUID)
+
+	comparison, err := as.CompareWithGroup(context.Background(), "user-1", "30d", 3, []string{GroupMetricKDA}, MatchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, comparison.Games)
+	require.Len(t, comparison.Members, 1)
+	assert.Equal(t, "puuid-duo", comparison.Members[0].PUUID)
+	assert.Equal(t, 3, comparison.Members[0].SharedGames)
+}
+
+func TestBansUseLinkedAccounts(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	for i, puuid := range []string{"puuid-main", "puuid-smurf"} {
+		matchID := fmt.Sprintf("EUW1_%d", i)
+		insertAccountMatch(t, db, matchID, puuid, "Ahri", i == 0, i+1)
+		_, err := db.Exec(`INSERT INTO match_bans (match_id, team_id, champion_id) VALUES ($1, 100, 157), ($1, 200, 238)`, matchID)
+		require.NoError(t, err)
+	}
+
+	as := NewAnalyticsService(db, nil)
+	analysis, err := as.AnalyzeBans(context.Background(), "user-1", "30d", 1, MatchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, analysis.GamesWithBans)
+	require.Len(t, analysis.Bans, 1, "only the player's own team's bans count")
+	assert.Equal(t, 157, analysis.Bans[0].ChampionID)
+	assert.Equal(t, 2, analysis.Bans[0].Games)
+}
+
+func TestCSBenchmarksUseLinkedAccounts(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	insertAccountMatch(t, db, "EUW1_1", "puuid-main", "Ahri", true, 1)
+	insertAccountMatch(t, db, "EUW1_2", "puuid-smurf", "Ahri", false, 2)
+
+	as := NewAnalyticsService(db, nil)
+	analysis, err := as.AnalyzeCSBenchmarks(context.Background(), "user-1", "30d", MatchFilter{})
+	require.NoError(t, err)
+	require.Len(t, analysis.Roles, 1)
+	assert.Equal(t, "MID", analysis.Roles[0].Role)
+	assert.Equal(t, 2, analysis.Roles[0].Games)
+}
+
+func TestObjectiveImpactUsesLinkedAccounts(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	for i, puuid := range []string{"puuid-main", "puuid-smurf"} {
+		matchID := fmt.Sprintf("EUW1_%d", i)
+		insertAccountMatch(t, db, matchID, puuid, "Ahri", i == 0, i+1)
+		_, err := db.Exec(`INSERT INTO match_team_objectives VALUES ($1, 100, $2, 0, 0, 0, 0, 0), ($1, 200, $3, 0, 0, 0, 0, 0)`,
+			matchID, i == 0, i != 0)
+		require.NoError(t, err)
+	}
+
+	as := NewAnalyticsService(db, nil)
+	analysis, err := as.AnalyzeObjectiveImpact(context.Background(), "user-1", "30d", 1, []string{"first_blood"}, MatchFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, analysis.GamesWithObjectives)
+	require.Len(t, analysis.Objectives, 1)
+	assert.Equal(t, 1, analysis.Objectives[0].Secured.Games, "each account's own team is joined")
+	assert.Equal(t, 1, analysis.Objectives[0].NotSecured.Games)
+}
+
+func TestWarmupUserAnalyticsUsesLinkedAccounts(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	insertAccountMatch(t, db, "EUW1_1", "puuid-main", "Ahri", true, 1)
+	insertAccountMatch(t, db, "EUW1_2", "puuid-smurf", "Zed", false, 2)
+
+	as := NewAnalyticsService(db, nil)
+	ctx := context.Background()
+	require.NoError(t, as.WarmupUserAnalytics(ctx, "user-1"))
+
+	for _, puuid := range []string{"puuid-main", "puuid-smurf"} {
+		aggregates, err := as.playerRepo.ListChampionAggregates(ctx, puuid)
+		require.NoError(t, err)
+		assert.Len(t, aggregates, 1, "aggregates of %s", puuid)
+	}
+	aggregates, err := as.playerRepo.ListChampionAggregates(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Empty(t, aggregates, "nothing is stored under the user ID")
+
+	// Users without linked accounts have nothing to warm
+	require.NoError(t, as.WarmupUserAnalytics(ctx, "user-2"))
+}
+
+func TestRebuildPlayerStatsUsesLinkedAccounts(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	insertAccountMatch(t, db, "EUW1_1", "puuid-main", "Ahri", true, 1)
+	insertAccountMatch(t, db, "EUW1_2", "puuid-main", "Ahri", false, 2)
+	insertAccountMatch(t, db, "EUW1_3", "puuid-smurf", "Zed", true, 3)
+
+	as := NewAnalyticsService(db, nil)
+	processed, err := as.rebuildPlayerStats(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, processed)
+
+	counts := make(map[string]int)
+	rows, err := db.Query(`SELECT player_id, total_matches FROM player_stats WHERE time_range = '30d'`)
+	require.NoError(t, err)
+	for rows.Next() {
+		var playerID string
+		var matches int
+		require.NoError(t, rows.Scan(&playerID, &matches))
+		counts[playerID] = matches
+	}
+	require.NoError(t, rows.Close())
+	assert.Equal(t, map[string]int{"puuid-main": 2, "puuid-smurf": 1}, counts, "nothing is stored under the user ID")
+}
+
+func TestArchiveSeasonUsesLinkedAccounts(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	insertAccountMatch(t, db, "EUW1_1", "puuid-main", "Ahri", true, 1)
+	insertAccountMatch(t, db, "EUW1_2", "puuid-smurf", "Zed", false, 2)
+	insertAccountMatch(t, db, "EUW1_3", "puuid-other", "Zed", false, 3)
+
+	as := NewAnalyticsService(db, nil)
+	ctx := context.Background()
+	_, err := as.rebuildPlayerStats(ctx, "user-1")
+	require.NoError(t, err)
+
+	archive, err := as.ArchiveSeason(ctx, "user-1", "2025")
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", archive.PlayerID)
+	assert.Equal(t, 2, archive.Stats.TotalMatches)
+	assert.Equal(t, 1, archive.Stats.Wins)
+	assert.Equal(t, 2, archive.Stats.TotalChampionsPlayed)
+
+	var remaining int
+	require.NoError(t, db.QueryRow(`SELECT COUNT(*) FROM player_stats`).Scan(&remaining))
+	assert.Zero(t, remaining, "each account's current window is reset")
+}
+
+func TestSnapshotDiffUsesLinkedAccounts(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	insertAccountMatch(t, db, "EUW1_1", "puuid-main", "Ahri", true, 1)
+	insertAccountMatch(t, db, "EUW1_2", "puuid-smurf", "Zed", false, 2)
+
+	as := NewAnalyticsService(db, nil)
+	ctx := context.Background()
+	require.NoError(t, as.playerRepo.SaveAnalyticsSnapshot(ctx, &models.AnalyticsSnapshot{
+		PlayerID: "user-1",
+		TakenAt:  time.Now().AddDate(0, 0, -7),
+	}))
+
+	snapshot, err := as.RecordSnapshot(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, snapshot.Games)
+	assert.Equal(t, []string{"Ahri", "Zed"}, snapshot.Champions)
+
+	diff, err := as.GetSnapshotDiff(ctx, "user-1", time.Now().AddDate(0, 0, -7), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 2, diff.GamesDelta)
+	assert.Equal(t, 50.0, diff.WinRateDelta)
+	assert.Equal(t, []string{"Ahri", "Zed"}, diff.ChampionsAdded)
+}
+
+func TestPoolGapRecommendationsUseLinkedAccounts(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	// Ahri is played on both accounts, her games alternating between them
+	for i, puuid := range []string{"puuid-main", "puuid-smurf", "puuid-main", "puuid-smurf", "puuid-main", "puuid-smurf"} {
+		insertAccountMatch(t, db, fmt.Sprintf("EUW1_%d", i), puuid, "Ahri", true, 10-i)
+	}
+	insertAccountMatch(t, db, "EUW1_9", "puuid-smurf", "Zed", false, 1)
+
+	as := NewAnalyticsService(db, nil)
+	mas := NewMetaAnalyticsService(as)
+	report, err := mas.GetPoolGapRecommendations(context.Background(), "user-1", "ALL", "14.1", "all", "all", 5)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.PoolSize)
+
+	ramps := make(map[string]ChampionRamp)
+	for _, ramp := range report.ChampionRamps {
+		ramps[ramp.Champion] = ramp
+	}
+	require.Contains(t, ramps, "Ahri")
+	assert.Equal(t, 6, ramps["Ahri"].Games, "games on every account count toward the ramp")
+	assert.Equal(t, DefaultComfortMinGames, ramps["Ahri"].GamesToComfort)
+}
+
+func TestRecentFormUsesLinkedAccounts(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	insertAccountMatch(t, db, "EUW1_1", "puuid-smurf", "Zed", false, 1)
+	insertAccountMatch(t, db, "EUW1_2", "puuid-main", "Ahri", true, 2)
+	insertAccountMatch(t, db, "EUW1_3", "puuid-main", "Ahri", true, 3)
+
+	as := NewAnalyticsService(db, nil)
+	form, err := as.GetRecentForm(context.Background(), "user-1")
+	require.NoError(t, err)
+	assert.Equal(t, 3, form.Last10.Games)
+	assert.Equal(t, 2, form.Last10.Wins)
+	assert.Equal(t, "LWW", form.Last10.Results, "results are newest first across accounts")
+}
+
+func TestWardPlacementsUseLinkedAccounts(t *testing.T) {
+	gormDB, db := newAccountTestGormDB(t)
+	require.NoError(t, gormDB.AutoMigrate(&models.WardPlacement{}))
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	insertAccountMatch(t, db, "EUW1_1", "puuid-main", "Ahri", true, 1)
+	insertAccountMatch(t, db, "EUW1_2", "puuid-smurf", "Zed", false, 2)
+	insertAccountMatch(t, db, "EUW1_3", "puuid-other", "Lux", true, 3)
+
+	wards := []models.WardPlacement{
+		{ID: "w1", MatchID: "EUW1_1", PlayerID: "puuid-main", WardType: "YELLOW", Timestamp: 90},
+		{ID: "w2", MatchID: "EUW1_2", PlayerID: "puuid-smurf", WardType: "CONTROL", Timestamp: 300},
+		{ID: "w3", MatchID: "EUW1_3", PlayerID: "puuid-other", WardType: "YELLOW", Timestamp: 120},
+	}
+	require.NoError(t, gormDB.Create(&wards).Error)
+
+	// Resyncing a match doesn't record its wards twice
+	duplicate := models.WardPlacement{ID: "w4", MatchID: "EUW1_1", PlayerID: "puuid-main", WardType: "YELLOW", Timestamp: 90}
+	assert.Error(t, gormDB.Create(&duplicate).Error)
+
+	vas := NewVisionAnalyticsService(NewAnalyticsService(db, nil), NewMapService())
+	ctx := context.Background()
+
+	placed, err := vas.getWardPlacementData(ctx, "user-1", "30d", "ALL")
+	require.NoError(t, err)
+	require.Len(t, placed, 2)
+	assert.Equal(t, "w2", placed[0].ID, "wards are ordered by game start")
+	assert.Equal(t, "w1", placed[1].ID)
+
+	placed, err = vas.getWardPlacementData(ctx, "user-1", "30d", "CONTROL")
+	require.NoError(t, err)
+	require.Len(t, placed, 1)
+	assert.Equal(t, "puuid-smurf", placed[0].PlayerID)
+}
+
+func TestNonCompetitiveGamesUseStoredSignals(t *testing.T) {
+	db := newAccountTestDB(t)
+	linkAccount(t, db, "user-1", "puuid-main")
+	linkAccount(t, db, "user-1", "puuid-smurf")
+	insertAccountMatch(t, db, "EUW1_1", "puuid-main", "Ahri", true, 1)
+	insertAccountMatch(t, db, "EUW1_2", "puuid-main", "Ahri", false, 2)
+	insertAccountMatch(t, db, "EUW1_3", "puuid-smurf", "Zed", false, 3)
+
+	_, err := db.Exec(`UPDATE match_participants SET game_ended_in_early_surrender = 1 WHERE match_id = 'EUW1_2'`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO match_timeline_frames (user_id, match_id, minute, gold, has_opponent, opponent_gold)
+		VALUES ('user-1', 'EUW1_3', 15, 2000, 1, 8000), ('user-1', 'EUW1_1', 10, 2000, 1, 8000)`)
+	require.NoError(t, err)
+
+	as := NewAnalyticsService(db, nil)
+	matches, _, err := as.getAccountFilteredMatches(context.Background(), "user-1", time.Now().AddDate(0, 0, -30), time.Now(), "", MatchFilter{})
+	require.NoError(t, err)
+	require.Len(t, matches, 3)
+	byID := make(map[string]models.MatchData)
+	for _, match := range matches {
+		byID[match.MatchID] = match
+	}
+	assert.True(t, byID["EUW1_2"].GameEndedEarly)
+	assert.Equal(t, -6000, byID["EUW1_3"].GoldDiffAt15)
+	assert.Zero(t, byID["EUW1_1"].GoldDiffAt15, "only the 15 minute frame counts")
+
+	kept, excluded, err := as.getAccountFilteredMatches(context.Background(), "user-1", time.Now().AddDate(0, 0, -30), time.Now(), "", MatchFilter{ExcludeNonCompetitive: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, excluded)
+	require.Len(t, kept, 1)
+	assert.Equal(t, "EUW1_1", kept[0].MatchID)
+}
@@ -0,0 +1,48 @@
+package services
+
+// Directions of a metric, telling clients which way is an improvement
+const (
+	MetricHigherIsBetter = "higher_is_better"
+	MetricLowerIsBetter  = "lower_is_better"
+	// MetricNeutral marks metrics that describe a playstyle or depend on the
+	// role, such as a support's small damage share, so no value is better
+	MetricNeutral = "neutral"
+)
+
+// MetricDefinition describes one analytics metric exposed by the API
+type MetricDefinition struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name"`
+	Unit        string `json:"unit"`     // "ratio", "percent", "per_minute", "count", "gold", "seconds"
+	Category    string `json:"category"` // "combat", "farming", "vision", "damage", "economy", "early_game", "results"
+	Description string `json:"description"`
+	Direction   string `json:"direction"` // MetricHigherIsBetter, MetricLowerIsBetter or MetricNeutral
+}
+
+// metricCatalog lists every metric the analytics endpoints report. Add new
+// metrics here so they show up in GET /analytics/metrics.
+var metricCatalog = []MetricDefinition{
+	{ID: "win_rate", DisplayName: "Win Rate", Unit: "percent", Category: "results", Description: "Share of games won", Direction: MetricHigherIsBetter},
+	{ID: "kda", DisplayName: "KDA", Unit: "ratio", Category: "combat", Description: "(Kills + Assists) / Deaths, with deaths floored at 1", Direction: MetricHigherIsBetter},
+	{ID: "kill_participation", DisplayName: "Kill Participation", Unit: "percent", Category: "combat", Description: "Share of team kills the player scored or assisted", Direction: MetricHigherIsBetter},
+	{ID: "deaths", DisplayName: "Deaths", Unit: "count", Category: "combat", Description: "Average deaths per game", Direction: MetricLowerIsBetter},
+	{ID: "cs_per_minute", DisplayName: "CS per Minute", Unit: "per_minute", Category: "farming", Description: "Minions and neutral monsters killed per minute", Direction: MetricHigherIsBetter},
+	{ID: "cs_diff_at_15", DisplayName: "CS Diff @15", Unit: "count", Category: "early_game", Description: "CS difference against the lane opponent at 15 minutes", Direction: MetricHigherIsBetter},
+	{ID: "gold_diff_at_15", DisplayName: "Gold Diff @15", Unit: "gold", Category: "early_game", Description: "Gold difference against the lane opponent at 15 minutes", Direction: MetricHigherIsBetter},
+	{ID: "first_blood_participation", DisplayName: "First Blood Participation", Unit: "percent", Category: "early_game", Description: "Share of games with a first blood kill or assist", Direction: MetricHigherIsBetter},
+	{ID: "vision_score", DisplayName: "Vision Score", Unit: "count", Category: "vision", Description: "Riot vision score per game", Direction: MetricHigherIsBetter},
+	{ID: "control_wards_placed", DisplayName: "Control Wards", Unit: "count", Category: "vision", Description: "Control wards placed per game", Direction: MetricHigherIsBetter},
+	{ID: "damage_share", DisplayName: "Damage Share", Unit: "percent", Category: "damage", Description: "Share of the team's damage to champions; expected to be low for supports", Direction: MetricNeutral},
+	{ID: "damage_per_minute", DisplayName: "Damage per Minute", Unit: "per_minute", Category: "damage", Description: "Damage dealt to champions per minute", Direction: MetricHigherIsBetter},
+	{ID: "physical_damage_share", DisplayName: "Physical Damage", Unit: "percent", Category: "damage", Description: "Share of damage to champions dealt as physical damage", Direction: MetricNeutral},
+	{ID: "magic_damage_share", DisplayName: "Magic Damage", Unit: "percent", Category: "damage", Description: "Share of damage to champions dealt as magic damage", Direction: MetricNeutral},
+	{ID: "gold_per_minute", DisplayName: "Gold per Minute", Unit: "per_minute", Category: "economy", Description: "Gold earned per minute", Direction: MetricHigherIsBetter},
+	{ID: "game_duration", DisplayName: "Game Duration", Unit: "seconds", Category: "results", Description: "Average game length", Direction: MetricNeutral},
+}
+
+// GetMetricCatalog returns the definitions of all supported analytics metrics
+func (as *AnalyticsService) GetMetricCatalog() []MetricDefinition {
+	catalog := make([]MetricDefinition, len(metricCatalog))
+	copy(catalog, metricCatalog)
+	return catalog
+}
@@ -3,11 +3,15 @@ package services
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/herald-lol/herald/backend/internal/logger"
 	"github.com/herald-lol/herald/backend/internal/models"
 	"github.com/herald-lol/herald/backend/internal/repository"
 	"github.com/redis/go-redis/v9"
@@ -19,13 +23,53 @@ type AnalyticsService struct {
 	matchRepo    *repository.MatchRepository
 	playerRepo   *repository.PlayerRepository
 	redisService *RedisService
+
+	// Stats rebuild jobs keyed by player ID
+	rebuildJobs map[string]*StatsRebuildJob
+	rebuildMu   sync.RWMutex
+
+	// Players with a post-sync precompute in flight
+	precomputing map[string]bool
+	precomputeMu sync.Mutex
+
+	// Matches loaded per page when refreshing aggregates
+	batchSize int
+
+	// Serializes incremental champion aggregate updates per player so a
+	// match is never folded in twice, without one player's update waiting on
+	// another's
+	aggregateLocks playerLocks
+
+	// Shared games needed before a teammate counts as a duo partner
+	duoMinSharedGames int
+
+	// Default width of the hour-of-day buckets in time-of-day analysis
+	timeOfDayBucketHours int
+
+	// Default percentage of games the effective champion pool covers
+	championPoolThreshold float64
+
+	// Team bans of a champion needed before its ban win rate is reported
+	banMinGames int
+
+	// Games needed on each side of a first objective before its impact counts
+	objectiveMinGames int
+
+	// CS per minute benchmark by role, nil for DefaultCSBenchmarks
+	csBenchmarks map[string]float64
 }
 
+// DefaultAnalyticsBatchSize is the number of matches processed per page when
+// rebuilding aggregates, keeping memory bounded for large histories
+const DefaultAnalyticsBatchSize = 500
+
 // KDAAnalysis represents KDA statistical analysis
 type KDAAnalysis struct {
-	PlayerID  string `json:"player_id"`
-	Champion  string `json:"champion,omitempty"`
-	TimeRange string `json:"time_range"`
+	PlayerID      string      `json:"player_id"`
+	Champion      string      `json:"champion,omitempty"`
+	TimeRange     string      `json:"time_range"`
+	Filter        MatchFilter `json:"filter"`
+	ExcludedGames int         `json:"excluded_games"` // non-competitive games left out
 
 	// Core KDA Metrics
 	TotalKills   int `json:"total_kills"`
@@ -106,10 +150,12 @@ type MatchKDAData struct {
 
 // CSAnalysis represents Creep Score analysis
 type CSAnalysis struct {
-	PlayerID  string `json:"player_id"`
-	Champion  string `json:"champion,omitempty"`
-	Position  string `json:"position,omitempty"`
-	TimeRange string `json:"time_range"`
+	PlayerID      string      `json:"player_id"`
+	Champion      string      `json:"champion,omitempty"`
+	Position      string      `json:"position,omitempty"`
+	TimeRange     string      `json:"time_range"`
+	Filter        MatchFilter `json:"filter"`
+	ExcludedGames int         `json:"excluded_games"` // non-competitive games left out
 
 	// Core CS Metrics
 	TotalCS         int     `json:"total_cs"`
@@ -233,6 +279,16 @@ func NewAnalyticsService(db *sql.DB, redisClient interface{}) *AnalyticsService
 		matchRepo:    matchRepo,
 		playerRepo:   playerRepo,
 		redisService: redisService,
+		rebuildJobs:  make(map[string]*StatsRebuildJob),
+		precomputing: make(map[string]bool),
+		batchSize:    DefaultAnalyticsBatchSize,
+
+		duoMinSharedGames:    DefaultDuoMinSharedGames,
+		timeOfDayBucketHours: DefaultTimeOfDayBucketHours,
+
+		championPoolThreshold: DefaultChampionPoolThreshold,
+		banMinGames:           DefaultBanMinGames,
+		objectiveMinGames:     DefaultObjectiveMinGames,
 	}
 }
 
@@ -243,32 +299,57 @@ func NewAnalyticsServiceFull(db *sql.DB, matchRepo *repository.MatchRepository,
 		matchRepo:    matchRepo,
 		playerRepo:   playerRepo,
 		redisService: redisService,
+		rebuildJobs:  make(map[string]*StatsRebuildJob),
+		precomputing: make(map[string]bool),
+		batchSize:    DefaultAnalyticsBatchSize,
+
+		duoMinSharedGames:    DefaultDuoMinSharedGames,
+		timeOfDayBucketHours: DefaultTimeOfDayBucketHours,
+
+		championPoolThreshold: DefaultChampionPoolThreshold,
+		banMinGames:           DefaultBanMinGames,
+		objectiveMinGames:     DefaultObjectiveMinGames,
+	}
+}
+
+// SetBatchSize sets how many matches are loaded per page when refreshing
+// aggregates; values below 1 restore the default
+func (as *AnalyticsService) SetBatchSize(size int) {
+	if size < 1 {
+		size = DefaultAnalyticsBatchSize
 	}
+	as.batchSize = size
 }
 
 // AnalyzeKDA performs comprehensive KDA analysis
-func (as *AnalyticsService) AnalyzeKDA(ctx context.Context, playerID string, timeRange string, champion string) (*KDAAnalysis, error) {
+func (as *AnalyticsService) AnalyzeKDA(ctx context.Context, playerID string, timeRange string, champion string, filter MatchFilter) (*KDAAnalysis, error) {
+	filter = filter.normalize()
+
 	// Define time range
 	startDate, endDate := as.parseTimeRange(timeRange)
 
 	// Get match data
-	matches, err := as.getPlayerMatches(ctx, playerID, startDate, endDate, champion)
+	matches, excluded, err := as.getFilteredMatches(ctx, playerID, startDate, endDate, champion, filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get player matches: %w", err)
+		return nil, err
 	}
 
 	if len(matches) == 0 {
 		return &KDAAnalysis{
-			PlayerID:  playerID,
-			Champion:  champion,
-			TimeRange: timeRange,
+			PlayerID:      playerID,
+			Champion:      champion,
+			TimeRange:     timeRange,
+			Filter:        filter,
+			ExcludedGames: excluded,
 		}, nil
 	}
 
 	analysis := &KDAAnalysis{
-		PlayerID:  playerID,
-		Champion:  champion,
-		TimeRange: timeRange,
+		PlayerID:      playerID,
+		Champion:      champion,
+		TimeRange:     timeRange,
+		Filter:        filter,
+		ExcludedGames: excluded,
 	}
 
 	// Calculate basic statistics
@@ -284,7 +365,7 @@ func (as *AnalyticsService) AnalyzeKDA(ctx context.Context, playerID string, tim
 	err = as.performKDAComparison(ctx, analysis, playerID)
 	if err != nil {
 		// Log error but don't fail the entire analysis
-		fmt.Printf("Warning: failed to perform KDA comparison: %v", err)
+		logger.Warnf("Failed to perform KDA comparison: %v", err)
 	}
 
 	// Contextual analysis
@@ -306,28 +387,33 @@ func (as *AnalyticsService) AnalyzeKDA(ctx context.Context, playerID string, tim
 }
 
 // AnalyzeCS performs comprehensive Creep Score analysis
-func (as *AnalyticsService) AnalyzeCS(ctx context.Context, playerID string, timeRange string, position string, champion string) (*CSAnalysis, error) {
+func (as *AnalyticsService) AnalyzeCS(ctx context.Context, playerID string, timeRange string, position string, champion string, filter MatchFilter) (*CSAnalysis, error) {
+	filter = filter.normalize()
 	startDate, endDate := as.parseTimeRange(timeRange)
 
-	matches, err := as.getPlayerMatches(ctx, playerID, startDate, endDate, champion)
+	matches, excluded, err := as.getFilteredMatches(ctx, playerID, startDate, endDate, champion, filter)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get player matches: %w", err)
+		return nil, err
 	}
 
 	if len(matches) == 0 {
 		return &CSAnalysis{
-			PlayerID:  playerID,
-			Champion:  champion,
-			Position:  position,
-			TimeRange: timeRange,
+			PlayerID:      playerID,
+			Champion:      champion,
+			Position:      position,
+			TimeRange:     timeRange,
+			Filter:        filter,
+			ExcludedGames: excluded,
 		}, nil
 	}
 
 	analysis := &CSAnalysis{
-		PlayerID:  playerID,
-		Champion:  champion,
-		Position:  position,
-		TimeRange: timeRange,
+		PlayerID:      playerID,
+		Champion:      champion,
+		Position:      position,
+		TimeRange:     timeRange,
+		Filter:        filter,
+		ExcludedGames: excluded,
 	}
 
 	// Calculate CS basics
@@ -336,7 +422,7 @@ func (as *AnalyticsService) AnalyzeCS(ctx context.Context, playerID string, time
 	// Get benchmarks
 	err = as.getCSBenchmarks(ctx, analysis, position)
 	if err != nil {
-		fmt.Printf("Warning: failed to get CS benchmarks: %v", err)
+		logger.Warnf("Failed to get CS benchmarks: %v", err)
 	}
 
 	// Analyze CS efficiency
@@ -403,6 +489,360 @@ func (as *AnalyticsService) ComparePerformance(ctx context.Context, playerID str
 
 // Helper functions for KDA analysis
 
+// EarlyGameAnalysis summarizes a player's impact in the first 15 minutes
+type EarlyGameAnalysis struct {
+	PlayerID  string `json:"player_id"`
+	Champion  string `json:"champion,omitempty"`
+	TimeRange string `json:"time_range"`
+	Matches   int    `json:"matches"`
+
+	// First blood
+	FirstBloodParticipation  float64 `json:"first_blood_participation"` // % of games with a first blood kill or assist
+	FirstBloodKillRate       float64 `json:"first_blood_kill_rate"`
+	WinRateWithFirstBlood    float64 `json:"win_rate_with_first_blood"`
+	WinRateWithoutFirstBlood float64 `json:"win_rate_without_first_blood"`
+
+	// Lane differentials against the direct opponent, from stored timelines
+	TimelineMatches     int     `json:"timeline_matches"` // games with a timeline frame at 10 minutes
+	AverageGoldDiffAt10 float64 `json:"average_gold_diff_at_10"`
+	AverageGoldDiffAt15 float64 `json:"average_gold_diff_at_15"`
+	AverageCSDiffAt10   float64 `json:"average_cs_diff_at_10"`
+	AverageCSDiffAt15   float64 `json:"average_cs_diff_at_15"`
+	AverageCSAt10       float64 `json:"average_cs_at_10"`
+	AverageCSAt15       float64 `json:"average_cs_at_15"`
+	AverageXPDiffAt15   float64 `json:"average_xp_diff_at_15"`
+
+	// Share of games ahead in gold at 15 and how often those were won
+	AheadAt15Rate    float64 `json:"ahead_at_15_rate"`
+	WinRateWhenAhead float64 `json:"win_rate_when_ahead"`
+}
+
+// AnalyzeEarlyGame computes first-blood participation across the user's
+// games and 10-15 minute lane differentials from the timeline frames stored
+// for them. Differentials only count games with a lane opponent in the
+// timeline; games synced without SYNC_FETCH_TIMELINES add to Matches only.
+func (as *AnalyticsService) AnalyzeEarlyGame(ctx context.Context, playerID string, timeRange string, champion string) (*EarlyGameAnalysis, error) {
+	startDate, endDate := as.parseTimeRange(timeRange)
+
+	puuids, err := as.accountPUUIDs(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]models.MatchData, 0)
+	for _, puuid := range puuids {
+		accountMatches, _, err := as.getFilteredMatches(ctx, puuid, startDate, endDate, champion, MatchFilter{})
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, accountMatches...)
+	}
+
+	analysis := &EarlyGameAnalysis{
+		PlayerID:  playerID,
+		Champion:  champion,
+		TimeRange: timeRange,
+		Matches:   len(matches),
+	}
+
+	if len(matches) == 0 {
+		return analysis, nil
+	}
+
+	frames, err := as.matchRepo.GetTimelineFrames(ctx, playerID, 10, 15)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get timeline frames: %w", err)
+	}
+
+	var fbGames, fbKills, fbWins, noFBGames, noFBWins, aheadGames, aheadWins int
+	var at10Games, at15Games, diff10Games, diff15Games int
+	var goldDiff10, goldDiff15, csDiff10, csDiff15, cs10, cs15, xpDiff15 float64
+
+	for _, match := range matches {
+		if match.FirstBloodKill || match.FirstBloodAssist {
+			fbGames++
+			if match.Win {
+				fbWins++
+			}
+		} else {
+			noFBGames++
+			if match.Win {
+				noFBWins++
+			}
+		}
+		if match.FirstBloodKill {
+			fbKills++
+		}
+
+		if frame, ok := frames[match.MatchID][10]; ok {
+			at10Games++
+			cs10 += float64(frame.CS)
+			if frame.HasOpponent {
+				diff10Games++
+				goldDiff10 += float64(frame.Gold - frame.OpponentGold)
+				csDiff10 += float64(frame.CS - frame.OpponentCS)
+			}
+		}
+		if frame, ok := frames[match.MatchID][15]; ok {
+			at15Games++
+			cs15 += float64(frame.CS)
+			if frame.HasOpponent {
+				diff15Games++
+				goldDiff15 += float64(frame.Gold - frame.OpponentGold)
+				csDiff15 += float64(frame.CS - frame.OpponentCS)
+				xpDiff15 += float64(frame.XP - frame.OpponentXP)
+				if frame.Gold > frame.OpponentGold {
+					aheadGames++
+					if match.Win {
+						aheadWins++
+					}
+				}
+			}
+		}
+	}
+
+	count := float64(len(matches))
+	analysis.FirstBloodParticipation = float64(fbGames) / count * 100
+	analysis.FirstBloodKillRate = float64(fbKills) / count * 100
+	if fbGames > 0 {
+		analysis.WinRateWithFirstBlood = float64(fbWins) / float64(fbGames) * 100
+	}
+	if noFBGames > 0 {
+		analysis.WinRateWithoutFirstBlood = float64(noFBWins) / float64(noFBGames) * 100
+	}
+
+	analysis.TimelineMatches = at10Games
+	if at10Games > 0 {
+		analysis.AverageCSAt10 = cs10 / float64(at10Games)
+	}
+	if at15Games > 0 {
+		analysis.AverageCSAt15 = cs15 / float64(at15Games)
+	}
+	if diff10Games > 0 {
+		analysis.AverageGoldDiffAt10 = goldDiff10 / float64(diff10Games)
+		analysis.AverageCSDiffAt10 = csDiff10 / float64(diff10Games)
+	}
+	if diff15Games > 0 {
+		analysis.AverageGoldDiffAt15 = goldDiff15 / float64(diff15Games)
+		analysis.AverageCSDiffAt15 = csDiff15 / float64(diff15Games)
+		analysis.AverageXPDiffAt15 = xpDiff15 / float64(diff15Games)
+		analysis.AheadAt15Rate = float64(aheadGames) / float64(diff15Games) * 100
+	}
+	if aheadGames > 0 {
+		analysis.WinRateWhenAhead = float64(aheadWins) / float64(aheadGames) * 100
+	}
+
+	return analysis, nil
+}
+
+// recentFormLookback bounds how far back GetRecentForm searches for games
+const recentFormLookback = 365 * 24 * time.Hour
+
+// FormWindow summarizes the last N games regardless of when they were played
+type FormWindow struct {
+	Games      int     `json:"games"` // may be fewer than requested if not enough games exist
+	Wins       int     `json:"wins"`
+	Losses     int     `json:"losses"`
+	WinRate    float64 `json:"win_rate"`
+	AverageKDA float64 `json:"average_kda"`
+	Results    string  `json:"results"` // most recent first, e.g. "WWLWL"
+}
+
+// RecentForm holds rolling game-count windows (last 10, last 20)
+type RecentForm struct {
+	PlayerID string      `json:"player_id"`
+	Last10   *FormWindow `json:"last_10"`
+	Last20   *FormWindow `json:"last_20"`
+}
+
+// GetRecentForm computes win rate and KDA over the most recent games across
+// the user's linked accounts. Unlike calendar windows, these stay meaningful
+// after a break from playing.
+func (as *AnalyticsService) GetRecentForm(ctx context.Context, playerID string) (*RecentForm, error) {
+	endDate := time.Now()
+	startDate := endDate.Add(-recentFormLookback)
+
+	matches, err := as.loadAccountMatches(ctx, playerID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player matches: %w", err)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Date.After(matches[j].Date)
+	})
+
+	return &RecentForm{
+		PlayerID: playerID,
+		Last10:   as.calculateFormWindow(matches, 10),
+		Last20:   as.calculateFormWindow(matches, 20),
+	}, nil
+}
+
+// calculateFormWindow summarizes the first n matches, which must be sorted newest first
+func (as *AnalyticsService) calculateFormWindow(matches []models.MatchData, n int) *FormWindow {
+	if len(matches) > n {
+		matches = matches[:n]
+	}
+
+	window := &FormWindow{Games: len(matches)}
+	if len(matches) == 0 {
+		return window
+	}
+
+	kdaValues := make([]float64, 0, len(matches))
+	results := make([]byte, 0, len(matches))
+	for _, match := range matches {
+		if match.Win {
+			window.Wins++
+			results = append(results, 'W')
+		} else {
+			window.Losses++
+			results = append(results, 'L')
+		}
+		kdaValues = append(kdaValues, as.calculateKDA(match.Kills, match.Deaths, match.Assists))
+	}
+
+	window.WinRate = float64(window.Wins) / float64(len(matches)) * 100
+	window.AverageKDA = as.calculateMean(kdaValues)
+	window.Results = string(results)
+
+	return window
+}
+
+// PeriodStats summarizes a player's games within a calendar window
+type PeriodStats struct {
+	PlayerID      string      `json:"player_id"`
+	Days          int         `json:"days"`
+	Filter        MatchFilter `json:"filter"`
+	StartDate     time.Time   `json:"start_date"`
+	EndDate       time.Time   `json:"end_date"`
+	Games         int         `json:"games"`
+	ExcludedGames int         `json:"excluded_games"` // non-competitive games left out
+	Wins          int         `json:"wins"`
+	Losses        int         `json:"losses"`
+	WinRate       float64     `json:"win_rate"`
+	AverageKDA    float64     `json:"average_kda"`
+	CSPerMinute   float64     `json:"cs_per_minute"`
+	VisionScore   float64     `json:"vision_score"`
+
+	// Set when the previous equivalent period was requested
+	Previous *PeriodStats `json:"previous,omitempty"`
+	Delta    *PeriodDelta `json:"delta,omitempty"`
+}
+
+// PeriodDelta is the change from the previous period to the current one
+type PeriodDelta struct {
+	Games            int     `json:"games"`
+	WinRate          float64 `json:"win_rate"` // percentage points
+	AverageKDA       float64 `json:"average_kda"`
+	KDAChangePercent float64 `json:"kda_change_percent"`
+	CSPerMinute      float64 `json:"cs_per_minute"`
+	VisionScore      float64 `json:"vision_score"`
+}
+
+// GetPeriodStats summarizes the last days of games. With compare set, the
+// equivalent period immediately before it is also computed along with deltas,
+// e.g. this week vs last week.
+func (as *AnalyticsService) GetPeriodStats(ctx context.Context, playerID string, days int, compare bool, filter MatchFilter) (*PeriodStats, error) {
+	filter = filter.normalize()
+	key := periodStatsCacheKey(playerID, days, compare, filter)
+	if as.redisService != nil {
+		var cached PeriodStats
+		if err := as.redisService.Get(ctx, key, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	stats, err := as.calculatePeriodComparison(ctx, playerID, days, compare, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if as.redisService != nil {
+		as.redisService.Set(ctx, key, stats, 30*time.Minute)
+	}
+
+	return stats, nil
+}
+
+func periodStatsCacheKey(playerID string, days int, compare bool, filter MatchFilter) string {
+	return fmt.Sprintf("period_stats:%s:%d:%t:%s", playerID, days, compare, filter.cacheKey())
+}
+
+func (as *AnalyticsService) calculatePeriodComparison(ctx context.Context, playerID string, days int, compare bool, filter MatchFilter) (*PeriodStats, error) {
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -days)
+
+	current, err := as.calculatePeriodStats(ctx, playerID, days, filter, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if !compare {
+		return current, nil
+	}
+
+	previous, err := as.calculatePeriodStats(ctx, playerID, days, filter, startDate.AddDate(0, 0, -days), startDate)
+	if err != nil {
+		return nil, err
+	}
+
+	current.Previous = previous
+	current.Delta = &PeriodDelta{
+		Games:       current.Games - previous.Games,
+		WinRate:     current.WinRate - previous.WinRate,
+		AverageKDA:  current.AverageKDA - previous.AverageKDA,
+		CSPerMinute: current.CSPerMinute - previous.CSPerMinute,
+		VisionScore: current.VisionScore - previous.VisionScore,
+	}
+	if previous.AverageKDA > 0 {
+		current.Delta.KDAChangePercent = (current.AverageKDA - previous.AverageKDA) / previous.AverageKDA * 100
+	}
+
+	return current, nil
+}
+
+func (as *AnalyticsService) calculatePeriodStats(ctx context.Context, playerID string, days int, filter MatchFilter, startDate, endDate time.Time) (*PeriodStats, error) {
+	matches, excluded, err := as.getFilteredMatches(ctx, playerID, startDate, endDate, "", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &PeriodStats{
+		PlayerID:      playerID,
+		Days:          days,
+		Filter:        filter,
+		StartDate:     startDate,
+		EndDate:       endDate,
+		Games:         len(matches),
+		ExcludedGames: excluded,
+	}
+	if len(matches) == 0 {
+		return stats, nil
+	}
+
+	kdaValues := make([]float64, 0, len(matches))
+	var totalCSPerMin, totalVision float64
+	for _, match := range matches {
+		if match.Win {
+			stats.Wins++
+		} else {
+			stats.Losses++
+		}
+		kdaValues = append(kdaValues, as.calculateKDA(match.Kills, match.Deaths, match.Assists))
+		totalCSPerMin += match.CSPerMinute
+		totalVision += float64(match.VisionScore)
+	}
+
+	games := float64(len(matches))
+	stats.WinRate = float64(stats.Wins) / games * 100
+	stats.AverageKDA = as.calculateMean(kdaValues)
+	stats.CSPerMinute = totalCSPerMin / games
+	stats.VisionScore = totalVision / games
+
+	return stats, nil
+}
+
 func (as *AnalyticsService) calculateKDABasics(analysis *KDAAnalysis, matches []models.MatchData) {
 	if len(matches) == 0 {
 		return
@@ -630,10 +1070,7 @@ func (as *AnalyticsService) generateCSRecommendations(analysis *CSAnalysis) {
 // Mathematical utility functions
 
 func (as *AnalyticsService) calculateKDA(kills, deaths, assists int) float64 {
-	if deaths == 0 {
-		return float64(kills + assists)
-	}
-	return float64(kills+assists) / float64(deaths)
+	return kdaRatio(kills, deaths, assists)
 }
 
 func (as *AnalyticsService) calculateMean(values []float64) float64 {
@@ -710,45 +1147,7 @@ func (as *AnalyticsService) calculateStandardDeviation(values []float64) float64
 }
 
 func (as *AnalyticsService) calculateLinearRegression(values []float64) (slope, confidence float64) {
-	if len(values) < 2 {
-		return 0, 0
-	}
-
-	n := float64(len(values))
-	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
-
-	for i, y := range values {
-		x := float64(i)
-		sumX += x
-		sumY += y
-		sumXY += x * y
-		sumXX += x * x
-	}
-
-	// Calculate slope using least squares
-	denominator := n*sumXX - sumX*sumX
-	if denominator == 0 {
-		return 0, 0
-	}
-
-	slope = (n*sumXY - sumX*sumY) / denominator
-
-	// Calculate R-squared for confidence
-	meanY := sumY / n
-	ssTotal, ssRes := 0.0, 0.0
-
-	for i, y := range values {
-		predicted := slope*float64(i) + (sumY-slope*sumX)/n
-		ssTotal += (y - meanY) * (y - meanY)
-		ssRes += (y - predicted) * (y - predicted)
-	}
-
-	confidence = 1 - (ssRes / ssTotal)
-	if confidence < 0 {
-		confidence = 0
-	}
-
-	return slope, confidence
+	return LinearTrend(values)
 }
 
 // Cache operations
@@ -757,7 +1156,7 @@ func (as *AnalyticsService) cacheKDAAnalysis(ctx context.Context, analysis *KDAA
 		return
 	}
 
-	key := fmt.Sprintf("kda_analysis:%s:%s:%s", analysis.PlayerID, analysis.TimeRange, analysis.Champion)
+	key := kdaAnalysisCacheKey(analysis.PlayerID, analysis.TimeRange, analysis.Champion, analysis.Filter)
 	as.redisService.SetJSON(ctx, key, analysis, 30*time.Minute)
 }
 
@@ -766,10 +1165,45 @@ func (as *AnalyticsService) cacheCSAnalysis(ctx context.Context, analysis *CSAna
 		return
 	}
 
-	key := fmt.Sprintf("cs_analysis:%s:%s:%s", analysis.PlayerID, analysis.TimeRange, analysis.Champion)
+	key := csAnalysisCacheKey(analysis.PlayerID, analysis.TimeRange, analysis.Champion, analysis.Filter)
 	as.redisService.SetJSON(ctx, key, analysis, 30*time.Minute)
 }
 
+func kdaAnalysisCacheKey(playerID, timeRange, champion string, filter MatchFilter) string {
+	return fmt.Sprintf("%s%s:%s:%s", kdaAnalysisCachePrefix(playerID), timeRange, champion, filter.cacheKey())
+}
+
+func kdaAnalysisCachePrefix(playerID string) string {
+	return fmt.Sprintf("kda_analysis:%s:", playerID)
+}
+
+func csAnalysisCacheKey(playerID, timeRange, champion string, filter MatchFilter) string {
+	return fmt.Sprintf("%s%s:%s:%s", csAnalysisCachePrefix(playerID), timeRange, champion, filter.cacheKey())
+}
+
+func csAnalysisCachePrefix(playerID string) string {
+	return fmt.Sprintf("cs_analysis:%s:", playerID)
+}
+
+// invalidatePlayerCaches drops every cached analysis of the PUUID, in any
+// time range, champion, or filter, so it's recomputed from the stored stats
+func (as *AnalyticsService) invalidatePlayerCaches(ctx context.Context, puuid string) {
+	if as.redisService == nil {
+		return
+	}
+	prefixes := []string{
+		kdaAnalysisCachePrefix(puuid),
+		csAnalysisCachePrefix(puuid),
+		fmt.Sprintf("period_stats:%s:", puuid),
+		fmt.Sprintf("champion_stats:%s:", puuid),
+	}
+	for _, prefix := range prefixes {
+		if err := as.redisService.DeletePrefix(ctx, prefix); err != nil {
+			logger.Warnf("Failed to clear cached analyses %s*: %v", prefix, err)
+		}
+	}
+}
+
 // Additional helper functions would be implemented here...
 
 func (as *AnalyticsService) parseTimeRange(timeRange string) (time.Time, time.Time) {
@@ -813,3 +1247,1004 @@ func (as *AnalyticsService) calculateTheoreticalMaxCS(gameDuration int, position
 		return minutes * 10.0
 	}
 }
+
+// StatsRebuildJob tracks an asynchronous rebuild of a player's derived stats
+type StatsRebuildJob struct {
+	PlayerID    string     `json:"player_id"`
+	Status      string     `json:"status"` // "running", "completed", "failed"
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Matches     int        `json:"matches_processed"`
+	Error       string     `json:"error,omitempty"`
+}
+
+// ErrRebuildInProgress is returned when a rebuild is already running for the player
+var ErrRebuildInProgress = fmt.Errorf("stats rebuild already in progress")
+
+// rebuildTimeRanges are the aggregates recomputed by a rebuild
+var rebuildTimeRanges = []string{"7d", "30d", "90d"}
+
+// StartStatsRebuild clears and recomputes all derived stats for each Riot
+// account linked to the user playerID from stored matches. The work runs in
+// the background; poll GetStatsRebuildStatus.
+func (as *AnalyticsService) StartStatsRebuild(playerID string) (*StatsRebuildJob, error) {
+	as.rebuildMu.Lock()
+	if existing, ok := as.rebuildJobs[playerID]; ok && existing.Status == "running" {
+		as.rebuildMu.Unlock()
+		return nil, ErrRebuildInProgress
+	}
+
+	job := &StatsRebuildJob{
+		PlayerID:  playerID,
+		Status:    "running",
+		StartedAt: time.Now(),
+	}
+	as.rebuildJobs[playerID] = job
+	snapshot := *job
+	as.rebuildMu.Unlock()
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+		defer cancel()
+
+		matches, err := as.rebuildPlayerStats(ctx, playerID)
+
+		as.rebuildMu.Lock()
+		defer as.rebuildMu.Unlock()
+
+		completedAt := time.Now()
+		job.CompletedAt = &completedAt
+		job.Matches = matches
+		if err != nil {
+			job.Status = "failed"
+			job.Error = err.Error()
+			return
+		}
+		job.Status = "completed"
+	}()
+
+	return &snapshot, nil
+}
+
+// ErrSeasonAlreadyArchived is returned when a season name is reused
+var ErrSeasonAlreadyArchived = fmt.Errorf("season already archived")
+
+// SeasonHistory lists a player's archived seasons and when the current one began
+type SeasonHistory struct {
+	PlayerID           string                  `json:"player_id"`
+	CurrentSeasonStart time.Time               `json:"current_season_start"`
+	Archives           []*models.SeasonArchive `json:"archives"`
+}
+
+// ArchiveSeason snapshots the aggregate stats of the user's linked accounts
+// since the previous archive (or all-time for the first one) under the given
+// season name, then resets their derived stats so the next season starts
+// fresh. Raw matches are preserved.
+func (as *AnalyticsService) ArchiveSeason(ctx context.Context, playerID, season string) (*models.SeasonArchive, error) {
+	archives, err := as.playerRepo.ListSeasonArchives(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, archive := range archives {
+		if archive.Season == season {
+			return nil, ErrSeasonAlreadyArchived
+		}
+	}
+
+	seasonStart := championStatsEpoch
+	if len(archives) > 0 {
+		seasonStart = archives[0].ArchivedAt
+	}
+
+	puuids, err := as.accountPUUIDs(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stats, err := as.seasonStats(ctx, playerID, puuids, seasonStart, now)
+	if err != nil {
+		return nil, err
+	}
+
+	archive := &models.SeasonArchive{
+		PlayerID:    playerID,
+		Season:      season,
+		SeasonStart: seasonStart,
+		ArchivedAt:  now,
+		Stats:       stats,
+	}
+	if err := as.playerRepo.SaveSeasonArchive(ctx, archive); err != nil {
+		return nil, err
+	}
+
+	// Reset the current window of each account
+	for _, puuid := range puuids {
+		if err := as.playerRepo.DeletePlayerStats(ctx, puuid); err != nil {
+			return nil, err
+		}
+		as.invalidatePlayerCaches(ctx, puuid)
+	}
+
+	return archive, nil
+}
+
+// ListSeasons returns the player's archived seasons, newest first
+func (as *AnalyticsService) ListSeasons(ctx context.Context, playerID string) (*SeasonHistory, error) {
+	archives, err := as.playerRepo.ListSeasonArchives(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &SeasonHistory{
+		PlayerID:           playerID,
+		CurrentSeasonStart: championStatsEpoch,
+		Archives:           archives,
+	}
+	if len(archives) > 0 {
+		history.CurrentSeasonStart = archives[0].ArchivedAt
+	}
+
+	return history, nil
+}
+
+// precomputePeriods are the period stats windows warmed after a sync
+var precomputePeriods = []int{7, 30}
+
+// ErrWarmupInProgress is returned when the player's analytics are already
+// being warmed
+var ErrWarmupInProgress = fmt.Errorf("analytics warmup already in progress")
+
+// PrecomputeAnalytics recomputes and caches the common analytics of the
+// user's linked Riot accounts in the background so the first load after a
+// sync doesn't pay for it. It is used as a RiotService sync hook; a
+// precompute already running for an account is not started twice.
+func (as *AnalyticsService) PrecomputeAnalytics(userID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		start := time.Now()
+		err := as.WarmupUserAnalytics(ctx, userID)
+		switch {
+		case errors.Is(err, ErrWarmupInProgress):
+		case err != nil:
+			logger.Warnf("Analytics precompute for user %s failed: %v", userID, err)
+		default:
+			logger.Debugf("Analytics precompute for user %s finished in %s", userID, time.Since(start).Round(time.Millisecond))
+		}
+	}()
+}
+
+// WarmupUserAnalytics runs WarmupPlayerAnalytics for each Riot account linked
+// to the user; analytics are cached per account PUUID, as the endpoints read
+// them. Accounts already being warmed are skipped, and ErrWarmupInProgress
+// is returned only when all of them were. A user without linked accounts has
+// nothing to warm.
+func (as *AnalyticsService) WarmupUserAnalytics(ctx context.Context, userID string) error {
+	puuids, err := as.matchRepo.ListLinkedPUUIDs(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	skipped := 0
+	for _, puuid := range puuids {
+		err := as.WarmupPlayerAnalytics(ctx, puuid)
+		switch {
+		case errors.Is(err, ErrWarmupInProgress):
+			skipped++
+		case err != nil:
+			return fmt.Errorf("account %s: %w", puuid, err)
+		}
+	}
+	if len(puuids) > 0 && skipped == len(puuids) {
+		return ErrWarmupInProgress
+	}
+	return nil
+}
+
+// WarmupPlayerAnalytics recomputes and caches a player's common analytics,
+// returning ErrWarmupInProgress when a warmup for the player is already
+// running
+func (as *AnalyticsService) WarmupPlayerAnalytics(ctx context.Context, playerID string) error {
+	as.precomputeMu.Lock()
+	if as.precomputing[playerID] {
+		as.precomputeMu.Unlock()
+		return ErrWarmupInProgress
+	}
+	as.precomputing[playerID] = true
+	as.precomputeMu.Unlock()
+
+	defer func() {
+		as.precomputeMu.Lock()
+		delete(as.precomputing, playerID)
+		as.precomputeMu.Unlock()
+	}()
+
+	return as.warmPlayerAnalytics(ctx, playerID)
+}
+
+// warmPlayerAnalytics folds new matches into the champion aggregates, drops
+// the player's stale cached analytics and recomputes period stats, KDA/CS
+// trends and per-champion stats
+func (as *AnalyticsService) warmPlayerAnalytics(ctx context.Context, playerID string) error {
+	if _, err := as.UpdateChampionStats(ctx, playerID); err != nil {
+		return fmt.Errorf("failed to update champion aggregates: %w", err)
+	}
+
+	if as.redisService == nil {
+		return nil // Nothing to warm without a cache
+	}
+
+	for _, days := range precomputePeriods {
+		for _, compare := range []bool{false, true} {
+			as.redisService.Delete(ctx, periodStatsCacheKey(playerID, days, compare, MatchFilter{}.normalize()))
+			if _, err := as.GetPeriodStats(ctx, playerID, days, compare, MatchFilter{}); err != nil {
+				return fmt.Errorf("failed to precompute period stats: %w", err)
+			}
+		}
+	}
+
+	for _, timeRange := range rebuildTimeRanges {
+		if _, err := as.AnalyzeKDA(ctx, playerID, timeRange, "", MatchFilter{}); err != nil {
+			return fmt.Errorf("failed to precompute KDA analysis: %w", err)
+		}
+		if _, err := as.AnalyzeCS(ctx, playerID, timeRange, "", "", MatchFilter{}); err != nil {
+			return fmt.Errorf("failed to precompute CS analysis: %w", err)
+		}
+	}
+
+	// All-time stats for every champion played recently
+	startDate, endDate := as.parseTimeRange("30d")
+	matches, err := as.loadPlayerMatches(ctx, playerID, startDate, endDate)
+	if err != nil {
+		return fmt.Errorf("failed to get player matches: %w", err)
+	}
+
+	seen := make(map[int]bool)
+	for _, match := range matches {
+		if seen[match.ChampionID] {
+			continue
+		}
+		seen[match.ChampionID] = true
+
+		window := StatsWindow{}
+		as.redisService.Delete(ctx, championStatsCacheKey(playerID, match.ChampionID, window))
+		if _, err := as.GetChampionStats(ctx, playerID, match.ChampionID, window); err != nil {
+			return fmt.Errorf("failed to precompute champion stats: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GetStatsRebuildStatus returns the latest rebuild job for a player
+func (as *AnalyticsService) GetStatsRebuildStatus(playerID string) (*StatsRebuildJob, bool) {
+	as.rebuildMu.RLock()
+	defer as.rebuildMu.RUnlock()
+
+	job, ok := as.rebuildJobs[playerID]
+	if !ok {
+		return nil, false
+	}
+	snapshot := *job
+	return &snapshot, true
+}
+
+// rebuildPlayerStats rebuilds the stats of every Riot account linked to the
+// user playerID, or of the PUUID playerID itself when none is linked, and
+// returns the number of matches processed across them
+func (as *AnalyticsService) rebuildPlayerStats(ctx context.Context, playerID string) (int, error) {
+	puuids, err := as.accountPUUIDs(ctx, playerID)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, puuid := range puuids {
+		matches, err := as.rebuildAccountStats(ctx, puuid)
+		processed += matches
+		if err != nil {
+			return processed, fmt.Errorf("account %s: %w", puuid, err)
+		}
+	}
+	return processed, nil
+}
+
+// rebuildAccountStats clears and recomputes the stats stored under one PUUID
+func (as *AnalyticsService) rebuildAccountStats(ctx context.Context, playerID string) (int, error) {
+	if err := as.playerRepo.DeletePlayerStats(ctx, playerID); err != nil {
+		return 0, err
+	}
+
+	// Drop cached analyses so they're recomputed from the fresh data
+	as.invalidatePlayerCaches(ctx, playerID)
+
+	processed := 0
+	for _, timeRange := range rebuildTimeRanges {
+		startDate, endDate := as.parseTimeRange(timeRange)
+
+		acc := newPlayerStatsAccumulator(as, playerID, timeRange)
+		err := as.forEachMatchBatch(ctx, playerID, startDate, endDate, func(batch []models.MatchData) error {
+			for _, match := range batch {
+				acc.add(match)
+			}
+			return nil
+		})
+		if err != nil {
+			return processed, fmt.Errorf("failed to get player matches: %w", err)
+		}
+
+		stats := acc.result()
+		if stats.TotalMatches > processed {
+			processed = stats.TotalMatches
+		}
+		if err := as.playerRepo.SavePlayerStats(ctx, stats); err != nil {
+			return processed, err
+		}
+
+		if _, err := as.AnalyzeKDA(ctx, playerID, timeRange, "", MatchFilter{}); err != nil {
+			return processed, fmt.Errorf("failed to rebuild KDA analysis: %w", err)
+		}
+		if _, err := as.AnalyzeCS(ctx, playerID, timeRange, "", "", MatchFilter{}); err != nil {
+			return processed, fmt.Errorf("failed to rebuild CS analysis: %w", err)
+		}
+	}
+
+	// The aggregates were cleared above, so this folds in every match again
+	if _, err := as.UpdateChampionStats(ctx, playerID); err != nil {
+		return processed, fmt.Errorf("failed to rebuild champion aggregates: %w", err)
+	}
+
+	// champion_stats keeps one all-time row per champion, derived from the
+	// fresh aggregates
+	aggregates, err := as.playerRepo.ListChampionAggregates(ctx, playerID)
+	if err != nil {
+		return processed, err
+	}
+	for _, agg := range aggregates {
+		if err := as.playerRepo.SaveChampionStats(ctx, ChampionStatsFromAggregate(agg)); err != nil {
+			return processed, err
+		}
+	}
+
+	return processed, nil
+}
+
+// StatsWindow limits a computation to recent matches. Zero values mean
+// all-time; when both are set the match must satisfy both limits.
+type StatsWindow struct {
+	Days  int `json:"days,omitempty"`  // only matches from the last N days
+	Games int `json:"games,omitempty"` // only the N most recent matches
+
+	MatchFilter
+}
+
+// MatchFilter narrows the matches an analysis is computed from. The zero
+// value keeps every match.
+type MatchFilter struct {
+	Result                ResultFilter `json:"result,omitempty"`                  // only won or lost matches
+	ExcludeNonCompetitive bool         `json:"exclude_non_competitive,omitempty"` // drop remakes, early surrenders, AFK games
+	ExcludeRemakes        bool         `json:"exclude_remakes,omitempty"`         // drop remakes only, see ExcludeRemakesDefault
+
+	// Only games on IncludeChampions when set, and never games on
+	// ExcludeChampions, e.g. to review carry champions without fill games
+	IncludeChampions []string `json:"include_champions,omitempty"`
+	ExcludeChampions []string `json:"exclude_champions,omitempty"`
+}
+
+// ResultFilter limits analytics to won or lost games, e.g. to see which
+// champions a player loses most on
+type ResultFilter string
+
+const (
+	ResultAll  ResultFilter = "all"
+	ResultWin  ResultFilter = "win"
+	ResultLoss ResultFilter = "loss"
+)
+
+// ParseResultFilter validates a result query value; an empty value means all games
+func ParseResultFilter(value string) (ResultFilter, error) {
+	switch filter := ResultFilter(strings.ToLower(strings.TrimSpace(value))); filter {
+	case "":
+		return ResultAll, nil
+	case ResultAll, ResultWin, ResultLoss:
+		return filter, nil
+	default:
+		return "", fmt.Errorf("invalid result filter %q: use win, loss, or all", value)
+	}
+}
+
+func (f ResultFilter) normalize() ResultFilter {
+	if f == "" {
+		return ResultAll
+	}
+	return f
+}
+
+func (f MatchFilter) normalize() MatchFilter {
+	f.Result = f.Result.normalize()
+	f.IncludeChampions = normalizeChampionList(f.IncludeChampions)
+	f.ExcludeChampions = normalizeChampionList(f.ExcludeChampions)
+	return f
+}
+
+// normalizeChampionList maps champion names to display names, sorted and
+// without duplicates in any letter case, so equal lists share cache keys
+func normalizeChampionList(champions []string) []string {
+	if len(champions) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(champions))
+	normalized := make([]string, 0, len(champions))
+	for _, champion := range champions {
+		name := models.ChampionDisplayName(champion)
+		if name == "" || seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		normalized = append(normalized, name)
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		return strings.ToLower(normalized[i]) < strings.ToLower(normalized[j])
+	})
+	return normalized
+}
+
+// cacheKey identifies the filter within analytics cache keys
+func (f MatchFilter) cacheKey() string {
+	key := fmt.Sprintf("%s:%t:%t", f.Result.normalize(), f.ExcludeNonCompetitive, f.ExcludeRemakes)
+	include, exclude := normalizeChampionList(f.IncludeChampions), normalizeChampionList(f.ExcludeChampions)
+	if len(include) > 0 || len(exclude) > 0 {
+		key += ":" + strings.ToLower(strings.Join(include, ",")) + ":" + strings.ToLower(strings.Join(exclude, ","))
+	}
+	return key
+}
+
+// isEmpty reports whether the filter keeps every match
+func (f MatchFilter) isEmpty() bool {
+	return f.Result.normalize() == ResultAll && !f.ExcludeNonCompetitive && !f.ExcludeRemakes &&
+		len(normalizeChampionList(f.IncludeChampions)) == 0 && len(normalizeChampionList(f.ExcludeChampions)) == 0
+}
+
+// championQuery turns the champion lists into the repository filter, adding
+// every stored name form of each champion. A non-empty champion narrows the
+// include list to that champion; ok is false when it is outside the include
+// list or excluded, so no match can pass.
+func (f MatchFilter) championQuery(champion string) (filter repository.ChampionFilter, ok bool) {
+	include := normalizeChampionList(f.IncludeChampions)
+	exclude := normalizeChampionList(f.ExcludeChampions)
+
+	if champion != "" {
+		name := models.ChampionDisplayName(champion)
+		if include != nil && !championSet(include)[strings.ToLower(name)] {
+			return filter, false
+		}
+		if championSet(exclude)[strings.ToLower(name)] {
+			return filter, false
+		}
+		include = []string{name}
+	}
+
+	filter.Include = championNameForms(include)
+	filter.Exclude = championNameForms(exclude)
+	return filter, true
+}
+
+// championNameForms lists the display and internal name of each champion
+func championNameForms(champions []string) []string {
+	if len(champions) == 0 {
+		return nil
+	}
+	forms := make([]string, 0, len(champions)*2)
+	for _, champion := range champions {
+		forms = append(forms, models.ChampionDisplayName(champion))
+		if internal := models.ChampionInternalName(champion); !strings.EqualFold(internal, forms[len(forms)-1]) {
+			forms = append(forms, internal)
+		}
+	}
+	return forms
+}
+
+// getFilteredMatches loads the player's matches in [startDate, endDate) with
+// the champion lists applied in the query, then applies the rest of the
+// filter. champion, when set, limits the query to that champion. It returns
+// the matches, newest first, and how many remakes and non-competitive games
+// were excluded.
+func (as *AnalyticsService) getFilteredMatches(ctx context.Context, playerID string, startDate, endDate time.Time, champion string, filter MatchFilter) ([]models.MatchData, int, error) {
+	champions, ok := filter.championQuery(champion)
+	if !ok {
+		return []models.MatchData{}, 0, nil
+	}
+
+	matches, err := as.matchRepo.GetPlayerMatchData(ctx, playerID, startDate, endDate, champions)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get player matches: %w", err)
+	}
+
+	matches, excluded := filter.apply(matches)
+	return matches, excluded, nil
+}
+
+// getAccountFilteredMatches is getFilteredMatches over every Riot account
+// linked to the user playerID, or over the PUUID playerID itself when no
+// account is linked to it. Matches stay newest first.
+func (as *AnalyticsService) getAccountFilteredMatches(ctx context.Context, playerID string, startDate, endDate time.Time, champion string, filter MatchFilter) ([]models.MatchData, int, error) {
+	puuids, err := as.accountPUUIDs(ctx, playerID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	matches := make([]models.MatchData, 0)
+	excluded := 0
+	for _, puuid := range puuids {
+		accountMatches, accountExcluded, err := as.getFilteredMatches(ctx, puuid, startDate, endDate, champion, filter)
+		if err != nil {
+			return nil, 0, err
+		}
+		matches = append(matches, accountMatches...)
+		excluded += accountExcluded
+	}
+
+	if len(puuids) > 1 {
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].Date.After(matches[j].Date)
+		})
+	}
+	return matches, excluded, nil
+}
+
+// apply returns the matches passing the filter along with the number of
+// remakes and non-competitive games that were excluded. Queries already
+// narrow by champion through championQuery; apply checks the champion lists
+// again so it is correct on any match list.
+func (f MatchFilter) apply(matches []models.MatchData) ([]models.MatchData, int) {
+	result := f.Result.normalize()
+	include := championSet(f.IncludeChampions)
+	exclude := championSet(f.ExcludeChampions)
+	if result == ResultAll && !f.ExcludeNonCompetitive && !f.ExcludeRemakes && include == nil && exclude == nil {
+		return matches, 0
+	}
+
+	filtered := make([]models.MatchData, 0, len(matches))
+	excluded := 0
+	for _, match := range matches {
+		if (f.ExcludeNonCompetitive && match.IsNonCompetitive()) || (f.ExcludeRemakes && match.GameWasRemade) {
+			excluded++
+			continue
+		}
+		if result != ResultAll && match.Win != (result == ResultWin) {
+			continue
+		}
+		if include != nil || exclude != nil {
+			champion := strings.ToLower(models.ChampionDisplayName(match.ChampionName))
+			if (include != nil && !include[champion]) || exclude[champion] {
+				continue
+			}
+		}
+		filtered = append(filtered, match)
+	}
+	return filtered, excluded
+}
+
+// championSet returns the lower-cased display names of champions, nil when
+// there are none
+func championSet(champions []string) map[string]bool {
+	normalized := normalizeChampionList(champions)
+	if len(normalized) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(normalized))
+	for _, champion := range normalized {
+		set[strings.ToLower(champion)] = true
+	}
+	return set
+}
+
+// ExcludeRemakesDefault returns the user's excludeRemakes preference, used
+// when a request doesn't set it explicitly. Missing preferences mean false.
+func (as *AnalyticsService) ExcludeRemakesDefault(ctx context.Context, userID string) bool {
+	var exclude bool
+	err := as.db.QueryRowContext(ctx, `SELECT exclude_remakes FROM user_preferences WHERE user_id = $1`, userID).Scan(&exclude)
+	if err != nil && err != sql.ErrNoRows {
+		logger.Warnf("Failed to load excludeRemakes preference for %s: %v", userID, err)
+	}
+	return exclude
+}
+
+// championStatsEpoch is the start date used for all-time champion stats
+var championStatsEpoch = time.Date(2009, time.October, 27, 0, 0, 0, 0, time.UTC)
+
+// GetChampionStats computes a player's statistics on one champion within the
+// given window, e.g. the last 20 games for current form or all-time for lifetime
+func (as *AnalyticsService) GetChampionStats(ctx context.Context, playerID string, championID int, window StatsWindow) (*models.ChampionStats, error) {
+	key := championStatsCacheKey(playerID, championID, window)
+	if as.redisService != nil {
+		var cached models.ChampionStats
+		if err := as.redisService.Get(ctx, key, &cached); err == nil {
+			return &cached, nil
+		}
+	}
+
+	stats, err := as.calculateChampionStats(ctx, playerID, championID, window)
+	if err != nil {
+		return nil, err
+	}
+
+	if as.redisService != nil {
+		as.redisService.Set(ctx, key, stats, 30*time.Minute)
+	}
+
+	return stats, nil
+}
+
+func championStatsCacheKey(playerID string, championID int, window StatsWindow) string {
+	return fmt.Sprintf("champion_stats:%s:%d:%d:%d:%s", playerID, championID, window.Days, window.Games, window.MatchFilter.cacheKey())
+}
+
+func (as *AnalyticsService) calculateChampionStats(ctx context.Context, playerID string, championID int, window StatsWindow) (*models.ChampionStats, error) {
+	if window.isAllTime() {
+		return as.allTimeChampionStats(ctx, playerID, championID)
+	}
+
+	endDate := time.Now()
+	startDate := championStatsEpoch
+	if window.Days > 0 {
+		startDate = endDate.AddDate(0, 0, -window.Days)
+	}
+
+	matches, excluded, err := as.getFilteredMatches(ctx, playerID, startDate, endDate, "", window.MatchFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	championMatches := make([]models.MatchData, 0, len(matches))
+	for _, match := range matches {
+		if match.ChampionID == championID {
+			championMatches = append(championMatches, match)
+		}
+	}
+
+	sort.Slice(championMatches, func(i, j int) bool {
+		return championMatches[i].Date.After(championMatches[j].Date)
+	})
+	if window.Games > 0 && len(championMatches) > window.Games {
+		championMatches = championMatches[:window.Games]
+	}
+
+	stats := as.aggregateChampionStats(playerID, championID, championMatches)
+	stats.ExcludedGames = excluded
+	return stats, nil
+}
+
+// ChampionSort orders a player's champion list
+type ChampionSort string
+
+const (
+	ChampionSortGames   ChampionSort = "games"
+	ChampionSortWinRate ChampionSort = "winrate"
+	ChampionSortKDA     ChampionSort = "kda"
+)
+
+// DefaultChampionSortMinGames is the minimum games per champion applied when
+// sorting by win rate or KDA, so one lucky game doesn't top the list
+const DefaultChampionSortMinGames = 5
+
+// championSortColumns maps each sort to its ORDER BY expression
+var championSortColumns = map[ChampionSort]string{
+	ChampionSortGames:   "games DESC",
+	ChampionSortWinRate: "win_rate DESC, games DESC",
+	ChampionSortKDA:     "kda DESC, games DESC",
+}
+
+// ParseChampionSort validates a sort query parameter, defaulting to games
+func ParseChampionSort(value string) (ChampionSort, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" {
+		return ChampionSortGames, nil
+	}
+	sortBy := ChampionSort(value)
+	if _, ok := championSortColumns[sortBy]; !ok {
+		return "", fmt.Errorf("invalid sort parameter, must be games, winrate or kda")
+	}
+	return sortBy, nil
+}
+
+// ListChampionStats returns per-champion stats for a player, sorted and
+// thresholded in SQL. A negative minGames uses DefaultChampionSortMinGames for
+// win rate and KDA sorts and no threshold for games. With byRole each
+// champion and role pair is its own entry, so Lux mid and Lux support are
+// ranked and thresholded separately.
+func (as *AnalyticsService) ListChampionStats(ctx context.Context, playerID, timeRange string, sortBy ChampionSort, minGames int, byRole bool) ([]models.ChampionStats, error) {
+	orderBy, ok := championSortColumns[sortBy]
+	if !ok {
+		return nil, fmt.Errorf("unknown champion sort %q", sortBy)
+	}
+	if minGames < 0 {
+		minGames = 1
+		if sortBy != ChampionSortGames {
+			minGames = DefaultChampionSortMinGames
+		}
+	}
+	startDate, _ := as.parseTimeRange(timeRange)
+
+	roleColumn, groupBy := "''", "mp.champion_id, mp.champion_name"
+	if byRole {
+		roleColumn = "COALESCE(mp.team_position, '')"
+		groupBy += ", mp.team_position"
+	}
+
+	query := `
+		SELECT mp.champion_id, mp.champion_name, ` + roleColumn + ` AS role, COUNT(*) AS games,
+			SUM(CASE WHEN mp.won THEN 1 ELSE 0 END) AS wins,
+			AVG(CASE WHEN mp.won THEN 100.0 ELSE 0 END) AS win_rate,
+			CASE WHEN SUM(mp.deaths) = 0 THEN SUM(mp.kills + mp.assists) * 1.0
+				ELSE SUM(mp.kills + mp.assists) * 1.0 / SUM(mp.deaths) END AS kda,
+			COALESCE(AVG(mp.cs_per_minute), 0),
+			COALESCE(AVG(mp.vision_score), 0),
+			MAX(m.game_start_timestamp)
+		FROM match_participants mp
+		JOIN matches m ON mp.match_id = m.id
+		WHERE mp.puuid = $1
+		AND m.game_start_timestamp >= $2
+		GROUP BY ` + groupBy + `
+		HAVING COUNT(*) >= $3
+		ORDER BY ` + orderBy + `, mp.champion_name, role
+	`
+
+	rows, err := as.db.QueryContext(ctx, query, playerID, startDate.UnixMilli(), minGames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query champion stats: %w", err)
+	}
+	defer rows.Close()
+
+	champions := make([]models.ChampionStats, 0)
+	for rows.Next() {
+		stats := models.ChampionStats{PlayerID: playerID}
+		var lastPlayed int64
+		if err := rows.Scan(&stats.ChampionID, &stats.ChampionName, &stats.Role, &stats.TotalMatches, &stats.Wins,
+			&stats.WinRate, &stats.AverageKDA, &stats.AverageCSPerMin, &stats.AverageVisionScore, &lastPlayed); err != nil {
+			return nil, fmt.Errorf("failed to scan champion stats: %w", err)
+		}
+		stats.Losses = stats.TotalMatches - stats.Wins
+		stats.LastPlayed = time.UnixMilli(lastPlayed)
+		champions = append(champions, stats)
+	}
+
+	return champions, rows.Err()
+}
+
+// aggregateChampionStats builds ChampionStats from matches sorted newest first
+func (as *AnalyticsService) aggregateChampionStats(playerID string, championID int, matches []models.MatchData) *models.ChampionStats {
+	stats := &models.ChampionStats{
+		PlayerID:          playerID,
+		ChampionID:        championID,
+		TotalMatches:      len(matches),
+		RoleSpecificStats: make(map[string]interface{}),
+	}
+
+	if len(matches) == 0 {
+		return stats
+	}
+
+	stats.ChampionName = matches[0].ChampionName
+	stats.LastPlayed = matches[0].Date
+
+	var kills, deaths, assists int
+	var totalCSPerMin, totalVision, totalDamageShare float64
+	roleGames := make(map[string]int)
+
+	for _, match := range matches {
+		if match.Win {
+			stats.Wins++
+		} else {
+			stats.Losses++
+		}
+		kills += match.Kills
+		deaths += match.Deaths
+		assists += match.Assists
+		totalCSPerMin += match.CSPerMinute
+		totalVision += float64(match.VisionScore)
+		totalDamageShare += match.DamageShare
+		roleGames[match.Position]++
+	}
+
+	count := float64(len(matches))
+	stats.WinRate = float64(stats.Wins) / count * 100
+	stats.AverageKDA = as.calculateKDA(kills, deaths, assists)
+	stats.AverageCSPerMin = totalCSPerMin / count
+	stats.AverageVisionScore = totalVision / count
+	stats.AverageDamageShare = totalDamageShare / count
+
+	for role, games := range roleGames {
+		if games > roleGames[stats.PreferredRole] || (games == roleGames[stats.PreferredRole] && role < stats.PreferredRole) {
+			stats.PreferredRole = role
+		}
+	}
+
+	return stats
+}
+
+// aggregatePlayerStats builds the PlayerStats row for a set of matches
+func (as *AnalyticsService) aggregatePlayerStats(playerID, timeRange string, matches []models.MatchData) *models.PlayerStats {
+	acc := newPlayerStatsAccumulator(as, playerID, timeRange)
+	for _, match := range matches {
+		acc.add(match)
+	}
+	return acc.result()
+}
+
+// forEachMatchBatch pages through a player's matches in [startDate, endDate)
+// oldest first, handing each batch of at most batchSize matches to fn so only
+// one page is held in memory at a time
+func (as *AnalyticsService) forEachMatchBatch(ctx context.Context, playerID string, startDate, endDate time.Time, fn func([]models.MatchData) error) error {
+	return as.forEachMatchBatchAfter(ctx, playerID, startDate, endDate, repository.MatchCursor{}, fn)
+}
+
+// loadPlayerMatches collects every batch of forEachMatchBatch, for analyses
+// that need the whole window at once
+func (as *AnalyticsService) loadPlayerMatches(ctx context.Context, playerID string, startDate, endDate time.Time) ([]models.MatchData, error) {
+	matches := make([]models.MatchData, 0)
+	err := as.forEachMatchBatch(ctx, playerID, startDate, endDate, func(batch []models.MatchData) error {
+		matches = append(matches, batch...)
+		return nil
+	})
+	return matches, err
+}
+
+// accountPUUIDs returns the PUUIDs of the Riot accounts linked to the user
+// playerID, or playerID itself when it has no linked account and so is
+// taken to be a PUUID
+func (as *AnalyticsService) accountPUUIDs(ctx context.Context, playerID string) ([]string, error) {
+	puuids, err := as.matchRepo.ListLinkedPUUIDs(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if len(puuids) == 0 {
+		puuids = []string{playerID}
+	}
+	return puuids, nil
+}
+
+// loadAccountMatches loads the matches in [startDate, endDate) of every Riot
+// account linked to the user playerID, or of the PUUID playerID itself when
+// no account is linked to it
+func (as *AnalyticsService) loadAccountMatches(ctx context.Context, playerID string, startDate, endDate time.Time) ([]models.MatchData, error) {
+	puuids, err := as.accountPUUIDs(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]models.MatchData, 0)
+	for _, puuid := range puuids {
+		accountMatches, err := as.loadPlayerMatches(ctx, puuid, startDate, endDate)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, accountMatches...)
+	}
+	return matches, nil
+}
+
+// seasonStats aggregates the matches in [startDate, endDate) of every Riot
+// account linked to the user playerID one batch at a time, for season archives
+func (as *AnalyticsService) seasonStats(ctx context.Context, playerID string, puuids []string, startDate, endDate time.Time) (*models.PlayerStats, error) {
+	acc := newPlayerStatsAccumulator(as, playerID, "season")
+	for _, puuid := range puuids {
+		err := as.forEachMatchBatch(ctx, puuid, startDate, endDate, func(batch []models.MatchData) error {
+			for _, match := range batch {
+				acc.add(match)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get player matches: %w", err)
+		}
+	}
+	return acc.result(), nil
+}
+
+// forEachMatchBatchAfter is forEachMatchBatch starting strictly after cursor
+func (as *AnalyticsService) forEachMatchBatchAfter(ctx context.Context, playerID string, startDate, endDate time.Time, cursor repository.MatchCursor, fn func([]models.MatchData) error) error {
+	batchSize := as.batchSize
+	if batchSize < 1 {
+		batchSize = DefaultAnalyticsBatchSize
+	}
+
+	for {
+		batch, err := as.matchRepo.GetPlayerMatchDataPage(ctx, playerID, startDate, endDate, cursor, batchSize)
+		if err != nil {
+			return err
+		}
+		if len(batch) == 0 {
+			return nil
+		}
+
+		if err := fn(batch); err != nil {
+			return err
+		}
+
+		if len(batch) < batchSize {
+			return nil
+		}
+		last := batch[len(batch)-1]
+		cursor = repository.MatchCursor{Date: last.Date, MatchID: last.MatchID}
+	}
+}
+
+// playerStatsAccumulator builds PlayerStats one match at a time so aggregates
+// can be computed over batches without holding every match in memory
+type playerStatsAccumulator struct {
+	as    *AnalyticsService
+	stats *models.PlayerStats
+
+	championGames                                   map[string]int
+	totalCS, totalCSPerMin, totalVision, totalShare float64
+}
+
+func newPlayerStatsAccumulator(as *AnalyticsService, playerID, timeRange string) *playerStatsAccumulator {
+	return &playerStatsAccumulator{
+		as: as,
+		stats: &models.PlayerStats{
+			PlayerID:         playerID,
+			TimeRange:        timeRange,
+			LastUpdated:      time.Now(),
+			RoleDistribution: make(map[string]int),
+		},
+		championGames: make(map[string]int),
+	}
+}
+
+func (a *playerStatsAccumulator) add(match models.MatchData) {
+	stats := a.stats
+	stats.TotalMatches++
+	if match.Win {
+		stats.Wins++
+	} else {
+		stats.Losses++
+	}
+	stats.TotalKills += match.Kills
+	stats.TotalDeaths += match.Deaths
+	stats.TotalAssists += match.Assists
+
+	if kda := a.as.calculateKDA(match.Kills, match.Deaths, match.Assists); stats.TotalMatches == 1 || kda > stats.BestKDA {
+		stats.BestKDA = kda
+	}
+
+	a.totalCS += float64(match.TotalCS)
+	a.totalCSPerMin += match.CSPerMinute
+	a.totalVision += float64(match.VisionScore)
+	a.totalShare += match.DamageShare
+
+	a.championGames[match.ChampionName]++
+	stats.RoleDistribution[match.Position]++
+}
+
+func (a *playerStatsAccumulator) result() *models.PlayerStats {
+	stats := a.stats
+	if stats.TotalMatches == 0 {
+		return stats
+	}
+
+	count := float64(stats.TotalMatches)
+	stats.WinRate = float64(stats.Wins) / count * 100
+	stats.AverageKDA = a.as.calculateKDA(stats.TotalKills, stats.TotalDeaths, stats.TotalAssists)
+	stats.AverageCS = a.totalCS / count
+	stats.AverageCSPerMin = a.totalCSPerMin / count
+	stats.AverageVisionScore = a.totalVision / count
+	stats.AverageDamageShare = a.totalShare / count
+	stats.TotalChampionsPlayed = len(a.championGames)
+
+	for champion, games := range a.championGames {
+		if games > a.championGames[stats.MainChampion] || (games == a.championGames[stats.MainChampion] && champion < stats.MainChampion) {
+			stats.MainChampion = champion
+		}
+	}
+	for role, games := range stats.RoleDistribution {
+		if games > stats.RoleDistribution[stats.MainRole] || (games == stats.RoleDistribution[stats.MainRole] && role < stats.MainRole) {
+			stats.MainRole = role
+		}
+	}
+
+	return stats
+}
@@ -2,6 +2,8 @@ package services_test
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -26,7 +28,7 @@ func TestAnalyticsService_AnalyzeKDA(t *testing.T) {
 		testMatches := createTestMatches(playerID)
 
 		// Perform analysis
-		analysis, err := service.AnalyzeKDA(ctx, playerID, timeRange, champion)
+		analysis, err := service.AnalyzeKDA(ctx, playerID, timeRange, champion, services.MatchFilter{})
 
 		// Assertions
 		require.NoError(t, err)
@@ -42,7 +44,7 @@ func TestAnalyticsService_AnalyzeKDA(t *testing.T) {
 	})
 
 	t.Run("KDA trend analysis", func(t *testing.T) {
-		analysis, err := service.AnalyzeKDA(ctx, playerID, timeRange, champion)
+		analysis, err := service.AnalyzeKDA(ctx, playerID, timeRange, champion, services.MatchFilter{})
 		require.NoError(t, err)
 
 		// Check trend analysis
@@ -56,7 +58,7 @@ func TestAnalyticsService_AnalyzeKDA(t *testing.T) {
 	})
 
 	t.Run("KDA distribution calculation", func(t *testing.T) {
-		analysis, err := service.AnalyzeKDA(ctx, playerID, timeRange, champion)
+		analysis, err := service.AnalyzeKDA(ctx, playerID, timeRange, champion, services.MatchFilter{})
 		require.NoError(t, err)
 
 		// Verify distribution categories
@@ -84,7 +86,7 @@ func TestAnalyticsService_AnalyzeCS(t *testing.T) {
 	champion := ""
 
 	t.Run("successful CS analysis", func(t *testing.T) {
-		analysis, err := service.AnalyzeCS(ctx, playerID, timeRange, position, champion)
+		analysis, err := service.AnalyzeCS(ctx, playerID, timeRange, position, champion, services.MatchFilter{})
 
 		require.NoError(t, err)
 		assert.NotNil(t, analysis)
@@ -94,7 +96,7 @@ func TestAnalyticsService_AnalyzeCS(t *testing.T) {
 	})
 
 	t.Run("CS benchmarking", func(t *testing.T) {
-		analysis, err := service.AnalyzeCS(ctx, playerID, timeRange, position, champion)
+		analysis, err := service.AnalyzeCS(ctx, playerID, timeRange, position, champion, services.MatchFilter{})
 		require.NoError(t, err)
 
 		// Should have benchmark data for ADC role
@@ -107,7 +109,7 @@ func TestAnalyticsService_AnalyzeCS(t *testing.T) {
 	})
 
 	t.Run("CS efficiency calculation", func(t *testing.T) {
-		analysis, err := service.AnalyzeCS(ctx, playerID, timeRange, position, champion)
+		analysis, err := service.AnalyzeCS(ctx, playerID, timeRange, position, champion, services.MatchFilter{})
 		require.NoError(t, err)
 
 		// CS efficiency should be a percentage
@@ -116,7 +118,7 @@ func TestAnalyticsService_AnalyzeCS(t *testing.T) {
 	})
 
 	t.Run("CS recommendations", func(t *testing.T) {
-		analysis, err := service.AnalyzeCS(ctx, playerID, timeRange, position, champion)
+		analysis, err := service.AnalyzeCS(ctx, playerID, timeRange, position, champion, services.MatchFilter{})
 		require.NoError(t, err)
 
 		// Should have recommendations
@@ -186,6 +188,690 @@ func TestAnalyticsService_ComparePerformance(t *testing.T) {
 
 // Mathematical utility function tests
 
+func TestParseResultFilter(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected services.ResultFilter
+		wantErr  bool
+	}{
+		{"", services.ResultAll, false},
+		{"all", services.ResultAll, false},
+		{"win", services.ResultWin, false},
+		{" LOSS ", services.ResultLoss, false},
+		{"draw", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := services.ParseResultFilter(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParseChampionSort(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected services.ChampionSort
+		wantErr  bool
+	}{
+		{"", services.ChampionSortGames, false},
+		{"games", services.ChampionSortGames, false},
+		{"WinRate", services.ChampionSortWinRate, false},
+		{" kda ", services.ChampionSortKDA, false},
+		{"damage", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			sortBy, err := services.ParseChampionSort(tt.input)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, sortBy)
+		})
+	}
+}
+
+func TestChampionEntropy(t *testing.T) {
+	picks := func(names ...string) []models.MatchData {
+		matches := make([]models.MatchData, 0, len(names))
+		for _, name := range names {
+			matches = append(matches, models.MatchData{ChampionName: name})
+		}
+		return matches
+	}
+
+	t.Run("one-trick has no diversity", func(t *testing.T) {
+		entropy, normalized, unique := services.ChampionEntropy(picks("Ahri", "Ahri", "Ahri"))
+		assert.Equal(t, 0.0, entropy)
+		assert.Equal(t, 0.0, normalized)
+		assert.Equal(t, 1, unique)
+	})
+
+	t.Run("even split is maximal", func(t *testing.T) {
+		entropy, normalized, unique := services.ChampionEntropy(picks("Ahri", "Lux", "Zed", "Jinx"))
+		assert.InDelta(t, 2.0, entropy, 1e-9)
+		assert.InDelta(t, 1.0, normalized, 1e-9)
+		assert.Equal(t, 4, unique)
+	})
+
+	t.Run("skewed split", func(t *testing.T) {
+		// p = 3/4, 1/4: H = -(0.75*log2(0.75) + 0.25*log2(0.25)) ~ 0.8113
+		entropy, normalized, _ := services.ChampionEntropy(picks("Ahri", "Ahri", "Ahri", "Lux"))
+		assert.InDelta(t, 0.8113, entropy, 1e-4)
+		assert.InDelta(t, 0.8113, normalized, 1e-4)
+	})
+
+	t.Run("ignores unknown champions", func(t *testing.T) {
+		_, _, unique := services.ChampionEntropy(picks("Ahri", "", "Lux"))
+		assert.Equal(t, 2, unique)
+	})
+}
+
+func TestLinearTrend(t *testing.T) {
+	t.Run("perfect line", func(t *testing.T) {
+		slope, r2 := services.LinearTrend([]float64{1, 3, 5, 7, 9})
+		assert.InDelta(t, 2.0, slope, 1e-9)
+		assert.InDelta(t, 1.0, r2, 1e-9)
+	})
+
+	t.Run("noisy decline", func(t *testing.T) {
+		// x = 0..3, y = 4, 2, 3, 1: slope = -0.8, r^2 = 0.64
+		slope, r2 := services.LinearTrend([]float64{4, 2, 3, 1})
+		assert.InDelta(t, -0.8, slope, 1e-9)
+		assert.InDelta(t, 0.64, r2, 1e-9)
+	})
+
+	t.Run("flat values have no trend", func(t *testing.T) {
+		slope, r2 := services.LinearTrend([]float64{2, 2, 2})
+		assert.Equal(t, 0.0, slope)
+		assert.Equal(t, 0.0, r2)
+	})
+
+	t.Run("too few values", func(t *testing.T) {
+		slope, r2 := services.LinearTrend([]float64{5})
+		assert.Equal(t, 0.0, slope)
+		assert.Equal(t, 0.0, r2)
+	})
+}
+
+func TestBuildPerformanceAnalysis(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	matches := make([]models.MatchData, 0, 10)
+	for i := 0; i < 10; i++ {
+		matches = append(matches, models.MatchData{
+			ChampionName: []string{"Ahri", "Lux"}[i%2],
+			Kills:        2 + i,
+			Deaths:       2,
+			Assists:      2,
+			Win:          i >= 5,
+			Date:         start.AddDate(0, 0, i),
+		})
+	}
+	// Out of order input must give the same result
+	matches[0], matches[9] = matches[9], matches[0]
+
+	analysis := services.BuildPerformanceAnalysis(matches, 80)
+	assert.Equal(t, 10, analysis.Matches)
+	assert.Equal(t, 10, analysis.TrendGames)
+	assert.InDelta(t, 1.0, analysis.ChampionDiversity, 1e-9)
+	assert.Equal(t, 2, analysis.ChampionPoolSize)
+	assert.Equal(t, 80.0, analysis.ChampionPoolThreshold)
+	assert.InDelta(t, 0.5, analysis.KDATrendSlope, 1e-9)
+	assert.InDelta(t, 1.0, analysis.KDATrendR2, 1e-9)
+	assert.Greater(t, analysis.WinRateSlope, 0.0)
+	assert.Equal(t, "improving", analysis.TrendDirection)
+	assert.Equal(t, analysis, services.BuildPerformanceAnalysis(matches, 80))
+
+	few := services.BuildPerformanceAnalysis(matches[:3], 0)
+	assert.Equal(t, "insufficient_data", few.TrendDirection)
+	assert.Equal(t, services.DefaultChampionPoolThreshold, few.ChampionPoolThreshold)
+}
+
+func TestChampionPoolSize(t *testing.T) {
+	var matches []models.MatchData
+	for champion, games := range map[string]int{"Ahri": 6, "Lux": 2, "Zed": 1, "Yasuo": 1} {
+		for i := 0; i < games; i++ {
+			matches = append(matches, models.MatchData{ChampionName: champion})
+		}
+	}
+
+	assert.Equal(t, 1, services.ChampionPoolSize(matches, 50))
+	assert.Equal(t, 1, services.ChampionPoolSize(matches, 60))
+	assert.Equal(t, 2, services.ChampionPoolSize(matches, 80))
+	assert.Equal(t, 3, services.ChampionPoolSize(matches, 85))
+	assert.Equal(t, 4, services.ChampionPoolSize(matches, 100))
+	assert.Equal(t, 0, services.ChampionPoolSize(nil, 80))
+}
+
+func TestBuildDurationAnalysis(t *testing.T) {
+	matches := make([]models.MatchData, 0)
+	for i := 0; i < 10; i++ {
+		// Short games are all won, long games are mostly lost
+		matches = append(matches, models.MatchData{GameDuration: 20 * 60, Win: true, Kills: 4, Deaths: 2, Assists: 2})
+		matches = append(matches, models.MatchData{GameDuration: 40 * 60, Win: i < 3, Kills: 1, Deaths: 1, Assists: 1})
+	}
+	matches = append(matches, models.MatchData{GameDuration: 25 * 60, Win: true})
+
+	analysis := services.BuildDurationAnalysis(matches)
+	require.Len(t, analysis.Buckets, 3)
+	assert.Equal(t, 21, analysis.Matches)
+
+	short, mid, long := analysis.Buckets[0], analysis.Buckets[1], analysis.Buckets[2]
+	assert.Equal(t, 10, short.Games)
+	assert.InDelta(t, 100.0, short.WinRate, 1e-9)
+	assert.InDelta(t, 3.0, short.AverageKDA, 1e-9)
+	assert.Equal(t, 1, mid.Games) // 25 minutes falls in the middle bucket
+	assert.Equal(t, 10, long.Games)
+	assert.Equal(t, 7, long.Losses)
+	assert.InDelta(t, 30.0, long.WinRate, 1e-9)
+	assert.Equal(t, "early_game", analysis.Tendency)
+
+	assert.Equal(t, "insufficient_data", services.BuildDurationAnalysis(matches[:4]).Tendency)
+}
+
+func TestBuildPerformanceTrend(t *testing.T) {
+	monday := time.Date(2024, 6, 3, 18, 0, 0, 0, time.UTC)
+	matches := []models.MatchData{
+		{Date: monday.AddDate(0, 0, 14), Win: true},
+		{Date: monday, Win: true},
+		{Date: monday.AddDate(0, 0, 2), Win: false},
+		{Date: monday.AddDate(0, 0, 7), Win: true},
+	}
+	winRate := func(m models.MatchData) float64 {
+		if m.Win {
+			return 100
+		}
+		return 0
+	}
+
+	points := services.BuildPerformanceTrend(matches, winRate, "weekly")
+	require.Len(t, points, 3)
+	assert.Equal(t, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), points[0].PeriodStart)
+	assert.Equal(t, 2, points[0].Matches)
+	assert.InDelta(t, 50.0, points[0].Value, 1e-9)
+	assert.InDelta(t, 75.0, points[1].MovingAvg, 1e-9)
+	assert.InDelta(t, 250.0/3, points[2].MovingAvg, 1e-9)
+
+	assert.Len(t, services.BuildPerformanceTrend(matches, winRate, "daily"), 4)
+	assert.Len(t, services.BuildPerformanceTrend(matches, winRate, "monthly"), 1)
+}
+
+func TestBuildKDADistribution(t *testing.T) {
+	matches := []models.MatchData{
+		{Kills: 0, Deaths: 4, Assists: 0},
+		{Kills: 2, Deaths: 2, Assists: 2, Win: true},
+		{Kills: 5, Deaths: 1, Assists: 5, Win: true},
+		{Kills: 3, Deaths: 12, Assists: 1},
+		{Kills: 4, Deaths: 0, Assists: 6, Win: true},
+	}
+
+	distribution := services.BuildKDADistribution(matches)
+	assert.Equal(t, 5, distribution.Matches)
+	assert.InDelta(t, 40.0, distribution.FeedRate, 1e-9)
+	assert.InDelta(t, 40.0, distribution.CarryRate, 1e-9)
+
+	require.Len(t, distribution.KDABuckets, 6)
+	assert.Equal(t, "0-1", distribution.KDABuckets[0].Label)
+	assert.Equal(t, 2, distribution.KDABuckets[0].Games)
+	assert.InDelta(t, 0.0, distribution.KDABuckets[0].WinRate, 1e-9)
+	assert.Equal(t, "8+", distribution.KDABuckets[5].Label)
+	assert.Equal(t, 2, distribution.KDABuckets[5].Games)
+	assert.InDelta(t, 40.0, distribution.KDABuckets[5].Share, 1e-9)
+
+	assert.Equal(t, "10+", distribution.DeathsBuckets[10].Label)
+	assert.Equal(t, 1, distribution.DeathsBuckets[10].Games, "12 deaths falls in the last bucket")
+
+	assert.InDelta(t, 2.0, distribution.KDAPercentiles.P50, 1e-9)
+	assert.InDelta(t, 2.0, distribution.DeathsPercentiles.P50, 1e-9)
+	assert.InDelta(t, 1.0, distribution.DeathsPercentiles.P25, 1e-9)
+	assert.InDelta(t, 8.8, distribution.DeathsPercentiles.P90, 1e-9)
+
+	assert.Zero(t, services.BuildKDADistribution(nil).AverageKDA)
+}
+
+func TestBuildDuoAnalysis(t *testing.T) {
+	duo := services.DuoTeammate{PUUID: "duo", SummonerName: "Partner"}
+	matches := make([]models.MatchData, 0)
+	teammates := make(map[string][]services.DuoTeammate)
+	for i := 0; i < 6; i++ {
+		// Games with the partner are won, games without are lost
+		withDuo := fmt.Sprintf("EUW1_%d", i)
+		matches = append(matches, models.MatchData{MatchID: withDuo, Win: true, Kills: 2, Deaths: 1})
+		teammates[withDuo] = []services.DuoTeammate{duo, {PUUID: fmt.Sprintf("random-%d", i)}}
+
+		solo := fmt.Sprintf("EUW1_solo_%d", i)
+		matches = append(matches, models.MatchData{MatchID: solo, Win: false, Kills: 1, Deaths: 1})
+		teammates[solo] = []services.DuoTeammate{{PUUID: fmt.Sprintf("other-%d", i)}}
+	}
+
+	analysis := services.BuildDuoAnalysis(matches, teammates, 3)
+	assert.Equal(t, 6, analysis.Duo.Games)
+	assert.InDelta(t, 100.0, analysis.Duo.WinRate, 1e-9)
+	assert.InDelta(t, 2.0, analysis.Duo.AverageKDA, 1e-9)
+	assert.Equal(t, 6, analysis.Solo.Games)
+	assert.Equal(t, 6, analysis.Solo.Losses)
+	assert.InDelta(t, 100.0, analysis.WinRateDelta, 1e-9)
+	require.Len(t, analysis.Partners, 1)
+	assert.Equal(t, "Partner", analysis.Partners[0].SummonerName)
+
+	// A threshold above the shared games leaves every game solo
+	analysis = services.BuildDuoAnalysis(matches, teammates, 7)
+	assert.Equal(t, 12, analysis.Solo.Games)
+	assert.Zero(t, analysis.Duo.Games)
+	assert.Zero(t, analysis.WinRateDelta)
+}
+
+func TestBuildCSBenchmarkAnalysis(t *testing.T) {
+	matches := []models.MatchData{
+		{Position: "MIDDLE", TotalCS: 240, GameDuration: 1800},
+		{Position: "MIDDLE", TotalCS: 180, GameDuration: 1800},
+		{Position: "UTILITY", TotalCS: 45, GameDuration: 1800},
+		{Position: "", TotalCS: 300, GameDuration: 1200}, // ARAM has no role
+	}
+
+	analysis := services.BuildCSBenchmarkAnalysis(matches, nil)
+	require.Len(t, analysis.Roles, 2)
+
+	mid := analysis.Roles[0]
+	assert.Equal(t, "MID", mid.Role)
+	assert.Equal(t, 2, mid.Games)
+	assert.Equal(t, 7.0, mid.AverageCSPerMin)
+	assert.Equal(t, 8.0, mid.BestCSPerMin)
+	assert.Equal(t, 7.5, mid.Benchmark)
+	assert.Equal(t, -0.5, mid.Gap)
+	assert.Equal(t, 36.9, mid.Percentile)
+	assert.Equal(t, 1, mid.GamesAtBenchmark)
+
+	support := analysis.Roles[1]
+	assert.Equal(t, "SUPPORT", support.Role)
+	assert.InDelta(t, 0.3, support.Gap, 1e-9)
+	assert.Equal(t, 89.4, support.Percentile)
+
+	// Configured benchmarks replace the defaults
+	analysis = services.BuildCSBenchmarkAnalysis(matches, map[string]float64{"MID": 6.0})
+	require.Len(t, analysis.Roles, 1)
+	assert.Equal(t, 1.0, analysis.Roles[0].Gap)
+	assert.Equal(t, 79.8, analysis.Roles[0].Percentile)
+}
+
+func TestBuildBanAnalysis(t *testing.T) {
+	matches := []models.MatchData{
+		{MatchID: "EUW1_1", Win: true},
+		{MatchID: "EUW1_2", Win: true},
+		{MatchID: "EUW1_3", Win: true},
+		{MatchID: "EUW1_4", Win: false},
+		{MatchID: "EUW1_5", Win: false},
+		{MatchID: "EUW1_6", Win: false}, // Synced before bans were stored
+	}
+	bans := map[string][]int{
+		"EUW1_1": {157, 238},
+		"EUW1_2": {157},
+		"EUW1_3": {157, 238},
+		"EUW1_4": {238},
+		"EUW1_5": {55},
+	}
+
+	analysis := services.BuildBanAnalysis(matches, bans, 2)
+	assert.Equal(t, 5, analysis.GamesWithBans)
+	assert.Equal(t, 3, analysis.Overall.Wins)
+	require.Len(t, analysis.Bans, 2, "one-off bans are left out")
+
+	assert.Equal(t, 157, analysis.Bans[0].ChampionID)
+	assert.Equal(t, 3, analysis.Bans[0].Games)
+	assert.InDelta(t, 100.0, analysis.Bans[0].WinRate, 1e-9)
+	assert.InDelta(t, 60.0, analysis.Bans[0].BanRate, 1e-9)
+	assert.InDelta(t, 100.0, analysis.Bans[0].WinRateDelta, 1e-9) // 100% vs 0%
+
+	assert.Equal(t, 238, analysis.Bans[1].ChampionID)
+	assert.InDelta(t, 200.0/3, analysis.Bans[1].WinRate, 1e-9)
+	assert.InDelta(t, 200.0/3-50, analysis.Bans[1].WinRateDelta, 1e-9)
+
+	empty := services.BuildBanAnalysis(matches, nil, 0)
+	assert.Zero(t, empty.GamesWithBans)
+	assert.Equal(t, services.DefaultBanMinGames, empty.MinGames)
+	assert.Empty(t, empty.Bans)
+}
+
+func TestBuildObjectiveImpact(t *testing.T) {
+	matches := []models.MatchData{
+		{MatchID: "EUW1_1", Win: true},
+		{MatchID: "EUW1_2", Win: true},
+		{MatchID: "EUW1_3", Win: false},
+		{MatchID: "EUW1_4", Win: false},
+		{MatchID: "EUW1_5", Win: true}, // Synced before objectives were stored
+	}
+	objectives := map[string]models.MatchTeamObjectives{
+		"EUW1_1": {FirstTower: true, FirstDragon: true},
+		"EUW1_2": {FirstTower: true, FirstBlood: true},
+		"EUW1_3": {FirstDragon: true, FirstBlood: true},
+		"EUW1_4": {},
+	}
+
+	analysis := services.BuildObjectiveImpact(matches, objectives,
+		[]string{services.ObjectiveFirstBlood, services.ObjectiveFirstTower, services.ObjectiveFirstDragon}, 2)
+	assert.Equal(t, 4, analysis.GamesWithObjectives)
+	assert.Equal(t, 2, analysis.Overall.Wins)
+	require.Len(t, analysis.Objectives, 3)
+
+	// Largest win rate delta first
+	tower := analysis.Objectives[0]
+	assert.Equal(t, services.ObjectiveFirstTower, tower.Objective)
+	assert.True(t, tower.EnoughGames)
+	assert.InDelta(t, 100.0, tower.Secured.WinRate, 1e-9)
+	assert.InDelta(t, 0.0, tower.NotSecured.WinRate, 1e-9)
+	assert.InDelta(t, 50.0, tower.SecureRate, 1e-9)
+	assert.InDelta(t, 100.0, tower.WinRateDelta, 1e-9)
+
+	assert.InDelta(t, 0.0, analysis.Objectives[1].WinRateDelta, 1e-9)
+	assert.InDelta(t, 0.0, analysis.Objectives[2].WinRateDelta, 1e-9)
+
+	// Too few games on either side sorts last whatever the delta
+	strict := services.BuildObjectiveImpact(matches, objectives, nil, 3)
+	require.Len(t, strict.Objectives, len(services.Objectives))
+	for _, impact := range strict.Objectives {
+		assert.False(t, impact.EnoughGames, impact.Objective)
+	}
+
+	empty := services.BuildObjectiveImpact(matches, nil, nil, 0)
+	assert.Zero(t, empty.GamesWithObjectives)
+	assert.Equal(t, services.DefaultObjectiveMinGames, empty.MinGames)
+	assert.Empty(t, empty.Objectives)
+}
+
+func TestParseObjectives(t *testing.T) {
+	objectives, err := services.ParseObjectives("first_dragon, FIRST_BLOOD")
+	require.NoError(t, err)
+	assert.Equal(t, []string{services.ObjectiveFirstBlood, services.ObjectiveFirstDragon}, objectives)
+
+	all, err := services.ParseObjectives("")
+	require.NoError(t, err)
+	assert.Equal(t, services.Objectives, all)
+
+	_, err = services.ParseObjectives("first_blood,first_grub")
+	assert.Error(t, err)
+}
+
+func TestBuildGroupComparison(t *testing.T) {
+	player := []models.MatchData{
+		{Win: true, Kills: 6, Deaths: 2, Assists: 6, TotalCS: 210, GameDuration: 1800},
+		{Win: true, Kills: 4, Deaths: 2, Assists: 2, TotalCS: 180, GameDuration: 1800},
+	}
+	members := []services.GroupMember{
+		{DuoTeammate: services.DuoTeammate{PUUID: "a"}, SharedGames: 3},
+		{DuoTeammate: services.DuoTeammate{PUUID: "b"}, SharedGames: 5},
+		{DuoTeammate: services.DuoTeammate{PUUID: "idle"}, SharedGames: 4},
+	}
+	memberMatches := map[string][]models.MatchData{
+		// Each member counts once however many games they played
+		"a": {{Win: false, Kills: 1, Deaths: 4, Assists: 3, TotalCS: 150, GameDuration: 1800}},
+		"b": {
+			{Win: true, Kills: 2, Deaths: 1, Assists: 2, TotalCS: 240, GameDuration: 1800},
+			{Win: false, Kills: 2, Deaths: 5, Assists: 3, TotalCS: 240, GameDuration: 1800},
+		},
+	}
+
+	metrics, err := services.ParseGroupMetrics("deaths, win_rate,kda")
+	require.NoError(t, err)
+	assert.Equal(t, []string{services.GroupMetricWinRate, services.GroupMetricKDA, services.GroupMetricDeaths}, metrics)
+	_, err = services.ParseGroupMetrics("win_rate,mmr")
+	assert.Error(t, err)
+
+	comparison := services.BuildGroupComparison(player, members, memberMatches, metrics)
+	assert.Equal(t, 2, comparison.Games)
+	require.Len(t, comparison.Members, 3)
+	assert.Equal(t, "b", comparison.Members[0].PUUID)
+	assert.Equal(t, 2, comparison.Members[0].Games)
+	assert.Zero(t, comparison.Members[1].Games)
+
+	require.Len(t, comparison.Metrics, 3)
+	winRate := comparison.Metrics[0]
+	assert.InDelta(t, 100.0, winRate.Player, 1e-9)
+	assert.InDelta(t, 25.0, winRate.GroupAverage, 1e-9) // (0 + 50) / 2
+	assert.InDelta(t, 75.0, winRate.Delta, 1e-9)
+	assert.Equal(t, 1, winRate.Rank)
+
+	// Fewer deaths rank better
+	deaths := comparison.Metrics[2]
+	assert.InDelta(t, 2.0, deaths.Player, 1e-9)
+	assert.InDelta(t, 3.5, deaths.GroupAverage, 1e-9) // (4 + 3) / 2
+	assert.Equal(t, 1, deaths.Rank)
+
+	// Without members there is nothing to compare against
+	alone := services.BuildGroupComparison(player, nil, nil, metrics)
+	assert.Zero(t, alone.Metrics[0].GroupAverage)
+	assert.Zero(t, alone.Metrics[0].Delta)
+}
+
+func TestChampionAggregateIncremental(t *testing.T) {
+	now := time.Now()
+	matches := []models.MatchData{
+		{MatchID: "EUW1_1", Date: now.Add(-3 * time.Hour), ChampionID: 99, ChampionName: "Lux", Position: "MIDDLE", Win: true, Kills: 6, Deaths: 2, Assists: 4, CSPerMinute: 7},
+		{MatchID: "EUW1_2", Date: now.Add(-2 * time.Hour), ChampionID: 99, ChampionName: "Lux", Position: "UTILITY", Win: false, Kills: 1, Deaths: 4, Assists: 10, CSPerMinute: 1},
+		{MatchID: "EUW1_3", Date: now.Add(-1 * time.Hour), ChampionID: 99, ChampionName: "Lux", Position: "UTILITY", Win: true, Kills: 2, Deaths: 0, Assists: 12, CSPerMinute: 2},
+	}
+
+	// Folding matches one sync at a time matches aggregating them all at once
+	incremental := &models.ChampionAggregate{PlayerID: "player", ChampionID: 99}
+	incremental.Add(matches[0])
+	incremental.Add(matches[1])
+	incremental.Add(matches[2])
+
+	stats := services.ChampionStatsFromAggregate(incremental)
+	assert.Equal(t, 3, stats.TotalMatches)
+	assert.Equal(t, 2, stats.Wins)
+	assert.Equal(t, 1, stats.Losses)
+	assert.InDelta(t, 200.0/3, stats.WinRate, 1e-9)
+	assert.InDelta(t, 35.0/6, stats.AverageKDA, 1e-9)
+	assert.InDelta(t, 10.0/3, stats.AverageCSPerMin, 1e-9)
+	assert.Equal(t, "UTILITY", stats.PreferredRole)
+	assert.Equal(t, "EUW1_3", incremental.LastMatchID)
+	assert.True(t, stats.LastPlayed.Equal(matches[2].Date))
+
+	empty := services.ChampionStatsFromAggregate(&models.ChampionAggregate{ChampionID: 1})
+	assert.Zero(t, empty.TotalMatches)
+	assert.Zero(t, empty.WinRate)
+}
+
+func TestMetaTierListMinSample(t *testing.T) {
+	meta := services.NewMetaAnalyticsService(nil)
+	ctx := context.Background()
+
+	full, err := meta.GetTierList(ctx, "14.1", "na1", "GOLD", "")
+	require.NoError(t, err)
+	assert.Zero(t, full.FilteredChampions)
+
+	// The simulated tier list has pick rates from 15% down to 4.2%
+	meta.SetMinSample(0, 10)
+	filtered, err := meta.GetTierList(ctx, "14.1", "na1", "GOLD", "")
+	require.NoError(t, err)
+	assert.Equal(t, 5, filtered.FilteredChampions)
+	assert.Equal(t, 10.0, filtered.MinPickRate)
+
+	ranked := len(filtered.SPlusTier) + len(filtered.STier) + len(filtered.APlusTier) + len(filtered.ATier) +
+		len(filtered.BPlusTier) + len(filtered.BTier) + len(filtered.CPlusTier) + len(filtered.CTier) + len(filtered.DTier)
+	assert.Equal(t, 5, ranked)
+}
+
+func TestBuildTimeOfDayAnalysis(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	require.NoError(t, err)
+
+	// 22:30 UTC in January is 23:30 in Paris; 08:00 UTC is 09:00
+	var matches []models.MatchData
+	for i := 0; i < 5; i++ {
+		matches = append(matches, models.MatchData{Date: time.Date(2024, 1, 10+i, 22, 30, 0, 0, time.UTC), Win: i == 0})
+		matches = append(matches, models.MatchData{Date: time.Date(2024, 1, 10+i, 8, 0, 0, 0, time.UTC), Win: i < 4})
+	}
+
+	analysis := services.BuildTimeOfDayAnalysis(matches, paris, 2)
+	assert.Equal(t, "Europe/Paris", analysis.Timezone)
+	require.Len(t, analysis.Buckets, 12)
+	assert.Equal(t, "22:00-23:59", analysis.Buckets[11].Label)
+	assert.Equal(t, 5, analysis.Buckets[11].Games)
+	assert.Equal(t, 5, analysis.Buckets[4].Games)
+	assert.Equal(t, 10, analysis.Overall.Games)
+
+	require.NotNil(t, analysis.Best)
+	require.NotNil(t, analysis.Worst)
+	assert.Equal(t, 8, analysis.Best.StartHour)
+	assert.Equal(t, 80.0, analysis.Best.WinRate)
+	assert.Equal(t, 22, analysis.Worst.StartHour)
+	assert.Equal(t, 20.0, analysis.Worst.WinRate)
+
+	// Uneven bucket widths fall back to single hours
+	assert.Len(t, services.BuildTimeOfDayAnalysis(nil, nil, 5).Buckets, 24)
+}
+
+func TestEstimateLearningCurve(t *testing.T) {
+	// Ashe (difficulty 1): W L W W L -> 60% after the 5th game
+	// Ahri (difficulty 2): loses the first 6, then wins 8 -> 50% on game 12
+	var matches []models.MatchData
+	for _, win := range []bool{true, false, true, true, false} {
+		matches = append(matches, models.MatchData{ChampionName: "Ashe", Win: win})
+	}
+	for i := 0; i < 14; i++ {
+		matches = append(matches, models.MatchData{ChampionName: "Ahri", Win: i >= 6})
+	}
+
+	ramps := services.BuildChampionRamps(matches, 5, 50)
+	require.Len(t, ramps, 2)
+	assert.Equal(t, "Ahri", ramps[0].Champion)
+	assert.Equal(t, 12, ramps[0].GamesToComfort)
+	assert.Equal(t, 5, ramps[1].GamesToComfort)
+
+	// Ratios 12/25 (weighted double) and 5/10 average 0.49, bounded to 0.5
+	// and shrunk toward 1 over two champions -> 0.75 of the 25-game baseline
+	estimate := services.EstimateLearningCurve(2, ramps)
+	assert.Equal(t, services.LearningBasisPersonal, estimate.Basis)
+	assert.Equal(t, 25, estimate.BaselineGames)
+	assert.InDelta(t, 0.75, estimate.RampFactor, 0.001)
+	assert.Equal(t, 19, estimate.ExpectedGames)
+	assert.ElementsMatch(t, []string{"Ahri", "Ashe"}, estimate.SampleChampions)
+
+	// Without history the difficulty baseline is used as is
+	baseline := services.EstimateLearningCurve(3, nil)
+	assert.Equal(t, services.LearningBasisDifficulty, baseline.Basis)
+	assert.Equal(t, 50, baseline.ExpectedGames)
+}
+
+func TestExtractWardPlacements(t *testing.T) {
+	raw := `{
+		"metadata": {"matchId": "EUW1_1", "participants": ["other", "me"]},
+		"info": {
+			"frameInterval": 60000,
+			"participants": [{"participantId": 1, "puuid": "other"}, {"participantId": 7, "puuid": "me"}],
+			"frames": [
+				{"timestamp": 0, "participantFrames": {"7": {"participantId": 7, "position": {"x": 14000, "y": 14000}}}, "events": []},
+				{"timestamp": 60000, "participantFrames": {"7": {"participantId": 7, "position": {"x": 9800, "y": 4400}}}, "events": [
+					{"type": "WARD_PLACED", "timestamp": 70000, "creatorId": 7, "wardType": "YELLOW_TRINKET"},
+					{"type": "WARD_PLACED", "timestamp": 80000, "creatorId": 1, "wardType": "CONTROL_WARD"},
+					{"type": "WARD_PLACED", "timestamp": 90000, "creatorId": 7, "wardType": "UNDEFINED"}
+				]},
+				{"timestamp": 1800000, "participantFrames": {"7": {"participantId": 7, "position": {"x": 5000, "y": 10500}}}, "events": [
+					{"type": "WARD_PLACED", "timestamp": 1790000, "creatorId": 7, "wardType": "CONTROL_WARD"}
+				]}
+			]
+		}
+	}`
+	var timeline services.MatchTimeline
+	require.NoError(t, json.Unmarshal([]byte(raw), &timeline))
+
+	wards := services.ExtractWardPlacements(&timeline, "me")
+	require.Len(t, wards, 2)
+
+	assert.Equal(t, "YELLOW", wards[0].WardType)
+	assert.Equal(t, 9800, wards[0].X) // nearest frame position
+	assert.Equal(t, 4400, wards[0].Y)
+	assert.Equal(t, "early", wards[0].GamePhase)
+	assert.Equal(t, "RED", wards[0].MapSide)
+	assert.Equal(t, "me", wards[0].PlayerID)
+	assert.Equal(t, "EUW1_1", wards[0].MatchID)
+
+	assert.Equal(t, "CONTROL", wards[1].WardType)
+	assert.Equal(t, 5000, wards[1].X)
+	assert.Equal(t, "late", wards[1].GamePhase)
+	assert.Equal(t, 1790, wards[1].Timestamp)
+
+	assert.Empty(t, services.ExtractWardPlacements(&timeline, "missing"))
+}
+
+func TestExtractTimelineFrames(t *testing.T) {
+	rawTimeline := `{
+		"metadata": {"matchId": "EUW1_2", "participants": ["me", "enemy"]},
+		"info": {
+			"frameInterval": 60000,
+			"participants": [{"participantId": 2, "puuid": "me"}, {"participantId": 7, "puuid": "enemy"}],
+			"frames": [
+				{"timestamp": 0, "participantFrames": {
+					"2": {"totalGold": 500, "xp": 0, "minionsKilled": 0},
+					"7": {"totalGold": 500, "xp": 0, "minionsKilled": 0}
+				}},
+				{"timestamp": 60021, "participantFrames": {
+					"2": {"totalGold": 1400, "xp": 980, "minionsKilled": 14, "jungleMinionsKilled": 2},
+					"7": {"totalGold": 1100, "xp": 1020, "minionsKilled": 11}
+				}},
+				{"timestamp": 180043, "participantFrames": {
+					"2": {"totalGold": 2600, "xp": 2900, "minionsKilled": 30},
+					"7": {"totalGold": 2500, "xp": 2800, "minionsKilled": 28}
+				}},
+				{"timestamp": 195310, "participantFrames": {
+					"2": {"totalGold": 2700, "xp": 3000, "minionsKilled": 31},
+					"7": {"totalGold": 2550, "xp": 2850, "minionsKilled": 29}
+				}}
+			]
+		}
+	}`
+	rawMatch := `{
+		"metadata": {"matchId": "EUW1_2"},
+		"info": {"participants": [
+			{"participantId": 2, "puuid": "me", "teamId": 100, "teamPosition": "MIDDLE"},
+			{"participantId": 6, "puuid": "top", "teamId": 200, "teamPosition": "TOP"},
+			{"participantId": 7, "puuid": "enemy", "teamId": 200, "teamPosition": "MIDDLE"}
+		]}
+	}`
+	var timeline services.MatchTimeline
+	require.NoError(t, json.Unmarshal([]byte(rawTimeline), &timeline))
+	var match services.MatchDetails
+	require.NoError(t, json.Unmarshal([]byte(rawMatch), &match))
+
+	// Minutes come from the timestamps: the minute 2 frame is missing and the
+	// end of game frame falls in minute 3, which is already recorded
+	frames := services.ExtractTimelineFrames(&timeline, &match, "user-1", "me")
+	require.Len(t, frames, 3)
+	assert.Equal(t, 1, frames[1].Minute)
+	assert.Equal(t, 3, frames[2].Minute)
+	assert.Equal(t, 2600, frames[2].Gold)
+	assert.Equal(t, "user-1", frames[1].UserID)
+	assert.Equal(t, "EUW1_2", frames[1].MatchID)
+	assert.Equal(t, 16, frames[1].CS)
+	assert.True(t, frames[1].HasOpponent)
+	assert.Equal(t, 1100, frames[1].OpponentGold)
+
+	series := services.BuildMatchTimelineSeries("EUW1_2", frames)
+	assert.True(t, series.HasOpponent)
+	assert.Equal(t, 300, series.Minutes[1].GoldDiff)
+	assert.Equal(t, 5, series.Minutes[1].CSDiff)
+	assert.Equal(t, -40, series.Minutes[1].XPDiff)
+
+	// Without a position there is no lane opponent
+	match.Info.Participants[0].TeamPosition = ""
+	frames = services.ExtractTimelineFrames(&timeline, &match, "user-1", "me")
+	require.Len(t, frames, 3)
+	assert.False(t, frames[1].HasOpponent)
+	assert.False(t, services.BuildMatchTimelineSeries("EUW1_2", frames).HasOpponent)
+
+	assert.Empty(t, services.ExtractTimelineFrames(&timeline, &match, "user-1", "missing"))
+}
+
 func TestKDACalculation(t *testing.T) {
 	testCases := []struct {
 		name     string
@@ -394,7 +1080,7 @@ func BenchmarkKDAAnalysis(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = service.AnalyzeKDA(ctx, "test-player", "30d", "")
+		_, _ = service.AnalyzeKDA(ctx, "test-player", "30d", "", services.MatchFilter{})
 	}
 }
 
@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/herald-lol/herald/backend/internal/logger"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// Snapshot cadence
+const (
+	// snapshotWindowDays is the rolling window each snapshot aggregates
+	snapshotWindowDays = 30
+	// snapshotInterval is the minimum time between two snapshots of a player
+	snapshotInterval = 24 * time.Hour
+)
+
+// ErrNoSnapshots is returned when a player has no recorded snapshots yet
+var ErrNoSnapshots = errors.New("no analytics snapshots recorded")
+
+// SnapshotDiff compares the snapshots nearest to two dates
+type SnapshotDiff struct {
+	PlayerID string                    `json:"player_id"`
+	From     *models.AnalyticsSnapshot `json:"from"`
+	To       *models.AnalyticsSnapshot `json:"to"`
+
+	GamesDelta   int     `json:"games_delta"`
+	WinRateDelta float64 `json:"win_rate_delta"` // percentage points
+	KDADelta     float64 `json:"kda_delta"`
+
+	RankFrom string `json:"rank_from,omitempty"`
+	RankTo   string `json:"rank_to,omitempty"`
+	// LP-equivalent climb across tiers and divisions, nil when either rank is unknown
+	RankPointsDelta *int `json:"rank_points_delta,omitempty"`
+
+	ChampionPoolDelta int      `json:"champion_pool_delta"`
+	ChampionsAdded    []string `json:"champions_added"`
+	ChampionsDropped  []string `json:"champions_dropped"`
+}
+
+// RecordSnapshot stores the rolling 30-day aggregates of every Riot account
+// linked to the user playerID. At most one snapshot is kept per
+// snapshotInterval; a recent one is returned unchanged.
+func (as *AnalyticsService) RecordSnapshot(ctx context.Context, playerID string) (*models.AnalyticsSnapshot, error) {
+	latest, err := as.playerRepo.LatestAnalyticsSnapshot(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && time.Since(latest.TakenAt) < snapshotInterval {
+		return latest, nil
+	}
+
+	now := time.Now()
+	matches, err := as.loadAccountMatches(ctx, playerID, now.AddDate(0, 0, -snapshotWindowDays), now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player matches: %w", err)
+	}
+	stats := as.aggregatePlayerStats(playerID, fmt.Sprintf("%dd", snapshotWindowDays), matches)
+
+	champions := make(map[string]bool)
+	for _, match := range matches {
+		if match.ChampionName != "" {
+			champions[match.ChampionName] = true
+		}
+	}
+
+	snapshot := &models.AnalyticsSnapshot{
+		PlayerID:     playerID,
+		TakenAt:      now,
+		Games:        stats.TotalMatches,
+		WinRate:      stats.WinRate,
+		AverageKDA:   stats.AverageKDA,
+		Tier:         stats.CurrentTier,
+		Division:     stats.CurrentRank,
+		LeaguePoints: stats.LeaguePoints,
+		Champions:    sortedKeys(champions),
+	}
+	if err := as.playerRepo.SaveAnalyticsSnapshot(ctx, snapshot); err != nil {
+		return nil, err
+	}
+
+	return snapshot, nil
+}
+
+// SnapshotAfterSync records a snapshot in the background. It is used as a
+// RiotService sync hook so snapshots follow the user's sync cadence.
+func (as *AnalyticsService) SnapshotAfterSync(playerID string) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		if _, err := as.RecordSnapshot(ctx, playerID); err != nil {
+			logger.Warnf("Failed to record analytics snapshot for %s: %v", playerID, err)
+		}
+	}()
+}
+
+// GetSnapshotDiff compares the snapshots taken nearest to from and to
+func (as *AnalyticsService) GetSnapshotDiff(ctx context.Context, playerID string, from, to time.Time) (*SnapshotDiff, error) {
+	fromSnapshot, err := as.playerRepo.NearestAnalyticsSnapshot(ctx, playerID, from)
+	if err != nil {
+		return nil, err
+	}
+	toSnapshot, err := as.playerRepo.NearestAnalyticsSnapshot(ctx, playerID, to)
+	if err != nil {
+		return nil, err
+	}
+	if fromSnapshot == nil || toSnapshot == nil {
+		return nil, ErrNoSnapshots
+	}
+
+	return diffSnapshots(playerID, fromSnapshot, toSnapshot), nil
+}
+
+func diffSnapshots(playerID string, from, to *models.AnalyticsSnapshot) *SnapshotDiff {
+	diff := &SnapshotDiff{
+		PlayerID:          playerID,
+		From:              from,
+		To:                to,
+		GamesDelta:        to.Games - from.Games,
+		WinRateDelta:      to.WinRate - from.WinRate,
+		KDADelta:          to.AverageKDA - from.AverageKDA,
+		RankFrom:          strings.TrimSpace(from.Tier + " " + from.Division),
+		RankTo:            strings.TrimSpace(to.Tier + " " + to.Division),
+		ChampionPoolDelta: len(to.Champions) - len(from.Champions),
+		ChampionsAdded:    []string{},
+		ChampionsDropped:  []string{},
+	}
+
+	fromScore, fromOK := rankPoints(from.Tier, from.Division, from.LeaguePoints)
+	toScore, toOK := rankPoints(to.Tier, to.Division, to.LeaguePoints)
+	if fromOK && toOK {
+		delta := toScore - fromScore
+		diff.RankPointsDelta = &delta
+	}
+
+	before := make(map[string]bool, len(from.Champions))
+	for _, champion := range from.Champions {
+		before[champion] = true
+	}
+	after := make(map[string]bool, len(to.Champions))
+	for _, champion := range to.Champions {
+		after[champion] = true
+		if !before[champion] {
+			diff.ChampionsAdded = append(diff.ChampionsAdded, champion)
+		}
+	}
+	for _, champion := range from.Champions {
+		if !after[champion] {
+			diff.ChampionsDropped = append(diff.ChampionsDropped, champion)
+		}
+	}
+
+	return diff
+}
+
+// rankTiers lists ranked tiers from lowest to highest
+var rankTiers = []string{"IRON", "BRONZE", "SILVER", "GOLD", "PLATINUM", "EMERALD", "DIAMOND", "MASTER", "GRANDMASTER", "CHALLENGER"}
+
+// rankPoints converts a rank to a single LP-equivalent number, counting 100 LP
+// per division and four divisions per tier. Apex tiers share one ladder where
+// only LP matters.
+func rankPoints(tier, division string, lp int) (int, bool) {
+	tier = strings.ToUpper(strings.TrimSpace(tier))
+	index := -1
+	for i, t := range rankTiers {
+		if t == tier {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return 0, false
+	}
+
+	apex := 7 // MASTER
+	if index >= apex {
+		return apex*400 + lp, true
+	}
+
+	divisions := map[string]int{"IV": 0, "III": 1, "II": 2, "I": 3}
+	step, ok := divisions[strings.ToUpper(strings.TrimSpace(division))]
+	if !ok {
+		return 0, false
+	}
+
+	return index*400 + step*100 + lp, true
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
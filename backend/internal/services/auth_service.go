@@ -120,6 +120,7 @@ func (s *AuthService) Register(req RegisterRequest) (*AuthResponse, error) {
 		RankChangeNotifications: true,
 		AutoSyncMatches:         true,
 		SyncInterval:            300,
+		MaxSyncMatches:          DefaultMaxSyncMatches,
 		IncludeNormalGames:      true,
 		IncludeARAMGames:        true,
 		PublicProfile:           true,
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/logger"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// AutoSyncService periodically syncs match history for every linked account
+// whose owner has auto sync enabled. Accounts are synced concurrently by a
+// bounded worker pool; all workers share the RiotService per-region rate
+// limiters, so Riot limits are respected globally regardless of pool size.
+type AutoSyncService struct {
+	db          *gorm.DB
+	riotService *RiotService
+
+	interval time.Duration
+	workers  int
+
+	lastCycle *AutoSyncCycleStats
+	statsMu   sync.RWMutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// AutoSyncCycleStats summarizes one pass over all auto-synced accounts
+type AutoSyncCycleStats struct {
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Accounts  int           `json:"accounts"`
+	Synced    int           `json:"synced"`
+	Failed    int           `json:"failed"`
+	Skipped   int           `json:"skipped"` // the user was already syncing
+	Workers   int           `json:"workers"`
+}
+
+// autoSyncAccount is the minimal account data a worker needs
+type autoSyncAccount struct {
+	ID     string
+	UserID string
+}
+
+// NewAutoSyncService creates an auto sync service; workers below 1 default to 1
+func NewAutoSyncService(db *gorm.DB, riotService *RiotService, interval time.Duration, workers int) *AutoSyncService {
+	if workers < 1 {
+		workers = 1
+	}
+	if interval <= 0 {
+		interval = 30 * time.Minute
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &AutoSyncService{
+		db:          db,
+		riotService: riotService,
+		interval:    interval,
+		workers:     workers,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Start runs a sync cycle immediately and then on every interval
+func (s *AutoSyncService) Start() {
+	logger.Infof("Starting auto sync service (interval %s, %d workers)", s.interval, s.workers)
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.RunCycle(s.ctx)
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.RunCycle(s.ctx)
+			}
+		}
+	}()
+}
+
+// Stop cancels the running cycle and stops scheduling new ones
+func (s *AutoSyncService) Stop() {
+	logger.Infof("Stopping auto sync service...")
+	s.cancel()
+}
+
+// LastCycle returns statistics for the most recently completed cycle
+func (s *AutoSyncService) LastCycle() *AutoSyncCycleStats {
+	s.statsMu.RLock()
+	defer s.statsMu.RUnlock()
+	return s.lastCycle
+}
+
+// RunCycle syncs every eligible account once using the worker pool
+func (s *AutoSyncService) RunCycle(ctx context.Context) *AutoSyncCycleStats {
+	stats := &AutoSyncCycleStats{
+		StartedAt: time.Now(),
+		Workers:   s.workers,
+	}
+
+	accounts, err := s.loadAccounts(ctx)
+	if err != nil {
+		logger.Errorf("Auto sync: failed to load accounts: %v", err)
+		return stats
+	}
+	stats.Accounts = len(accounts)
+
+	jobs := make(chan autoSyncAccount)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for account := range jobs {
+				err := s.riotService.SyncMatchHistory(ctx, account.UserID, account.ID, 0, MatchListFilter{})
+
+				skipped := errors.Is(err, ErrSyncInProgress)
+
+				mu.Lock()
+				switch {
+				case skipped:
+					stats.Skipped++
+				case err != nil:
+					stats.Failed++
+				default:
+					stats.Synced++
+				}
+				mu.Unlock()
+
+				if err != nil && !skipped {
+					logger.Warnf("Auto sync: account %s failed: %v", account.ID, err)
+				}
+			}
+		}()
+	}
+
+	for _, account := range accounts {
+		select {
+		case jobs <- account:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	stats.Duration = time.Since(stats.StartedAt)
+	logger.Infof("Auto sync cycle finished in %s: %d accounts, %d synced, %d failed, %d skipped (%d workers)",
+		stats.Duration.Round(time.Millisecond), stats.Accounts, stats.Synced, stats.Failed, stats.Skipped, stats.Workers)
+
+	s.statsMu.Lock()
+	s.lastCycle = stats
+	s.statsMu.Unlock()
+
+	return stats
+}
+
+// loadAccounts returns linked accounts whose owner has auto sync enabled,
+// least recently synced first so stale accounts are picked up early
+func (s *AutoSyncService) loadAccounts(ctx context.Context) ([]autoSyncAccount, error) {
+	var riotAccounts []models.RiotAccount
+	err := s.db.WithContext(ctx).
+		Where("user_id NOT IN (?)", s.db.Model(&models.UserPreferences{}).
+			Select("user_id").Where("auto_sync_matches = ?", false)).
+		Order("last_sync_at ASC").
+		Find(&riotAccounts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	accounts := make([]autoSyncAccount, 0, len(riotAccounts))
+	for _, account := range riotAccounts {
+		accounts = append(accounts, autoSyncAccount{
+			ID:     account.ID.String(),
+			UserID: account.UserID.String(),
+		})
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// DefaultBanMinGames is how many of the player's games a champion must have
+// been banned by their team in before its ban win rate is reported
+const DefaultBanMinGames = 3
+
+// BanStats is the player's record in games where their team banned a champion
+type BanStats struct {
+	ChampionID   int    `json:"champion_id"`
+	ChampionName string `json:"champion_name,omitempty"`
+	QueueSplitStats
+	// BanRate is the share of the player's games with ban data in which
+	// their team banned the champion, in percent
+	BanRate float64 `json:"ban_rate"`
+	// WinRateDelta is the win rate with the ban minus the win rate in games
+	// with ban data where the champion was not banned, in percentage points
+	WinRateDelta float64 `json:"win_rate_delta"`
+}
+
+// BanEffectivenessAnalysis relates the champions the player's team banned to
+// the results of those games
+type BanEffectivenessAnalysis struct {
+	PlayerID  string `json:"player_id"`
+	TimeRange string `json:"time_range"`
+	MinGames  int    `json:"min_games"`
+	// Games with stored ban data; matches synced before bans were recorded
+	// and modes without bans are left out
+	GamesWithBans int             `json:"games_with_bans"`
+	Overall       QueueSplitStats `json:"overall"`
+	Bans          []BanStats      `json:"bans"`
+}
+
+// AnalyzeBans reports the win rate of the user's linked accounts in games
+// where their team banned each champion. minGames overrides the configured threshold when positive.
+func (as *AnalyticsService) AnalyzeBans(ctx context.Context, playerID, timeRange string, minGames int, filter MatchFilter) (*BanEffectivenessAnalysis, error) {
+	filter = filter.normalize()
+	if minGames < 1 {
+		minGames = as.banMinGames
+	}
+	startDate, endDate := as.parseTimeRange(timeRange)
+
+	matches, _, err := as.getAccountFilteredMatches(ctx, playerID, startDate, endDate, "", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	bans, err := as.getMatchTeamBans(ctx, playerID, startDate.UnixMilli(), endDate.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bans: %w", err)
+	}
+
+	analysis := BuildBanAnalysis(matches, bans, minGames)
+	analysis.PlayerID = playerID
+	analysis.TimeRange = timeRange
+	return analysis, nil
+}
+
+// SetBanMinGames sets how many team bans of a champion are needed before its
+// ban win rate is reported; values below 1 restore the default
+func (as *AnalyticsService) SetBanMinGames(games int) {
+	if games < 1 {
+		games = DefaultBanMinGames
+	}
+	as.banMinGames = games
+}
+
+// getMatchTeamBans returns the champion IDs banned by the team of each Riot
+// account linked to the user playerID, keyed by Riot match ID, for matches
+// started between start and end (Unix milliseconds). Matches without stored
+// bans are absent.
+func (as *AnalyticsService) getMatchTeamBans(ctx context.Context, playerID string, start, end int64) (map[string][]int, error) {
+	puuids, err := as.accountPUUIDs(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT m.match_id, b.champion_id
+		FROM match_participants mp
+		JOIN matches m ON mp.match_id = m.id
+		JOIN match_bans b ON b.match_id = mp.match_id
+			AND b.team_id = mp.team_id
+		WHERE mp.puuid = $1
+		AND m.game_start_timestamp BETWEEN $2 AND $3
+	`
+
+	bans := make(map[string][]int)
+	for _, puuid := range puuids {
+		if err := as.scanMatchTeamBans(ctx, bans, query, puuid, start, end); err != nil {
+			return nil, err
+		}
+	}
+	return bans, nil
+}
+
+func (as *AnalyticsService) scanMatchTeamBans(ctx context.Context, bans map[string][]int, query, puuid string, start, end int64) error {
+	rows, err := as.db.QueryContext(ctx, query, puuid, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to query bans: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var matchID string
+		var championID int
+		if err := rows.Scan(&matchID, &championID); err != nil {
+			return fmt.Errorf("failed to scan ban: %w", err)
+		}
+		bans[matchID] = append(bans[matchID], championID)
+	}
+	return rows.Err()
+}
+
+// BuildBanAnalysis computes the record with and without each champion banned
+// over the matches that have ban data, listing champions banned in at least
+// minGames of them, most banned first. bans is keyed by MatchData.MatchID.
+func BuildBanAnalysis(matches []models.MatchData, bans map[string][]int, minGames int) *BanEffectivenessAnalysis {
+	if minGames < 1 {
+		minGames = DefaultBanMinGames
+	}
+
+	var overall splitAccumulator
+	banned := make(map[int]*splitAccumulator)
+	for _, match := range matches {
+		championIDs, ok := bans[match.MatchID]
+		if !ok {
+			continue
+		}
+		overall.add(match)
+		for _, championID := range championIDs {
+			if banned[championID] == nil {
+				banned[championID] = &splitAccumulator{}
+			}
+			banned[championID].add(match)
+		}
+	}
+
+	analysis := &BanEffectivenessAnalysis{
+		MinGames:      minGames,
+		GamesWithBans: overall.games,
+		Overall:       overall.stats(),
+		Bans:          make([]BanStats, 0),
+	}
+	for championID, record := range banned {
+		if record.games < minGames {
+			continue
+		}
+		stats := BanStats{
+			ChampionID:      championID,
+			ChampionName:    models.ChampionNameByID(championID),
+			QueueSplitStats: record.stats(),
+			BanRate:         float64(record.games) / float64(overall.games) * 100,
+		}
+		// Compare against the games where the champion was not banned
+		if rest := overall.games - record.games; rest > 0 {
+			restWinRate := float64(overall.wins-record.wins) / float64(rest) * 100
+			stats.WinRateDelta = stats.WinRate - restWinRate
+		}
+		analysis.Bans = append(analysis.Bans, stats)
+	}
+	sort.Slice(analysis.Bans, func(i, j int) bool {
+		if analysis.Bans[i].Games != analysis.Bans[j].Games {
+			return analysis.Bans[i].Games > analysis.Bans[j].Games
+		}
+		return analysis.Bans[i].ChampionID < analysis.Bans[j].ChampionID
+	})
+
+	return analysis
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/logger"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// CacheWarmupService warms the analytics cache of every active user's linked
+// Riot accounts, e.g. after a deploy, so the first loads don't all hit a
+// cold cache at once.
+// Users are warmed concurrently by a bounded worker pool; the latest run's
+// progress can be polled while it runs.
+type CacheWarmupService struct {
+	db        *gorm.DB
+	analytics *AnalyticsService
+
+	workers    int
+	activeDays int
+
+	job   *CacheWarmupJob
+	jobMu sync.RWMutex
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// CacheWarmupJob reports the progress of a cache warmup run
+type CacheWarmupJob struct {
+	Status      string     `json:"status"` // "running", "completed", "failed"
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Users       int        `json:"users"`
+	Processed   int        `json:"processed"`
+	Warmed      int        `json:"warmed"`
+	Failed      int        `json:"failed"`
+	Skipped     int        `json:"skipped"` // every account of the user was already being warmed
+	Workers     int        `json:"workers"`
+	ActiveDays  int        `json:"active_days"`
+	Limit       int        `json:"limit,omitempty"` // only the most recently active users; 0 for all
+	Error       string     `json:"error,omitempty"`
+}
+
+// ErrCacheWarmupRunning is returned when a warmup run is already in progress
+var ErrCacheWarmupRunning = fmt.Errorf("cache warmup already in progress")
+
+// NewCacheWarmupService creates a cache warmup service; workers below 1
+// default to 1 and activeDays below 1 to 30
+func NewCacheWarmupService(db *gorm.DB, analytics *AnalyticsService, workers, activeDays int) *CacheWarmupService {
+	if workers < 1 {
+		workers = 1
+	}
+	if activeDays < 1 {
+		activeDays = 30
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &CacheWarmupService{
+		db:         db,
+		analytics:  analytics,
+		workers:    workers,
+		activeDays: activeDays,
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+}
+
+// Start warms every active user's analytics in the background; poll Progress
+func (s *CacheWarmupService) Start() (*CacheWarmupJob, error) {
+	return s.StartTop(0)
+}
+
+// StartTop warms the analytics of the limit most recently active users in
+// the background, or of every active user when limit is 0
+func (s *CacheWarmupService) StartTop(limit int) (*CacheWarmupJob, error) {
+	s.jobMu.Lock()
+	if s.job != nil && s.job.Status == "running" {
+		s.jobMu.Unlock()
+		return nil, ErrCacheWarmupRunning
+	}
+
+	job := &CacheWarmupJob{
+		Status:     "running",
+		StartedAt:  time.Now(),
+		Workers:    s.workers,
+		ActiveDays: s.activeDays,
+		Limit:      max(limit, 0),
+	}
+	s.job = job
+	snapshot := *job
+	s.jobMu.Unlock()
+
+	go s.run(s.ctx, job)
+
+	return &snapshot, nil
+}
+
+// StartAfterBoot runs StartTop(limit) once delay has passed, leaving the
+// server time to come up before the warmup competes with it for the
+// database. It returns immediately; Stop cancels the pending run.
+func (s *CacheWarmupService) StartAfterBoot(delay time.Duration, limit int) {
+	go func() {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-s.ctx.Done():
+			return
+		}
+
+		if _, err := s.StartTop(limit); err != nil {
+			logger.Warnf("Startup cache warmup not started: %v", err)
+			return
+		}
+		logger.Infof("Startup cache warmup started for up to %d users", limit)
+	}()
+}
+
+// Progress returns the latest warmup run, if any
+func (s *CacheWarmupService) Progress() (*CacheWarmupJob, bool) {
+	s.jobMu.RLock()
+	defer s.jobMu.RUnlock()
+
+	if s.job == nil {
+		return nil, false
+	}
+	snapshot := *s.job
+	return &snapshot, true
+}
+
+// Stop cancels a running warmup
+func (s *CacheWarmupService) Stop() {
+	s.cancel()
+}
+
+func (s *CacheWarmupService) run(ctx context.Context, job *CacheWarmupJob) {
+	userIDs, err := s.loadActiveUsers(ctx, job.Limit)
+	if err != nil {
+		s.finish(job, fmt.Errorf("failed to load active users: %w", err))
+		return
+	}
+
+	s.jobMu.Lock()
+	job.Users = len(userIDs)
+	s.jobMu.Unlock()
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for userID := range jobs {
+				err := s.analytics.WarmupUserAnalytics(ctx, userID)
+
+				skipped := errors.Is(err, ErrWarmupInProgress)
+
+				s.jobMu.Lock()
+				job.Processed++
+				switch {
+				case skipped:
+					job.Skipped++
+				case err != nil:
+					job.Failed++
+				default:
+					job.Warmed++
+				}
+				s.jobMu.Unlock()
+
+				if err != nil && !skipped {
+					logger.Warnf("Cache warmup: user %s failed: %v", userID, err)
+				}
+			}
+		}()
+	}
+
+	for _, userID := range userIDs {
+		select {
+		case jobs <- userID:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	s.finish(job, ctx.Err())
+}
+
+func (s *CacheWarmupService) finish(job *CacheWarmupJob, err error) {
+	s.jobMu.Lock()
+	defer s.jobMu.Unlock()
+
+	completedAt := time.Now()
+	job.CompletedAt = &completedAt
+	if err != nil {
+		job.Status = "failed"
+		job.Error = err.Error()
+		logger.Errorf("Cache warmup failed after %d of %d users: %v", job.Processed, job.Users, err)
+		return
+	}
+	job.Status = "completed"
+	logger.Infof("Cache warmup finished in %s: %d users, %d warmed, %d failed, %d skipped (%d workers)",
+		completedAt.Sub(job.StartedAt).Round(time.Millisecond), job.Users, job.Warmed, job.Failed, job.Skipped, job.Workers)
+}
+
+// loadActiveUsers returns the IDs of active users who logged in within the
+// last activeDays, most recent first so the busiest users are warmed early.
+// A positive limit keeps only that many.
+func (s *CacheWarmupService) loadActiveUsers(ctx context.Context, limit int) ([]string, error) {
+	var userIDs []string
+	query := s.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("is_active = ? AND last_login_at >= ?", true, time.Now().AddDate(0, 0, -s.activeDays)).
+		Order("last_login_at DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Pluck("id", &userIDs).Error
+	return userIDs, err
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// createWarmupUser stores a user who last logged in daysAgo
+func createWarmupUser(t *testing.T, db *gorm.DB, userID string, daysAgo int) {
+	lastLogin := time.Now().AddDate(0, 0, -daysAgo)
+	require.NoError(t, db.Create(&models.User{
+		ID:           userID,
+		Email:        userID + "@herald.lol",
+		Username:     userID,
+		PasswordHash: "hash",
+		IsActive:     true,
+		LastLoginAt:  &lastLogin,
+	}).Error)
+}
+
+// waitForWarmup polls the service until the latest run is no longer running
+func waitForWarmup(t *testing.T, s *CacheWarmupService) *CacheWarmupJob {
+	var job *CacheWarmupJob
+	require.Eventually(t, func() bool {
+		var ok bool
+		job, ok = s.Progress()
+		return ok && job.Status != "running"
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestCacheWarmupProgress(t *testing.T) {
+	gormDB, db := newAccountTestGormDB(t)
+	for i := 1; i <= 3; i++ {
+		userID := fmt.Sprintf("user-%d", i)
+		createWarmupUser(t, gormDB, userID, i)
+		puuid := fmt.Sprintf("puuid-%d", i)
+		linkAccount(t, db, userID, puuid)
+		insertAccountMatch(t, db, fmt.Sprintf("EUW1_%d", i), puuid, "Ahri", true, i)
+	}
+	// Not logged in within the active window
+	createWarmupUser(t, gormDB, "user-idle", 90)
+
+	analytics := NewAnalyticsService(db, nil)
+	s := NewCacheWarmupService(gormDB, analytics, 2, 30)
+	defer s.Stop()
+
+	_, ok := s.Progress()
+	assert.False(t, ok, "no run before the first start")
+
+	started, err := s.Start()
+	require.NoError(t, err)
+	assert.Equal(t, "running", started.Status)
+	assert.Equal(t, 2, started.Workers)
+
+	job := waitForWarmup(t, s)
+	assert.Equal(t, "completed", job.Status)
+	assert.Equal(t, 3, job.Users)
+	assert.Equal(t, 3, job.Processed)
+	assert.Equal(t, 3, job.Warmed)
+	assert.Zero(t, job.Failed)
+	require.NotNil(t, job.CompletedAt)
+
+	// Each linked account was warmed under its PUUID
+	aggregates, err := analytics.playerRepo.ListChampionAggregates(context.Background(), "puuid-2")
+	require.NoError(t, err)
+	assert.Len(t, aggregates, 1)
+
+	// A limited run only warms the most recently active users
+	_, err = s.StartTop(1)
+	require.NoError(t, err)
+	job = waitForWarmup(t, s)
+	assert.Equal(t, "completed", job.Status)
+	assert.Equal(t, 1, job.Limit)
+	assert.Equal(t, 1, job.Users)
+}
+
+func TestCacheWarmupCancellation(t *testing.T) {
+	gormDB, db := newAccountTestGormDB(t)
+	createWarmupUser(t, gormDB, "user-1", 1)
+
+	s := NewCacheWarmupService(gormDB, NewAnalyticsService(db, nil), 1, 30)
+	s.Stop()
+
+	_, err := s.Start()
+	require.NoError(t, err)
+
+	job := waitForWarmup(t, s)
+	assert.Equal(t, "failed", job.Status)
+	assert.Contains(t, job.Error, context.Canceled.Error())
+	assert.Zero(t, job.Processed)
+
+	// A finished run, even a failed one, doesn't block the next start
+	_, err = s.Start()
+	assert.NotErrorIs(t, err, ErrCacheWarmupRunning)
+	waitForWarmup(t, s)
+}
@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+	"github.com/herald-lol/herald/backend/internal/repository"
+)
+
+// playerLocks hands out one mutex per player. A player's entry lives only
+// while someone holds or waits for it, so the map doesn't grow with every
+// player ever updated. The zero value is ready to use.
+type playerLocks struct {
+	mu    sync.Mutex
+	locks map[string]*playerLock
+}
+
+type playerLock struct {
+	mu      sync.Mutex
+	waiters int // holders and waiters, guarded by playerLocks.mu
+}
+
+// lock takes the player's mutex and returns the function that releases it
+func (l *playerLocks) lock(playerID string) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*playerLock)
+	}
+	entry, ok := l.locks[playerID]
+	if !ok {
+		entry = &playerLock{}
+		l.locks[playerID] = entry
+	}
+	entry.waiters++
+	l.mu.Unlock()
+
+	entry.mu.Lock()
+	return func() {
+		entry.mu.Unlock()
+
+		l.mu.Lock()
+		entry.waiters--
+		if entry.waiters == 0 {
+			delete(l.locks, playerID)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// UpdateChampionStats folds the player's matches newer than their last
+// aggregated match into the running all-time champion aggregates and returns
+// how many matches were added. It only reads new matches, so refreshing after
+// a sync stays cheap for long histories; StartStatsRebuild clears the
+// aggregates and recomputes them from every match for recovery, e.g. after
+// older matches were backfilled.
+func (as *AnalyticsService) UpdateChampionStats(ctx context.Context, playerID string) (int, error) {
+	unlock := as.aggregateLocks.lock(playerID)
+	defer unlock()
+
+	aggregates, err := as.playerRepo.ListChampionAggregates(ctx, playerID)
+	if err != nil {
+		return 0, err
+	}
+
+	cursor := latestAggregatedMatch(aggregates)
+	changed := make(map[int]bool)
+	added := 0
+	err = as.forEachMatchBatchAfter(ctx, playerID, championStatsEpoch, time.Now(), cursor, func(batch []models.MatchData) error {
+		for _, match := range batch {
+			agg, ok := aggregates[match.ChampionID]
+			if !ok {
+				agg = &models.ChampionAggregate{PlayerID: playerID, ChampionID: match.ChampionID}
+				aggregates[match.ChampionID] = agg
+			}
+			agg.Add(match)
+			changed[match.ChampionID] = true
+			added++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get new matches: %w", err)
+	}
+
+	now := time.Now()
+	for championID := range changed {
+		agg := aggregates[championID]
+		agg.UpdatedAt = now
+		if err := as.playerRepo.SaveChampionAggregate(ctx, agg); err != nil {
+			return 0, err
+		}
+	}
+
+	return added, nil
+}
+
+// allTimeChampionStats brings the aggregates up to date and returns the
+// champion's all-time stats from them
+func (as *AnalyticsService) allTimeChampionStats(ctx context.Context, playerID string, championID int) (*models.ChampionStats, error) {
+	if _, err := as.UpdateChampionStats(ctx, playerID); err != nil {
+		return nil, err
+	}
+
+	aggregates, err := as.playerRepo.ListChampionAggregates(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	agg, ok := aggregates[championID]
+	if !ok {
+		agg = &models.ChampionAggregate{PlayerID: playerID, ChampionID: championID}
+	}
+	return ChampionStatsFromAggregate(agg), nil
+}
+
+// ChampionStatsFromAggregate derives champion stats from running sums
+func ChampionStatsFromAggregate(agg *models.ChampionAggregate) *models.ChampionStats {
+	stats := &models.ChampionStats{
+		PlayerID:          agg.PlayerID,
+		ChampionID:        agg.ChampionID,
+		ChampionName:      agg.ChampionName,
+		TotalMatches:      agg.Games,
+		Wins:              agg.Wins,
+		Losses:            agg.Games - agg.Wins,
+		LastPlayed:        agg.LastMatchAt,
+		RoleSpecificStats: make(map[string]interface{}),
+	}
+	if agg.Games == 0 {
+		return stats
+	}
+
+	count := float64(agg.Games)
+	stats.WinRate = float64(agg.Wins) / count * 100
+	stats.AverageKDA = kdaRatio(agg.Kills, agg.Deaths, agg.Assists)
+	stats.AverageCSPerMin = agg.CSPerMinSum / count
+	stats.AverageVisionScore = agg.VisionSum / count
+	stats.AverageDamageShare = agg.DamageShareSum / count
+
+	for role, games := range agg.RoleGames {
+		if games > agg.RoleGames[stats.PreferredRole] || (games == agg.RoleGames[stats.PreferredRole] && role < stats.PreferredRole) {
+			stats.PreferredRole = role
+		}
+	}
+
+	return stats
+}
+
+// latestAggregatedMatch returns the cursor of the newest match folded into
+// any of the aggregates
+func latestAggregatedMatch(aggregates map[int]*models.ChampionAggregate) repository.MatchCursor {
+	var cursor repository.MatchCursor
+	for _, agg := range aggregates {
+		if agg.LastMatchAt.After(cursor.Date) || (agg.LastMatchAt.Equal(cursor.Date) && agg.LastMatchID > cursor.MatchID) {
+			cursor = repository.MatchCursor{Date: agg.LastMatchAt, MatchID: agg.LastMatchID}
+		}
+	}
+	return cursor
+}
+
+// isAllTime reports whether the window covers every match unfiltered, which
+// the running champion aggregates can answer
+func (w StatsWindow) isAllTime() bool {
+	return w.Days == 0 && w.Games == 0 && w.MatchFilter.isEmpty()
+}
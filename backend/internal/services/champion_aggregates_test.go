@@ -0,0 +1,43 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlayerLocks(t *testing.T) {
+	var locks playerLocks
+
+	unlock := locks.lock("player-1")
+
+	other := make(chan struct{})
+	go func() {
+		locks.lock("player-2")()
+		close(other)
+	}()
+	select {
+	case <-other:
+	case <-time.After(time.Second):
+		t.Fatal("another player's update waited on player-1")
+	}
+
+	same := make(chan struct{})
+	go func() {
+		locks.lock("player-1")()
+		close(same)
+	}()
+	select {
+	case <-same:
+		t.Fatal("a second update of player-1 ran while the first held the lock")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	unlock()
+	<-same
+
+	locks.mu.Lock()
+	defer locks.mu.Unlock()
+	assert.Empty(t, locks.locks, "released locks are dropped")
+}
@@ -0,0 +1,211 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+	"github.com/herald-lol/herald/backend/internal/repository"
+)
+
+// DefaultCSBenchmarks are the CS per minute expected in each role, keyed by
+// the canonical role names of models.NormalizeRole
+var DefaultCSBenchmarks = map[string]float64{
+	models.RoleTop:     7.0,
+	models.RoleJungle:  5.5,
+	models.RoleMid:     7.5,
+	models.RoleADC:     8.0,
+	models.RoleSupport: 1.2,
+}
+
+// csBenchmarkSpread is the standard deviation of CS per minute within a role
+// as a share of its benchmark. Percentiles treat the benchmark as the role
+// median with this spread, so 20% above the benchmark is about the 84th.
+const csBenchmarkSpread = 0.2
+
+// RoleCSBenchmark compares the player's CS per minute in one role with the
+// role's benchmark
+type RoleCSBenchmark struct {
+	Role            string  `json:"role"`
+	Games           int     `json:"games"`
+	AverageCSPerMin float64 `json:"average_cs_per_min"`
+	BestCSPerMin    float64 `json:"best_cs_per_min"`
+	Benchmark       float64 `json:"benchmark_cs_per_min"`
+	// Gap is the average minus the benchmark; negative when below it
+	Gap        float64 `json:"gap"`
+	Percentile float64 `json:"percentile"`
+	// GamesAtBenchmark counts games at or above the benchmark
+	GamesAtBenchmark int `json:"games_at_benchmark"`
+}
+
+// CSBenchmarkAnalysis is the player's CS per minute in each role they played
+// against the configured role benchmarks
+type CSBenchmarkAnalysis struct {
+	PlayerID      string             `json:"player_id"`
+	TimeRange     string             `json:"time_range"`
+	Filter        MatchFilter        `json:"filter"`
+	ExcludedGames int                `json:"excluded_games"` // non-competitive games left out
+	Roles         []RoleCSBenchmark  `json:"roles"`          // most played first
+	Benchmarks    map[string]float64 `json:"benchmarks"`
+}
+
+// AnalyzeCSBenchmarks compares the CS per minute of the user's linked
+// accounts in each role with the role benchmarks, from the stored
+// participant rows of their matches
+func (as *AnalyticsService) AnalyzeCSBenchmarks(ctx context.Context, playerID, timeRange string, filter MatchFilter) (*CSBenchmarkAnalysis, error) {
+	filter = filter.normalize()
+	startDate, endDate := as.parseTimeRange(timeRange)
+
+	champions, _ := filter.championQuery("")
+	matches, err := as.getRoleCSGames(ctx, playerID, startDate.UnixMilli(), endDate.UnixMilli(), champions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get player matches: %w", err)
+	}
+	matches, excluded := filter.apply(matches)
+
+	analysis := BuildCSBenchmarkAnalysis(matches, as.csBenchmarks)
+	analysis.PlayerID = playerID
+	analysis.TimeRange = timeRange
+	analysis.Filter = filter
+	analysis.ExcludedGames = excluded
+	return analysis, nil
+}
+
+// SetCSBenchmarks overrides the CS per minute benchmark of the given roles.
+// Role names are normalized with models.NormalizeRole; unknown roles and
+// values <= 0 are ignored, and roles not listed keep their default.
+func (as *AnalyticsService) SetCSBenchmarks(benchmarks map[string]float64) {
+	merged := make(map[string]float64, len(DefaultCSBenchmarks))
+	for role, benchmark := range DefaultCSBenchmarks {
+		merged[role] = benchmark
+	}
+	for role, benchmark := range benchmarks {
+		if models.IsCanonicalRole(role) && benchmark > 0 {
+			merged[models.NormalizeRole(role)] = benchmark
+		}
+	}
+	as.csBenchmarks = merged
+}
+
+// getRoleCSGames loads the games of every Riot account linked to the user
+// playerID on the allowed champions started between start and end (Unix
+// milliseconds) with the fields CS benchmarks and match filters use
+func (as *AnalyticsService) getRoleCSGames(ctx context.Context, playerID string, start, end int64, champions repository.ChampionFilter) ([]models.MatchData, error) {
+	puuids, err := as.accountPUUIDs(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := make([]models.MatchData, 0)
+	for _, puuid := range puuids {
+		accountMatches, err := as.getAccountRoleCSGames(ctx, puuid, start, end, champions)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, accountMatches...)
+	}
+	return matches, nil
+}
+
+// getAccountRoleCSGames is getRoleCSGames for the account puuid
+func (as *AnalyticsService) getAccountRoleCSGames(ctx context.Context, puuid string, start, end int64, champions repository.ChampionFilter) ([]models.MatchData, error) {
+	championSQL, args := champions.Where("mp.champion_name", []interface{}{puuid, start, end})
+	query := `
+		SELECT m.match_id, mp.champion_name, COALESCE(mp.team_position, ''),
+			mp.total_cs, mp.gold_earned, mp.won, m.game_duration, m.game_start_timestamp
+		FROM match_participants mp
+		JOIN matches m ON mp.match_id = m.id
+		WHERE mp.puuid = $1
+		AND m.game_start_timestamp BETWEEN $2 AND $3
+		AND m.game_duration > 0` + championSQL + `
+		ORDER BY m.game_start_timestamp DESC
+	`
+
+	rows, err := as.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query CS: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make([]models.MatchData, 0)
+	for rows.Next() {
+		match := models.MatchData{PlayerID: puuid}
+		var goldEarned int
+		var startedAt int64
+		if err := rows.Scan(&match.MatchID, &match.ChampionName, &match.Position,
+			&match.TotalCS, &goldEarned, &match.Win, &match.GameDuration, &startedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan CS: %w", err)
+		}
+		minutes := float64(match.GameDuration) / 60
+		match.CSPerMinute = float64(match.TotalCS) / minutes
+		match.GoldPerMinute = float64(goldEarned) / minutes
+		match.GameWasRemade = models.IsLikelyRemake(match.GameDuration)
+		match.Date = time.UnixMilli(startedAt)
+		matches = append(matches, match)
+	}
+
+	return matches, rows.Err()
+}
+
+// BuildCSBenchmarkAnalysis groups the matches by role and compares each
+// role's CS per minute with its benchmark. Games without a known role, such
+// as ARAM, are left out. A nil benchmarks map uses DefaultCSBenchmarks.
+func BuildCSBenchmarkAnalysis(matches []models.MatchData, benchmarks map[string]float64) *CSBenchmarkAnalysis {
+	if benchmarks == nil {
+		benchmarks = DefaultCSBenchmarks
+	}
+
+	byRole := make(map[string]*RoleCSBenchmark)
+	totals := make(map[string]float64)
+	for _, match := range matches {
+		role := models.NormalizeRole(match.Position)
+		benchmark, ok := benchmarks[role]
+		if !ok || match.GameDuration <= 0 {
+			continue
+		}
+
+		csPerMin := float64(match.TotalCS) / (float64(match.GameDuration) / 60)
+		stats, exists := byRole[role]
+		if !exists {
+			stats = &RoleCSBenchmark{Role: role, Benchmark: benchmark}
+			byRole[role] = stats
+		}
+		stats.Games++
+		totals[role] += csPerMin
+		stats.BestCSPerMin = math.Max(stats.BestCSPerMin, csPerMin)
+		if csPerMin >= benchmark {
+			stats.GamesAtBenchmark++
+		}
+	}
+
+	roles := make([]RoleCSBenchmark, 0, len(byRole))
+	for role, stats := range byRole {
+		average := totals[role] / float64(stats.Games)
+		stats.AverageCSPerMin = math.Round(average*100) / 100
+		stats.BestCSPerMin = math.Round(stats.BestCSPerMin*100) / 100
+		stats.Gap = math.Round((average-stats.Benchmark)*100) / 100
+		stats.Percentile = csPercentile(average, stats.Benchmark)
+		roles = append(roles, *stats)
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		if roles[i].Games != roles[j].Games {
+			return roles[i].Games > roles[j].Games
+		}
+		return roles[i].Role < roles[j].Role
+	})
+
+	return &CSBenchmarkAnalysis{Roles: roles, Benchmarks: benchmarks}
+}
+
+// csPercentile estimates where csPerMin falls among players of a role whose
+// median is benchmark, see csBenchmarkSpread
+func csPercentile(csPerMin, benchmark float64) float64 {
+	if benchmark <= 0 {
+		return 50
+	}
+	z := (csPerMin - benchmark) / (benchmark * csBenchmarkSpread)
+	return math.Round(500*(1+math.Erf(z/math.Sqrt2))) / 10
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/herald-lol/herald/backend/internal/logger"
 	"github.com/herald-lol/herald/backend/internal/models"
 )
 
@@ -157,6 +158,21 @@ type DamageTrendPoint struct {
 	CarryPotential   float64   `json:"carry_potential"`
 }
 
+// ChampionDamageBreakdown splits a player's damage to champions by type on one champion
+type ChampionDamageBreakdown struct {
+	ChampionID     int     `json:"champion_id"`
+	ChampionName   string  `json:"champion_name"`
+	Games          int     `json:"games"`
+	PhysicalDamage int64   `json:"physical_damage"`
+	MagicDamage    int64   `json:"magic_damage"`
+	TrueDamage     int64   `json:"true_damage"`
+	TotalDamage    int64   `json:"total_damage"`
+	PhysicalShare  float64 `json:"physical_share"`
+	MagicShare     float64 `json:"magic_share"`
+	TrueShare      float64 `json:"true_share"`
+	DamageProfile  string  `json:"damage_profile"` // "physical", "magic", "true", "mixed"
+}
+
 // MatchDamageData represents damage data from a specific match
 type MatchDamageData struct {
 	MatchID           string    `json:"match_id"`
@@ -251,7 +267,7 @@ func (das *DamageAnalyticsService) AnalyzeDamage(ctx context.Context, playerID s
 	// Perform comparative analysis
 	err = das.performDamageComparison(ctx, analysis, position, champion)
 	if err != nil {
-		fmt.Printf("Warning: failed to perform damage comparison: %v", err)
+		logger.Warnf("Failed to perform damage comparison: %v", err)
 	}
 
 	// Calculate carry potential and consistency
@@ -318,6 +334,69 @@ func (das *DamageAnalyticsService) GetTeamContribution(ctx context.Context, play
 	return contribution, nil
 }
 
+// GetChampionDamageBreakdown aggregates physical, magic and true damage dealt to
+// champions per champion from stored match participants, most played first
+func (das *DamageAnalyticsService) GetChampionDamageBreakdown(ctx context.Context, playerID string, timeRange string) ([]ChampionDamageBreakdown, error) {
+	startDate, _ := das.analyticsService.parseTimeRange(timeRange)
+
+	query := `
+		SELECT mp.champion_id, mp.champion_name, COUNT(*),
+			COALESCE(SUM(mp.physical_damage_dealt_to_champions), 0),
+			COALESCE(SUM(mp.magic_damage_dealt_to_champions), 0),
+			COALESCE(SUM(mp.true_damage_dealt_to_champions), 0)
+		FROM match_participants mp
+		JOIN matches m ON mp.match_id = m.id
+		WHERE mp.puuid = $1
+		AND m.game_start_timestamp >= $2
+		GROUP BY mp.champion_id, mp.champion_name
+		ORDER BY COUNT(*) DESC, mp.champion_name
+	`
+
+	rows, err := das.analyticsService.db.QueryContext(ctx, query, playerID, startDate.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query champion damage: %w", err)
+	}
+	defer rows.Close()
+
+	breakdowns := make([]ChampionDamageBreakdown, 0)
+	for rows.Next() {
+		var b ChampionDamageBreakdown
+		if err := rows.Scan(&b.ChampionID, &b.ChampionName, &b.Games, &b.PhysicalDamage, &b.MagicDamage, &b.TrueDamage); err != nil {
+			return nil, fmt.Errorf("failed to scan champion damage: %w", err)
+		}
+		das.calculateDamageTypeShares(&b)
+		breakdowns = append(breakdowns, b)
+	}
+
+	return breakdowns, rows.Err()
+}
+
+// calculateDamageTypeShares fills in the percentage split and classifies the
+// profile; a type counts as dominant at 60% or more of the total
+func (das *DamageAnalyticsService) calculateDamageTypeShares(b *ChampionDamageBreakdown) {
+	b.TotalDamage = b.PhysicalDamage + b.MagicDamage + b.TrueDamage
+	if b.TotalDamage == 0 {
+		b.DamageProfile = "mixed"
+		return
+	}
+
+	total := float64(b.TotalDamage)
+	b.PhysicalShare = float64(b.PhysicalDamage) / total * 100
+	b.MagicShare = float64(b.MagicDamage) / total * 100
+	b.TrueShare = float64(b.TrueDamage) / total * 100
+
+	switch {
+	case b.PhysicalShare >= 60:
+		b.DamageProfile = "physical"
+	case b.MagicShare >= 60:
+		b.DamageProfile = "magic"
+	case b.TrueShare >= 60:
+		b.DamageProfile = "true"
+	default:
+		b.DamageProfile = "mixed"
+	}
+}
+
 // Helper functions for damage analysis
 
 func (das *DamageAnalyticsService) calculateDamageBasics(analysis *DamageAnalysis, matches []models.MatchData) {
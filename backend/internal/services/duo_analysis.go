@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// DefaultDuoMinSharedGames is how many games a teammate must share with the
+// player before they are treated as a duo partner. Riot's match data does not
+// expose pre-made parties, so partners are inferred from repeated teammates.
+const DefaultDuoMinSharedGames = 3
+
+// DuoTeammate is an ally in one of the player's matches
+type DuoTeammate struct {
+	PUUID        string `json:"puuid"`
+	SummonerName string `json:"summoner_name"`
+}
+
+// QueueSplitStats is the record of a set of games
+type QueueSplitStats struct {
+	Games      int     `json:"games"`
+	Wins       int     `json:"wins"`
+	Losses     int     `json:"losses"`
+	WinRate    float64 `json:"win_rate"`
+	AverageKDA float64 `json:"average_kda"`
+}
+
+// DuoPartnerStats is the record of the games played with one duo partner
+type DuoPartnerStats struct {
+	DuoTeammate
+	QueueSplitStats
+}
+
+// DuoAnalysis splits a player's games into those played alone and those
+// played with a duo partner
+type DuoAnalysis struct {
+	PlayerID       string            `json:"player_id"`
+	TimeRange      string            `json:"time_range"`
+	MinSharedGames int               `json:"min_shared_games"`
+	Solo           QueueSplitStats   `json:"solo"`
+	Duo            QueueSplitStats   `json:"duo"`
+	Partners       []DuoPartnerStats `json:"partners"`
+	// WinRateDelta is the duo win rate minus the solo win rate, in
+	// percentage points; zero unless both splits have games
+	WinRateDelta float64 `json:"win_rate_delta"`
+}
+
+// AnalyzeDuoVsSolo compares the win rate and KDA of the user's linked
+// accounts in games with and without a duo partner. minSharedGames overrides
+// the configured partner threshold when positive.
+func (as *AnalyticsService) AnalyzeDuoVsSolo(ctx context.Context, playerID, timeRange string, minSharedGames int, filter MatchFilter) (*DuoAnalysis, error) {
+	filter = filter.normalize()
+	if minSharedGames < 1 {
+		minSharedGames = as.duoMinSharedGames
+	}
+	startDate, endDate := as.parseTimeRange(timeRange)
+
+	matches, _, err := as.getAccountFilteredMatches(ctx, playerID, startDate, endDate, "", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	teammates, err := as.getMatchTeammates(ctx, playerID, startDate.UnixMilli(), endDate.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teammates: %w", err)
+	}
+
+	analysis := BuildDuoAnalysis(matches, teammates, minSharedGames)
+	analysis.PlayerID = playerID
+	analysis.TimeRange = timeRange
+	return analysis, nil
+}
+
+// SetDuoMinSharedGames sets how many shared games make a teammate a duo
+// partner; values below 1 restore the default
+func (as *AnalyticsService) SetDuoMinSharedGames(games int) {
+	if games < 1 {
+		games = DefaultDuoMinSharedGames
+	}
+	as.duoMinSharedGames = games
+}
+
+// getMatchTeammates returns the allies of every Riot account linked to the
+// user playerID, keyed by Riot match ID, for matches started between start
+// and end (Unix milliseconds)
+func (as *AnalyticsService) getMatchTeammates(ctx context.Context, playerID string, start, end int64) (map[string][]DuoTeammate, error) {
+	puuids, err := as.accountPUUIDs(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT m.match_id, ally.puuid, COALESCE(ally.summoner_name, '')
+		FROM match_participants mp
+		JOIN matches m ON mp.match_id = m.id
+		JOIN match_participants ally ON ally.match_id = mp.match_id
+			AND ally.team_id = mp.team_id
+			AND ally.puuid <> mp.puuid
+		WHERE mp.puuid = $1
+		AND m.game_start_timestamp BETWEEN $2 AND $3
+	`
+
+	teammates := make(map[string][]DuoTeammate)
+	for _, puuid := range puuids {
+		if err := as.scanMatchTeammates(ctx, teammates, query, puuid, start, end); err != nil {
+			return nil, err
+		}
+	}
+	return teammates, nil
+}
+
+func (as *AnalyticsService) scanMatchTeammates(ctx context.Context, teammates map[string][]DuoTeammate, query, puuid string, start, end int64) error {
+	rows, err := as.db.QueryContext(ctx, query, puuid, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to query teammates: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var matchID string
+		var teammate DuoTeammate
+		if err := rows.Scan(&matchID, &teammate.PUUID, &teammate.SummonerName); err != nil {
+			return fmt.Errorf("failed to scan teammate: %w", err)
+		}
+		teammates[matchID] = append(teammates[matchID], teammate)
+	}
+	return rows.Err()
+}
+
+// BuildDuoAnalysis treats every ally who appears in at least minSharedGames of
+// the matches as a duo partner, then splits the matches into solo games and
+// games with a partner. teammates is keyed by MatchData.MatchID.
+func BuildDuoAnalysis(matches []models.MatchData, teammates map[string][]DuoTeammate, minSharedGames int) *DuoAnalysis {
+	if minSharedGames < 1 {
+		minSharedGames = DefaultDuoMinSharedGames
+	}
+
+	shared := make(map[string]int)
+	names := make(map[string]string)
+	for _, match := range matches {
+		for _, teammate := range teammates[match.MatchID] {
+			shared[teammate.PUUID]++
+			if teammate.SummonerName != "" {
+				names[teammate.PUUID] = teammate.SummonerName
+			}
+		}
+	}
+
+	var solo, duo splitAccumulator
+	partners := make(map[string]*splitAccumulator)
+	for _, match := range matches {
+		isDuo := false
+		for _, teammate := range teammates[match.MatchID] {
+			if shared[teammate.PUUID] < minSharedGames {
+				continue
+			}
+			isDuo = true
+			if partners[teammate.PUUID] == nil {
+				partners[teammate.PUUID] = &splitAccumulator{}
+			}
+			partners[teammate.PUUID].add(match)
+		}
+
+		if isDuo {
+			duo.add(match)
+		} else {
+			solo.add(match)
+		}
+	}
+
+	analysis := &DuoAnalysis{
+		MinSharedGames: minSharedGames,
+		Solo:           solo.stats(),
+		Duo:            duo.stats(),
+		Partners:       make([]DuoPartnerStats, 0, len(partners)),
+	}
+	for puuid, partner := range partners {
+		analysis.Partners = append(analysis.Partners, DuoPartnerStats{
+			DuoTeammate:     DuoTeammate{PUUID: puuid, SummonerName: names[puuid]},
+			QueueSplitStats: partner.stats(),
+		})
+	}
+	sort.Slice(analysis.Partners, func(i, j int) bool {
+		if analysis.Partners[i].Games != analysis.Partners[j].Games {
+			return analysis.Partners[i].Games > analysis.Partners[j].Games
+		}
+		return analysis.Partners[i].PUUID < analysis.Partners[j].PUUID
+	})
+
+	if analysis.Solo.Games > 0 && analysis.Duo.Games > 0 {
+		analysis.WinRateDelta = analysis.Duo.WinRate - analysis.Solo.WinRate
+	}
+
+	return analysis
+}
+
+// splitAccumulator sums the record of a set of games
+type splitAccumulator struct {
+	games, wins int
+	kdaSum      float64
+}
+
+func (a *splitAccumulator) add(match models.MatchData) {
+	a.games++
+	if match.Win {
+		a.wins++
+	}
+	a.kdaSum += kdaRatio(match.Kills, match.Deaths, match.Assists)
+}
+
+func (a *splitAccumulator) stats() QueueSplitStats {
+	stats := QueueSplitStats{Games: a.games, Wins: a.wins, Losses: a.games - a.wins}
+	if a.games > 0 {
+		stats.WinRate = float64(a.wins) / float64(a.games) * 100
+		stats.AverageKDA = a.kdaSum / float64(a.games)
+	}
+	return stats
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// Game length bucket boundaries in minutes
+const (
+	shortGameMinutes = 25
+	longGameMinutes  = 35
+)
+
+// durationTendencyGap is the win rate gap in percentage points between short
+// and long games needed to call a player an early or late game player
+const durationTendencyGap = 10.0
+
+// durationTendencyMinGames is the fewest games both the short and long
+// buckets need before a tendency is reported
+const durationTendencyMinGames = 5
+
+// DurationBucket is the record of games within a range of game lengths
+type DurationBucket struct {
+	Label      string  `json:"label"`
+	MinMinutes int     `json:"min_minutes"`
+	MaxMinutes int     `json:"max_minutes,omitempty"` // exclusive, 0 for no upper bound
+	Games      int     `json:"games"`
+	Wins       int     `json:"wins"`
+	Losses     int     `json:"losses"`
+	WinRate    float64 `json:"win_rate"`
+	AverageKDA float64 `json:"average_kda"`
+}
+
+// DurationAnalysis holds a player's win rate by game length
+type DurationAnalysis struct {
+	PlayerID  string           `json:"player_id"`
+	TimeRange string           `json:"time_range"`
+	Matches   int              `json:"matches"`
+	Buckets   []DurationBucket `json:"buckets"`
+	Tendency  string           `json:"tendency"` // "early_game", "late_game", "balanced", "insufficient_data"
+}
+
+// AnalyzeWinRateByDuration buckets the games of the user's linked accounts
+// in timeRange by length (under 25, 25 to 35, over 35 minutes) and computes
+// the win rate per bucket
+func (as *AnalyticsService) AnalyzeWinRateByDuration(ctx context.Context, playerID, timeRange string, filter MatchFilter) (*DurationAnalysis, error) {
+	filter = filter.normalize()
+	startDate, endDate := as.parseTimeRange(timeRange)
+
+	matches, _, err := as.getAccountFilteredMatches(ctx, playerID, startDate, endDate, "", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := BuildDurationAnalysis(matches)
+	analysis.PlayerID = playerID
+	analysis.TimeRange = timeRange
+	return analysis, nil
+}
+
+// BuildDurationAnalysis buckets matches by GameDuration and derives the win
+// rate per bucket and the player's early or late game tendency
+func BuildDurationAnalysis(matches []models.MatchData) *DurationAnalysis {
+	buckets := []DurationBucket{
+		{Label: fmt.Sprintf("<%d", shortGameMinutes), MinMinutes: 0, MaxMinutes: shortGameMinutes},
+		{Label: fmt.Sprintf("%d-%d", shortGameMinutes, longGameMinutes), MinMinutes: shortGameMinutes, MaxMinutes: longGameMinutes},
+		{Label: fmt.Sprintf(">%d", longGameMinutes), MinMinutes: longGameMinutes},
+	}
+	kdaSums := make([]float64, len(buckets))
+
+	for _, match := range matches {
+		idx := durationBucketIndex(match.GameDuration)
+		bucket := &buckets[idx]
+		bucket.Games++
+		if match.Win {
+			bucket.Wins++
+		} else {
+			bucket.Losses++
+		}
+		kdaSums[idx] += kdaRatio(match.Kills, match.Deaths, match.Assists)
+	}
+
+	for i := range buckets {
+		if buckets[i].Games > 0 {
+			buckets[i].WinRate = float64(buckets[i].Wins) / float64(buckets[i].Games) * 100
+			buckets[i].AverageKDA = kdaSums[i] / float64(buckets[i].Games)
+		}
+	}
+
+	return &DurationAnalysis{
+		Matches:  len(matches),
+		Buckets:  buckets,
+		Tendency: durationTendency(buckets[0], buckets[len(buckets)-1]),
+	}
+}
+
+// durationBucketIndex returns the bucket for a game length in seconds
+func durationBucketIndex(seconds int) int {
+	minutes := seconds / 60
+	switch {
+	case minutes < shortGameMinutes:
+		return 0
+	case minutes < longGameMinutes:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// durationTendency compares short and long game win rates
+func durationTendency(short, long DurationBucket) string {
+	if short.Games < durationTendencyMinGames || long.Games < durationTendencyMinGames {
+		return "insufficient_data"
+	}
+
+	switch gap := short.WinRate - long.WinRate; {
+	case gap >= durationTendencyGap:
+		return "early_game"
+	case gap <= -durationTendencyGap:
+		return "late_game"
+	default:
+		return "balanced"
+	}
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"sync"
+)
+
+// exportRowBudget counts the matches held in memory by running exports. An
+// export waits for room before loading more once the limit is reached, so
+// concurrent large exports can't exhaust memory between them.
+type exportRowBudget struct {
+	limit int
+
+	mu       sync.Mutex
+	inFlight int
+	// freed is closed and replaced whenever rows are released
+	freed chan struct{}
+}
+
+// newExportRowBudget returns nil when limit is 0, which never blocks
+func newExportRowBudget(limit int) *exportRowBudget {
+	if limit <= 0 {
+		return nil
+	}
+	return &exportRowBudget{limit: limit, freed: make(chan struct{})}
+}
+
+// acquire reserves rows, waiting until they fit under the limit or ctx is
+// done. A reservation larger than the limit is let through once nothing else
+// is in flight, so it can't wait forever.
+func (b *exportRowBudget) acquire(ctx context.Context, rows int) error {
+	if b == nil {
+		return nil
+	}
+
+	for {
+		b.mu.Lock()
+		if b.inFlight == 0 || b.inFlight+rows <= b.limit {
+			b.inFlight += rows
+			b.mu.Unlock()
+			return nil
+		}
+		freed := b.freed
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-freed:
+		}
+	}
+}
+
+// release returns reserved rows and wakes the exports waiting for room
+func (b *exportRowBudget) release(rows int) {
+	if b == nil || rows <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight -= rows
+	close(b.freed)
+	b.freed = make(chan struct{})
+}
+
+// reserved returns the number of rows currently reserved
+func (b *exportRowBudget) reserved() int {
+	if b == nil {
+		return 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.inFlight
+}
@@ -0,0 +1,54 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportRowBudget(t *testing.T) {
+	budget := newExportRowBudget(10)
+	ctx := context.Background()
+
+	require.NoError(t, budget.acquire(ctx, 6))
+	require.NoError(t, budget.acquire(ctx, 4))
+	assert.Equal(t, 10, budget.reserved())
+
+	// A third export waits until rows are written out
+	acquired := make(chan error, 1)
+	go func() { acquired <- budget.acquire(ctx, 5) }()
+	select {
+	case <-acquired:
+		t.Fatal("acquire should wait while the budget is full")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	budget.release(6)
+	select {
+	case err := <-acquired:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("acquire should proceed once rows are released")
+	}
+	assert.Equal(t, 9, budget.reserved())
+
+	// Waiting stops with the request
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.ErrorIs(t, budget.acquire(cancelled, 5), context.Canceled)
+
+	// A reservation over the limit runs alone
+	budget.release(9)
+	require.NoError(t, budget.acquire(ctx, 25))
+	assert.Equal(t, 25, budget.reserved())
+	budget.release(25)
+
+	// No limit never waits
+	unlimited := newExportRowBudget(0)
+	require.NoError(t, unlimited.acquire(ctx, 1000))
+	unlimited.release(1000)
+	assert.Equal(t, 0, unlimited.reserved())
+}
@@ -25,7 +25,7 @@ func TestGamingAnalyticsPerformance(t *testing.T) {
 		playerID := "test-summoner-123"
 		createTestMatchData(t, service.db, playerID, 100) // 100 matches
 
-		analysis, err := service.AnalyzeKDA(ctx, playerID, "30d", "")
+		analysis, err := service.AnalyzeKDA(ctx, playerID, "30d", "", MatchFilter{})
 
 		duration := time.Since(start)
 
@@ -42,7 +42,7 @@ func TestGamingAnalyticsPerformance(t *testing.T) {
 		playerID := "test-summoner-cs"
 		createTestMatchData(t, service.db, playerID, 100)
 
-		analysis, err := service.AnalyzeCS(ctx, playerID, "30d", "ADC", "")
+		analysis, err := service.AnalyzeCS(ctx, playerID, "30d", "ADC", "", MatchFilter{})
 
 		duration := time.Since(start)
 
@@ -60,8 +60,8 @@ func TestGamingAnalyticsPerformance(t *testing.T) {
 		createTestMatchData(t, service.db, playerID, 50)
 
 		// Run multiple analytics in sequence (simulating dashboard load)
-		kdaAnalysis, err1 := service.AnalyzeKDA(ctx, playerID, "30d", "")
-		csAnalysis, err2 := service.AnalyzeCS(ctx, playerID, "30d", "ADC", "")
+		kdaAnalysis, err1 := service.AnalyzeKDA(ctx, playerID, "30d", "", MatchFilter{})
+		csAnalysis, err2 := service.AnalyzeCS(ctx, playerID, "30d", "ADC", "", MatchFilter{})
 		comparison, err3 := service.ComparePerformance(ctx, playerID, "30d")
 
 		duration := time.Since(start)
@@ -84,7 +84,7 @@ func TestGamingAnalyticsPerformance(t *testing.T) {
 		playerID := "test-summoner-large"
 		createTestMatchData(t, service.db, playerID, 500) // Large dataset
 
-		analysis, err := service.AnalyzeKDA(ctx, playerID, "90d", "")
+		analysis, err := service.AnalyzeKDA(ctx, playerID, "90d", "", MatchFilter{})
 
 		duration := time.Since(start)
 
@@ -264,7 +264,7 @@ func TestGamingMetricsValidation(t *testing.T) {
 				createChampionMatchData(t, service.db, playerID, champion, 20)
 
 				ctx := context.Background()
-				analysis, err := service.AnalyzeKDA(ctx, playerID, "30d", champion)
+				analysis, err := service.AnalyzeKDA(ctx, playerID, "30d", champion, MatchFilter{})
 
 				require.NoError(t, err)
 				assert.NotNil(t, analysis)
@@ -289,7 +289,7 @@ func TestGamingMetricsValidation(t *testing.T) {
 
 		for _, timeRange := range timeRanges {
 			t.Run(timeRange, func(t *testing.T) {
-				analysis, err := service.AnalyzeKDA(ctx, playerID, timeRange, "")
+				analysis, err := service.AnalyzeKDA(ctx, playerID, timeRange, "", MatchFilter{})
 
 				require.NoError(t, err)
 				assert.NotNil(t, analysis)
@@ -322,7 +322,7 @@ func BenchmarkGamingAnalytics(b *testing.B) {
 
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			_, _ = service.AnalyzeKDA(ctx, playerID, "30d", "")
+			_, _ = service.AnalyzeKDA(ctx, playerID, "30d", "", MatchFilter{})
 		}
 	})
 
@@ -332,7 +332,7 @@ func BenchmarkGamingAnalytics(b *testing.B) {
 
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			_, _ = service.AnalyzeKDA(ctx, playerID, "30d", "")
+			_, _ = service.AnalyzeKDA(ctx, playerID, "30d", "", MatchFilter{})
 		}
 	})
 
@@ -342,7 +342,7 @@ func BenchmarkGamingAnalytics(b *testing.B) {
 
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
-			_, _ = service.AnalyzeCS(ctx, playerID, "30d", "ADC", "")
+			_, _ = service.AnalyzeCS(ctx, playerID, "30d", "ADC", "", MatchFilter{})
 		}
 	})
 
@@ -396,7 +396,7 @@ func BenchmarkMemoryUsage(b *testing.B) {
 			createTestMatchData(b, service.db, playerID+string(rune(i)), 1000)
 
 			ctx := context.Background()
-			_, _ = service.AnalyzeKDA(ctx, playerID+string(rune(i)), "90d", "")
+			_, _ = service.AnalyzeKDA(ctx, playerID+string(rune(i)), "90d", "", MatchFilter{})
 		}
 	})
 }
@@ -419,7 +419,7 @@ func BenchmarkConcurrentAnalytics(b *testing.B) {
 			playerIndex := 0
 			for pb.Next() {
 				playerID := players[playerIndex%len(players)]
-				_, _ = service.AnalyzeKDA(ctx, playerID, "30d", "")
+				_, _ = service.AnalyzeKDA(ctx, playerID, "30d", "", MatchFilter{})
 				playerIndex++
 			}
 		})
@@ -435,9 +435,9 @@ func BenchmarkConcurrentAnalytics(b *testing.B) {
 				// Simulate mixed workload
 				switch playerIndex % 3 {
 				case 0:
-					_, _ = service.AnalyzeKDA(ctx, playerID, "30d", "")
+					_, _ = service.AnalyzeKDA(ctx, playerID, "30d", "", MatchFilter{})
 				case 1:
-					_, _ = service.AnalyzeCS(ctx, playerID, "30d", "ADC", "")
+					_, _ = service.AnalyzeCS(ctx, playerID, "30d", "ADC", "", MatchFilter{})
 				case 2:
 					_, _ = service.ComparePerformance(ctx, playerID, "30d")
 				}
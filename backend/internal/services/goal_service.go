@@ -0,0 +1,315 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// Champion goal metrics
+const (
+	GoalMetricWinRate  = "win_rate"
+	GoalMetricKDA      = "kda"
+	GoalMetricCSPerMin = "cs_per_min"
+)
+
+// Champion goal limits
+const (
+	GoalTypeChampion   = "champion"
+	DefaultGoalGames   = 20
+	MaxGoalGames       = 200
+	goalStatusActive   = "active"
+	goalStatusComplete = "completed"
+)
+
+var (
+	ErrGoalNotFound = errors.New("goal not found")
+	ErrInvalidGoal  = errors.New("invalid goal")
+)
+
+// GoalService stores champion goals and tracks them against stored matches
+type GoalService struct {
+	db *gorm.DB
+}
+
+// CreateGoalRequest describes a goal such as a 60% win rate on Jinx over 20
+// games
+type CreateGoalRequest struct {
+	Champion    string     `json:"champion" binding:"required"`
+	Metric      string     `json:"metric"` // win_rate (default), kda, cs_per_min
+	Target      float64    `json:"target" binding:"required"`
+	Games       int        `json:"games"` // default 20, max 200
+	Deadline    *time.Time `json:"deadline,omitempty"`
+	Description string     `json:"description"`
+}
+
+// GoalProgress is a goal with the stats it is measured on
+type GoalProgress struct {
+	Goal         models.SkillGoal `json:"goal"`
+	Games        int              `json:"games"`         // games counted, at most the goal's TargetGames
+	GamesNeeded  int              `json:"games_needed"`  // games still to play before the goal can be met
+	CurrentValue float64          `json:"current_value"` // metric over the counted games
+	Met          bool             `json:"met"`
+}
+
+func NewGoalService(db *gorm.DB) *GoalService {
+	return &GoalService{db: db}
+}
+
+// CreateGoal stores a champion goal for the user and returns its current
+// progress
+func (s *GoalService) CreateGoal(userID uuid.UUID, req CreateGoalRequest) (*GoalProgress, error) {
+	goal, err := newChampionGoal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var user models.User
+	if err := s.db.First(&user, "id = ?", userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	goal.UserID = userID
+	goal.SummonerID = user.SummonerID
+
+	if err := s.db.Create(goal).Error; err != nil {
+		return nil, err
+	}
+	return s.track(userID, goal)
+}
+
+// ListGoals returns the user's champion goals with their progress, newest
+// first. Active goals that are now met are marked completed.
+func (s *GoalService) ListGoals(userID uuid.UUID) ([]GoalProgress, error) {
+	var goals []models.SkillGoal
+	err := s.db.Where("user_id = ? AND goal_type = ?", userID, GoalTypeChampion).
+		Order("created_at DESC").
+		Find(&goals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	progress := make([]GoalProgress, 0, len(goals))
+	for i := range goals {
+		p, err := s.track(userID, &goals[i])
+		if err != nil {
+			return nil, err
+		}
+		progress = append(progress, *p)
+	}
+	return progress, nil
+}
+
+// DeleteGoal removes one of the user's goals
+func (s *GoalService) DeleteGoal(userID uuid.UUID, goalID uint) error {
+	result := s.db.Where("id = ? AND user_id = ?", goalID, userID).Delete(&models.SkillGoal{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrGoalNotFound
+	}
+	return nil
+}
+
+// track measures the goal on the user's stored matches and saves the
+// progress, completing the goal once it is met
+func (s *GoalService) track(userID uuid.UUID, goal *models.SkillGoal) (*GoalProgress, error) {
+	participants, err := s.championMatches(userID, goal.Champion, goal.CreatedAt, goal.TargetGames)
+	if err != nil {
+		return nil, err
+	}
+
+	progress := EvaluateGoal(goal, participants)
+	if goal.Status == goalStatusActive && progress.Met {
+		goal.Status = goalStatusComplete
+		goal.Achieved = true
+		goal.AchievementDate = time.Now()
+	}
+	if goal.Achieved {
+		goal.Progress = 100 // completed goals stay completed as later games come in
+	}
+
+	err = s.db.Model(goal).Select("current", "progress", "status", "achieved", "achievement_date").Updates(goal).Error
+	if err != nil {
+		return nil, err
+	}
+	progress.Goal = *goal
+	return progress, nil
+}
+
+// championMatches loads the user's latest non-remake games on the champion
+// across their linked accounts played since the goal was set, newest first
+func (s *GoalService) championMatches(userID uuid.UUID, champion string, since time.Time, limit int) ([]models.MatchParticipant, error) {
+	var puuids []string
+	err := s.db.Table("riot_accounts").Where("CAST(user_id AS TEXT) = ?", userID.String()).Pluck("puuid", &puuids).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(puuids) == 0 {
+		return []models.MatchParticipant{}, nil
+	}
+
+	names := []string{strings.ToLower(models.ChampionInternalName(champion)), strings.ToLower(models.ChampionDisplayName(champion))}
+	var participants []models.MatchParticipant
+	err = s.db.Joins("JOIN matches ON matches.id = match_participants.match_id").
+		Where("match_participants.puuid IN ? AND LOWER(match_participants.champion_name) IN ?", puuids, names).
+		Where("matches.game_duration > ?", models.RemakeMaxDuration).
+		Where("matches.game_start_timestamp >= ?", since.UnixMilli()).
+		Order("matches.game_start_timestamp DESC").
+		Limit(limit).
+		Find(&participants).Error
+	if err != nil || len(participants) == 0 {
+		return participants, err
+	}
+
+	// Load the matches by primary key; Preload("Match") would join on the
+	// Riot match ID since Match has a MatchID field of its own
+	ids := make([]uuid.UUID, len(participants))
+	for i, p := range participants {
+		ids[i] = p.MatchID
+	}
+	var matches []models.Match
+	if err := s.db.Where("id IN ?", ids).Find(&matches).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]models.Match, len(matches))
+	for _, match := range matches {
+		byID[match.ID] = match
+	}
+	for i := range participants {
+		participants[i].Match = byID[participants[i].MatchID]
+	}
+	return participants, nil
+}
+
+// newChampionGoal validates a goal request and builds the goal to store
+func newChampionGoal(req CreateGoalRequest) (*models.SkillGoal, error) {
+	champion := models.ChampionDisplayName(req.Champion)
+	if champion == "" {
+		return nil, fmt.Errorf("%w: champion is required", ErrInvalidGoal)
+	}
+
+	metric := strings.ToLower(strings.TrimSpace(req.Metric))
+	if metric == "" {
+		metric = GoalMetricWinRate
+	}
+	var target string
+	switch metric {
+	case GoalMetricWinRate:
+		if req.Target <= 0 || req.Target > 100 {
+			return nil, fmt.Errorf("%w: win rate target must be between 0 and 100", ErrInvalidGoal)
+		}
+		target = fmt.Sprintf("%.0f%% win rate", req.Target)
+	case GoalMetricKDA:
+		if req.Target <= 0 {
+			return nil, fmt.Errorf("%w: KDA target must be positive", ErrInvalidGoal)
+		}
+		target = fmt.Sprintf("%.1f KDA", req.Target)
+	case GoalMetricCSPerMin:
+		if req.Target <= 0 {
+			return nil, fmt.Errorf("%w: CS per minute target must be positive", ErrInvalidGoal)
+		}
+		target = fmt.Sprintf("%.1f CS/min", req.Target)
+	default:
+		return nil, fmt.Errorf("%w: metric must be one of win_rate, kda, cs_per_min", ErrInvalidGoal)
+	}
+
+	games := req.Games
+	if games == 0 {
+		games = DefaultGoalGames
+	}
+	if games < 1 || games > MaxGoalGames {
+		return nil, fmt.Errorf("%w: games must be between 1 and %d", ErrInvalidGoal, MaxGoalGames)
+	}
+
+	goal := &models.SkillGoal{
+		GoalType:    GoalTypeChampion,
+		Target:      fmt.Sprintf("%s on %s over %d games", target, champion, games),
+		Description: req.Description,
+		Status:      goalStatusActive,
+		Champion:    champion,
+		Metric:      metric,
+		TargetValue: req.Target,
+		TargetGames: games,
+	}
+	if req.Deadline != nil {
+		goal.Deadline = *req.Deadline
+	}
+	return goal, nil
+}
+
+// EvaluateGoal measures a champion goal over the given games, newest first.
+// The goal is met once it has been measured over its full number of games
+// and the metric reaches the target. Progress weighs the games played and how
+// close the metric is to the target equally.
+func EvaluateGoal(goal *models.SkillGoal, participants []models.MatchParticipant) *GoalProgress {
+	if len(participants) > goal.TargetGames {
+		participants = participants[:goal.TargetGames]
+	}
+
+	progress := &GoalProgress{
+		Games:       len(participants),
+		GamesNeeded: goal.TargetGames - len(participants),
+	}
+	if len(participants) > 0 {
+		progress.CurrentValue = math.Round(goalMetric(goal.Metric, participants)*100) / 100
+	}
+	progress.Met = progress.GamesNeeded == 0 && progress.CurrentValue >= goal.TargetValue
+
+	gamesShare := float64(progress.Games) / float64(goal.TargetGames)
+	valueShare := 0.0
+	if goal.TargetValue > 0 {
+		valueShare = math.Min(progress.CurrentValue/goal.TargetValue, 1)
+	}
+	goal.Progress = math.Round((gamesShare+valueShare)/2*1000) / 10
+	if progress.Met {
+		goal.Progress = 100
+	}
+	goal.Current = formatGoalValue(goal.Metric, progress.CurrentValue, progress.Games)
+
+	return progress
+}
+
+func goalMetric(metric string, participants []models.MatchParticipant) float64 {
+	var total float64
+	for _, p := range participants {
+		switch metric {
+		case GoalMetricKDA:
+			deaths := p.Deaths
+			if deaths == 0 {
+				deaths = 1
+			}
+			total += float64(p.Kills+p.Assists) / float64(deaths)
+		case GoalMetricCSPerMin:
+			if p.Match.GameDuration > 0 {
+				total += float64(p.TotalCS) / (float64(p.Match.GameDuration) / 60)
+			}
+		default:
+			if p.Won {
+				total += 100
+			}
+		}
+	}
+	return total / float64(len(participants))
+}
+
+func formatGoalValue(metric string, value float64, games int) string {
+	switch metric {
+	case GoalMetricKDA:
+		return fmt.Sprintf("%.2f KDA over %d games", value, games)
+	case GoalMetricCSPerMin:
+		return fmt.Sprintf("%.1f CS/min over %d games", value, games)
+	default:
+		return fmt.Sprintf("%.1f%% win rate over %d games", value, games)
+	}
+}
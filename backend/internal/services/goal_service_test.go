@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+func TestEvaluateGoal(t *testing.T) {
+	goal, err := newChampionGoal(CreateGoalRequest{Champion: "kaisa", Target: 60, Games: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "Kai'Sa", goal.Champion)
+	assert.Equal(t, GoalMetricWinRate, goal.Metric)
+
+	games := []models.MatchParticipant{{Won: true}, {Won: true}, {Won: false}}
+	progress := EvaluateGoal(goal, games)
+	assert.Equal(t, 3, progress.Games)
+	assert.Equal(t, 2, progress.GamesNeeded)
+	assert.False(t, progress.Met, "the goal needs its full number of games")
+
+	games = append(games, models.MatchParticipant{Won: true}, models.MatchParticipant{Won: false}, models.MatchParticipant{Won: false})
+	progress = EvaluateGoal(goal, games)
+	assert.Equal(t, 5, progress.Games, "only the latest games count")
+	assert.Equal(t, 60.0, progress.CurrentValue)
+	assert.True(t, progress.Met)
+	assert.Equal(t, 100.0, goal.Progress)
+
+	_, err = newChampionGoal(CreateGoalRequest{Champion: "Jinx", Metric: "gold", Target: 1})
+	assert.ErrorIs(t, err, ErrInvalidGoal)
+	_, err = newChampionGoal(CreateGoalRequest{Champion: "Jinx", Target: 120})
+	assert.ErrorIs(t, err, ErrInvalidGoal)
+}
+
+func TestChampionMatchesSinceGoalCreated(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE riot_accounts (user_id TEXT, puuid TEXT)`).Error)
+	createModelTable(t, db, &models.Match{})
+	createModelTable(t, db, &models.MatchParticipant{})
+
+	userID := uuid.New()
+	require.NoError(t, db.Exec(`INSERT INTO riot_accounts VALUES (?, 'puuid-main')`, userID.String()).Error)
+	goalSet := time.Now().Add(-24 * time.Hour)
+	for i, startedAt := range []time.Time{goalSet.Add(-time.Hour), goalSet.Add(time.Hour), goalSet.Add(2 * time.Hour)} {
+		matchID := uuid.New().String()
+		require.NoError(t, db.Exec(`INSERT INTO matches (id, match_id, game_start_timestamp, game_duration) VALUES (?, ?, ?, 1800)`, matchID, fmt.Sprintf("EUW1_%d", i), startedAt.UnixMilli()).Error)
+		require.NoError(t, db.Exec(`INSERT INTO match_participants (id, match_id, puuid, champion_id, champion_name, won) VALUES (?, ?, 'puuid-main', 222, 'Jinx', 1)`, uuid.New().String(), matchID).Error)
+	}
+
+	s := NewGoalService(db)
+	participants, err := s.championMatches(userID, "jinx", goalSet, 10)
+	require.NoError(t, err)
+	require.Len(t, participants, 2, "games before the goal was set don't count")
+	assert.Equal(t, "EUW1_2", participants[0].Match.MatchID)
+}
+
+// createModelTable creates the table of a model with an untyped column per
+// field, since sqlite can't run the models' postgres defaults
+func createModelTable(t *testing.T, db *gorm.DB, model interface{}) {
+	stmt := &gorm.Statement{DB: db}
+	require.NoError(t, stmt.Parse(model))
+	columns := strings.Join(stmt.Schema.DBNames, ", ")
+	require.NoError(t, db.Exec(fmt.Sprintf("CREATE TABLE %s (%s)", stmt.Schema.Table, columns)).Error)
+}
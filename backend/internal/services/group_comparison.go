@@ -0,0 +1,216 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// Metrics a player can be compared to their group on
+const (
+	GroupMetricWinRate      = "win_rate"
+	GroupMetricKDA          = "kda"
+	GroupMetricCSPerMin     = "cs_per_min"
+	GroupMetricVisionPerMin = "vision_per_min"
+	GroupMetricDamageShare  = "damage_share"
+	GroupMetricDeaths       = "deaths"
+)
+
+// GroupMetrics lists every group comparison metric in response order
+var GroupMetrics = []string{
+	GroupMetricWinRate,
+	GroupMetricKDA,
+	GroupMetricCSPerMin,
+	GroupMetricVisionPerMin,
+	GroupMetricDamageShare,
+	GroupMetricDeaths,
+}
+
+// GroupMember is a duo partner counted in the group average
+type GroupMember struct {
+	DuoTeammate
+	SharedGames int `json:"shared_games"` // games played together in the window
+	Games       int `json:"games"`        // the member's own games in the window
+}
+
+// MetricComparison is the player's value of a metric against the group's
+type MetricComparison struct {
+	Metric       string  `json:"metric"`
+	Player       float64 `json:"player"`
+	GroupAverage float64 `json:"group_average"`
+	// Delta is the player's value minus the group average; for deaths a
+	// negative delta is the better result
+	Delta float64 `json:"delta"`
+	// Rank is the player's place among themselves and the members, 1 best
+	Rank int `json:"rank"`
+}
+
+// GroupComparison compares a player's key metrics with the average of their
+// group. The group is the player's duo partners: teammates who shared at
+// least MinSharedGames of the player's games in the window. This stands in
+// for explicit group membership, which nothing stores yet: the RBAC team
+// tables are neither migrated nor routed. Once groups are stored, members
+// should come from there instead.
+type GroupComparison struct {
+	PlayerID       string             `json:"player_id"`
+	TimeRange      string             `json:"time_range"`
+	MinSharedGames int                `json:"min_shared_games"`
+	Games          int                `json:"games"`
+	Members        []GroupMember      `json:"members"`
+	Metrics        []MetricComparison `json:"metrics"`
+}
+
+// ParseGroupMetrics validates a comma-separated metrics query value; an empty
+// value selects every metric
+func ParseGroupMetrics(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return GroupMetrics, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, metric := range strings.Split(value, ",") {
+		metric = strings.ToLower(strings.TrimSpace(metric))
+		if metric == "" {
+			continue
+		}
+		if groupMetricValue(metric, models.MatchData{}) == nil {
+			return nil, fmt.Errorf("invalid metric %q: use %s", metric, strings.Join(GroupMetrics, ", "))
+		}
+		selected[metric] = true
+	}
+
+	// Keep the response order stable whatever order was requested
+	metrics := make([]string, 0, len(selected))
+	for _, metric := range GroupMetrics {
+		if selected[metric] {
+			metrics = append(metrics, metric)
+		}
+	}
+	return metrics, nil
+}
+
+// CompareWithGroup compares the user's linked accounts with the average of
+// their duo partners over timeRange. Only members of the group are loaded.
+// minSharedGames overrides the configured partner threshold when positive.
+func (as *AnalyticsService) CompareWithGroup(ctx context.Context, playerID, timeRange string, minSharedGames int, metrics []string, filter MatchFilter) (*GroupComparison, error) {
+	filter = filter.normalize()
+	if minSharedGames < 1 {
+		minSharedGames = as.duoMinSharedGames
+	}
+	if len(metrics) == 0 {
+		metrics = GroupMetrics
+	}
+	startDate, endDate := as.parseTimeRange(timeRange)
+
+	matches, _, err := as.getAccountFilteredMatches(ctx, playerID, startDate, endDate, "", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	teammates, err := as.getMatchTeammates(ctx, playerID, startDate.UnixMilli(), endDate.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get teammates: %w", err)
+	}
+	partners := BuildDuoAnalysis(matches, teammates, minSharedGames).Partners
+
+	members := make([]GroupMember, 0, len(partners))
+	memberMatches := make(map[string][]models.MatchData, len(partners))
+	for _, partner := range partners {
+		partnerMatches, _, err := as.getFilteredMatches(ctx, partner.PUUID, startDate, endDate, "", filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get matches of group member %s: %w", partner.PUUID, err)
+		}
+		memberMatches[partner.PUUID] = partnerMatches
+		members = append(members, GroupMember{DuoTeammate: partner.DuoTeammate, SharedGames: partner.Games})
+	}
+
+	comparison := BuildGroupComparison(matches, members, memberMatches, metrics)
+	comparison.PlayerID = playerID
+	comparison.TimeRange = timeRange
+	comparison.MinSharedGames = minSharedGames
+	return comparison, nil
+}
+
+// BuildGroupComparison averages each metric per member, then compares the
+// player's average with the mean of the members' averages so every member
+// counts equally however much they play. Members without games in the window
+// are listed but left out of the averages. memberMatches is keyed by PUUID.
+func BuildGroupComparison(matches []models.MatchData, members []GroupMember, memberMatches map[string][]models.MatchData, metrics []string) *GroupComparison {
+	comparison := &GroupComparison{
+		Games:   len(matches),
+		Members: members,
+		Metrics: make([]MetricComparison, 0, len(metrics)),
+	}
+	for i := range comparison.Members {
+		comparison.Members[i].Games = len(memberMatches[comparison.Members[i].PUUID])
+	}
+
+	for _, metric := range metrics {
+		result := MetricComparison{Metric: metric, Player: averageGroupMetric(metric, matches), Rank: 1}
+
+		var memberAverages []float64
+		for _, member := range comparison.Members {
+			if member.Games > 0 {
+				memberAverages = append(memberAverages, averageGroupMetric(metric, memberMatches[member.PUUID]))
+			}
+		}
+		if len(memberAverages) > 0 {
+			result.GroupAverage = mean(memberAverages)
+			result.Delta = result.Player - result.GroupAverage
+		}
+
+		for _, value := range memberAverages {
+			if (metric == GroupMetricDeaths && value < result.Player) || (metric != GroupMetricDeaths && value > result.Player) {
+				result.Rank++
+			}
+		}
+		comparison.Metrics = append(comparison.Metrics, result)
+	}
+
+	sort.SliceStable(comparison.Members, func(i, j int) bool {
+		return comparison.Members[i].SharedGames > comparison.Members[j].SharedGames
+	})
+	return comparison
+}
+
+func averageGroupMetric(metric string, matches []models.MatchData) float64 {
+	values := make([]float64, 0, len(matches))
+	for _, match := range matches {
+		if value := groupMetricValue(metric, match); value != nil {
+			values = append(values, *value)
+		}
+	}
+	return mean(values)
+}
+
+// groupMetricValue returns a match's value of metric, nil for unknown metrics
+func groupMetricValue(metric string, match models.MatchData) *float64 {
+	var value float64
+	minutes := float64(match.GameDuration) / 60
+	switch metric {
+	case GroupMetricWinRate:
+		if match.Win {
+			value = 100
+		}
+	case GroupMetricKDA:
+		value = kdaRatio(match.Kills, match.Deaths, match.Assists)
+	case GroupMetricCSPerMin:
+		if minutes > 0 {
+			value = float64(match.TotalCS) / minutes
+		}
+	case GroupMetricVisionPerMin:
+		if minutes > 0 {
+			value = float64(match.VisionScore) / minutes
+		}
+	case GroupMetricDamageShare:
+		value = match.DamageShare
+	case GroupMetricDeaths:
+		value = float64(match.Deaths)
+	default:
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// KDA thresholds for calling a game a feed or a carry
+const (
+	feedKDA  = 1.0
+	carryKDA = 5.0
+)
+
+// kdaBucketEdges are the lower bounds of the KDA histogram buckets
+var kdaBucketEdges = []float64{0, 1, 2, 3, 5, 8}
+
+// maxDeathsBucket is the deaths count whose bucket also holds every game
+// with more deaths
+const maxDeathsBucket = 10
+
+// DistributionBucket is the games whose value falls within [Min, Max)
+type DistributionBucket struct {
+	Label   string  `json:"label"`
+	Min     float64 `json:"min"`
+	Max     float64 `json:"max,omitempty"` // exclusive, 0 for no upper bound
+	Games   int     `json:"games"`
+	Share   float64 `json:"share"` // percent of all games
+	WinRate float64 `json:"win_rate"`
+}
+
+// Percentiles summarizes a distribution
+type Percentiles struct {
+	P10 float64 `json:"p10"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P90 float64 `json:"p90"`
+}
+
+// KDADistribution holds a player's KDA and deaths histograms
+type KDADistribution struct {
+	PlayerID  string `json:"player_id"`
+	TimeRange string `json:"time_range"`
+	Matches   int    `json:"matches"`
+
+	AverageKDA     float64              `json:"average_kda"`
+	KDAStdDev      float64              `json:"kda_std_dev"`
+	KDAPercentiles Percentiles          `json:"kda_percentiles"`
+	KDABuckets     []DistributionBucket `json:"kda_buckets"`
+
+	AverageDeaths     float64              `json:"average_deaths"`
+	DeathsPercentiles Percentiles          `json:"deaths_percentiles"`
+	DeathsBuckets     []DistributionBucket `json:"deaths_buckets"`
+
+	// FeedRate is the percent of games with a KDA below 1, CarryRate the
+	// percent with a KDA of 5 or more
+	FeedRate  float64 `json:"feed_rate"`
+	CarryRate float64 `json:"carry_rate"`
+}
+
+// AnalyzeKDADistribution builds the KDA and deaths distributions of the
+// user's linked accounts over the games in timeRange
+func (as *AnalyticsService) AnalyzeKDADistribution(ctx context.Context, playerID, timeRange string, filter MatchFilter) (*KDADistribution, error) {
+	filter = filter.normalize()
+	startDate, endDate := as.parseTimeRange(timeRange)
+
+	matches, _, err := as.getAccountFilteredMatches(ctx, playerID, startDate, endDate, "", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	distribution := BuildKDADistribution(matches)
+	distribution.PlayerID = playerID
+	distribution.TimeRange = timeRange
+	return distribution, nil
+}
+
+// BuildKDADistribution buckets matches by KDA and by deaths and computes the
+// percentiles of both
+func BuildKDADistribution(matches []models.MatchData) *KDADistribution {
+	kdaBuckets := make([]DistributionBucket, len(kdaBucketEdges))
+	for i, edge := range kdaBucketEdges {
+		kdaBuckets[i] = DistributionBucket{Label: fmt.Sprintf("%g+", edge), Min: edge}
+		if i+1 < len(kdaBucketEdges) {
+			kdaBuckets[i].Max = kdaBucketEdges[i+1]
+			kdaBuckets[i].Label = fmt.Sprintf("%g-%g", edge, kdaBuckets[i].Max)
+		}
+	}
+	deathsBuckets := make([]DistributionBucket, maxDeathsBucket+1)
+	for deaths := range deathsBuckets {
+		deathsBuckets[deaths] = DistributionBucket{Label: fmt.Sprint(deaths), Min: float64(deaths), Max: float64(deaths + 1)}
+	}
+	deathsBuckets[maxDeathsBucket].Label = fmt.Sprintf("%d+", maxDeathsBucket)
+	deathsBuckets[maxDeathsBucket].Max = 0
+
+	distribution := &KDADistribution{
+		Matches:       len(matches),
+		KDABuckets:    kdaBuckets,
+		DeathsBuckets: deathsBuckets,
+	}
+	if len(matches) == 0 {
+		return distribution
+	}
+
+	kdas := make([]float64, len(matches))
+	deaths := make([]float64, len(matches))
+	kdaWins := make([]int, len(kdaBuckets))
+	deathsWins := make([]int, len(deathsBuckets))
+	var feeds, carries int
+	for i, match := range matches {
+		kda := kdaRatio(match.Kills, match.Deaths, match.Assists)
+		kdas[i] = kda
+		deaths[i] = float64(match.Deaths)
+
+		kdaIdx := 0
+		for j, edge := range kdaBucketEdges {
+			if kda >= edge {
+				kdaIdx = j
+			}
+		}
+		deathsIdx := min(max(match.Deaths, 0), maxDeathsBucket)
+		kdaBuckets[kdaIdx].Games++
+		deathsBuckets[deathsIdx].Games++
+		if match.Win {
+			kdaWins[kdaIdx]++
+			deathsWins[deathsIdx]++
+		}
+
+		switch {
+		case kda < feedKDA:
+			feeds++
+		case kda >= carryKDA:
+			carries++
+		}
+	}
+
+	total := float64(len(matches))
+	finishBuckets(kdaBuckets, kdaWins, total)
+	finishBuckets(deathsBuckets, deathsWins, total)
+
+	distribution.AverageKDA = mean(kdas)
+	distribution.KDAStdDev = stdDev(kdas, distribution.AverageKDA)
+	distribution.KDAPercentiles = percentiles(kdas)
+	distribution.AverageDeaths = mean(deaths)
+	distribution.DeathsPercentiles = percentiles(deaths)
+	distribution.FeedRate = float64(feeds) / total * 100
+	distribution.CarryRate = float64(carries) / total * 100
+	return distribution
+}
+
+// finishBuckets fills each bucket's share of all games and win rate
+func finishBuckets(buckets []DistributionBucket, wins []int, total float64) {
+	for i := range buckets {
+		if buckets[i].Games == 0 {
+			continue
+		}
+		buckets[i].Share = float64(buckets[i].Games) / total * 100
+		buckets[i].WinRate = float64(wins[i]) / float64(buckets[i].Games) * 100
+	}
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func stdDev(values []float64, average float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += (v - average) * (v - average)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
+
+// percentiles returns the 10th to 90th percentiles of values, interpolating
+// linearly between the closest ranks
+func percentiles(values []float64) Percentiles {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	at := func(p float64) float64 {
+		if len(sorted) == 0 {
+			return 0
+		}
+		rank := p / 100 * float64(len(sorted)-1)
+		lower := int(math.Floor(rank))
+		upper := int(math.Ceil(rank))
+		return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+	}
+
+	return Percentiles{P10: at(10), P25: at(25), P50: at(50), P75: at(75), P90: at(90)}
+}
@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// Default thresholds for a player to be considered comfortable on a champion
+const (
+	// DefaultComfortWinRate is the cumulative win rate, in percent, that marks
+	// a champion as learned
+	DefaultComfortWinRate = 50.0
+	// DefaultComfortMinGames is the fewest games before a champion can be
+	// counted as learned, so a lucky first win doesn't count as comfort
+	DefaultComfortMinGames = 5
+)
+
+// Learning estimate tuning
+const (
+	// learningPriorWeight is how many champions' worth of evidence the
+	// difficulty baseline is worth when blended with the player's own ramp
+	learningPriorWeight = 2.0
+	// learningSimilarWeight is how much more a champion of the same
+	// difficulty counts toward the player's ramp than any other champion
+	learningSimilarWeight = 2.0
+	// learningMinFactor and learningMaxFactor bound the personal ramp factor
+	learningMinFactor = 0.5
+	learningMaxFactor = 3.0
+)
+
+// Estimate bases
+const (
+	LearningBasisPersonal   = "personal"
+	LearningBasisDifficulty = "difficulty"
+)
+
+// learningMethodology explains how EstimatedGames is derived
+const learningMethodology = "Each difficulty rating has a baseline number of games to comfort (1: 10, 2: 25, 3: 50). " +
+	"For every champion the player has played, the ramp is the number of games until their cumulative win rate " +
+	"first reached the comfort win rate after the minimum number of games; champions that never got there count " +
+	"only once they have been played past their baseline. Each ramp is divided by its champion's baseline, and " +
+	"the ratios are averaged with champions of the same difficulty counting double. That personal factor, " +
+	"bounded to 0.5-3x and blended toward 1x when few champions back it, scales the baseline of the " +
+	"recommended champion's difficulty."
+
+// ChampionRamp is how long a player took to get comfortable on one champion
+type ChampionRamp struct {
+	Champion   string `json:"champion"`
+	Difficulty int    `json:"difficulty,omitempty"`
+	Games      int    `json:"games"`
+	// GamesToComfort is the game on which the player reached the comfort win
+	// rate; zero if they never did
+	GamesToComfort int `json:"games_to_comfort"`
+}
+
+// LearningEstimate is the expected number of games for a player to become
+// comfortable on a champion, with the inputs it was derived from
+type LearningEstimate struct {
+	ExpectedGames   int      `json:"expected_games_to_comfort"`
+	BaselineGames   int      `json:"baseline_games"`
+	RampFactor      float64  `json:"ramp_factor"`
+	Basis           string   `json:"basis"`
+	SampleChampions []string `json:"sample_champions"`
+}
+
+// SetLearningCurve sets the cumulative win rate, in percent, and the fewest
+// games that mark a champion as learned; values out of range restore the
+// defaults
+func (mas *MetaAnalyticsService) SetLearningCurve(minGames int, winRate float64) {
+	if minGames < 1 {
+		minGames = DefaultComfortMinGames
+	}
+	if winRate <= 0 || winRate > 100 {
+		winRate = DefaultComfortWinRate
+	}
+	mas.comfortMinGames = minGames
+	mas.comfortWinRate = winRate
+}
+
+// loadChampionRamps replays the whole match history of the given accounts
+// oldest first to measure the player's ramp on each champion. A single
+// account is paged through in batches; the histories of several accounts are
+// merged by date so a champion played on more than one is replayed in order.
+func (mas *MetaAnalyticsService) loadChampionRamps(ctx context.Context, puuids []string) ([]ChampionRamp, error) {
+	if len(puuids) != 1 {
+		matches := make([]models.MatchData, 0)
+		for _, puuid := range puuids {
+			accountMatches, err := mas.analyticsService.loadPlayerMatches(ctx, puuid, championStatsEpoch, time.Now())
+			if err != nil {
+				return nil, fmt.Errorf("failed to load match history: %w", err)
+			}
+			matches = append(matches, accountMatches...)
+		}
+		sort.SliceStable(matches, func(i, j int) bool {
+			return matches[i].Date.Before(matches[j].Date)
+		})
+		return BuildChampionRamps(matches, mas.comfortMinGames, mas.comfortWinRate), nil
+	}
+
+	ramps := newRampAccumulator(mas.comfortMinGames, mas.comfortWinRate)
+	err := mas.analyticsService.forEachMatchBatch(ctx, puuids[0], championStatsEpoch, time.Now(), func(batch []models.MatchData) error {
+		for _, match := range batch {
+			ramps.add(match)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load match history: %w", err)
+	}
+	return ramps.result(), nil
+}
+
+// BuildChampionRamps measures the player's ramp on each champion from matches
+// ordered oldest first
+func BuildChampionRamps(matches []models.MatchData, minGames int, winRate float64) []ChampionRamp {
+	ramps := newRampAccumulator(minGames, winRate)
+	for _, match := range matches {
+		ramps.add(match)
+	}
+	return ramps.result()
+}
+
+// EstimateLearningCurve scales the baseline games to comfort for a champion
+// of the given difficulty by how quickly the player ramped on the champions
+// they have already played
+func EstimateLearningCurve(difficulty int, ramps []ChampionRamp) LearningEstimate {
+	baseline, ok := gamesToLearn[difficulty]
+	if !ok {
+		baseline = gamesToLearn[2]
+	}
+
+	estimate := LearningEstimate{
+		BaselineGames:   baseline,
+		RampFactor:      1,
+		Basis:           LearningBasisDifficulty,
+		SampleChampions: []string{},
+	}
+
+	var weightedRatio, totalWeight float64
+	samples := 0
+	for _, ramp := range ramps {
+		rampBaseline, ok := gamesToLearn[ramp.Difficulty]
+		if !ok {
+			continue
+		}
+
+		games := ramp.GamesToComfort
+		if games == 0 {
+			// Not comfortable yet: only informative once past the baseline
+			if ramp.Games <= rampBaseline {
+				continue
+			}
+			games = ramp.Games
+		}
+
+		weight := 1.0
+		if ramp.Difficulty == difficulty {
+			weight = learningSimilarWeight
+		}
+		weightedRatio += weight * float64(games) / float64(rampBaseline)
+		totalWeight += weight
+		samples++
+		estimate.SampleChampions = append(estimate.SampleChampions, ramp.Champion)
+	}
+
+	if samples > 0 {
+		factor := math.Max(learningMinFactor, math.Min(learningMaxFactor, weightedRatio/totalWeight))
+		// Shrink toward the baseline when only a few champions back the factor
+		n := float64(samples)
+		factor = 1 + (factor-1)*n/(n+learningPriorWeight)
+
+		estimate.RampFactor = math.Round(factor*100) / 100
+		estimate.Basis = LearningBasisPersonal
+	}
+
+	estimate.ExpectedGames = int(math.Round(float64(baseline) * estimate.RampFactor))
+	return estimate
+}
+
+// rampAccumulator tracks each champion's running record one match at a time
+type rampAccumulator struct {
+	minGames int
+	winRate  float64
+	order    []string
+	ramps    map[string]*championRampState
+}
+
+type championRampState struct {
+	ramp ChampionRamp
+	wins int
+}
+
+func newRampAccumulator(minGames int, winRate float64) *rampAccumulator {
+	if minGames < 1 {
+		minGames = DefaultComfortMinGames
+	}
+	if winRate <= 0 || winRate > 100 {
+		winRate = DefaultComfortWinRate
+	}
+	return &rampAccumulator{minGames: minGames, winRate: winRate, ramps: make(map[string]*championRampState)}
+}
+
+func (r *rampAccumulator) add(match models.MatchData) {
+	name := models.ResolveChampionAlias(match.ChampionName)
+	state, ok := r.ramps[name]
+	if !ok {
+		state = &championRampState{ramp: ChampionRamp{Champion: name}}
+		if traits, ok := models.LookupChampionTraits(name); ok {
+			state.ramp.Difficulty = traits.Difficulty
+		}
+		r.ramps[name] = state
+		r.order = append(r.order, name)
+	}
+
+	state.ramp.Games++
+	if match.Win {
+		state.wins++
+	}
+	if state.ramp.GamesToComfort == 0 && state.ramp.Games >= r.minGames &&
+		float64(state.wins)/float64(state.ramp.Games)*100 >= r.winRate {
+		state.ramp.GamesToComfort = state.ramp.Games
+	}
+}
+
+func (r *rampAccumulator) result() []ChampionRamp {
+	ramps := make([]ChampionRamp, 0, len(r.order))
+	for _, name := range r.order {
+		ramps = append(ramps, r.ramps[name].ramp)
+	}
+	sort.SliceStable(ramps, func(i, j int) bool {
+		return ramps[i].Games > ramps[j].Games
+	})
+	return ramps
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+func TestMatchFilterChampions(t *testing.T) {
+	matches := []models.MatchData{
+		{MatchID: "1", ChampionName: "Jinx", Win: true},
+		{MatchID: "2", ChampionName: "MonkeyKing", Win: false},
+		{MatchID: "3", ChampionName: "Caitlyn", Win: true},
+		{MatchID: "4", ChampionName: "Jinx", Win: false},
+	}
+	ids := func(matches []models.MatchData) []string {
+		var ids []string
+		for _, m := range matches {
+			ids = append(ids, m.MatchID)
+		}
+		return ids
+	}
+
+	filtered, _ := MatchFilter{IncludeChampions: []string{"jinx", "Wukong"}}.apply(matches)
+	assert.Equal(t, []string{"1", "2", "4"}, ids(filtered))
+
+	filtered, _ = MatchFilter{ExcludeChampions: []string{"Jinx"}}.apply(matches)
+	assert.Equal(t, []string{"2", "3"}, ids(filtered))
+
+	// Exclusions win over inclusions and combine with the other filters
+	filtered, _ = MatchFilter{
+		Result:           ResultWin,
+		IncludeChampions: []string{"Jinx", "Caitlyn"},
+		ExcludeChampions: []string{"Caitlyn"},
+	}.apply(matches)
+	assert.Equal(t, []string{"1"}, ids(filtered))
+
+	// Equal lists share a cache key whatever their order, case or name form
+	a := MatchFilter{IncludeChampions: []string{"Wukong", "jinx"}}
+	b := MatchFilter{IncludeChampions: []string{"Jinx", "MonkeyKing", "Jinx"}}
+	assert.Equal(t, a.cacheKey(), b.cacheKey())
+	assert.NotEqual(t, a.cacheKey(), MatchFilter{}.cacheKey())
+	assert.Equal(t, "all:false:false", MatchFilter{}.cacheKey())
+}
+
+func TestMatchFilterChampionQuery(t *testing.T) {
+	query, ok := MatchFilter{IncludeChampions: []string{"wukong", "Jinx"}, ExcludeChampions: []string{"Caitlyn"}}.championQuery("")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Jinx", "Wukong", "MonkeyKing"}, query.Include)
+	assert.Equal(t, []string{"Caitlyn"}, query.Exclude)
+
+	sql, args := query.Where("mp.champion_name", []interface{}{"puuid"})
+	assert.Equal(t, " AND LOWER(mp.champion_name) IN ($2, $3, $4) AND LOWER(mp.champion_name) NOT IN ($5)", sql)
+	assert.Equal(t, []interface{}{"puuid", "jinx", "wukong", "monkeyking", "caitlyn"}, args)
+
+	// A single champion narrows the include list, or matches nothing outside it
+	query, ok = MatchFilter{IncludeChampions: []string{"Jinx", "Caitlyn"}}.championQuery("jinx")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"Jinx"}, query.Include)
+	_, ok = MatchFilter{IncludeChampions: []string{"Jinx"}}.championQuery("Caitlyn")
+	assert.False(t, ok)
+	_, ok = MatchFilter{ExcludeChampions: []string{"Jinx"}}.championQuery("Jinx")
+	assert.False(t, ok)
+
+	assert.True(t, MatchFilter{}.isEmpty())
+	assert.True(t, MatchFilter{Result: ResultAll, IncludeChampions: []string{" "}}.isEmpty())
+	assert.False(t, MatchFilter{ExcludeChampions: []string{"Jinx"}}.isEmpty())
+}
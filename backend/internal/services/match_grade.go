@@ -0,0 +1,188 @@
+package services
+
+import (
+	"math"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// Match grades, best first
+const (
+	GradeS = "S"
+	GradeA = "A"
+	GradeB = "B"
+	GradeC = "C"
+	GradeD = "D"
+)
+
+// GradeThresholds are the lowest 0-100 scores graded S, A, B and C
+type GradeThresholds struct {
+	S float64 `json:"s"`
+	A float64 `json:"a"`
+	B float64 `json:"b"`
+	C float64 `json:"c"`
+}
+
+// DefaultGradeThresholds grade a game that matches the role benchmarks a B
+var DefaultGradeThresholds = GradeThresholds{S: 80, A: 65, B: 50, C: 35}
+
+// gradeThresholdsFromConfig reads the configured thresholds, falling back to
+// the defaults unless they are in descending order
+func gradeThresholdsFromConfig(cfg *config.Config) GradeThresholds {
+	if cfg == nil {
+		return DefaultGradeThresholds
+	}
+	t := GradeThresholds{S: cfg.Analytics.GradeS, A: cfg.Analytics.GradeA, B: cfg.Analytics.GradeB, C: cfg.Analytics.GradeC}
+	if !(t.S > t.A && t.A > t.B && t.B > t.C && t.C >= 0) {
+		return DefaultGradeThresholds
+	}
+	return t
+}
+
+// roleBenchmark is a typical game in a role
+type roleBenchmark struct {
+	KDA          float64
+	CSPerMin     float64
+	DamageShare  float64 // percent of the team's damage to champions
+	VisionPerMin float64
+}
+
+// roleBenchmarks are keyed by team position
+var roleBenchmarks = map[string]roleBenchmark{
+	"TOP":     {KDA: 2.8, CSPerMin: 7.0, DamageShare: 22, VisionPerMin: 0.6},
+	"JUNGLE":  {KDA: 3.2, CSPerMin: 5.5, DamageShare: 18, VisionPerMin: 1.0},
+	"MIDDLE":  {KDA: 3.0, CSPerMin: 7.5, DamageShare: 25, VisionPerMin: 0.7},
+	"BOTTOM":  {KDA: 3.2, CSPerMin: 8.0, DamageShare: 27, VisionPerMin: 0.6},
+	"UTILITY": {KDA: 3.2, CSPerMin: 1.2, DamageShare: 10, VisionPerMin: 2.2},
+}
+
+// defaultRoleBenchmark is used for games without a known position
+var defaultRoleBenchmark = roleBenchmark{KDA: 3.0, CSPerMin: 6.0, DamageShare: 20, VisionPerMin: 0.8}
+
+// How much each stat counts toward the score. Supports are judged less on CS.
+var (
+	gradeWeights        = roleBenchmark{KDA: 0.35, CSPerMin: 0.2, DamageShare: 0.25, VisionPerMin: 0.2}
+	supportGradeWeights = roleBenchmark{KDA: 0.35, CSPerMin: 0.05, DamageShare: 0.2, VisionPerMin: 0.4}
+)
+
+// MatchGradeStats are one player's stats in a game
+type MatchGradeStats struct {
+	Position              string
+	Kills                 int
+	Deaths                int
+	Assists               int
+	TotalCS               int
+	DamageToChampions     int
+	TeamDamageToChampions int
+	VisionScore           int
+	GameDuration          int // seconds
+}
+
+// MatchGrade is a post-game style grade of one player's game
+type MatchGrade struct {
+	Grade string  `json:"grade"`
+	Score float64 `json:"score"` // 0-100, 50 matches the role benchmarks
+}
+
+// GradeMatch scores a game from KDA, CS per minute, damage share and vision
+// per minute relative to the role's benchmarks, then grades the score. Each
+// stat scores 50 at the benchmark and 100 at twice the benchmark. Remakes are
+// not graded and give nil.
+func GradeMatch(stats MatchGradeStats, thresholds GradeThresholds) *MatchGrade {
+	if stats.GameDuration <= models.RemakeMaxDuration {
+		return nil
+	}
+
+	benchmark, ok := roleBenchmarks[stats.Position]
+	if !ok {
+		benchmark = defaultRoleBenchmark
+	}
+	weights := gradeWeights
+	if stats.Position == "UTILITY" {
+		weights = supportGradeWeights
+	}
+
+	minutes := float64(stats.GameDuration) / 60
+	var damageShare float64
+	if stats.TeamDamageToChampions > 0 {
+		damageShare = float64(stats.DamageToChampions) / float64(stats.TeamDamageToChampions) * 100
+	}
+
+	score := weights.KDA*statScore(kdaRatio(stats.Kills, stats.Deaths, stats.Assists), benchmark.KDA) +
+		weights.CSPerMin*statScore(float64(stats.TotalCS)/minutes, benchmark.CSPerMin) +
+		weights.DamageShare*statScore(damageShare, benchmark.DamageShare) +
+		weights.VisionPerMin*statScore(float64(stats.VisionScore)/minutes, benchmark.VisionPerMin)
+	score = math.Round(score*10) / 10
+
+	return &MatchGrade{Grade: thresholds.grade(score), Score: score}
+}
+
+// statScore scores a stat against its benchmark, from 0 to 100
+func statScore(value, benchmark float64) float64 {
+	if benchmark <= 0 {
+		return 50
+	}
+	return math.Max(0, math.Min(value/benchmark*50, 100))
+}
+
+func (t GradeThresholds) grade(score float64) string {
+	switch {
+	case score >= t.S:
+		return GradeS
+	case score >= t.A:
+		return GradeA
+	case score >= t.B:
+		return GradeB
+	case score >= t.C:
+		return GradeC
+	default:
+		return GradeD
+	}
+}
+
+// GradeParticipants grades every participant of a match detail response
+func (s *RiotService) GradeParticipants(match *MatchDetails) {
+	thresholds := gradeThresholdsFromConfig(s.config)
+
+	teamDamage := make(map[int]int)
+	for _, p := range match.Info.Participants {
+		teamDamage[p.TeamID] += p.TotalDamageDealtToChampions
+	}
+	for i := range match.Info.Participants {
+		p := &match.Info.Participants[i]
+		p.Grade = GradeMatch(MatchGradeStats{
+			Position:              p.TeamPosition,
+			Kills:                 p.Kills,
+			Deaths:                p.Deaths,
+			Assists:               p.Assists,
+			TotalCS:               p.TotalMinionsKilled + p.NeutralMinionsKilled,
+			DamageToChampions:     p.TotalDamageDealtToChampions,
+			TeamDamageToChampions: teamDamage[p.TeamID],
+			VisionScore:           p.VisionScore,
+			GameDuration:          match.Info.GameDuration,
+		}, thresholds)
+	}
+}
+
+// gradeStoredMatch grades a stored participant; the match's participants must
+// be loaded for the damage share
+func gradeStoredMatch(p models.MatchParticipant, thresholds GradeThresholds) *MatchGrade {
+	var teamDamage int
+	for _, teammate := range p.Match.Participants {
+		if teammate.TeamID == p.TeamID {
+			teamDamage += teammate.TotalDamageDealtToChampions
+		}
+	}
+	return GradeMatch(MatchGradeStats{
+		Position:              p.TeamPosition,
+		Kills:                 p.Kills,
+		Deaths:                p.Deaths,
+		Assists:               p.Assists,
+		TotalCS:               p.TotalCS,
+		DamageToChampions:     p.TotalDamageDealtToChampions,
+		TeamDamageToChampions: teamDamage,
+		VisionScore:           p.VisionScore,
+		GameDuration:          p.Match.GameDuration,
+	}, thresholds)
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+)
+
+func TestGradeMatch(t *testing.T) {
+	// A 30 minute mid game right on the role benchmarks
+	benchmark := MatchGradeStats{
+		Position:              "MIDDLE",
+		Kills:                 3,
+		Deaths:                2,
+		Assists:               3,
+		TotalCS:               225,
+		DamageToChampions:     25000,
+		TeamDamageToChampions: 100000,
+		VisionScore:           21,
+		GameDuration:          30 * 60,
+	}
+	grade := GradeMatch(benchmark, DefaultGradeThresholds)
+	require.NotNil(t, grade)
+	assert.Equal(t, 50.0, grade.Score)
+	assert.Equal(t, GradeB, grade.Grade)
+
+	carry := benchmark
+	carry.Kills, carry.Deaths, carry.Assists = 10, 1, 8
+	carry.TotalCS = 450
+	carry.DamageToChampions = 50000
+	carry.VisionScore = 42
+	grade = GradeMatch(carry, DefaultGradeThresholds)
+	require.NotNil(t, grade)
+	assert.Equal(t, 100.0, grade.Score)
+	assert.Equal(t, GradeS, grade.Grade)
+
+	feed := benchmark
+	feed.Kills, feed.Deaths, feed.Assists = 0, 10, 1
+	feed.TotalCS = 60
+	feed.DamageToChampions = 5000
+	feed.VisionScore = 3
+	grade = GradeMatch(feed, DefaultGradeThresholds)
+	require.NotNil(t, grade)
+	assert.Equal(t, GradeD, grade.Grade)
+
+	// Custom thresholds move the same game up a grade
+	grade = GradeMatch(benchmark, GradeThresholds{S: 70, A: 50, B: 40, C: 20})
+	require.NotNil(t, grade)
+	assert.Equal(t, GradeA, grade.Grade)
+
+	remake := benchmark
+	remake.GameDuration = 200
+	assert.Nil(t, GradeMatch(remake, DefaultGradeThresholds))
+}
+
+func TestGradeThresholdsFromConfig(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Analytics.GradeS, cfg.Analytics.GradeA, cfg.Analytics.GradeB, cfg.Analytics.GradeC = 90, 70, 55, 40
+	assert.Equal(t, GradeThresholds{S: 90, A: 70, B: 55, C: 40}, gradeThresholdsFromConfig(cfg))
+
+	// Out of order thresholds fall back to the defaults
+	cfg.Analytics.GradeC = 60
+	assert.Equal(t, DefaultGradeThresholds, gradeThresholdsFromConfig(cfg))
+	assert.Equal(t, DefaultGradeThresholds, gradeThresholdsFromConfig(nil))
+}
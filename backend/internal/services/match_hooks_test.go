@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+func TestMatchHooks(t *testing.T) {
+	s := &RiotService{}
+
+	var seen []string
+	s.OnMatchStored(func(userID string, match *models.Match) {
+		seen = append(seen, userID+":"+match.MatchID)
+	})
+	s.OnMatchStored(func(userID string, match *models.Match) {
+		panic("plugin bug")
+	})
+	s.OnMatchStored(func(userID string, match *models.Match) {
+		seen = append(seen, "last:"+match.MatchID)
+	})
+
+	assert.NotPanics(t, func() {
+		s.runMatchHooks("user-1", []*models.Match{{MatchID: "NA1_1"}, {MatchID: "NA1_2"}})
+	})
+	assert.Equal(t, []string{"user-1:NA1_1", "last:NA1_1", "user-1:NA1_2", "last:NA1_2"}, seen)
+
+	// Failed saves store nothing, so no hook runs
+	seen = nil
+	s.runMatchHooks("user-1", nil)
+	assert.Empty(t, seen)
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// matchListCache keeps the latest match-ID list fetched per player for a
+// short time so repeated syncs don't re-request Riot's match list when nothing
+// new has been played
+type matchListCache struct {
+	mu      sync.Mutex
+	entries map[string]matchListEntry
+}
+
+type matchListEntry struct {
+	puuid     string
+	matchIDs  []string
+	count     int // match IDs requested; fewer means the history ended
+	fetchedAt time.Time
+}
+
+func newMatchListCache() *matchListCache {
+	return &matchListCache{entries: make(map[string]matchListEntry)}
+}
+
+// matchListKey keys lists by region, player and filter, as a filtered list
+// can't serve an unfiltered request or one with another filter
+func matchListKey(region, puuid string, filter MatchListFilter) string {
+	return strings.ToLower(region) + ":" + puuid + filter.query()
+}
+
+// get returns the first count cached match IDs when the entry is younger
+// than ttl and covers count
+func (c *matchListCache) get(region, puuid string, filter MatchListFilter, count int, ttl time.Duration) ([]string, bool) {
+	if ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[matchListKey(region, puuid, filter)]
+	if !ok || time.Since(entry.fetchedAt) >= ttl {
+		return nil, false
+	}
+	// A shorter request can be served from a longer list, and so can any
+	// request once the list ended before the count asked for
+	if entry.count < count && len(entry.matchIDs) == entry.count {
+		return nil, false
+	}
+
+	n := min(count, len(entry.matchIDs))
+	return append([]string(nil), entry.matchIDs[:n]...), true
+}
+
+func (c *matchListCache) put(region, puuid string, filter MatchListFilter, count int, matchIDs []string, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[matchListKey(region, puuid, filter)] = matchListEntry{
+		puuid:     puuid,
+		matchIDs:  append([]string(nil), matchIDs...),
+		count:     count,
+		fetchedAt: time.Now(),
+	}
+
+	// Drop expired entries so the cache only holds recently synced players
+	for key, entry := range c.entries {
+		if time.Since(entry.fetchedAt) >= ttl {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// invalidate drops the player's cached lists in every region and for every
+// filter fetched before activeAt; a zero activeAt drops them unconditionally
+func (c *matchListCache) invalidate(puuid string, activeAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, entry := range c.entries {
+		if entry.puuid == puuid && (activeAt.IsZero() || entry.fetchedAt.Before(activeAt)) {
+			delete(c.entries, key)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchListCache(t *testing.T) {
+	cache := newMatchListCache()
+	ids := []string{"EUW1_3", "EUW1_2", "EUW1_1"}
+
+	cache.put("euw1", "puuid", MatchListFilter{}, 3, ids, time.Minute)
+	cached, ok := cache.get("EUW1", "puuid", MatchListFilter{}, 2, time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"EUW1_3", "EUW1_2"}, cached)
+
+	_, ok = cache.get("euw1", "puuid", MatchListFilter{}, 20, time.Minute)
+	assert.False(t, ok, "a full page may have older matches beyond it")
+
+	cache.put("euw1", "short", MatchListFilter{}, 20, ids, time.Minute)
+	cached, ok = cache.get("euw1", "short", MatchListFilter{}, 50, time.Minute)
+	assert.True(t, ok, "a list that ended early covers any count")
+	assert.Len(t, cached, 3)
+
+	_, ok = cache.get("euw1", "puuid", MatchListFilter{}, 2, 0)
+	assert.False(t, ok, "a zero TTL disables the cache")
+
+	ranked := MatchListFilter{Type: "ranked"}
+	_, ok = cache.get("euw1", "puuid", ranked, 2, time.Minute)
+	assert.False(t, ok, "an unfiltered list can't serve a filtered request")
+	cache.put("euw1", "puuid", ranked, 3, ids[:1], time.Minute)
+	cached, ok = cache.get("euw1", "puuid", ranked, 2, time.Minute)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"EUW1_3"}, cached)
+
+	cache.invalidate("puuid", time.Now().Add(-time.Hour))
+	_, ok = cache.get("euw1", "puuid", MatchListFilter{}, 2, time.Minute)
+	assert.True(t, ok, "activity before the fetch keeps the list")
+
+	cache.invalidate("puuid", time.Now().Add(time.Second))
+	_, ok = cache.get("euw1", "puuid", MatchListFilter{}, 2, time.Minute)
+	assert.False(t, ok, "newer activity drops the list")
+	_, ok = cache.get("euw1", "puuid", ranked, 2, time.Minute)
+	assert.False(t, ok, "newer activity drops filtered lists too")
+}
+
+func TestMatchListFilter(t *testing.T) {
+	assert.NoError(t, MatchListFilter{}.Validate())
+	assert.Empty(t, MatchListFilter{}.query())
+
+	filter := MatchListFilter{Type: "ranked", Queue: 420}
+	assert.NoError(t, filter.Validate())
+	assert.Equal(t, "&type=ranked&queue=420", filter.query())
+
+	assert.ErrorIs(t, MatchListFilter{Type: "arena"}.Validate(), ErrInvalidMatchFilter)
+	assert.ErrorIs(t, MatchListFilter{Queue: -1}.Validate(), ErrInvalidMatchFilter)
+}
@@ -3,11 +3,11 @@ package services
 import (
 	"context"
 	"fmt"
-	"log"
 	"sync"
 	"time"
 
 	"github.com/herald-lol/herald/backend/internal/analytics"
+	"github.com/herald-lol/herald/backend/internal/logger"
 	"github.com/herald-lol/herald/backend/internal/match"
 	"github.com/herald-lol/herald/backend/internal/riot"
 )
@@ -29,6 +29,10 @@ type MatchProcessingService struct {
 	workers         int
 	shutdown        chan bool
 	wg              sync.WaitGroup
+
+	// Job tracking
+	jobs   map[string]*MatchProcessingJob
+	jobsMu sync.RWMutex
 }
 
 // MatchProcessingConfig contains service configuration
@@ -53,6 +57,10 @@ type MatchProcessingConfig struct {
 
 	// Rate limiting
 	ProcessingRateLimit int `json:"processing_rate_limit"` // matches per minute
+
+	// Job retention
+	JobCleanupInterval time.Duration `json:"job_cleanup_interval"` // how often cleanupOldJobs runs
+	JobRetention       time.Duration `json:"job_retention"`        // how long finished jobs are kept
 }
 
 // MatchProcessingJob represents a match processing job
@@ -147,6 +155,10 @@ func NewMatchProcessingService(
 	if config == nil {
 		config = DefaultMatchProcessingConfig()
 	}
+	if config.JobCleanupInterval <= 0 {
+		// time.NewTicker panics on a non-positive interval
+		config.JobCleanupInterval = DefaultMatchProcessingConfig().JobCleanupInterval
+	}
 
 	// Create match analyzer
 	analyzerConfig := match.DefaultMatchAnalysisConfig()
@@ -164,10 +176,12 @@ func NewMatchProcessingService(
 		processingQueue: make(chan *MatchProcessingJob, config.QueueSize),
 		workers:         config.WorkerCount,
 		shutdown:        make(chan bool),
+		jobs:            make(map[string]*MatchProcessingJob),
 	}
 
 	// Start worker goroutines
 	service.startWorkers()
+	go service.cleanupOldJobs()
 
 	return service
 }
@@ -191,11 +205,14 @@ func (s *MatchProcessingService) ProcessMatch(ctx context.Context, matchID, play
 		CreatedAt:   time.Now(),
 		Status:      "pending",
 	}
+	// Workers update the queued job, so the caller gets a copy
+	queued := *job
 
 	select {
 	case s.processingQueue <- job:
-		log.Printf("Match processing job queued: %s", job.ID)
-		return job, nil
+		s.trackJob(job)
+		logger.Debugf("Match processing job queued: %s", job.ID)
+		return &queued, nil
 	case <-ctx.Done():
 		return nil, fmt.Errorf("processing queue full or context cancelled")
 	default:
@@ -220,11 +237,15 @@ func (s *MatchProcessingService) ProcessMatchSync(ctx context.Context, matchID,
 		case <-timeout:
 			return nil, fmt.Errorf("processing timeout for job %s", job.ID)
 		case <-ticker.C:
-			if job.Status == "completed" {
-				return job.Result, nil
+			status, err := s.GetJobStatus(job.ID)
+			if err != nil {
+				continue
 			}
-			if job.Status == "failed" {
-				return nil, fmt.Errorf("processing failed: %s", job.Error)
+			if status.Status == "completed" {
+				return status.Result, nil
+			}
+			if status.Status == "failed" {
+				return nil, fmt.Errorf("processing failed: %s", status.Error)
 			}
 		case <-ctx.Done():
 			return nil, ctx.Err()
@@ -297,11 +318,56 @@ func (s *MatchProcessingService) ProcessMatchBatch(ctx context.Context, request
 	return batchResult, nil
 }
 
-// GetJobStatus returns the status of a processing job
+// GetJobStatus returns a snapshot of a processing job
 func (s *MatchProcessingService) GetJobStatus(jobID string) (*MatchProcessingJob, error) {
-	// In a real implementation, this would query a database or cache
-	// For now, we'll return a mock response
-	return nil, fmt.Errorf("job status tracking not yet implemented")
+	s.jobsMu.RLock()
+	defer s.jobsMu.RUnlock()
+
+	job, exists := s.jobs[jobID]
+	if !exists {
+		return nil, fmt.Errorf("job not found: %s", jobID)
+	}
+
+	snapshot := *job
+	return &snapshot, nil
+}
+
+func (s *MatchProcessingService) trackJob(job *MatchProcessingJob) {
+	s.jobsMu.Lock()
+	s.jobs[job.ID] = job
+	s.jobsMu.Unlock()
+}
+
+// cleanupOldJobs periodically drops finished jobs older than the configured retention
+func (s *MatchProcessingService) cleanupOldJobs() {
+	ticker := time.NewTicker(s.config.JobCleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.shutdown:
+			return
+		case <-ticker.C:
+			cutoff := time.Now().Add(-s.config.JobRetention)
+			removed := 0
+
+			s.jobsMu.Lock()
+			for id, job := range s.jobs {
+				if job.CompletedAt == nil || job.Status == "pending" || job.Status == "processing" {
+					continue
+				}
+				if job.CompletedAt.Before(cutoff) {
+					delete(s.jobs, id)
+					removed++
+				}
+			}
+			s.jobsMu.Unlock()
+
+			if removed > 0 {
+				logger.Debugf("Cleaned up %d finished match processing jobs", removed)
+			}
+		}
+	}
 }
 
 // Worker implementation
@@ -326,10 +392,14 @@ func (s *MatchProcessingService) worker(workerID string) {
 	}
 }
 
+// processJob runs a job and records its outcome. Job fields that change are
+// written under jobsMu so GetJobStatus can copy the job at any time.
 func (s *MatchProcessingService) processJob(job *MatchProcessingJob, workerID string) {
 	startTime := time.Now()
+	s.jobsMu.Lock()
 	job.Status = "processing"
 	job.StartedAt = &startTime
+	s.jobsMu.Unlock()
 
 	ctx, cancel := context.WithTimeout(context.Background(), s.config.ProcessingTimeout)
 	defer cancel()
@@ -338,6 +408,7 @@ func (s *MatchProcessingService) processJob(job *MatchProcessingJob, workerID st
 	result, err := s.doProcessMatch(ctx, job, workerID)
 
 	completedAt := time.Now()
+	s.jobsMu.Lock()
 	job.CompletedAt = &completedAt
 
 	if err != nil {
@@ -345,38 +416,45 @@ func (s *MatchProcessingService) processJob(job *MatchProcessingJob, workerID st
 		if job.RetryCount < s.config.RetryAttempts {
 			job.RetryCount++
 			job.Status = "pending"
+			attempt := job.RetryCount + 1
+			s.jobsMu.Unlock()
 
 			// Retry after delay
 			go func() {
 				time.Sleep(s.config.RetryDelay)
 				select {
 				case s.processingQueue <- job:
-					log.Printf("Retrying job %s (attempt %d)", job.ID, job.RetryCount+1)
+					logger.Warnf("Retrying job %s (attempt %d)", job.ID, attempt)
 				default:
-					log.Printf("Failed to queue retry for job %s", job.ID)
+					logger.Errorf("Failed to queue retry for job %s", job.ID)
+					s.jobsMu.Lock()
 					job.Status = "failed"
+					s.jobsMu.Unlock()
 				}
 			}()
 			return
 		}
 		job.Status = "failed"
-		log.Printf("Job failed permanently: %s - %s", job.ID, err.Error())
-	} else {
-		job.Status = "completed"
-		job.Result = result
-		log.Printf("Job completed successfully: %s", job.ID)
+		s.jobsMu.Unlock()
+		logger.Errorf("Job failed permanently: %s - %s", job.ID, err.Error())
+		return
 	}
 
+	job.Status = "completed"
+	job.Result = result
+	s.jobsMu.Unlock()
+	logger.Debugf("Job completed successfully: %s", job.ID)
+
 	// Execute callbacks
-	if job.Result != nil {
+	if result != nil {
 		for _, callback := range job.Callbacks {
 			go func(cb func(*MatchProcessingResult)) {
 				defer func() {
 					if r := recover(); r != nil {
-						log.Printf("Callback panic for job %s: %v", job.ID, r)
+						logger.Errorf("Callback panic for job %s: %v", job.ID, r)
 					}
 				}()
-				cb(job.Result)
+				cb(result)
 			}(callback)
 		}
 	}
@@ -504,7 +582,7 @@ func (s *MatchProcessingService) calculateBatchStats(batchResult *MatchBatchProc
 
 // Shutdown gracefully shuts down the match processing service
 func (s *MatchProcessingService) Shutdown(ctx context.Context) error {
-	log.Println("Shutting down match processing service...")
+	logger.Infof("Shutting down match processing service...")
 
 	close(s.shutdown)
 
@@ -517,7 +595,7 @@ func (s *MatchProcessingService) Shutdown(ctx context.Context) error {
 
 	select {
 	case <-done:
-		log.Println("Match processing service shut down successfully")
+		logger.Infof("Match processing service shut down successfully")
 		return nil
 	case <-ctx.Done():
 		return fmt.Errorf("shutdown timeout exceeded")
@@ -540,5 +618,7 @@ func DefaultMatchProcessingConfig() *MatchProcessingConfig {
 		CacheExpiration:      24 * time.Hour,
 		BatchSize:            10,
 		ProcessingRateLimit:  60, // 60 matches per minute
+		JobCleanupInterval:   10 * time.Minute,
+		JobRetention:         1 * time.Hour,
 	}
 }
@@ -0,0 +1,36 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchProcessingJobSnapshots(t *testing.T) {
+	s := &MatchProcessingService{
+		config:          DefaultMatchProcessingConfig(),
+		processingQueue: make(chan *MatchProcessingJob, 1),
+		jobs:            make(map[string]*MatchProcessingJob),
+	}
+
+	queued, err := s.ProcessMatch(context.Background(), "EUW1_1", "puuid", &MatchProcessingOptions{})
+	require.NoError(t, err)
+	job := <-s.processingQueue
+
+	s.jobsMu.Lock()
+	job.Status = "processing"
+	s.jobsMu.Unlock()
+	assert.Equal(t, "pending", queued.Status, "the caller's job is a copy")
+
+	status, err := s.GetJobStatus(queued.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "processing", status.Status)
+
+	status.Status = "completed"
+	assert.Equal(t, "processing", job.Status, "a status is a snapshot")
+
+	_, err = s.GetJobStatus("missing")
+	assert.Error(t, err)
+}
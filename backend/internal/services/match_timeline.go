@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm/clause"
+
+	"github.com/herald-lol/herald/backend/internal/logger"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// MatchTimeline is the subset of Riot's match-v5 timeline used for analytics
+type MatchTimeline struct {
+	Metadata struct {
+		MatchID      string   `json:"matchId"`
+		Participants []string `json:"participants"`
+	} `json:"metadata"`
+	Info struct {
+		FrameInterval int64           `json:"frameInterval"`
+		Frames        []TimelineFrame `json:"frames"`
+		Participants  []struct {
+			ParticipantID int    `json:"participantId"`
+			PUUID         string `json:"puuid"`
+		} `json:"participants"`
+	} `json:"info"`
+}
+
+// TimelineFrame is one snapshot of the game, normally one per minute
+type TimelineFrame struct {
+	Timestamp         int64                               `json:"timestamp"` // milliseconds
+	ParticipantFrames map[string]TimelineParticipantFrame `json:"participantFrames"`
+	Events            []TimelineEvent                     `json:"events"`
+}
+
+// TimelineParticipantFrame is a participant's state at a frame
+type TimelineParticipantFrame struct {
+	ParticipantID       int               `json:"participantId"`
+	Position            *TimelinePosition `json:"position"`
+	TotalGold           int               `json:"totalGold"`
+	Level               int               `json:"level"`
+	XP                  int               `json:"xp"`
+	MinionsKilled       int               `json:"minionsKilled"`
+	JungleMinionsKilled int               `json:"jungleMinionsKilled"`
+}
+
+// TimelineEvent is a single timeline event such as WARD_PLACED
+type TimelineEvent struct {
+	Type          string            `json:"type"`
+	Timestamp     int64             `json:"timestamp"` // milliseconds
+	ParticipantID int               `json:"participantId"`
+	CreatorID     int               `json:"creatorId"`
+	KillerID      int               `json:"killerId"`
+	WardType      string            `json:"wardType"`
+	Position      *TimelinePosition `json:"position"`
+}
+
+// TimelinePosition is a point on Summoner's Rift
+type TimelinePosition struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Game phase boundaries used to label timeline events
+const (
+	earlyGameEnd = 14 * time.Minute
+	midGameEnd   = 25 * time.Minute
+)
+
+// riotWardTypes maps Riot's timeline ward types to the vision model ward types
+var riotWardTypes = map[string]string{
+	"YELLOW_TRINKET": "YELLOW",
+	"SIGHT_WARD":     "YELLOW",
+	"CONTROL_WARD":   "CONTROL",
+	"BLUE_TRINKET":   "BLUE_TRINKET",
+}
+
+// GetMatchTimeline gets the minute-by-minute timeline of a match
+func (s *RiotService) GetMatchTimeline(ctx context.Context, region, matchID string) (*MatchTimeline, error) {
+	endpoint := fmt.Sprintf("/lol/match/v5/matches/%s/timeline", matchID)
+
+	resp, err := s.makeAPIRequest(ctx, region, endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var timeline MatchTimeline
+	if err := json.Unmarshal(body, &timeline); err != nil {
+		return nil, err
+	}
+
+	return &timeline, nil
+}
+
+// recordTimeline fetches a match timeline and stores the player's ward
+// placements for vision heatmaps and their per-minute frames for the match
+// timeline charts. Failures are logged; they never fail a sync.
+func (s *RiotService) recordTimeline(ctx context.Context, userID, region, puuid string, match *MatchDetails) {
+	matchID := match.Metadata.MatchID
+	timeline, err := s.GetMatchTimeline(ctx, region, matchID)
+	if err != nil {
+		logger.Debugf("Skipping timeline of %s: %v", matchID, err)
+		return
+	}
+
+	placements := ExtractWardPlacements(timeline, puuid)
+	frames := ExtractTimelineFrames(timeline, match, userID, puuid)
+
+	// Resyncing a match keeps the rows already recorded for it
+	unlock := s.lockWrites()
+	defer unlock()
+	if len(placements) > 0 {
+		if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&placements).Error; err != nil {
+			logger.Warnf("Failed to save ward placements for %s: %v", matchID, err)
+		}
+	}
+	if len(frames) > 0 {
+		if err := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&frames).Error; err != nil {
+			logger.Warnf("Failed to save timeline frames for %s: %v", matchID, err)
+		}
+	}
+}
+
+// timelineParticipantID returns the timeline participant ID of puuid, or 0
+// when the player is not in the match
+func timelineParticipantID(timeline *MatchTimeline, puuid string) int {
+	for _, participant := range timeline.Info.Participants {
+		if participant.PUUID == puuid {
+			return participant.ParticipantID
+		}
+	}
+	for i, participant := range timeline.Metadata.Participants {
+		if participant == puuid {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// laneOpponentID returns the participant ID of the player's lane opponent,
+// the enemy with the same team position, or 0 when there is none (ARAM and
+// other modes without positions)
+func laneOpponentID(match *MatchDetails, puuid string) int {
+	for _, player := range match.Info.Participants {
+		if player.PUUID != puuid {
+			continue
+		}
+		if player.TeamPosition == "" {
+			return 0
+		}
+		for _, opponent := range match.Info.Participants {
+			if opponent.TeamID != player.TeamID && opponent.TeamPosition == player.TeamPosition {
+				return opponent.ParticipantID
+			}
+		}
+		return 0
+	}
+	return 0
+}
+
+// ExtractTimelineFrames returns the gold, CS and XP of the participant with
+// puuid and of their lane opponent at every timeline frame, attributed to
+// playerID. Riot records a frame about each minute and a last one at the end
+// of the game, so the minute is taken from the frame timestamp; a frame
+// falling in a minute already recorded, like the end of game frame shortly
+// after the last full minute, is skipped.
+func ExtractTimelineFrames(timeline *MatchTimeline, match *MatchDetails, playerID, puuid string) []models.MatchTimelineFrame {
+	participantID := timelineParticipantID(timeline, puuid)
+	if participantID == 0 {
+		return nil
+	}
+	opponentID := laneOpponentID(match, puuid)
+
+	frames := make([]models.MatchTimelineFrame, 0, len(timeline.Info.Frames))
+	recorded := make(map[int]bool, len(timeline.Info.Frames))
+	for _, frame := range timeline.Info.Frames {
+		player, ok := frame.ParticipantFrames[fmt.Sprint(participantID)]
+		minute := int(frame.Timestamp / 60000)
+		if !ok || recorded[minute] {
+			continue
+		}
+		recorded[minute] = true
+
+		record := models.MatchTimelineFrame{
+			UserID:  playerID,
+			MatchID: match.Metadata.MatchID,
+			Minute:  minute,
+			Gold:    player.TotalGold,
+			CS:      player.MinionsKilled + player.JungleMinionsKilled,
+			XP:      player.XP,
+		}
+		if opponent, ok := frame.ParticipantFrames[fmt.Sprint(opponentID)]; ok && opponentID != 0 {
+			record.HasOpponent = true
+			record.OpponentGold = opponent.TotalGold
+			record.OpponentCS = opponent.MinionsKilled + opponent.JungleMinionsKilled
+			record.OpponentXP = opponent.XP
+		}
+		frames = append(frames, record)
+	}
+
+	return frames
+}
+
+// ExtractWardPlacements returns the wards placed by the participant with
+// puuid, keyed by that PUUID so every linked account's wards stay apart and
+// a heatmap can be drawn for any of them. Riot's WARD_PLACED events carry no coordinates, so
+// a ward is placed at the event position when present and otherwise at the
+// player's position in the nearest frame, which is accurate to within the
+// distance walked in half a frame interval.
+func ExtractWardPlacements(timeline *MatchTimeline, puuid string) []models.WardPlacement {
+	participantID := timelineParticipantID(timeline, puuid)
+	if participantID == 0 {
+		return nil
+	}
+
+	mapSide := "BLUE"
+	if participantID > 5 {
+		mapSide = "RED"
+	}
+
+	zones := newWardZones()
+	placements := make([]models.WardPlacement, 0)
+	for _, frame := range timeline.Info.Frames {
+		for _, event := range frame.Events {
+			if event.Type != "WARD_PLACED" || event.CreatorID != participantID {
+				continue
+			}
+			wardType, ok := riotWardTypes[event.WardType]
+			if !ok {
+				continue // UNDEFINED wards are not real placements
+			}
+
+			position := event.Position
+			if position == nil {
+				position = nearestFramePosition(timeline.Info.Frames, participantID, event.Timestamp)
+			}
+			if position == nil {
+				continue
+			}
+
+			zone, strategic := zones.zoneAt(position.X, position.Y)
+			placements = append(placements, models.WardPlacement{
+				ID:        uuid.New().String(),
+				MatchID:   timeline.Metadata.MatchID,
+				PlayerID:  puuid,
+				WardType:  wardType,
+				X:         position.X,
+				Y:         position.Y,
+				MapSide:   mapSide,
+				Timestamp: int(event.Timestamp / 1000),
+				GamePhase: gamePhaseAt(event.Timestamp),
+				Zone:      zone,
+				Strategic: strategic,
+				CreatedAt: time.Now(),
+			})
+		}
+	}
+
+	return placements
+}
+
+// nearestFramePosition returns the participant's position in the frame
+// closest to timestamp
+func nearestFramePosition(frames []TimelineFrame, participantID int, timestamp int64) *TimelinePosition {
+	var best *TimelinePosition
+	bestDistance := int64(-1)
+	key := fmt.Sprint(participantID)
+
+	for _, frame := range frames {
+		participant, ok := frame.ParticipantFrames[key]
+		if !ok || participant.Position == nil {
+			continue
+		}
+		distance := frame.Timestamp - timestamp
+		if distance < 0 {
+			distance = -distance
+		}
+		if bestDistance < 0 || distance < bestDistance {
+			best = participant.Position
+			bestDistance = distance
+		}
+	}
+
+	return best
+}
+
+// gamePhaseAt labels a timeline timestamp in milliseconds as early, mid or late
+func gamePhaseAt(timestamp int64) string {
+	at := time.Duration(timestamp) * time.Millisecond
+	switch {
+	case at < earlyGameEnd:
+		return "early"
+	case at < midGameEnd:
+		return "mid"
+	default:
+		return "late"
+	}
+}
+
+// wardZones buckets ward positions into map zones, built once per timeline
+type wardZones struct {
+	mapService *MapService
+	strategic  []MapZone
+}
+
+func newWardZones() *wardZones {
+	mapService := NewMapService()
+	return &wardZones{mapService: mapService, strategic: mapService.GetStrategicZones()}
+}
+
+// zoneAt returns the strategic zone containing a map position, or its lane
+// or jungle area when it is in none, and whether the zone is strategic
+func (z *wardZones) zoneAt(x, y int) (string, bool) {
+	for _, zone := range z.strategic {
+		if z.mapService.IsPointInZone(x, y, zone) {
+			return zone.Name, zone.Strategic
+		}
+	}
+	return z.mapService.GetLaneForPosition(x, y), false
+}
+
+// ErrTimelineNotStored is returned for matches without recorded timeline
+// frames, either synced with SYNC_FETCH_TIMELINES off or not synced at all
+var ErrTimelineNotStored = errors.New("no timeline stored for this match")
+
+// MatchTimelineSeries is the per-minute gold, CS and XP of a player and their
+// lane opponent in one match, for the match review charts
+type MatchTimelineSeries struct {
+	MatchID string `json:"match_id"`
+	// False when the player had no lane opponent, leaving the opponent
+	// values and differentials at 0
+	HasOpponent bool                  `json:"has_opponent"`
+	Minutes     []MatchTimelineMinute `json:"minutes"`
+}
+
+// MatchTimelineMinute is one point of a MatchTimelineSeries; differentials
+// are the player's value minus the opponent's
+type MatchTimelineMinute struct {
+	models.MatchTimelineFrame
+	GoldDiff int `json:"gold_diff"`
+	CSDiff   int `json:"cs_diff"`
+	XPDiff   int `json:"xp_diff"`
+}
+
+// GetMatchTimelineSeries returns the timeline frames stored for the user's
+// match, or ErrTimelineNotStored
+func (s *RiotService) GetMatchTimelineSeries(ctx context.Context, userID, matchID string) (*MatchTimelineSeries, error) {
+	var frames []models.MatchTimelineFrame
+	if err := s.db.WithContext(ctx).
+		Where("user_id = ? AND match_id = ?", userID, matchID).
+		Order("minute").
+		Find(&frames).Error; err != nil {
+		return nil, err
+	}
+	if len(frames) == 0 {
+		return nil, ErrTimelineNotStored
+	}
+
+	return BuildMatchTimelineSeries(matchID, frames), nil
+}
+
+// BuildMatchTimelineSeries adds the differentials to frames sorted by minute
+func BuildMatchTimelineSeries(matchID string, frames []models.MatchTimelineFrame) *MatchTimelineSeries {
+	series := &MatchTimelineSeries{
+		MatchID: matchID,
+		Minutes: make([]MatchTimelineMinute, len(frames)),
+	}
+	for i, frame := range frames {
+		point := MatchTimelineMinute{MatchTimelineFrame: frame}
+		if frame.HasOpponent {
+			series.HasOpponent = true
+			point.GoldDiff = frame.Gold - frame.OpponentGold
+			point.CSDiff = frame.CS - frame.OpponentCS
+			point.XPDiff = frame.XP - frame.OpponentXP
+		}
+		series.Minutes[i] = point
+	}
+	return series
+}
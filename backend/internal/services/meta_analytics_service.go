@@ -14,13 +14,51 @@ import (
 // MetaAnalyticsService handles meta analysis and tier list generation
 type MetaAnalyticsService struct {
 	analyticsService *AnalyticsService
+
+	// Champions below either threshold are left out of the tier lists
+	minGames    int
+	minPickRate float64
+
+	// A champion counts as learned once the player's cumulative win rate on
+	// it reaches comfortWinRate after at least comfortMinGames games
+	comfortMinGames int
+	comfortWinRate  float64
 }
 
+// Default minimum sample for a champion to be placed in a meta tier
+const (
+	DefaultMetaMinGames    = 100
+	DefaultMetaMinPickRate = 0.5 // percent
+)
+
 // NewMetaAnalyticsService creates a new meta analytics service
 func NewMetaAnalyticsService(analyticsService *AnalyticsService) *MetaAnalyticsService {
 	return &MetaAnalyticsService{
 		analyticsService: analyticsService,
+		minGames:         DefaultMetaMinGames,
+		minPickRate:      DefaultMetaMinPickRate,
+		comfortMinGames:  DefaultComfortMinGames,
+		comfortWinRate:   DefaultComfortWinRate,
+	}
+}
+
+// SetMinSample sets the fewest games and the lowest pick rate, in percent, a
+// champion needs to be placed in a tier. Zero disables a threshold; negative
+// values restore the default.
+func (mas *MetaAnalyticsService) SetMinSample(minGames int, minPickRate float64) {
+	if minGames < 0 {
+		minGames = DefaultMetaMinGames
 	}
+	if minPickRate < 0 {
+		minPickRate = DefaultMetaMinPickRate
+	}
+	mas.minGames = minGames
+	mas.minPickRate = minPickRate
+}
+
+// meetsMinSample reports whether a tier entry has enough games to be ranked
+func (mas *MetaAnalyticsService) meetsMinSample(entry ChampionTierEntry) bool {
+	return entry.Games >= mas.minGames && entry.PickRate >= mas.minPickRate
 }
 
 // MetaAnalysis represents comprehensive meta analysis results
@@ -31,6 +69,9 @@ type MetaAnalysis struct {
 	Rank         string    `json:"rank"`
 	TimeRange    string    `json:"time_range"`
 	AnalysisDate time.Time `json:"analysis_date"`
+	// CurrentPatch is the live patch when the analysis ran, empty until
+	// DataDragon has been reached
+	CurrentPatch string `json:"current_patch,omitempty"`
 
 	// Tier Lists
 	TierList      ChampionTierList            `json:"tier_list"`
@@ -77,12 +118,19 @@ type ChampionTierList struct {
 	LastUpdated time.Time `json:"last_updated"`
 	SampleSize  int       `json:"sample_size"`
 	Confidence  float64   `json:"confidence"`
+
+	// Champions left out for having fewer games or a lower pick rate than
+	// the minimum sample
+	FilteredChampions int     `json:"filtered_champions"`
+	MinGames          int     `json:"min_games"`
+	MinPickRate       float64 `json:"min_pick_rate"`
 }
 
 // ChampionTierEntry represents a champion in a tier
 type ChampionTierEntry struct {
 	Champion       string   `json:"champion"`
 	TierScore      float64  `json:"tier_score"`
+	Games          int      `json:"games"`
 	WinRate        float64  `json:"win_rate"`
 	PickRate       float64  `json:"pick_rate"`
 	BanRate        float64  `json:"ban_rate"`
@@ -455,6 +503,9 @@ func (mas *MetaAnalyticsService) AnalyzeMeta(ctx context.Context, patch string,
 		LastUpdated:  time.Now(),
 		DataQuality:  0.95,
 	}
+	if current, ok := models.CurrentPatch(); ok {
+		analysis.CurrentPatch = current.Patch
+	}
 
 	// Generate tier list
 	if err := mas.generateTierList(ctx, analysis); err != nil {
@@ -502,6 +553,8 @@ func (mas *MetaAnalyticsService) generateTierList(ctx context.Context, analysis
 		LastUpdated: time.Now(),
 		SampleSize:  50000,
 		Confidence:  0.92,
+		MinGames:    mas.minGames,
+		MinPickRate: mas.minPickRate,
 	}
 
 	for i, champion := range champions {
@@ -514,6 +567,7 @@ func (mas *MetaAnalyticsService) generateTierList(ctx context.Context, analysis
 		entry := ChampionTierEntry{
 			Champion:       champion,
 			TierScore:      baseScore,
+			Games:          int(pickRate / 100 * float64(tierList.SampleSize)),
 			WinRate:        winRate,
 			PickRate:       pickRate,
 			BanRate:        banRate,
@@ -523,6 +577,10 @@ func (mas *MetaAnalyticsService) generateTierList(ctx context.Context, analysis
 			TierMovement:   []int{1, 0, -1, 0, 1}[i%5],
 			RecommendedFor: []string{"climbing", "one_trick", "flex_pick"},
 		}
+		if !mas.meetsMinSample(entry) {
+			tierList.FilteredChampions++
+			continue
+		}
 
 		// Distribute to tiers based on tier score
 		switch {
@@ -558,6 +616,8 @@ func (mas *MetaAnalyticsService) generateTierList(ctx context.Context, analysis
 			LastUpdated: time.Now(),
 			SampleSize:  10000,
 			Confidence:  0.88,
+			MinGames:    mas.minGames,
+			MinPickRate: mas.minPickRate,
 		}
 
 		// Distribute some champions to each role tier list
@@ -566,6 +626,7 @@ func (mas *MetaAnalyticsService) generateTierList(ctx context.Context, analysis
 				entry := ChampionTierEntry{
 					Champion:       champions[i],
 					TierScore:      80.0 - float64(i)*5,
+					Games:          int((20.0 - float64(i)*3) / 100 * float64(roleTierList.SampleSize)),
 					WinRate:        52.0 + float64(i),
 					PickRate:       20.0 - float64(i)*3,
 					BanRate:        15.0 - float64(i)*2,
@@ -575,6 +636,10 @@ func (mas *MetaAnalyticsService) generateTierList(ctx context.Context, analysis
 					TierMovement:   0,
 					RecommendedFor: []string{"climbing"},
 				}
+				if !mas.meetsMinSample(entry) {
+					roleTierList.FilteredChampions++
+					continue
+				}
 				roleTierList.STier = append(roleTierList.STier, entry)
 			}
 		}
@@ -32,6 +32,10 @@ type NotificationService struct {
 	pushProvider   PushProvider
 	templateEngine TemplateEngine
 
+	// Notification feed
+	feed   map[string][]*FeedNotification
+	feedMu sync.RWMutex
+
 	// Worker management
 	workers  int
 	shutdown chan bool
@@ -283,6 +287,7 @@ func NewNotificationService(config *NotificationConfig, emailProvider EmailProvi
 		emailProvider:     emailProvider,
 		pushProvider:      pushProvider,
 		templateEngine:    templateEngine,
+		feed:              make(map[string][]*FeedNotification),
 		workers:           config.WorkerCount,
 		shutdown:          make(chan bool),
 	}
@@ -541,6 +546,134 @@ func (s *NotificationService) GetMetrics(since time.Time) (*NotificationMetrics,
 
 // Worker implementations
 
+// FeedNotification is an entry in a user's notification feed
+type FeedNotification struct {
+	ID        string               `json:"id"`
+	Type      NotificationType     `json:"type"`
+	Title     string               `json:"title"`
+	Message   string               `json:"message"`
+	Priority  NotificationPriority `json:"priority"`
+	Read      bool                 `json:"read"`
+	CreatedAt time.Time            `json:"created_at"`
+}
+
+// NotificationListFilter scopes a notification feed query
+type NotificationListFilter struct {
+	Limit      int
+	Offset     int
+	UnreadOnly bool
+	Type       NotificationType
+}
+
+// NotificationList is a page of a user's notification feed
+type NotificationList struct {
+	Notifications []*FeedNotification `json:"notifications"`
+	Total         int                 `json:"total"`
+	UnreadCount   int                 `json:"unread_count"`
+	Limit         int                 `json:"limit"`
+	Offset        int                 `json:"offset"`
+	HasMore       bool                `json:"has_more"`
+}
+
+// maxFeedNotificationsPerUser bounds the in-memory feed for each user
+const maxFeedNotificationsPerUser = 500
+
+// ListNotifications returns a filtered, newest-first page of the user's feed.
+// UnreadCount always counts every unread notification, regardless of filters.
+func (s *NotificationService) ListNotifications(userID string, filter NotificationListFilter) *NotificationList {
+	s.feedMu.RLock()
+	defer s.feedMu.RUnlock()
+
+	entries := s.feed[userID]
+	matched := make([]*FeedNotification, 0, len(entries))
+	unread := 0
+
+	// Feed is stored oldest-first, walk it backwards for newest-first results
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		if !entry.Read {
+			unread++
+		}
+		if filter.UnreadOnly && entry.Read {
+			continue
+		}
+		if filter.Type != "" && entry.Type != filter.Type {
+			continue
+		}
+		copied := *entry
+		matched = append(matched, &copied)
+	}
+
+	list := &NotificationList{
+		Notifications: []*FeedNotification{},
+		Total:         len(matched),
+		UnreadCount:   unread,
+		Limit:         filter.Limit,
+		Offset:        filter.Offset,
+	}
+
+	if filter.Offset >= len(matched) {
+		return list
+	}
+
+	end := len(matched)
+	if filter.Limit > 0 && filter.Offset+filter.Limit < end {
+		end = filter.Offset + filter.Limit
+	}
+
+	list.Notifications = matched[filter.Offset:end]
+	list.HasMore = end < len(matched)
+
+	return list
+}
+
+// MarkNotificationsRead marks the given notifications (or all of them) as read
+// and returns how many changed state
+func (s *NotificationService) MarkNotificationsRead(userID string, notificationIDs []string, all bool) int {
+	ids := make(map[string]bool, len(notificationIDs))
+	for _, id := range notificationIDs {
+		ids[id] = true
+	}
+
+	s.feedMu.Lock()
+	defer s.feedMu.Unlock()
+
+	marked := 0
+	for _, entry := range s.feed[userID] {
+		if entry.Read || (!all && !ids[entry.ID]) {
+			continue
+		}
+		entry.Read = true
+		marked++
+	}
+
+	return marked
+}
+
+func (s *NotificationService) appendToFeed(notification *NotificationJob) {
+	if notification.UserID == "" || notification.Content == nil {
+		return
+	}
+
+	entry := &FeedNotification{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Title:     notification.Content.Title,
+		Message:   notification.Content.Message,
+		Priority:  notification.Priority,
+		CreatedAt: notification.CreatedAt,
+	}
+
+	s.feedMu.Lock()
+	defer s.feedMu.Unlock()
+
+	entries := append(s.feed[notification.UserID], entry)
+	if len(entries) > maxFeedNotificationsPerUser {
+		entries = entries[len(entries)-maxFeedNotificationsPerUser:]
+	}
+	s.feed[notification.UserID] = entries
+}
+
 func (s *NotificationService) startWorkers() {
 	// Start notification workers
 	for i := 0; i < s.workers; i++ {
@@ -616,6 +749,8 @@ func (s *NotificationService) processNotification(notification *NotificationJob,
 		}
 	}
 
+	s.appendToFeed(notification)
+
 	notification.Status = "completed"
 	log.Printf("Notification processed: %s by %s", notification.ID, workerID)
 }
@@ -0,0 +1,265 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// First objectives whose impact on the player's win rate can be analyzed
+const (
+	ObjectiveFirstBlood     = "first_blood"
+	ObjectiveFirstTower     = "first_tower"
+	ObjectiveFirstDragon    = "first_dragon"
+	ObjectiveFirstHerald    = "first_herald"
+	ObjectiveFirstBaron     = "first_baron"
+	ObjectiveFirstInhibitor = "first_inhibitor"
+)
+
+// Objectives lists the analyzable first objectives in response order
+var Objectives = []string{
+	ObjectiveFirstBlood,
+	ObjectiveFirstTower,
+	ObjectiveFirstDragon,
+	ObjectiveFirstHerald,
+	ObjectiveFirstBaron,
+	ObjectiveFirstInhibitor,
+}
+
+// DefaultObjectiveMinGames is how many games the player needs both with and
+// without a first objective before its win rate impact counts
+const DefaultObjectiveMinGames = 5
+
+// ObjectiveImpact is the player's record in games where their team secured
+// a first objective against games where it did not
+type ObjectiveImpact struct {
+	Objective  string          `json:"objective"`
+	Secured    QueueSplitStats `json:"secured"`
+	NotSecured QueueSplitStats `json:"not_secured"`
+	// SecureRate is the share of games with objective data in which the
+	// player's team secured the objective, in percent
+	SecureRate float64 `json:"secure_rate"`
+	// WinRateDelta is the win rate when secured minus the win rate when
+	// not, in percentage points
+	WinRateDelta float64 `json:"win_rate_delta"`
+	// EnoughGames is false when either side has fewer than MinGames games,
+	// in which case the delta is too noisy to act on
+	EnoughGames bool `json:"enough_games"`
+}
+
+// ObjectiveImpactAnalysis relates the first objectives the player's team
+// secured to the results of those games
+type ObjectiveImpactAnalysis struct {
+	PlayerID      string      `json:"player_id"`
+	TimeRange     string      `json:"time_range"`
+	Filter        MatchFilter `json:"filter"`
+	ExcludedGames int         `json:"excluded_games"` // non-competitive games left out
+	MinGames      int         `json:"min_games"`
+	// Games with stored objective data; matches synced before objectives
+	// were recorded are left out
+	GamesWithObjectives int             `json:"games_with_objectives"`
+	Overall             QueueSplitStats `json:"overall"`
+	// Objectives with enough games come first, largest win rate delta first
+	Objectives []ObjectiveImpact `json:"objectives"`
+}
+
+// ParseObjectives validates a comma-separated objectives query value; an
+// empty value selects every objective
+func ParseObjectives(value string) ([]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return Objectives, nil
+	}
+
+	selected := make(map[string]bool)
+	for _, objective := range strings.Split(value, ",") {
+		objective = strings.ToLower(strings.TrimSpace(objective))
+		if objective == "" {
+			continue
+		}
+		if _, ok := objectiveSecured(objective, models.MatchTeamObjectives{}); !ok {
+			return nil, fmt.Errorf("invalid objective %q: use %s", objective, strings.Join(Objectives, ", "))
+		}
+		selected[objective] = true
+	}
+
+	// Keep the response order stable whatever order was requested
+	objectives := make([]string, 0, len(selected))
+	for _, objective := range Objectives {
+		if selected[objective] {
+			objectives = append(objectives, objective)
+		}
+	}
+	return objectives, nil
+}
+
+// AnalyzeObjectiveImpact reports the win rate of the user's linked accounts
+// with and without each of the given first objectives. minGames overrides the configured threshold
+// when positive; nil objectives analyzes all of them.
+func (as *AnalyticsService) AnalyzeObjectiveImpact(ctx context.Context, playerID, timeRange string, minGames int, objectives []string, filter MatchFilter) (*ObjectiveImpactAnalysis, error) {
+	filter = filter.normalize()
+	if minGames < 1 {
+		minGames = as.objectiveMinGames
+	}
+	startDate, endDate := as.parseTimeRange(timeRange)
+
+	matches, excluded, err := as.getAccountFilteredMatches(ctx, playerID, startDate, endDate, "", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	teamObjectives, err := as.getMatchTeamObjectives(ctx, playerID, startDate.UnixMilli(), endDate.UnixMilli())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get objectives: %w", err)
+	}
+
+	analysis := BuildObjectiveImpact(matches, teamObjectives, objectives, minGames)
+	analysis.PlayerID = playerID
+	analysis.TimeRange = timeRange
+	analysis.Filter = filter
+	analysis.ExcludedGames = excluded
+	return analysis, nil
+}
+
+// SetObjectiveMinGames sets how many games are needed on each side of a
+// first objective before its impact counts; values below 1 restore the
+// default
+func (as *AnalyticsService) SetObjectiveMinGames(games int) {
+	if games < 1 {
+		games = DefaultObjectiveMinGames
+	}
+	as.objectiveMinGames = games
+}
+
+// getMatchTeamObjectives returns the objectives of the team of each Riot
+// account linked to the user playerID, keyed by Riot match ID, for matches
+// started between start and end (Unix milliseconds). Matches without stored
+// objectives are absent.
+func (as *AnalyticsService) getMatchTeamObjectives(ctx context.Context, playerID string, start, end int64) (map[string]models.MatchTeamObjectives, error) {
+	puuids, err := as.accountPUUIDs(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT m.match_id, o.team_id, o.first_blood, o.first_tower, o.first_dragon,
+			o.first_herald, o.first_baron, o.first_inhibitor
+		FROM match_participants mp
+		JOIN matches m ON mp.match_id = m.id
+		JOIN match_team_objectives o ON o.match_id = mp.match_id
+			AND o.team_id = mp.team_id
+		WHERE mp.puuid = $1
+		AND m.game_start_timestamp BETWEEN $2 AND $3
+	`
+
+	objectives := make(map[string]models.MatchTeamObjectives)
+	for _, puuid := range puuids {
+		if err := as.scanMatchTeamObjectives(ctx, objectives, query, puuid, start, end); err != nil {
+			return nil, err
+		}
+	}
+	return objectives, nil
+}
+
+func (as *AnalyticsService) scanMatchTeamObjectives(ctx context.Context, objectives map[string]models.MatchTeamObjectives, query, puuid string, start, end int64) error {
+	rows, err := as.db.QueryContext(ctx, query, puuid, start, end)
+	if err != nil {
+		return fmt.Errorf("failed to query objectives: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var matchID string
+		var o models.MatchTeamObjectives
+		if err := rows.Scan(&matchID, &o.TeamID, &o.FirstBlood, &o.FirstTower, &o.FirstDragon,
+			&o.FirstHerald, &o.FirstBaron, &o.FirstInhibitor); err != nil {
+			return fmt.Errorf("failed to scan objectives: %w", err)
+		}
+		objectives[matchID] = o
+	}
+	return rows.Err()
+}
+
+// BuildObjectiveImpact splits the matches that have objective data by whether
+// the player's team secured each objective first. teamObjectives is keyed by
+// MatchData.MatchID; nil objectives analyzes all of them.
+func BuildObjectiveImpact(matches []models.MatchData, teamObjectives map[string]models.MatchTeamObjectives, objectives []string, minGames int) *ObjectiveImpactAnalysis {
+	if minGames < 1 {
+		minGames = DefaultObjectiveMinGames
+	}
+	if objectives == nil {
+		objectives = Objectives
+	}
+
+	var overall splitAccumulator
+	secured := make([]splitAccumulator, len(objectives))
+	notSecured := make([]splitAccumulator, len(objectives))
+	for _, match := range matches {
+		team, ok := teamObjectives[match.MatchID]
+		if !ok {
+			continue
+		}
+		overall.add(match)
+		for i, objective := range objectives {
+			if first, _ := objectiveSecured(objective, team); first {
+				secured[i].add(match)
+			} else {
+				notSecured[i].add(match)
+			}
+		}
+	}
+
+	analysis := &ObjectiveImpactAnalysis{
+		MinGames:            minGames,
+		GamesWithObjectives: overall.games,
+		Overall:             overall.stats(),
+		Objectives:          make([]ObjectiveImpact, 0, len(objectives)),
+	}
+	if overall.games == 0 {
+		return analysis
+	}
+	for i, objective := range objectives {
+		impact := ObjectiveImpact{
+			Objective:   objective,
+			Secured:     secured[i].stats(),
+			NotSecured:  notSecured[i].stats(),
+			SecureRate:  float64(secured[i].games) / float64(overall.games) * 100,
+			EnoughGames: secured[i].games >= minGames && notSecured[i].games >= minGames,
+		}
+		if secured[i].games > 0 && notSecured[i].games > 0 {
+			impact.WinRateDelta = impact.Secured.WinRate - impact.NotSecured.WinRate
+		}
+		analysis.Objectives = append(analysis.Objectives, impact)
+	}
+	sort.SliceStable(analysis.Objectives, func(i, j int) bool {
+		a, b := analysis.Objectives[i], analysis.Objectives[j]
+		if a.EnoughGames != b.EnoughGames {
+			return a.EnoughGames
+		}
+		return a.WinRateDelta > b.WinRateDelta
+	})
+
+	return analysis
+}
+
+// objectiveSecured reports whether the team took the given first objective;
+// ok is false for unknown objectives
+func objectiveSecured(objective string, team models.MatchTeamObjectives) (first, ok bool) {
+	switch objective {
+	case ObjectiveFirstBlood:
+		return team.FirstBlood, true
+	case ObjectiveFirstTower:
+		return team.FirstTower, true
+	case ObjectiveFirstDragon:
+		return team.FirstDragon, true
+	case ObjectiveFirstHerald:
+		return team.FirstHerald, true
+	case ObjectiveFirstBaron:
+		return team.FirstBaron, true
+	case ObjectiveFirstInhibitor:
+		return team.FirstInhibitor, true
+	}
+	return false, false
+}
@@ -0,0 +1,309 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// Performance analysis tuning
+const (
+	// performanceTrendGames is how many of the most recent games the trend
+	// regression is fitted over
+	performanceTrendGames = 20
+	// performanceTrendMinGames is the fewest games a trend is reported for
+	performanceTrendMinGames = 5
+	// kdaTrendThreshold is the KDA change per game treated as a real trend
+	kdaTrendThreshold = 0.05
+	// winRateTrendThreshold is the win probability change per game treated as
+	// a real trend (one percentage point per game)
+	winRateTrendThreshold = 0.01
+)
+
+// DefaultChampionPoolThreshold is the percentage of games the champions
+// counted in the effective champion pool must account for
+const DefaultChampionPoolThreshold = 80.0
+
+// PerformanceAnalysis holds deterministic diversity and trend metrics for a
+// player's games. Every value is derived from the match data alone so the
+// same games always produce the same analysis.
+type PerformanceAnalysis struct {
+	PlayerID  string `json:"player_id"`
+	TimeRange string `json:"time_range"`
+	Matches   int    `json:"matches"`
+
+	// Champion diversity: Shannon entropy of the champion pick distribution
+	UniqueChampions   int     `json:"unique_champions"`
+	ChampionEntropy   float64 `json:"champion_entropy"`   // bits
+	ChampionDiversity float64 `json:"champion_diversity"` // entropy / log2(unique champions), 0-1
+
+	// Effective champion pool: the fewest champions accounting for
+	// ChampionPoolThreshold percent of games, most played first
+	ChampionPoolSize      int     `json:"champion_pool_size"`
+	ChampionPoolThreshold float64 `json:"champion_pool_threshold"`
+
+	// Trend: least squares slope over the most recent games, oldest first
+	TrendGames     int     `json:"trend_games"`
+	KDATrendSlope  float64 `json:"kda_trend_slope"`      // KDA change per game
+	KDATrendR2     float64 `json:"kda_trend_r_squared"`  // fit quality, 0-1
+	WinRateSlope   float64 `json:"win_rate_trend_slope"` // win probability change per game
+	TrendDirection string  `json:"trend_direction"`      // "improving", "declining", "stable", "insufficient_data"
+	Summary        string  `json:"summary"`
+}
+
+// SetChampionPoolThreshold sets the default percentage of games the effective
+// champion pool covers; values outside (0, 100] restore the default
+func (as *AnalyticsService) SetChampionPoolThreshold(percent float64) {
+	if !IsValidPoolThreshold(percent) {
+		percent = DefaultChampionPoolThreshold
+	}
+	as.championPoolThreshold = percent
+}
+
+// IsValidPoolThreshold reports whether percent is a usable champion pool
+// threshold
+func IsValidPoolThreshold(percent float64) bool {
+	return percent > 0 && percent <= 100
+}
+
+// AnalyzePerformance computes champion diversity, the effective champion pool
+// and recent performance trend for the player's games in timeRange.
+// poolThreshold overrides the configured pool threshold when valid.
+func (as *AnalyticsService) AnalyzePerformance(ctx context.Context, playerID, timeRange string, poolThreshold float64, filter MatchFilter) (*PerformanceAnalysis, error) {
+	filter = filter.normalize()
+	startDate, endDate := as.parseTimeRange(timeRange)
+
+	matches, _, err := as.getFilteredMatches(ctx, playerID, startDate, endDate, "", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if !IsValidPoolThreshold(poolThreshold) {
+		poolThreshold = as.championPoolThreshold
+	}
+
+	analysis := BuildPerformanceAnalysis(matches, poolThreshold)
+	analysis.PlayerID = playerID
+	analysis.TimeRange = timeRange
+	return analysis, nil
+}
+
+// BuildPerformanceAnalysis derives the diversity, champion pool and trend
+// metrics from matches. matches is not modified.
+func BuildPerformanceAnalysis(matches []models.MatchData, poolThreshold float64) *PerformanceAnalysis {
+	if !IsValidPoolThreshold(poolThreshold) {
+		poolThreshold = DefaultChampionPoolThreshold
+	}
+
+	entropy, diversity, unique := ChampionEntropy(matches)
+	analysis := &PerformanceAnalysis{
+		Matches:               len(matches),
+		UniqueChampions:       unique,
+		ChampionEntropy:       entropy,
+		ChampionDiversity:     diversity,
+		ChampionPoolSize:      ChampionPoolSize(matches, poolThreshold),
+		ChampionPoolThreshold: poolThreshold,
+		TrendDirection:        "insufficient_data",
+	}
+
+	ordered := make([]models.MatchData, len(matches))
+	copy(ordered, matches)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Date.Before(ordered[j].Date)
+	})
+	if len(ordered) > performanceTrendGames {
+		ordered = ordered[len(ordered)-performanceTrendGames:]
+	}
+	analysis.TrendGames = len(ordered)
+
+	if len(ordered) >= performanceTrendMinGames {
+		kdaValues := make([]float64, len(ordered))
+		winValues := make([]float64, len(ordered))
+		for i, match := range ordered {
+			kdaValues[i] = kdaRatio(match.Kills, match.Deaths, match.Assists)
+			if match.Win {
+				winValues[i] = 1
+			}
+		}
+		analysis.KDATrendSlope, analysis.KDATrendR2 = LinearTrend(kdaValues)
+		analysis.WinRateSlope, _ = LinearTrend(winValues)
+		analysis.TrendDirection = trendDirection(analysis.KDATrendSlope, analysis.WinRateSlope)
+	}
+
+	analysis.Summary = summarizePerformance(analysis)
+	return analysis
+}
+
+// ChampionEntropy returns the Shannon entropy in bits of the champion pick
+// distribution, the entropy normalized by its maximum for the number of
+// distinct champions (1 when every champion is played equally, 0 for a
+// one-trick), and the number of distinct champions
+func ChampionEntropy(matches []models.MatchData) (entropy, normalized float64, unique int) {
+	counts := make(map[string]int)
+	total := 0
+	for _, match := range matches {
+		if match.ChampionName == "" {
+			continue
+		}
+		counts[match.ChampionName]++
+		total++
+	}
+	unique = len(counts)
+	if unique < 2 {
+		return 0, 0, unique
+	}
+
+	// Sum in a fixed order so floating point results are reproducible
+	names := make([]string, 0, unique)
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := float64(counts[name]) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	normalized = entropy / math.Log2(float64(unique))
+
+	return entropy, normalized, unique
+}
+
+// ChampionPoolSize returns how many of the player's most played champions it
+// takes to cover percent of their games, e.g. 3 when their top three
+// champions account for 80% of games
+func ChampionPoolSize(matches []models.MatchData, percent float64) int {
+	counts := make(map[string]int)
+	total := 0
+	for _, match := range matches {
+		if match.ChampionName == "" {
+			continue
+		}
+		counts[match.ChampionName]++
+		total++
+	}
+	if total == 0 {
+		return 0
+	}
+
+	games := make([]int, 0, len(counts))
+	for _, count := range counts {
+		games = append(games, count)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(games)))
+
+	// Compare game counts rather than shares so 80% of 10 games is exactly 8
+	needed := percent / 100 * float64(total)
+	covered := 0
+	for i, count := range games {
+		covered += count
+		if float64(covered) >= needed-1e-9 {
+			return i + 1
+		}
+	}
+	return len(games)
+}
+
+// LinearTrend fits values (oldest first, one per game) with ordinary least
+// squares and returns the slope per game and the coefficient of
+// determination. Fewer than two values, or values with no variance, have no
+// trend.
+func LinearTrend(values []float64) (slope, rSquared float64) {
+	n := float64(len(values))
+	if len(values) < 2 {
+		return 0, 0
+	}
+
+	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
+	for i, y := range values {
+		x := float64(i)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, 0
+	}
+	slope = (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+
+	meanY := sumY / n
+	ssTotal, ssRes := 0.0, 0.0
+	for i, y := range values {
+		predicted := slope*float64(i) + intercept
+		ssTotal += (y - meanY) * (y - meanY)
+		ssRes += (y - predicted) * (y - predicted)
+	}
+	if ssTotal == 0 {
+		return 0, 0
+	}
+
+	rSquared = 1 - ssRes/ssTotal
+	if rSquared < 0 {
+		rSquared = 0
+	}
+	return slope, rSquared
+}
+
+// kdaRatio is (kills + assists) / deaths, counting zero deaths as one
+func kdaRatio(kills, deaths, assists int) float64 {
+	if deaths == 0 {
+		return float64(kills + assists)
+	}
+	return float64(kills+assists) / float64(deaths)
+}
+
+// trendDirection combines the KDA and win rate slopes. A direction is only
+// reported when neither metric moves against it.
+func trendDirection(kdaSlope, winSlope float64) string {
+	kdaUp, kdaDown := kdaSlope > kdaTrendThreshold, kdaSlope < -kdaTrendThreshold
+	winUp, winDown := winSlope > winRateTrendThreshold, winSlope < -winRateTrendThreshold
+
+	switch {
+	case (kdaUp || winUp) && !kdaDown && !winDown:
+		return "improving"
+	case (kdaDown || winDown) && !kdaUp && !winUp:
+		return "declining"
+	default:
+		return "stable"
+	}
+}
+
+// summarizePerformance writes the analysis summary from the computed metrics
+func summarizePerformance(analysis *PerformanceAnalysis) string {
+	if analysis.Matches == 0 {
+		return "No games in this period."
+	}
+
+	parts := make([]string, 0, 3)
+	switch {
+	case analysis.UniqueChampions <= 1:
+		parts = append(parts, "You played a single champion.")
+	case analysis.ChampionDiversity < 0.5:
+		parts = append(parts, fmt.Sprintf("Your games are concentrated on a few of your %d champions (diversity %.2f).", analysis.UniqueChampions, analysis.ChampionDiversity))
+	default:
+		parts = append(parts, fmt.Sprintf("Your games are spread across %d champions (diversity %.2f).", analysis.UniqueChampions, analysis.ChampionDiversity))
+	}
+	if analysis.UniqueChampions > 1 {
+		parts = append(parts, fmt.Sprintf("Your effective champion pool is %d: they account for %.0f%% of your games.", analysis.ChampionPoolSize, analysis.ChampionPoolThreshold))
+	}
+
+	switch analysis.TrendDirection {
+	case "improving", "declining":
+		parts = append(parts, fmt.Sprintf("Over your last %d games your performance is %s: KDA %+.2f and win rate %+.1f points per game.",
+			analysis.TrendGames, analysis.TrendDirection, analysis.KDATrendSlope, analysis.WinRateSlope*100))
+	case "stable":
+		parts = append(parts, fmt.Sprintf("Over your last %d games your performance is stable.", analysis.TrendGames))
+	default:
+		parts = append(parts, fmt.Sprintf("At least %d games are needed to measure a trend.", performanceTrendMinGames))
+	}
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// performanceTrendWindow is the number of periods averaged into a trend
+// point's moving average
+const performanceTrendWindow = 3
+
+// PerformanceTrendPoint is a player's average of one metric over one period
+type PerformanceTrendPoint struct {
+	PeriodStart time.Time `json:"period_start"`
+	Matches     int       `json:"matches"`
+	Value       float64   `json:"value"`
+	MovingAvg   float64   `json:"moving_average"` // over this and up to two previous periods
+}
+
+// GetPerformanceTrends averages metric (kda, cs, vision, damage or winrate)
+// over the player's games of the last days, per daily, weekly or monthly
+// period, oldest first. Periods without games are left out.
+func (as *AnalyticsService) GetPerformanceTrends(ctx context.Context, playerID, metric, period string, days int) ([]PerformanceTrendPoint, error) {
+	value, ok := performanceTrendMetrics[metric]
+	if !ok {
+		return nil, fmt.Errorf("unknown metric %q", metric)
+	}
+
+	endDate := time.Now()
+	matches, _, err := as.getFilteredMatches(ctx, playerID, endDate.AddDate(0, 0, -days), endDate, "", MatchFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildPerformanceTrend(matches, value, period), nil
+}
+
+// performanceTrendMetrics extract each trend metric's value from a game
+var performanceTrendMetrics = map[string]func(models.MatchData) float64{
+	"kda": func(m models.MatchData) float64 {
+		return float64(m.Kills+m.Assists) / float64(max(1, m.Deaths))
+	},
+	"cs":     func(m models.MatchData) float64 { return m.CSPerMinute },
+	"vision": func(m models.MatchData) float64 { return float64(m.VisionScore) },
+	"damage": func(m models.MatchData) float64 { return m.DamageShare * 100 },
+	"winrate": func(m models.MatchData) float64 {
+		if m.Win {
+			return 100
+		}
+		return 0
+	},
+}
+
+// BuildPerformanceTrend groups matches by period and averages value over
+// each period's games
+func BuildPerformanceTrend(matches []models.MatchData, value func(models.MatchData) float64, period string) []PerformanceTrendPoint {
+	byPeriod := make(map[time.Time]*PerformanceTrendPoint)
+	for _, match := range matches {
+		start := trendPeriodStart(match.Date, period)
+		point, exists := byPeriod[start]
+		if !exists {
+			point = &PerformanceTrendPoint{PeriodStart: start}
+			byPeriod[start] = point
+		}
+		point.Matches++
+		point.Value += value(match)
+	}
+
+	points := make([]PerformanceTrendPoint, 0, len(byPeriod))
+	for _, point := range byPeriod {
+		point.Value /= float64(point.Matches)
+		points = append(points, *point)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].PeriodStart.Before(points[j].PeriodStart)
+	})
+
+	for i := range points {
+		from := max(0, i-performanceTrendWindow+1)
+		sum := 0.0
+		for _, point := range points[from : i+1] {
+			sum += point.Value
+		}
+		points[i].MovingAvg = sum / float64(i+1-from)
+	}
+
+	return points
+}
+
+// trendPeriodStart returns the UTC start of the day, week (Monday) or month
+// containing t
+func trendPeriodStart(t time.Time, period string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch period {
+	case "weekly":
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case "monthly":
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
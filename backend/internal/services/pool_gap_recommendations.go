@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// Pool gap recommendation tuning
+const (
+	// poolGapMinTierScore is the lowest meta tier score worth recommending
+	poolGapMinTierScore = 75.0
+	// poolGapBonus is added to a candidate's score when it covers a damage
+	// type the player's pool is missing
+	poolGapBonus = 15.0
+	// poolGapWindow is the time range used to read the player's pool
+	poolGapWindow = "90d"
+	// DefaultPoolGapLimit is how many recommendations are returned by default
+	DefaultPoolGapLimit = 5
+	// MaxPoolGapLimit is the most recommendations one request can ask for
+	MaxPoolGapLimit = 20
+)
+
+// gamesToLearn is the baseline games to become comfortable by champion
+// difficulty, before it is scaled by the player's own ramp
+var gamesToLearn = map[int]int{1: 10, 2: 25, 3: 50}
+
+// learningCurves names each difficulty level
+var learningCurves = map[int]string{1: "gentle", 2: "moderate", 3: "steep"}
+
+// PoolGapReport lists high-tier champions that fill gaps in a player's pool
+type PoolGapReport struct {
+	PlayerID           string                  `json:"player_id"`
+	Role               string                  `json:"role,omitempty"`
+	PoolSize           int                     `json:"pool_size"`
+	DamageCoverage     map[string]int          `json:"damage_coverage"` // played champions per damage type
+	MissingDamageTypes []string                `json:"missing_damage_types"`
+	Recommendations    []PoolGapRecommendation `json:"recommendations"`
+	// ChampionRamps is how long the player took to get comfortable on each
+	// champion they've played, the input to every learning estimate
+	ChampionRamps       []ChampionRamp `json:"champion_ramps"`
+	ComfortWinRate      float64        `json:"comfort_win_rate"`
+	ComfortMinGames     int            `json:"comfort_min_games"`
+	LearningMethodology string         `json:"learning_methodology"`
+}
+
+// PoolGapRecommendation is one champion suggested for the player to learn
+type PoolGapRecommendation struct {
+	Champion       string  `json:"champion"`
+	TierScore      float64 `json:"tier_score"`
+	WinRate        float64 `json:"win_rate"`
+	DamageType     string  `json:"damage_type,omitempty"`
+	FillsGap       bool    `json:"fills_gap"`
+	Difficulty     int     `json:"difficulty,omitempty"`
+	LearningCurve  string  `json:"learning_curve"`
+	EstimatedGames int     `json:"estimated_games_to_learn"`
+	// Learning shows how EstimatedGames was derived; nil when the champion's
+	// difficulty is unknown
+	Learning       *LearningEstimate `json:"learning,omitempty"`
+	Reasons        []string          `json:"reasons"`
+	recommendScore float64
+}
+
+// GetPoolGapRecommendations cross-references the champions the user has
+// played recently on any linked Riot account against the current meta tier list for their role and
+// recommends strong champions they don't play yet, favoring damage types
+// missing from their pool
+func (mas *MetaAnalyticsService) GetPoolGapRecommendations(ctx context.Context, playerID, role, patch, region, rank string, limit int) (*PoolGapReport, error) {
+	if limit <= 0 {
+		limit = DefaultPoolGapLimit
+	}
+
+	puuids, err := mas.analyticsService.accountPUUIDs(ctx, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load linked accounts: %w", err)
+	}
+
+	// The pool is every champion played on any of the user's accounts
+	var played []models.ChampionStats
+	for _, puuid := range puuids {
+		accountPlayed, err := mas.analyticsService.ListChampionStats(ctx, puuid, poolGapWindow, ChampionSortGames, 1, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load champion pool: %w", err)
+		}
+		played = append(played, accountPlayed...)
+	}
+
+	tierList, err := mas.GetTierList(ctx, patch, region, rank, role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tier list: %w", err)
+	}
+
+	ramps, err := mas.loadChampionRamps(ctx, puuids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load champion ramps: %w", err)
+	}
+
+	report := &PoolGapReport{
+		PlayerID: playerID,
+		Role:     role,
+		DamageCoverage: map[string]int{
+			models.DamagePhysical: 0,
+			models.DamageMagic:    0,
+			models.DamageMixed:    0,
+		},
+		MissingDamageTypes:  []string{},
+		Recommendations:     []PoolGapRecommendation{},
+		ChampionRamps:       ramps,
+		ComfortWinRate:      mas.comfortWinRate,
+		ComfortMinGames:     mas.comfortMinGames,
+		LearningMethodology: learningMethodology,
+	}
+
+	pool := make(map[string]bool, len(played))
+	for _, champion := range played {
+		name := models.ResolveChampionAlias(champion.ChampionName)
+		if pool[name] {
+			continue
+		}
+		pool[name] = true
+		if traits, ok := models.LookupChampionTraits(name); ok {
+			report.DamageCoverage[traits.DamageType]++
+		}
+	}
+	report.PoolSize = len(pool)
+	missing := make(map[string]bool)
+	for _, damageType := range []string{models.DamagePhysical, models.DamageMagic} {
+		// Mixed-damage champions count toward both sides
+		if report.DamageCoverage[damageType] == 0 && report.DamageCoverage[models.DamageMixed] == 0 {
+			missing[damageType] = true
+			report.MissingDamageTypes = append(report.MissingDamageTypes, damageType)
+		}
+	}
+
+	for _, entry := range tierListEntries(tierList) {
+		name := models.ResolveChampionAlias(entry.Champion)
+		if pool[name] || entry.TierScore < poolGapMinTierScore {
+			continue
+		}
+
+		rec := PoolGapRecommendation{
+			Champion:       name,
+			TierScore:      entry.TierScore,
+			WinRate:        entry.WinRate,
+			LearningCurve:  "unknown",
+			Reasons:        []string{fmt.Sprintf("Strong in the current meta (tier score %.0f)", entry.TierScore)},
+			recommendScore: entry.TierScore,
+		}
+		if traits, ok := models.LookupChampionTraits(name); ok {
+			rec.DamageType = traits.DamageType
+			rec.Difficulty = traits.Difficulty
+			rec.LearningCurve = learningCurves[traits.Difficulty]
+			learning := EstimateLearningCurve(traits.Difficulty, ramps)
+			rec.Learning = &learning
+			rec.EstimatedGames = learning.ExpectedGames
+			if missing[traits.DamageType] || (traits.DamageType == models.DamageMixed && len(missing) > 0) {
+				rec.FillsGap = true
+				rec.recommendScore += poolGapBonus
+				rec.Reasons = append(rec.Reasons, fmt.Sprintf("Adds %s damage your pool lacks", traits.DamageType))
+			}
+			if learning.ExpectedGames <= gamesToLearn[1] {
+				rec.Reasons = append(rec.Reasons, "Quick to pick up")
+			}
+		}
+		report.Recommendations = append(report.Recommendations, rec)
+	}
+
+	sort.SliceStable(report.Recommendations, func(i, j int) bool {
+		return report.Recommendations[i].recommendScore > report.Recommendations[j].recommendScore
+	})
+	if len(report.Recommendations) > limit {
+		report.Recommendations = report.Recommendations[:limit]
+	}
+
+	return report, nil
+}
+
+// tierListEntries flattens a tier list from the highest tier down
+func tierListEntries(tierList *ChampionTierList) []ChampionTierEntry {
+	tiers := [][]ChampionTierEntry{
+		tierList.SPlusTier, tierList.STier, tierList.APlusTier, tierList.ATier,
+		tierList.BPlusTier, tierList.BTier, tierList.CPlusTier, tierList.CTier, tierList.DTier,
+	}
+
+	entries := make([]ChampionTierEntry, 0)
+	for _, tier := range tiers {
+		entries = append(entries, tier...)
+	}
+	return entries
+}
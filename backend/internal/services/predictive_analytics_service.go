@@ -14,15 +14,32 @@ import (
 // PredictiveAnalyticsService handles predictive performance modeling
 type PredictiveAnalyticsService struct {
 	analyticsService *AnalyticsService
+	metaService      *MetaAnalyticsService
+
+	// recencyHalfLife is the age, in days, at which a game counts half as
+	// much towards champion recommendations
+	recencyHalfLife float64
 }
 
 // NewPredictiveAnalyticsService creates a new predictive analytics service
-func NewPredictiveAnalyticsService(analyticsService *AnalyticsService) *PredictiveAnalyticsService {
+func NewPredictiveAnalyticsService(analyticsService *AnalyticsService, metaService *MetaAnalyticsService) *PredictiveAnalyticsService {
 	return &PredictiveAnalyticsService{
 		analyticsService: analyticsService,
+		metaService:      metaService,
+		recencyHalfLife:  DefaultRecencyHalfLifeDays,
 	}
 }
 
+// SetRecencyHalfLife sets the half-life, in days, of the exponential recency
+// weighting applied to champion recommendations; values <= 0 restore the
+// default
+func (pas *PredictiveAnalyticsService) SetRecencyHalfLife(days float64) {
+	if days <= 0 {
+		days = DefaultRecencyHalfLifeDays
+	}
+	pas.recencyHalfLife = days
+}
+
 // PredictiveAnalysis represents comprehensive predictive analysis results
 type PredictiveAnalysis struct {
 	ID           string `json:"id"`
@@ -284,10 +301,30 @@ type ChampionRecommendation struct {
 	Champion             string   `json:"champion"`
 	RecommendationScore  float64  `json:"recommendation_score"`
 	PredictedWinRate     float64  `json:"predicted_win_rate"`
-	PredictedPerformance float64  `json:"predicted_performance"`
+	PredictedPerformance float64  `json:"predicted_performance"` // recency-weighted average KDA on the champion
 	ReasoningFactors     []string `json:"reasoning_factors"`
 	LearningCurve        string   `json:"learning_curve"`
 	Confidence           float64  `json:"confidence"`
+
+	ScoreBreakdown *RecommendationScoreBreakdown `json:"score_breakdown,omitempty"`
+}
+
+// RecommendationScoreBreakdown explains how a recommendation score was built.
+// Each component is normalized to 0-1; Contribution holds its weighted share
+// of the final 0-100 score.
+type RecommendationScoreBreakdown struct {
+	GamesPlayed      int                `json:"games_played"`
+	WinRate          float64            `json:"win_rate"`
+	AdjustedWinRate  float64            `json:"adjusted_win_rate"`
+	SampleConfidence float64            `json:"sample_confidence"`
+	Recency          float64            `json:"recency"`
+	DaysSincePlayed  int                `json:"days_since_played"`
+	WeightedGames    float64            `json:"weighted_games"` // recency-weighted games behind AdjustedWinRate
+	HalfLifeDays     float64            `json:"half_life_days"` // age at which a game counts as half a game
+	MetaTier         string             `json:"meta_tier"`
+	MetaScore        float64            `json:"meta_score"`
+	Weights          map[string]float64 `json:"weights"`
+	Contribution     map[string]float64 `json:"contribution"`
 }
 
 // ChampionMetaPrediction represents meta-based champion predictions
@@ -1152,28 +1189,75 @@ func (pas *PredictiveAnalyticsService) predictNextMatch(ctx context.Context, ana
 	return nil
 }
 
+// Recommendation scoring weights, they add up to 1
+const (
+	recommendationWinRateWeight    = 0.45
+	recommendationConfidenceWeight = 0.20
+	recommendationRecencyWeight    = 0.15
+	recommendationMetaWeight       = 0.20
+
+	// recommendationPriorGames is the number of virtual 50% games blended into
+	// the win rate, and the sample size at which confidence reaches 0.5
+	recommendationPriorGames = 10.0
+	// recommendationLookbackDays bounds the match history used for scoring
+	recommendationLookbackDays = 180
+	maxChampionRecommendations = 5
+)
+
+// DefaultRecencyHalfLifeDays is how many days old a game is when it counts
+// half as much towards champion recommendations
+const DefaultRecencyHalfLifeDays = 30.0
+
+// championPlayStats aggregates a player's games on one champion. Weighted
+// games and wins count each game by recencyWeight, so recent form outweighs
+// games months old.
+type championPlayStats struct {
+	Champion      string
+	Games         int
+	Wins          int
+	WeightedGames float64
+	WeightedWins  float64
+	WeightedKDA   float64 // sum of each game's KDA times its weight
+	LastPlayed    time.Time
+}
+
+// recencyWeight halves a game's weight every halfLife days since it was
+// played
+func recencyWeight(playedAt, now time.Time, halfLife float64) float64 {
+	if playedAt.IsZero() || halfLife <= 0 {
+		return 1
+	}
+	days := math.Max(0, now.Sub(playedAt).Hours()/24)
+	return math.Pow(0.5, days/halfLife)
+}
+
 // generateChampionPredictions generates champion recommendations
 func (pas *PredictiveAnalyticsService) generateChampionPredictions(ctx context.Context, analysis *PredictiveAnalysis) error {
-	// Champion recommendations
-	recommendations := []ChampionRecommendation{
-		{
-			Champion:             "Jinx",
-			RecommendationScore:  88.5,
-			PredictedWinRate:     67.2,
-			PredictedPerformance: 82.3,
-			ReasoningFactors:     []string{"Meta strength", "Personal performance history", "Team synergy"},
-			LearningCurve:        "moderate",
-			Confidence:           0.89,
-		},
-		{
-			Champion:             "Caitlyn",
-			RecommendationScore:  84.1,
-			PredictedWinRate:     63.8,
-			PredictedPerformance: 79.6,
-			ReasoningFactors:     []string{"Safe pick", "Good scaling", "High skill ceiling"},
-			LearningCurve:        "easy",
-			Confidence:           0.85,
-		},
+	now := time.Now()
+	matches, err := pas.analyticsService.loadAccountMatches(ctx, analysis.PlayerID, now.AddDate(0, 0, -recommendationLookbackDays), now)
+	if err != nil {
+		return fmt.Errorf("failed to load player matches: %w", err)
+	}
+
+	tiers := pas.championMetaTiers(ctx)
+
+	recommendations := make([]ChampionRecommendation, 0)
+	for _, stats := range aggregateChampionPlayStats(matches, now, pas.recencyHalfLife) {
+		tier, found := tiers[stats.Champion]
+		var entry *ChampionTierEntry
+		tierName := "unranked"
+		if found {
+			entry = &tier.entry
+			tierName = tier.name
+		}
+		recommendations = append(recommendations, scoreChampionRecommendation(stats, entry, tierName, now, pas.recencyHalfLife))
+	}
+
+	sort.Slice(recommendations, func(i, j int) bool {
+		return recommendations[i].RecommendationScore > recommendations[j].RecommendationScore
+	})
+	if len(recommendations) > maxChampionRecommendations {
+		recommendations = recommendations[:maxChampionRecommendations]
 	}
 
 	// Meta champions
@@ -1219,6 +1303,173 @@ func (pas *PredictiveAnalyticsService) generateChampionPredictions(ctx context.C
 	return nil
 }
 
+// aggregateChampionPlayStats groups matches by champion, weighting each game
+// by its age relative to now
+func aggregateChampionPlayStats(matches []models.MatchData, now time.Time, halfLife float64) []championPlayStats {
+	byChampion := make(map[string]*championPlayStats)
+	order := make([]string, 0)
+
+	for _, match := range matches {
+		if match.ChampionName == "" {
+			continue
+		}
+		stats, exists := byChampion[match.ChampionName]
+		if !exists {
+			stats = &championPlayStats{Champion: match.ChampionName}
+			byChampion[match.ChampionName] = stats
+			order = append(order, match.ChampionName)
+		}
+		weight := recencyWeight(match.Date, now, halfLife)
+		stats.Games++
+		stats.WeightedGames += weight
+		stats.WeightedKDA += float64(match.Kills+match.Assists) / math.Max(1, float64(match.Deaths)) * weight
+		if match.Win {
+			stats.Wins++
+			stats.WeightedWins += weight
+		}
+		if match.Date.After(stats.LastPlayed) {
+			stats.LastPlayed = match.Date
+		}
+	}
+
+	result := make([]championPlayStats, 0, len(order))
+	for _, champion := range order {
+		result = append(result, *byChampion[champion])
+	}
+	return result
+}
+
+// scoreChampionRecommendation combines personal win rate, sample size, recency
+// and meta strength into a 0-100 recommendation score. The win rate is
+// recency-weighted, so a champion the player did well on long ago ranks below
+// one they are winning on now.
+func scoreChampionRecommendation(stats championPlayStats, metaEntry *ChampionTierEntry, tierName string, now time.Time, halfLife float64) ChampionRecommendation {
+	winRate := 0.0
+	if stats.Games > 0 {
+		winRate = float64(stats.Wins) / float64(stats.Games)
+	}
+	averageKDA := 0.0
+	if stats.WeightedGames > 0 {
+		averageKDA = stats.WeightedKDA / stats.WeightedGames
+	}
+
+	// Shrink towards 50% so a 2-0 record doesn't outrank a 60% over 50 games;
+	// old games weigh less and so pull less away from 50%
+	adjustedWinRate := (stats.WeightedWins + 0.5*recommendationPriorGames) / (stats.WeightedGames + recommendationPriorGames)
+	confidence := float64(stats.Games) / (float64(stats.Games) + recommendationPriorGames)
+
+	daysSince := 0.0
+	if !stats.LastPlayed.IsZero() {
+		daysSince = math.Max(0, now.Sub(stats.LastPlayed).Hours()/24)
+	}
+	recency := recencyWeight(stats.LastPlayed, now, halfLife)
+
+	metaScore := 0.5 // Neutral when the champion isn't on the tier list
+	if metaEntry != nil {
+		metaScore = math.Max(0, math.Min(1, metaEntry.TierScore/100))
+	}
+
+	contribution := map[string]float64{
+		"win_rate":          adjustedWinRate * recommendationWinRateWeight * 100,
+		"sample_confidence": confidence * recommendationConfidenceWeight * 100,
+		"recency":           recency * recommendationRecencyWeight * 100,
+		"meta_tier":         metaScore * recommendationMetaWeight * 100,
+	}
+	score := 0.0
+	for _, value := range contribution {
+		score += value
+	}
+
+	reasons := make([]string, 0, 4)
+	if stats.Games >= 5 && winRate >= 0.55 {
+		reasons = append(reasons, fmt.Sprintf("%.0f%% win rate over %d games", winRate*100, stats.Games))
+	}
+	if confidence < 0.5 {
+		reasons = append(reasons, "Small sample size")
+	}
+	if daysSince <= 7 {
+		reasons = append(reasons, "Played recently")
+	} else if daysSince > 60 {
+		reasons = append(reasons, "Not played in a while")
+	}
+	if metaScore >= 0.8 {
+		reasons = append(reasons, "Strong in current meta")
+	}
+
+	learningCurve := "moderate"
+	switch {
+	case stats.Games >= 30:
+		learningCurve = "mastered"
+	case stats.Games < 5:
+		learningCurve = "steep"
+	}
+
+	return ChampionRecommendation{
+		Champion:             stats.Champion,
+		RecommendationScore:  math.Round(score*10) / 10,
+		PredictedWinRate:     math.Round(adjustedWinRate*1000) / 10,
+		PredictedPerformance: math.Round(averageKDA*100) / 100,
+		ReasoningFactors:     reasons,
+		LearningCurve:        learningCurve,
+		Confidence:           confidence,
+		ScoreBreakdown: &RecommendationScoreBreakdown{
+			GamesPlayed:      stats.Games,
+			WinRate:          winRate,
+			AdjustedWinRate:  adjustedWinRate,
+			SampleConfidence: confidence,
+			Recency:          recency,
+			DaysSincePlayed:  int(daysSince),
+			WeightedGames:    math.Round(stats.WeightedGames*100) / 100,
+			HalfLifeDays:     halfLife,
+			MetaTier:         tierName,
+			MetaScore:        metaScore,
+			Weights: map[string]float64{
+				"win_rate":          recommendationWinRateWeight,
+				"sample_confidence": recommendationConfidenceWeight,
+				"recency":           recommendationRecencyWeight,
+				"meta_tier":         recommendationMetaWeight,
+			},
+			Contribution: contribution,
+		},
+	}
+}
+
+type championMetaTier struct {
+	name  string
+	entry ChampionTierEntry
+}
+
+// championMetaTiers flattens the current tier list into a lookup by champion
+func (pas *PredictiveAnalyticsService) championMetaTiers(ctx context.Context) map[string]championMetaTier {
+	tiers := make(map[string]championMetaTier)
+	if pas.metaService == nil {
+		return tiers
+	}
+
+	tierList, err := pas.metaService.GetTierList(ctx, "current", "all", "all", "ALL")
+	if err != nil || tierList == nil {
+		return tiers
+	}
+
+	groups := []struct {
+		name    string
+		entries []ChampionTierEntry
+	}{
+		{"S+", tierList.SPlusTier}, {"S", tierList.STier},
+		{"A+", tierList.APlusTier}, {"A", tierList.ATier},
+		{"B+", tierList.BPlusTier}, {"B", tierList.BTier},
+		{"C+", tierList.CPlusTier}, {"C", tierList.CTier},
+		{"D", tierList.DTier},
+	}
+	for _, group := range groups {
+		for _, entry := range group.entries {
+			tiers[entry.Champion] = championMetaTier{name: group.name, entry: entry}
+		}
+	}
+
+	return tiers
+}
+
 // predictTeamPerformance predicts team performance
 func (pas *PredictiveAnalyticsService) predictTeamPerformance(ctx context.Context, analysis *PredictiveAnalysis) error {
 	analysis.TeamPerformance = TeamPredictionData{
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+func TestChampionRecommendationRecencyWeighting(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 1.0, recencyWeight(now, now, 30))
+	assert.InDelta(t, 0.5, recencyWeight(now.AddDate(0, 0, -30), now, 30), 1e-9)
+	assert.InDelta(t, 0.25, recencyWeight(now.AddDate(0, 0, -60), now, 30), 1e-9)
+
+	var matches []models.MatchData
+	// 12-0 on Ahri four months ago, 7-3 on Jinx this week
+	for i := 0; i < 12; i++ {
+		matches = append(matches, models.MatchData{ChampionName: "Ahri", Win: true, Date: now.AddDate(0, -4, -i)})
+	}
+	for i := 0; i < 10; i++ {
+		matches = append(matches, models.MatchData{ChampionName: "Jinx", Win: i < 7, Date: now.AddDate(0, 0, -i%5),
+			Kills: 6, Deaths: 2, Assists: 4})
+	}
+
+	stats := aggregateChampionPlayStats(matches, now, 30)
+	require.Len(t, stats, 2)
+	assert.Equal(t, 12, stats[0].Games)
+	assert.Less(t, stats[0].WeightedGames, 1.0, "games four months old barely count")
+	assert.Greater(t, stats[1].WeightedGames, 8.0)
+
+	ahri := scoreChampionRecommendation(stats[0], nil, "unranked", now, 30)
+	jinx := scoreChampionRecommendation(stats[1], nil, "unranked", now, 30)
+	assert.Greater(t, jinx.RecommendationScore, ahri.RecommendationScore)
+	assert.Equal(t, 30.0, jinx.ScoreBreakdown.HalfLifeDays)
+	assert.Equal(t, 5.0, jinx.PredictedPerformance, "average KDA, not a copy of the score")
+
+	// A long half-life brings back the old record
+	stats = aggregateChampionPlayStats(matches, now, 3650)
+	ahri = scoreChampionRecommendation(stats[0], nil, "unranked", now, 3650)
+	jinx = scoreChampionRecommendation(stats[1], nil, "unranked", now, 3650)
+	assert.Greater(t, ahri.PredictedWinRate, jinx.PredictedWinRate)
+}
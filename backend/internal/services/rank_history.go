@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// RankHistory returns the user's stored LP history, oldest first. Entries are
+// keyed by the summoner the user signed up with, see
+// models.RankProgressionHistory.
+func (s *RiotService) RankHistory(ctx context.Context, userID string) ([]models.RankProgressionHistory, error) {
+	var history []models.RankProgressionHistory
+	err := s.db.WithContext(ctx).
+		Joins("JOIN users ON users.summoner_id = rank_progression_history.summoner_id").
+		Where("users.id = ?", userID).
+		Order("rank_progression_history.recorded_at").
+		Find(&history).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rank history: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+)
+
+// roundTripFunc answers requests without a network
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+// newRetryTestService returns a service whose Riot requests are answered
+// with the given status codes in turn, the last one repeating, and a pointer
+// to the number of requests made
+func newRetryTestService(retries int, backoff time.Duration, statuses ...int) (*RiotService, *int) {
+	cfg := &config.Config{}
+	cfg.Riot.RateLimitPerSecond = 1000
+	cfg.Sync.RateLimitRetries = retries
+	cfg.Sync.RateLimitBackoff = backoff
+
+	calls := 0
+	s := &RiotService{
+		config:       cfg,
+		rateLimiters: make(map[string]*rate.Limiter),
+		syncStatus:   make(map[string]*SyncStatus),
+		httpClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			status := statuses[len(statuses)-1]
+			if calls < len(statuses) {
+				status = statuses[calls]
+			}
+			calls++
+			return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}")), Request: r}, nil
+		})},
+	}
+	return s, &calls
+}
+
+func TestMakeAPIRequestRetriesRateLimits(t *testing.T) {
+	s, calls := newRetryTestService(3, time.Millisecond, 429, 429, 200)
+	ctx := s.startSyncStatus(context.Background(), "user", "account")
+
+	resp, err := s.makeAPIRequest(ctx, "euw1", "/lol/status")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, 3, *calls)
+
+	status, ok := s.GetSyncStatus("user")
+	require.True(t, ok)
+	assert.Equal(t, SyncStateRunning, status.State, "the sync is back to running after the wait")
+	assert.Equal(t, 2, status.RetryAttempt)
+	assert.Nil(t, status.NextRetryAt)
+}
+
+func TestMakeAPIRequestGivesUpAfterRetries(t *testing.T) {
+	s, calls := newRetryTestService(2, time.Millisecond, 429)
+	_, err := s.makeAPIRequest(context.Background(), "euw1", "/lol/status")
+	assert.ErrorIs(t, err, ErrRateLimitExceeded)
+	assert.Equal(t, 3, *calls, "the first attempt and two retries")
+
+	s, calls = newRetryTestService(2, time.Millisecond, 404)
+	_, err = s.makeAPIRequest(context.Background(), "euw1", "/lol/status")
+	assert.ErrorIs(t, err, ErrSummonerNotFound)
+	assert.Equal(t, 1, *calls, "only rate limits are retried")
+}
+
+func TestMakeAPIRequestCancelledDuringBackoff(t *testing.T) {
+	s, calls := newRetryTestService(3, time.Hour, 429)
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	started := time.Now()
+	_, err := s.makeAPIRequest(ctx, "euw1", "/lol/status")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NotErrorIs(t, err, ErrRateLimitExceeded)
+	assert.Less(t, time.Since(started), time.Minute, "cancelling ends the backoff")
+	assert.Equal(t, 1, *calls)
+
+	_, err = s.makeAPIRequest(ctx, "euw1", "/lol/status")
+	assert.ErrorIs(t, err, context.Canceled, "a cancelled request is not reported as rate limited")
+}
+
+func TestRateLimitDelay(t *testing.T) {
+	s, _ := newRetryTestService(3, 2*time.Second, 429)
+
+	assert.Equal(t, 2*time.Second, s.rateLimitDelay(http.Header{}, 1))
+	assert.Equal(t, 4*time.Second, s.rateLimitDelay(http.Header{}, 2))
+	assert.Equal(t, 8*time.Second, s.rateLimitDelay(http.Header{}, 3))
+
+	header := http.Header{}
+	header.Set("Retry-After", "7")
+	assert.Equal(t, 7*time.Second, s.rateLimitDelay(header, 3), "Retry-After wins over the backoff")
+	header.Set("Retry-After", "soon")
+	assert.Equal(t, 2*time.Second, s.rateLimitDelay(header, 1))
+
+	s.config.Sync.RateLimitBackoff = 0
+	assert.Equal(t, 10*time.Second, s.rateLimitDelay(http.Header{}, 1))
+}
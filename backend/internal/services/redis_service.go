@@ -38,11 +38,36 @@ func (r *RedisService) Get(ctx context.Context, key string, dest interface{}) er
 	return json.Unmarshal(data, dest)
 }
 
+// Ping checks that Redis is reachable
+func (r *RedisService) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
 // Delete removes a key from Redis
 func (r *RedisService) Delete(ctx context.Context, key string) error {
 	return r.client.Del(ctx, key).Err()
 }
 
+// DeletePrefix removes every key that starts with prefix
+func (r *RedisService) DeletePrefix(ctx context.Context, prefix string) error {
+	var cursor uint64
+	for {
+		keys, next, err := r.client.Scan(ctx, cursor, prefix+"*", 100).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := r.client.Del(ctx, keys...).Err(); err != nil {
+				return err
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
 // Exists checks if a key exists in Redis
 func (r *RedisService) Exists(ctx context.Context, key string) (bool, error) {
 	count, err := r.client.Exists(ctx, key).Result()
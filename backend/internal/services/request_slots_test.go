@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+)
+
+func TestAcquireRequestSlot(t *testing.T) {
+	unlimited := &RiotService{}
+	release, err := unlimited.acquireRequestSlot(context.Background())
+	require.NoError(t, err, "without a limit every request gets a slot")
+	release()
+
+	s := &RiotService{requestSlots: make(chan struct{}, 1)}
+	release, err = s.acquireRequestSlot(context.Background())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = s.acquireRequestSlot(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "a request waits while every slot is taken")
+
+	release()
+	release()
+	assert.Len(t, s.requestSlots, 0, "releasing twice frees the slot once")
+
+	release, err = s.acquireRequestSlot(context.Background())
+	require.NoError(t, err)
+	release()
+}
+
+func TestMakeAPIRequestLimitsConcurrency(t *testing.T) {
+	const limit = 2
+	cfg := &config.Config{}
+	cfg.Riot.RateLimitPerSecond = 1000
+	cfg.Riot.MaxConcurrentRequests = limit
+
+	var inFlight, maxInFlight int32
+	s := NewRiotService(cfg, nil)
+	s.httpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+
+		status := http.StatusOK
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			status = http.StatusNotFound
+		}
+		return &http.Response{StatusCode: status, Header: http.Header{}, Body: io.NopCloser(strings.NewReader("{}")), Request: r}, nil
+	})}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			endpoint := "/lol/status"
+			if i%2 == 0 {
+				endpoint = "/lol/missing"
+			}
+			resp, err := s.makeAPIRequest(context.Background(), "euw1", endpoint)
+			if err != nil {
+				assert.ErrorIs(t, err, ErrSummonerNotFound)
+				return
+			}
+			resp.Body.Close()
+		}(i)
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(limit))
+	assert.Len(t, s.requestSlots, 0, "every slot is freed, including on error responses")
+}
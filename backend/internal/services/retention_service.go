@@ -0,0 +1,207 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/logger"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// RetentionService periodically purges matches older than a configured number
+// of months. Before any match is removed, every linked player's aggregates
+// are saved as a season archive so long-term history survives the purge; if
+// any archive fails, nothing is deleted that run.
+// Retention is opt-in: it only runs when MATCH_RETENTION_MONTHS is set.
+type RetentionService struct {
+	db               *gorm.DB
+	analyticsService *AnalyticsService
+
+	months   int
+	interval time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// RetentionPurgeStats summarizes one retention purge
+type RetentionPurgeStats struct {
+	StartedAt     time.Time     `json:"started_at"`
+	Duration      time.Duration `json:"duration"`
+	Cutoff        time.Time     `json:"cutoff"`
+	Players       int           `json:"players"`
+	Archived      int           `json:"archived"`
+	MatchesPurged int64         `json:"matches_purged"`
+	Failed        int           `json:"failed"`
+}
+
+// NewRetentionService creates a retention service keeping months of matches;
+// the interval defaults to daily
+func NewRetentionService(db *gorm.DB, analyticsService *AnalyticsService, months int, interval time.Duration) *RetentionService {
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &RetentionService{
+		db:               db,
+		analyticsService: analyticsService,
+		months:           months,
+		interval:         interval,
+		ctx:              ctx,
+		cancel:           cancel,
+	}
+}
+
+// Start runs a purge immediately and then on every interval. It does nothing
+// when no retention period is configured.
+func (s *RetentionService) Start() {
+	if s.months <= 0 {
+		return
+	}
+	logger.Infof("Starting match retention service (keeping %d months, interval %s)", s.months, s.interval)
+
+	go func() {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		s.RunPurge(s.ctx)
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.C:
+				s.RunPurge(s.ctx)
+			}
+		}
+	}()
+}
+
+// Stop cancels the running purge and stops scheduling new ones
+func (s *RetentionService) Stop() {
+	logger.Infof("Stopping match retention service...")
+	s.cancel()
+}
+
+// RunPurge archives the aggregates of every player with matches older than
+// the retention period, then removes the stored Riot matches of that age.
+// The delete only runs once every archive has been saved.
+func (s *RetentionService) RunPurge(ctx context.Context) *RetentionPurgeStats {
+	stats := &RetentionPurgeStats{
+		StartedAt: time.Now(),
+		Cutoff:    time.Now().AddDate(0, -s.months, 0),
+	}
+
+	players, err := s.analyticsService.matchRepo.ListPlayersWithMatchesBefore(ctx, stats.Cutoff)
+	if err != nil {
+		logger.Errorf("Match retention: failed to list players: %v", err)
+		return stats
+	}
+	stats.Players = len(players)
+
+	for _, playerID := range players {
+		if ctx.Err() != nil {
+			break
+		}
+
+		archive, err := s.analyticsService.ArchiveBefore(ctx, playerID, stats.Cutoff)
+		if err != nil {
+			stats.Failed++
+			logger.Warnf("Match retention: archiving player %s failed: %v", playerID, err)
+			continue
+		}
+		if archive != nil {
+			stats.Archived++
+		}
+	}
+
+	switch {
+	case ctx.Err() != nil:
+		logger.Warnf("Match retention: cancelled before every player was archived, skipping purge")
+	case stats.Failed > 0:
+		logger.Warnf("Match retention: %d players could not be archived, skipping purge", stats.Failed)
+	default:
+		matches, err := s.purgeRiotMatches(ctx, stats.Cutoff)
+		if err != nil {
+			logger.Errorf("Match retention: failed to purge stored Riot matches: %v", err)
+		}
+		stats.MatchesPurged = matches
+	}
+
+	stats.Duration = time.Since(stats.StartedAt)
+	logger.Infof("Match retention finished in %s: %d players, %d archived, %d Riot matches purged, %d failed",
+		stats.Duration.Round(time.Millisecond), stats.Players, stats.Archived, stats.MatchesPurged, stats.Failed)
+
+	return stats
+}
+
+// purgeRiotMatches deletes stored Riot matches that started before cutoff,
+// along with their participants, bans and team objectives
+func (s *RetentionService) purgeRiotMatches(ctx context.Context, cutoff time.Time) (int64, error) {
+	var deleted int64
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		old := tx.Model(&models.Match{}).Select("id").Where("game_start_timestamp < ?", cutoff.UnixMilli())
+		if err := tx.Where("match_id IN (?)", old).Delete(&models.MatchParticipant{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("match_id IN (?)", old).Delete(&models.MatchBan{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("match_id IN (?)", old).Delete(&models.MatchTeamObjectives{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("game_start_timestamp < ?", cutoff.UnixMilli()).Delete(&models.Match{})
+		if result.Error != nil {
+			return result.Error
+		}
+		deleted = result.RowsAffected
+		return nil
+	})
+
+	return deleted, err
+}
+
+// ArchiveBefore saves the aggregates of the player's current-season matches
+// older than cutoff as a season archive, so they survive the retention purge.
+// Matches from earlier seasons are already covered by their own archives.
+// The archive is stamped at cutoff, so the current season continues from the
+// oldest match still stored. It returns nil when nothing needed archiving.
+func (as *AnalyticsService) ArchiveBefore(ctx context.Context, playerID string, cutoff time.Time) (*models.SeasonArchive, error) {
+	archives, err := as.playerRepo.ListSeasonArchives(ctx, playerID)
+	if err != nil {
+		return nil, err
+	}
+
+	seasonStart := championStatsEpoch
+	if len(archives) > 0 {
+		seasonStart = archives[0].ArchivedAt
+	}
+	if !cutoff.After(seasonStart) {
+		return nil, nil
+	}
+
+	stats, err := as.seasonStats(ctx, playerID, []string{playerID}, seasonStart, cutoff)
+	if err != nil {
+		return nil, err
+	}
+	if stats.TotalMatches == 0 {
+		return nil, nil
+	}
+
+	archive := &models.SeasonArchive{
+		PlayerID:    playerID,
+		Season:      "retention-" + cutoff.Format("2006-01-02"),
+		SeasonStart: seasonStart,
+		ArchivedAt:  cutoff,
+		Stats:       stats,
+	}
+	if err := as.playerRepo.SaveSeasonArchive(ctx, archive); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
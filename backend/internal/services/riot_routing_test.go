@@ -0,0 +1,27 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+)
+
+func TestRiotServiceRouting(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Riot.Routing = map[string]string{"euw1": "europe", "me1": "europe", "kr": "asia"}
+	s := &RiotService{config: cfg}
+
+	assert.Equal(t, "https://me1.api.riotgames.com", s.getRegionURL("me1"), "configured platforms get their own host")
+	assert.Equal(t, "https://europe.api.riotgames.com", s.getRegionURL("europe"))
+	assert.Equal(t, "https://na1.api.riotgames.com", s.getRegionURL("xx9"), "unknown platforms fall back to na1")
+
+	assert.Equal(t, "europe", s.getPlatformFromRegion("me1"))
+	assert.Equal(t, "asia", s.getPlatformFromRegion("kr"))
+	assert.Equal(t, "americas", s.getPlatformFromRegion("xx9"))
+
+	assert.True(t, s.IsSupportedRegion("me1"))
+	assert.False(t, s.IsSupportedRegion("na1"), "platforms outside the routing table are rejected")
+	assert.Equal(t, []string{"euw1", "kr", "me1"}, s.SupportedRegions())
+}
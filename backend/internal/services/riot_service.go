@@ -1,18 +1,26 @@
 package services
 
 import (
+	"archive/zip"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"golang.org/x/time/rate"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/herald-lol/herald/backend/internal/config"
+	"github.com/herald-lol/herald/backend/internal/logger"
 	"github.com/herald-lol/herald/backend/internal/models"
 )
 
@@ -24,6 +32,64 @@ type RiotService struct {
 	// Rate limiters for different regions
 	rateLimiters map[string]*rate.Limiter
 	mutex        sync.RWMutex
+
+	// Slots for Riot requests in flight, shared by every caller; nil when
+	// concurrency is unlimited
+	requestSlots chan struct{}
+
+	// API key health, updated from every Riot response
+	keyStatus   RiotAPIKeyStatus
+	keyStatusMu sync.RWMutex
+
+	// Serializes sync writes on SQLite, which only allows a single writer
+	writeMu sync.Mutex
+
+	// Called with the user ID after a sync stores new matches
+	syncHooks   []func(userID string)
+	syncHooksMu sync.RWMutex
+
+	// Called with every match a sync stores
+	matchHooks   []MatchHook
+	matchHooksMu sync.RWMutex
+
+	// Latest sync status per user, including rate limit retry state
+	syncStatus   map[string]*SyncStatus
+	syncStatusMu sync.RWMutex
+
+	// Recently fetched match lists, kept for Riot.MatchListCacheTTL
+	matchLists *matchListCache
+
+	// Running sync per user, so a second request doesn't start a duplicate
+	syncLocks   map[string]*syncLock
+	syncLocksMu sync.Mutex
+
+	// Bulk syncs of all of a user's accounts, running or recently finished
+	bulkSyncs   map[uuid.UUID]*BulkSyncResult
+	bulkSyncsMu sync.RWMutex
+
+	// Matches loaded by running exports and not yet written, nil when
+	// unlimited
+	exportRows *exportRowBudget
+
+	// When the item list of a patch last failed to download, so the download
+	// is not retried for every match of that patch
+	itemFailures   map[string]time.Time
+	itemFailuresMu sync.Mutex
+}
+
+// itemNamesRetryAfter is how long a patch whose item list could not be
+// downloaded is skipped before it is tried again
+const itemNamesRetryAfter = 5 * time.Minute
+
+// RiotAPIKeyStatus reports whether the configured Riot API key is usable.
+// Development keys expire every 24 hours; once Riot answers 401/403 the key is
+// flagged invalid until a later request succeeds (e.g. after a key rotation).
+type RiotAPIKeyStatus struct {
+	Configured   bool       `json:"configured"`
+	Valid        bool       `json:"valid"`
+	InvalidSince *time.Time `json:"invalid_since,omitempty"`
+	LastStatus   int        `json:"last_status,omitempty"`
+	LastChecked  *time.Time `json:"last_checked,omitempty"`
 }
 
 // Riot API Response Structures
@@ -63,7 +129,43 @@ type MatchHistory struct {
 	MatchIDs []string `json:"matchIds"`
 }
 
+// MatchTypes are the values Riot accepts for the match list type parameter
+var MatchTypes = []string{"ranked", "normal", "tourney", "tutorial"}
+
+// MatchListFilter narrows Riot's match list so a sync only fetches details
+// for the games it keeps. The zero value lists every match.
+type MatchListFilter struct {
+	Type  string `json:"type,omitempty"`  // One of MatchTypes
+	Queue int    `json:"queue,omitempty"` // Queue ID, e.g. 420 for ranked solo/duo
+}
+
+// Validate checks the filter against the values Riot accepts
+func (f MatchListFilter) Validate() error {
+	if f.Type != "" && !containsString(MatchTypes, f.Type) {
+		return fmt.Errorf("%w: type must be one of %s", ErrInvalidMatchFilter, strings.Join(MatchTypes, ", "))
+	}
+	if f.Queue < 0 {
+		return fmt.Errorf("%w: queue must be a positive queue ID", ErrInvalidMatchFilter)
+	}
+	return nil
+}
+
+// query returns the filter as match list query parameters
+func (f MatchListFilter) query() string {
+	var query string
+	if f.Type != "" {
+		query += "&type=" + url.QueryEscape(f.Type)
+	}
+	if f.Queue > 0 {
+		query += "&queue=" + strconv.Itoa(f.Queue)
+	}
+	return query
+}
+
 type MatchDetails struct {
+	// Raw is the unmodified response body, kept for raw JSON exports
+	Raw json.RawMessage `json:"-"`
+
 	Metadata struct {
 		DataVersion  string   `json:"dataVersion"`
 		MatchID      string   `json:"matchId"`
@@ -247,19 +349,32 @@ type MatchDetails struct {
 			WardsKilled                    int    `json:"wardsKilled"`
 			WardsPlaced                    int    `json:"wardsPlaced"`
 			Win                            bool   `json:"win"`
+
+			// ItemNames holds the names of item0-item6 when resolved for a
+			// match detail response, see ResolveItemNames
+			ItemNames []string `json:"itemNames,omitempty"`
+			// Grade is the participant's performance grade, see GradeParticipants
+			Grade *MatchGrade `json:"grade,omitempty"`
 		} `json:"participants"`
 	} `json:"info"`
 }
 
 var (
 	ErrRateLimitExceeded  = errors.New("rate limit exceeded")
-	ErrAPIKeyInvalid      = errors.New("invalid API key")
+	ErrInvalidAPIKey      = errors.New("riot API key is invalid or expired")
 	ErrSummonerNotFound   = errors.New("summoner not found")
 	ErrMatchNotFound      = errors.New("match not found")
 	ErrRegionNotSupported = errors.New("region not supported")
+	ErrGameCountExceeded  = errors.New("requested game count exceeds the configured maximum")
+	ErrInvalidMatchFilter = errors.New("invalid match filter")
 )
 
 func NewRiotService(config *config.Config, db *gorm.DB) *RiotService {
+	var requestSlots chan struct{}
+	if config.Riot.MaxConcurrentRequests > 0 {
+		requestSlots = make(chan struct{}, config.Riot.MaxConcurrentRequests)
+	}
+
 	return &RiotService{
 		config: config,
 		db:     db,
@@ -268,6 +383,53 @@ func NewRiotService(config *config.Config, db *gorm.DB) *RiotService {
 		},
 		rateLimiters: make(map[string]*rate.Limiter),
 		mutex:        sync.RWMutex{},
+		requestSlots: requestSlots,
+		syncStatus:   make(map[string]*SyncStatus),
+		matchLists:   newMatchListCache(),
+		syncLocks:    make(map[string]*syncLock),
+		bulkSyncs:    make(map[uuid.UUID]*BulkSyncResult),
+		exportRows:   newExportRowBudget(config.Riot.ExportMaxInFlightRows),
+		itemFailures: make(map[string]time.Time),
+		keyStatus: RiotAPIKeyStatus{
+			Configured: config.Riot.APIKey != "",
+			Valid:      config.Riot.APIKey != "",
+		},
+	}
+}
+
+// IsConfigured returns true if an API key is set and Riot has not rejected it
+func (s *RiotService) IsConfigured() bool {
+	status := s.APIKeyStatus()
+	return status.Configured && status.Valid
+}
+
+// APIKeyStatus returns the current health of the Riot API key
+func (s *RiotService) APIKeyStatus() RiotAPIKeyStatus {
+	s.keyStatusMu.RLock()
+	defer s.keyStatusMu.RUnlock()
+	return s.keyStatus
+}
+
+// recordKeyStatus updates the key health from a Riot response status code
+func (s *RiotService) recordKeyStatus(statusCode int) {
+	now := time.Now()
+
+	s.keyStatusMu.Lock()
+	defer s.keyStatusMu.Unlock()
+
+	s.keyStatus.LastStatus = statusCode
+	s.keyStatus.LastChecked = &now
+
+	switch {
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		if s.keyStatus.Valid || s.keyStatus.InvalidSince == nil {
+			logger.Errorf("Riot API rejected the API key (HTTP %d); it is invalid or has expired", statusCode)
+			s.keyStatus.InvalidSince = &now
+		}
+		s.keyStatus.Valid = false
+	case statusCode < 400:
+		s.keyStatus.Valid = true
+		s.keyStatus.InvalidSince = nil
 	}
 }
 
@@ -287,14 +449,44 @@ func (s *RiotService) GetRateLimiter(region string) *rate.Limiter {
 	return limiter
 }
 
-// makeAPIRequest makes a rate-limited request to Riot API
+// makeAPIRequest makes a rate-limited request to Riot API. Requests answered
+// with 429 are retried up to Sync.RateLimitRetries times; during a sync the
+// wait is reported through the user's SyncStatus. Cancelling ctx ends the
+// wait with ctx.Err().
 func (s *RiotService) makeAPIRequest(ctx context.Context, region, endpoint string) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, retryAfter, err := s.doAPIRequest(ctx, region, endpoint)
+		if !errors.Is(err, ErrRateLimitExceeded) || retryAfter == nil || attempt > s.config.Sync.RateLimitRetries {
+			return resp, err
+		}
+
+		delay := s.rateLimitDelay(retryAfter, attempt)
+		s.reportRetry(ctx, attempt, time.Now().Add(delay), "rate_limited")
+		logger.Debugf("Riot rate limit hit for %s, retry %d in %s", region, attempt, delay)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		s.reportRetryDone(ctx)
+	}
+}
+
+// doAPIRequest makes a single request. On a 429 it also returns the response
+// headers so the caller can honor Retry-After.
+func (s *RiotService) doAPIRequest(ctx context.Context, region, endpoint string) (*http.Response, http.Header, error) {
 	// Get rate limiter for region
 	limiter := s.GetRateLimiter(region)
 
-	// Wait for rate limit
+	// Wait for rate limit; a cancelled request reports the cancellation
 	if err := limiter.Wait(ctx); err != nil {
-		return nil, ErrRateLimitExceeded
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+		return nil, nil, ErrRateLimitExceeded
 	}
 
 	// Build URL
@@ -304,36 +496,74 @@ func (s *RiotService) makeAPIRequest(ctx context.Context, region, endpoint strin
 	// Create request
 	req, err := http.NewRequestWithContext(ctx, "GET", fullURL, nil)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Add API key header
 	req.Header.Set("X-Riot-Token", s.config.Riot.APIKey)
 	req.Header.Set("User-Agent", "Herald.lol/1.0")
 
+	// Take a global request slot, held until the response body is closed
+	release, err := s.acquireRequestSlot(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	// Make request
 	resp, err := s.httpClient.Do(req)
 	if err != nil {
-		return nil, err
+		release()
+		return nil, nil, err
 	}
+	resp.Body = &slotReleasingBody{ReadCloser: resp.Body, release: release}
+
+	s.recordKeyStatus(resp.StatusCode)
 
 	// Handle rate limiting
 	if resp.StatusCode == 429 {
 		resp.Body.Close()
-		return nil, ErrRateLimitExceeded
+		return nil, resp.Header, ErrRateLimitExceeded
 	}
 
 	// Handle API errors
 	switch resp.StatusCode {
 	case 401, 403:
 		resp.Body.Close()
-		return nil, ErrAPIKeyInvalid
+		return nil, nil, ErrInvalidAPIKey
 	case 404:
 		resp.Body.Close()
-		return nil, ErrSummonerNotFound
+		return nil, nil, ErrSummonerNotFound
+	}
+
+	return resp, nil, nil
+}
+
+// acquireRequestSlot waits for a free global request slot and returns the
+// function that frees it
+func (s *RiotService) acquireRequestSlot(ctx context.Context) (func(), error) {
+	if s.requestSlots == nil {
+		return func() {}, nil
 	}
 
-	return resp, nil
+	select {
+	case s.requestSlots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	var once sync.Once
+	return func() { once.Do(func() { <-s.requestSlots }) }, nil
+}
+
+// slotReleasingBody frees a request slot when the response body is closed
+type slotReleasingBody struct {
+	io.ReadCloser
+	release func()
+}
+
+func (b *slotReleasingBody) Close() error {
+	defer b.release()
+	return b.ReadCloser.Close()
 }
 
 // GetAccountByRiotID gets account information by Riot ID (name#tag)
@@ -369,6 +599,12 @@ func (s *RiotService) GetSummonerByPUUID(ctx context.Context, region, puuid stri
 		return nil, err
 	}
 
+	// Riot bumps the revision date when the summoner finishes a game, so a
+	// match list cached before it is missing that game
+	if summoner.RevisionDate > 0 {
+		s.matchLists.invalidate(puuid, time.UnixMilli(summoner.RevisionDate))
+	}
+
 	return &summoner, nil
 }
 
@@ -390,9 +626,63 @@ func (s *RiotService) GetLeagueEntries(ctx context.Context, region, summonerID s
 	return entries, nil
 }
 
-// GetMatchHistory gets match history for a player
-func (s *RiotService) GetMatchHistory(ctx context.Context, region, puuid string, count int) (*MatchHistory, error) {
-	endpoint := fmt.Sprintf("/lol/match/v5/matches/by-puuid/%s/ids?count=%d", puuid, count)
+// Match list paging limits
+const (
+	// RiotMatchIDsPageSize is the most match IDs Riot returns per request
+	RiotMatchIDsPageSize = 100
+	// DefaultMaxSyncMatches is used when a user has no sync depth preference
+	DefaultMaxSyncMatches = 20
+)
+
+// MaxGameCount returns the most matches a single sync may request
+func (s *RiotService) MaxGameCount() int {
+	if s.config.Sync.MaxGameCount > 0 {
+		return s.config.Sync.MaxGameCount
+	}
+	return config.DefaultMaxGameCount
+}
+
+// GetMatchHistory gets match history for a player, paging through Riot's
+// match list when more than RiotMatchIDsPageSize matches are requested. The
+// list is cached for Riot.MatchListCacheTTL so rapid repeated syncs reuse it.
+// The filter is applied by Riot, so count is the number of matching games.
+func (s *RiotService) GetMatchHistory(ctx context.Context, region, puuid string, count int, filter MatchListFilter) (*MatchHistory, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	ttl := s.config.Riot.MatchListCacheTTL
+	if cached, ok := s.matchLists.get(region, puuid, filter, count, ttl); ok {
+		return &MatchHistory{MatchIDs: cached}, nil
+	}
+
+	matchIDs := make([]string, 0, count)
+
+	for start := 0; start < count; start += RiotMatchIDsPageSize {
+		pageSize := count - start
+		if pageSize > RiotMatchIDsPageSize {
+			pageSize = RiotMatchIDsPageSize
+		}
+
+		page, err := s.getMatchIDsPage(ctx, region, puuid, start, pageSize, filter)
+		if err != nil {
+			return nil, err
+		}
+
+		matchIDs = append(matchIDs, page...)
+
+		// A short page means the player has no older matches
+		if len(page) < pageSize {
+			break
+		}
+	}
+
+	s.matchLists.put(region, puuid, filter, count, matchIDs, ttl)
+	return &MatchHistory{MatchIDs: matchIDs}, nil
+}
+
+func (s *RiotService) getMatchIDsPage(ctx context.Context, region, puuid string, start, count int, filter MatchListFilter) ([]string, error) {
+	endpoint := fmt.Sprintf("/lol/match/v5/matches/by-puuid/%s/ids?start=%d&count=%d%s", puuid, start, count, filter.query())
 
 	resp, err := s.makeAPIRequest(ctx, region, endpoint)
 	if err != nil {
@@ -405,7 +695,7 @@ func (s *RiotService) GetMatchHistory(ctx context.Context, region, puuid string,
 		return nil, err
 	}
 
-	return &MatchHistory{MatchIDs: matchIDs}, nil
+	return matchIDs, nil
 }
 
 // GetMatchDetails gets detailed information about a match
@@ -418,14 +708,254 @@ func (s *RiotService) GetMatchDetails(ctx context.Context, region, matchID strin
 	}
 	defer resp.Body.Close()
 
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
 	var match MatchDetails
-	if err := json.NewDecoder(resp.Body).Decode(&match); err != nil {
+	if err := json.Unmarshal(body, &match); err != nil {
 		return nil, err
 	}
+	match.Raw = body
 
 	return &match, nil
 }
 
+// RefreshQueues downloads Riot's queues.json so queue IDs missing from the
+// built-in names still resolve to a readable description. The copy on disk
+// is loaded when the download fails, and the list in memory is kept when
+// there is none.
+func (s *RiotService) RefreshQueues(ctx context.Context) error {
+	body, err := s.fetchStatic(ctx, "queues.json", models.QueuesURL)
+	if err != nil {
+		return fmt.Errorf("failed to download queues: %w", err)
+	}
+
+	return models.LoadQueues(body)
+}
+
+// RefreshPatch downloads DataDragon's version list and records the newest
+// version as the current patch, see models.CurrentPatch. The copy on disk is
+// used when the download fails.
+func (s *RiotService) RefreshPatch(ctx context.Context) (models.PatchInfo, error) {
+	body, err := s.fetchStatic(ctx, "versions.json", models.DataDragonVersionsURL)
+	if err != nil {
+		return models.PatchInfo{}, fmt.Errorf("failed to download DataDragon versions: %w", err)
+	}
+
+	return models.LoadDataDragonVersions(body)
+}
+
+// RefreshChampionNames refreshes the current patch, then downloads its
+// champion list from DataDragon so internal champion names of new releases map
+// to their display names. When DataDragon is unreachable the last champion
+// list on disk is loaded, and the built-in names are kept when there is none.
+func (s *RiotService) RefreshChampionNames(ctx context.Context) error {
+	patch, err := s.RefreshPatch(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := s.fetchStatic(ctx, "champion.json", fmt.Sprintf(models.DataDragonChampionsURL, patch.DataDragonVersion))
+	if err != nil {
+		return fmt.Errorf("failed to download champions: %w", err)
+	}
+
+	count, err := models.LoadChampionNames(body)
+	if err != nil {
+		return err
+	}
+	logger.Debugf("Loaded %d champion names from DataDragon %s", count, patch.DataDragonVersion)
+	return nil
+}
+
+// RefreshItemNames downloads DataDragon's item list for the patch of each
+// game version that isn't cached yet, so exports and match details can show
+// item names next to IDs. Without versions it refreshes the current patch.
+// Items of a patch that can't be downloaded or found on disk are named from
+// the newest cached patch instead, and the patch is not tried again for
+// itemNamesRetryAfter.
+func (s *RiotService) RefreshItemNames(ctx context.Context, gameVersions ...string) error {
+	if len(gameVersions) == 0 {
+		patch, ok := models.CurrentPatch()
+		if !ok {
+			return fmt.Errorf("current patch is not known yet")
+		}
+		gameVersions = []string{patch.DataDragonVersion}
+	}
+
+	var errs []error
+	seen := make(map[string]bool)
+	for _, version := range gameVersions {
+		patch := models.PatchFromVersion(version)
+		if patch == "" || seen[patch] || models.HasItemNames(patch) {
+			continue
+		}
+		seen[patch] = true
+		if s.itemNamesFailedRecently(patch) {
+			logger.Debugf("Skipping item names for %s, the last download failed", patch)
+			continue
+		}
+
+		// DataDragon publishes every patch as <patch>.1
+		ddragonVersion := patch + ".1"
+		if current, ok := models.CurrentPatch(); ok && current.Patch == patch {
+			ddragonVersion = current.DataDragonVersion
+		}
+
+		body, err := s.fetchStatic(ctx, "item-"+ddragonVersion+".json", fmt.Sprintf(models.DataDragonItemsURL, ddragonVersion))
+		if err != nil {
+			// A cancelled request says nothing about DataDragon
+			if ctx.Err() == nil {
+				s.recordItemNamesResult(patch, false)
+			}
+			errs = append(errs, fmt.Errorf("failed to download items for %s: %w", patch, err))
+			continue
+		}
+		count, err := models.LoadItemNames(ddragonVersion, body)
+		if err != nil {
+			s.recordItemNamesResult(patch, false)
+			errs = append(errs, err)
+			continue
+		}
+		s.recordItemNamesResult(patch, true)
+		logger.Debugf("Loaded %d item names from DataDragon %s", count, ddragonVersion)
+	}
+	return errors.Join(errs...)
+}
+
+// itemNamesFailedRecently reports whether the patch's item list failed to
+// load within itemNamesRetryAfter
+func (s *RiotService) itemNamesFailedRecently(patch string) bool {
+	s.itemFailuresMu.Lock()
+	defer s.itemFailuresMu.Unlock()
+	failedAt, ok := s.itemFailures[patch]
+	return ok && time.Since(failedAt) < itemNamesRetryAfter
+}
+
+// recordItemNamesResult remembers a failed item list download, or forgets it
+// once the patch loads
+func (s *RiotService) recordItemNamesResult(patch string, loaded bool) {
+	s.itemFailuresMu.Lock()
+	defer s.itemFailuresMu.Unlock()
+	if loaded {
+		delete(s.itemFailures, patch)
+		return
+	}
+	if s.itemFailures == nil {
+		s.itemFailures = make(map[string]time.Time)
+	}
+	s.itemFailures[patch] = time.Now()
+}
+
+// ResolveItemNames names every participant's items as of the match's patch,
+// downloading the patch's item list when it isn't cached
+func (s *RiotService) ResolveItemNames(ctx context.Context, match *MatchDetails) {
+	if err := s.RefreshItemNames(ctx, match.Info.GameVersion); err != nil {
+		logger.Warnf("Item names for %s may be incomplete: %v", match.Metadata.MatchID, err)
+	}
+	for i := range match.Info.Participants {
+		p := &match.Info.Participants[i]
+		p.ItemNames = models.ItemNames(match.Info.GameVersion, []int{p.Item0, p.Item1, p.Item2, p.Item3, p.Item4, p.Item5, p.Item6})
+	}
+}
+
+// NormalizeChampionNames rewrites every participant's champion to its display
+// name, or to Riot's internal name when internal is set
+func (s *RiotService) NormalizeChampionNames(match *MatchDetails, internal bool) {
+	for i := range match.Info.Participants {
+		p := &match.Info.Participants[i]
+		p.ChampionName = models.ChampionName(p.ChampionName, internal)
+	}
+}
+
+// downloadStatic fetches a static data file outside the Riot API, so no API
+// key, rate limit or request slot applies
+func (s *RiotService) downloadStatic(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// TestAPIKey verifies the configured API key by looking up the configured test account
+func (s *RiotService) TestAPIKey(ctx context.Context) (*RiotAccount, error) {
+	return s.GetAccountByRiotID(ctx, s.config.Riot.TestRegion, s.config.Riot.TestGameName, s.config.Riot.TestTagLine)
+}
+
+// RiotAccountValidationRequest identifies a Riot account to validate
+type RiotAccountValidationRequest struct {
+	RiotID  string `json:"riot_id" binding:"required"`
+	RiotTag string `json:"riot_tag" binding:"required"`
+	Region  string `json:"region" binding:"required"`
+}
+
+// RiotAccountValidationResult reports whether a single account exists on Riot's side
+type RiotAccountValidationResult struct {
+	RiotID  string `json:"riot_id"`
+	RiotTag string `json:"riot_tag"`
+	Region  string `json:"region"`
+	Valid   bool   `json:"valid"`
+	PUUID   string `json:"puuid,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// ValidateRiotAccounts checks a list of Riot IDs concurrently, running at most
+// concurrency lookups at once. Results keep the order of the input and no
+// account is linked or stored.
+func (s *RiotService) ValidateRiotAccounts(ctx context.Context, accounts []RiotAccountValidationRequest, concurrency int) []RiotAccountValidationResult {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	results := make([]RiotAccountValidationResult, len(accounts))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, concurrency)
+
+	for i, account := range accounts {
+		wg.Add(1)
+		go func(idx int, req RiotAccountValidationRequest) {
+			defer wg.Done()
+			semaphore <- struct{}{}        // Acquire semaphore
+			defer func() { <-semaphore }() // Release semaphore
+
+			result := RiotAccountValidationResult{
+				RiotID:  req.RiotID,
+				RiotTag: req.RiotTag,
+				Region:  req.Region,
+			}
+
+			riotAccount, err := s.GetAccountByRiotID(ctx, req.Region, req.RiotID, req.RiotTag)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Valid = true
+				result.PUUID = riotAccount.PUUID
+			}
+
+			results[idx] = result
+		}(i, account)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
 // LinkRiotAccount links a Riot account to a user
 func (s *RiotService) LinkRiotAccount(ctx context.Context, userID string, region, gameName, tagLine string) (*models.RiotAccount, error) {
 	// Get account from Riot API
@@ -490,21 +1020,68 @@ func (s *RiotService) LinkRiotAccount(ctx context.Context, userID string, region
 	return &account, nil
 }
 
-// SyncMatchHistory syncs recent matches for a user
-func (s *RiotService) SyncMatchHistory(ctx context.Context, userID, riotAccountID string, count int) error {
+// SyncMatchHistory syncs recent matches for a user. A count of zero or less
+// uses the user's maxSyncMatches preference; an explicit count above
+// MaxGameCount is rejected with ErrGameCountExceeded. The filter restricts
+// the sync to a match type or queue before any match details are fetched,
+// so count is the number of matching games. Progress, including
+// rate limit retries, is reported through GetSyncStatus. Only one sync runs
+// per user at a time: while one is running, a SyncInProgressError carrying its
+// job ID is returned instead.
+func (s *RiotService) SyncMatchHistory(ctx context.Context, userID, riotAccountID string, count int, filter MatchListFilter) (err error) {
+	maxGames := s.MaxGameCount()
+	if count > maxGames {
+		return ErrGameCountExceeded
+	}
+	if err := filter.Validate(); err != nil {
+		return err
+	}
+
 	// Get riot account
 	var riotAccount models.RiotAccount
 	if err := s.db.Where("id = ? AND user_id = ?", riotAccountID, userID).First(&riotAccount).Error; err != nil {
 		return err
 	}
 
+	if count <= 0 {
+		count = s.maxSyncMatchesForUser(userID)
+	}
+	if count > maxGames {
+		count = maxGames
+	}
+
+	// Turn away a second sync of the same user before it touches the
+	// running sync's status
+	ctx, release, err := s.acquireSyncLock(ctx, userID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	saved := 0
+	ctx = s.startSyncStatus(ctx, userID, riotAccountID)
+	job := s.startSyncJob(ctx, userID, riotAccountID, count, filter)
+	if job != nil {
+		s.setSyncLockJob(userID, job.ID)
+	}
+	defer func() {
+		s.finishSyncStatus(ctx, userID, saved, err)
+		s.finishSyncJob(job, saved, err)
+	}()
+
 	// Get match history from Riot API
-	matchHistory, err := s.GetMatchHistory(ctx, riotAccount.Region, riotAccount.PUUID, count)
+	matchHistory, err := s.GetMatchHistory(ctx, riotAccount.Region, riotAccount.PUUID, count, filter)
 	if err != nil {
 		return err
 	}
 
-	// Process each match
+	// Process each match, writing fetched matches in batched transactions
+	batchSize := s.config.Database.SyncWriteBatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	pending := make([]*MatchDetails, 0, batchSize)
+
 	for _, matchID := range matchHistory.MatchIDs {
 		// Check if match already exists
 		var existingMatch models.Match
@@ -515,24 +1092,239 @@ func (s *RiotService) SyncMatchHistory(ctx context.Context, userID, riotAccountI
 		// Get match details
 		matchDetails, err := s.GetMatchDetails(ctx, riotAccount.Region, matchID)
 		if err != nil {
-			continue // Skip on error, don't fail entire sync
+			if errors.Is(err, ErrInvalidAPIKey) {
+				if n := s.saveMatchesToDatabase(userID, riotAccountID, pending); n > 0 {
+					saved += n
+					s.runSyncHooks(userID)
+				}
+				return err // Every remaining request would fail the same way
+			}
+			// Keep a stub so the gap is tracked and retried, don't fail entire sync
+			unlock := s.lockWrites()
+			s.recordIncompleteMatch(s.db, userID, riotAccountID, matchID, models.IncompleteDetailsUnavailable, err)
+			unlock()
+			continue
+		}
+		if !containsString(matchDetails.Metadata.Participants, riotAccount.PUUID) {
+			unlock := s.lockWrites()
+			s.recordIncompleteMatch(s.db, userID, riotAccountID, matchID, models.IncompletePlayerNotFound, nil)
+			unlock()
+			continue
 		}
 
-		// Save match to database
-		if err := s.saveMatchToDatabase(matchDetails); err != nil {
-			continue // Skip on error, don't fail entire sync
+		pending = append(pending, matchDetails)
+		if s.config.Sync.FetchTimelines {
+			s.recordTimeline(ctx, userID, riotAccount.Region, riotAccount.PUUID, matchDetails)
+		}
+		if len(pending) >= batchSize {
+			saved += s.saveMatchesToDatabase(userID, riotAccountID, pending)
+			pending = pending[:0]
 		}
 	}
+	saved += s.saveMatchesToDatabase(userID, riotAccountID, pending)
 
 	// Update last sync time
 	riotAccount.LastSyncAt = time.Now()
+	unlock := s.lockWrites()
 	s.db.Save(&riotAccount)
+	unlock()
+
+	logger.Debugf("Synced account %s: %d new of %d matches", riotAccountID, saved, len(matchHistory.MatchIDs))
+	if saved > 0 {
+		s.runSyncHooks(userID)
+	}
 
 	return nil
 }
 
-// saveMatchToDatabase saves match details to the database
-func (s *RiotService) saveMatchToDatabase(matchDetails *MatchDetails) error {
+// OnSyncComplete registers a hook run after a sync stores new matches for a
+// user. Hooks run synchronously at the end of the sync and should hand off
+// any slow work to a goroutine.
+func (s *RiotService) OnSyncComplete(hook func(userID string)) {
+	s.syncHooksMu.Lock()
+	defer s.syncHooksMu.Unlock()
+	s.syncHooks = append(s.syncHooks, hook)
+}
+
+func (s *RiotService) runSyncHooks(userID string) {
+	s.syncHooksMu.RLock()
+	defer s.syncHooksMu.RUnlock()
+	for _, hook := range s.syncHooks {
+		hook(userID)
+	}
+}
+
+// MatchHook is run with each match a sync stores and the user it was synced
+// for. match includes its participants.
+type MatchHook func(userID string, match *models.Match)
+
+// OnMatchStored registers a hook run after each match is committed, letting
+// self-hosters push stored matches elsewhere without forking. Register hooks
+// at startup. Hooks run synchronously on the sync and should hand off slow
+// work to a goroutine; a panicking hook is logged and does not fail the sync.
+func (s *RiotService) OnMatchStored(hook MatchHook) {
+	s.matchHooksMu.Lock()
+	defer s.matchHooksMu.Unlock()
+	s.matchHooks = append(s.matchHooks, hook)
+}
+
+func (s *RiotService) runMatchHooks(userID string, matches []*models.Match) {
+	s.matchHooksMu.RLock()
+	defer s.matchHooksMu.RUnlock()
+	for _, match := range matches {
+		for _, hook := range s.matchHooks {
+			runMatchHook(hook, userID, match)
+		}
+	}
+}
+
+func runMatchHook(hook MatchHook, userID string, match *models.Match) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Errorf("Match hook panicked for match %s: %v", match.MatchID, r)
+		}
+	}()
+	hook(userID, match)
+}
+
+// lockWrites serializes database writes when running on SQLite so concurrent
+// syncs queue behind each other instead of failing with "database is locked".
+// On other drivers it is a no-op.
+func (s *RiotService) lockWrites() func() {
+	if !s.config.IsSQLite() {
+		return func() {}
+	}
+	s.writeMu.Lock()
+	return s.writeMu.Unlock
+}
+
+// saveMatchesToDatabase stores a batch of matches in one transaction and
+// returns how many were saved. If the batch fails, matches are retried one by
+// one so a single bad match does not drop the others; individual failures are
+// logged and recorded as incomplete matches.
+func (s *RiotService) saveMatchesToDatabase(userID, riotAccountID string, batch []*MatchDetails) int {
+	if len(batch) == 0 {
+		return 0
+	}
+
+	stored := s.storeMatches(userID, riotAccountID, batch)
+
+	// Hooks only see committed matches, and run outside the write lock
+	s.runMatchHooks(userID, stored)
+	return len(stored)
+}
+
+// storeMatches writes the batch in one transaction, falling back to one
+// transaction per match when it fails, and returns the matches stored
+func (s *RiotService) storeMatches(userID, riotAccountID string, batch []*MatchDetails) []*models.Match {
+	unlock := s.lockWrites()
+	defer unlock()
+
+	var stored []*models.Match
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		for _, matchDetails := range batch {
+			match, err := s.createMatchRecords(tx, matchDetails)
+			if err != nil {
+				return err
+			}
+			stored = append(stored, match)
+		}
+		return nil
+	})
+	if err == nil {
+		return stored
+	}
+	if len(batch) == 1 {
+		logger.Warnf("Failed to save match %s: %v", batch[0].Metadata.MatchID, err)
+		s.recordIncompleteMatch(s.db, userID, riotAccountID, batch[0].Metadata.MatchID, models.IncompleteSaveFailed, err)
+		return nil
+	}
+
+	stored = nil
+	for _, matchDetails := range batch {
+		var match *models.Match
+		if err := s.db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			match, err = s.createMatchRecords(tx, matchDetails)
+			return err
+		}); err != nil {
+			logger.Warnf("Failed to save match %s: %v", matchDetails.Metadata.MatchID, err)
+			s.recordIncompleteMatch(s.db, userID, riotAccountID, matchDetails.Metadata.MatchID, models.IncompleteSaveFailed, err)
+			continue
+		}
+		stored = append(stored, match)
+	}
+
+	return stored
+}
+
+// recordIncompleteMatch stores or refreshes the stub for a match that could
+// not be saved. Callers must hold the write lock.
+func (s *RiotService) recordIncompleteMatch(db *gorm.DB, userID, riotAccountID, matchID, reason string, cause error) {
+	stub := models.IncompleteMatch{
+		UserID:        userID,
+		RiotAccountID: riotAccountID,
+		MatchID:       matchID,
+		Reason:        reason,
+		Attempts:      1,
+		LastAttemptAt: time.Now(),
+	}
+	if cause != nil {
+		stub.Detail = cause.Error()
+	}
+
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "riot_account_id"}, {Name: "match_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"reason":          stub.Reason,
+			"detail":          stub.Detail,
+			"attempts":        gorm.Expr("incomplete_matches.attempts + 1"),
+			"last_attempt_at": stub.LastAttemptAt,
+			"updated_at":      stub.LastAttemptAt,
+		}),
+	}).Create(&stub).Error
+	if err != nil {
+		logger.Warnf("Failed to record incomplete match %s: %v", matchID, err)
+	}
+}
+
+// CountIncompleteMatches returns how many matches in the user's history are
+// missing because they could not be fetched or stored
+func (s *RiotService) CountIncompleteMatches(ctx context.Context, userID string) (int64, error) {
+	var count int64
+	err := s.db.WithContext(ctx).Model(&models.IncompleteMatch{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+// ListIncompleteMatches returns the user's incomplete match stubs, most
+// recently attempted first
+func (s *RiotService) ListIncompleteMatches(ctx context.Context, userID string) ([]models.IncompleteMatch, error) {
+	var stubs []models.IncompleteMatch
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).Order("last_attempt_at DESC").Find(&stubs).Error
+	return stubs, err
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
+
+// maxSyncMatchesForUser returns the user's preferred sync depth
+func (s *RiotService) maxSyncMatchesForUser(userID string) int {
+	var prefs models.UserPreferences
+	if err := s.db.Where("user_id = ?", userID).First(&prefs).Error; err != nil || prefs.MaxSyncMatches <= 0 {
+		return DefaultMaxSyncMatches
+	}
+	return prefs.MaxSyncMatches
+}
+
+// createMatchRecords saves a match and its participants within tx and returns
+// the stored match with its participants
+func (s *RiotService) createMatchRecords(tx *gorm.DB, matchDetails *MatchDetails) (*models.Match, error) {
 	// Create match record
 	match := models.Match{
 		MatchID:            matchDetails.Metadata.MatchID,
@@ -548,6 +1340,7 @@ func (s *RiotService) saveMatchToDatabase(matchDetails *MatchDetails) error {
 		GameVersion:        matchDetails.Info.GameVersion,
 		IsProcessed:        false,
 		IsAnalyzed:         false,
+		RawData:            string(matchDetails.Raw),
 	}
 
 	// Determine winning team
@@ -558,64 +1351,64 @@ func (s *RiotService) saveMatchToDatabase(matchDetails *MatchDetails) error {
 		}
 	}
 
-	// Start transaction
-	tx := s.db.Begin()
-
 	// Save match
 	if err := tx.Create(&match).Error; err != nil {
-		tx.Rollback()
-		return err
+		return nil, err
 	}
 
 	// Save participants
 	for _, p := range matchDetails.Info.Participants {
 		participant := models.MatchParticipant{
-			MatchID:                     match.ID,
-			PUUID:                       p.PUUID,
-			SummonerName:                p.SummonerName,
-			SummonerID:                  p.SummonerID,
-			ParticipantID:               p.ParticipantID,
-			TeamID:                      p.TeamID,
-			TeamPosition:                p.TeamPosition,
-			ChampionID:                  p.ChampionID,
-			ChampionName:                p.ChampionName,
-			Spell1ID:                    p.Summoner1ID,
-			Spell2ID:                    p.Summoner2ID,
-			Kills:                       p.Kills,
-			Deaths:                      p.Deaths,
-			Assists:                     p.Assists,
-			ChampionLevel:               p.ChampLevel,
-			Won:                         p.Win,
-			TotalDamageDealt:            p.TotalDamageDealt,
-			TotalDamageDealtToChampions: p.TotalDamageDealtToChampions,
-			TotalDamageTaken:            p.TotalDamageTaken,
-			TotalHeal:                   p.TotalHeal,
-			TotalHealsOnTeammates:       p.TotalHealsOnTeammates,
-			DamageDealtToObjectives:     p.DamageDealtToObjectives,
-			DamageDealtToTurrets:        p.DamageDealtToTurrets,
-			GoldEarned:                  p.GoldEarned,
-			GoldSpent:                   p.GoldSpent,
-			TotalCS:                     p.TotalMinionsKilled + p.NeutralMinionsKilled,
-			VisionScore:                 p.VisionScore,
-			WardsPlaced:                 p.WardsPlaced,
-			WardsKilled:                 p.WardsKilled,
-			ControlWardsPlaced:          p.DetectorWardsPlaced,
-			VisionWardsBoughtInGame:     p.VisionWardsBoughtInGame,
-			Item0:                       p.Item0,
-			Item1:                       p.Item1,
-			Item2:                       p.Item2,
-			Item3:                       p.Item3,
-			Item4:                       p.Item4,
-			Item5:                       p.Item5,
-			Item6:                       p.Item6,
-			TurretKills:                 p.TurretKills,
-			InhibitorKills:              p.InhibitorKills,
-			DragonKills:                 p.DragonKills,
-			BaronKills:                  p.BaronKills,
-			FirstBloodKill:              p.FirstBloodKill,
-			FirstBloodAssist:            p.FirstBloodAssist,
-			LargestKillingSpree:         p.LargestKillingSpree,
-			LargestMultiKill:            p.LargestMultiKill,
+			MatchID:                        match.ID,
+			PUUID:                          p.PUUID,
+			SummonerName:                   p.SummonerName,
+			SummonerID:                     p.SummonerID,
+			ParticipantID:                  p.ParticipantID,
+			TeamID:                         p.TeamID,
+			TeamPosition:                   p.TeamPosition,
+			ChampionID:                     p.ChampionID,
+			ChampionName:                   p.ChampionName,
+			Spell1ID:                       p.Summoner1ID,
+			Spell2ID:                       p.Summoner2ID,
+			Kills:                          p.Kills,
+			Deaths:                         p.Deaths,
+			Assists:                        p.Assists,
+			ChampionLevel:                  p.ChampLevel,
+			Won:                            p.Win,
+			GameEndedInEarlySurrender:      p.GameEndedInEarlySurrender,
+			TotalDamageDealt:               p.TotalDamageDealt,
+			TotalDamageDealtToChampions:    p.TotalDamageDealtToChampions,
+			TotalDamageTaken:               p.TotalDamageTaken,
+			TotalHeal:                      p.TotalHeal,
+			TotalHealsOnTeammates:          p.TotalHealsOnTeammates,
+			DamageDealtToObjectives:        p.DamageDealtToObjectives,
+			DamageDealtToTurrets:           p.DamageDealtToTurrets,
+			PhysicalDamageDealtToChampions: p.PhysicalDamageDealtToChampions,
+			MagicDamageDealtToChampions:    p.MagicDamageDealtToChampions,
+			TrueDamageDealtToChampions:     p.TrueDamageDealtToChampions,
+			GoldEarned:                     p.GoldEarned,
+			GoldSpent:                      p.GoldSpent,
+			TotalCS:                        p.TotalMinionsKilled + p.NeutralMinionsKilled,
+			VisionScore:                    p.VisionScore,
+			WardsPlaced:                    p.WardsPlaced,
+			WardsKilled:                    p.WardsKilled,
+			ControlWardsPlaced:             p.DetectorWardsPlaced,
+			VisionWardsBoughtInGame:        p.VisionWardsBoughtInGame,
+			Item0:                          p.Item0,
+			Item1:                          p.Item1,
+			Item2:                          p.Item2,
+			Item3:                          p.Item3,
+			Item4:                          p.Item4,
+			Item5:                          p.Item5,
+			Item6:                          p.Item6,
+			TurretKills:                    p.TurretKills,
+			InhibitorKills:                 p.InhibitorKills,
+			DragonKills:                    p.DragonKills,
+			BaronKills:                     p.BaronKills,
+			FirstBloodKill:                 p.FirstBloodKill,
+			FirstBloodAssist:               p.FirstBloodAssist,
+			LargestKillingSpree:            p.LargestKillingSpree,
+			LargestMultiKill:               p.LargestMultiKill,
 		}
 
 		// Calculate derived metrics
@@ -631,76 +1424,266 @@ func (s *RiotService) saveMatchToDatabase(matchDetails *MatchDetails) error {
 		}
 
 		if err := tx.Create(&participant).Error; err != nil {
-			tx.Rollback()
+			return nil, err
+		}
+		match.Participants = append(match.Participants, participant)
+	}
+
+	// Save bans; teams that skipped a ban report champion -1
+	for _, team := range matchDetails.Info.Teams {
+		for _, ban := range team.Bans {
+			if ban.ChampionID <= 0 {
+				continue
+			}
+			record := models.MatchBan{
+				MatchID:    match.ID,
+				TeamID:     team.TeamID,
+				ChampionID: ban.ChampionID,
+				PickTurn:   ban.PickTurn,
+			}
+			if err := tx.Create(&record).Error; err != nil {
+				return nil, err
+			}
+			match.Bans = append(match.Bans, record)
+		}
+	}
+
+	// Save the objectives each team secured
+	for _, team := range matchDetails.Info.Teams {
+		objectives := team.Objectives
+		record := models.MatchTeamObjectives{
+			MatchID:        match.ID,
+			TeamID:         team.TeamID,
+			FirstBlood:     objectives.Champion.First,
+			FirstTower:     objectives.Tower.First,
+			FirstDragon:    objectives.Dragon.First,
+			FirstHerald:    objectives.RiftHerald.First,
+			FirstBaron:     objectives.Baron.First,
+			FirstInhibitor: objectives.Inhibitor.First,
+			TowerKills:     objectives.Tower.Kills,
+			DragonKills:    objectives.Dragon.Kills,
+			HeraldKills:    objectives.RiftHerald.Kills,
+			BaronKills:     objectives.Baron.Kills,
+			InhibitorKills: objectives.Inhibitor.Kills,
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return nil, err
+		}
+		match.TeamObjectives = append(match.TeamObjectives, record)
+	}
+
+	// The match is now stored, so any earlier stub for it is resolved
+	if err := tx.Where("match_id = ?", match.MatchID).Delete(&models.IncompleteMatch{}).Error; err != nil {
+		return nil, err
+	}
+	return &match, nil
+}
+
+// Match archive formats
+const (
+	// FormatRawJSON exports the original Riot match-v5 responses
+	FormatRawJSON = "raw_json"
+	// FormatNormalizedJSON exports Herald's stored match records
+	FormatNormalizedJSON = "json"
+)
+
+// ExportMatchArchive writes a zip with one JSON file per synced match of the
+// user's linked accounts to w. With FormatRawJSON, matches fetched from Riot
+// are written as the unmodified API response; test or imported matches without
+// a stored response fall back to the normalized record. Normalized records use
+// champion display names ("Wukong") unless internalChampionNames is set, in
+// which case they keep Riot's internal names ("MonkeyKing"), and with
+// itemNames each participant's item IDs are accompanied by item names as of
+// the match's patch.
+//
+// Matches are loaded a page at a time and streamed to the writer through a
+// buffer of Riot.ExportBufferSize matches, so a large history is never held
+// in memory at once. Loading waits while running exports hold
+// Riot.ExportMaxInFlightRows matches between them. Nothing is written to w
+// before the first match is loaded.
+func (s *RiotService) ExportMatchArchive(ctx context.Context, w io.Writer, userID, format string, internalChampionNames, itemNames bool) error {
+	if format != FormatRawJSON && format != FormatNormalizedJSON {
+		return fmt.Errorf("unsupported archive format: %s", format)
+	}
+
+	var puuids []string
+	if err := s.db.WithContext(ctx).Model(&models.RiotAccount{}).
+		Where("user_id = ?", userID).Pluck("puuid", &puuids).Error; err != nil {
+		return err
+	}
+
+	pageSize := s.config.Riot.ExportBufferSize
+	if pageSize <= 0 {
+		pageSize = defaultExportBufferSize
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	matches := make(chan models.Match, pageSize)
+	loadErr := make(chan error, 1)
+	go func() {
+		defer close(matches)
+		if len(puuids) == 0 {
+			loadErr <- nil
+			return
+		}
+		loadErr <- s.loadExportMatches(ctx, puuids, pageSize, itemNames && format == FormatNormalizedJSON, matches)
+	}()
+
+	archive := zip.NewWriter(w)
+	for match := range matches {
+		err := writeArchiveMatch(archive, match, format, internalChampionNames, itemNames)
+		s.exportRows.release(1)
+		if err != nil {
+			cancel()
+			for range matches {
+				s.exportRows.release(1)
+			}
 			return err
 		}
 	}
+	if err := <-loadErr; err != nil {
+		return err
+	}
 
-	tx.Commit()
-	return nil
+	return archive.Close()
+}
+
+// defaultExportBufferSize is used when Riot.ExportBufferSize is unset
+const defaultExportBufferSize = 50
+
+// loadExportMatches sends the matches played on puuids to out, newest first,
+// loading pageSize at a time. Each page is reserved against the export row
+// budget before it is loaded; the consumer releases a row per match written.
+func (s *RiotService) loadExportMatches(ctx context.Context, puuids []string, pageSize int, resolveItems bool, out chan<- models.Match) error {
+	subQuery := s.db.Model(&models.MatchParticipant{}).Select("match_id").Where("puuid IN ?", puuids)
+
+	for offset := 0; ; offset += pageSize {
+		if err := s.exportRows.acquire(ctx, pageSize); err != nil {
+			return err
+		}
+
+		var page []models.Match
+		if err := s.db.WithContext(ctx).Preload("Participants").
+			Where("id IN (?)", subQuery).
+			Order("game_start_timestamp DESC, id").
+			Offset(offset).
+			Limit(pageSize).
+			Find(&page).Error; err != nil {
+			s.exportRows.release(pageSize)
+			return err
+		}
+		s.exportRows.release(pageSize - len(page))
+
+		if resolveItems && len(page) > 0 {
+			versions := make([]string, len(page))
+			for i, match := range page {
+				versions[i] = match.GameVersion
+			}
+			if err := s.RefreshItemNames(ctx, versions...); err != nil {
+				logger.Warnf("Exported item names may be incomplete: %v", err)
+			}
+		}
+
+		for i, match := range page {
+			select {
+			case out <- match:
+			case <-ctx.Done():
+				s.exportRows.release(len(page) - i)
+				return ctx.Err()
+			}
+		}
+
+		if len(page) < pageSize {
+			return nil
+		}
+	}
+}
+
+// writeArchiveMatch adds one match file to an export archive
+func writeArchiveMatch(archive *zip.Writer, match models.Match, format string, internalChampionNames, itemNames bool) error {
+	var data []byte
+	if format == FormatRawJSON && match.RawData != "" {
+		data = []byte(match.RawData)
+	} else {
+		for i := range match.Participants {
+			match.Participants[i].ChampionName = models.ChampionName(match.Participants[i].ChampionName, internalChampionNames)
+			if itemNames {
+				match.Participants[i].ResolveItemNames(match.GameVersion)
+			}
+		}
+		normalized, err := json.MarshalIndent(match, "", "  ")
+		if err != nil {
+			return err
+		}
+		data = normalized
+	}
+
+	file, err := archive.Create(match.MatchID + ".json")
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(data)
+	return err
 }
 
 // Helper functions
+
+// getRegionURL returns the API host for a platform (na1, euw1, ...) or
+// regional routing value (americas, europe, ...) from the routing table
 func (s *RiotService) getRegionURL(region string) string {
-	regionURLs := map[string]string{
-		"na1":  "https://na1.api.riotgames.com",
-		"euw1": "https://euw1.api.riotgames.com",
-		"eun1": "https://eun1.api.riotgames.com",
-		"kr":   "https://kr.api.riotgames.com",
-		"jp1":  "https://jp1.api.riotgames.com",
-		"br1":  "https://br1.api.riotgames.com",
-		"la1":  "https://la1.api.riotgames.com",
-		"la2":  "https://la2.api.riotgames.com",
-		"oc1":  "https://oc1.api.riotgames.com",
-		"tr1":  "https://tr1.api.riotgames.com",
-		"ru":   "https://ru.api.riotgames.com",
-		"ph2":  "https://ph2.api.riotgames.com",
-		"sg2":  "https://sg2.api.riotgames.com",
-		"th2":  "https://th2.api.riotgames.com",
-		"tw2":  "https://tw2.api.riotgames.com",
-		"vn2":  "https://vn2.api.riotgames.com",
-
-		// Regional routing values
-		"americas": "https://americas.api.riotgames.com",
-		"europe":   "https://europe.api.riotgames.com",
-		"asia":     "https://asia.api.riotgames.com",
-		"esports":  "https://esports.api.riotgames.com",
-	}
-
-	if url, exists := regionURLs[region]; exists {
-		return url
+	if s.config.Riot.IsSupportedRegion(region) || config.IsRegionalRoute(region) {
+		return fmt.Sprintf("https://%s.api.riotgames.com", region)
 	}
 
 	return "https://na1.api.riotgames.com" // Default
 }
 
 func (s *RiotService) getPlatformFromRegion(region string) string {
-	platformMap := map[string]string{
-		"na1":  "americas",
-		"br1":  "americas",
-		"la1":  "americas",
-		"la2":  "americas",
-		"oc1":  "americas",
-		"euw1": "europe",
-		"eun1": "europe",
-		"tr1":  "europe",
-		"ru":   "europe",
-		"kr":   "asia",
-		"jp1":  "asia",
-		"ph2":  "asia",
-		"sg2":  "asia",
-		"th2":  "asia",
-		"tw2":  "asia",
-		"vn2":  "asia",
-	}
-
-	if platform, exists := platformMap[region]; exists {
-		return platform
+	if route, exists := s.config.Riot.RegionalRoute(region); exists {
+		return route
 	}
 
 	return "americas" // Default
 }
 
+// SupportedRegions returns the platforms in the configured routing table
+func (s *RiotService) SupportedRegions() []string {
+	return s.config.Riot.SupportedRegions()
+}
+
+// IsSupportedRegion reports whether a platform is in the routing table
+func (s *RiotService) IsSupportedRegion(region string) bool {
+	return s.config.Riot.IsSupportedRegion(region)
+}
+
+// ResolveMatchRegion returns the platform for a match lookup. An explicit
+// region overrides the user's default and must be supported; otherwise the
+// user's primary linked account decides, then their most recently linked
+// account, then the configured default region.
+func (s *RiotService) ResolveMatchRegion(ctx context.Context, userID, region string) (string, error) {
+	if region = strings.ToLower(strings.TrimSpace(region)); region != "" {
+		if !s.IsSupportedRegion(region) {
+			return "", ErrRegionNotSupported
+		}
+		return region, nil
+	}
+
+	var account models.RiotAccount
+	err := s.db.WithContext(ctx).Where("user_id = ?", userID).
+		Order("is_primary DESC, created_at DESC").
+		First(&account).Error
+	if err == nil && s.IsSupportedRegion(account.Region) {
+		return account.Region, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	return s.config.Riot.DefaultRegion, nil
+}
+
 func parseUUID(s string) uuid.UUID {
 	id, _ := uuid.Parse(s)
 	return id
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// Share link limits
+const (
+	DefaultShareLinkTTL = 7 * 24 * time.Hour
+	MaxShareLinkTTL     = 90 * 24 * time.Hour
+	shareRecentMatches  = 20
+	shareTokenBytes     = 32
+)
+
+var (
+	ErrShareLinkNotFound = errors.New("share link not found")
+	ErrShareLinkInactive = errors.New("share link expired or revoked")
+	ErrProfileNotPublic  = errors.New("profile is not public")
+)
+
+// ShareService issues and resolves expiring read-only share links.
+// Share tokens are opaque random strings rather than JWTs, so they can never
+// be mistaken for an access token by AuthService.ValidateToken.
+type ShareService struct {
+	db     *gorm.DB
+	config *config.Config
+}
+
+// ShareLinkResponse is returned when a share link is created
+type ShareLinkResponse struct {
+	Link  models.ShareLink `json:"link"`
+	Token string           `json:"token"`
+}
+
+// SharedProfile is the read-only view served to share link holders
+type SharedProfile struct {
+	Username      string         `json:"username"`
+	DisplayName   string         `json:"display_name"`
+	Region        string         `json:"region"`
+	CurrentRank   string         `json:"current_rank"`
+	PeakRank      string         `json:"peak_rank"`
+	PreferredRole string         `json:"preferred_role"`
+	RecentMatches []SharedMatch  `json:"recent_matches"`
+	ExpiresAt     time.Time      `json:"expires_at"`
+	Summary       *SharedSummary `json:"summary"`
+}
+
+// SharedMatch is a public summary of one match
+type SharedMatch struct {
+	MatchID      string    `json:"match_id"`
+	ChampionName string    `json:"champion_name"`
+	Position     string    `json:"position"`
+	Kills        int       `json:"kills"`
+	Deaths       int       `json:"deaths"`
+	Assists      int       `json:"assists"`
+	TotalCS      int       `json:"total_cs"`
+	Won          bool      `json:"won"`
+	GameDuration int       `json:"game_duration"`
+	PlayedAt     time.Time `json:"played_at"`
+
+	// Grade is nil for remakes
+	Grade *MatchGrade `json:"grade,omitempty"`
+}
+
+// SharedSummary aggregates the shared matches
+type SharedSummary struct {
+	Games      int     `json:"games"`
+	Wins       int     `json:"wins"`
+	WinRate    float64 `json:"win_rate"`
+	AverageKDA float64 `json:"average_kda"`
+}
+
+func NewShareService(db *gorm.DB, config *config.Config) *ShareService {
+	return &ShareService{
+		db:     db,
+		config: config,
+	}
+}
+
+// CreateShareLink stores a new share link and returns its token. The token is
+// only returned here; the database keeps its hash.
+// A ttl of zero uses DefaultShareLinkTTL; longer values are capped at MaxShareLinkTTL.
+func (s *ShareService) CreateShareLink(userID string, ttl time.Duration) (*ShareLinkResponse, error) {
+	if ttl <= 0 {
+		ttl = DefaultShareLinkTTL
+	}
+	if ttl > MaxShareLinkTTL {
+		ttl = MaxShareLinkTTL
+	}
+
+	raw := make([]byte, shareTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	link := models.ShareLink{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: hashShareToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	if err := s.db.Create(&link).Error; err != nil {
+		return nil, err
+	}
+
+	return &ShareLinkResponse{Link: link, Token: token}, nil
+}
+
+// ListShareLinks returns the user's share links, newest first
+func (s *ShareService) ListShareLinks(userID string) ([]models.ShareLink, error) {
+	var links []models.ShareLink
+	err := s.db.Where("user_id = ?", userID).Order("created_at DESC").Find(&links).Error
+	return links, err
+}
+
+// RevokeShareLink immediately invalidates a share link owned by the user
+func (s *ShareService) RevokeShareLink(userID string, shareID uuid.UUID) error {
+	now := time.Now()
+	result := s.db.Model(&models.ShareLink{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", shareID, userID).
+		Update("revoked_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrShareLinkNotFound
+	}
+	return nil
+}
+
+// GetSharedProfile resolves a share token and returns the owner's public stats.
+// Tokens stop working as soon as the link is revoked or the owner turns off
+// their public profile, even before they expire.
+func (s *ShareService) GetSharedProfile(token string) (*SharedProfile, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	var link models.ShareLink
+	if err := s.db.Where("token_hash = ?", hashShareToken(token)).First(&link).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrShareLinkNotFound
+		}
+		return nil, err
+	}
+	if !link.IsActive(time.Now()) {
+		return nil, ErrShareLinkInactive
+	}
+
+	var prefs models.UserPreferences
+	if err := s.db.Where("user_id = ?", link.UserID).First(&prefs).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	} else if err == nil && !prefs.PublicProfile {
+		return nil, ErrProfileNotPublic
+	}
+
+	var user models.User
+	if err := s.db.First(&user, "id = ?", link.UserID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+
+	matches, err := s.recentMatches(link.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SharedProfile{
+		Username:      user.Username,
+		DisplayName:   user.DisplayName,
+		Region:        user.Region,
+		CurrentRank:   user.CurrentRank,
+		PeakRank:      user.PeakRank,
+		PreferredRole: user.PreferredRole,
+		RecentMatches: matches,
+		Summary:       summarizeSharedMatches(matches),
+		ExpiresAt:     link.ExpiresAt,
+	}, nil
+}
+
+// recentMatches loads the latest matches played on the user's linked accounts
+func (s *ShareService) recentMatches(userID string) ([]SharedMatch, error) {
+	var puuids []string
+	if err := s.db.Table("riot_accounts").Where("CAST(user_id AS TEXT) = ?", userID).Pluck("puuid", &puuids).Error; err != nil {
+		return nil, err
+	}
+	if len(puuids) == 0 {
+		return []SharedMatch{}, nil
+	}
+
+	var participants []models.MatchParticipant
+	err := s.db.Preload("Match.Participants").
+		Joins("JOIN matches ON matches.id = match_participants.match_id").
+		Where("match_participants.puuid IN ?", puuids).
+		Order("matches.game_start_timestamp DESC").
+		Limit(shareRecentMatches).
+		Find(&participants).Error
+	if err != nil {
+		return nil, err
+	}
+
+	thresholds := gradeThresholdsFromConfig(s.config)
+	matches := make([]SharedMatch, 0, len(participants))
+	for _, p := range participants {
+		matches = append(matches, SharedMatch{
+			MatchID:      p.Match.MatchID,
+			ChampionName: p.ChampionName,
+			Position:     p.TeamPosition,
+			Kills:        p.Kills,
+			Deaths:       p.Deaths,
+			Assists:      p.Assists,
+			TotalCS:      p.TotalCS,
+			Won:          p.Won,
+			GameDuration: p.Match.GameDuration,
+			PlayedAt:     time.UnixMilli(p.Match.GameStartTimestamp),
+			Grade:        gradeStoredMatch(p, thresholds),
+		})
+	}
+
+	return matches, nil
+}
+
+// hashShareToken returns the hex SHA-256 of a share token, as stored in share_links
+func hashShareToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func summarizeSharedMatches(matches []SharedMatch) *SharedSummary {
+	summary := &SharedSummary{Games: len(matches)}
+	if len(matches) == 0 {
+		return summary
+	}
+
+	var kdaTotal float64
+	for _, m := range matches {
+		if m.Won {
+			summary.Wins++
+		}
+		deaths := m.Deaths
+		if deaths == 0 {
+			deaths = 1
+		}
+		kdaTotal += float64(m.Kills+m.Assists) / float64(deaths)
+	}
+
+	summary.WinRate = float64(summary.Wins) / float64(len(matches)) * 100
+	summary.AverageKDA = kdaTotal / float64(len(matches))
+
+	return summary
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/herald-lol/herald/backend/internal/logger"
+)
+
+// fetchStatic downloads a static data file and keeps a copy under
+// Riot.StaticDataCacheDir. When the download fails, the last copy on disk is
+// returned instead so queue, champion and item names keep resolving while
+// DataDragon is unreachable; the error is returned only when there is no
+// copy either.
+func (s *RiotService) fetchStatic(ctx context.Context, name, url string) ([]byte, error) {
+	body, err := s.downloadStatic(ctx, url)
+	if err == nil {
+		s.storeStatic(name, body)
+		return body, nil
+	}
+
+	cached, cacheErr := s.loadStatic(name)
+	if cacheErr != nil {
+		return nil, err
+	}
+	logger.Warnf("Failed to download %s, using the cached copy: %v", name, err)
+	return cached, nil
+}
+
+// storeStatic writes a downloaded file to the cache directory, replacing the
+// previous copy atomically so a crash never leaves a truncated file behind
+func (s *RiotService) storeStatic(name string, body []byte) {
+	dir := s.config.Riot.StaticDataCacheDir
+	if dir == "" {
+		return
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		logger.Warnf("Failed to create static data cache %s: %v", dir, err)
+		return
+	}
+
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		logger.Warnf("Failed to cache %s: %v", name, err)
+		return
+	}
+	_, writeErr := tmp.Write(body)
+	closeErr := tmp.Close()
+	if writeErr == nil {
+		writeErr = closeErr
+	}
+	if writeErr == nil {
+		writeErr = os.Rename(tmp.Name(), filepath.Join(dir, name))
+	}
+	if writeErr != nil {
+		os.Remove(tmp.Name())
+		logger.Warnf("Failed to cache %s: %v", name, writeErr)
+	}
+}
+
+func (s *RiotService) loadStatic(name string) ([]byte, error) {
+	dir := s.config.Riot.StaticDataCacheDir
+	if dir == "" {
+		return nil, fmt.Errorf("static data cache is disabled")
+	}
+	return os.ReadFile(filepath.Join(dir, name))
+}
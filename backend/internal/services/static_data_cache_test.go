@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+)
+
+func TestFetchStaticFallsBackToDisk(t *testing.T) {
+	up := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`[{"queueId": 420}]`))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{}
+	cfg.Riot.StaticDataCacheDir = t.TempDir()
+	s := &RiotService{config: cfg, httpClient: server.Client()}
+	ctx := context.Background()
+
+	body, err := s.fetchStatic(ctx, "queues.json", server.URL)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"queueId": 420}]`, string(body))
+
+	up = false
+	body, err = s.fetchStatic(ctx, "queues.json", server.URL)
+	require.NoError(t, err, "the cached copy is served while the download fails")
+	assert.JSONEq(t, `[{"queueId": 420}]`, string(body))
+
+	_, err = s.fetchStatic(ctx, "champion.json", server.URL)
+	assert.Error(t, err, "files never downloaded have no fallback")
+
+	cfg.Riot.StaticDataCacheDir = ""
+	_, err = s.fetchStatic(ctx, "queues.json", server.URL)
+	assert.Error(t, err, "an empty cache directory disables the fallback")
+}
+
+func TestRefreshItemNamesSkipsRecentFailures(t *testing.T) {
+	downloads := 0
+	s := &RiotService{config: &config.Config{}, httpClient: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		downloads++
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody, Request: r}, nil
+	})}}
+	ctx := context.Background()
+
+	assert.Error(t, s.RefreshItemNames(ctx, "99.1.512.4096"))
+	assert.NoError(t, s.RefreshItemNames(ctx, "99.1.512.4096", "99.1.600.1000"), "a patch that just failed is skipped")
+	assert.Equal(t, 1, downloads)
+
+	s.itemFailures["99.1"] = time.Now().Add(-itemNamesRetryAfter)
+	assert.Error(t, s.RefreshItemNames(ctx, "99.1.512.4096"))
+	assert.Equal(t, 2, downloads, "the download is retried once the failure expires")
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	assert.Error(t, s.RefreshItemNames(cancelled, "99.2.1.1"))
+	_, failed := s.itemFailures["99.2"]
+	assert.False(t, failed, "a cancelled download is not remembered as a failure")
+}
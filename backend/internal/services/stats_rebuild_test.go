@@ -0,0 +1,70 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRebuildPlayerStatsSQLite(t *testing.T) {
+	db := newAccountTestDB(t)
+
+	playerID := "puuid-1"
+	now := time.Now()
+	games := []struct {
+		champion   string
+		championID int
+		won        bool
+		daysAgo    int
+	}{
+		{"Jinx", 222, true, 1},
+		{"Jinx", 222, false, 2},
+		{"Caitlyn", 51, true, 40},
+	}
+	for i, game := range games {
+		matchID := "EUW1_" + string(rune('1'+i))
+		_, err := db.Exec(`INSERT INTO matches VALUES ($1, $2, $3, $4)`,
+			matchID, matchID, now.AddDate(0, 0, -game.daysAgo).UnixMilli(), 1800)
+		require.NoError(t, err)
+		_, err = db.Exec(`INSERT INTO match_participants (match_id, puuid, champion_id, champion_name, team_position, won,
+			kills, deaths, assists, total_cs, cs_per_minute, vision_score, damage_share, gold_earned)
+			VALUES ($1, $2, $3, $4, 'BOTTOM', $5, 5, 2, 7, 240, 8.0, 20, 0.25, 12000)`,
+			matchID, playerID, game.championID, game.champion, game.won)
+		require.NoError(t, err)
+	}
+
+	// Stale rows the rebuild must replace
+	_, err := db.Exec(`INSERT INTO champion_stats (player_id, champion_id, champion_name, total_matches) VALUES ($1, 99, 'Stale', 50)`, playerID)
+	require.NoError(t, err)
+
+	as := NewAnalyticsService(db, nil)
+	processed, err := as.rebuildPlayerStats(context.Background(), playerID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, processed)
+
+	var ranges []string
+	rows, err := db.Query(`SELECT time_range FROM player_stats WHERE player_id = $1 ORDER BY time_range`, playerID)
+	require.NoError(t, err)
+	for rows.Next() {
+		var timeRange string
+		require.NoError(t, rows.Scan(&timeRange))
+		ranges = append(ranges, timeRange)
+	}
+	require.NoError(t, rows.Close())
+	assert.Equal(t, []string{"30d", "7d", "90d"}, ranges)
+
+	champions := make(map[string]int)
+	rows, err = db.Query(`SELECT champion_name, total_matches FROM champion_stats WHERE player_id = $1`, playerID)
+	require.NoError(t, err)
+	for rows.Next() {
+		var name string
+		var games int
+		require.NoError(t, rows.Scan(&name, &games))
+		champions[name] = games
+	}
+	require.NoError(t, rows.Close())
+	assert.Equal(t, map[string]int{"Jinx": 2, "Caitlyn": 1}, champions)
+}
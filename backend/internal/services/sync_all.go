@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// syncAllConcurrency bounds how many of a user's accounts sync at once. Every
+// sync goes through the shared per-region rate limiters, so this only limits
+// local work, not Riot API usage.
+const syncAllConcurrency = 3
+
+// bulkSyncRetention is how long a finished bulk sync stays available to poll
+const bulkSyncRetention = time.Hour
+
+// ErrNoLinkedAccounts is returned when a user has no Riot accounts to sync
+var ErrNoLinkedAccounts = errors.New("no linked riot accounts")
+
+// BulkSyncResult is the progress, and once finished the combined outcome, of
+// syncing all of a user's accounts
+type BulkSyncResult struct {
+	ID         uuid.UUID           `json:"id"`
+	UserID     string              `json:"user_id"`
+	State      string              `json:"state"` // "running" or "completed"
+	StartedAt  time.Time           `json:"started_at"`
+	FinishedAt *time.Time          `json:"finished_at,omitempty"`
+	Accounts   int                 `json:"accounts"`
+	Synced     int                 `json:"synced"`
+	Failed     int                 `json:"failed"`
+	Results    []AccountSyncResult `json:"results"`
+}
+
+// AccountSyncResult is the outcome of syncing one linked account. Done is
+// false while the account is waiting or syncing.
+type AccountSyncResult struct {
+	RiotAccountID string `json:"riot_account_id"`
+	SummonerName  string `json:"summoner_name"`
+	TagLine       string `json:"tag_line"`
+	Region        string `json:"region"`
+	Done          bool   `json:"done"`
+	Success       bool   `json:"success"`
+	Error         string `json:"error,omitempty"`
+}
+
+// snapshot copies the result so it can be read while the sync updates it
+func (r *BulkSyncResult) snapshot() *BulkSyncResult {
+	snapshot := *r
+	snapshot.Results = append([]AccountSyncResult(nil), r.Results...)
+	return &snapshot
+}
+
+// SyncAllAccounts starts syncing every Riot account linked to the user in the
+// background and returns the running bulk sync; poll it with GetBulkSync.
+// count and filter have the same meaning as in SyncMatchHistory. Results keep
+// the order the accounts were linked in; one account failing does not stop
+// the others. While it runs, GetSyncStatus reports the most recently started
+// account.
+func (s *RiotService) SyncAllAccounts(ctx context.Context, userID string, count int, filter MatchListFilter) (*BulkSyncResult, error) {
+	if count > s.MaxGameCount() {
+		return nil, ErrGameCountExceeded
+	}
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
+	var accounts []struct {
+		ID           string
+		SummonerName string
+		TagLine      string
+		Region       string
+	}
+	err := s.db.WithContext(ctx).
+		Table("riot_accounts").
+		Select("id, summoner_name, tag_line, region").
+		Where("CAST(user_id AS TEXT) = ?", userID).
+		Order("created_at ASC").
+		Scan(&accounts).Error
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, ErrNoLinkedAccounts
+	}
+
+	// The accounts sync concurrently under the user's single sync lock. The
+	// sync outlives the request that started it.
+	syncCtx, release, err := s.acquireSyncLock(context.WithoutCancel(ctx), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BulkSyncResult{
+		ID:        uuid.New(),
+		UserID:    userID,
+		State:     SyncStateRunning,
+		StartedAt: time.Now(),
+		Accounts:  len(accounts),
+		Results:   make([]AccountSyncResult, len(accounts)),
+	}
+	for i, account := range accounts {
+		result.Results[i] = AccountSyncResult{
+			RiotAccountID: account.ID,
+			SummonerName:  account.SummonerName,
+			TagLine:       account.TagLine,
+			Region:        account.Region,
+		}
+	}
+	s.storeBulkSync(result)
+	started := s.snapshotBulkSync(result)
+
+	go func() {
+		defer release()
+
+		var wg sync.WaitGroup
+		semaphore := make(chan struct{}, syncAllConcurrency)
+
+		for i := range result.Results {
+			wg.Add(1)
+			go func(idx int, accountID string) {
+				defer wg.Done()
+				semaphore <- struct{}{}        // Acquire semaphore
+				defer func() { <-semaphore }() // Release semaphore
+
+				err := s.SyncMatchHistory(syncCtx, userID, accountID, count, filter)
+				s.finishBulkSyncAccount(result, idx, err)
+			}(i, result.Results[i].RiotAccountID)
+		}
+
+		wg.Wait()
+
+		s.bulkSyncsMu.Lock()
+		finished := time.Now()
+		result.FinishedAt = &finished
+		result.State = SyncStateCompleted
+		s.bulkSyncsMu.Unlock()
+	}()
+
+	return started, nil
+}
+
+// GetBulkSync returns the user's bulk sync with the given ID, if it is
+// running or finished within bulkSyncRetention
+func (s *RiotService) GetBulkSync(userID, id string) (*BulkSyncResult, bool) {
+	syncID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, false
+	}
+
+	s.bulkSyncsMu.RLock()
+	defer s.bulkSyncsMu.RUnlock()
+
+	result, ok := s.bulkSyncs[syncID]
+	if !ok || result.UserID != userID {
+		return nil, false
+	}
+	return result.snapshot(), true
+}
+
+// storeBulkSync registers a new bulk sync and drops finished ones past
+// bulkSyncRetention
+func (s *RiotService) storeBulkSync(result *BulkSyncResult) {
+	s.bulkSyncsMu.Lock()
+	defer s.bulkSyncsMu.Unlock()
+
+	for id, old := range s.bulkSyncs {
+		if old.FinishedAt != nil && time.Since(*old.FinishedAt) > bulkSyncRetention {
+			delete(s.bulkSyncs, id)
+		}
+	}
+	s.bulkSyncs[result.ID] = result
+}
+
+func (s *RiotService) snapshotBulkSync(result *BulkSyncResult) *BulkSyncResult {
+	s.bulkSyncsMu.RLock()
+	defer s.bulkSyncsMu.RUnlock()
+	return result.snapshot()
+}
+
+// finishBulkSyncAccount records the outcome of one account's sync
+func (s *RiotService) finishBulkSyncAccount(result *BulkSyncResult, idx int, err error) {
+	s.bulkSyncsMu.Lock()
+	defer s.bulkSyncsMu.Unlock()
+
+	accountResult := &result.Results[idx]
+	accountResult.Done = true
+	if err != nil {
+		accountResult.Error = err.Error()
+		result.Failed++
+		return
+	}
+	accountResult.Success = true
+	result.Synced++
+}
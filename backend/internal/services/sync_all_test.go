@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+)
+
+func newBulkSyncTestService(t *testing.T) *RiotService {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE riot_accounts (id TEXT, user_id TEXT, summoner_name TEXT, tag_line TEXT,
+		region TEXT, created_at DATETIME)`).Error)
+
+	return &RiotService{
+		db:        db,
+		config:    &config.Config{},
+		syncLocks: make(map[string]*syncLock),
+		bulkSyncs: make(map[uuid.UUID]*BulkSyncResult),
+	}
+}
+
+func TestSyncAllAccountsWithoutStarting(t *testing.T) {
+	s := newBulkSyncTestService(t)
+
+	_, err := s.SyncAllAccounts(context.Background(), "user", 0, MatchListFilter{})
+	assert.ErrorIs(t, err, ErrNoLinkedAccounts)
+
+	require.NoError(t, s.db.Exec(`INSERT INTO riot_accounts VALUES ('account', 'user', 'Faker', 'KR1', 'kr', ?)`, time.Now()).Error)
+	_, release, err := s.acquireSyncLock(context.Background(), "user")
+	require.NoError(t, err)
+	defer release()
+
+	_, err = s.SyncAllAccounts(context.Background(), "user", 0, MatchListFilter{})
+	var inProgress *SyncInProgressError
+	assert.True(t, errors.As(err, &inProgress), "a running sync blocks a bulk sync")
+	assert.Empty(t, s.bulkSyncs)
+}
+
+func TestGetBulkSync(t *testing.T) {
+	s := newBulkSyncTestService(t)
+	result := &BulkSyncResult{
+		ID:        uuid.New(),
+		UserID:    "user",
+		State:     SyncStateRunning,
+		StartedAt: time.Now(),
+		Accounts:  2,
+		Results:   []AccountSyncResult{{RiotAccountID: "first"}, {RiotAccountID: "second"}},
+	}
+	s.storeBulkSync(result)
+
+	polled, ok := s.GetBulkSync("user", result.ID.String())
+	require.True(t, ok)
+	assert.Equal(t, SyncStateRunning, polled.State)
+	assert.False(t, polled.Results[0].Done)
+
+	s.finishBulkSyncAccount(result, 0, nil)
+	s.finishBulkSyncAccount(result, 1, errors.New("riot unavailable"))
+	assert.False(t, polled.Results[0].Done, "a polled result is a snapshot")
+
+	polled, ok = s.GetBulkSync("user", result.ID.String())
+	require.True(t, ok)
+	assert.Equal(t, 1, polled.Synced)
+	assert.Equal(t, 1, polled.Failed)
+	assert.True(t, polled.Results[0].Success)
+	assert.True(t, polled.Results[1].Done)
+	assert.Equal(t, "riot unavailable", polled.Results[1].Error)
+
+	_, ok = s.GetBulkSync("other", result.ID.String())
+	assert.False(t, ok, "bulk syncs of other users are hidden")
+	_, ok = s.GetBulkSync("user", "not-a-uuid")
+	assert.False(t, ok)
+
+	// Finished syncs are dropped once they are past the retention
+	finished := time.Now().Add(-2 * bulkSyncRetention)
+	result.FinishedAt = &finished
+	s.storeBulkSync(&BulkSyncResult{ID: uuid.New(), UserID: "user"})
+	_, ok = s.GetBulkSync("user", result.ID.String())
+	assert.False(t, ok)
+}
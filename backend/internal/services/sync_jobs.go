@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/logger"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// SyncJobRetried marks a failed sync job that has been retried; the retry is
+// its own job with RetryOf set
+const SyncJobRetried = "retried"
+
+// maxListedSyncJobs caps how many jobs ListSyncJobs returns
+const maxListedSyncJobs = 100
+
+// syncJobInterrupted is the error recorded on a job whose sync never
+// finished, because the server stopped or the sync hung
+const syncJobInterrupted = "sync interrupted before it finished"
+
+var (
+	// ErrSyncJobNotFound is returned when a sync job does not exist or belongs
+	// to another user
+	ErrSyncJobNotFound = errors.New("sync job not found")
+	// ErrSyncJobNotRetryable is returned when retrying a job that did not fail
+	ErrSyncJobNotRetryable = errors.New("only failed sync jobs can be retried")
+)
+
+type syncRetryKey struct{}
+
+// startSyncJob stores a running job for a sync. Jobs are bookkeeping, so a
+// failure to store one is logged and the sync continues without it.
+func (s *RiotService) startSyncJob(ctx context.Context, userID, riotAccountID string, count int, filter MatchListFilter) *models.SyncJob {
+	job := &models.SyncJob{
+		ID:            uuid.New(),
+		UserID:        userID,
+		RiotAccountID: riotAccountID,
+		Count:         count,
+		MatchType:     filter.Type,
+		Queue:         filter.Queue,
+		Status:        SyncStateRunning,
+		StartedAt:     time.Now(),
+	}
+	if retryOf, ok := ctx.Value(syncRetryKey{}).(uuid.UUID); ok {
+		job.RetryOf = &retryOf
+	}
+
+	unlock := s.lockWrites()
+	defer unlock()
+	if err := s.db.Create(job).Error; err != nil {
+		logger.Warnf("Failed to record sync job for account %s: %v", riotAccountID, err)
+		return nil
+	}
+	return job
+}
+
+// finishSyncJob records the outcome of a sync on its job
+func (s *RiotService) finishSyncJob(job *models.SyncJob, saved int, err error) {
+	if job == nil {
+		return
+	}
+
+	finished := time.Now()
+	job.FinishedAt = &finished
+	job.SavedMatches = saved
+	job.Status = SyncStateCompleted
+	if err != nil {
+		job.Status = SyncStateFailed
+		job.Error = err.Error()
+	}
+
+	unlock := s.lockWrites()
+	defer unlock()
+	if err := s.db.Save(job).Error; err != nil {
+		logger.Warnf("Failed to update sync job %s: %v", job.ID, err)
+	}
+}
+
+// ListSyncJobs returns the user's most recent sync jobs, newest first,
+// optionally limited to one status. Jobs running for longer than
+// Sync.LockTimeout are marked failed first, so they show up as retryable.
+func (s *RiotService) ListSyncJobs(ctx context.Context, userID, status string) ([]models.SyncJob, error) {
+	if timeout := s.config.Sync.LockTimeout; timeout > 0 {
+		if _, err := s.failRunningSyncJobs(ctx, userID, time.Now().Add(-timeout)); err != nil {
+			logger.Warnf("Failed to close stale sync jobs for user %s: %v", userID, err)
+		}
+	}
+
+	query := s.db.WithContext(ctx).Where("user_id = ?", userID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var jobs []models.SyncJob
+	err := query.Order("started_at DESC").Limit(maxListedSyncJobs).Find(&jobs).Error
+	return jobs, err
+}
+
+// ReapInterruptedSyncJobs marks every job still running as failed and
+// returns how many there were. It is meant for startup, when no sync of this
+// process has begun, so running jobs are left over from before a restart.
+func (s *RiotService) ReapInterruptedSyncJobs(ctx context.Context) (int64, error) {
+	return s.failRunningSyncJobs(ctx, "", time.Now())
+}
+
+// failRunningSyncJobs marks running jobs started before cutoff as failed,
+// only the user's unless userID is empty
+func (s *RiotService) failRunningSyncJobs(ctx context.Context, userID string, cutoff time.Time) (int64, error) {
+	query := s.db.WithContext(ctx).Model(&models.SyncJob{}).
+		Where("status = ? AND started_at < ?", SyncStateRunning, cutoff)
+	if userID != "" {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	unlock := s.lockWrites()
+	defer unlock()
+	result := query.Updates(map[string]interface{}{
+		"status":      SyncStateFailed,
+		"error":       syncJobInterrupted,
+		"finished_at": time.Now(),
+	})
+	return result.RowsAffected, result.Error
+}
+
+// RetrySyncJob re-runs a failed sync job with its original account, count and
+// match filter and returns the new job. The failed job is marked retried before the new
+// sync starts, so it is not retried twice.
+func (s *RiotService) RetrySyncJob(ctx context.Context, userID, jobID string) (*models.SyncJob, error) {
+	id, err := uuid.Parse(jobID)
+	if err != nil {
+		return nil, ErrSyncJobNotFound
+	}
+
+	var failed models.SyncJob
+	if err := s.db.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&failed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSyncJobNotFound
+		}
+		return nil, err
+	}
+
+	unlock := s.lockWrites()
+	result := s.db.WithContext(ctx).Model(&models.SyncJob{}).
+		Where("id = ? AND status = ?", id, SyncStateFailed).
+		Update("status", SyncJobRetried)
+	unlock()
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrSyncJobNotRetryable
+	}
+
+	filter := MatchListFilter{Type: failed.MatchType, Queue: failed.Queue}
+	syncErr := s.SyncMatchHistory(context.WithValue(ctx, syncRetryKey{}, id), userID, failed.RiotAccountID, failed.Count, filter)
+
+	var retry models.SyncJob
+	if err := s.db.WithContext(ctx).Where("retry_of = ?", id).Order("started_at DESC").First(&retry).Error; err != nil {
+		// The sync failed before its job was recorded, so the failed job
+		// remains the latest record and stays retryable
+		unlock := s.lockWrites()
+		s.db.Model(&models.SyncJob{}).Where("id = ?", id).Update("status", SyncStateFailed)
+		unlock()
+		if syncErr != nil {
+			return nil, syncErr
+		}
+		return nil, err
+	}
+
+	return &retry, nil
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+func newSyncJobTestService(t *testing.T, lockTimeout time.Duration) *RiotService {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.Exec(`CREATE TABLE sync_jobs (id TEXT PRIMARY KEY, created_at DATETIME, updated_at DATETIME,
+		user_id TEXT, riot_account_id TEXT, count INTEGER, match_type TEXT, queue INTEGER, status TEXT, error TEXT,
+		saved_matches INTEGER, started_at DATETIME, finished_at DATETIME, retry_of TEXT)`).Error)
+
+	cfg := &config.Config{}
+	cfg.Sync.LockTimeout = lockTimeout
+	return &RiotService{db: db, config: cfg}
+}
+
+func createSyncJob(t *testing.T, s *RiotService, userID, status string, age time.Duration) uuid.UUID {
+	job := models.SyncJob{
+		ID:            uuid.New(),
+		UserID:        userID,
+		RiotAccountID: "account",
+		Count:         20,
+		Status:        status,
+		StartedAt:     time.Now().Add(-age),
+	}
+	require.NoError(t, s.db.Create(&job).Error)
+	return job.ID
+}
+
+func TestListSyncJobsReapsStaleRunningJobs(t *testing.T) {
+	s := newSyncJobTestService(t, 30*time.Minute)
+	stale := createSyncJob(t, s, "user", SyncStateRunning, 2*time.Hour)
+	active := createSyncJob(t, s, "user", SyncStateRunning, time.Minute)
+	otherUser := createSyncJob(t, s, "other", SyncStateRunning, 2*time.Hour)
+
+	jobs, err := s.ListSyncJobs(context.Background(), "user", "")
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, active, jobs[0].ID, "newest job first")
+	assert.Equal(t, SyncStateRunning, jobs[0].Status)
+	assert.Equal(t, stale, jobs[1].ID)
+	assert.Equal(t, SyncStateFailed, jobs[1].Status)
+	assert.Equal(t, syncJobInterrupted, jobs[1].Error)
+	assert.NotNil(t, jobs[1].FinishedAt)
+
+	// Listing one user's jobs leaves other users' jobs alone
+	var other models.SyncJob
+	require.NoError(t, s.db.First(&other, "id = ?", otherUser).Error)
+	assert.Equal(t, SyncStateRunning, other.Status)
+
+	failed, err := s.ListSyncJobs(context.Background(), "user", SyncStateFailed)
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, stale, failed[0].ID)
+}
+
+func TestListSyncJobsWithoutLockTimeoutKeepsRunningJobs(t *testing.T) {
+	s := newSyncJobTestService(t, 0)
+	createSyncJob(t, s, "user", SyncStateRunning, 48*time.Hour)
+
+	jobs, err := s.ListSyncJobs(context.Background(), "user", "")
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, SyncStateRunning, jobs[0].Status)
+}
+
+func TestReapInterruptedSyncJobs(t *testing.T) {
+	s := newSyncJobTestService(t, 0)
+	createSyncJob(t, s, "user", SyncStateRunning, time.Minute)
+	createSyncJob(t, s, "other", SyncStateRunning, time.Hour)
+	completed := createSyncJob(t, s, "user", SyncStateCompleted, time.Hour)
+
+	reaped, err := s.ReapInterruptedSyncJobs(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), reaped)
+
+	var running int64
+	require.NoError(t, s.db.Model(&models.SyncJob{}).Where("status = ?", SyncStateRunning).Count(&running).Error)
+	assert.Zero(t, running)
+
+	var job models.SyncJob
+	require.NoError(t, s.db.First(&job, "id = ?", completed).Error)
+	assert.Equal(t, SyncStateCompleted, job.Status, "finished jobs are not touched")
+}
+
+func TestRetrySyncJobRejectsJobsThatDidNotFail(t *testing.T) {
+	s := newSyncJobTestService(t, 0)
+	completed := createSyncJob(t, s, "user", SyncStateCompleted, time.Hour)
+
+	_, err := s.RetrySyncJob(context.Background(), "user", completed.String())
+	assert.ErrorIs(t, err, ErrSyncJobNotRetryable)
+
+	_, err = s.RetrySyncJob(context.Background(), "other", completed.String())
+	assert.ErrorIs(t, err, ErrSyncJobNotFound, "jobs of other users are hidden")
+
+	_, err = s.RetrySyncJob(context.Background(), "user", "not-a-uuid")
+	assert.ErrorIs(t, err, ErrSyncJobNotFound)
+}
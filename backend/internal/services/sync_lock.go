@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSyncInProgress is returned, wrapped in a SyncInProgressError, when a sync
+// is requested while another sync of the same user is running
+var ErrSyncInProgress = errors.New("a sync is already in progress")
+
+// SyncInProgressError identifies the running sync a new request was turned
+// away for
+type SyncInProgressError struct {
+	StartedAt time.Time
+	// JobID is the running sync's latest job, nil until one is recorded
+	JobID *uuid.UUID
+}
+
+func (e *SyncInProgressError) Error() string {
+	if e.JobID != nil {
+		return fmt.Sprintf("%s (job %s)", ErrSyncInProgress, e.JobID)
+	}
+	return ErrSyncInProgress.Error()
+}
+
+func (e *SyncInProgressError) Unwrap() error {
+	return ErrSyncInProgress
+}
+
+// syncLock marks a user's running sync
+type syncLock struct {
+	startedAt time.Time
+	jobID     *uuid.UUID
+}
+
+// syncLockKey marks a context whose caller holds the user's sync lock, so the
+// per-account syncs of SyncAllAccounts run under one lock
+type syncLockKey struct{}
+
+// acquireSyncLock takes the user's sync lock, or returns a SyncInProgressError
+// when another sync holds it. Locks older than Sync.LockTimeout are taken over
+// so a stuck sync can't block the user forever. The returned release must be
+// called when the sync finishes; it only drops the lock it took.
+func (s *RiotService) acquireSyncLock(ctx context.Context, userID string) (context.Context, func(), error) {
+	if holder, ok := ctx.Value(syncLockKey{}).(string); ok && holder == userID {
+		return ctx, func() {}, nil
+	}
+
+	s.syncLocksMu.Lock()
+	defer s.syncLocksMu.Unlock()
+
+	if held, ok := s.syncLocks[userID]; ok {
+		timeout := s.config.Sync.LockTimeout
+		if timeout <= 0 || time.Since(held.startedAt) < timeout {
+			return ctx, nil, &SyncInProgressError{StartedAt: held.startedAt, JobID: held.jobID}
+		}
+	}
+
+	lock := &syncLock{startedAt: time.Now()}
+	s.syncLocks[userID] = lock
+
+	release := func() {
+		s.syncLocksMu.Lock()
+		defer s.syncLocksMu.Unlock()
+		if s.syncLocks[userID] == lock {
+			delete(s.syncLocks, userID)
+		}
+	}
+	return context.WithValue(ctx, syncLockKey{}, userID), release, nil
+}
+
+// setSyncLockJob records the job of the sync holding the user's lock
+func (s *RiotService) setSyncLockJob(userID string, jobID uuid.UUID) {
+	s.syncLocksMu.Lock()
+	defer s.syncLocksMu.Unlock()
+	if lock, ok := s.syncLocks[userID]; ok {
+		lock.jobID = &jobID
+	}
+}
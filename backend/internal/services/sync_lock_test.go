@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+)
+
+func TestSyncLock(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Sync.LockTimeout = time.Minute
+	s := &RiotService{config: cfg, syncLocks: make(map[string]*syncLock)}
+
+	ctx, release, err := s.acquireSyncLock(context.Background(), "user")
+	require.NoError(t, err)
+	jobID := uuid.New()
+	s.setSyncLockJob("user", jobID)
+
+	_, _, err = s.acquireSyncLock(context.Background(), "user")
+	var inProgress *SyncInProgressError
+	require.True(t, errors.As(err, &inProgress))
+	assert.ErrorIs(t, err, ErrSyncInProgress)
+	assert.Equal(t, jobID, *inProgress.JobID)
+
+	_, nested, err := s.acquireSyncLock(ctx, "user")
+	assert.NoError(t, err, "the lock holder's context may sync each account")
+	nested()
+	_, _, err = s.acquireSyncLock(context.Background(), "user")
+	assert.ErrorIs(t, err, ErrSyncInProgress, "a nested release keeps the lock")
+
+	_, other, err := s.acquireSyncLock(context.Background(), "other")
+	assert.NoError(t, err, "locks are per user")
+	other()
+
+	release()
+	_, release, err = s.acquireSyncLock(context.Background(), "user")
+	assert.NoError(t, err)
+	release()
+
+	// An abandoned lock is taken over once it times out
+	s.syncLocks["user"] = &syncLock{startedAt: time.Now().Add(-2 * time.Minute)}
+	_, release, err = s.acquireSyncLock(context.Background(), "user")
+	assert.NoError(t, err)
+	release()
+}
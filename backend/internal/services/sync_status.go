@@ -0,0 +1,147 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/herald-lol/herald/backend/internal/logger"
+)
+
+// Sync states reported by SyncStatus
+const (
+	SyncStateRunning   = "running"
+	SyncStateRetrying  = "retrying"
+	SyncStateCompleted = "completed"
+	SyncStateFailed    = "failed"
+)
+
+// SyncStatus is the state of a user's latest match sync, including any rate
+// limit backoff in progress so clients can show "retrying in 30s"
+type SyncStatus struct {
+	UserID        string    `json:"user_id"`
+	RiotAccountID string    `json:"riot_account_id"`
+	State         string    `json:"state"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at,omitempty"`
+	SavedMatches  int       `json:"saved_matches"`
+	Error         string    `json:"error,omitempty"`
+	// IncompleteMatches counts the user's matches that could not be fetched
+	// or stored, as of the end of the sync; see ListIncompleteMatches
+	IncompleteMatches int64 `json:"incomplete_matches"`
+
+	// Retry state, set while waiting out a rate limit
+	RetryAttempt int        `json:"retry_attempt,omitempty"`
+	MaxRetries   int        `json:"max_retries"`
+	NextRetryAt  *time.Time `json:"next_retry_at,omitempty"`
+	RetryReason  string     `json:"retry_reason,omitempty"`
+}
+
+// GetSyncStatus returns a copy of the user's latest sync status
+func (s *RiotService) GetSyncStatus(userID string) (*SyncStatus, bool) {
+	s.syncStatusMu.RLock()
+	defer s.syncStatusMu.RUnlock()
+
+	status, ok := s.syncStatus[userID]
+	if !ok {
+		return nil, false
+	}
+	copied := *status
+	return &copied, true
+}
+
+// updateSyncStatus applies fn to the user's sync status under the lock
+func (s *RiotService) updateSyncStatus(userID string, fn func(status *SyncStatus)) {
+	s.syncStatusMu.Lock()
+	defer s.syncStatusMu.Unlock()
+
+	status, ok := s.syncStatus[userID]
+	if !ok {
+		status = &SyncStatus{UserID: userID}
+		s.syncStatus[userID] = status
+	}
+	fn(status)
+}
+
+// startSyncStatus marks a new sync as running and returns a context that
+// reports rate limit retries back to the status
+func (s *RiotService) startSyncStatus(ctx context.Context, userID, riotAccountID string) context.Context {
+	s.syncStatusMu.Lock()
+	s.syncStatus[userID] = &SyncStatus{
+		UserID:        userID,
+		RiotAccountID: riotAccountID,
+		State:         SyncStateRunning,
+		StartedAt:     time.Now(),
+		MaxRetries:    s.config.Sync.RateLimitRetries,
+	}
+	s.syncStatusMu.Unlock()
+
+	return context.WithValue(ctx, syncStatusKey{}, userID)
+}
+
+// finishSyncStatus records the outcome of a sync and the matches still
+// incomplete after it
+func (s *RiotService) finishSyncStatus(ctx context.Context, userID string, saved int, err error) {
+	// Counted even when the sync was cancelled
+	incomplete, countErr := s.CountIncompleteMatches(context.WithoutCancel(ctx), userID)
+	if countErr != nil {
+		logger.Warnf("Failed to count incomplete matches for user %s: %v", userID, countErr)
+	}
+	s.updateSyncStatus(userID, func(status *SyncStatus) {
+		status.State = SyncStateCompleted
+		status.FinishedAt = time.Now()
+		status.SavedMatches = saved
+		status.IncompleteMatches = incomplete
+		status.NextRetryAt = nil
+		status.RetryReason = ""
+		if err != nil {
+			status.State = SyncStateFailed
+			status.Error = err.Error()
+		}
+	})
+}
+
+type syncStatusKey struct{}
+
+// reportRetry marks the sync behind ctx, if any, as waiting to retry
+func (s *RiotService) reportRetry(ctx context.Context, attempt int, next time.Time, reason string) {
+	userID, ok := ctx.Value(syncStatusKey{}).(string)
+	if !ok {
+		return
+	}
+	s.updateSyncStatus(userID, func(status *SyncStatus) {
+		status.State = SyncStateRetrying
+		status.RetryAttempt = attempt
+		status.NextRetryAt = &next
+		status.RetryReason = reason
+	})
+}
+
+// reportRetryDone moves the sync behind ctx back to running after a wait
+func (s *RiotService) reportRetryDone(ctx context.Context) {
+	userID, ok := ctx.Value(syncStatusKey{}).(string)
+	if !ok {
+		return
+	}
+	s.updateSyncStatus(userID, func(status *SyncStatus) {
+		if status.State == SyncStateRetrying {
+			status.State = SyncStateRunning
+			status.NextRetryAt = nil
+		}
+	})
+}
+
+// rateLimitDelay returns how long to wait before retry number attempt
+// (1-based), preferring Riot's Retry-After header over exponential backoff
+func (s *RiotService) rateLimitDelay(header http.Header, attempt int) time.Duration {
+	if seconds, err := strconv.Atoi(header.Get("Retry-After")); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	backoff := s.config.Sync.RateLimitBackoff
+	if backoff <= 0 {
+		backoff = 10 * time.Second
+	}
+	return backoff << (attempt - 1)
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+func TestSyncStatusCountsIncompleteMatches(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	createModelTable(t, db, &models.IncompleteMatch{})
+	for _, stub := range []models.IncompleteMatch{
+		{UserID: "user", RiotAccountID: "account", MatchID: "EUW1_1", Reason: "fetch_failed"},
+		{UserID: "user", RiotAccountID: "account", MatchID: "EUW1_2", Reason: "store_failed"},
+		{UserID: "other", RiotAccountID: "other-account", MatchID: "EUW1_1", Reason: "fetch_failed"},
+	} {
+		stub.ID = uuid.New()
+		stub.LastAttemptAt = time.Now()
+		require.NoError(t, db.Create(&stub).Error)
+	}
+
+	s := &RiotService{db: db, config: &config.Config{}, syncStatus: make(map[string]*SyncStatus)}
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = s.startSyncStatus(ctx, "user", "account")
+
+	// A cancelled sync still reports what is left to fetch
+	cancel()
+	s.finishSyncStatus(ctx, "user", 3, context.Canceled)
+
+	status, ok := s.GetSyncStatus("user")
+	require.True(t, ok)
+	assert.Equal(t, SyncStateFailed, status.State)
+	assert.Equal(t, 3, status.SavedMatches)
+	assert.Equal(t, int64(2), status.IncompleteMatches)
+}
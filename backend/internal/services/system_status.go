@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// SubsystemState is the machine-readable health of one subsystem
+type SubsystemState string
+
+// Subsystem states, from best to worst. Disabled subsystems are left out of
+// the overall status.
+const (
+	StateOK       SubsystemState = "ok"
+	StateDegraded SubsystemState = "degraded"
+	StateDown     SubsystemState = "down"
+	StateDisabled SubsystemState = "disabled"
+)
+
+// DefaultStatusCheckTimeout bounds each live probe of a status check
+const DefaultStatusCheckTimeout = 2 * time.Second
+
+// autoSyncStallCycles is how many missed intervals mark auto-sync as stalled
+const autoSyncStallCycles = 3
+
+// SubsystemStatus is the state of one subsystem with a human-readable reason
+type SubsystemStatus struct {
+	Status  SubsystemState `json:"status"`
+	Message string         `json:"message,omitempty"`
+}
+
+// SystemStatus is the state of every subsystem, for a single frontend banner
+type SystemStatus struct {
+	Status     SubsystemState             `json:"status"`
+	Message    string                     `json:"message,omitempty"` // operator banner text
+	Subsystems map[string]SubsystemStatus `json:"subsystems"`
+	CheckedAt  time.Time                  `json:"checked_at"`
+}
+
+// StatusService reports the health of the Riot API, the database, the cache,
+// analytics and auto-sync in one place
+type StatusService struct {
+	db        *gorm.DB
+	riot      *RiotService
+	analytics *AnalyticsService
+	autoSync  *AutoSyncService
+
+	timeout time.Duration
+	message string
+
+	// The last check, reused for cacheTTL so frequent polling probes the
+	// subsystems at most once per TTL
+	cacheTTL  time.Duration
+	cached    *SystemStatus
+	checkedAt time.Time
+	cacheMu   sync.Mutex
+}
+
+// NewStatusService creates a new status service
+func NewStatusService(db *gorm.DB, riot *RiotService, analytics *AnalyticsService) *StatusService {
+	return &StatusService{
+		db:        db,
+		riot:      riot,
+		analytics: analytics,
+		timeout:   DefaultStatusCheckTimeout,
+	}
+}
+
+// SetAutoSync reports on the running auto-sync service; without one
+// auto-sync is reported as disabled
+func (s *StatusService) SetAutoSync(autoSync *AutoSyncService) {
+	s.autoSync = autoSync
+}
+
+// SetCheckTimeout bounds each live probe; non-positive values restore the
+// default
+func (s *StatusService) SetCheckTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = DefaultStatusCheckTimeout
+	}
+	s.timeout = timeout
+}
+
+// SetCacheTTL sets how long a check is reused; 0 checks on every call
+func (s *StatusService) SetCacheTTL(ttl time.Duration) {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+	s.cacheTTL = ttl
+	s.cached = nil
+}
+
+// SetMessage sets operator banner text returned with every status, e.g. a
+// maintenance notice; empty clears it
+func (s *StatusService) SetMessage(message string) {
+	s.message = message
+}
+
+// Status returns the last check while it is younger than the cache TTL and
+// checks again otherwise. Concurrent callers share one check, which runs
+// without the caller's cancellation since its result is reused.
+func (s *StatusService) Status(ctx context.Context) *SystemStatus {
+	s.cacheMu.Lock()
+	defer s.cacheMu.Unlock()
+
+	if s.cached == nil || time.Since(s.checkedAt) >= s.cacheTTL {
+		s.cached = s.Check(context.WithoutCancel(ctx))
+		s.checkedAt = time.Now()
+	}
+
+	status := *s.cached
+	status.Subsystems = make(map[string]SubsystemStatus, len(s.cached.Subsystems))
+	for name, subsystem := range s.cached.Subsystems {
+		status.Subsystems[name] = subsystem
+	}
+	return &status
+}
+
+// Check probes every subsystem and derives the overall status from the worst
+// enabled one
+func (s *StatusService) Check(ctx context.Context) *SystemStatus {
+	database := s.databaseStatus(ctx)
+	cache := s.cacheStatus(ctx)
+	subsystems := map[string]SubsystemStatus{
+		"riot":      s.riotStatus(),
+		"db":        database,
+		"cache":     cache,
+		"analytics": analyticsStatus(database, cache),
+		"autosync":  s.autoSyncStatus(),
+	}
+
+	return &SystemStatus{
+		Status:     OverallState(subsystems),
+		Message:    s.message,
+		Subsystems: subsystems,
+		CheckedAt:  time.Now().UTC(),
+	}
+}
+
+// OverallState is the worst state among the enabled subsystems
+func OverallState(subsystems map[string]SubsystemStatus) SubsystemState {
+	overall := StateOK
+	for _, subsystem := range subsystems {
+		switch subsystem.Status {
+		case StateDown:
+			return StateDown
+		case StateDegraded:
+			overall = StateDegraded
+		}
+	}
+	return overall
+}
+
+func (s *StatusService) riotStatus() SubsystemStatus {
+	if s.riot == nil {
+		return SubsystemStatus{Status: StateDisabled}
+	}
+	key := s.riot.APIKeyStatus()
+	switch {
+	case !key.Configured:
+		return SubsystemStatus{Status: StateDown, Message: "Riot API key is not configured"}
+	case !key.Valid:
+		return SubsystemStatus{Status: StateDown, Message: "Riot API key was rejected and may have expired"}
+	}
+	return SubsystemStatus{Status: StateOK}
+}
+
+func (s *StatusService) databaseStatus(ctx context.Context) SubsystemStatus {
+	if s.db == nil {
+		return SubsystemStatus{Status: StateDown, Message: "Database is not connected"}
+	}
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return SubsystemStatus{Status: StateDown, Message: "Database is not connected"}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return SubsystemStatus{Status: StateDown, Message: "Database is unreachable"}
+	}
+	return SubsystemStatus{Status: StateOK}
+}
+
+func (s *StatusService) cacheStatus(ctx context.Context) SubsystemStatus {
+	if s.analytics == nil || s.analytics.redisService == nil {
+		return SubsystemStatus{Status: StateDisabled}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	if err := s.analytics.redisService.Ping(ctx); err != nil {
+		return SubsystemStatus{Status: StateDegraded, Message: "Cache is unreachable, analytics are computed on every request"}
+	}
+	return SubsystemStatus{Status: StateOK}
+}
+
+// analyticsStatus follows the database, then the cache and static data
+func analyticsStatus(database, cache SubsystemStatus) SubsystemStatus {
+	if database.Status == StateDown {
+		return SubsystemStatus{Status: StateDown, Message: "Analytics need the database"}
+	}
+	if cache.Status == StateDegraded {
+		return SubsystemStatus{Status: StateDegraded, Message: "Analytics are slower while the cache is unreachable"}
+	}
+	if _, ok := models.CurrentPatch(); !ok {
+		return SubsystemStatus{Status: StateDegraded, Message: "Patch and champion data have not loaded yet"}
+	}
+	return SubsystemStatus{Status: StateOK}
+}
+
+func (s *StatusService) autoSyncStatus() SubsystemStatus {
+	if s.autoSync == nil {
+		return SubsystemStatus{Status: StateDisabled}
+	}
+	if s.riot != nil && !s.riot.IsConfigured() {
+		return SubsystemStatus{Status: StateDown, Message: "Auto-sync is paused until the Riot API key works"}
+	}
+
+	cycle := s.autoSync.LastCycle()
+	if cycle == nil {
+		return SubsystemStatus{Status: StateOK, Message: "Waiting for the first cycle"}
+	}
+	if s.autoSync.interval > 0 && time.Since(cycle.StartedAt) > autoSyncStallCycles*s.autoSync.interval {
+		return SubsystemStatus{Status: StateDegraded, Message: "Auto-sync has not run recently"}
+	}
+	if cycle.Accounts > 0 && cycle.Synced == 0 && cycle.Failed > 0 {
+		return SubsystemStatus{Status: StateDegraded, Message: "Every account failed to sync in the last cycle"}
+	}
+	return SubsystemStatus{Status: StateOK}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverallState(t *testing.T) {
+	ok := SubsystemStatus{Status: StateOK}
+	disabled := SubsystemStatus{Status: StateDisabled}
+	degraded := SubsystemStatus{Status: StateDegraded}
+	down := SubsystemStatus{Status: StateDown}
+
+	assert.Equal(t, StateOK, OverallState(map[string]SubsystemStatus{"db": ok, "cache": disabled}),
+		"disabled subsystems don't affect the overall status")
+	assert.Equal(t, StateDegraded, OverallState(map[string]SubsystemStatus{"db": ok, "cache": degraded}))
+	assert.Equal(t, StateDown, OverallState(map[string]SubsystemStatus{"riot": down, "cache": degraded}))
+
+	assert.Equal(t, StateDown, analyticsStatus(down, ok).Status, "analytics need the database")
+	assert.Equal(t, StateDegraded, analyticsStatus(ok, degraded).Status)
+}
+
+func TestStatusReusesRecentCheck(t *testing.T) {
+	s := NewStatusService(nil, nil, nil)
+	s.SetCacheTTL(time.Hour)
+	ctx := context.Background()
+
+	first := s.Status(ctx)
+	assert.Equal(t, StateDown, first.Status, "no database is connected")
+	first.Subsystems["db"] = SubsystemStatus{Status: StateOK}
+
+	s.SetMessage("Maintenance tonight")
+	second := s.Status(ctx)
+	assert.Equal(t, first.CheckedAt, second.CheckedAt, "the check is reused within the TTL")
+	assert.Empty(t, second.Message)
+	assert.Equal(t, StateDown, second.Subsystems["db"].Status, "callers can't change the cached status")
+
+	s.SetCacheTTL(0)
+	assert.Equal(t, "Maintenance tonight", s.Status(ctx).Message, "without a TTL every call checks again")
+}
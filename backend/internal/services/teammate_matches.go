@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidRiotID is returned for a Riot ID not in the GameName#TAG form
+var ErrInvalidRiotID = errors.New("invalid riot id")
+
+// parseRiotID splits a Riot ID such as "Hide on bush#KR1" into its game name
+// and tag line
+func parseRiotID(riotID string) (string, string, error) {
+	gameName, tagLine, ok := strings.Cut(strings.TrimSpace(riotID), "#")
+	gameName, tagLine = strings.TrimSpace(gameName), strings.TrimSpace(tagLine)
+	if !ok || gameName == "" || tagLine == "" || strings.Contains(tagLine, "#") {
+		return "", "", fmt.Errorf("%w: %q, expected GameName#TAG", ErrInvalidRiotID, riotID)
+	}
+	return gameName, tagLine, nil
+}
+
+// MatchIDsWithTeammate returns the Riot match IDs of stored matches where
+// playerPUUID and the account behind teammateRiotID were on the same team.
+// Every participant of a synced match is stored, so games against the
+// teammate are left out and games synced from either account count.
+func (s *RiotService) MatchIDsWithTeammate(ctx context.Context, region, playerPUUID, teammateRiotID string) ([]string, error) {
+	gameName, tagLine, err := parseRiotID(teammateRiotID)
+	if err != nil {
+		return nil, err
+	}
+
+	teammate, err := s.GetAccountByRiotID(ctx, region, gameName, tagLine)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve teammate %s: %w", teammateRiotID, err)
+	}
+
+	matchIDs := []string{}
+	err = s.db.WithContext(ctx).
+		Table("matches").
+		Joins("JOIN match_participants player ON player.match_id = matches.id").
+		Joins("JOIN match_participants teammate ON teammate.match_id = matches.id AND teammate.team_id = player.team_id").
+		Where("player.puuid = ? AND teammate.puuid = ?", playerPUUID, teammate.PUUID).
+		Order("matches.game_start_timestamp DESC").
+		Pluck("matches.match_id", &matchIDs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to load matches with teammate: %w", err)
+	}
+	return matchIDs, nil
+}
+
+// LinkedAccountMatches is one of a user's linked Riot accounts with the
+// Riot match IDs of its own stored matches, newest first
+type LinkedAccountMatches struct {
+	PUUID        string   `json:"puuid" gorm:"column:puuid"`
+	SummonerName string   `json:"summoner_name"`
+	Region       string   `json:"region"`
+	MatchIDs     []string `json:"match_ids"`
+}
+
+// LinkedAccountMatches returns every Riot account linked to the user with at
+// most limit of each account's stored matches. Only accounts the user has
+// linked are returned, so an export built from them can't reach anyone
+// else's matches.
+func (s *RiotService) LinkedAccountMatches(ctx context.Context, userID string, limit int) ([]LinkedAccountMatches, error) {
+	var accounts []LinkedAccountMatches
+	err := s.db.WithContext(ctx).
+		Table("riot_accounts").
+		Select("puuid, summoner_name, region").
+		Where("CAST(user_id AS TEXT) = ?", userID).
+		Order("puuid").
+		Scan(&accounts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list linked accounts: %w", err)
+	}
+
+	for i := range accounts {
+		matchIDs := []string{}
+		query := s.db.WithContext(ctx).
+			Table("matches").
+			Joins("JOIN match_participants mp ON mp.match_id = matches.id").
+			Where("mp.puuid = ?", accounts[i].PUUID).
+			Order("matches.game_start_timestamp DESC")
+		if limit > 0 {
+			query = query.Limit(limit)
+		}
+		if err := query.Pluck("matches.match_id", &matchIDs).Error; err != nil {
+			return nil, fmt.Errorf("failed to load matches of %s: %w", accounts[i].PUUID, err)
+		}
+		accounts[i].MatchIDs = matchIDs
+	}
+	return accounts, nil
+}
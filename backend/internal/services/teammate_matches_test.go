@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+func TestParseRiotID(t *testing.T) {
+	gameName, tagLine, err := parseRiotID(" Hide on bush#KR1 ")
+	require.NoError(t, err)
+	assert.Equal(t, "Hide on bush", gameName)
+	assert.Equal(t, "KR1", tagLine)
+
+	for _, riotID := range []string{"", "Hide on bush", "#KR1", "Hide on bush#", "a#b#c"} {
+		_, _, err := parseRiotID(riotID)
+		assert.ErrorIs(t, err, ErrInvalidRiotID, riotID)
+	}
+}
+
+func TestLinkedAccountMatches(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	for _, stmt := range []string{
+		`CREATE TABLE riot_accounts (user_id TEXT, puuid TEXT, summoner_name TEXT, region TEXT)`,
+		`CREATE TABLE matches (id TEXT PRIMARY KEY, match_id TEXT, game_start_timestamp INTEGER)`,
+		`CREATE TABLE match_participants (match_id TEXT, puuid TEXT)`,
+		`INSERT INTO riot_accounts VALUES ('user-1', 'puuid-main', 'Main', 'euw1'), ('user-1', 'puuid-smurf', 'Smurf', 'euw1'),
+			('user-2', 'puuid-other', 'Other', 'na1')`,
+		`INSERT INTO matches VALUES ('1', 'EUW1_1', 100), ('2', 'EUW1_2', 200), ('3', 'EUW1_3', 300)`,
+		// EUW1_2 was played by both of user-1's accounts
+		`INSERT INTO match_participants VALUES ('1', 'puuid-main'), ('2', 'puuid-main'), ('2', 'puuid-smurf'),
+			('3', 'puuid-smurf'), ('3', 'puuid-other')`,
+	} {
+		require.NoError(t, db.Exec(stmt).Error)
+	}
+
+	service := &RiotService{db: db}
+	accounts, err := service.LinkedAccountMatches(context.Background(), "user-1", 0)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "puuid-main", accounts[0].PUUID)
+	assert.Equal(t, "Main", accounts[0].SummonerName)
+	assert.Equal(t, []string{"EUW1_2", "EUW1_1"}, accounts[0].MatchIDs)
+	assert.Equal(t, "puuid-smurf", accounts[1].PUUID)
+	assert.Equal(t, []string{"EUW1_3", "EUW1_2"}, accounts[1].MatchIDs)
+
+	accounts, err = service.LinkedAccountMatches(context.Background(), "user-1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EUW1_2"}, accounts[0].MatchIDs)
+
+	accounts, err = service.LinkedAccountMatches(context.Background(), "user-3", 0)
+	require.NoError(t, err)
+	assert.Empty(t, accounts)
+}
+
+func TestRankHistory(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{})
+	require.NoError(t, err)
+	require.NoError(t, db.AutoMigrate(&models.User{}, &models.RankProgressionHistory{}))
+	require.NoError(t, db.Create(&models.User{ID: "user-1", Email: "a@herald.lol", Username: "a", PasswordHash: "x", SummonerID: "summoner-1"}).Error)
+	require.NoError(t, db.Create(&models.User{ID: "user-2", Email: "b@herald.lol", Username: "b", PasswordHash: "x", SummonerID: "summoner-2"}).Error)
+	start := time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)
+	for _, entry := range []models.RankProgressionHistory{
+		{SummonerID: "summoner-1", Rank: "GOLD", LP: 50, RecordedAt: start.Add(time.Hour)},
+		{SummonerID: "summoner-1", Rank: "GOLD", LP: 30, RecordedAt: start},
+		{SummonerID: "summoner-2", Rank: "GOLD", LP: 90, RecordedAt: start},
+	} {
+		entry.Season, entry.GameMode = "2026", "RANKED_SOLO_5x5"
+		require.NoError(t, db.Omit("User").Create(&entry).Error)
+	}
+
+	service := &RiotService{db: db}
+	history, err := service.RankHistory(context.Background(), "user-1")
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 30, history[0].LP)
+	assert.Equal(t, 50, history[1].LP)
+}
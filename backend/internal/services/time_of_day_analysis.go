@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/herald-lol/herald/backend/internal/logger"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// DefaultTimeOfDayBucketHours is the width of each hour-of-day bucket
+const DefaultTimeOfDayBucketHours = 1
+
+// timeOfDayMinGames is the fewest games a bucket needs to be named the
+// player's best or worst time to queue
+const timeOfDayMinGames = 5
+
+// HourBucket is the record of games started within a range of local hours
+type HourBucket struct {
+	Label     string `json:"label"`      // e.g. "22:00-23:59"
+	StartHour int    `json:"start_hour"` // inclusive
+	EndHour   int    `json:"end_hour"`   // exclusive
+	QueueSplitStats
+}
+
+// TimeOfDayAnalysis holds a player's win rate by the local hour their games
+// started
+type TimeOfDayAnalysis struct {
+	PlayerID    string          `json:"player_id"`
+	TimeRange   string          `json:"time_range"`
+	Timezone    string          `json:"timezone"`
+	BucketHours int             `json:"bucket_hours"`
+	Matches     int             `json:"matches"`
+	Overall     QueueSplitStats `json:"overall"`
+	Buckets     []HourBucket    `json:"buckets"`
+	// Best and Worst are the buckets with the highest and lowest win rate
+	// among those with at least MinGames games; nil when none qualify
+	MinGames int         `json:"min_games"`
+	Best     *HourBucket `json:"best,omitempty"`
+	Worst    *HourBucket `json:"worst,omitempty"`
+}
+
+// IsValidBucketHours reports whether hours splits the day into equal buckets
+func IsValidBucketHours(hours int) bool {
+	return hours >= 1 && hours <= 12 && 24%hours == 0
+}
+
+// SetTimeOfDayBucketHours sets the default hour-of-day bucket width; values
+// that don't divide the day evenly restore the default
+func (as *AnalyticsService) SetTimeOfDayBucketHours(hours int) {
+	if !IsValidBucketHours(hours) {
+		hours = DefaultTimeOfDayBucketHours
+	}
+	as.timeOfDayBucketHours = hours
+}
+
+// TimezoneDefault returns the user's timezone preference, falling back to UTC
+// when it is unset or not a known IANA zone
+func (as *AnalyticsService) TimezoneDefault(ctx context.Context, userID string) *time.Location {
+	var timezone sql.NullString
+	err := as.db.QueryRowContext(ctx, `SELECT timezone FROM user_preferences WHERE user_id = $1`, userID).Scan(&timezone)
+	if err != nil && err != sql.ErrNoRows {
+		logger.Warnf("Failed to load timezone preference for %s: %v", userID, err)
+	}
+	if !timezone.Valid || timezone.String == "" {
+		return time.UTC
+	}
+
+	loc, err := time.LoadLocation(timezone.String)
+	if err != nil {
+		logger.Warnf("Ignoring invalid timezone preference %q for %s", timezone.String, userID)
+		return time.UTC
+	}
+	return loc
+}
+
+// AnalyzeWinRateByTimeOfDay buckets the games of the user's linked accounts
+// in timeRange by the hour they started in loc and computes the win rate per bucket. bucketHours
+// overrides the configured bucket width when positive.
+func (as *AnalyticsService) AnalyzeWinRateByTimeOfDay(ctx context.Context, playerID, timeRange string, loc *time.Location, bucketHours int, filter MatchFilter) (*TimeOfDayAnalysis, error) {
+	filter = filter.normalize()
+	if bucketHours < 1 {
+		bucketHours = as.timeOfDayBucketHours
+	}
+	startDate, endDate := as.parseTimeRange(timeRange)
+
+	matches, _, err := as.getAccountFilteredMatches(ctx, playerID, startDate, endDate, "", filter)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := BuildTimeOfDayAnalysis(matches, loc, bucketHours)
+	analysis.PlayerID = playerID
+	analysis.TimeRange = timeRange
+	return analysis, nil
+}
+
+// BuildTimeOfDayAnalysis buckets matches by the local hour of their start
+// time in loc and picks the best and worst buckets
+func BuildTimeOfDayAnalysis(matches []models.MatchData, loc *time.Location, bucketHours int) *TimeOfDayAnalysis {
+	if loc == nil {
+		loc = time.UTC
+	}
+	if !IsValidBucketHours(bucketHours) {
+		bucketHours = DefaultTimeOfDayBucketHours
+	}
+
+	count := 24 / bucketHours
+	accumulators := make([]splitAccumulator, count)
+	var overall splitAccumulator
+	for _, match := range matches {
+		accumulators[match.Date.In(loc).Hour()/bucketHours].add(match)
+		overall.add(match)
+	}
+
+	analysis := &TimeOfDayAnalysis{
+		Timezone:    loc.String(),
+		BucketHours: bucketHours,
+		Matches:     len(matches),
+		Overall:     overall.stats(),
+		Buckets:     make([]HourBucket, count),
+		MinGames:    timeOfDayMinGames,
+	}
+	for i := range accumulators {
+		start := i * bucketHours
+		bucket := HourBucket{
+			Label:           fmt.Sprintf("%02d:00-%02d:59", start, start+bucketHours-1),
+			StartHour:       start,
+			EndHour:         start + bucketHours,
+			QueueSplitStats: accumulators[i].stats(),
+		}
+		analysis.Buckets[i] = bucket
+
+		if bucket.Games < timeOfDayMinGames {
+			continue
+		}
+		if analysis.Best == nil || bucket.WinRate > analysis.Best.WinRate {
+			best := bucket
+			analysis.Best = &best
+		}
+		if analysis.Worst == nil || bucket.WinRate < analysis.Worst.WinRate {
+			worst := bucket
+			analysis.Worst = &worst
+		}
+	}
+
+	return analysis
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/herald-lol/herald/backend/internal/config"
+	"github.com/herald-lol/herald/backend/internal/models"
+)
+
+// ErrInvalidPreferences is returned when a preferences update fails validation
+var ErrInvalidPreferences = errors.New("invalid preferences")
+
+// PreferenceThemes are the accepted values of the theme preference
+var PreferenceThemes = []string{"dark", "light", "auto"}
+
+// languagePattern matches a language code such as "en" or "pt-BR"
+var languagePattern = regexp.MustCompile(`^[a-z]{2}(-[A-Z]{2})?$`)
+
+// PreferencesError lists every invalid field of a preferences update, keyed
+// by JSON field name. It wraps ErrInvalidPreferences.
+type PreferencesError struct {
+	Fields map[string]string
+}
+
+func (e *PreferencesError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	messages := make([]string, len(names))
+	for i, name := range names {
+		messages[i] = name + " " + e.Fields[name]
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidPreferences, strings.Join(messages, "; "))
+}
+
+func (e *PreferencesError) Unwrap() error {
+	return ErrInvalidPreferences
+}
+
+// add records a field error, keeping the first one per field
+func (e *PreferencesError) add(field, message string) {
+	if _, exists := e.Fields[field]; !exists {
+		e.Fields[field] = message
+	}
+}
+
+// orNil returns e when it holds any field error
+func (e *PreferencesError) orNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// UpdatePreferencesRequest holds the preferences a user can change, named as
+// in models.UserPreferences. Omitted or null fields are left unchanged.
+type UpdatePreferencesRequest struct {
+	Theme                   *string `json:"theme,omitempty"`
+	Language                *string `json:"language,omitempty"`
+	Region                  *string `json:"region,omitempty"`
+	Timezone                *string `json:"timezone,omitempty"`
+	EmailNotifications      *bool   `json:"emailNotifications,omitempty"`
+	PushNotifications       *bool   `json:"pushNotifications,omitempty"`
+	MatchAlerts             *bool   `json:"matchAlerts,omitempty"`
+	AnalyticsSharing        *bool   `json:"analyticsSharing,omitempty"`
+	PrivacyMode             *bool   `json:"privacyMode,omitempty"`
+	AutoSyncMatches         *bool   `json:"autoSyncMatches,omitempty"`
+	MaxSyncMatches          *int    `json:"maxSyncMatches,omitempty"`
+	CoachingRecommendations *bool   `json:"coachingRecommendations,omitempty"`
+	PublicProfile           *bool   `json:"publicProfile,omitempty"`
+	ExcludeRemakes          *bool   `json:"excludeRemakes,omitempty"`
+}
+
+// UnmarshalJSON decodes each field with an explicit type so a wrong-typed or
+// unknown field is reported by name instead of failing the whole body or
+// silently defaulting. Booleans also accept "true"/"false" and integers
+// accept numeric strings. Every bad field is returned in a PreferencesError.
+func (r *UpdatePreferencesRequest) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("%w: request body must be a JSON object", ErrInvalidPreferences)
+	}
+
+	*r = UpdatePreferencesRequest{}
+	stringFields := map[string]**string{
+		"theme":    &r.Theme,
+		"language": &r.Language,
+		"region":   &r.Region,
+		"timezone": &r.Timezone,
+	}
+	boolFields := map[string]**bool{
+		"emailNotifications":      &r.EmailNotifications,
+		"pushNotifications":       &r.PushNotifications,
+		"matchAlerts":             &r.MatchAlerts,
+		"analyticsSharing":        &r.AnalyticsSharing,
+		"privacyMode":             &r.PrivacyMode,
+		"autoSyncMatches":         &r.AutoSyncMatches,
+		"coachingRecommendations": &r.CoachingRecommendations,
+		"publicProfile":           &r.PublicProfile,
+		"excludeRemakes":          &r.ExcludeRemakes,
+	}
+	intFields := map[string]**int{
+		"maxSyncMatches": &r.MaxSyncMatches,
+	}
+
+	fieldErrors := &PreferencesError{Fields: make(map[string]string)}
+	for name, value := range raw {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			continue
+		}
+		switch {
+		case stringFields[name] != nil:
+			var parsed string
+			if err := json.Unmarshal(value, &parsed); err != nil {
+				fieldErrors.add(name, "must be a string")
+				continue
+			}
+			*stringFields[name] = &parsed
+		case boolFields[name] != nil:
+			parsed, ok := parsePreferenceBool(value)
+			if !ok {
+				fieldErrors.add(name, "must be a boolean")
+				continue
+			}
+			*boolFields[name] = &parsed
+		case intFields[name] != nil:
+			parsed, ok := parsePreferenceInt(value)
+			if !ok {
+				fieldErrors.add(name, "must be an integer")
+				continue
+			}
+			*intFields[name] = &parsed
+		default:
+			fieldErrors.add(name, "is not a known preference")
+		}
+	}
+	return fieldErrors.orNil()
+}
+
+// parsePreferenceBool accepts a JSON boolean or the string "true"/"false"
+func parsePreferenceBool(value json.RawMessage) (bool, bool) {
+	var parsed bool
+	if err := json.Unmarshal(value, &parsed); err == nil {
+		return parsed, true
+	}
+	var text string
+	if err := json.Unmarshal(value, &text); err != nil {
+		return false, false
+	}
+	parsed, err := strconv.ParseBool(strings.TrimSpace(text))
+	return parsed, err == nil
+}
+
+// parsePreferenceInt accepts a whole JSON number or a numeric string
+func parsePreferenceInt(value json.RawMessage) (int, bool) {
+	var parsed int
+	if err := json.Unmarshal(value, &parsed); err == nil {
+		return parsed, true
+	}
+	var text string
+	if err := json.Unmarshal(value, &text); err != nil {
+		return 0, false
+	}
+	parsed, err := strconv.Atoi(strings.TrimSpace(text))
+	return parsed, err == nil
+}
+
+// GetPreferences returns the user's preferences, or the defaults when none
+// have been saved yet
+func (s *AuthService) GetPreferences(userID string) (*models.UserPreferences, error) {
+	var prefs models.UserPreferences
+	err := s.db.Where("user_id = ?", userID).First(&prefs).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return defaultPreferences(userID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &prefs, nil
+}
+
+// UpdatePreferences validates and saves a preferences update
+func (s *AuthService) UpdatePreferences(userID string, req UpdatePreferencesRequest) (*models.UserPreferences, error) {
+	if err := validatePreferences(req, s.isSupportedRegion, s.maxSyncMatches()); err != nil {
+		return nil, err
+	}
+
+	prefs, err := s.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Theme != nil {
+		prefs.Theme = *req.Theme
+	}
+	if req.Language != nil {
+		prefs.Language = *req.Language
+	}
+	if req.Region != nil {
+		prefs.Region = strings.ToLower(strings.TrimSpace(*req.Region))
+	}
+	if req.Timezone != nil {
+		prefs.Timezone = *req.Timezone
+	}
+	if req.EmailNotifications != nil {
+		prefs.EmailNotifications = *req.EmailNotifications
+	}
+	if req.PushNotifications != nil {
+		prefs.PushNotifications = *req.PushNotifications
+	}
+	if req.MatchAlerts != nil {
+		prefs.MatchAlerts = *req.MatchAlerts
+	}
+	if req.AnalyticsSharing != nil {
+		prefs.AnalyticsSharing = *req.AnalyticsSharing
+	}
+	if req.PrivacyMode != nil {
+		prefs.PrivacyMode = *req.PrivacyMode
+	}
+	if req.AutoSyncMatches != nil {
+		prefs.AutoSyncMatches = *req.AutoSyncMatches
+	}
+	if req.MaxSyncMatches != nil {
+		prefs.MaxSyncMatches = *req.MaxSyncMatches
+	}
+	if req.CoachingRecommendations != nil {
+		prefs.CoachingRecommendations = *req.CoachingRecommendations
+	}
+	if req.PublicProfile != nil {
+		prefs.PublicProfile = *req.PublicProfile
+	}
+	if req.ExcludeRemakes != nil {
+		prefs.ExcludeRemakes = *req.ExcludeRemakes
+	}
+
+	if err := s.savePreferences(prefs); err != nil {
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// validatePreferences checks the range of every field in a preferences
+// update before it is saved and reports all invalid fields at once. An empty
+// region clears it; isRegion may be nil to skip the routing table check.
+// req.MaxSyncMatches may not exceed maxSyncMatches.
+func validatePreferences(req UpdatePreferencesRequest, isRegion func(string) bool, maxSyncMatches int) error {
+	fieldErrors := &PreferencesError{Fields: make(map[string]string)}
+
+	if req.Theme != nil && !containsString(PreferenceThemes, *req.Theme) {
+		fieldErrors.add("theme", "must be one of "+strings.Join(PreferenceThemes, ", "))
+	}
+	if req.Language != nil && !languagePattern.MatchString(*req.Language) {
+		fieldErrors.add("language", "must be a language code such as en or pt-BR")
+	}
+	if req.Region != nil {
+		region := strings.ToLower(strings.TrimSpace(*req.Region))
+		if region != "" && isRegion != nil && !isRegion(region) {
+			fieldErrors.add("region", "is not a supported region")
+		}
+	}
+	if req.Timezone != nil && *req.Timezone != "" {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "Local" {
+			fieldErrors.add("timezone", "must be an IANA timezone such as Europe/Paris")
+		}
+	}
+	if req.MaxSyncMatches != nil && (*req.MaxSyncMatches < 1 || *req.MaxSyncMatches > maxSyncMatches) {
+		fieldErrors.add("maxSyncMatches", fmt.Sprintf("must be between 1 and %d", maxSyncMatches))
+	}
+
+	return fieldErrors.orNil()
+}
+
+// isSupportedRegion reports whether a platform is in the Riot routing table
+func (s *AuthService) isSupportedRegion(region string) bool {
+	if s.config == nil {
+		return true
+	}
+	return s.config.Riot.IsSupportedRegion(region)
+}
+
+// maxSyncMatches returns the highest accepted maxSyncMatches preference,
+// the most matches a single sync may request (MAX_GAME_COUNT)
+func (s *AuthService) maxSyncMatches() int {
+	if s.config != nil && s.config.Sync.MaxGameCount > 0 {
+		return s.config.Sync.MaxGameCount
+	}
+	return config.DefaultMaxGameCount
+}
+
+// savePreferences creates or updates the user's preferences row
+func (s *AuthService) savePreferences(prefs *models.UserPreferences) error {
+	if prefs.ID == 0 {
+		return s.db.Create(prefs).Error
+	}
+	return s.db.Save(prefs).Error
+}
+
+func defaultPreferences(userID string) *models.UserPreferences {
+	return &models.UserPreferences{
+		UserID:          userID,
+		Theme:           "dark",
+		Language:        "en",
+		AutoSyncMatches: true,
+		MaxSyncMatches:  DefaultMaxSyncMatches,
+		PublicProfile:   true,
+	}
+}
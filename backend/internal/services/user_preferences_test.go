@@ -0,0 +1,50 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdatePreferencesRequestValidation(t *testing.T) {
+	var req UpdatePreferencesRequest
+	err := json.Unmarshal([]byte(`{"autoSyncMatches":"yes","maxSyncMatches":"50","excludeRemakes":"true","theme":null,"color":"red"}`), &req)
+
+	var fieldErrors *PreferencesError
+	require.True(t, errors.As(err, &fieldErrors))
+	assert.True(t, errors.Is(err, ErrInvalidPreferences))
+	assert.Equal(t, "must be a boolean", fieldErrors.Fields["autoSyncMatches"])
+	assert.Equal(t, "is not a known preference", fieldErrors.Fields["color"])
+	assert.Len(t, fieldErrors.Fields, 2)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"maxSyncMatches":"50","excludeRemakes":"true","theme":null}`), &req))
+	require.NotNil(t, req.MaxSyncMatches)
+	assert.Equal(t, 50, *req.MaxSyncMatches)
+	require.NotNil(t, req.ExcludeRemakes)
+	assert.True(t, *req.ExcludeRemakes)
+	assert.Nil(t, req.Theme)
+
+	theme, language, timezone, region := "neon", "english", "Mars/Olympus", "xx1"
+	maxSync := 0
+	err = validatePreferences(UpdatePreferencesRequest{
+		Theme:          &theme,
+		Language:       &language,
+		Timezone:       &timezone,
+		Region:         &region,
+		MaxSyncMatches: &maxSync,
+	}, func(region string) bool { return region == "euw1" }, 1000)
+	require.True(t, errors.As(err, &fieldErrors))
+	assert.Len(t, fieldErrors.Fields, 5)
+
+	theme, language, timezone, region, maxSync = "light", "pt-BR", "Europe/Paris", "EUW1", 100
+	assert.NoError(t, validatePreferences(UpdatePreferencesRequest{
+		Theme:          &theme,
+		Language:       &language,
+		Timezone:       &timezone,
+		Region:         &region,
+		MaxSyncMatches: &maxSync,
+	}, func(region string) bool { return region == "euw1" }, 1000))
+}
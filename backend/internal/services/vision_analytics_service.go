@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/herald-lol/herald/backend/internal/models"
@@ -113,6 +114,9 @@ type HeatmapData struct {
 	DataPoints []HeatmapPoint `json:"data_points"`
 	Intensity  map[string]int `json:"intensity"` // Zone -> frequency
 	Coverage   float64        `json:"coverage_percent"`
+
+	// Zones buckets the ward placements by map zone
+	Zones []models.MapZoneStats `json:"zones"`
 }
 
 // HeatmapPoint represents a point on the heatmap
@@ -273,7 +277,7 @@ func (vas *VisionAnalyticsService) AnalyzeVision(ctx context.Context, playerID s
 }
 
 // GenerateVisionHeatmap creates detailed heatmap for ward placements
-func (vas *VisionAnalyticsService) GenerateVisionHeatmap(ctx context.Context, playerID string, timeRange string, wardType string) (*HeatmapData, error) {
+func (vas *VisionAnalyticsService) GenerateVisionHeatmap(ctx context.Context, playerID string, timeRange string, wardType string, mapSide string) (*HeatmapData, error) {
 	// Get detailed ward placement data
 	wardData, err := vas.getWardPlacementData(ctx, playerID, timeRange, wardType)
 	if err != nil {
@@ -284,10 +288,20 @@ func (vas *VisionAnalyticsService) GenerateVisionHeatmap(ctx context.Context, pl
 		MapSide:    "both",
 		DataPoints: []HeatmapPoint{},
 		Intensity:  make(map[string]int),
+		Zones:      []models.MapZoneStats{},
+	}
+	if mapSide == "BLUE" || mapSide == "RED" {
+		heatmap.MapSide = strings.ToLower(mapSide)
 	}
 
 	// Process ward placements into heatmap points
+	placed := make([]models.WardPlacement, 0, len(wardData))
 	for _, ward := range wardData {
+		if heatmap.MapSide != "both" && ward.MapSide != mapSide {
+			continue
+		}
+		placed = append(placed, ward)
+
 		point := HeatmapPoint{
 			X:         ward.X,
 			Y:         ward.Y,
@@ -305,9 +319,60 @@ func (vas *VisionAnalyticsService) GenerateVisionHeatmap(ctx context.Context, pl
 	// Calculate coverage percentage
 	heatmap.Coverage = vas.calculateMapCoverage(heatmap.DataPoints)
 
+	// Bucket placements by the map zone recorded at ingestion
+	heatmap.Zones = aggregateWardZones(playerID, timeRange, placed)
+
 	return heatmap, nil
 }
 
+// aggregateWardZones buckets ward placements into per-zone stats, busiest
+// zone first
+func aggregateWardZones(playerID, timeRange string, wards []models.WardPlacement) []models.MapZoneStats {
+	byZone := make(map[string]*models.MapZoneStats)
+	for _, ward := range wards {
+		zone := ward.Zone
+		if zone == "" {
+			zone = "unknown"
+		}
+		stats, ok := byZone[zone]
+		if !ok {
+			stats = &models.MapZoneStats{
+				PlayerID:    playerID,
+				ZoneName:    zone,
+				TimeRange:   timeRange,
+				LastUpdated: time.Now(),
+			}
+			byZone[zone] = stats
+		}
+
+		stats.WardsPlaced++
+		if ward.WardType == "CONTROL" {
+			stats.ControlWardsPlaced++
+		}
+		switch ward.GamePhase {
+		case "early":
+			stats.EarlyGameActivity++
+		case "mid":
+			stats.MidGameActivity++
+		case "late":
+			stats.LateGameActivity++
+		}
+	}
+
+	zones := make([]models.MapZoneStats, 0, len(byZone))
+	for _, stats := range byZone {
+		zones = append(zones, *stats)
+	}
+	sort.Slice(zones, func(i, j int) bool {
+		if zones[i].WardsPlaced != zones[j].WardsPlaced {
+			return zones[i].WardsPlaced > zones[j].WardsPlaced
+		}
+		return zones[i].ZoneName < zones[j].ZoneName
+	})
+
+	return zones
+}
+
 // GetVisionRecommendations provides personalized vision improvement tips
 func (vas *VisionAnalyticsService) GetVisionRecommendations(ctx context.Context, analysis *VisionAnalysis) []VisionRecommendation {
 	recommendations := []VisionRecommendation{}
@@ -775,9 +840,57 @@ func (vas *VisionAnalyticsService) getVisionBenchmark(ctx context.Context, categ
 	}, nil
 }
 
+// getWardPlacementData loads the ward placements recorded from the timelines
+// of the player's matches played in timeRange. A wardType of ALL or empty
+// returns every ward type.
 func (vas *VisionAnalyticsService) getWardPlacementData(ctx context.Context, playerID string, timeRange string, wardType string) ([]models.WardPlacement, error) {
-	// This would query detailed ward placement data
-	return []models.WardPlacement{}, nil
+	startDate, _ := vas.analyticsService.parseTimeRange(timeRange)
+
+	// Wards are keyed by the PUUID of the account that placed them
+	puuids, err := vas.analyticsService.accountPUUIDs(ctx, playerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve linked accounts: %w", err)
+	}
+
+	args := make([]interface{}, 0, len(puuids)+2)
+	placeholders := make([]string, 0, len(puuids))
+	for _, puuid := range puuids {
+		args = append(args, puuid)
+		placeholders = append(placeholders, fmt.Sprintf("$%d", len(args)))
+	}
+	args = append(args, startDate.UnixMilli())
+
+	query := fmt.Sprintf(`
+		SELECT w.id, w.match_id, w.player_id, w.ward_type, w.x, w.y, w.map_side,
+			w.timestamp, w.game_phase, w.zone, w.strategic
+		FROM ward_placements w
+		JOIN matches m ON m.match_id = w.match_id
+		WHERE w.player_id IN (%s)
+		AND m.game_start_timestamp >= $%d
+	`, strings.Join(placeholders, ", "), len(args))
+	if wardType != "" && wardType != "ALL" {
+		args = append(args, wardType)
+		query += fmt.Sprintf(" AND w.ward_type = $%d", len(args))
+	}
+	query += " ORDER BY m.game_start_timestamp, w.timestamp"
+
+	rows, err := vas.analyticsService.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ward placements: %w", err)
+	}
+	defer rows.Close()
+
+	wards := make([]models.WardPlacement, 0)
+	for rows.Next() {
+		var ward models.WardPlacement
+		if err := rows.Scan(&ward.ID, &ward.MatchID, &ward.PlayerID, &ward.WardType, &ward.X, &ward.Y, &ward.MapSide,
+			&ward.Timestamp, &ward.GamePhase, &ward.Zone, &ward.Strategic); err != nil {
+			return nil, fmt.Errorf("failed to scan ward placement: %w", err)
+		}
+		wards = append(wards, ward)
+	}
+
+	return wards, rows.Err()
 }
 
 func (vas *VisionAnalyticsService) identifyZone(x, y int) string {
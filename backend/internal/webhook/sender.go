@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Event is the envelope every webhook payload is sent in
+type Event struct {
+	Type      string    `json:"type"` // e.g. "export.completed", "sync.completed", "rank.changed"
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+// Sender delivers signed webhook events. Every webhook emitter should go
+// through it so payloads are shaped and signed the same way.
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender returns a Sender using client, or a client with a 10 second
+// timeout when nil
+func NewSender(client *http.Client) *Sender {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Sender{client: client}
+}
+
+// Send posts the event to url signed with the user's secret. Non-2xx
+// responses are returned as errors so callers can retry.
+func (s *Sender) Send(ctx context.Context, url, secret, eventType string, data any) error {
+	now := time.Now()
+	body, err := json.Marshal(Event{Type: eventType, CreatedAt: now.UTC(), Data: data})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Herald-Webhooks/1.0")
+	req.Header.Set(EventHeader, eventType)
+	req.Header.Set(SignatureHeader, Sign(secret, now, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook delivery failed: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook receiver returned %d", resp.StatusCode)
+	}
+	return nil
+}
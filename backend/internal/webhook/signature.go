@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Herald.lol Gaming Analytics - Webhook Signatures
+// Every webhook Herald sends (exports, syncs, rank changes) is signed the same
+// way so receivers can check it came from Herald and wasn't replayed.
+//
+// The X-Herald-Signature header looks like
+//
+//	X-Herald-Signature: t=1700000000,v1=5257a869e7ecebeda32affa62cdca3fa51cad7e77a0e56ff536d0ce8e108d8bd
+//
+// where t is the Unix time the payload was signed and v1 is the hex
+// HMAC-SHA256 of "<t>.<raw request body>" keyed with the user's webhook
+// secret. Receivers should recompute the HMAC over the raw body, before any
+// JSON decoding, compare it in constant time and reject old timestamps;
+// Verify does all three.
+
+const (
+	// SignatureHeader carries the payload signature
+	SignatureHeader = "X-Herald-Signature"
+	// EventHeader names the event a payload describes, e.g. "sync.completed"
+	EventHeader = "X-Herald-Event"
+
+	// DefaultTolerance is how old a signature Verify accepts by default
+	DefaultTolerance = 5 * time.Minute
+
+	// secretPrefix marks webhook secrets so they are recognizable in configs
+	secretPrefix     = "whsec_"
+	signatureVersion = "v1"
+)
+
+var (
+	ErrMissingSignature = errors.New("webhook signature missing")
+	ErrInvalidSignature = errors.New("webhook signature invalid")
+	ErrSignatureExpired = errors.New("webhook signature timestamp outside tolerance")
+)
+
+// NewSecret generates a random per-user webhook secret
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return secretPrefix + hex.EncodeToString(buf), nil
+}
+
+// Sign returns the X-Herald-Signature header value for payload signed at
+// timestamp
+func Sign(secret string, timestamp time.Time, payload []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return fmt.Sprintf("t=%s,%s=%s", t, signatureVersion, computeSignature(secret, t, payload))
+}
+
+// Verify checks an X-Herald-Signature header against the raw request body.
+// Signatures older or newer than tolerance (DefaultTolerance when 0) are
+// rejected so captured requests can't be replayed. Headers may carry several
+// v1 signatures while a secret is rotated; any one matching is enough.
+func Verify(secret, header string, payload []byte, tolerance time.Duration) error {
+	return verifyAt(secret, header, payload, tolerance, time.Now())
+}
+
+func verifyAt(secret, header string, payload []byte, tolerance time.Duration, now time.Time) error {
+	if header == "" {
+		return ErrMissingSignature
+	}
+	if tolerance <= 0 {
+		tolerance = DefaultTolerance
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case signatureVersion:
+			signatures = append(signatures, value)
+		}
+	}
+	if timestamp == "" || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return ErrSignatureExpired
+	}
+
+	expected := []byte(computeSignature(secret, timestamp, payload))
+	for _, signature := range signatures {
+		if hmac.Equal(expected, []byte(signature)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+func computeSignature(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(secret, "whsec_"))
+
+	payload := []byte(`{"type":"sync.completed"}`)
+	signedAt := time.Unix(1700000000, 0)
+	header := Sign(secret, signedAt, payload)
+	assert.True(t, strings.HasPrefix(header, "t=1700000000,v1="))
+
+	assert.NoError(t, verifyAt(secret, header, payload, 0, signedAt.Add(time.Minute)))
+	assert.ErrorIs(t, verifyAt(secret, header, []byte(`{"type":"rank.changed"}`), 0, signedAt), ErrInvalidSignature)
+	assert.ErrorIs(t, verifyAt("whsec_other", header, payload, 0, signedAt), ErrInvalidSignature)
+	assert.ErrorIs(t, verifyAt(secret, header, payload, 0, signedAt.Add(time.Hour)), ErrSignatureExpired)
+	assert.NoError(t, verifyAt(secret, header, payload, 2*time.Hour, signedAt.Add(time.Hour)))
+	assert.ErrorIs(t, verifyAt(secret, "", payload, 0, signedAt), ErrMissingSignature)
+	assert.ErrorIs(t, verifyAt(secret, "t=abc,v1=00", payload, 0, signedAt), ErrInvalidSignature)
+
+	// Any matching signature passes while a secret is being rotated
+	rotated := Sign("whsec_old", signedAt, payload) + ",v1=" + strings.SplitN(header, "v1=", 2)[1]
+	assert.NoError(t, verifyAt(secret, rotated, payload, 0, signedAt))
+}
+
+func TestSenderSignsPayload(t *testing.T) {
+	secret := "whsec_test"
+	var received error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "export.completed", r.Header.Get(EventHeader))
+		received = Verify(secret, r.Header.Get(SignatureHeader), body, 0)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	err := NewSender(server.Client()).Send(context.Background(), server.URL, secret, "export.completed", map[string]int{"matches": 3})
+	require.NoError(t, err)
+	assert.NoError(t, received)
+}
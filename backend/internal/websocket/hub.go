@@ -35,6 +35,13 @@ type Hub struct {
 	// Match-specific subscriptions
 	matchSubs map[string]map[*Client]bool
 
+	// Connections per user, oldest first, used to enforce maxConnsPerUser
+	userConns       map[string][]*Client
+	maxConnsPerUser int
+
+	// Checks the token a client connects with belongs to the user it claims
+	tokenValidator func(token, userID string) bool
+
 	// Statistics
 	stats HubStats
 
@@ -48,8 +55,15 @@ type HubStats struct {
 	MessagesPerSecond   float64   `json:"messages_per_second"`
 	LastMessageTime     time.Time `json:"last_message_time"`
 	AverageResponseTime float64   `json:"average_response_time_ms"`
+
+	// Per-user connection limit and how many connections it has closed
+	MaxConnectionsPerUser int   `json:"max_connections_per_user"`
+	EvictedConnections    int64 `json:"evicted_connections"`
 }
 
+// DefaultMaxConnectionsPerUser is used when NewHub is given no limit
+const DefaultMaxConnectionsPerUser = 5
+
 // Message types for Herald.lol gaming platform
 const (
 	MessageTypeMatchUpdate        = "match_update"
@@ -142,8 +156,13 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
-// NewHub creates a new WebSocket hub for Herald.lol
-func NewHub() *Hub {
+// NewHub creates a new WebSocket hub for Herald.lol. Each user may hold at
+// most maxConnsPerUser connections; values below 1 use
+// DefaultMaxConnectionsPerUser.
+func NewHub(maxConnsPerUser int) *Hub {
+	if maxConnsPerUser < 1 {
+		maxConnsPerUser = DefaultMaxConnectionsPerUser
+	}
 	return &Hub{
 		broadcast:  make(chan []byte, 1000), // Large buffer for high-throughput gaming data
 		register:   make(chan *Client, 100),
@@ -152,10 +171,22 @@ func NewHub() *Hub {
 		rooms:      make(map[string]map[*Client]bool),
 		userSubs:   make(map[string]map[*Client]bool),
 		matchSubs:  make(map[string]map[*Client]bool),
-		stats:      HubStats{},
+		userConns:  make(map[string][]*Client),
+		stats: HubStats{
+			MaxConnectionsPerUser: maxConnsPerUser,
+		},
+		maxConnsPerUser: maxConnsPerUser,
 	}
 }
 
+// WithTokenValidator sets the check that the token a client connects with
+// belongs to the user_id it claims; without one any non-empty token is
+// accepted
+func (h *Hub) WithTokenValidator(validate func(token, userID string) bool) *Hub {
+	h.tokenValidator = validate
+	return h
+}
+
 // Run starts the WebSocket hub
 func (h *Hub) Run(ctx context.Context) {
 	ticker := time.NewTicker(30 * time.Second)
@@ -170,22 +201,20 @@ func (h *Hub) Run(ctx context.Context) {
 		case client := <-h.register:
 			h.mu.Lock()
 			h.clients[client] = true
+			h.userConns[client.userID] = append(h.userConns[client.userID], client)
 			h.stats.TotalConnections++
+			evicted := h.enforceConnectionLimit(client.userID)
 			h.stats.ActiveConnections = len(h.clients)
 			h.mu.Unlock()
 
+			if evicted > 0 {
+				log.Printf("Closed %d oldest connection(s) for %s over the limit of %d", evicted, client.userID, h.maxConnsPerUser)
+			}
 			log.Printf("Client connected: %s (total: %d)", client.userID, len(h.clients))
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client]; ok {
-				delete(h.clients, client)
-				h.stats.ActiveConnections = len(h.clients)
-				close(client.send)
-
-				// Remove from all subscriptions
-				h.removeClientFromSubscriptions(client)
-			}
+			h.removeClient(client)
 			h.mu.Unlock()
 
 			log.Printf("Client disconnected: %s (total: %d)", client.userID, len(h.clients))
@@ -204,8 +233,7 @@ func (h *Hub) Run(ctx context.Context) {
 				case client.send <- message:
 				default:
 					h.mu.Lock()
-					delete(h.clients, client)
-					close(client.send)
+					h.removeClient(client)
 					h.mu.Unlock()
 				}
 			}
@@ -228,8 +256,6 @@ func (h *Hub) HandleWebSocket(c *gin.Context) {
 		return
 	}
 
-	// TODO: Validate JWT token
-	// For now, accept any non-empty token
 	if !h.validateToken(token, userID) {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
@@ -392,11 +418,55 @@ func (h *Hub) GetStats() HubStats {
 	return stats
 }
 
-// validateToken validates JWT token (placeholder implementation)
+// validateToken checks the client's token with the configured validator
 func (h *Hub) validateToken(token, userID string) bool {
-	// TODO: Implement actual JWT validation
-	// For now, accept any non-empty token
-	return token != "" && userID != ""
+	if token == "" || userID == "" {
+		return false
+	}
+	if h.tokenValidator == nil {
+		return true
+	}
+	return h.tokenValidator(token, userID)
+}
+
+// enforceConnectionLimit closes the user's oldest connections until they are
+// within maxConnsPerUser and returns how many were closed. Callers must hold
+// h.mu.
+func (h *Hub) enforceConnectionLimit(userID string) int {
+	evicted := 0
+	for len(h.userConns[userID]) > h.maxConnsPerUser {
+		h.removeClient(h.userConns[userID][0])
+		h.stats.EvictedConnections++
+		evicted++
+	}
+	return evicted
+}
+
+// removeClient drops a registered client and closes its send channel, which
+// makes its write pump close the connection. Callers must hold h.mu.
+func (h *Hub) removeClient(client *Client) {
+	if _, ok := h.clients[client]; !ok {
+		return
+	}
+	delete(h.clients, client)
+	close(client.send)
+	h.stats.ActiveConnections = len(h.clients)
+
+	conns := h.userConns[client.userID]
+	for i, conn := range conns {
+		if conn == client {
+			conns = append(conns[:i], conns[i+1:]...)
+			break
+		}
+	}
+	if len(conns) == 0 {
+		delete(h.userConns, client.userID)
+	} else {
+		h.userConns[client.userID] = conns
+	}
+
+	// Remove from all subscriptions
+	h.removeClientFromSubscriptions(client)
 }
 
 // removeClientFromSubscriptions removes client from all subscriptions